@@ -25,6 +25,31 @@ type Config struct {
 		AuthURL  string `mapstructure:"AUTH_URL"`
 	}
 
+	Analytics struct {
+		Aggregation struct {
+			Enabled         bool `mapstructure:"ENABLED"`
+			IntervalMinutes int  `mapstructure:"INTERVAL_MINUTES"`
+			LookbackDays    int  `mapstructure:"LOOKBACK_DAYS"`
+		}
+	}
+
+	Archive struct {
+		MaxSizeMB int64 `mapstructure:"MAX_SIZE_MB"`
+	}
+
+	Attachment struct {
+		MaxSizeMB        int64 `mapstructure:"MAX_SIZE_MB"`
+		URLExpiryMinutes int   `mapstructure:"URL_EXPIRY_MINUTES"`
+	}
+
+	Calendar struct {
+		FeedKey string `mapstructure:"FEED_KEY"`
+	}
+
+	Certificate struct {
+		SigningKey string `mapstructure:"SIGNING_KEY"`
+	}
+
 	Cache struct {
 		Redis struct {
 			Primary struct {
@@ -84,6 +109,10 @@ type Config struct {
 				Region          string `mapstructure:"REGION"`
 				SecretAccessKey string `mapstructure:"SECRET_ACCESS_KEY"`
 				Topics          struct {
+					AnnouncementPosted struct {
+						ARN     string `mapstructure:"ARN"`
+						Enabled bool   `mapstructure:"ENABLED"`
+					} `mapstructure:"ANNOUNCEMENT_POSTED"`
 					FooCreated struct {
 						ARN     string `mapstructure:"ARN"`
 						Enabled bool   `mapstructure:"ENABLED"`
@@ -102,6 +131,22 @@ type Config struct {
 			GracePeriodSeconds   int64 `mapstructure:"GRACE_PERIOD_SECONDS"`
 		}
 	}
+
+	Storage struct {
+		Driver string `mapstructure:"DRIVER"`
+		Local  struct {
+			Root       string `mapstructure:"ROOT"`
+			SigningKey string `mapstructure:"SIGNING_KEY"`
+		}
+		S3 struct {
+			AccessKeyID     string `mapstructure:"ACCESS_KEY_ID"`
+			Bucket          string `mapstructure:"BUCKET"`
+			Endpoint        string `mapstructure:"ENDPOINT"`
+			ForcePathStyle  bool   `mapstructure:"FORCE_PATH_STYLE"`
+			Region          string `mapstructure:"REGION"`
+			SecretAccessKey string `mapstructure:"SECRET_ACCESS_KEY"`
+		}
+	}
 }
 
 var (
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/rs/zerolog/log"
+)
+
+// LocalFilesPath is the path, relative to the app URL, the blobs of a
+// LocalStorage are downloaded from.
+const LocalFilesPath = "/v1/files/"
+
+// LocalStorage is a Storage keeping blobs in a directory of the local
+// filesystem. Its signed URLs point back to this app, which verifies them
+// before serving the blob.
+type LocalStorage struct {
+	Root       string
+	BaseURL    string
+	SigningKey string
+}
+
+// NewLocalStorage creates a LocalStorage from the configuration.
+func NewLocalStorage(config *configs.Config) *LocalStorage {
+	root := config.Storage.Local.Root
+	if root == "" {
+		root = "storage"
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		log.Fatal().Err(err).Str("root", root).Msg("failed creating local storage root")
+	}
+
+	if config.Storage.Local.SigningKey == "" {
+		log.Warn().Msg("STORAGE.LOCAL.SIGNING_KEY is not set, local storage will not sign or serve download links.")
+	}
+
+	log.Info().Str("root", root).Msg("Local storage ready.")
+	return &LocalStorage{
+		Root:       root,
+		BaseURL:    strings.TrimSuffix(config.App.URL, "/") + LocalFilesPath,
+		SigningKey: config.Storage.Local.SigningKey,
+	}
+}
+
+// Delete deletes a blob.
+func (s *LocalStorage) Delete(key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(name)
+	if err != nil && !os.IsNotExist(err) {
+		logger.ErrorWithStack(err)
+		return err
+	}
+
+	return nil
+}
+
+// Open opens a blob for reading.
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, failure.NotFound("file")
+		}
+
+		logger.ErrorWithStack(err)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Put stores a blob. It is written to a temporary file first, so readers
+// never see a partial blob.
+func (s *LocalStorage) Put(key string, body io.ReadSeeker, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		logger.ErrorWithStack(err)
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return err
+	}
+
+	if written != size {
+		return fmt.Errorf("stored %d bytes of a %d bytes blob", written, size)
+	}
+
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return err
+}
+
+// SignedURL returns a URL to this app's file endpoint, signed so it can only
+// download this blob, under this file name, until it expires.
+func (s *LocalStorage) SignedURL(key string, fileName string, expiry time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	signature, err := s.sign(key, fileName, expires)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("filename", fileName)
+	query.Set("signature", signature)
+
+	return s.BaseURL + key + "?" + query.Encode(), nil
+}
+
+// Verify checks a signed URL's parameters were issued by SignedURL for this
+// blob and have not expired yet. Nothing verifies while no signing key is
+// configured.
+func (s *LocalStorage) Verify(key string, fileName string, expires string, signature string) error {
+	expected, err := s.sign(key, fileName, expires)
+	if err != nil {
+		return failure.NotFound("file")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return failure.Forbidden("the download link is invalid")
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return failure.Forbidden("the download link has expired")
+	}
+
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would
+// escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned != "/"+key {
+		return "", failure.BadRequestFromString("invalid storage key")
+	}
+
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+// sign computes the signature of a download link. It refuses to sign with an
+// empty key, which would let anyone forge links.
+func (s *LocalStorage) sign(key string, fileName string, expires string) (string, error) {
+	if s.SigningKey == "" {
+		return "", errors.New("local storage signing key is not configured")
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.SigningKey))
+	mac.Write([]byte(key + "\n" + fileName + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/infras/storage"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	s := &storage.LocalStorage{Root: root, BaseURL: "http://localhost:8080" + storage.LocalFilesPath, SigningKey: "secret"}
+	key := "courses/1/lessons/2/3"
+	content := "fmt.Println(\"hello\")"
+
+	t.Run("stored blobs can be read back and deleted", func(t *testing.T) {
+		assert.NoError(t, s.Put(key, strings.NewReader(content), int64(len(content)), "text/plain"))
+
+		file, err := s.Open(key)
+		assert.NoError(t, err)
+		stored, _ := ioutil.ReadAll(file)
+		file.Close()
+		assert.Equal(t, content, string(stored))
+
+		assert.NoError(t, s.Delete(key))
+		_, err = s.Open(key)
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+		assert.NoError(t, s.Delete(key))
+	})
+
+	t.Run("keys cannot escape the root", func(t *testing.T) {
+		err := s.Put("../outside", strings.NewReader(content), int64(len(content)), "text/plain")
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("signed URLs only download their blob until they expire", func(t *testing.T) {
+		signed, err := s.SignedURL(key, "main.go", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(signed, s.BaseURL+key+"?"))
+
+		link, _ := url.Parse(signed)
+		query := link.Query()
+		assert.NoError(t, s.Verify(key, query.Get("filename"), query.Get("expires"), query.Get("signature")))
+		assert.Error(t, s.Verify("courses/1/lessons/2/4", query.Get("filename"), query.Get("expires"), query.Get("signature")))
+		assert.Error(t, s.Verify(key, "other.go", query.Get("expires"), query.Get("signature")))
+
+		expired, _ := s.SignedURL(key, "main.go", -time.Minute)
+		link, _ = url.Parse(expired)
+		query = link.Query()
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(s.Verify(key, query.Get("filename"), query.Get("expires"), query.Get("signature"))))
+	})
+	t.Run("nothing is signed or served without a signing key", func(t *testing.T) {
+		unsigned := &storage.LocalStorage{Root: root, BaseURL: s.BaseURL}
+		assert.NoError(t, unsigned.Put(key, strings.NewReader(content), int64(len(content)), "text/plain"))
+
+		_, err := unsigned.SignedURL(key, "main.go", time.Minute)
+		assert.Error(t, err)
+
+		expires := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+		mac := hmac.New(sha256.New, nil)
+		mac.Write([]byte(key + "\nmain.go\n" + expires))
+		forged := hex.EncodeToString(mac.Sum(nil))
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(unsigned.Verify(key, "main.go", expires, forged)))
+	})
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/rs/zerolog/log"
+)
+
+// S3Storage is a Storage keeping blobs in a bucket of S3 or of an
+// S3-compatible service, such as MinIO. Its signed URLs are presigned S3
+// requests.
+type S3Storage struct {
+	Bucket string
+	client *s3.S3
+}
+
+// NewS3Storage creates an S3Storage from the configuration.
+func NewS3Storage(config *configs.Config) *S3Storage {
+	awsConfig := &aws.Config{
+		Region: aws.String(config.Storage.S3.Region),
+		Credentials: credentials.NewStaticCredentialsFromCreds(credentials.Value{
+			AccessKeyID:     config.Storage.S3.AccessKeyID,
+			SecretAccessKey: config.Storage.S3.SecretAccessKey,
+		}),
+		S3ForcePathStyle: aws.Bool(config.Storage.S3.ForcePathStyle),
+	}
+	if config.Storage.S3.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Storage.S3.Endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed creating S3 session")
+	}
+
+	log.Info().Str("bucket", config.Storage.S3.Bucket).Msg("S3 storage ready.")
+	return &S3Storage{Bucket: config.Storage.S3.Bucket, client: s3.New(sess)}
+}
+
+// Delete deletes a blob.
+func (s *S3Storage) Delete(key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return err
+}
+
+// Open opens a blob for reading.
+func (s *S3Storage) Open(key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, failure.NotFound("file")
+		}
+
+		logger.ErrorWithStack(err)
+		return nil, err
+	}
+
+	return output.Body, nil
+}
+
+// Put stores a blob.
+func (s *S3Storage) Put(key string, body io.ReadSeeker, size int64, contentType string) error {
+	_, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket:        aws.String(s.Bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return err
+}
+
+// SignedURL presigns a request downloading a blob under the given file name.
+func (s *S3Storage) SignedURL(key string, fileName string, expiry time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     aws.String(s.Bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	})
+
+	signed, err := req.Presign(expiry)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return signed, err
+}
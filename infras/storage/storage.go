@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"io"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DriverLocal keeps blobs on the local filesystem.
+	DriverLocal = "local"
+	// DriverS3 keeps blobs in an S3-compatible bucket.
+	DriverS3 = "s3"
+)
+
+// Storage represents a blob storage. Blobs are addressed by slash-separated
+// keys, such as "courses/{id}/lessons/{id}/{attachmentID}".
+type Storage interface {
+	// Delete deletes a blob. Deleting a missing blob is not an error.
+	Delete(key string) error
+	// Open opens a blob for reading. The caller closes it.
+	Open(key string) (io.ReadCloser, error)
+	// Put stores a blob, replacing any blob with the same key.
+	Put(key string, body io.ReadSeeker, size int64, contentType string) error
+	// SignedURL returns a URL anyone can download a blob from until it
+	// expires, saved under the given file name.
+	SignedURL(key string, fileName string, expiry time.Duration) (string, error)
+}
+
+// ProvideStorage provides the Storage selected by STORAGE.DRIVER, defaulting
+// to the local filesystem.
+func ProvideStorage(config *configs.Config) Storage {
+	switch config.Storage.Driver {
+	case DriverS3:
+		return NewS3Storage(config)
+	case DriverLocal, "":
+		return NewLocalStorage(config)
+	default:
+		log.Fatal().Str("driver", config.Storage.Driver).Msg("unknown storage driver")
+		return nil
+	}
+}
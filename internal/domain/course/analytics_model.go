@@ -0,0 +1,382 @@
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+const (
+	// AnalyticsDefaultRangeDays is how many days analytics cover, up to today,
+	// when no range is given.
+	AnalyticsDefaultRangeDays = 30
+	// AnalyticsMaxRangeDays is the longest range analytics may cover at once.
+	AnalyticsMaxRangeDays = 366
+	// QuizScoreBuckets is how many equally wide ranges of percentages quiz
+	// scores are distributed across.
+	QuizScoreBuckets = 10
+
+	analyticsDayFormat = "2006-01-02"
+)
+
+//// Analytics Query Parameters
+
+// AnalyticsQueryParameters filters the aggregated analytics of a Course down to
+// one of its Cohorts and to the days from From to To, both included.
+type AnalyticsQueryParameters struct {
+	CourseID uuid.UUID
+	CohortID nuuid.NUUID
+	From     time.Time
+	To       time.Time
+}
+
+// NewAnalyticsQueryParameters builds the AnalyticsQueryParameters of a Course.
+// Times are truncated to their UTC day; a missing to defaults to today and a
+// missing from to AnalyticsDefaultRangeDays days before to.
+func NewAnalyticsQueryParameters(courseID uuid.UUID, cohortID nuuid.NUUID, from null.Time, to null.Time, now time.Time) (params AnalyticsQueryParameters, err error) {
+	params = AnalyticsQueryParameters{
+		CourseID: courseID,
+		CohortID: cohortID,
+		To:       analyticsDay(now),
+	}
+
+	if to.Valid {
+		params.To = analyticsDay(to.Time)
+	}
+
+	params.From = params.To.AddDate(0, 0, 1-AnalyticsDefaultRangeDays)
+	if from.Valid {
+		params.From = analyticsDay(from.Time)
+	}
+
+	if params.From.After(params.To) {
+		return params, errors.New("from must not be after to")
+	}
+
+	if len(params.Days()) > AnalyticsMaxRangeDays {
+		return params, fmt.Errorf("analytics may cover at most %d days at once", AnalyticsMaxRangeDays)
+	}
+
+	return
+}
+
+// Days lists every day these parameters cover, in order.
+func (p AnalyticsQueryParameters) Days() (days []time.Time) {
+	for day := p.From; !day.After(p.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	return
+}
+
+// analyticsDay truncates a time to the start of its UTC day, which is how the
+// aggregation job buckets activity.
+func analyticsDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+//// Enrollments Over Time
+
+// EnrollmentsDay is how many students were enrolled in and withdrew or were
+// removed from a Course on a day.
+type EnrollmentsDay struct {
+	Day         time.Time `db:"day"`
+	Enrollments int       `db:"enrollments"`
+	Withdrawals int       `db:"withdrawals"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (d EnrollmentsDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.ToResponseFormat())
+}
+
+// ToResponseFormat converts this EnrollmentsDay into its response format.
+func (d EnrollmentsDay) ToResponseFormat() EnrollmentsDayResponseFormat {
+	return EnrollmentsDayResponseFormat{
+		Day:         d.Day.Format(analyticsDayFormat),
+		Enrollments: d.Enrollments,
+		Withdrawals: d.Withdrawals,
+	}
+}
+
+// EnrollmentsDayResponseFormat is the response format of an EnrollmentsDay.
+type EnrollmentsDayResponseFormat struct {
+	Day         string `json:"day" example:"2024-01-31"`
+	Enrollments int    `json:"enrollments"`
+	Withdrawals int    `json:"withdrawals"`
+}
+
+// NewEnrollmentsSeries lists the EnrollmentsDays of every day the parameters
+// cover, with the days nothing happened on at zero.
+func NewEnrollmentsSeries(params AnalyticsQueryParameters, days []EnrollmentsDay) []EnrollmentsDay {
+	byDay := make(map[time.Time]EnrollmentsDay, len(days))
+	for _, day := range days {
+		byDay[analyticsDay(day.Day)] = day
+	}
+
+	series := make([]EnrollmentsDay, 0, len(days))
+	for _, day := range params.Days() {
+		enrollments := byDay[day]
+		enrollments.Day = day
+		series = append(series, enrollments)
+	}
+
+	return series
+}
+
+//// Completion Funnel
+
+// LessonProgressCount is how many students started and completed a Lesson.
+type LessonProgressCount struct {
+	LessonID  uuid.UUID `db:"lesson_id"`
+	Started   int       `db:"started"`
+	Completed int       `db:"completed"`
+}
+
+// LessonFunnelStep is how far students got through a Lesson of a Course,
+// compared to the Lesson before it.
+type LessonFunnelStep struct {
+	LessonID       uuid.UUID  `json:"lessonId"`
+	ModuleID       uuid.UUID  `json:"moduleId"`
+	Title          string     `json:"title"`
+	Started        int        `json:"started"`
+	Completed      int        `json:"completed"`
+	CompletionRate null.Float `json:"completionRate"`
+	DropOffRate    null.Float `json:"dropOffRate"`
+}
+
+// NewLessonFunnel lays the LessonProgressCounts of a Course out over its
+// Modules' Lessons, in course order. The completion rate of a Lesson is the
+// percentage of the students who started it that completed it; its drop-off
+// rate is how many fewer completions it had than the Lesson before it, as a
+// percentage of those.
+func NewLessonFunnel(modules []Module, counts []LessonProgressCount) []LessonFunnelStep {
+	byLesson := make(map[uuid.UUID]LessonProgressCount, len(counts))
+	for _, count := range counts {
+		byLesson[count.LessonID] = count
+	}
+
+	funnel := make([]LessonFunnelStep, 0)
+	for _, module := range modules {
+		for _, lesson := range module.Lessons {
+			count := byLesson[lesson.ID]
+			step := LessonFunnelStep{
+				LessonID:  lesson.ID,
+				ModuleID:  module.ID,
+				Title:     lesson.Title,
+				Started:   count.Started,
+				Completed: count.Completed,
+			}
+
+			if step.Started > 0 {
+				step.CompletionRate = null.FloatFrom(roundPercent(float64(step.Completed) * 100 / float64(step.Started)))
+			}
+
+			if len(funnel) > 0 && funnel[len(funnel)-1].Completed > 0 {
+				previous := float64(funnel[len(funnel)-1].Completed)
+				// students may skip ahead, which is no drop-off
+				lost := previous - float64(step.Completed)
+				if lost < 0 {
+					lost = 0
+				}
+				step.DropOffRate = null.FloatFrom(roundPercent(lost * 100 / previous))
+			}
+
+			funnel = append(funnel, step)
+		}
+	}
+
+	return funnel
+}
+
+//// Time To Complete
+
+// CompletionTimeDay is how many students completed a Course on a day and the
+// total time they took from enrolling to completing it.
+type CompletionTimeDay struct {
+	Day          time.Time `db:"day"`
+	Completions  int       `db:"completions"`
+	TotalSeconds int64     `db:"total_seconds"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (d CompletionTimeDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.ToResponseFormat())
+}
+
+// AverageSeconds is the average time the students who completed the Course on
+// this day took, if any did.
+func (d CompletionTimeDay) AverageSeconds() null.Int {
+	if d.Completions == 0 {
+		return null.Int{}
+	}
+
+	return null.IntFrom(d.TotalSeconds / int64(d.Completions))
+}
+
+// ToResponseFormat converts this CompletionTimeDay into its response format.
+func (d CompletionTimeDay) ToResponseFormat() CompletionTimeDayResponseFormat {
+	return CompletionTimeDayResponseFormat{
+		Day:            d.Day.Format(analyticsDayFormat),
+		Completions:    d.Completions,
+		AverageSeconds: d.AverageSeconds(),
+	}
+}
+
+// CompletionTimeDayResponseFormat is the response format of a CompletionTimeDay.
+type CompletionTimeDayResponseFormat struct {
+	Day            string   `json:"day" example:"2024-01-31"`
+	Completions    int      `json:"completions"`
+	AverageSeconds null.Int `json:"averageSeconds"`
+}
+
+// CompletionTime is the average time students took to complete a Course, over
+// a range of days and day by day.
+type CompletionTime struct {
+	Completions    int                 `json:"completions"`
+	AverageSeconds null.Int            `json:"averageSeconds"`
+	Days           []CompletionTimeDay `json:"days"`
+}
+
+// NewCompletionTime averages the CompletionTimeDays of the days the parameters
+// cover, listing every one of them.
+func NewCompletionTime(params AnalyticsQueryParameters, days []CompletionTimeDay) CompletionTime {
+	byDay := make(map[time.Time]CompletionTimeDay, len(days))
+	for _, day := range days {
+		byDay[analyticsDay(day.Day)] = day
+	}
+
+	var total CompletionTimeDay
+	completionTime := CompletionTime{Days: make([]CompletionTimeDay, 0, len(days))}
+	for _, day := range params.Days() {
+		completions := byDay[day]
+		completions.Day = day
+		completionTime.Days = append(completionTime.Days, completions)
+
+		total.Completions += completions.Completions
+		total.TotalSeconds += completions.TotalSeconds
+	}
+
+	completionTime.Completions = total.Completions
+	completionTime.AverageSeconds = total.AverageSeconds()
+	return completionTime
+}
+
+//// Quiz Score Distributions
+
+// QuizScoreBucket is how many submitted attempts at a Quiz scored within a
+// range of percentages. Bucket n holds the scores from n*10% up to, but not
+// including, (n+1)*10%; the last one includes perfect scores.
+type QuizScoreBucket struct {
+	QuizID   uuid.UUID `db:"quiz_id"`
+	Bucket   int       `db:"bucket"`
+	Attempts int       `db:"attempts"`
+}
+
+// QuizScoreRange is how many submitted attempts at a Quiz scored from
+// MinPercent up to MaxPercent.
+type QuizScoreRange struct {
+	MinPercent int `json:"minPercent"`
+	MaxPercent int `json:"maxPercent"`
+	Attempts   int `json:"attempts"`
+}
+
+// QuizScoreDistribution is how the scores of the submitted attempts at a Quiz
+// are distributed.
+type QuizScoreDistribution struct {
+	QuizID   uuid.UUID        `json:"quizId"`
+	LessonID uuid.UUID        `json:"lessonId"`
+	Title    string           `json:"title"`
+	Attempts int              `json:"attempts"`
+	Ranges   []QuizScoreRange `json:"ranges"`
+}
+
+// NewQuizScoreDistributions distributes the QuizScoreBuckets of the active
+// Quizzes of a Course across QuizScoreBuckets ranges each. Buckets of Quizzes
+// that were deleted since are left out.
+func NewQuizScoreDistributions(quizzes []Quiz, buckets []QuizScoreBucket) []QuizScoreDistribution {
+	distributions := make([]QuizScoreDistribution, 0, len(quizzes))
+	quizIndexes := make(map[uuid.UUID]int, len(quizzes))
+	width := 100 / QuizScoreBuckets
+
+	for _, quiz := range quizzes {
+		distribution := QuizScoreDistribution{
+			QuizID:   quiz.ID,
+			LessonID: quiz.LessonID,
+			Title:    quiz.Title,
+			Ranges:   make([]QuizScoreRange, QuizScoreBuckets),
+		}
+
+		for i := range distribution.Ranges {
+			distribution.Ranges[i] = QuizScoreRange{MinPercent: i * width, MaxPercent: (i + 1) * width}
+		}
+
+		quizIndexes[quiz.ID] = len(distributions)
+		distributions = append(distributions, distribution)
+	}
+
+	for _, bucket := range buckets {
+		i, ok := quizIndexes[bucket.QuizID]
+		if !ok || bucket.Bucket < 0 || bucket.Bucket >= QuizScoreBuckets {
+			continue
+		}
+
+		distributions[i].Ranges[bucket.Bucket].Attempts += bucket.Attempts
+		distributions[i].Attempts += bucket.Attempts
+	}
+
+	return distributions
+}
+
+//// Active Learners
+
+// ActiveLearnersDay is how many enrolled students were active in a Course on a
+// day: progressed through a Lesson, attempted a Quiz, submitted an Assignment
+// or posted in its discussions.
+type ActiveLearnersDay struct {
+	Day            time.Time `db:"day"`
+	ActiveLearners int       `db:"active_learners"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (d ActiveLearnersDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.ToResponseFormat())
+}
+
+// ToResponseFormat converts this ActiveLearnersDay into its response format.
+func (d ActiveLearnersDay) ToResponseFormat() ActiveLearnersDayResponseFormat {
+	return ActiveLearnersDayResponseFormat{
+		Day:            d.Day.Format(analyticsDayFormat),
+		ActiveLearners: d.ActiveLearners,
+	}
+}
+
+// ActiveLearnersDayResponseFormat is the response format of an ActiveLearnersDay.
+type ActiveLearnersDayResponseFormat struct {
+	Day            string `json:"day" example:"2024-01-31"`
+	ActiveLearners int    `json:"activeLearners"`
+}
+
+// NewActiveLearnersSeries lists the ActiveLearnersDays of every day the
+// parameters cover, with the days no one was active on at zero.
+func NewActiveLearnersSeries(params AnalyticsQueryParameters, days []ActiveLearnersDay) []ActiveLearnersDay {
+	byDay := make(map[time.Time]ActiveLearnersDay, len(days))
+	for _, day := range days {
+		byDay[analyticsDay(day.Day)] = day
+	}
+
+	series := make([]ActiveLearnersDay, 0, len(days))
+	for _, day := range params.Days() {
+		learners := byDay[day]
+		learners.Day = day
+		series = append(series, learners)
+	}
+
+	return series
+}
@@ -0,0 +1,96 @@
+package course_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalytics(t *testing.T) {
+	courseID := getRandomUUID()
+	now := time.Date(2024, 3, 10, 15, 4, 5, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("ranges default to the last 30 days and are validated", func(t *testing.T) {
+		params, err := course.NewAnalyticsQueryParameters(courseID, nuuid.NUUID{}, null.Time{}, null.Time{}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, day(10), params.To)
+		assert.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), params.From)
+		assert.Len(t, params.Days(), course.AnalyticsDefaultRangeDays)
+
+		_, err = course.NewAnalyticsQueryParameters(courseID, nuuid.NUUID{}, null.TimeFrom(day(9)), null.TimeFrom(day(8)), now)
+		assert.Error(t, err)
+
+		_, err = course.NewAnalyticsQueryParameters(courseID, nuuid.NUUID{}, null.TimeFrom(day(1).AddDate(-2, 0, 0)), null.Time{}, now)
+		assert.Error(t, err)
+	})
+
+	params, err := course.NewAnalyticsQueryParameters(courseID, nuuid.NUUID{}, null.TimeFrom(day(1)), null.TimeFrom(day(3).Add(time.Hour)), now)
+	assert.NoError(t, err)
+
+	t.Run("series list every day of the range", func(t *testing.T) {
+		series := course.NewEnrollmentsSeries(params, []course.EnrollmentsDay{{Day: day(2), Enrollments: 4, Withdrawals: 1}})
+
+		assert.Len(t, series, 3)
+		assert.Equal(t, day(1), series[0].Day)
+		assert.Equal(t, 0, series[0].Enrollments)
+		assert.Equal(t, 4, series[1].Enrollments)
+		assert.Equal(t, "2024-03-03", series[2].ToResponseFormat().Day)
+	})
+
+	t.Run("the funnel follows course order and measures drop-off", func(t *testing.T) {
+		first, second := course.Module{ID: getRandomUUID()}, course.Module{ID: getRandomUUID()}
+		first.Lessons = []course.Lesson{{ID: getRandomUUID(), Title: "Welcome"}, {ID: getRandomUUID(), Title: "Basics"}}
+		second.Lessons = []course.Lesson{{ID: getRandomUUID(), Title: "Advanced"}, {ID: getRandomUUID(), Title: "Wrap-up"}}
+
+		funnel := course.NewLessonFunnel([]course.Module{first, second}, []course.LessonProgressCount{
+			{LessonID: second.Lessons[0].ID, Started: 10, Completed: 5},
+			{LessonID: first.Lessons[0].ID, Started: 40, Completed: 40},
+			{LessonID: first.Lessons[1].ID, Started: 35, Completed: 20},
+		})
+
+		assert.Len(t, funnel, 4)
+		assert.Equal(t, "Welcome", funnel[0].Title)
+		assert.False(t, funnel[0].DropOffRate.Valid)
+		assert.Equal(t, null.FloatFrom(57.14), funnel[1].CompletionRate)
+		assert.Equal(t, null.FloatFrom(50), funnel[1].DropOffRate)
+		assert.Equal(t, second.ID, funnel[2].ModuleID)
+		assert.Equal(t, null.FloatFrom(75), funnel[2].DropOffRate)
+		assert.False(t, funnel[3].CompletionRate.Valid, "no one started the last lesson")
+		assert.Equal(t, null.FloatFrom(100), funnel[3].DropOffRate)
+	})
+
+	t.Run("completion times average over the range", func(t *testing.T) {
+		completionTime := course.NewCompletionTime(params, []course.CompletionTimeDay{
+			{Day: day(1), Completions: 1, TotalSeconds: 3600},
+			{Day: day(3), Completions: 2, TotalSeconds: 3 * 3600},
+		})
+
+		assert.Equal(t, 3, completionTime.Completions)
+		assert.Equal(t, null.IntFrom(4*3600/3), completionTime.AverageSeconds)
+		assert.Len(t, completionTime.Days, 3)
+		assert.False(t, completionTime.Days[1].AverageSeconds().Valid)
+		assert.Equal(t, null.IntFrom(5400), completionTime.Days[2].AverageSeconds())
+	})
+
+	t.Run("quiz scores distribute across ten ranges", func(t *testing.T) {
+		quiz := course.Quiz{ID: getRandomUUID(), Title: "Checkpoint"}
+		distributions := course.NewQuizScoreDistributions([]course.Quiz{quiz}, []course.QuizScoreBucket{
+			{QuizID: quiz.ID, Bucket: 9, Attempts: 3},
+			{QuizID: quiz.ID, Bucket: 4, Attempts: 2},
+			{QuizID: getRandomUUID(), Bucket: 0, Attempts: 7},
+		})
+
+		assert.Len(t, distributions, 1)
+		assert.Equal(t, 5, distributions[0].Attempts)
+		assert.Len(t, distributions[0].Ranges, course.QuizScoreBuckets)
+		assert.Equal(t, course.QuizScoreRange{MinPercent: 40, MaxPercent: 50, Attempts: 2}, distributions[0].Ranges[4])
+		assert.Equal(t, 3, distributions[0].Ranges[9].Attempts)
+	})
+}
@@ -0,0 +1,418 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source analytics_repository.go -destination mock/analytics_repository_mock.go -package course_mock
+
+import (
+	"time"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// The aggregate tables are keyed by cohort, with students outside of any
+// Cohort under an empty cohort_id, and by UTC day. Reads add the cohorts up
+// unless filtered down to one.
+var (
+	analyticsQueries = struct {
+		deleteEnrollmentsDays       string
+		aggregateEnrollmentsDays    string
+		selectEnrollmentsDays       string
+		deleteLessonProgressDays    string
+		aggregateLessonProgressDays string
+		selectLessonProgressCounts  string
+		deleteCompletionTimeDays    string
+		aggregateCompletionTimeDays string
+		selectCompletionTimeDays    string
+		deleteQuizScoreDays         string
+		aggregateQuizScoreDays      string
+		selectQuizScoreBuckets      string
+		aggregateActiveLearnersDays string
+		selectActiveLearnersDays    string
+	}{
+		deleteEnrollmentsDays: `
+			DELETE FROM analytics_enrollments_daily
+			WHERE day >= :since
+		`,
+
+		aggregateEnrollmentsDays: `
+			INSERT INTO analytics_enrollments_daily (
+				course_id,
+				cohort_id,
+				day,
+				enrollments,
+				withdrawals
+			)
+			SELECT course_id, cohort_id, day, SUM(enrollments), SUM(withdrawals)
+			FROM (
+				SELECT
+					course_id,
+					COALESCE(cohort_id, '') AS cohort_id,
+					DATE(created_at) AS day,
+					1 AS enrollments,
+					0 AS withdrawals
+				FROM enrollments
+				WHERE status IN ('active', 'withdrawn', 'removed') AND created_at >= :since
+				UNION ALL
+				SELECT
+					course_id,
+					COALESCE(cohort_id, ''),
+					DATE(updated_at),
+					0,
+					1
+				FROM enrollments
+				WHERE status IN ('withdrawn', 'removed') AND updated_at >= :since
+			) AS events
+			GROUP BY course_id, cohort_id, day
+		`,
+
+		selectEnrollmentsDays: `
+			SELECT
+				day,
+				SUM(enrollments) AS enrollments,
+				SUM(withdrawals) AS withdrawals
+			FROM analytics_enrollments_daily
+		`,
+
+		deleteLessonProgressDays: `
+			DELETE FROM analytics_lesson_progress_daily
+			WHERE day >= :since
+		`,
+
+		aggregateLessonProgressDays: `
+			INSERT INTO analytics_lesson_progress_daily (
+				course_id,
+				cohort_id,
+				lesson_id,
+				day,
+				started,
+				completed
+			)
+			SELECT course_id, cohort_id, lesson_id, day, SUM(started), SUM(completed)
+			FROM (
+				SELECT
+					p.course_id,
+					COALESCE(e.cohort_id, '') AS cohort_id,
+					p.lesson_id,
+					DATE(p.started_at) AS day,
+					1 AS started,
+					0 AS completed
+				FROM lesson_progress p
+				JOIN enrollments e ON e.course_id = p.course_id AND e.student_id = p.student_id
+				WHERE p.started_at >= :since
+				UNION ALL
+				SELECT
+					p.course_id,
+					COALESCE(e.cohort_id, ''),
+					p.lesson_id,
+					DATE(p.completed_at),
+					0,
+					1
+				FROM lesson_progress p
+				JOIN enrollments e ON e.course_id = p.course_id AND e.student_id = p.student_id
+				WHERE p.completed_at >= :since
+			) AS events
+			GROUP BY course_id, cohort_id, lesson_id, day
+		`,
+
+		selectLessonProgressCounts: `
+			SELECT
+				lesson_id,
+				SUM(started) AS started,
+				SUM(completed) AS completed
+			FROM analytics_lesson_progress_daily
+		`,
+
+		deleteCompletionTimeDays: `
+			DELETE FROM analytics_completion_times_daily
+			WHERE day >= :since
+		`,
+
+		aggregateCompletionTimeDays: `
+			INSERT INTO analytics_completion_times_daily (
+				course_id,
+				cohort_id,
+				day,
+				completions,
+				total_seconds
+			)
+			SELECT course_id, cohort_id, day, COUNT(*), SUM(seconds)
+			FROM (
+				SELECT
+					c.course_id,
+					COALESCE(e.cohort_id, '') AS cohort_id,
+					DATE(c.completed_at) AS day,
+					GREATEST(TIMESTAMPDIFF(SECOND, e.created_at, c.completed_at), 0) AS seconds
+				FROM certificates c
+				JOIN enrollments e ON e.course_id = c.course_id AND e.student_id = c.student_id
+				WHERE c.revoked_at IS NULL AND c.completed_at >= :since
+			) AS completions
+			GROUP BY course_id, cohort_id, day
+		`,
+
+		selectCompletionTimeDays: `
+			SELECT
+				day,
+				SUM(completions) AS completions,
+				SUM(total_seconds) AS total_seconds
+			FROM analytics_completion_times_daily
+		`,
+
+		deleteQuizScoreDays: `
+			DELETE FROM analytics_quiz_scores_daily
+			WHERE day >= :since
+		`,
+
+		aggregateQuizScoreDays: `
+			INSERT INTO analytics_quiz_scores_daily (
+				course_id,
+				cohort_id,
+				quiz_id,
+				day,
+				bucket,
+				attempts
+			)
+			SELECT course_id, cohort_id, quiz_id, day, bucket, COUNT(*)
+			FROM (
+				SELECT
+					q.course_id,
+					COALESCE(e.cohort_id, '') AS cohort_id,
+					a.quiz_id,
+					DATE(a.submitted_at) AS day,
+					LEAST(FLOOR(a.score * :buckets / a.max_score), :buckets - 1) AS bucket
+				FROM quiz_attempts a
+				JOIN quizzes q ON q.id = a.quiz_id
+				JOIN enrollments e ON e.course_id = q.course_id AND e.student_id = a.student_id
+				WHERE a.status = 'submitted' AND a.max_score > 0 AND a.submitted_at >= :since
+			) AS attempts
+			GROUP BY course_id, cohort_id, quiz_id, day, bucket
+		`,
+
+		selectQuizScoreBuckets: `
+			SELECT
+				quiz_id,
+				bucket,
+				SUM(attempts) AS attempts
+			FROM analytics_quiz_scores_daily
+		`,
+
+		// Lesson progress only keeps its latest update, so a day's count is
+		// never lowered by aggregating it again after a student moved on.
+		aggregateActiveLearnersDays: `
+			INSERT INTO analytics_active_learners_daily (
+				course_id,
+				cohort_id,
+				day,
+				active_learners
+			)
+			SELECT course_id, cohort_id, day, COUNT(DISTINCT student_id)
+			FROM (
+				SELECT
+					activity.course_id,
+					COALESCE(e.cohort_id, '') AS cohort_id,
+					activity.day,
+					activity.student_id
+				FROM (
+					SELECT course_id, student_id, DATE(started_at) AS day
+					FROM lesson_progress
+					WHERE started_at >= :since
+					UNION ALL
+					SELECT course_id, student_id, DATE(updated_at)
+					FROM lesson_progress
+					WHERE updated_at >= :since
+					UNION ALL
+					SELECT course_id, student_id, DATE(completed_at)
+					FROM lesson_progress
+					WHERE completed_at >= :since
+					UNION ALL
+					SELECT q.course_id, a.student_id, DATE(a.started_at)
+					FROM quiz_attempts a
+					JOIN quizzes q ON q.id = a.quiz_id
+					WHERE a.started_at >= :since
+					UNION ALL
+					SELECT q.course_id, a.student_id, DATE(a.submitted_at)
+					FROM quiz_attempts a
+					JOIN quizzes q ON q.id = a.quiz_id
+					WHERE a.submitted_at >= :since
+					UNION ALL
+					SELECT course_id, student_id, DATE(submitted_at)
+					FROM submissions
+					WHERE submitted_at >= :since
+					UNION ALL
+					SELECT t.course_id, p.created_by, DATE(p.created_at)
+					FROM discussion_posts p
+					JOIN discussion_threads t ON t.id = p.thread_id
+					WHERE p.created_at >= :since
+				) AS activity
+				JOIN enrollments e ON e.course_id = activity.course_id AND e.student_id = activity.student_id
+			) AS learners
+			GROUP BY course_id, cohort_id, day
+			ON DUPLICATE KEY UPDATE
+				active_learners = GREATEST(active_learners, VALUES(active_learners))
+		`,
+
+		selectActiveLearnersDays: `
+			SELECT
+				day,
+				SUM(active_learners) AS active_learners
+			FROM analytics_active_learners_daily
+		`,
+	}
+)
+
+// AnalyticsRepository is the repository for the aggregated learning analytics
+// of Courses.
+type AnalyticsRepository interface {
+	AggregateAnalytics(since time.Time) (err error)
+	ResolveActiveLearnersDays(params AnalyticsQueryParameters) (days []ActiveLearnersDay, err error)
+	ResolveCompletionTimeDays(params AnalyticsQueryParameters) (days []CompletionTimeDay, err error)
+	ResolveEnrollmentsDays(params AnalyticsQueryParameters) (days []EnrollmentsDay, err error)
+	ResolveLessonProgressCounts(params AnalyticsQueryParameters) (counts []LessonProgressCount, err error)
+	ResolveQuizScoreBuckets(params AnalyticsQueryParameters) (buckets []QuizScoreBucket, err error)
+}
+
+// AnalyticsRepositoryMySQL is the MySQL-backed implementation of AnalyticsRepository.
+type AnalyticsRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideAnalyticsRepositoryMySQL is the provider for this repository.
+func ProvideAnalyticsRepositoryMySQL(db *infras.MySQLConn) *AnalyticsRepositoryMySQL {
+	s := new(AnalyticsRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// analyticsWindow is the named argument of the aggregation queries.
+type analyticsWindow struct {
+	Since   time.Time `db:"since"`
+	Buckets int       `db:"buckets"`
+}
+
+// AggregateAnalytics aggregates the activity of every Course from the start of
+// the given UTC day onwards, replacing what was aggregated for those days
+// before. Aggregating the same days again yields the same results, so running
+// it on several instances at once is safe.
+func (r *AnalyticsRepositoryMySQL) AggregateAnalytics(since time.Time) (err error) {
+	window := analyticsWindow{Since: analyticsDay(since), Buckets: QuizScoreBuckets}
+
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		for _, query := range []string{
+			analyticsQueries.deleteEnrollmentsDays,
+			analyticsQueries.aggregateEnrollmentsDays,
+			analyticsQueries.deleteLessonProgressDays,
+			analyticsQueries.aggregateLessonProgressDays,
+			analyticsQueries.deleteCompletionTimeDays,
+			analyticsQueries.aggregateCompletionTimeDays,
+			analyticsQueries.deleteQuizScoreDays,
+			analyticsQueries.aggregateQuizScoreDays,
+			analyticsQueries.aggregateActiveLearnersDays,
+		} {
+			if err := r.txExecNamed(tx, query, window); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveActiveLearnersDays resolves the aggregated ActiveLearnersDays of a
+// Course, oldest first. Days no one was active on are left out.
+func (r *AnalyticsRepositoryMySQL) ResolveActiveLearnersDays(params AnalyticsQueryParameters) (days []ActiveLearnersDay, err error) {
+	where, args := analyticsFilter(params)
+	err = r.DB.Read.Select(&days, analyticsQueries.selectActiveLearnersDays+where+" GROUP BY day ORDER BY day", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCompletionTimeDays resolves the aggregated CompletionTimeDays of a
+// Course, oldest first. Days no one completed it on are left out.
+func (r *AnalyticsRepositoryMySQL) ResolveCompletionTimeDays(params AnalyticsQueryParameters) (days []CompletionTimeDay, err error) {
+	where, args := analyticsFilter(params)
+	err = r.DB.Read.Select(&days, analyticsQueries.selectCompletionTimeDays+where+" GROUP BY day ORDER BY day", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveEnrollmentsDays resolves the aggregated EnrollmentsDays of a Course,
+// oldest first. Days nothing happened on are left out.
+func (r *AnalyticsRepositoryMySQL) ResolveEnrollmentsDays(params AnalyticsQueryParameters) (days []EnrollmentsDay, err error) {
+	where, args := analyticsFilter(params)
+	err = r.DB.Read.Select(&days, analyticsQueries.selectEnrollmentsDays+where+" GROUP BY day ORDER BY day", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveLessonProgressCounts resolves how many students started and completed
+// each Lesson of a Course over the given days. Lessons no one progressed
+// through are left out.
+func (r *AnalyticsRepositoryMySQL) ResolveLessonProgressCounts(params AnalyticsQueryParameters) (counts []LessonProgressCount, err error) {
+	where, args := analyticsFilter(params)
+	err = r.DB.Read.Select(&counts, analyticsQueries.selectLessonProgressCounts+where+" GROUP BY lesson_id", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveQuizScoreBuckets resolves the aggregated QuizScoreBuckets of the
+// Quizzes of a Course over the given days. Empty buckets are left out.
+func (r *AnalyticsRepositoryMySQL) ResolveQuizScoreBuckets(params AnalyticsQueryParameters) (buckets []QuizScoreBucket, err error) {
+	where, args := analyticsFilter(params)
+	err = r.DB.Read.Select(&buckets, analyticsQueries.selectQuizScoreBuckets+where+" GROUP BY quiz_id, bucket", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *AnalyticsRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// analyticsFilter composes the WHERE clause and arguments shared by the reads
+// of the aggregate tables.
+func analyticsFilter(params AnalyticsQueryParameters) (where string, args []interface{}) {
+	where = " WHERE course_id = ? AND day BETWEEN ? AND ?"
+	args = []interface{}{
+		params.CourseID.String(),
+		params.From.Format(analyticsDayFormat),
+		params.To.Format(analyticsDayFormat),
+	}
+
+	if params.CohortID.Valid {
+		where += " AND cohort_id = ?"
+		args = append(args, params.CohortID.UUID.String())
+	}
+
+	return
+}
@@ -0,0 +1,175 @@
+package course
+
+import (
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// AnalyticsService is the service interface for the learning analytics of
+// Courses.
+type AnalyticsService interface {
+	AggregateAnalytics(now time.Time, lookbackDays int) (err error)
+	ResolveActiveLearners(params AnalyticsQueryParameters, userID uuid.UUID) (days []ActiveLearnersDay, err error)
+	ResolveCompletionFunnel(params AnalyticsQueryParameters, userID uuid.UUID) (funnel []LessonFunnelStep, err error)
+	ResolveCompletionTime(params AnalyticsQueryParameters, userID uuid.UUID) (completionTime CompletionTime, err error)
+	ResolveEnrollmentsOverTime(params AnalyticsQueryParameters, userID uuid.UUID) (days []EnrollmentsDay, err error)
+	ResolveQuizScoreDistributions(params AnalyticsQueryParameters, userID uuid.UUID) (distributions []QuizScoreDistribution, err error)
+}
+
+// AnalyticsServiceImpl is the service implementation for the learning
+// analytics of Courses.
+type AnalyticsServiceImpl struct {
+	AnalyticsRepository    AnalyticsRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	ModuleRepository       ModuleRepository
+	QuizRepository         QuizRepository
+}
+
+// ProvideAnalyticsServiceImpl is the provider for this service.
+func ProvideAnalyticsServiceImpl(
+	analyticsRepository AnalyticsRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	moduleRepository ModuleRepository,
+	quizRepository QuizRepository) *AnalyticsServiceImpl {
+	s := new(AnalyticsServiceImpl)
+	s.AnalyticsRepository = analyticsRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.ModuleRepository = moduleRepository
+	s.QuizRepository = quizRepository
+
+	return s
+}
+
+// AggregateAnalytics aggregates the activity of every Course over today and
+// the lookbackDays days before it. Older days keep what they were last
+// aggregated with; a long lookback once backfills them.
+func (s *AnalyticsServiceImpl) AggregateAnalytics(now time.Time, lookbackDays int) (err error) {
+	if lookbackDays < 0 {
+		lookbackDays = 0
+	}
+
+	return s.AnalyticsRepository.AggregateAnalytics(analyticsDay(now).AddDate(0, 0, -lookbackDays))
+}
+
+// ResolveActiveLearners resolves how many students were active in a Course day
+// by day, for those allowed to view its students.
+func (s *AnalyticsServiceImpl) ResolveActiveLearners(params AnalyticsQueryParameters, userID uuid.UUID) (days []ActiveLearnersDay, err error) {
+	err = s.checkAnalyticsAccess(params, userID)
+	if err != nil {
+		return
+	}
+
+	days, err = s.AnalyticsRepository.ResolveActiveLearnersDays(params)
+	if err != nil {
+		return
+	}
+
+	return NewActiveLearnersSeries(params, days), nil
+}
+
+// ResolveCompletionFunnel resolves how far students got through each Lesson of
+// a Course, in course order, for those allowed to view its students.
+func (s *AnalyticsServiceImpl) ResolveCompletionFunnel(params AnalyticsQueryParameters, userID uuid.UUID) (funnel []LessonFunnelStep, err error) {
+	err = s.checkAnalyticsAccess(params, userID)
+	if err != nil {
+		return
+	}
+
+	course, err := attachCourseModules(s.ModuleRepository, Course{ID: params.CourseID})
+	if err != nil {
+		return
+	}
+
+	counts, err := s.AnalyticsRepository.ResolveLessonProgressCounts(params)
+	if err != nil {
+		return
+	}
+
+	return NewLessonFunnel(course.Modules, counts), nil
+}
+
+// ResolveCompletionTime resolves the average time students took to complete a
+// Course, for those allowed to view its students.
+func (s *AnalyticsServiceImpl) ResolveCompletionTime(params AnalyticsQueryParameters, userID uuid.UUID) (completionTime CompletionTime, err error) {
+	err = s.checkAnalyticsAccess(params, userID)
+	if err != nil {
+		return
+	}
+
+	days, err := s.AnalyticsRepository.ResolveCompletionTimeDays(params)
+	if err != nil {
+		return
+	}
+
+	return NewCompletionTime(params, days), nil
+}
+
+// ResolveEnrollmentsOverTime resolves the enrollments in and withdrawals from a
+// Course day by day, for those allowed to view its students.
+func (s *AnalyticsServiceImpl) ResolveEnrollmentsOverTime(params AnalyticsQueryParameters, userID uuid.UUID) (days []EnrollmentsDay, err error) {
+	err = s.checkAnalyticsAccess(params, userID)
+	if err != nil {
+		return
+	}
+
+	days, err = s.AnalyticsRepository.ResolveEnrollmentsDays(params)
+	if err != nil {
+		return
+	}
+
+	return NewEnrollmentsSeries(params, days), nil
+}
+
+// ResolveQuizScoreDistributions resolves how the scores of the active Quizzes
+// of a Course are distributed, for those allowed to view its students.
+func (s *AnalyticsServiceImpl) ResolveQuizScoreDistributions(params AnalyticsQueryParameters, userID uuid.UUID) (distributions []QuizScoreDistribution, err error) {
+	err = s.checkAnalyticsAccess(params, userID)
+	if err != nil {
+		return
+	}
+
+	quizzes, err := s.QuizRepository.ResolveQuizzesByCourseID(params.CourseID)
+	if err != nil {
+		return
+	}
+
+	buckets, err := s.AnalyticsRepository.ResolveQuizScoreBuckets(params)
+	if err != nil {
+		return
+	}
+
+	return NewQuizScoreDistributions(quizzes, buckets), nil
+}
+
+// checkAnalyticsAccess makes sure the given user may view the students of the
+// Course the parameters filter by, and that the Cohort they filter by, if any,
+// is one of its own.
+func (s *AnalyticsServiceImpl) checkAnalyticsAccess(params AnalyticsQueryParameters, userID uuid.UUID) (err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, params.CourseID, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	if !params.CohortID.Valid {
+		return
+	}
+
+	cohort, err := resolveCohort(s.CohortRepository, params.CohortID.UUID)
+	if err != nil {
+		return
+	}
+
+	if cohort.CourseID != course.ID {
+		return failure.NotFound("cohort")
+	}
+
+	return
+}
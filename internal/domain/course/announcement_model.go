@@ -0,0 +1,307 @@
+package course
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+const (
+	// AnnouncementPostedEventType is the type of the event published when an
+	// Announcement is posted.
+	AnnouncementPostedEventType = "evm.boilerplate-go.announcement-posted.fifo"
+
+	// announcementRecipientBatchSize is the maximum number of recipients
+	// listed in a single AnnouncementPostedEvent. Larger audiences are split
+	// over several events.
+	announcementRecipientBatchSize = 500
+)
+
+//// Announcements
+
+// Announcement is a message posted by the people teaching a Course to every
+// student actively enrolled in it, or only to those of one of its Cohorts.
+type Announcement struct {
+	ID       uuid.UUID   `db:"id" validate:"required"`
+	CourseID uuid.UUID   `db:"course_id" validate:"required"`
+	CohortID nuuid.NUUID `db:"cohort_id"`
+	Title    string      `db:"title" validate:"required,max=255"`
+	Body     string      `db:"body" validate:"required,max=10000"`
+	// RecipientCount is the number of students the Announcement was
+	// delivered to when it was posted.
+	RecipientCount int         `db:"recipient_count" validate:"min=0"`
+	CreatedAt      time.Time   `db:"created_at" validate:"required"`
+	CreatedBy      uuid.UUID   `db:"created_by" validate:"required"`
+	DeletedAt      null.Time   `db:"deleted_at"`
+	DeletedBy      nuuid.NUUID `db:"deleted_by"`
+	// Read tells whether the student reading the Announcement has read it.
+	// It is only set for students.
+	Read null.Bool `db:"-"`
+}
+
+// AnnouncementQueryParameters filters the Announcements of a Course.
+type AnnouncementQueryParameters struct {
+	CourseID uuid.UUID
+	// CohortID limits the Announcements to those posted to a Cohort.
+	CohortID nuuid.NUUID
+	// IncludeCourseWide includes the Announcements posted to the whole Course.
+	// Without a CohortID, it limits the Announcements to those.
+	IncludeCourseWide bool
+	Page              int `validate:"min=0"`
+	Limit             int `validate:"min=1,max=100"`
+}
+
+// Offset returns the number of Announcements skipped before the current page.
+func (p AnnouncementQueryParameters) Offset() int {
+	return p.Page * p.Limit
+}
+
+// Validate validates the query parameters.
+func (p *AnnouncementQueryParameters) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(p)
+}
+
+// NewAnnouncementFromRequestFormat creates an Announcement of a Course. The
+// Cohort, if any, must belong to the Course.
+func (a Announcement) NewAnnouncementFromRequestFormat(course Course, cohort *Cohort, req AnnouncementRequestFormat, userID uuid.UUID) (newAnnouncement Announcement, err error) {
+	announcementID, _ := uuid.NewV4()
+	newAnnouncement = Announcement{
+		ID:        announcementID,
+		CourseID:  course.ID,
+		Title:     strings.TrimSpace(req.Title),
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	if cohort != nil {
+		if cohort.CourseID != course.ID {
+			return newAnnouncement, failure.BadRequestFromString("the cohort does not belong to this course")
+		}
+
+		newAnnouncement.CohortID = nuuid.From(cohort.ID)
+	}
+
+	err = newAnnouncement.Validate()
+	if err != nil {
+		return newAnnouncement, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// IsDeleted checks whether an Announcement is soft deleted.
+func (a *Announcement) IsDeleted() bool {
+	return a.DeletedAt.Valid
+}
+
+// IsFor checks whether an Enrollment puts a student in the audience of an
+// Announcement.
+func (a *Announcement) IsFor(enrollment Enrollment) bool {
+	if enrollment.CourseID != a.CourseID || !enrollment.IsActive() {
+		return false
+	}
+
+	return !a.CohortID.Valid || enrollment.CohortID == a.CohortID
+}
+
+// SoftDelete marks an Announcement as deleted.
+func (a *Announcement) SoftDelete(userID uuid.UUID) (err error) {
+	if a.IsDeleted() {
+		return failure.Conflict("softDelete", "announcement", "already marked as deleted")
+	}
+
+	a.DeletedAt = null.TimeFrom(time.Now())
+	a.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// Validate validates the entity.
+func (a *Announcement) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(a)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (a Announcement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ToResponseFormat())
+}
+
+// ToResponseFormat converts this Announcement to its response format.
+func (a Announcement) ToResponseFormat() AnnouncementResponseFormat {
+	return AnnouncementResponseFormat{
+		ID:             a.ID,
+		CourseID:       a.CourseID,
+		CohortID:       a.CohortID.Ptr(),
+		Title:          a.Title,
+		Body:           a.Body,
+		RecipientCount: a.RecipientCount,
+		Read:           a.Read,
+		CreatedAt:      a.CreatedAt,
+		CreatedBy:      a.CreatedBy,
+	}
+}
+
+// AnnouncementPostedEvent asks downstream notification services to deliver
+// an Announcement to a batch of its recipients.
+type AnnouncementPostedEvent struct {
+	AnnouncementID uuid.UUID   `json:"announcementID"`
+	CourseID       uuid.UUID   `json:"courseID"`
+	CohortID       *uuid.UUID  `json:"cohortID"`
+	Title          string      `json:"title"`
+	Body           string      `json:"body"`
+	PostedBy       uuid.UUID   `json:"postedBy"`
+	PostedAt       time.Time   `json:"postedAt"`
+	RecipientIDs   []uuid.UUID `json:"recipientIDs"`
+	Batch          int         `json:"batch"`
+	BatchCount     int         `json:"batchCount"`
+}
+
+// NewAnnouncementPostedEvents splits the recipients of an Announcement into
+// batches, one AnnouncementPostedEvent each.
+func NewAnnouncementPostedEvents(announcement Announcement, recipientIDs []uuid.UUID) (events []AnnouncementPostedEvent) {
+	batchCount := (len(recipientIDs) + announcementRecipientBatchSize - 1) / announcementRecipientBatchSize
+	for batch := 0; batch < batchCount; batch++ {
+		start := batch * announcementRecipientBatchSize
+		end := start + announcementRecipientBatchSize
+		if end > len(recipientIDs) {
+			end = len(recipientIDs)
+		}
+
+		events = append(events, AnnouncementPostedEvent{
+			AnnouncementID: announcement.ID,
+			CourseID:       announcement.CourseID,
+			CohortID:       announcement.CohortID.Ptr(),
+			Title:          announcement.Title,
+			Body:           announcement.Body,
+			PostedBy:       announcement.CreatedBy,
+			PostedAt:       announcement.CreatedAt,
+			RecipientIDs:   recipientIDs[start:end],
+			Batch:          batch + 1,
+			BatchCount:     batchCount,
+		})
+	}
+
+	return
+}
+
+// AnnouncementRequestFormat represents an Announcement's standard formatting for JSON deserializing.
+type AnnouncementRequestFormat struct {
+	CohortID nuuid.NUUID `json:"cohortID" swaggertype:"string"`
+	Title    string      `json:"title" validate:"required,max=255" example:"Week 3 live session moved"`
+	Body     string      `json:"body" validate:"required,max=10000" example:"This week's live session starts an hour later."`
+}
+
+// AnnouncementResponseFormat represents an Announcement's standard formatting for JSON serializing.
+type AnnouncementResponseFormat struct {
+	ID             uuid.UUID  `json:"id"`
+	CourseID       uuid.UUID  `json:"courseID"`
+	CohortID       *uuid.UUID `json:"cohortID"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	RecipientCount int        `json:"recipientCount"`
+	Read           null.Bool  `json:"read,omitempty" swaggertype:"boolean"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	CreatedBy      uuid.UUID  `json:"createdBy"`
+}
+
+//// Read Receipts
+
+// AnnouncementReceipt records a student reading an Announcement.
+type AnnouncementReceipt struct {
+	AnnouncementID uuid.UUID `db:"announcement_id"`
+	StudentID      uuid.UUID `db:"student_id"`
+	ReadAt         time.Time `db:"read_at"`
+}
+
+// NewAnnouncementReceipt creates the AnnouncementReceipt of a student
+// reading an Announcement.
+func NewAnnouncementReceipt(announcement Announcement, studentID uuid.UUID) AnnouncementReceipt {
+	return AnnouncementReceipt{
+		AnnouncementID: announcement.ID,
+		StudentID:      studentID,
+		ReadAt:         time.Now(),
+	}
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (r AnnouncementReceipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToResponseFormat())
+}
+
+// ToResponseFormat converts this AnnouncementReceipt to its response format.
+func (r AnnouncementReceipt) ToResponseFormat() AnnouncementReceiptResponseFormat {
+	return AnnouncementReceiptResponseFormat(r)
+}
+
+// AnnouncementReceiptResponseFormat represents an AnnouncementReceipt's standard formatting for JSON serializing.
+type AnnouncementReceiptResponseFormat struct {
+	AnnouncementID uuid.UUID `json:"announcementID"`
+	StudentID      uuid.UUID `json:"studentID"`
+	ReadAt         time.Time `json:"readAt"`
+}
+
+// AnnouncementReceiptsResponseFormat lists the AnnouncementReceipts of an
+// Announcement along with how many of its recipients have yet to read it.
+type AnnouncementReceiptsResponseFormat struct {
+	RecipientCount int                   `json:"recipientCount"`
+	ReadCount      int                   `json:"readCount"`
+	UnreadCount    int                   `json:"unreadCount"`
+	Receipts       []AnnouncementReceipt `json:"receipts"`
+}
+
+// NewAnnouncementReceiptsResponseFormat sums up the AnnouncementReceipts of
+// an Announcement.
+func NewAnnouncementReceiptsResponseFormat(announcement Announcement, receipts []AnnouncementReceipt) AnnouncementReceiptsResponseFormat {
+	if receipts == nil {
+		receipts = []AnnouncementReceipt{}
+	}
+
+	unread := announcement.RecipientCount - len(receipts)
+	if unread < 0 {
+		unread = 0
+	}
+
+	return AnnouncementReceiptsResponseFormat{
+		RecipientCount: announcement.RecipientCount,
+		ReadCount:      len(receipts),
+		UnreadCount:    unread,
+		Receipts:       receipts,
+	}
+}
+
+//// Unread Counts
+
+// UnreadAnnouncementCount is the number of Announcements of a Course a
+// student has yet to read.
+type UnreadAnnouncementCount struct {
+	CourseID uuid.UUID `db:"course_id" json:"courseID"`
+	Count    int       `db:"unread_count" json:"count"`
+}
+
+// UnreadAnnouncementsResponseFormat sums up the Announcements a student has
+// yet to read, over all of their Courses and per Course.
+type UnreadAnnouncementsResponseFormat struct {
+	Total   int                       `json:"total"`
+	Courses []UnreadAnnouncementCount `json:"courses"`
+}
+
+// NewUnreadAnnouncementsResponseFormat sums up the unread Announcements of a
+// student.
+func NewUnreadAnnouncementsResponseFormat(counts []UnreadAnnouncementCount) (res UnreadAnnouncementsResponseFormat) {
+	res.Courses = []UnreadAnnouncementCount{}
+	for _, count := range counts {
+		res.Total += count.Count
+		res.Courses = append(res.Courses, count)
+	}
+
+	return
+}
@@ -0,0 +1,80 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncement(t *testing.T) {
+	c := newDraftCourse()
+	cohort := course.Cohort{ID: getRandomUUID(), CourseID: c.ID}
+	req := course.AnnouncementRequestFormat{Title: " Week 3 ", Body: "The live session moves to Thursday."}
+
+	t.Run("announcements go to a cohort of their course", func(t *testing.T) {
+		announcement, err := course.Announcement{}.NewAnnouncementFromRequestFormat(c, &cohort, req, c.UserID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Week 3", announcement.Title)
+		assert.Equal(t, nuuid.From(cohort.ID), announcement.CohortID)
+
+		other := course.Cohort{ID: getRandomUUID(), CourseID: getRandomUUID()}
+		_, err = course.Announcement{}.NewAnnouncementFromRequestFormat(c, &other, req, c.UserID)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("announcements reach the active students of their audience", func(t *testing.T) {
+		courseWide, err := course.Announcement{}.NewAnnouncementFromRequestFormat(c, nil, req, c.UserID)
+		assert.NoError(t, err)
+		cohortOnly, err := course.Announcement{}.NewAnnouncementFromRequestFormat(c, &cohort, req, c.UserID)
+		assert.NoError(t, err)
+
+		inCohort := newEnrollment(c.ID, getRandomUUID(), course.EnrollmentStatusActive)
+		inCohort.CohortID = nuuid.From(cohort.ID)
+		outsideCohort := newEnrollment(c.ID, getRandomUUID(), course.EnrollmentStatusActive)
+		withdrawn := newEnrollment(c.ID, getRandomUUID(), course.EnrollmentStatusWithdrawn)
+
+		assert.True(t, courseWide.IsFor(inCohort))
+		assert.True(t, courseWide.IsFor(outsideCohort))
+		assert.False(t, courseWide.IsFor(withdrawn))
+		assert.True(t, cohortOnly.IsFor(inCohort))
+		assert.False(t, cohortOnly.IsFor(outsideCohort))
+	})
+
+	t.Run("recipients are split into batches", func(t *testing.T) {
+		announcement, err := course.Announcement{}.NewAnnouncementFromRequestFormat(c, nil, req, c.UserID)
+		assert.NoError(t, err)
+
+		recipientIDs := make([]uuid.UUID, 1201)
+		for i := range recipientIDs {
+			recipientIDs[i] = getRandomUUID()
+		}
+
+		events := course.NewAnnouncementPostedEvents(announcement, recipientIDs)
+
+		assert.Len(t, events, 3)
+		assert.Len(t, events[0].RecipientIDs, 500)
+		assert.Len(t, events[2].RecipientIDs, 201)
+		assert.Equal(t, 3, events[2].Batch)
+		assert.Equal(t, 3, events[2].BatchCount)
+		assert.Empty(t, course.NewAnnouncementPostedEvents(announcement, nil))
+	})
+
+	t.Run("receipts count the recipients yet to read", func(t *testing.T) {
+		announcement, err := course.Announcement{}.NewAnnouncementFromRequestFormat(c, nil, req, c.UserID)
+		assert.NoError(t, err)
+		announcement.RecipientCount = 3
+
+		res := course.NewAnnouncementReceiptsResponseFormat(announcement, []course.AnnouncementReceipt{
+			course.NewAnnouncementReceipt(announcement, getRandomUUID()),
+		})
+
+		assert.Equal(t, 1, res.ReadCount)
+		assert.Equal(t, 2, res.UnreadCount)
+	})
+}
@@ -0,0 +1,285 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source announcement_repository.go -destination mock/announcement_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	announcementQueries = struct {
+		selectAnnouncement string
+		insertAnnouncement string
+		updateAnnouncement string
+		insertReceipt      string
+		selectReceipts     string
+		selectReadIDs      string
+		countUnread        string
+	}{
+		selectAnnouncement: `
+			SELECT
+				id,
+				course_id,
+				cohort_id,
+				title,
+				body,
+				recipient_count,
+				created_at,
+				created_by,
+				deleted_at,
+				deleted_by
+			FROM announcements
+		`,
+
+		insertAnnouncement: `
+			INSERT INTO announcements (
+				id,
+				course_id,
+				cohort_id,
+				title,
+				body,
+				recipient_count,
+				created_at,
+				created_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:cohort_id,
+				:title,
+				:body,
+				:recipient_count,
+				:created_at,
+				:created_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		updateAnnouncement: `
+			UPDATE announcements
+			SET
+				title = :title,
+				body = :body,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		insertReceipt: `
+			INSERT IGNORE INTO announcement_reads (
+				announcement_id,
+				student_id,
+				read_at
+			) VALUES (
+				:announcement_id,
+				:student_id,
+				:read_at
+			)
+		`,
+
+		selectReceipts: `
+			SELECT
+				announcement_id,
+				student_id,
+				read_at
+			FROM announcement_reads
+			WHERE announcement_id = ?
+			ORDER BY read_at, student_id
+		`,
+
+		selectReadIDs: `
+			SELECT announcement_id
+			FROM announcement_reads
+			WHERE student_id = ? AND announcement_id IN (?)
+		`,
+
+		countUnread: `
+			SELECT
+				a.course_id,
+				COUNT(a.id) AS unread_count
+			FROM enrollments e
+			JOIN courses c ON c.id = e.course_id AND c.deleted_at IS NULL
+			JOIN announcements a ON a.course_id = e.course_id
+				AND (a.cohort_id IS NULL OR a.cohort_id = e.cohort_id)
+				AND a.deleted_at IS NULL
+			LEFT JOIN announcement_reads r ON r.announcement_id = a.id
+				AND r.student_id = e.student_id
+			WHERE e.student_id = ? AND e.status = ? AND r.announcement_id IS NULL
+			GROUP BY a.course_id
+			ORDER BY a.course_id
+		`,
+	}
+)
+
+// AnnouncementRepository is the repository for Announcements and their read receipts.
+type AnnouncementRepository interface {
+	CountUnread(studentID uuid.UUID) (counts []UnreadAnnouncementCount, err error)
+	CreateAnnouncement(announcement Announcement) (err error)
+	CreateReceipt(receipt AnnouncementReceipt) (err error)
+	ResolveAnnouncementByID(id uuid.UUID) (announcement Announcement, err error)
+	ResolveAnnouncements(params AnnouncementQueryParameters) (announcements []Announcement, err error)
+	ResolveReadIDs(studentID uuid.UUID, announcementIDs []uuid.UUID) (readIDs []uuid.UUID, err error)
+	ResolveReceipts(announcementID uuid.UUID) (receipts []AnnouncementReceipt, err error)
+	UpdateAnnouncement(announcement Announcement) (err error)
+}
+
+// AnnouncementRepositoryMySQL is the MySQL-backed implementation of AnnouncementRepository.
+type AnnouncementRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideAnnouncementRepositoryMySQL is the provider for this repository.
+func ProvideAnnouncementRepositoryMySQL(db *infras.MySQLConn) *AnnouncementRepositoryMySQL {
+	s := new(AnnouncementRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CountUnread counts, per Course, the Announcements a student actively
+// enrolled in it has yet to read.
+func (r *AnnouncementRepositoryMySQL) CountUnread(studentID uuid.UUID) (counts []UnreadAnnouncementCount, err error) {
+	err = r.DB.Read.Select(&counts, announcementQueries.countUnread, studentID.String(), EnrollmentStatusActive)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// CreateAnnouncement creates an Announcement.
+func (r *AnnouncementRepositoryMySQL) CreateAnnouncement(announcement Announcement) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, announcementQueries.insertAnnouncement, announcement); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateReceipt records a student reading an Announcement. Reading it again
+// keeps the first AnnouncementReceipt.
+func (r *AnnouncementRepositoryMySQL) CreateReceipt(receipt AnnouncementReceipt) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, announcementQueries.insertReceipt, receipt); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveAnnouncementByID resolves an Announcement by its ID.
+func (r *AnnouncementRepositoryMySQL) ResolveAnnouncementByID(id uuid.UUID) (announcement Announcement, err error) {
+	err = r.DB.Read.Get(&announcement, announcementQueries.selectAnnouncement+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("announcement")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAnnouncements resolves a page of the Announcements of a Course,
+// newest first.
+func (r *AnnouncementRepositoryMySQL) ResolveAnnouncements(params AnnouncementQueryParameters) (announcements []Announcement, err error) {
+	query := announcementQueries.selectAnnouncement + " WHERE course_id = ? AND deleted_at IS NULL"
+	args := []interface{}{params.CourseID.String()}
+
+	switch {
+	case params.CohortID.Valid && params.IncludeCourseWide:
+		query += " AND (cohort_id IS NULL OR cohort_id = ?)"
+		args = append(args, params.CohortID.UUID.String())
+	case params.CohortID.Valid:
+		query += " AND cohort_id = ?"
+		args = append(args, params.CohortID.UUID.String())
+	case params.IncludeCourseWide:
+		query += " AND cohort_id IS NULL"
+	}
+
+	query += " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	args = append(args, params.Limit, params.Offset())
+
+	err = r.DB.Read.Select(&announcements, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveReadIDs resolves which of the given Announcements a student read.
+func (r *AnnouncementRepositoryMySQL) ResolveReadIDs(studentID uuid.UUID, announcementIDs []uuid.UUID) (readIDs []uuid.UUID, err error) {
+	if len(announcementIDs) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(announcementQueries.selectReadIDs, studentID.String(), announcementIDs)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&readIDs, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveReceipts resolves the AnnouncementReceipts of an Announcement, in
+// the order it was read.
+func (r *AnnouncementRepositoryMySQL) ResolveReceipts(announcementID uuid.UUID) (receipts []AnnouncementReceipt, err error) {
+	err = r.DB.Read.Select(&receipts, announcementQueries.selectReceipts, announcementID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateAnnouncement updates an Announcement.
+func (r *AnnouncementRepositoryMySQL) UpdateAnnouncement(announcement Announcement) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, announcementQueries.updateAnnouncement, announcement); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *AnnouncementRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,321 @@
+package course
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/event/model"
+	"github.com/evermos/boilerplate-go/event/producer"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// AnnouncementService is the service interface for Announcements.
+type AnnouncementService interface {
+	CreateAnnouncement(courseID uuid.UUID, requestFormat AnnouncementRequestFormat, userID uuid.UUID) (announcement Announcement, err error)
+	DeleteAnnouncement(id uuid.UUID, userID uuid.UUID) (announcement Announcement, err error)
+	MarkAnnouncementRead(id uuid.UUID, studentID uuid.UUID) (receipt AnnouncementReceipt, err error)
+	ResolveAnnouncementByID(id uuid.UUID, userID uuid.UUID, role string) (announcement Announcement, err error)
+	ResolveAnnouncementReceipts(id uuid.UUID, userID uuid.UUID) (res AnnouncementReceiptsResponseFormat, err error)
+	ResolveAnnouncements(params AnnouncementQueryParameters, userID uuid.UUID, role string) (announcements []Announcement, err error)
+	ResolveUnreadAnnouncements(studentID uuid.UUID) (res UnreadAnnouncementsResponseFormat, err error)
+}
+
+// AnnouncementServiceImpl is the service implementation for Announcements.
+type AnnouncementServiceImpl struct {
+	AnnouncementRepository AnnouncementRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	Producer               producer.Producer
+	Config                 *configs.Config
+}
+
+// ProvideAnnouncementServiceImpl is the provider for this service.
+func ProvideAnnouncementServiceImpl(
+	announcementRepository AnnouncementRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	producer producer.Producer,
+	config *configs.Config) *AnnouncementServiceImpl {
+	s := new(AnnouncementServiceImpl)
+	s.AnnouncementRepository = announcementRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.Producer = producer
+	s.Config = config
+
+	return s
+}
+
+// CreateAnnouncement posts an Announcement to the students actively enrolled
+// in a Course, or in one of its Cohorts, and publishes it for delivery.
+func (s *AnnouncementServiceImpl) CreateAnnouncement(courseID uuid.UUID, requestFormat AnnouncementRequestFormat, userID uuid.UUID) (announcement Announcement, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPostAnnouncements)
+	if err != nil {
+		return
+	}
+
+	var cohort *Cohort
+	if requestFormat.CohortID.Valid {
+		resolved, err := resolveCohort(s.CohortRepository, requestFormat.CohortID.UUID)
+		if err != nil {
+			return announcement, err
+		}
+
+		cohort = &resolved
+	}
+
+	announcement, err = Announcement{}.NewAnnouncementFromRequestFormat(course, cohort, requestFormat, userID)
+	if err != nil {
+		return
+	}
+
+	enrollments, err := s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: course.ID,
+		CohortID: announcement.CohortID,
+		Status:   EnrollmentStatusActive,
+	})
+	if err != nil {
+		return
+	}
+
+	recipientIDs := make([]uuid.UUID, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		recipientIDs = append(recipientIDs, enrollment.StudentID)
+	}
+
+	announcement.RecipientCount = len(recipientIDs)
+	err = s.AnnouncementRepository.CreateAnnouncement(announcement)
+	if err != nil {
+		return
+	}
+
+	s.publishAnnouncement(announcement, recipientIDs)
+	return
+}
+
+// DeleteAnnouncement soft deletes an Announcement. Notifications already
+// delivered are not recalled.
+func (s *AnnouncementServiceImpl) DeleteAnnouncement(id uuid.UUID, userID uuid.UUID) (announcement Announcement, err error) {
+	announcement, err = s.resolveManagedAnnouncement(id, userID, PermissionPostAnnouncements)
+	if err != nil {
+		return
+	}
+
+	err = announcement.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AnnouncementRepository.UpdateAnnouncement(announcement)
+	return
+}
+
+// MarkAnnouncementRead records a student reading an Announcement posted to
+// them. Marking it read again keeps the first read time.
+func (s *AnnouncementServiceImpl) MarkAnnouncementRead(id uuid.UUID, studentID uuid.UUID) (receipt AnnouncementReceipt, err error) {
+	announcement, err := s.resolveStudentAnnouncement(id, studentID)
+	if err != nil {
+		return
+	}
+
+	receipt = NewAnnouncementReceipt(announcement, studentID)
+	err = s.AnnouncementRepository.CreateReceipt(receipt)
+	return
+}
+
+// ResolveAnnouncementByID resolves an Announcement. Students only see the
+// Announcements posted to them.
+func (s *AnnouncementServiceImpl) ResolveAnnouncementByID(id uuid.UUID, userID uuid.UUID, role string) (announcement Announcement, err error) {
+	if role == shared.RoleTeacher {
+		announcement, err = s.AnnouncementRepository.ResolveAnnouncementByID(id)
+		if err == nil && announcement.IsDeleted() {
+			err = failure.NotFound("announcement")
+		}
+
+		return
+	}
+
+	announcement, err = s.resolveStudentAnnouncement(id, userID)
+	if err != nil {
+		return
+	}
+
+	announcements := []Announcement{announcement}
+	err = s.markRead(userID, announcements)
+	return announcements[0], err
+}
+
+// ResolveAnnouncementReceipts resolves who read an Announcement, and how
+// many of its recipients have not yet.
+func (s *AnnouncementServiceImpl) ResolveAnnouncementReceipts(id uuid.UUID, userID uuid.UUID) (res AnnouncementReceiptsResponseFormat, err error) {
+	announcement, err := s.resolveManagedAnnouncement(id, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	receipts, err := s.AnnouncementRepository.ResolveReceipts(announcement.ID)
+	if err != nil {
+		return
+	}
+
+	return NewAnnouncementReceiptsResponseFormat(announcement, receipts), nil
+}
+
+// ResolveAnnouncements resolves a page of the Announcements of a Course.
+// Students only see those posted to the whole Course or to their Cohort,
+// each marked as read or not.
+func (s *AnnouncementServiceImpl) ResolveAnnouncements(params AnnouncementQueryParameters, userID uuid.UUID, role string) (announcements []Announcement, err error) {
+	err = params.Validate()
+	if err != nil {
+		return announcements, failure.BadRequest(err)
+	}
+
+	course, err := s.CourseRepository.ResolveCourseByID(params.CourseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() {
+		return announcements, failure.NotFound("course")
+	}
+
+	if role == shared.RoleTeacher {
+		return s.AnnouncementRepository.ResolveAnnouncements(params)
+	}
+
+	enrollment, err := s.resolveActiveEnrollment(course.ID, userID)
+	if err != nil {
+		return
+	}
+
+	params.CohortID = enrollment.CohortID
+	params.IncludeCourseWide = true
+	announcements, err = s.AnnouncementRepository.ResolveAnnouncements(params)
+	if err != nil {
+		return
+	}
+
+	err = s.markRead(userID, announcements)
+	return
+}
+
+// ResolveUnreadAnnouncements counts the Announcements a student has yet to
+// read, over all the Courses they are actively enrolled in.
+func (s *AnnouncementServiceImpl) ResolveUnreadAnnouncements(studentID uuid.UUID) (res UnreadAnnouncementsResponseFormat, err error) {
+	counts, err := s.AnnouncementRepository.CountUnread(studentID)
+	if err != nil {
+		return
+	}
+
+	return NewUnreadAnnouncementsResponseFormat(counts), nil
+}
+
+// markRead sets whether a student read each of the given Announcements.
+func (s *AnnouncementServiceImpl) markRead(studentID uuid.UUID, announcements []Announcement) (err error) {
+	ids := make([]uuid.UUID, 0, len(announcements))
+	for _, announcement := range announcements {
+		ids = append(ids, announcement.ID)
+	}
+
+	readIDs, err := s.AnnouncementRepository.ResolveReadIDs(studentID, ids)
+	if err != nil {
+		return
+	}
+
+	read := make(map[uuid.UUID]bool)
+	for _, id := range readIDs {
+		read[id] = true
+	}
+
+	for i := range announcements {
+		announcements[i].Read = null.BoolFrom(read[announcements[i].ID])
+	}
+
+	return
+}
+
+// publishAnnouncement publishes an Announcement for delivery to its
+// recipients, in batches. The Announcement is already saved, so failing to
+// publish it is logged rather than reported to the teacher.
+func (s *AnnouncementServiceImpl) publishAnnouncement(announcement Announcement, recipientIDs []uuid.UUID) {
+	topic := s.Config.Event.Producer.SNS.Topics.AnnouncementPosted
+	if !topic.Enabled {
+		return
+	}
+
+	messageGroupID := announcement.ID.String()
+	for _, event := range NewAnnouncementPostedEvents(announcement, recipientIDs) {
+		err := s.Producer.Publish(model.PublishRequest{
+			Event:          model.NewEvent(AnnouncementPostedEventType, event),
+			MessageGroupID: &messageGroupID,
+			Topic:          topic.ARN,
+		})
+		if err != nil {
+			logger.ErrorWithStack(err)
+		}
+	}
+}
+
+// resolveActiveEnrollment resolves the Enrollment of a student in a Course,
+// which has to be active.
+func (s *AnnouncementServiceImpl) resolveActiveEnrollment(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	if err != nil || !enrollment.IsActive() {
+		return enrollment, failure.Forbidden("an active enrollment is required to read this course")
+	}
+
+	return enrollment, nil
+}
+
+// resolveStudentAnnouncement resolves an Announcement posted to a student.
+func (s *AnnouncementServiceImpl) resolveStudentAnnouncement(id uuid.UUID, studentID uuid.UUID) (announcement Announcement, err error) {
+	announcement, err = s.AnnouncementRepository.ResolveAnnouncementByID(id)
+	if err != nil {
+		return
+	}
+
+	if announcement.IsDeleted() {
+		return announcement, failure.NotFound("announcement")
+	}
+
+	enrollment, err := s.resolveActiveEnrollment(announcement.CourseID, studentID)
+	if err != nil {
+		return
+	}
+
+	if !announcement.IsFor(enrollment) {
+		return announcement, failure.NotFound("announcement")
+	}
+
+	return
+}
+
+// resolveManagedAnnouncement resolves an Announcement on which the user is
+// allowed the given CoursePermission.
+func (s *AnnouncementServiceImpl) resolveManagedAnnouncement(id uuid.UUID, userID uuid.UUID, permission CoursePermission) (announcement Announcement, err error) {
+	announcement, err = s.AnnouncementRepository.ResolveAnnouncementByID(id)
+	if err != nil {
+		return
+	}
+
+	if announcement.IsDeleted() {
+		return announcement, failure.NotFound("announcement")
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, announcement.CourseID, userID, permission)
+	return
+}
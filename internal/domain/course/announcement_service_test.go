@@ -0,0 +1,149 @@
+package course_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/event/model"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingProducer records the events it is asked to publish.
+type recordingProducer struct {
+	requests []model.PublishRequest
+	err      error
+}
+
+func (p *recordingProducer) Publish(request model.PublishRequest) error {
+	p.requests = append(p.requests, request)
+	return p.err
+}
+
+func TestAnnouncementService(t *testing.T) {
+	config := &configs.Config{}
+	config.Event.Producer.SNS.Topics.AnnouncementPosted.Enabled = true
+	config.Event.Producer.SNS.Topics.AnnouncementPosted.ARN = "arn:aws:sns:ap-southeast-1:000000000000:announcement-posted.fifo"
+
+	c := newDraftCourse()
+	cohort := course.Cohort{ID: getRandomUUID(), CourseID: c.ID}
+	req := course.AnnouncementRequestFormat{Title: "Week 3", Body: "The live session moves to Thursday."}
+
+	t.Run("posting an announcement publishes it to its recipients", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAnnouncementRepo := course_mock.NewMockAnnouncementRepository(ctrl)
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		producer := &recordingProducer{err: errors.New("sns unavailable")}
+		s := course.ProvideAnnouncementServiceImpl(mockAnnouncementRepo, mockCohortRepo, nil, mockCourseRepo, mockEnrollmentRepo, producer, config)
+
+		var enrollments []course.Enrollment
+		for i := 0; i < 501; i++ {
+			enrollments = append(enrollments, newEnrollment(c.ID, getRandomUUID(), course.EnrollmentStatusActive))
+		}
+
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(c, nil)
+		mockCohortRepo.EXPECT().ResolveCohortByID(cohort.ID).Return(cohort, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollments(course.EnrollmentQueryParameters{
+			CourseID: c.ID,
+			CohortID: nuuid.From(cohort.ID),
+			Status:   course.EnrollmentStatusActive,
+		}).Return(enrollments, nil)
+		mockAnnouncementRepo.EXPECT().CreateAnnouncement(gomock.Any()).Return(nil)
+
+		cohortReq := req
+		cohortReq.CohortID = nuuid.From(cohort.ID)
+		announcement, err := s.CreateAnnouncement(c.ID, cohortReq, c.UserID)
+
+		// the announcement is saved even when publishing it fails
+		assert.NoError(t, err)
+		assert.Equal(t, 501, announcement.RecipientCount)
+		assert.Len(t, producer.requests, 2)
+		assert.Equal(t, announcement.ID.String(), *producer.requests[0].MessageGroupID)
+		assert.Equal(t, config.Event.Producer.SNS.Topics.AnnouncementPosted.ARN, producer.requests[0].Topic)
+	})
+
+	t.Run("teaching assistants cannot post announcements", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := course.ProvideAnnouncementServiceImpl(nil, nil, mockCollaboratorRepo, mockCourseRepo, nil, &recordingProducer{}, config)
+		assistantID := getRandomUUID()
+
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(c, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(c.ID, assistantID).Return(course.Collaborator{
+			CourseID: c.ID,
+			UserID:   assistantID,
+			Role:     course.CourseRoleTeachingAssistant,
+			Status:   course.CollaboratorStatusActive,
+		}, nil)
+
+		_, err := s.CreateAnnouncement(c.ID, req, assistantID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students list the announcements of their cohort with read flags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAnnouncementRepo := course_mock.NewMockAnnouncementRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideAnnouncementServiceImpl(mockAnnouncementRepo, nil, nil, mockCourseRepo, mockEnrollmentRepo, &recordingProducer{}, config)
+
+		studentID := getRandomUUID()
+		enrollment := newEnrollment(c.ID, studentID, course.EnrollmentStatusActive)
+		enrollment.CohortID = nuuid.From(cohort.ID)
+		read, _ := course.Announcement{}.NewAnnouncementFromRequestFormat(c, nil, req, c.UserID)
+		unread, _ := course.Announcement{}.NewAnnouncementFromRequestFormat(c, &cohort, req, c.UserID)
+
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(c, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(c.ID, studentID).Return(enrollment, nil)
+		mockAnnouncementRepo.EXPECT().ResolveAnnouncements(course.AnnouncementQueryParameters{
+			CourseID:          c.ID,
+			CohortID:          nuuid.From(cohort.ID),
+			IncludeCourseWide: true,
+			Limit:             20,
+		}).Return([]course.Announcement{unread, read}, nil)
+		mockAnnouncementRepo.EXPECT().ResolveReadIDs(studentID, []uuid.UUID{unread.ID, read.ID}).Return([]uuid.UUID{read.ID}, nil)
+
+		announcements, err := s.ResolveAnnouncements(course.AnnouncementQueryParameters{CourseID: c.ID, Limit: 20}, studentID, shared.RoleStudent)
+
+		assert.NoError(t, err)
+		assert.False(t, announcements[0].Read.Bool)
+		assert.True(t, announcements[1].Read.Bool)
+	})
+
+	t.Run("students cannot read announcements of another cohort", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAnnouncementRepo := course_mock.NewMockAnnouncementRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideAnnouncementServiceImpl(mockAnnouncementRepo, nil, nil, nil, mockEnrollmentRepo, &recordingProducer{}, config)
+
+		studentID := getRandomUUID()
+		announcement, _ := course.Announcement{}.NewAnnouncementFromRequestFormat(c, &cohort, req, c.UserID)
+
+		mockAnnouncementRepo.EXPECT().ResolveAnnouncementByID(announcement.ID).Return(announcement, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(c.ID, studentID).Return(newEnrollment(c.ID, studentID, course.EnrollmentStatusActive), nil)
+
+		_, err := s.MarkAnnouncementRead(announcement.ID, studentID)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
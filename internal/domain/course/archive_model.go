@@ -0,0 +1,628 @@
+package course
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+const (
+	// CourseArchiveFormat identifies course archives in their manifest.
+	CourseArchiveFormat = "evermos/course-archive"
+	// CourseArchiveVersion is the version of the archive format written on
+	// export. Archives of later versions cannot be imported.
+	CourseArchiveVersion = 1
+)
+
+// ImportConflictKind indicates how part of an imported CourseArchive did not
+// fit the system it was imported into.
+type ImportConflictKind string
+
+const (
+	// ImportConflictCategoryNotFound indicates a Category that doesn't exist
+	// here. The Course was imported without it.
+	ImportConflictCategoryNotFound ImportConflictKind = "category_not_found"
+	// ImportConflictCategoryMatchedByName indicates a Category that exists
+	// here under another ID, and was matched by its name instead.
+	ImportConflictCategoryMatchedByName ImportConflictKind = "category_matched_by_name"
+	// ImportConflictPrerequisiteNotFound indicates a prerequisite Course that
+	// doesn't exist here. The Course was imported without it.
+	ImportConflictPrerequisiteNotFound ImportConflictKind = "prerequisite_not_found"
+)
+
+//// Course Archive
+
+// CourseArchive is the portable content of a Course, stored as the
+// course.json of an archive: its details, taxonomy and prerequisites, and its
+// Modules with their Lessons, Quizzes and Attachments, in order. IDs are those
+// of the system it was exported from, and are replaced on import.
+type CourseArchive struct {
+	ID            uuid.UUID             `json:"id" validate:"required"`
+	Title         string                `json:"title" validate:"required"`
+	Content       string                `json:"content" validate:"required"`
+	SeatLimit     null.Int              `json:"seatLimit"`
+	Categories    []CategoryArchive     `json:"categories" validate:"dive"`
+	Tags          []string              `json:"tags"`
+	Prerequisites []PrerequisiteArchive `json:"prerequisites" validate:"dive"`
+	Modules       []ModuleArchive       `json:"modules" validate:"dive"`
+}
+
+// CategoryArchive is a Category a CourseArchive belongs to.
+type CategoryArchive struct {
+	ID   uuid.UUID `json:"id" validate:"required"`
+	Name string    `json:"name" validate:"required"`
+}
+
+// PrerequisiteArchive is a Course a CourseArchive requires.
+type PrerequisiteArchive struct {
+	ID    uuid.UUID `json:"id" validate:"required"`
+	Title string    `json:"title"`
+}
+
+// ModuleArchive is a Module of a CourseArchive.
+type ModuleArchive struct {
+	ID      uuid.UUID       `json:"id" validate:"required"`
+	Title   string          `json:"title" validate:"required"`
+	Lessons []LessonArchive `json:"lessons" validate:"dive"`
+}
+
+// LessonArchive is a Lesson of a CourseArchive.
+type LessonArchive struct {
+	ID          uuid.UUID           `json:"id" validate:"required"`
+	Title       string              `json:"title" validate:"required"`
+	Content     string              `json:"content"`
+	Quizzes     []QuizArchive       `json:"quizzes" validate:"dive"`
+	Attachments []AttachmentArchive `json:"attachments" validate:"dive"`
+}
+
+// QuizArchive is a Quiz of a CourseArchive, answer key included.
+type QuizArchive struct {
+	ID               uuid.UUID             `json:"id" validate:"required"`
+	Title            string                `json:"title" validate:"required"`
+	TimeLimitSeconds null.Int              `json:"timeLimitSeconds"`
+	MaxAttempts      null.Int              `json:"maxAttempts"`
+	ShuffleAnswers   bool                  `json:"shuffleAnswers"`
+	Questions        []QuizQuestionArchive `json:"questions" validate:"required,min=1,dive"`
+}
+
+// QuizQuestionArchive is a QuizQuestion of a CourseArchive.
+type QuizQuestionArchive struct {
+	ID      uuid.UUID           `json:"id" validate:"required"`
+	Type    QuestionType        `json:"type" validate:"required"`
+	Prompt  string              `json:"prompt" validate:"required"`
+	Answer  null.String         `json:"answer"`
+	Points  int                 `json:"points" validate:"min=1"`
+	Options []QuizOptionArchive `json:"options" validate:"dive"`
+}
+
+// QuizOptionArchive is a QuizOption of a CourseArchive.
+type QuizOptionArchive struct {
+	ID      uuid.UUID `json:"id" validate:"required"`
+	Text    string    `json:"text" validate:"required"`
+	Correct bool      `json:"correct"`
+}
+
+// AttachmentArchive is an Attachment of a CourseArchive. Its file is stored
+// in the archive under Path.
+type AttachmentArchive struct {
+	ID          uuid.UUID `json:"id" validate:"required"`
+	FileName    string    `json:"fileName" validate:"required"`
+	ContentType string    `json:"contentType" validate:"required"`
+	Size        int64     `json:"size" validate:"min=1"`
+	Checksum    string    `json:"checksum" validate:"required,len=64,hexadecimal"`
+	Path        string    `json:"path" validate:"required"`
+}
+
+// NewCourseArchive captures a Course, with its Modules and Lessons attached,
+// along with its taxonomy, the Courses it requires, and the Quizzes and
+// Attachments of its Lessons.
+func (a CourseArchive) NewCourseArchive(course Course, prerequisites []Course, quizzes []Quiz, attachments []Attachment) (archive CourseArchive) {
+	archive = CourseArchive{
+		ID:            course.ID,
+		Title:         course.Title,
+		Content:       course.Content,
+		SeatLimit:     course.SeatLimit,
+		Categories:    make([]CategoryArchive, 0, len(course.Categories)),
+		Tags:          make([]string, 0, len(course.Tags)),
+		Prerequisites: make([]PrerequisiteArchive, 0, len(prerequisites)),
+		Modules:       make([]ModuleArchive, 0, len(course.Modules)),
+	}
+
+	for _, category := range course.Categories {
+		archive.Categories = append(archive.Categories, CategoryArchive{ID: category.ID, Name: category.Name})
+	}
+
+	for _, tag := range course.Tags {
+		archive.Tags = append(archive.Tags, tag.Name)
+	}
+
+	for _, prerequisite := range prerequisites {
+		archive.Prerequisites = append(archive.Prerequisites, PrerequisiteArchive{ID: prerequisite.ID, Title: prerequisite.Title})
+	}
+
+	for _, module := range course.Modules {
+		moduleArchive := ModuleArchive{
+			ID:      module.ID,
+			Title:   module.Title,
+			Lessons: make([]LessonArchive, 0, len(module.Lessons)),
+		}
+
+		for _, lesson := range module.Lessons {
+			moduleArchive.Lessons = append(moduleArchive.Lessons, newLessonArchive(lesson, quizzes, attachments))
+		}
+
+		archive.Modules = append(archive.Modules, moduleArchive)
+	}
+
+	return
+}
+
+// NewCourseImport recreates the Course of this CourseArchive, owned by the
+// importing user, as a draft under new IDs. References to the archived IDs in
+// the content of the Course and its Lessons are replaced as well. Categories
+// are matched by ID, then by name, and prerequisites by ID; those not found
+// among the given ones are left out and reported as conflicts.
+func (a CourseArchive) NewCourseImport(categories CategoryTree, tags []Tag, prerequisites []Course, userID uuid.UUID) (courseImport CourseImport, err error) {
+	now := time.Now()
+	courseImport = CourseImport{
+		IDs:       make(map[uuid.UUID]uuid.UUID),
+		Files:     make(map[string]string),
+		Conflicts: make([]ImportConflict, 0),
+	}
+
+	courseImport.Course = Course{
+		ID:        courseImport.remap(a.ID),
+		UserID:    userID,
+		Title:     a.Title,
+		Content:   a.Content,
+		SeatLimit: a.SeatLimit,
+		Status:    CourseStatusDraft,
+		CreatedAt: now,
+		CreatedBy: userID,
+	}
+
+	for i, moduleArchive := range a.Modules {
+		module := Module{
+			ID:        courseImport.remap(moduleArchive.ID),
+			CourseID:  courseImport.Course.ID,
+			Title:     moduleArchive.Title,
+			Position:  i + 1,
+			CreatedAt: now,
+			CreatedBy: userID,
+		}
+
+		for j, lessonArchive := range moduleArchive.Lessons {
+			lesson := Lesson{
+				ID:        courseImport.remap(lessonArchive.ID),
+				ModuleID:  module.ID,
+				CourseID:  module.CourseID,
+				Title:     lessonArchive.Title,
+				Content:   lessonArchive.Content,
+				Position:  j + 1,
+				CreatedAt: now,
+				CreatedBy: userID,
+			}
+
+			err = courseImport.addLessonContent(lesson, lessonArchive, userID)
+			if err != nil {
+				return
+			}
+
+			module.Lessons = append(module.Lessons, lesson)
+		}
+
+		courseImport.Course.Modules = append(courseImport.Course.Modules, module)
+	}
+
+	courseImport.replaceReferences()
+	courseImport.matchCategories(a.Categories, categories)
+	courseImport.matchPrerequisites(a.Prerequisites, prerequisites, userID)
+
+	err = courseImport.matchTags(a.Tags, tags, userID)
+	if err != nil {
+		return
+	}
+
+	err = courseImport.Validate()
+	return
+}
+
+// Validate validates the entity.
+func (a *CourseArchive) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(a)
+}
+
+// newLessonArchive captures a Lesson with those of the given Quizzes and
+// Attachments that belong to it.
+func newLessonArchive(lesson Lesson, quizzes []Quiz, attachments []Attachment) (lessonArchive LessonArchive) {
+	lessonArchive = LessonArchive{
+		ID:          lesson.ID,
+		Title:       lesson.Title,
+		Content:     lesson.Content,
+		Quizzes:     make([]QuizArchive, 0),
+		Attachments: make([]AttachmentArchive, 0),
+	}
+
+	for _, quiz := range quizzes {
+		if quiz.LessonID != lesson.ID {
+			continue
+		}
+
+		quizArchive := QuizArchive{
+			ID:               quiz.ID,
+			Title:            quiz.Title,
+			TimeLimitSeconds: quiz.TimeLimitSeconds,
+			MaxAttempts:      quiz.MaxAttempts,
+			ShuffleAnswers:   quiz.ShuffleAnswers,
+			Questions:        make([]QuizQuestionArchive, 0, len(quiz.Questions)),
+		}
+
+		for _, question := range quiz.Questions {
+			questionArchive := QuizQuestionArchive{
+				ID:      question.ID,
+				Type:    question.Type,
+				Prompt:  question.Prompt,
+				Answer:  question.AnswerText,
+				Points:  question.Points,
+				Options: make([]QuizOptionArchive, 0, len(question.Options)),
+			}
+
+			for _, option := range question.Options {
+				questionArchive.Options = append(questionArchive.Options, QuizOptionArchive{
+					ID:      option.ID,
+					Text:    option.Text,
+					Correct: option.IsCorrect,
+				})
+			}
+
+			quizArchive.Questions = append(quizArchive.Questions, questionArchive)
+		}
+
+		lessonArchive.Quizzes = append(lessonArchive.Quizzes, quizArchive)
+	}
+
+	for _, attachment := range attachments {
+		if attachment.LessonID != lesson.ID {
+			continue
+		}
+
+		lessonArchive.Attachments = append(lessonArchive.Attachments, AttachmentArchive{
+			ID:          attachment.ID,
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Checksum:    attachment.Checksum,
+			Path:        courseArchiveAttachmentPath(attachment.ID),
+		})
+	}
+
+	return
+}
+
+// courseArchiveAttachmentPath is the path the file of an Attachment is stored
+// under in an archive.
+func courseArchiveAttachmentPath(id uuid.UUID) string {
+	return "attachments/" + id.String()
+}
+
+//// Course Archive Manifest
+
+// CourseArchiveManifest is the manifest.json of an archive. It identifies the
+// format and version of the archive, and lists every other file in it with its
+// size and checksum.
+type CourseArchiveManifest struct {
+	Format     string              `json:"format" validate:"required"`
+	Version    int                 `json:"version" validate:"min=1"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	ExportedBy uuid.UUID           `json:"exportedBy"`
+	Files      []CourseArchiveFile `json:"files" validate:"required,min=1,dive"`
+}
+
+// CourseArchiveFile is a file listed in a CourseArchiveManifest.
+type CourseArchiveFile struct {
+	Path string `json:"path" validate:"required"`
+	Size int64  `json:"size" validate:"min=0"`
+	// Checksum is the hex-encoded SHA-256 digest of the file.
+	Checksum string `json:"checksum" validate:"required,len=64,hexadecimal"`
+}
+
+// Validate validates the manifest, and checks an archive of its format and
+// version can be imported.
+func (m *CourseArchiveManifest) Validate() (err error) {
+	if m.Format != CourseArchiveFormat {
+		return fmt.Errorf("the archive is not a course archive")
+	}
+
+	if m.Version > CourseArchiveVersion {
+		return fmt.Errorf("course archives of version %d are not supported, only up to version %d", m.Version, CourseArchiveVersion)
+	}
+
+	validator := shared.GetValidator()
+	return validator.Struct(m)
+}
+
+//// Course Import
+
+// CourseImport is the Course of a CourseArchive recreated under new IDs, with
+// everything to be created along with it.
+type CourseImport struct {
+	// Course has its Modules and Lessons attached.
+	Course        Course
+	Quizzes       []Quiz
+	Attachments   []Attachment
+	CategoryIDs   []uuid.UUID
+	NewTags       []Tag
+	TagIDs        []uuid.UUID
+	Prerequisites []CoursePrerequisite
+	// IDs maps the archived IDs to the new ones.
+	IDs       map[uuid.UUID]uuid.UUID
+	Conflicts []ImportConflict
+	// Files maps the StorageKey of every Attachment to the path of its file in
+	// the archive.
+	Files map[string]string
+}
+
+// ImportConflict is a part of an imported CourseArchive that did not fit the
+// system it was imported into.
+type ImportConflict struct {
+	Kind    ImportConflictKind `json:"kind"`
+	ID      uuid.UUID          `json:"id"`
+	Name    string             `json:"name"`
+	Message string             `json:"message"`
+}
+
+// ToResponseFormat converts this CourseImport to its response format.
+func (i CourseImport) ToResponseFormat() CourseImportResponseFormat {
+	return CourseImportResponseFormat{
+		Course:    i.Course.ToResponseFormat(),
+		IDs:       i.IDs,
+		Conflicts: i.Conflicts,
+	}
+}
+
+// Validate validates every entity to be created.
+func (i *CourseImport) Validate() (err error) {
+	err = i.Course.Validate()
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	for _, module := range i.Course.Modules {
+		err = module.Validate()
+		if err != nil {
+			return failure.BadRequest(err)
+		}
+
+		for _, lesson := range module.Lessons {
+			err = lesson.Validate()
+			if err != nil {
+				return failure.BadRequest(err)
+			}
+		}
+	}
+
+	for _, quiz := range i.Quizzes {
+		err = quiz.Validate()
+		if err != nil {
+			return failure.BadRequest(fmt.Errorf("quiz %q: %w", quiz.Title, err))
+		}
+	}
+
+	for _, attachment := range i.Attachments {
+		err = attachment.Validate()
+		if err != nil {
+			return failure.BadRequest(err)
+		}
+	}
+
+	return
+}
+
+// addLessonContent adds the Quizzes and Attachments of an archived Lesson.
+func (i *CourseImport) addLessonContent(lesson Lesson, lessonArchive LessonArchive, userID uuid.UUID) (err error) {
+	for _, quizArchive := range lessonArchive.Quizzes {
+		quiz := Quiz{
+			ID:               i.remap(quizArchive.ID),
+			CourseID:         lesson.CourseID,
+			LessonID:         lesson.ID,
+			Title:            quizArchive.Title,
+			TimeLimitSeconds: quizArchive.TimeLimitSeconds,
+			MaxAttempts:      quizArchive.MaxAttempts,
+			ShuffleAnswers:   quizArchive.ShuffleAnswers,
+			CreatedAt:        lesson.CreatedAt,
+			CreatedBy:        userID,
+		}
+
+		for j, questionArchive := range quizArchive.Questions {
+			question := QuizQuestion{
+				ID:         i.remap(questionArchive.ID),
+				QuizID:     quiz.ID,
+				Type:       questionArchive.Type,
+				Prompt:     questionArchive.Prompt,
+				AnswerText: questionArchive.Answer,
+				Points:     questionArchive.Points,
+				Position:   j + 1,
+			}
+
+			for k, optionArchive := range questionArchive.Options {
+				question.Options = append(question.Options, QuizOption{
+					ID:         i.remap(optionArchive.ID),
+					QuestionID: question.ID,
+					Text:       optionArchive.Text,
+					IsCorrect:  optionArchive.Correct,
+					Position:   k + 1,
+				})
+			}
+
+			quiz.Questions = append(quiz.Questions, question)
+		}
+
+		i.Quizzes = append(i.Quizzes, quiz)
+	}
+
+	for _, attachmentArchive := range lessonArchive.Attachments {
+		if !attachmentContentTypes[attachmentArchive.ContentType] {
+			return failure.BadRequestFromString(fmt.Sprintf("files of type %s cannot be attached", attachmentArchive.ContentType))
+		}
+
+		attachmentID := i.remap(attachmentArchive.ID)
+		attachment := Attachment{
+			ID:          attachmentID,
+			LessonID:    lesson.ID,
+			CourseID:    lesson.CourseID,
+			FileName:    sanitizeFileName(attachmentArchive.FileName),
+			ContentType: attachmentArchive.ContentType,
+			Size:        attachmentArchive.Size,
+			Checksum:    attachmentArchive.Checksum,
+			StorageKey:  attachmentStorageKey(lesson, attachmentID),
+			CreatedAt:   lesson.CreatedAt,
+			CreatedBy:   userID,
+		}
+
+		i.Attachments = append(i.Attachments, attachment)
+		i.Files[attachment.StorageKey] = attachmentArchive.Path
+	}
+
+	return
+}
+
+// matchCategories keeps the archived Categories found among the given ones.
+func (i *CourseImport) matchCategories(archived []CategoryArchive, categories CategoryTree) {
+	linked := make(map[uuid.UUID]bool)
+	link := func(id uuid.UUID) {
+		if !linked[id] {
+			linked[id] = true
+			i.CategoryIDs = append(i.CategoryIDs, id)
+		}
+	}
+
+	for _, categoryArchive := range archived {
+		if category, ok := categories.find(categoryArchive.ID); ok {
+			link(category.ID)
+			continue
+		}
+
+		matched := false
+		for _, category := range categories {
+			if strings.EqualFold(category.Name, categoryArchive.Name) {
+				link(category.ID)
+				i.Conflicts = append(i.Conflicts, ImportConflict{
+					Kind:    ImportConflictCategoryMatchedByName,
+					ID:      categoryArchive.ID,
+					Name:    categoryArchive.Name,
+					Message: fmt.Sprintf("matched the category %s by its name", category.ID),
+				})
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			i.Conflicts = append(i.Conflicts, ImportConflict{
+				Kind:    ImportConflictCategoryNotFound,
+				ID:      categoryArchive.ID,
+				Name:    categoryArchive.Name,
+				Message: "the category does not exist and was left out",
+			})
+		}
+	}
+}
+
+// matchPrerequisites keeps the archived prerequisites found among the given
+// Courses.
+func (i *CourseImport) matchPrerequisites(archived []PrerequisiteArchive, prerequisites []Course, userID uuid.UUID) {
+	found := make(map[uuid.UUID]bool)
+	for _, prerequisite := range prerequisites {
+		found[prerequisite.ID] = true
+	}
+
+	for _, prerequisiteArchive := range archived {
+		if !found[prerequisiteArchive.ID] {
+			i.Conflicts = append(i.Conflicts, ImportConflict{
+				Kind:    ImportConflictPrerequisiteNotFound,
+				ID:      prerequisiteArchive.ID,
+				Name:    prerequisiteArchive.Title,
+				Message: "the prerequisite course does not exist and was left out",
+			})
+			continue
+		}
+
+		i.Prerequisites = append(i.Prerequisites, CoursePrerequisite{
+			CourseID:       i.Course.ID,
+			PrerequisiteID: prerequisiteArchive.ID,
+			CreatedAt:      i.Course.CreatedAt,
+			CreatedBy:      userID,
+		})
+	}
+}
+
+// matchTags links the archived Tags, creating those not among the given ones.
+func (i *CourseImport) matchTags(archived []string, tags []Tag, userID uuid.UUID) (err error) {
+	existing := make(map[string]uuid.UUID)
+	for _, tag := range tags {
+		existing[tag.Name] = tag.ID
+	}
+
+	for _, name := range NormalizeTagNames(archived) {
+		if id, ok := existing[name]; ok {
+			i.TagIDs = append(i.TagIDs, id)
+			continue
+		}
+
+		var tag Tag
+		tag, err = tag.NewTag(name, userID)
+		if err != nil {
+			return failure.BadRequest(err)
+		}
+
+		i.NewTags = append(i.NewTags, tag)
+		i.TagIDs = append(i.TagIDs, tag.ID)
+	}
+
+	return
+}
+
+// remap returns a new ID for an archived one, and records it.
+func (i *CourseImport) remap(archivedID uuid.UUID) uuid.UUID {
+	id, _ := uuid.NewV4()
+	i.IDs[archivedID] = id
+	return id
+}
+
+// replaceReferences replaces the archived IDs mentioned in the content of the
+// Course and its Lessons, such as in links between Lessons, with the new IDs.
+func (i *CourseImport) replaceReferences() {
+	replaceIDReferences(&i.Course, i.IDs)
+}
+
+// replaceIDReferences replaces the IDs mentioned in the content of a Course and
+// its Lessons with those they map to.
+func replaceIDReferences(course *Course, ids map[uuid.UUID]uuid.UUID) {
+	pairs := make([]string, 0, 2*len(ids))
+	for oldID, id := range ids {
+		pairs = append(pairs, oldID.String(), id.String())
+	}
+
+	replacer := strings.NewReplacer(pairs...)
+	course.Content = replacer.Replace(course.Content)
+	for j := range course.Modules {
+		for k := range course.Modules[j].Lessons {
+			lesson := &course.Modules[j].Lessons[k]
+			lesson.Content = replacer.Replace(lesson.Content)
+		}
+	}
+}
+
+// CourseImportResponseFormat represents a CourseImport's standard formatting
+// for JSON serializing.
+type CourseImportResponseFormat struct {
+	Course    CourseResponseFormat    `json:"course"`
+	IDs       map[uuid.UUID]uuid.UUID `json:"ids" swaggertype:"object,string"`
+	Conflicts []ImportConflict        `json:"conflicts"`
+}
@@ -0,0 +1,167 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source archive_repository.go -destination mock/archive_repository_mock.go -package course_mock
+
+import (
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// ArchiveRepository is the repository for Courses imported from archives.
+type ArchiveRepository interface {
+	CreateCourseImport(courseImport CourseImport) (err error)
+}
+
+// ArchiveRepositoryMySQL is the MySQL-backed implementation of ArchiveRepository.
+type ArchiveRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideArchiveRepositoryMySQL is the provider for this repository.
+func ProvideArchiveRepositoryMySQL(db *infras.MySQLConn) *ArchiveRepositoryMySQL {
+	s := new(ArchiveRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCourseImport creates an imported Course with its Modules, Lessons,
+// Quizzes and Attachments, links it to its Categories, Tags and prerequisites,
+// and creates its new Tags. Either everything is created or, if any part
+// fails, nothing is.
+func (r *ArchiveRepositoryMySQL) CreateCourseImport(courseImport CourseImport) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txCreateCourse(tx, courseImport.Course); err != nil {
+			e <- err
+			return
+		}
+
+		for _, quiz := range courseImport.Quizzes {
+			if err := r.txCreateQuiz(tx, quiz); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, attachment := range courseImport.Attachments {
+			if err := r.txExecNamed(tx, attachmentQueries.insertAttachment, attachment); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		if err := r.txCreateTaxonomy(tx, courseImport); err != nil {
+			e <- err
+			return
+		}
+
+		for _, prerequisite := range courseImport.Prerequisites {
+			if err := r.txExecNamed(tx, courseQueries.insertPrerequisite, prerequisite); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txCreateCourse creates a Course with its Modules and Lessons transactionally
+// given the *sqlx.Tx param.
+func (r *ArchiveRepositoryMySQL) txCreateCourse(tx *sqlx.Tx, course Course) (err error) {
+	err = r.txExecNamed(tx, courseQueries.insertCourse, course)
+	if err != nil {
+		return
+	}
+
+	for _, module := range course.Modules {
+		err = r.txExecNamed(tx, moduleQueries.insertModule, module)
+		if err != nil {
+			return
+		}
+
+		for _, lesson := range module.Lessons {
+			err = r.txExecNamed(tx, moduleQueries.insertLesson, lesson)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// txCreateQuiz creates a Quiz with its QuizQuestions and QuizOptions
+// transactionally given the *sqlx.Tx param.
+func (r *ArchiveRepositoryMySQL) txCreateQuiz(tx *sqlx.Tx, quiz Quiz) (err error) {
+	err = r.txExecNamed(tx, quizQueries.insertQuiz, quiz)
+	if err != nil {
+		return
+	}
+
+	for _, question := range quiz.Questions {
+		err = r.txExecNamed(tx, quizQueries.insertQuizQuestionBulk+quizQueries.insertQuizQuestionBulkPlaceholder, question)
+		if err != nil {
+			return
+		}
+
+		for _, option := range question.Options {
+			err = r.txExecNamed(tx, quizQueries.insertQuizOptionBulk+quizQueries.insertQuizOptionBulkPlaceholder, option)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// txCreateTaxonomy creates the new Tags of an imported Course, and links the
+// Course to its Categories and Tags transactionally given the *sqlx.Tx param.
+func (r *ArchiveRepositoryMySQL) txCreateTaxonomy(tx *sqlx.Tx, courseImport CourseImport) (err error) {
+	for _, tag := range courseImport.NewTags {
+		err = r.txExecNamed(tx, taxonomyQueries.insertTag, tag)
+		if err != nil {
+			return
+		}
+	}
+
+	courseID := courseImport.Course.ID.String()
+	for _, categoryID := range courseImport.CategoryIDs {
+		_, err = tx.Exec(taxonomyQueries.insertCourseCategory, courseID, categoryID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	for _, tagID := range courseImport.TagIDs {
+		_, err = tx.Exec(taxonomyQueries.insertCourseTag, courseID, tagID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *ArchiveRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
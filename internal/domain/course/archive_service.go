@@ -0,0 +1,320 @@
+package course
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras/storage"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+)
+
+const defaultArchiveMaxSizeMB = 500
+
+// ArchiveService is the service interface for exporting Courses to archives
+// and importing them back.
+type ArchiveService interface {
+	ArchiveSizeLimit() (maxSize int64)
+	ExportCourse(id uuid.UUID, userID uuid.UUID) (archive *ArchiveFile, course Course, err error)
+	ImportCourse(archive io.ReaderAt, size int64, userID uuid.UUID) (courseImport CourseImport, err error)
+}
+
+// ArchiveFile is an exported archive, or a file imported from one, spooled to
+// a temporary file rather than kept in memory. Closing it removes the file.
+type ArchiveFile struct {
+	*os.File
+	Size int64
+}
+
+// Close closes and removes the temporary file.
+func (f *ArchiveFile) Close() (err error) {
+	err = f.File.Close()
+	if removeErr := os.Remove(f.File.Name()); err == nil {
+		err = removeErr
+	}
+
+	return
+}
+
+// rewind records the size of the file written so far and seeks back to its
+// start.
+func (f *ArchiveFile) rewind() (err error) {
+	f.Size, err = f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	return
+}
+
+// ArchiveServiceImpl is the service implementation for exporting Courses to
+// archives and importing them back.
+type ArchiveServiceImpl struct {
+	ArchiveRepository      ArchiveRepository
+	AttachmentRepository   AttachmentRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	ModuleRepository       ModuleRepository
+	QuizRepository         QuizRepository
+	TaxonomyRepository     TaxonomyRepository
+	Storage                storage.Storage
+	Config                 *configs.Config
+}
+
+// ProvideArchiveServiceImpl is the provider for this service.
+func ProvideArchiveServiceImpl(
+	archiveRepository ArchiveRepository,
+	attachmentRepository AttachmentRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	moduleRepository ModuleRepository,
+	quizRepository QuizRepository,
+	taxonomyRepository TaxonomyRepository,
+	storage storage.Storage,
+	config *configs.Config) *ArchiveServiceImpl {
+	s := new(ArchiveServiceImpl)
+	s.ArchiveRepository = archiveRepository
+	s.AttachmentRepository = attachmentRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.ModuleRepository = moduleRepository
+	s.QuizRepository = quizRepository
+	s.TaxonomyRepository = taxonomyRepository
+	s.Storage = storage
+	s.Config = config
+
+	return s
+}
+
+// ArchiveSizeLimit returns the size, in bytes, imported archives cannot
+// exceed.
+func (s *ArchiveServiceImpl) ArchiveSizeLimit() (maxSize int64) {
+	maxSizeMB := s.Config.Archive.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultArchiveMaxSizeMB
+	}
+
+	return maxSizeMB << 20
+}
+
+// ExportCourse exports a Course as a zip archive holding its details,
+// taxonomy and prerequisites, its Modules, Lessons and Quizzes, and the files
+// of its Attachments, listed with their checksums in a versioned manifest.
+// Deleted content and student data are left out. The archive is written to a
+// temporary file, which the caller must close.
+func (s *ArchiveServiceImpl) ExportCourse(id uuid.UUID, userID uuid.UUID) (archive *ArchiveFile, course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionExportCourse)
+	if err != nil {
+		return
+	}
+
+	course, err = attachCourseModules(s.ModuleRepository, course)
+	if err != nil {
+		return
+	}
+
+	categories, err := s.TaxonomyRepository.ResolveCategoriesByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	tags, err := s.TaxonomyRepository.ResolveTagsByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	course.AttachTaxonomy(categories, tags)
+
+	prerequisiteIDs, err := s.CourseRepository.ResolvePrerequisiteIDs(course.ID)
+	if err != nil {
+		return
+	}
+
+	prerequisites, err := s.CourseRepository.ResolveCoursesByIDs(prerequisiteIDs)
+	if err != nil {
+		return
+	}
+
+	quizzes, attachments, err := s.resolveLessonContent(course)
+	if err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile("", "course-export-*.zip")
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	archive = &ArchiveFile{File: tmp}
+	courseArchive := CourseArchive{}.NewCourseArchive(course, prerequisites, quizzes, attachments)
+	err = WriteCourseArchive(archive, courseArchive, attachments, userID, s.Storage.Open)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		archive.Close()
+		return nil, course, err
+	}
+
+	err = archive.rewind()
+	if err != nil {
+		logger.ErrorWithStack(err)
+		archive.Close()
+		return nil, course, err
+	}
+
+	return archive, course, nil
+}
+
+// ImportCourse imports the Course of an archive as a new draft owned by the
+// importing user. The archive is verified against its manifest first, and
+// every ID in it is replaced. Categories and prerequisites that don't exist
+// here are left out and reported as conflicts. The Course is created with all
+// of its content at once, or not at all.
+func (s *ArchiveServiceImpl) ImportCourse(archive io.ReaderAt, size int64, userID uuid.UUID) (courseImport CourseImport, err error) {
+	reader, err := ReadCourseArchive(archive, size)
+	if err != nil {
+		return
+	}
+
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	tags, err := s.TaxonomyRepository.ResolveTagsByNames(NormalizeTagNames(reader.Archive.Tags))
+	if err != nil {
+		return
+	}
+
+	prerequisiteIDs := make([]uuid.UUID, 0, len(reader.Archive.Prerequisites))
+	for _, prerequisite := range reader.Archive.Prerequisites {
+		prerequisiteIDs = append(prerequisiteIDs, prerequisite.ID)
+	}
+
+	prerequisites, err := s.CourseRepository.ResolveCoursesByIDs(prerequisiteIDs)
+	if err != nil {
+		return
+	}
+
+	courseImport, err = reader.Archive.NewCourseImport(categories, tags, prerequisites, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.storeFiles(reader, courseImport)
+	if err != nil {
+		return
+	}
+
+	err = s.ArchiveRepository.CreateCourseImport(courseImport)
+	if err != nil {
+		// don't leave files behind without Attachments pointing to them
+		s.deleteFiles(courseImport.Attachments)
+		return
+	}
+
+	return
+}
+
+// deleteFiles removes the files of Attachments from blob storage.
+func (s *ArchiveServiceImpl) deleteFiles(attachments []Attachment) {
+	for _, attachment := range attachments {
+		if err := s.Storage.Delete(attachment.StorageKey); err != nil {
+			logger.ErrorWithStack(err)
+		}
+	}
+}
+
+// resolveLessonContent resolves the active Quizzes, with their QuizQuestions,
+// and Attachments of the Lessons of a Course.
+func (s *ArchiveServiceImpl) resolveLessonContent(course Course) (quizzes []Quiz, attachments []Attachment, err error) {
+	for _, module := range course.Modules {
+		for _, lesson := range module.Lessons {
+			lessonQuizzes, err := s.QuizRepository.ResolveQuizzesByLessonID(lesson.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			lessonAttachments, err := s.AttachmentRepository.ResolveAttachmentsByLessonID(lesson.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			quizzes = append(quizzes, lessonQuizzes...)
+			attachments = append(attachments, lessonAttachments...)
+		}
+	}
+
+	quizIDs := make([]uuid.UUID, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		quizIDs = append(quizIDs, quiz.ID)
+	}
+
+	questions, err := s.QuizRepository.ResolveQuestionsByQuizIDs(quizIDs)
+	if err != nil {
+		return
+	}
+
+	for i := range quizzes {
+		quizzes[i].AttachQuestions(questions)
+	}
+
+	return
+}
+
+// storeFiles copies the files of the Attachments of a CourseImport from the
+// archive to blob storage. If any fails, those already copied are removed.
+func (s *ArchiveServiceImpl) storeFiles(reader CourseArchiveReader, courseImport CourseImport) (err error) {
+	maxSize := attachmentSizeLimit(s.Config)
+	for i, attachment := range courseImport.Attachments {
+		if attachment.Size > maxSize {
+			err = failure.BadRequestFromString(fmt.Sprintf("the attachment %s is larger than the %d bytes limit", attachment.FileName, maxSize))
+		} else {
+			err = s.storeFile(reader, courseImport.Files[attachment.StorageKey], attachment)
+		}
+
+		if err != nil {
+			s.deleteFiles(courseImport.Attachments[:i])
+			return
+		}
+	}
+
+	return
+}
+
+// storeFile copies the file of an Attachment from the archive to blob storage.
+func (s *ArchiveServiceImpl) storeFile(reader CourseArchiveReader, path string, attachment Attachment) (err error) {
+	rc, err := reader.Open(path)
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+
+	// blob storages need to seek, which files in a zip can't
+	tmp, err := ioutil.TempFile("", "course-import-*")
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	file := &ArchiveFile{File: tmp}
+	defer file.Close()
+
+	_, err = io.Copy(file, io.LimitReader(rc, attachment.Size))
+	if err != nil {
+		return failure.BadRequestFromString(fmt.Sprintf("%s is corrupted", path))
+	}
+
+	err = file.rewind()
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return s.Storage.Put(attachment.StorageKey, file, attachment.Size, attachment.ContentType)
+}
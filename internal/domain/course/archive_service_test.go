@@ -0,0 +1,179 @@
+package course_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveService(t *testing.T) {
+	config := &configs.Config{}
+	pdf := "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"
+
+	// export exports a Course with a Quiz and an Attachment on its first Lesson,
+	// whose file is stored with the given content.
+	export := func(t *testing.T, ctrl *gomock.Controller, stored string) (c course.Course, archive []byte, err error) {
+		c = newDraftCourse()
+		lesson := c.Modules[0].Lessons[0]
+		c.Content = "Start with /lessons/" + lesson.ID.String()
+
+		quiz := newQuiz(t)
+		quiz.LessonID = lesson.ID
+		questions := quiz.Questions
+		quiz.Questions = nil
+
+		attachment, err := course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload("notes.pdf", pdf), 1<<20, c.UserID)
+		assert.NoError(t, err)
+		blobs := &memoryStorage{blobs: map[string][]byte{attachment.StorageKey: []byte(stored)}}
+
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		mockQuizRepo := course_mock.NewMockQuizRepository(ctrl)
+		mockAttachmentRepo := course_mock.NewMockAttachmentRepository(ctrl)
+		mockTaxonomyRepo := course_mock.NewMockTaxonomyRepository(ctrl)
+		s := course.ProvideArchiveServiceImpl(nil, mockAttachmentRepo, nil, mockCourseRepo, mockModuleRepo, mockQuizRepo, mockTaxonomyRepo, blobs, config)
+
+		module := c.Modules[0]
+		module.Lessons = nil
+		resolved := c
+		resolved.Modules = nil
+
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(resolved, nil)
+		mockModuleRepo.EXPECT().ResolveModulesByCourseIDs(gomock.Any()).Return([]course.Module{module}, nil)
+		mockModuleRepo.EXPECT().ResolveLessonsByModuleIDs(gomock.Any()).Return(c.Modules[0].Lessons, nil)
+		mockTaxonomyRepo.EXPECT().ResolveCategoriesByCourseID(c.ID).Return(nil, nil)
+		mockTaxonomyRepo.EXPECT().ResolveTagsByCourseID(c.ID).Return([]course.Tag{{ID: getRandomUUID(), Name: "go"}}, nil)
+		mockCourseRepo.EXPECT().ResolvePrerequisiteIDs(c.ID).Return(nil, nil)
+		mockCourseRepo.EXPECT().ResolveCoursesByIDs(gomock.Any()).Return(nil, nil)
+		mockQuizRepo.EXPECT().ResolveQuizzesByLessonID(lesson.ID).Return([]course.Quiz{quiz}, nil)
+		mockQuizRepo.EXPECT().ResolveQuizzesByLessonID(c.Modules[0].Lessons[1].ID).Return(nil, nil)
+		mockQuizRepo.EXPECT().ResolveQuestionsByQuizIDs([]uuid.UUID{quiz.ID}).Return(questions, nil)
+		mockAttachmentRepo.EXPECT().ResolveAttachmentsByLessonID(lesson.ID).Return([]course.Attachment{attachment}, nil)
+		mockAttachmentRepo.EXPECT().ResolveAttachmentsByLessonID(c.Modules[0].Lessons[1].ID).Return(nil, nil)
+
+		exported, _, err := s.ExportCourse(c.ID, c.UserID)
+		if err != nil {
+			return
+		}
+		defer exported.Close()
+
+		archive, err = ioutil.ReadAll(exported)
+		assert.Equal(t, exported.Size, int64(len(archive)))
+
+		return
+	}
+
+	t.Run("exported courses are imported under new IDs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, archive, err := export(t, ctrl, pdf)
+		assert.NoError(t, err)
+		importerID := getRandomUUID()
+
+		mockArchiveRepo := course_mock.NewMockArchiveRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockTaxonomyRepo := course_mock.NewMockTaxonomyRepository(ctrl)
+		blobs := &memoryStorage{blobs: map[string][]byte{}}
+		s := course.ProvideArchiveServiceImpl(mockArchiveRepo, nil, nil, mockCourseRepo, nil, nil, mockTaxonomyRepo, blobs, config)
+
+		mockTaxonomyRepo.EXPECT().ResolveCategories().Return(nil, nil)
+		mockTaxonomyRepo.EXPECT().ResolveTagsByNames([]string{"go"}).Return(nil, nil)
+		mockCourseRepo.EXPECT().ResolveCoursesByIDs(gomock.Any()).Return(nil, nil)
+		mockArchiveRepo.EXPECT().CreateCourseImport(gomock.Any()).Return(nil)
+
+		courseImport, err := s.ImportCourse(bytes.NewReader(archive), int64(len(archive)), importerID)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, c.ID, courseImport.Course.ID)
+		assert.Equal(t, importerID, courseImport.Course.UserID)
+		assert.Equal(t, course.CourseStatusDraft, courseImport.Course.Status)
+		assert.Equal(t, "Start with /lessons/"+courseImport.IDs[c.Modules[0].Lessons[0].ID].String(), courseImport.Course.Content)
+		assert.Len(t, courseImport.Course.Modules[0].Lessons, 2)
+		assert.Len(t, courseImport.Quizzes, 1)
+		assert.Len(t, courseImport.NewTags, 1)
+		assert.Len(t, courseImport.Attachments, 1)
+		assert.Equal(t, pdf, string(blobs.blobs[courseImport.Attachments[0].StorageKey]))
+	})
+
+	t.Run("stored files that changed fail the export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, _, err := export(t, ctrl, pdf+"%%EOF\n")
+
+		assert.Equal(t, http.StatusInternalServerError, failure.GetCode(err))
+	})
+
+	t.Run("failed imports leave no files behind", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, archive, err := export(t, ctrl, pdf)
+		assert.NoError(t, err)
+
+		mockArchiveRepo := course_mock.NewMockArchiveRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockTaxonomyRepo := course_mock.NewMockTaxonomyRepository(ctrl)
+		blobs := &memoryStorage{blobs: map[string][]byte{}}
+		s := course.ProvideArchiveServiceImpl(mockArchiveRepo, nil, nil, mockCourseRepo, nil, nil, mockTaxonomyRepo, blobs, config)
+
+		mockTaxonomyRepo.EXPECT().ResolveCategories().Return(nil, nil)
+		mockTaxonomyRepo.EXPECT().ResolveTagsByNames(gomock.Any()).Return(nil, nil)
+		mockCourseRepo.EXPECT().ResolveCoursesByIDs(gomock.Any()).Return(nil, nil)
+		mockArchiveRepo.EXPECT().CreateCourseImport(gomock.Any()).Return(errors.New("duplicate entry"))
+
+		_, err = s.ImportCourse(bytes.NewReader(archive), int64(len(archive)), c.UserID)
+
+		assert.Error(t, err)
+		assert.Empty(t, blobs.blobs)
+	})
+
+	t.Run("tampered archives are rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, archive, err := export(t, ctrl, pdf)
+		assert.NoError(t, err)
+		s := course.ProvideArchiveServiceImpl(nil, nil, nil, nil, nil, nil, nil, nil, config)
+
+		// rewrite the archive with one byte of the course.json changed
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		assert.NoError(t, err)
+
+		var tampered bytes.Buffer
+		zw := zip.NewWriter(&tampered)
+		for _, file := range zr.File {
+			rc, err := file.Open()
+			assert.NoError(t, err)
+			content, err := ioutil.ReadAll(rc)
+			assert.NoError(t, err)
+			rc.Close()
+
+			if file.Name == "course.json" {
+				content = bytes.Replace(content, []byte("Go basics"), []byte("Go basicz"), 1)
+			}
+
+			entry, err := zw.Create(file.Name)
+			assert.NoError(t, err)
+			_, err = entry.Write(content)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, zw.Close())
+
+		_, err = s.ImportCourse(bytes.NewReader(tampered.Bytes()), int64(tampered.Len()), getRandomUUID())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
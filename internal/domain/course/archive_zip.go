@@ -0,0 +1,290 @@
+package course
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	courseArchiveManifestPath = "manifest.json"
+	courseArchiveContentPath  = "course.json"
+	// courseArchiveManifestMaxSize caps the size of a manifest read from an
+	// archive, which is kept in memory.
+	courseArchiveManifestMaxSize = 1 << 20
+)
+
+// WriteCourseArchive writes a CourseArchive as a zip archive: the manifest,
+// the course.json and the file of every archived Attachment, read from blob
+// storage through open. Files whose size or checksum changed since they were
+// attached are reported instead of being exported corrupted.
+func WriteCourseArchive(w io.Writer, archive CourseArchive, attachments []Attachment, userID uuid.UUID, open func(key string) (io.ReadCloser, error)) (err error) {
+	content, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return
+	}
+
+	manifest := CourseArchiveManifest{
+		Format:     CourseArchiveFormat,
+		Version:    CourseArchiveVersion,
+		ExportedAt: time.Now(),
+		ExportedBy: userID,
+		Files:      []CourseArchiveFile{newCourseArchiveFile(courseArchiveContentPath, content)},
+	}
+
+	for _, attachment := range attachments {
+		manifest.Files = append(manifest.Files, CourseArchiveFile{
+			Path:     courseArchiveAttachmentPath(attachment.ID),
+			Size:     attachment.Size,
+			Checksum: attachment.Checksum,
+		})
+	}
+
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+
+	zw := zip.NewWriter(w)
+	err = writeCourseArchiveEntry(zw, courseArchiveManifestPath, manifestContent)
+	if err != nil {
+		return
+	}
+
+	err = writeCourseArchiveEntry(zw, courseArchiveContentPath, content)
+	if err != nil {
+		return
+	}
+
+	for i, attachment := range attachments {
+		err = copyCourseArchiveFile(zw, manifest.Files[i+1], func() (io.ReadCloser, error) {
+			return open(attachment.StorageKey)
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	return zw.Close()
+}
+
+// CourseArchiveReader reads an archive written by WriteCourseArchive.
+type CourseArchiveReader struct {
+	Manifest CourseArchiveManifest
+	Archive  CourseArchive
+	files    map[string]*zip.File
+}
+
+// ReadCourseArchive reads and verifies a zip archive of the given size: its
+// manifest must be of a supported version, and every file it lists must be in
+// the archive with the listed size and checksum, with nothing else beside.
+// Every Attachment of the CourseArchive must have its file listed.
+func ReadCourseArchive(r io.ReaderAt, size int64) (reader CourseArchiveReader, err error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return reader, failure.BadRequestFromString("the archive is not a valid zip file")
+	}
+
+	reader.files = make(map[string]*zip.File)
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if _, ok := reader.files[file.Name]; ok {
+			return reader, failure.BadRequestFromString(fmt.Sprintf("the archive contains %s more than once", file.Name))
+		}
+
+		reader.files[file.Name] = file
+	}
+
+	err = reader.readManifest()
+	if err != nil {
+		return
+	}
+
+	listed := make(map[string]CourseArchiveFile)
+	for _, file := range reader.Manifest.Files {
+		listed[file.Path] = file
+
+		err = reader.verify(file)
+		if err != nil {
+			return
+		}
+	}
+
+	for name := range reader.files {
+		if _, ok := listed[name]; !ok && name != courseArchiveManifestPath {
+			return reader, failure.BadRequestFromString(fmt.Sprintf("the archive contains %s, which its manifest does not list", name))
+		}
+	}
+
+	err = reader.readContent(listed)
+	return
+}
+
+// Open opens a file of the archive.
+func (r *CourseArchiveReader) Open(path string) (io.ReadCloser, error) {
+	file, ok := r.files[path]
+	if !ok {
+		return nil, failure.BadRequestFromString(fmt.Sprintf("the archive does not contain %s", path))
+	}
+
+	return file.Open()
+}
+
+// readContent reads the course.json of the archive, and checks the file of
+// every Attachment is listed as it was archived.
+func (r *CourseArchiveReader) readContent(listed map[string]CourseArchiveFile) (err error) {
+	file, ok := listed[courseArchiveContentPath]
+	if !ok {
+		return failure.BadRequestFromString(fmt.Sprintf("the archive does not contain %s", courseArchiveContentPath))
+	}
+
+	err = r.decode(file.Path, file.Size, &r.Archive)
+	if err != nil {
+		return
+	}
+
+	err = r.Archive.Validate()
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	for _, module := range r.Archive.Modules {
+		for _, lesson := range module.Lessons {
+			for _, attachment := range lesson.Attachments {
+				file, ok := listed[attachment.Path]
+				if !ok || file.Size != attachment.Size || file.Checksum != attachment.Checksum {
+					return failure.BadRequestFromString(fmt.Sprintf("the file of the attachment %s does not match the manifest", attachment.FileName))
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// readManifest reads the manifest of the archive.
+func (r *CourseArchiveReader) readManifest() (err error) {
+	if _, ok := r.files[courseArchiveManifestPath]; !ok {
+		return failure.BadRequestFromString(fmt.Sprintf("the archive does not contain %s", courseArchiveManifestPath))
+	}
+
+	err = r.decode(courseArchiveManifestPath, courseArchiveManifestMaxSize, &r.Manifest)
+	if err != nil {
+		return
+	}
+
+	err = r.Manifest.Validate()
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	return
+}
+
+// decode decodes a JSON file of the archive no larger than maxSize.
+func (r *CourseArchiveReader) decode(path string, maxSize int64, v interface{}) (err error) {
+	rc, err := r.Open(path)
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(rc, maxSize))
+	if err != nil {
+		return failure.BadRequestFromString(fmt.Sprintf("%s is corrupted", path))
+	}
+
+	err = json.Unmarshal(content, v)
+	if err != nil {
+		return failure.BadRequestFromString(fmt.Sprintf("%s is not valid JSON: %s", path, err))
+	}
+
+	return
+}
+
+// verify checks a file listed in the manifest is in the archive with the
+// listed size and checksum.
+func (r *CourseArchiveReader) verify(listed CourseArchiveFile) (err error) {
+	file, ok := r.files[listed.Path]
+	if !ok {
+		return failure.BadRequestFromString(fmt.Sprintf("the archive does not contain %s", listed.Path))
+	}
+
+	if file.UncompressedSize64 != uint64(listed.Size) {
+		return failure.BadRequestFromString(fmt.Sprintf("%s is not of the size listed in the manifest", listed.Path))
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return failure.BadRequestFromString(fmt.Sprintf("%s is corrupted", listed.Path))
+	}
+	defer rc.Close()
+
+	// the declared size can't be trusted, so never read past it
+	hash := sha256.New()
+	n, err := io.Copy(hash, io.LimitReader(rc, listed.Size+1))
+	if err != nil || n != listed.Size || hex.EncodeToString(hash.Sum(nil)) != listed.Checksum {
+		return failure.BadRequestFromString(fmt.Sprintf("%s does not match its checksum", listed.Path))
+	}
+
+	return
+}
+
+// copyCourseArchiveFile copies a file into an archive, checking it has the
+// listed size and checksum.
+func copyCourseArchiveFile(zw *zip.Writer, listed CourseArchiveFile, open func() (io.ReadCloser, error)) (err error) {
+	rc, err := open()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+
+	entry, err := zw.Create(listed.Path)
+	if err != nil {
+		return
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(entry, hash), rc)
+	if err != nil {
+		return
+	}
+
+	if n != listed.Size || hex.EncodeToString(hash.Sum(nil)) != listed.Checksum {
+		return failure.InternalError(fmt.Errorf("the stored file %s does not match its checksum", listed.Path))
+	}
+
+	return
+}
+
+// newCourseArchiveFile lists a file for the manifest.
+func newCourseArchiveFile(path string, content []byte) CourseArchiveFile {
+	checksum := sha256.Sum256(content)
+	return CourseArchiveFile{
+		Path:     path,
+		Size:     int64(len(content)),
+		Checksum: hex.EncodeToString(checksum[:]),
+	}
+}
+
+// writeCourseArchiveEntry writes a file into an archive.
+func writeCourseArchiveEntry(zw *zip.Writer, path string, content []byte) (err error) {
+	entry, err := zw.Create(path)
+	if err != nil {
+		return
+	}
+
+	_, err = entry.Write(content)
+	return
+}
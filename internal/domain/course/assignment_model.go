@@ -0,0 +1,701 @@
+package course
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// SubmissionStatus indicates the status of a Submission.
+type SubmissionStatus string
+
+const (
+	// SubmissionStatusSubmitted indicates a Submission waiting in the grading queue.
+	SubmissionStatusSubmitted SubmissionStatus = "submitted"
+	// SubmissionStatusInReview indicates a Submission a teacher is reviewing.
+	SubmissionStatusInReview SubmissionStatus = "in_review"
+	// SubmissionStatusGraded indicates a scored Submission.
+	SubmissionStatusGraded SubmissionStatus = "graded"
+	// SubmissionStatusReturned indicates a Submission returned to the student for revision.
+	SubmissionStatusReturned SubmissionStatus = "returned"
+)
+
+// GradingQueueStatuses lists the statuses of Submissions waiting for a teacher.
+var GradingQueueStatuses = []SubmissionStatus{SubmissionStatusSubmitted, SubmissionStatusInReview}
+
+//// Assignment
+
+// Assignment is a project students hand in for a Lesson.
+type Assignment struct {
+	ID                uuid.UUID         `db:"id" validate:"required"`
+	CourseID          uuid.UUID         `db:"course_id" validate:"required"`
+	LessonID          uuid.UUID         `db:"lesson_id" validate:"required"`
+	Title             string            `db:"title" validate:"required"`
+	Instructions      string            `db:"instructions"`
+	DueAt             time.Time         `db:"due_at" validate:"required"`
+	MaxScore          int               `db:"max_score" validate:"min=1"`
+	LatePenaltyPerDay int               `db:"late_penalty_per_day" validate:"min=0,max=100"`
+	MaxLatePenalty    int               `db:"max_late_penalty" validate:"min=0,max=100"`
+	LateCutoffAt      null.Time         `db:"late_cutoff_at"`
+	CreatedAt         time.Time         `db:"created_at" validate:"required"`
+	CreatedBy         uuid.UUID         `db:"created_by" validate:"required"`
+	UpdatedAt         null.Time         `db:"updated_at"`
+	UpdatedBy         nuuid.NUUID       `db:"updated_by"`
+	DeletedAt         null.Time         `db:"deleted_at"`
+	DeletedBy         nuuid.NUUID       `db:"deleted_by"`
+	Rubric            []RubricCriterion `db:"-" validate:"dive"`
+}
+
+// AcceptsSubmissionsAt checks whether an Assignment accepts submissions at the given time.
+func (a *Assignment) AcceptsSubmissionsAt(at time.Time) bool {
+	return !a.LateCutoffAt.Valid || !at.After(a.LateCutoffAt.Time)
+}
+
+// AttachRubric attaches RubricCriteria to this Assignment.
+func (a *Assignment) AttachRubric(criteria []RubricCriterion) Assignment {
+	for _, criterion := range criteria {
+		if criterion.AssignmentID == a.ID {
+			a.Rubric = append(a.Rubric, criterion)
+		}
+	}
+	return *a
+}
+
+// HasRubric checks whether an Assignment is scored with a rubric.
+func (a *Assignment) HasRubric() bool {
+	return len(a.Rubric) > 0
+}
+
+// IsDeleted checks whether an Assignment is marked as deleted.
+func (a *Assignment) IsDeleted() (deleted bool) {
+	return a.DeletedAt.Valid && a.DeletedBy.Valid
+}
+
+// Lateness returns how many started days a submission at the given time is
+// late, and the percentage of the score it loses for that.
+func (a *Assignment) Lateness(at time.Time) (daysLate int, penaltyPercent int) {
+	if !at.After(a.DueAt) {
+		return 0, 0
+	}
+
+	daysLate = int(math.Ceil(at.Sub(a.DueAt).Hours() / 24))
+	penaltyPercent = daysLate * a.LatePenaltyPerDay
+	if penaltyPercent > a.MaxLatePenalty {
+		penaltyPercent = a.MaxLatePenalty
+	}
+
+	return
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (a Assignment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ToResponseFormat())
+}
+
+// NewAssignmentFromRequestFormat creates a new Assignment for a Lesson.
+func (a Assignment) NewAssignmentFromRequestFormat(req AssignmentRequestFormat, lesson Lesson, userID uuid.UUID) (newAssignment Assignment, err error) {
+	assignmentID, _ := uuid.NewV4()
+	newAssignment = Assignment{
+		ID:        assignmentID,
+		CourseID:  lesson.CourseID,
+		LessonID:  lesson.ID,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	newAssignment.applyRequestFormat(req)
+	newAssignment.Rubric = newAssignment.rubricFromRequestFormat(req.Rubric)
+	newAssignment.recalculateMaxScore()
+	err = newAssignment.Validate()
+
+	return
+}
+
+// SoftDelete marks an Assignment as deleted.
+func (a *Assignment) SoftDelete(userID uuid.UUID) (err error) {
+	if a.IsDeleted() {
+		return failure.Conflict("softDelete", "assignment", "already marked as deleted")
+	}
+
+	a.DeletedAt = null.TimeFrom(time.Now())
+	a.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// ToResponseFormat converts this Assignment to its response format.
+func (a Assignment) ToResponseFormat() AssignmentResponseFormat {
+	resp := AssignmentResponseFormat{
+		ID:                a.ID,
+		CourseID:          a.CourseID,
+		LessonID:          a.LessonID,
+		Title:             a.Title,
+		Instructions:      a.Instructions,
+		DueAt:             a.DueAt,
+		MaxScore:          a.MaxScore,
+		LatePenaltyPerDay: a.LatePenaltyPerDay,
+		MaxLatePenalty:    a.MaxLatePenalty,
+		LateCutoffAt:      a.LateCutoffAt,
+		CreatedAt:         a.CreatedAt,
+		CreatedBy:         a.CreatedBy,
+		UpdatedAt:         a.UpdatedAt,
+		UpdatedBy:         a.UpdatedBy.Ptr(),
+		Rubric:            make([]RubricCriterionResponseFormat, 0),
+	}
+
+	for _, criterion := range a.Rubric {
+		resp.Rubric = append(resp.Rubric, criterion.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// Update updates an Assignment. The rubric is only replaced when the request
+// contains one.
+func (a *Assignment) Update(req AssignmentRequestFormat, userID uuid.UUID) (err error) {
+	a.applyRequestFormat(req)
+	if req.Rubric != nil {
+		a.Rubric = a.rubricFromRequestFormat(req.Rubric)
+	}
+
+	a.recalculateMaxScore()
+	a.UpdatedAt = null.TimeFrom(time.Now())
+	a.UpdatedBy = nuuid.From(userID)
+
+	err = a.Validate()
+
+	return
+}
+
+// Validate validates the entity.
+func (a *Assignment) Validate() (err error) {
+	if a.LateCutoffAt.Valid && a.LateCutoffAt.Time.Before(a.DueAt) {
+		return errors.New("late cutoff must not be before the due date")
+	}
+
+	validator := shared.GetValidator()
+	return validator.Struct(a)
+}
+
+// applyRequestFormat copies the settings of a request to this Assignment.
+func (a *Assignment) applyRequestFormat(req AssignmentRequestFormat) {
+	a.Title = req.Title
+	a.Instructions = req.Instructions
+	a.DueAt = req.DueAt
+	a.MaxScore = req.MaxScore
+	a.LatePenaltyPerDay = req.LatePenaltyPerDay
+	a.MaxLatePenalty = int(req.MaxLatePenalty.ValueOrZero())
+	if !req.MaxLatePenalty.Valid {
+		a.MaxLatePenalty = 100
+	}
+	a.LateCutoffAt = req.LateCutoffAt
+}
+
+// recalculateMaxScore makes the maximum score of an Assignment with a rubric
+// the sum of its criteria.
+func (a *Assignment) recalculateMaxScore() {
+	if !a.HasRubric() {
+		return
+	}
+
+	a.MaxScore = 0
+	for _, criterion := range a.Rubric {
+		a.MaxScore += criterion.MaxPoints
+	}
+}
+
+// rubricFromRequestFormat creates this Assignment's RubricCriteria in request order.
+func (a *Assignment) rubricFromRequestFormat(reqs []RubricCriterionRequestFormat) (rubric []RubricCriterion) {
+	rubric = make([]RubricCriterion, 0, len(reqs))
+	for i, req := range reqs {
+		criterionID, _ := uuid.NewV4()
+		rubric = append(rubric, RubricCriterion{
+			ID:           criterionID,
+			AssignmentID: a.ID,
+			Title:        req.Title,
+			MaxPoints:    req.MaxPoints,
+			Position:     i + 1,
+		})
+	}
+
+	return
+}
+
+// AssignmentRequestFormat represents an Assignment's standard formatting for JSON
+// deserializing. With a rubric, the maximum score is the sum of its criteria.
+// Updates without a rubric keep the current one.
+type AssignmentRequestFormat struct {
+	Title             string                         `json:"title" validate:"required"`
+	Instructions      string                         `json:"instructions"`
+	DueAt             time.Time                      `json:"dueAt" validate:"required"`
+	MaxScore          int                            `json:"maxScore" validate:"omitempty,min=1"`
+	LatePenaltyPerDay int                            `json:"latePenaltyPerDay" validate:"min=0,max=100"`
+	MaxLatePenalty    null.Int                       `json:"maxLatePenalty" swaggertype:"integer"`
+	LateCutoffAt      null.Time                      `json:"lateCutoffAt" swaggertype:"string"`
+	Rubric            []RubricCriterionRequestFormat `json:"rubric" validate:"dive"`
+}
+
+// AssignmentResponseFormat represents an Assignment's standard formatting for JSON serializing.
+type AssignmentResponseFormat struct {
+	ID                uuid.UUID                       `json:"id"`
+	CourseID          uuid.UUID                       `json:"courseID"`
+	LessonID          uuid.UUID                       `json:"lessonID"`
+	Title             string                          `json:"title"`
+	Instructions      string                          `json:"instructions"`
+	DueAt             time.Time                       `json:"dueAt"`
+	MaxScore          int                             `json:"maxScore"`
+	LatePenaltyPerDay int                             `json:"latePenaltyPerDay"`
+	MaxLatePenalty    int                             `json:"maxLatePenalty"`
+	LateCutoffAt      null.Time                       `json:"lateCutoffAt" swaggertype:"string"`
+	CreatedAt         time.Time                       `json:"createdAt"`
+	CreatedBy         uuid.UUID                       `json:"createdBy"`
+	UpdatedAt         null.Time                       `json:"updatedAt"`
+	UpdatedBy         *uuid.UUID                      `json:"updatedBy"`
+	Rubric            []RubricCriterionResponseFormat `json:"rubric"`
+}
+
+//// Rubric Criterion
+
+// RubricCriterion is one scored aspect of an Assignment.
+type RubricCriterion struct {
+	ID           uuid.UUID `db:"id" validate:"required"`
+	AssignmentID uuid.UUID `db:"assignment_id" validate:"required"`
+	Title        string    `db:"title" validate:"required"`
+	MaxPoints    int       `db:"max_points" validate:"min=1"`
+	Position     int       `db:"position" validate:"min=1"`
+}
+
+// ToResponseFormat converts this RubricCriterion to its response format.
+func (rc RubricCriterion) ToResponseFormat() RubricCriterionResponseFormat {
+	return RubricCriterionResponseFormat{
+		ID:        rc.ID,
+		Title:     rc.Title,
+		MaxPoints: rc.MaxPoints,
+		Position:  rc.Position,
+	}
+}
+
+// RubricCriterionRequestFormat represents a RubricCriterion's standard formatting for JSON deserializing.
+type RubricCriterionRequestFormat struct {
+	Title     string `json:"title" validate:"required"`
+	MaxPoints int    `json:"maxPoints" validate:"min=1"`
+}
+
+// RubricCriterionResponseFormat represents a RubricCriterion's standard formatting for JSON serializing.
+type RubricCriterionResponseFormat struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	MaxPoints int       `json:"maxPoints"`
+	Position  int       `json:"position"`
+}
+
+//// Submission
+
+// Submission is a student's hand-in for an Assignment.
+type Submission struct {
+	ID             uuid.UUID        `db:"id" validate:"required"`
+	AssignmentID   uuid.UUID        `db:"assignment_id" validate:"required"`
+	CourseID       uuid.UUID        `db:"course_id" validate:"required"`
+	StudentID      uuid.UUID        `db:"student_id" validate:"required"`
+	Status         SubmissionStatus `db:"status" validate:"required,oneof=submitted in_review graded returned"`
+	Content        string           `db:"content"`
+	Links          StringList       `db:"links" validate:"dive,url"`
+	Revision       int              `db:"revision" validate:"min=1"`
+	SubmittedAt    time.Time        `db:"submitted_at" validate:"required"`
+	DaysLate       int              `db:"days_late"`
+	PenaltyPercent int              `db:"penalty_percent"`
+	RawScore       null.Int         `db:"raw_score"`
+	Score          null.Float       `db:"score"`
+	Feedback       null.String      `db:"feedback"`
+	GradedAt       null.Time        `db:"graded_at"`
+	GradedBy       nuuid.NUUID      `db:"graded_by"`
+	CreatedAt      time.Time        `db:"created_at" validate:"required"`
+	UpdatedAt      null.Time        `db:"updated_at"`
+	UpdatedBy      nuuid.NUUID      `db:"updated_by"`
+	RubricScores   []RubricScore    `db:"-"`
+}
+
+// SubmissionQueryParameters filters the Submissions of a Course.
+type SubmissionQueryParameters struct {
+	CourseID     uuid.UUID
+	AssignmentID nuuid.NUUID
+	Statuses     []SubmissionStatus
+}
+
+// AttachRubricScores attaches RubricScores to this Submission.
+func (s *Submission) AttachRubricScores(scores []RubricScore) Submission {
+	for _, score := range scores {
+		if score.SubmissionID == s.ID {
+			s.RubricScores = append(s.RubricScores, score)
+		}
+	}
+	return *s
+}
+
+// Grade scores a Submission under review. Assignments with a rubric are scored
+// per criterion, others with a single score. The late penalty is deducted from
+// the resulting raw score.
+func (s *Submission) Grade(assignment Assignment, req GradeRequestFormat, userID uuid.UUID) (err error) {
+	rawScore, rubricScores, err := s.scoreFromRequestFormat(assignment, req)
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	err = s.UpdateStatus(SubmissionStatusGraded, userID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	s.RawScore = null.IntFrom(int64(rawScore))
+	s.Score = null.FloatFrom(math.Round(float64(rawScore)*float64(100-s.PenaltyPercent)) / 100)
+	s.Feedback = null.NewString(req.Feedback, req.Feedback != "")
+	s.GradedAt = null.TimeFrom(now)
+	s.GradedBy = nuuid.From(userID)
+	s.RubricScores = rubricScores
+
+	return
+}
+
+// IsLate checks whether a Submission was handed in after the due date.
+func (s *Submission) IsLate() bool {
+	return s.DaysLate > 0
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (s Submission) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToResponseFormat())
+}
+
+// NewSubmission hands in a student's work for an Assignment.
+func (s Submission) NewSubmission(assignment Assignment, req SubmissionRequestFormat, studentID uuid.UUID) (newSubmission Submission, err error) {
+	now := time.Now()
+	if !assignment.AcceptsSubmissionsAt(now) {
+		return newSubmission, failure.Conflict("submit", "submission", "the assignment no longer accepts submissions")
+	}
+
+	submissionID, _ := uuid.NewV4()
+	daysLate, penaltyPercent := assignment.Lateness(now)
+	newSubmission = Submission{
+		ID:             submissionID,
+		AssignmentID:   assignment.ID,
+		CourseID:       assignment.CourseID,
+		StudentID:      studentID,
+		Status:         SubmissionStatusSubmitted,
+		Content:        req.Content,
+		Links:          req.Links,
+		Revision:       1,
+		SubmittedAt:    now,
+		DaysLate:       daysLate,
+		PenaltyPercent: penaltyPercent,
+		CreatedAt:      now,
+	}
+
+	err = newSubmission.Validate()
+	if err != nil {
+		return newSubmission, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// Resubmit hands in a revision of a returned Submission. Revisions the teacher
+// asked for keep the lateness of the first submission, and the previous grade
+// is cleared.
+func (s *Submission) Resubmit(req SubmissionRequestFormat, studentID uuid.UUID) (err error) {
+	err = s.UpdateStatus(SubmissionStatusSubmitted, studentID)
+	if err != nil {
+		return
+	}
+
+	s.Content = req.Content
+	s.Links = req.Links
+	s.Revision++
+	s.SubmittedAt = time.Now()
+	s.RawScore = null.Int{}
+	s.Score = null.Float{}
+	s.GradedAt = null.Time{}
+	s.GradedBy = nuuid.NUUID{}
+	s.RubricScores = nil
+
+	err = s.Validate()
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	return
+}
+
+// Return returns a Submission to the student for revision.
+func (s *Submission) Return(req ReturnRequestFormat, userID uuid.UUID) (err error) {
+	err = s.UpdateStatus(SubmissionStatusReturned, userID)
+	if err != nil {
+		return
+	}
+
+	s.Feedback = null.StringFrom(req.Feedback)
+
+	return
+}
+
+// ToResponseFormat converts this Submission to its response format.
+func (s Submission) ToResponseFormat() SubmissionResponseFormat {
+	resp := SubmissionResponseFormat{
+		ID:             s.ID,
+		AssignmentID:   s.AssignmentID,
+		CourseID:       s.CourseID,
+		StudentID:      s.StudentID,
+		Status:         s.Status,
+		Content:        s.Content,
+		Links:          s.Links,
+		Revision:       s.Revision,
+		SubmittedAt:    s.SubmittedAt,
+		IsLate:         s.IsLate(),
+		DaysLate:       s.DaysLate,
+		PenaltyPercent: s.PenaltyPercent,
+		RawScore:       s.RawScore,
+		Score:          s.Score,
+		Feedback:       s.Feedback,
+		GradedAt:       s.GradedAt,
+		GradedBy:       s.GradedBy.Ptr(),
+		UpdatedAt:      s.UpdatedAt,
+		Rubric:         make([]RubricScoreResponseFormat, 0),
+	}
+
+	if resp.Links == nil {
+		resp.Links = make([]string, 0)
+	}
+
+	for _, score := range s.RubricScores {
+		resp.Rubric = append(resp.Rubric, score.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// UpdateStatus validates a Submission's status change. Allowed state changes are:
+// 1. Submitted --> InReview
+// 2. InReview --> Graded, Returned
+// 3. Graded --> Returned
+// 4. Returned --> Submitted
+func (s *Submission) UpdateStatus(newStatus SubmissionStatus, userID uuid.UUID) (err error) {
+	stateChangeNotAllowedError := failure.Conflict(
+		"stateChange",
+		"submission",
+		fmt.Sprintf("cannot change from %s to %s", s.Status, newStatus))
+
+	switch s.Status {
+	case SubmissionStatusSubmitted:
+		if newStatus != SubmissionStatusInReview {
+			return stateChangeNotAllowedError
+		}
+	case SubmissionStatusInReview:
+		if newStatus != SubmissionStatusGraded && newStatus != SubmissionStatusReturned {
+			return stateChangeNotAllowedError
+		}
+	case SubmissionStatusGraded:
+		if newStatus != SubmissionStatusReturned {
+			return stateChangeNotAllowedError
+		}
+	case SubmissionStatusReturned:
+		if newStatus != SubmissionStatusSubmitted {
+			return stateChangeNotAllowedError
+		}
+	}
+
+	// passed all state change validations, actually update the status
+	s.Status = newStatus
+	s.UpdatedAt = null.TimeFrom(time.Now())
+	s.UpdatedBy = nuuid.From(userID)
+
+	return nil
+}
+
+// Validate validates the entity.
+func (s *Submission) Validate() (err error) {
+	if strings.TrimSpace(s.Content) == "" && len(s.Links) == 0 {
+		return errors.New("a submission needs a text or at least one link")
+	}
+
+	validator := shared.GetValidator()
+	return validator.Struct(s)
+}
+
+// scoreFromRequestFormat computes the raw score of a grading request.
+func (s *Submission) scoreFromRequestFormat(assignment Assignment, req GradeRequestFormat) (rawScore int, rubricScores []RubricScore, err error) {
+	if !assignment.HasRubric() {
+		if len(req.Rubric) > 0 {
+			return 0, nil, errors.New("this assignment has no rubric")
+		}
+
+		if !req.Score.Valid || req.Score.Int64 < 0 || req.Score.Int64 > int64(assignment.MaxScore) {
+			return 0, nil, fmt.Errorf("score must be between 0 and %d", assignment.MaxScore)
+		}
+
+		return int(req.Score.Int64), nil, nil
+	}
+
+	if req.Score.Valid {
+		return 0, nil, errors.New("the score of a rubric-graded assignment is the sum of its criteria")
+	}
+
+	points := make(map[uuid.UUID]RubricScoreRequestFormat)
+	for _, score := range req.Rubric {
+		if _, duplicate := points[score.CriterionID]; duplicate {
+			return 0, nil, fmt.Errorf("criterion %s is scored more than once", score.CriterionID)
+		}
+		points[score.CriterionID] = score
+	}
+
+	if len(points) != len(assignment.Rubric) {
+		return 0, nil, errors.New("every rubric criterion must be scored exactly once")
+	}
+
+	for _, criterion := range assignment.Rubric {
+		score, ok := points[criterion.ID]
+		if !ok {
+			return 0, nil, fmt.Errorf("criterion %s is not scored", criterion.Title)
+		}
+
+		if score.Points < 0 || score.Points > criterion.MaxPoints {
+			return 0, nil, fmt.Errorf("criterion %s must score between 0 and %d", criterion.Title, criterion.MaxPoints)
+		}
+
+		scoreID, _ := uuid.NewV4()
+		rubricScores = append(rubricScores, RubricScore{
+			ID:           scoreID,
+			SubmissionID: s.ID,
+			CriterionID:  criterion.ID,
+			Points:       score.Points,
+			Comment:      null.NewString(score.Comment, score.Comment != ""),
+		})
+		rawScore += score.Points
+	}
+
+	return
+}
+
+// SubmissionRequestFormat represents a Submission's standard formatting for JSON deserializing.
+type SubmissionRequestFormat struct {
+	Content string   `json:"content"`
+	Links   []string `json:"links" validate:"dive,url"`
+}
+
+// GradeRequestFormat represents a teacher's grade for a Submission. Assignments
+// with a rubric are graded per criterion, others with a single score.
+type GradeRequestFormat struct {
+	Score    null.Int                   `json:"score" swaggertype:"integer"`
+	Rubric   []RubricScoreRequestFormat `json:"rubric" validate:"dive"`
+	Feedback string                     `json:"feedback"`
+}
+
+// ReturnRequestFormat represents a teacher's request for a revision of a Submission.
+type ReturnRequestFormat struct {
+	Feedback string `json:"feedback" validate:"required"`
+}
+
+// SubmissionResponseFormat represents a Submission's standard formatting for JSON serializing.
+type SubmissionResponseFormat struct {
+	ID             uuid.UUID                   `json:"id"`
+	AssignmentID   uuid.UUID                   `json:"assignmentID"`
+	CourseID       uuid.UUID                   `json:"courseID"`
+	StudentID      uuid.UUID                   `json:"studentID"`
+	Status         SubmissionStatus            `json:"status"`
+	Content        string                      `json:"content"`
+	Links          []string                    `json:"links"`
+	Revision       int                         `json:"revision"`
+	SubmittedAt    time.Time                   `json:"submittedAt"`
+	IsLate         bool                        `json:"isLate"`
+	DaysLate       int                         `json:"daysLate"`
+	PenaltyPercent int                         `json:"penaltyPercent"`
+	RawScore       null.Int                    `json:"rawScore" swaggertype:"integer"`
+	Score          null.Float                  `json:"score" swaggertype:"number"`
+	Feedback       null.String                 `json:"feedback" swaggertype:"string"`
+	GradedAt       null.Time                   `json:"gradedAt"`
+	GradedBy       *uuid.UUID                  `json:"gradedBy"`
+	UpdatedAt      null.Time                   `json:"updatedAt"`
+	Rubric         []RubricScoreResponseFormat `json:"rubric"`
+}
+
+//// Rubric Score
+
+// RubricScore is the points a Submission scored on one RubricCriterion.
+type RubricScore struct {
+	ID           uuid.UUID   `db:"id"`
+	SubmissionID uuid.UUID   `db:"submission_id"`
+	CriterionID  uuid.UUID   `db:"criterion_id"`
+	Points       int         `db:"points"`
+	Comment      null.String `db:"comment"`
+}
+
+// ToResponseFormat converts this RubricScore to its response format.
+func (rs RubricScore) ToResponseFormat() RubricScoreResponseFormat {
+	return RubricScoreResponseFormat{
+		CriterionID: rs.CriterionID,
+		Points:      rs.Points,
+		Comment:     rs.Comment,
+	}
+}
+
+// RubricScoreRequestFormat represents a RubricScore's standard formatting for JSON deserializing.
+type RubricScoreRequestFormat struct {
+	CriterionID uuid.UUID `json:"criterionID" validate:"required"`
+	Points      int       `json:"points" validate:"min=0"`
+	Comment     string    `json:"comment"`
+}
+
+// RubricScoreResponseFormat represents a RubricScore's standard formatting for JSON serializing.
+type RubricScoreResponseFormat struct {
+	CriterionID uuid.UUID   `json:"criterionID"`
+	Points      int         `json:"points"`
+	Comment     null.String `json:"comment" swaggertype:"string"`
+}
+
+//// String List
+
+// StringList is a list of strings stored one per line.
+type StringList []string
+
+// Scan implements the Scanner interface.
+func (l *StringList) Scan(value interface{}) error {
+	var str string
+	switch x := value.(type) {
+	case []byte:
+		str = string(x)
+	case string:
+		str = x
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan type %T into course.StringList: %v", value, value)
+	}
+
+	list := make(StringList, 0)
+	for _, line := range strings.Split(str, "\n") {
+		if line != "" {
+			list = append(list, line)
+		}
+	}
+
+	*l = list
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return strings.Join(l, "\n"), nil
+}
@@ -0,0 +1,197 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmissionUpdateStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      course.SubmissionStatus
+		to        course.SubmissionStatus
+		wantError bool
+	}{
+		{name: "submitted to in review", from: course.SubmissionStatusSubmitted, to: course.SubmissionStatusInReview},
+		{name: "submitted to graded", from: course.SubmissionStatusSubmitted, to: course.SubmissionStatusGraded, wantError: true},
+		{name: "in review to graded", from: course.SubmissionStatusInReview, to: course.SubmissionStatusGraded},
+		{name: "in review to returned", from: course.SubmissionStatusInReview, to: course.SubmissionStatusReturned},
+		{name: "graded to returned", from: course.SubmissionStatusGraded, to: course.SubmissionStatusReturned},
+		{name: "graded to in review", from: course.SubmissionStatusGraded, to: course.SubmissionStatusInReview, wantError: true},
+		{name: "returned to submitted", from: course.SubmissionStatusReturned, to: course.SubmissionStatusSubmitted},
+		{name: "returned to graded", from: course.SubmissionStatusReturned, to: course.SubmissionStatusGraded, wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			submission := course.Submission{Status: test.from}
+
+			err := submission.UpdateStatus(test.to, getRandomUUID())
+
+			if test.wantError {
+				assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+				assert.Equal(t, test.from, submission.Status)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.to, submission.Status)
+			assert.True(t, submission.UpdatedBy.Valid)
+		})
+	}
+}
+
+func TestAssignmentLateness(t *testing.T) {
+	assignment := course.Assignment{DueAt: time.Now(), LatePenaltyPerDay: 10, MaxLatePenalty: 25}
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		wantDays    int
+		wantPenalty int
+	}{
+		{name: "on time", at: assignment.DueAt},
+		{name: "an hour late", at: assignment.DueAt.Add(time.Hour), wantDays: 1, wantPenalty: 10},
+		{name: "a day and a minute late", at: assignment.DueAt.Add(24*time.Hour + time.Minute), wantDays: 2, wantPenalty: 20},
+		{name: "capped penalty", at: assignment.DueAt.Add(5 * 24 * time.Hour), wantDays: 5, wantPenalty: 25},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			days, penalty := assignment.Lateness(test.at)
+
+			assert.Equal(t, test.wantDays, days)
+			assert.Equal(t, test.wantPenalty, penalty)
+		})
+	}
+}
+
+func TestNewSubmission(t *testing.T) {
+	t.Run("penalises late submissions", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(-36*time.Hour), nil)
+
+		submission, err := course.Submission{}.NewSubmission(assignment, course.SubmissionRequestFormat{
+			Links: []string{"https://example.com/report"},
+		}, getRandomUUID())
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.SubmissionStatusSubmitted, submission.Status)
+		assert.Equal(t, 2, submission.DaysLate)
+		assert.Equal(t, 20, submission.PenaltyPercent)
+	})
+
+	t.Run("refuses submissions after the cutoff", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(-48*time.Hour), nil)
+		assignment.LateCutoffAt = null.TimeFrom(time.Now().Add(-time.Hour))
+
+		_, err := course.Submission{}.NewSubmission(assignment, course.SubmissionRequestFormat{Content: "Done"}, getRandomUUID())
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+
+	t.Run("needs content or links", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(time.Hour), nil)
+
+		_, err := course.Submission{}.NewSubmission(assignment, course.SubmissionRequestFormat{Content: "  "}, getRandomUUID())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
+
+func TestSubmissionGrade(t *testing.T) {
+	rubric := []course.RubricCriterionRequestFormat{{Title: "Correctness", MaxPoints: 6}, {Title: "Style", MaxPoints: 4}}
+
+	t.Run("sums the rubric and deducts the late penalty", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(-time.Hour), rubric)
+		submission := newSubmissionInReview(t, assignment)
+
+		err := submission.Grade(assignment, course.GradeRequestFormat{Rubric: []course.RubricScoreRequestFormat{
+			{CriterionID: assignment.Rubric[0].ID, Points: 5},
+			{CriterionID: assignment.Rubric[1].ID, Points: 3, Comment: "Inconsistent naming"},
+		}}, getRandomUUID())
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.SubmissionStatusGraded, submission.Status)
+		assert.Equal(t, int64(8), submission.RawScore.Int64)
+		assert.Equal(t, 7.2, submission.Score.Float64)
+		assert.Len(t, submission.RubricScores, 2)
+	})
+
+	t.Run("requires every criterion", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(time.Hour), rubric)
+		submission := newSubmissionInReview(t, assignment)
+
+		err := submission.Grade(assignment, course.GradeRequestFormat{Rubric: []course.RubricScoreRequestFormat{
+			{CriterionID: assignment.Rubric[0].ID, Points: 5},
+			{CriterionID: assignment.Rubric[0].ID, Points: 5},
+		}}, getRandomUUID())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		assert.Equal(t, course.SubmissionStatusInReview, submission.Status)
+	})
+
+	t.Run("rejects points above the criterion maximum", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(time.Hour), rubric)
+		submission := newSubmissionInReview(t, assignment)
+
+		err := submission.Grade(assignment, course.GradeRequestFormat{Rubric: []course.RubricScoreRequestFormat{
+			{CriterionID: assignment.Rubric[0].ID, Points: 7},
+			{CriterionID: assignment.Rubric[1].ID, Points: 4},
+		}}, getRandomUUID())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("cannot grade before review", func(t *testing.T) {
+		assignment := newAssignment(t, time.Now().Add(time.Hour), nil)
+		submission, _ := course.Submission{}.NewSubmission(assignment, course.SubmissionRequestFormat{Content: "Done"}, getRandomUUID())
+
+		err := submission.Grade(assignment, course.GradeRequestFormat{Score: null.IntFrom(50)}, getRandomUUID())
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
+
+func TestSubmissionResubmit(t *testing.T) {
+	assignment := newAssignment(t, time.Now().Add(-time.Hour), nil)
+	submission := newSubmissionInReview(t, assignment)
+	assert.NoError(t, submission.Grade(assignment, course.GradeRequestFormat{Score: null.IntFrom(60)}, getRandomUUID()))
+	assert.NoError(t, submission.Return(course.ReturnRequestFormat{Feedback: "Add the sources"}, getRandomUUID()))
+
+	err := submission.Resubmit(course.SubmissionRequestFormat{Content: "Now with sources"}, submission.StudentID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, course.SubmissionStatusSubmitted, submission.Status)
+	assert.Equal(t, 2, submission.Revision)
+	assert.Equal(t, 10, submission.PenaltyPercent, "a revision keeps the lateness of the first submission")
+	assert.False(t, submission.Score.Valid)
+}
+
+// newAssignment creates an Assignment worth 100 points, or the sum of its
+// rubric, that loses 10 percent per day late.
+func newAssignment(t *testing.T, dueAt time.Time, rubric []course.RubricCriterionRequestFormat) course.Assignment {
+	assignment, err := course.Assignment{}.NewAssignmentFromRequestFormat(course.AssignmentRequestFormat{
+		Title:             "Essay",
+		DueAt:             dueAt,
+		MaxScore:          100,
+		LatePenaltyPerDay: 10,
+		Rubric:            rubric,
+	}, newLesson(), getRandomUUID())
+	assert.NoError(t, err)
+
+	return assignment
+}
+
+func newSubmissionInReview(t *testing.T, assignment course.Assignment) course.Submission {
+	submission, err := course.Submission{}.NewSubmission(assignment, course.SubmissionRequestFormat{Content: "My essay"}, getRandomUUID())
+	assert.NoError(t, err)
+	assert.NoError(t, submission.UpdateStatus(course.SubmissionStatusInReview, getRandomUUID()))
+
+	return submission
+}
@@ -0,0 +1,555 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source assignment_repository.go -destination mock/assignment_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	assignmentQueries = struct {
+		selectAssignment      string
+		selectRubricCriterion string
+		selectSubmission      string
+		selectRubricScore     string
+		countSubmissions      string
+		insertAssignment      string
+		insertRubricCriterion string
+		insertSubmission      string
+		insertRubricScore     string
+		updateAssignment      string
+		updateSubmission      string
+	}{
+		selectAssignment: `
+			SELECT
+				id,
+				course_id,
+				lesson_id,
+				title,
+				instructions,
+				due_at,
+				max_score,
+				late_penalty_per_day,
+				max_late_penalty,
+				late_cutoff_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM assignments
+		`,
+
+		selectRubricCriterion: `
+			SELECT
+				id,
+				assignment_id,
+				title,
+				max_points,
+				position
+			FROM assignment_rubric_criteria
+		`,
+
+		selectSubmission: `
+			SELECT
+				id,
+				assignment_id,
+				course_id,
+				student_id,
+				status,
+				content,
+				links,
+				revision,
+				submitted_at,
+				days_late,
+				penalty_percent,
+				raw_score,
+				score,
+				feedback,
+				graded_at,
+				graded_by,
+				created_at,
+				updated_at,
+				updated_by
+			FROM submissions
+		`,
+
+		selectRubricScore: `
+			SELECT
+				id,
+				submission_id,
+				criterion_id,
+				points,
+				comment
+			FROM submission_rubric_scores
+		`,
+
+		countSubmissions: `
+			SELECT COUNT(id)
+			FROM submissions
+			WHERE assignment_id = ?
+		`,
+
+		insertAssignment: `
+			INSERT INTO assignments (
+				id,
+				course_id,
+				lesson_id,
+				title,
+				instructions,
+				due_at,
+				max_score,
+				late_penalty_per_day,
+				max_late_penalty,
+				late_cutoff_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:lesson_id,
+				:title,
+				:instructions,
+				:due_at,
+				:max_score,
+				:late_penalty_per_day,
+				:max_late_penalty,
+				:late_cutoff_at,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		insertRubricCriterion: `
+			INSERT INTO assignment_rubric_criteria (
+				id,
+				assignment_id,
+				title,
+				max_points,
+				position
+			) VALUES (
+				:id,
+				:assignment_id,
+				:title,
+				:max_points,
+				:position
+			)
+		`,
+
+		insertSubmission: `
+			INSERT INTO submissions (
+				id,
+				assignment_id,
+				course_id,
+				student_id,
+				status,
+				content,
+				links,
+				revision,
+				submitted_at,
+				days_late,
+				penalty_percent,
+				raw_score,
+				score,
+				feedback,
+				graded_at,
+				graded_by,
+				created_at,
+				updated_at,
+				updated_by
+			) VALUES (
+				:id,
+				:assignment_id,
+				:course_id,
+				:student_id,
+				:status,
+				:content,
+				:links,
+				:revision,
+				:submitted_at,
+				:days_late,
+				:penalty_percent,
+				:raw_score,
+				:score,
+				:feedback,
+				:graded_at,
+				:graded_by,
+				:created_at,
+				:updated_at,
+				:updated_by
+			)
+		`,
+
+		insertRubricScore: `
+			INSERT INTO submission_rubric_scores (
+				id,
+				submission_id,
+				criterion_id,
+				points,
+				comment
+			) VALUES (
+				:id,
+				:submission_id,
+				:criterion_id,
+				:points,
+				:comment
+			)
+		`,
+
+		updateAssignment: `
+			UPDATE assignments
+			SET
+				title = :title,
+				instructions = :instructions,
+				due_at = :due_at,
+				max_score = :max_score,
+				late_penalty_per_day = :late_penalty_per_day,
+				max_late_penalty = :max_late_penalty,
+				late_cutoff_at = :late_cutoff_at,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		updateSubmission: `
+			UPDATE submissions
+			SET
+				status = :status,
+				content = :content,
+				links = :links,
+				revision = :revision,
+				submitted_at = :submitted_at,
+				raw_score = :raw_score,
+				score = :score,
+				feedback = :feedback,
+				graded_at = :graded_at,
+				graded_by = :graded_by,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+	}
+)
+
+// AssignmentRepository is the repository for Assignment data.
+type AssignmentRepository interface {
+	CountSubmissionsByAssignmentID(assignmentID uuid.UUID) (count int, err error)
+	CreateAssignment(assignment Assignment) (err error)
+	CreateSubmission(submission Submission) (err error)
+	ResolveAssignmentByID(id uuid.UUID) (assignment Assignment, err error)
+	ResolveAssignmentsByCourseID(courseID uuid.UUID) (assignments []Assignment, err error)
+	ResolveAssignmentsByLessonID(lessonID uuid.UUID) (assignments []Assignment, err error)
+	ResolveRubricByAssignmentIDs(ids []uuid.UUID) (rubric []RubricCriterion, err error)
+	ResolveRubricScoresBySubmissionIDs(ids []uuid.UUID) (scores []RubricScore, err error)
+	ResolveSubmissionByAssignmentIDAndStudentID(assignmentID uuid.UUID, studentID uuid.UUID) (submission Submission, err error)
+	ResolveSubmissionByID(id uuid.UUID) (submission Submission, err error)
+	ResolveSubmissions(params SubmissionQueryParameters) (submissions []Submission, err error)
+	UpdateAssignment(assignment Assignment) (err error)
+	UpdateAssignmentWithRubric(assignment Assignment) (err error)
+	UpdateSubmission(submission Submission) (err error)
+}
+
+// AssignmentRepositoryMySQL is the MySQL-backed implementation of AssignmentRepository.
+type AssignmentRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideAssignmentRepositoryMySQL is the provider for this repository.
+func ProvideAssignmentRepositoryMySQL(db *infras.MySQLConn) *AssignmentRepositoryMySQL {
+	s := new(AssignmentRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CountSubmissionsByAssignmentID counts the Submissions of an Assignment.
+func (r *AssignmentRepositoryMySQL) CountSubmissionsByAssignmentID(assignmentID uuid.UUID) (count int, err error) {
+	err = r.DB.Read.Get(&count, assignmentQueries.countSubmissions, assignmentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// CreateAssignment creates a new Assignment together with its rubric.
+func (r *AssignmentRepositoryMySQL) CreateAssignment(assignment Assignment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, assignmentQueries.insertAssignment, assignment); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txCreateRubric(tx, assignment.Rubric); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateSubmission creates a new Submission.
+func (r *AssignmentRepositoryMySQL) CreateSubmission(submission Submission) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, assignmentQueries.insertSubmission, submission); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveAssignmentByID resolves an Assignment by its ID, without its rubric.
+func (r *AssignmentRepositoryMySQL) ResolveAssignmentByID(id uuid.UUID) (assignment Assignment, err error) {
+	err = r.DB.Read.Get(&assignment, assignmentQueries.selectAssignment+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("assignment")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAssignmentsByCourseID resolves the active Assignments of a Course.
+func (r *AssignmentRepositoryMySQL) ResolveAssignmentsByCourseID(courseID uuid.UUID) (assignments []Assignment, err error) {
+	err = r.DB.Read.Select(
+		&assignments,
+		assignmentQueries.selectAssignment+" WHERE course_id = ? AND deleted_at IS NULL ORDER BY due_at",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAssignmentsByLessonID resolves the active Assignments of a Lesson,
+// ordered by their due date.
+func (r *AssignmentRepositoryMySQL) ResolveAssignmentsByLessonID(lessonID uuid.UUID) (assignments []Assignment, err error) {
+	err = r.DB.Read.Select(
+		&assignments,
+		assignmentQueries.selectAssignment+" WHERE lesson_id = ? AND deleted_at IS NULL ORDER BY due_at",
+		lessonID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveRubricByAssignmentIDs resolves the RubricCriteria of a set of
+// Assignments, ordered by their position.
+func (r *AssignmentRepositoryMySQL) ResolveRubricByAssignmentIDs(ids []uuid.UUID) (rubric []RubricCriterion, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(assignmentQueries.selectRubricCriterion+" WHERE assignment_id IN (?) ORDER BY position", ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&rubric, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveRubricScoresBySubmissionIDs resolves the RubricScores of a set of Submissions.
+func (r *AssignmentRepositoryMySQL) ResolveRubricScoresBySubmissionIDs(ids []uuid.UUID) (scores []RubricScore, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(assignmentQueries.selectRubricScore+" WHERE submission_id IN (?)", ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&scores, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveSubmissionByAssignmentIDAndStudentID resolves a student's Submission for an Assignment.
+func (r *AssignmentRepositoryMySQL) ResolveSubmissionByAssignmentIDAndStudentID(assignmentID uuid.UUID, studentID uuid.UUID) (submission Submission, err error) {
+	err = r.DB.Read.Get(
+		&submission,
+		assignmentQueries.selectSubmission+" WHERE assignment_id = ? AND student_id = ?",
+		assignmentID.String(),
+		studentID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("submission")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveSubmissionByID resolves a Submission by its ID.
+func (r *AssignmentRepositoryMySQL) ResolveSubmissionByID(id uuid.UUID) (submission Submission, err error) {
+	err = r.DB.Read.Get(&submission, assignmentQueries.selectSubmission+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("submission")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveSubmissions resolves the Submissions of a Course, oldest submission first.
+func (r *AssignmentRepositoryMySQL) ResolveSubmissions(params SubmissionQueryParameters) (submissions []Submission, err error) {
+	query := assignmentQueries.selectSubmission + " WHERE course_id = ?"
+	args := []interface{}{params.CourseID.String()}
+
+	if params.AssignmentID.Valid {
+		query += " AND assignment_id = ?"
+		args = append(args, params.AssignmentID.UUID.String())
+	}
+
+	if len(params.Statuses) > 0 {
+		query += " AND status IN (?)"
+		args = append(args, params.Statuses)
+	}
+
+	query += " ORDER BY submitted_at"
+
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&submissions, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateAssignment updates an Assignment, leaving its rubric untouched.
+func (r *AssignmentRepositoryMySQL) UpdateAssignment(assignment Assignment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, assignmentQueries.updateAssignment, assignment); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateAssignmentWithRubric updates an Assignment and replaces its rubric.
+func (r *AssignmentRepositoryMySQL) UpdateAssignmentWithRubric(assignment Assignment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec("DELETE FROM assignment_rubric_criteria WHERE assignment_id = ?", assignment.ID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txCreateRubric(tx, assignment.Rubric); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, assignmentQueries.updateAssignment, assignment); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateSubmission updates a Submission and replaces its RubricScores.
+func (r *AssignmentRepositoryMySQL) UpdateSubmission(submission Submission) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, assignmentQueries.updateSubmission, submission); err != nil {
+			e <- err
+			return
+		}
+
+		if _, err := tx.Exec("DELETE FROM submission_rubric_scores WHERE submission_id = ?", submission.ID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		for _, score := range submission.RubricScores {
+			if err := r.txExecNamed(tx, assignmentQueries.insertRubricScore, score); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txCreateRubric creates RubricCriteria transactionally given the *sqlx.Tx param.
+func (r *AssignmentRepositoryMySQL) txCreateRubric(tx *sqlx.Tx, rubric []RubricCriterion) (err error) {
+	for _, criterion := range rubric {
+		err = r.txExecNamed(tx, assignmentQueries.insertRubricCriterion, criterion)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *AssignmentRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,401 @@
+package course
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+)
+
+// AssignmentService is the service interface for Assignments and their Submissions.
+type AssignmentService interface {
+	CreateAssignment(lessonID uuid.UUID, requestFormat AssignmentRequestFormat, userID uuid.UUID) (assignment Assignment, err error)
+	GradeSubmission(id uuid.UUID, requestFormat GradeRequestFormat, userID uuid.UUID) (submission Submission, err error)
+	ResolveAssignmentByID(id uuid.UUID, userID uuid.UUID, role string) (assignment Assignment, err error)
+	ResolveAssignmentsByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (assignments []Assignment, err error)
+	ResolveGradingQueue(courseID uuid.UUID, userID uuid.UUID) (submissions []Submission, err error)
+	ResolveMySubmission(assignmentID uuid.UUID, studentID uuid.UUID) (submission Submission, err error)
+	ResolveSubmissionsByAssignmentID(assignmentID uuid.UUID, statuses []SubmissionStatus, userID uuid.UUID) (submissions []Submission, err error)
+	ReturnSubmission(id uuid.UUID, requestFormat ReturnRequestFormat, userID uuid.UUID) (submission Submission, err error)
+	SoftDeleteAssignment(id uuid.UUID, userID uuid.UUID) (assignment Assignment, err error)
+	StartReview(id uuid.UUID, userID uuid.UUID) (submission Submission, err error)
+	Submit(assignmentID uuid.UUID, requestFormat SubmissionRequestFormat, studentID uuid.UUID) (submission Submission, err error)
+	UpdateAssignment(id uuid.UUID, requestFormat AssignmentRequestFormat, userID uuid.UUID) (assignment Assignment, err error)
+}
+
+// AssignmentServiceImpl is the service implementation for Assignments and their Submissions.
+type AssignmentServiceImpl struct {
+	AssignmentRepository   AssignmentRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	Config                 *configs.Config
+}
+
+// ProvideAssignmentServiceImpl is the provider for this service.
+func ProvideAssignmentServiceImpl(
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	moduleRepository ModuleRepository,
+	assignmentRepository AssignmentRepository,
+	config *configs.Config) *AssignmentServiceImpl {
+	s := new(AssignmentServiceImpl)
+	s.AssignmentRepository = assignmentRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.Config = config
+
+	return s
+}
+
+// CreateAssignment attaches a new Assignment to a Lesson.
+func (s *AssignmentServiceImpl) CreateAssignment(lessonID uuid.UUID, requestFormat AssignmentRequestFormat, userID uuid.UUID) (assignment Assignment, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, lesson.CourseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	assignment, err = assignment.NewAssignmentFromRequestFormat(requestFormat, lesson, userID)
+	if err != nil {
+		return assignment, failure.BadRequest(err)
+	}
+
+	err = s.AssignmentRepository.CreateAssignment(assignment)
+	return
+}
+
+// GradeSubmission scores a Submission under review.
+func (s *AssignmentServiceImpl) GradeSubmission(id uuid.UUID, requestFormat GradeRequestFormat, userID uuid.UUID) (submission Submission, err error) {
+	submission, assignment, err := s.resolveGradableSubmission(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = submission.Grade(assignment, requestFormat, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AssignmentRepository.UpdateSubmission(submission)
+	return
+}
+
+// ResolveAssignmentByID resolves an Assignment of a readable Lesson, with its rubric.
+func (s *AssignmentServiceImpl) ResolveAssignmentByID(id uuid.UUID, userID uuid.UUID, role string) (assignment Assignment, err error) {
+	assignment, lesson, err := s.resolveAssignment(id)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReadable(s.EnrollmentRepository, s.CohortRepository, lesson, userID, role)
+	return
+}
+
+// ResolveAssignmentsByLessonID lists the Assignments of a readable Lesson.
+func (s *AssignmentServiceImpl) ResolveAssignmentsByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (assignments []Assignment, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReadable(s.EnrollmentRepository, s.CohortRepository, lesson, userID, role)
+	if err != nil {
+		return
+	}
+
+	assignments, err = s.AssignmentRepository.ResolveAssignmentsByLessonID(lesson.ID)
+	if err != nil {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(assignments))
+	for _, assignment := range assignments {
+		ids = append(ids, assignment.ID)
+	}
+
+	rubric, err := s.AssignmentRepository.ResolveRubricByAssignmentIDs(ids)
+	if err != nil {
+		return
+	}
+
+	for i := range assignments {
+		assignments[i].AttachRubric(rubric)
+	}
+
+	return
+}
+
+// ResolveGradingQueue lists the Submissions of a Course waiting for a teacher,
+// oldest first.
+func (s *AssignmentServiceImpl) ResolveGradingQueue(courseID uuid.UUID, userID uuid.UUID) (submissions []Submission, err error) {
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	return s.AssignmentRepository.ResolveSubmissions(SubmissionQueryParameters{
+		CourseID: courseID,
+		Statuses: GradingQueueStatuses,
+	})
+}
+
+// ResolveMySubmission resolves a student's own Submission for an Assignment.
+func (s *AssignmentServiceImpl) ResolveMySubmission(assignmentID uuid.UUID, studentID uuid.UUID) (submission Submission, err error) {
+	assignment, _, err := s.resolveAssignment(assignmentID)
+	if err != nil {
+		return
+	}
+
+	err = checkReadAccess(s.EnrollmentRepository, assignment.CourseID, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	submission, err = s.AssignmentRepository.ResolveSubmissionByAssignmentIDAndStudentID(assignment.ID, studentID)
+	if err != nil {
+		return
+	}
+
+	err = s.attachRubricScores(&submission)
+	return
+}
+
+// ResolveSubmissionsByAssignmentID lists the Submissions for an Assignment,
+// optionally filtered by status.
+func (s *AssignmentServiceImpl) ResolveSubmissionsByAssignmentID(assignmentID uuid.UUID, statuses []SubmissionStatus, userID uuid.UUID) (submissions []Submission, err error) {
+	assignment, err := s.resolveManagedAssignment(assignmentID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	submissions, err = s.AssignmentRepository.ResolveSubmissions(SubmissionQueryParameters{
+		CourseID:     assignment.CourseID,
+		AssignmentID: nuuid.From(assignment.ID),
+		Statuses:     statuses,
+	})
+	if err != nil {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(submissions))
+	for _, submission := range submissions {
+		ids = append(ids, submission.ID)
+	}
+
+	scores, err := s.AssignmentRepository.ResolveRubricScoresBySubmissionIDs(ids)
+	if err != nil {
+		return
+	}
+
+	for i := range submissions {
+		submissions[i].AttachRubricScores(scores)
+	}
+
+	return
+}
+
+// ReturnSubmission returns a Submission to the student for revision.
+func (s *AssignmentServiceImpl) ReturnSubmission(id uuid.UUID, requestFormat ReturnRequestFormat, userID uuid.UUID) (submission Submission, err error) {
+	submission, _, err = s.resolveGradableSubmission(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = submission.Return(requestFormat, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AssignmentRepository.UpdateSubmission(submission)
+	return
+}
+
+// SoftDeleteAssignment marks an Assignment as deleted. Its Submissions are kept.
+func (s *AssignmentServiceImpl) SoftDeleteAssignment(id uuid.UUID, userID uuid.UUID) (assignment Assignment, err error) {
+	assignment, err = s.resolveManagedAssignment(id, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	err = assignment.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AssignmentRepository.UpdateAssignment(assignment)
+	return
+}
+
+// StartReview takes a Submission out of the grading queue for review.
+func (s *AssignmentServiceImpl) StartReview(id uuid.UUID, userID uuid.UUID) (submission Submission, err error) {
+	submission, _, err = s.resolveGradableSubmission(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = submission.UpdateStatus(SubmissionStatusInReview, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AssignmentRepository.UpdateSubmission(submission)
+	return
+}
+
+// Submit hands in a student's work for an Assignment. A student has a single
+// Submission per Assignment, which can only be handed in again after the
+// teacher returned it for revision. Assignments of Lessons the student's
+// Cohort has not released yet can't be handed in.
+func (s *AssignmentServiceImpl) Submit(assignmentID uuid.UUID, requestFormat SubmissionRequestFormat, studentID uuid.UUID) (submission Submission, err error) {
+	assignment, lesson, err := s.resolveAssignment(assignmentID)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReadable(s.EnrollmentRepository, s.CohortRepository, lesson, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	submission, err = s.AssignmentRepository.ResolveSubmissionByAssignmentIDAndStudentID(assignment.ID, studentID)
+	if err != nil {
+		if failure.GetCode(err) != http.StatusNotFound {
+			return
+		}
+
+		submission, err = submission.NewSubmission(assignment, requestFormat, studentID)
+		if err != nil {
+			return
+		}
+
+		err = s.AssignmentRepository.CreateSubmission(submission)
+		return
+	}
+
+	err = submission.Resubmit(requestFormat, studentID)
+	if err != nil {
+		return
+	}
+
+	err = s.AssignmentRepository.UpdateSubmission(submission)
+	return
+}
+
+// UpdateAssignment updates an Assignment. Its rubric cannot be replaced once
+// students have handed in work, as that would invalidate their grades.
+func (s *AssignmentServiceImpl) UpdateAssignment(id uuid.UUID, requestFormat AssignmentRequestFormat, userID uuid.UUID) (assignment Assignment, err error) {
+	assignment, err = s.resolveManagedAssignment(id, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	if requestFormat.Rubric == nil {
+		err = assignment.Update(requestFormat, userID)
+		if err != nil {
+			return assignment, failure.BadRequest(err)
+		}
+
+		err = s.AssignmentRepository.UpdateAssignment(assignment)
+		return
+	}
+
+	submissions, err := s.AssignmentRepository.CountSubmissionsByAssignmentID(assignment.ID)
+	if err != nil {
+		return
+	}
+
+	if submissions > 0 {
+		return assignment, failure.Conflict("update", "assignment", "the rubric of an assignment with submissions cannot change")
+	}
+
+	err = assignment.Update(requestFormat, userID)
+	if err != nil {
+		return assignment, failure.BadRequest(err)
+	}
+
+	err = s.AssignmentRepository.UpdateAssignmentWithRubric(assignment)
+	return
+}
+
+// attachRubricScores attaches its RubricScores to a Submission.
+func (s *AssignmentServiceImpl) attachRubricScores(submission *Submission) (err error) {
+	scores, err := s.AssignmentRepository.ResolveRubricScoresBySubmissionIDs([]uuid.UUID{submission.ID})
+	if err != nil {
+		return
+	}
+
+	submission.AttachRubricScores(scores)
+
+	return
+}
+
+// resolveAssignment resolves an Assignment with its rubric that is neither
+// deleted itself nor attached to a deleted Lesson, together with that Lesson.
+func (s *AssignmentServiceImpl) resolveAssignment(id uuid.UUID) (assignment Assignment, lesson Lesson, err error) {
+	assignment, err = s.AssignmentRepository.ResolveAssignmentByID(id)
+	if err != nil {
+		return
+	}
+
+	if assignment.IsDeleted() {
+		return assignment, lesson, failure.NotFound("assignment")
+	}
+
+	lesson, err = resolveLesson(s.ModuleRepository, assignment.LessonID)
+	if err != nil {
+		return
+	}
+
+	rubric, err := s.AssignmentRepository.ResolveRubricByAssignmentIDs([]uuid.UUID{assignment.ID})
+	if err != nil {
+		return
+	}
+
+	assignment.AttachRubric(rubric)
+
+	return
+}
+
+// resolveGradableSubmission resolves a Submission with its RubricScores and
+// Assignment for a user allowed to grade in its Course.
+func (s *AssignmentServiceImpl) resolveGradableSubmission(id uuid.UUID, userID uuid.UUID) (submission Submission, assignment Assignment, err error) {
+	submission, err = s.AssignmentRepository.ResolveSubmissionByID(id)
+	if err != nil {
+		return
+	}
+
+	assignment, err = s.resolveManagedAssignment(submission.AssignmentID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	err = s.attachRubricScores(&submission)
+	return
+}
+
+// resolveManagedAssignment resolves an Assignment whose Course the given user
+// has the permission on.
+func (s *AssignmentServiceImpl) resolveManagedAssignment(id uuid.UUID, userID uuid.UUID, permission CoursePermission) (assignment Assignment, err error) {
+	assignment, _, err = s.resolveAssignment(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, assignment.CourseID, userID, permission)
+	return
+}
@@ -0,0 +1,87 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignmentService(t *testing.T) {
+	config := &configs.Config{}
+	assignment := newAssignment(t, time.Now().Add(7*24*time.Hour), nil)
+	lesson := course.Lesson{ID: assignment.LessonID, ModuleID: getRandomUUID(), CourseID: assignment.CourseID}
+
+	t.Run("students cannot read assignments of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAssignmentRepo := course_mock.NewMockAssignmentRepository(ctrl)
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		s := course.ProvideAssignmentServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, mockAssignmentRepo, config)
+		studentID := getRandomUUID()
+
+		expectAssignment(mockModuleRepo, mockAssignmentRepo, assignment, lesson)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 2)
+
+		_, err := s.ResolveAssignmentByID(assignment.ID, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students cannot list assignments of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		s := course.ProvideAssignmentServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, nil, config)
+		studentID := getRandomUUID()
+
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(lesson.ModuleID).Return(course.Module{ID: lesson.ModuleID}, nil)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 2)
+
+		_, err := s.ResolveAssignmentsByLessonID(lesson.ID, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students cannot submit assignments of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAssignmentRepo := course_mock.NewMockAssignmentRepository(ctrl)
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		s := course.ProvideAssignmentServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, mockAssignmentRepo, config)
+		studentID := getRandomUUID()
+
+		expectAssignment(mockModuleRepo, mockAssignmentRepo, assignment, lesson)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 2)
+
+		_, err := s.Submit(assignment.ID, course.SubmissionRequestFormat{Content: "My essay"}, studentID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+// expectAssignment expects an Assignment to be resolved with its rubric and Lesson.
+func expectAssignment(mockModuleRepo *course_mock.MockModuleRepository, mockAssignmentRepo *course_mock.MockAssignmentRepository, assignment course.Assignment, lesson course.Lesson) {
+	mockAssignmentRepo.EXPECT().ResolveAssignmentByID(assignment.ID).Return(assignment, nil)
+	mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+	mockModuleRepo.EXPECT().ResolveModuleByID(lesson.ModuleID).Return(course.Module{ID: lesson.ModuleID}, nil)
+	mockAssignmentRepo.EXPECT().ResolveRubricByAssignmentIDs([]uuid.UUID{assignment.ID}).Return(nil, nil)
+}
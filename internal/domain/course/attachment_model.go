@@ -0,0 +1,248 @@
+package course
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// attachmentSniffLength is the number of leading bytes the content type of an
+// upload is detected from.
+const attachmentSniffLength = 512
+
+// attachmentContentTypes lists the content types files can be attached with.
+// They are detected from the content of the upload rather than trusted from
+// the client.
+var attachmentContentTypes = map[string]bool{
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+	"image/gif":          true,
+	"image/jpeg":         true,
+	"image/png":          true,
+	"image/webp":         true,
+	"text/plain":         true,
+}
+
+//// Attachments
+
+// Attachment is a file, such as a PDF, an image or a code archive, attached
+// to a Lesson. The file itself is kept in blob storage under StorageKey.
+type Attachment struct {
+	ID          uuid.UUID `db:"id" validate:"required"`
+	LessonID    uuid.UUID `db:"lesson_id" validate:"required"`
+	CourseID    uuid.UUID `db:"course_id" validate:"required"`
+	FileName    string    `db:"file_name" validate:"required,max=255"`
+	ContentType string    `db:"content_type" validate:"required,max=100"`
+	Size        int64     `db:"size" validate:"min=1"`
+	// Checksum is the hex-encoded SHA-256 digest of the file.
+	Checksum   string      `db:"checksum" validate:"required,len=64,hexadecimal"`
+	StorageKey string      `db:"storage_key" validate:"required,max=255"`
+	CreatedAt  time.Time   `db:"created_at" validate:"required"`
+	CreatedBy  uuid.UUID   `db:"created_by" validate:"required"`
+	DeletedAt  null.Time   `db:"deleted_at"`
+	DeletedBy  nuuid.NUUID `db:"deleted_by"`
+	// DownloadURL is a signed link to the file, valid until DownloadURLExpiresAt.
+	DownloadURL          null.String `db:"-"`
+	DownloadURLExpiresAt null.Time   `db:"-"`
+}
+
+// AttachmentUpload is a file uploaded to be attached to a Lesson.
+type AttachmentUpload struct {
+	FileName string
+	Size     int64
+	Body     io.ReadSeeker
+	// Checksum optionally carries the hex-encoded SHA-256 digest the client
+	// computed, to detect uploads corrupted on the way.
+	Checksum string
+}
+
+// NewAttachmentFromUpload creates an Attachment of a Lesson from an upload no
+// larger than maxSize bytes. It detects the content type of the upload and
+// computes its checksum, leaving the upload's body rewound.
+func (a Attachment) NewAttachmentFromUpload(lesson Lesson, upload AttachmentUpload, maxSize int64, userID uuid.UUID) (newAttachment Attachment, err error) {
+	if upload.Size <= 0 {
+		return newAttachment, failure.BadRequestFromString("the file is empty")
+	}
+
+	if upload.Size > maxSize {
+		return newAttachment, failure.BadRequestFromString(fmt.Sprintf("the file is larger than the %d bytes limit", maxSize))
+	}
+
+	contentType, err := detectContentType(upload.Body)
+	if err != nil {
+		return
+	}
+
+	if !attachmentContentTypes[contentType] {
+		return newAttachment, failure.BadRequestFromString(fmt.Sprintf("files of type %s cannot be attached", contentType))
+	}
+
+	checksum, err := checksumUpload(upload)
+	if err != nil {
+		return
+	}
+
+	attachmentID, _ := uuid.NewV4()
+	newAttachment = Attachment{
+		ID:          attachmentID,
+		LessonID:    lesson.ID,
+		CourseID:    lesson.CourseID,
+		FileName:    sanitizeFileName(upload.FileName),
+		ContentType: contentType,
+		Size:        upload.Size,
+		Checksum:    checksum,
+		StorageKey:  attachmentStorageKey(lesson, attachmentID),
+		CreatedAt:   time.Now(),
+		CreatedBy:   userID,
+	}
+
+	err = newAttachment.Validate()
+	if err != nil {
+		return newAttachment, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// IsDeleted checks whether an Attachment is soft deleted.
+func (a *Attachment) IsDeleted() bool {
+	return a.DeletedAt.Valid
+}
+
+// SetDownloadURL sets the signed link to the file of an Attachment.
+func (a *Attachment) SetDownloadURL(url string, expiresAt time.Time) {
+	a.DownloadURL = null.StringFrom(url)
+	a.DownloadURLExpiresAt = null.TimeFrom(expiresAt)
+}
+
+// SoftDelete marks an Attachment as deleted.
+func (a *Attachment) SoftDelete(userID uuid.UUID) (err error) {
+	if a.IsDeleted() {
+		return failure.Conflict("softDelete", "attachment", "already marked as deleted")
+	}
+
+	a.DeletedAt = null.TimeFrom(time.Now())
+	a.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// Validate validates the entity.
+func (a *Attachment) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(a)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (a Attachment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ToResponseFormat())
+}
+
+// ToResponseFormat converts this Attachment to its response format.
+func (a Attachment) ToResponseFormat() AttachmentResponseFormat {
+	return AttachmentResponseFormat{
+		ID:                   a.ID,
+		LessonID:             a.LessonID,
+		CourseID:             a.CourseID,
+		FileName:             a.FileName,
+		ContentType:          a.ContentType,
+		Size:                 a.Size,
+		Checksum:             a.Checksum,
+		DownloadURL:          a.DownloadURL,
+		DownloadURLExpiresAt: a.DownloadURLExpiresAt,
+		CreatedAt:            a.CreatedAt,
+		CreatedBy:            a.CreatedBy,
+	}
+}
+
+// AttachmentResponseFormat represents an Attachment's standard formatting for JSON serializing.
+type AttachmentResponseFormat struct {
+	ID                   uuid.UUID   `json:"id"`
+	LessonID             uuid.UUID   `json:"lessonID"`
+	CourseID             uuid.UUID   `json:"courseID"`
+	FileName             string      `json:"fileName"`
+	ContentType          string      `json:"contentType"`
+	Size                 int64       `json:"size"`
+	Checksum             string      `json:"checksum"`
+	DownloadURL          null.String `json:"downloadURL" swaggertype:"string"`
+	DownloadURLExpiresAt null.Time   `json:"downloadURLExpiresAt"`
+	CreatedAt            time.Time   `json:"createdAt"`
+	CreatedBy            uuid.UUID   `json:"createdBy"`
+}
+
+// attachmentStorageKey is the key the file of an Attachment is kept under in
+// blob storage.
+func attachmentStorageKey(lesson Lesson, id uuid.UUID) string {
+	return fmt.Sprintf("courses/%s/lessons/%s/%s", lesson.CourseID, lesson.ID, id)
+}
+
+// checksumUpload computes the SHA-256 checksum of an upload, checking it
+// matches both its declared size and the checksum the client sent, if any.
+func checksumUpload(upload AttachmentUpload) (checksum string, err error) {
+	hash := sha256.New()
+	read, err := io.Copy(hash, upload.Body)
+	if err != nil {
+		return
+	}
+
+	if _, err = upload.Body.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+
+	if read != upload.Size {
+		return "", failure.BadRequestFromString("the file does not match its declared size")
+	}
+
+	checksum = hex.EncodeToString(hash.Sum(nil))
+	if upload.Checksum != "" && !strings.EqualFold(upload.Checksum, checksum) {
+		return "", failure.BadRequestFromString("the file does not match its checksum; upload it again")
+	}
+
+	return
+}
+
+// detectContentType detects the media type of an upload from its leading
+// bytes.
+func detectContentType(body io.ReadSeeker) (contentType string, err error) {
+	buf := make([]byte, attachmentSniffLength)
+	n, err := io.ReadFull(body, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return
+	}
+
+	if _, err = body.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+
+	contentType, _, err = mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	return
+}
+
+// sanitizeFileName strips the directories some clients send along with the
+// name of an uploaded file.
+func sanitizeFileName(fileName string) string {
+	name := path.Base(strings.ReplaceAll(strings.TrimSpace(fileName), `\`, "/"))
+	if name == "." || name == "/" {
+		return "attachment"
+	}
+
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+
+	return name
+}
@@ -0,0 +1,71 @@
+package course_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUpload(fileName string, content string) course.AttachmentUpload {
+	return course.AttachmentUpload{
+		FileName: fileName,
+		Size:     int64(len(content)),
+		Body:     strings.NewReader(content),
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	lesson := newDraftCourse().Modules[0].Lessons[0]
+	userID := getRandomUUID()
+	pdf := "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"
+
+	t.Run("uploads are checksummed and typed from their content", func(t *testing.T) {
+		digest := sha256.Sum256([]byte(pdf))
+
+		attachment, err := course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload(`C:\Users\me\notes.pdf`, pdf), 1<<20, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "notes.pdf", attachment.FileName)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.Equal(t, hex.EncodeToString(digest[:]), attachment.Checksum)
+		assert.True(t, strings.HasSuffix(attachment.StorageKey, attachment.ID.String()))
+	})
+
+	t.Run("uploads are rewound for storage", func(t *testing.T) {
+		upload := newUpload("notes.pdf", pdf)
+
+		_, err := course.Attachment{}.NewAttachmentFromUpload(lesson, upload, 1<<20, userID)
+		assert.NoError(t, err)
+
+		rest := make([]byte, 4)
+		_, err = upload.Body.Read(rest)
+		assert.NoError(t, err)
+		assert.Equal(t, "%PDF", string(rest))
+	})
+
+	t.Run("uploads are limited in size and type", func(t *testing.T) {
+		_, err := course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload("notes.pdf", pdf), 8, userID)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+
+		_, err = course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload("empty.txt", ""), 1<<20, userID)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+
+		_, err = course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload("page.pdf", "<html><body>hi</body></html>"), 1<<20, userID)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("uploads must match the checksum the client sent", func(t *testing.T) {
+		upload := newUpload("notes.pdf", pdf)
+		upload.Checksum = strings.Repeat("0", 64)
+
+		_, err := course.Attachment{}.NewAttachmentFromUpload(lesson, upload, 1<<20, userID)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
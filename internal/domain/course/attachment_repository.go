@@ -0,0 +1,169 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source attachment_repository.go -destination mock/attachment_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	attachmentQueries = struct {
+		selectAttachment string
+		insertAttachment string
+		updateAttachment string
+	}{
+		selectAttachment: `
+			SELECT
+				id,
+				lesson_id,
+				course_id,
+				file_name,
+				content_type,
+				size,
+				checksum,
+				storage_key,
+				created_at,
+				created_by,
+				deleted_at,
+				deleted_by
+			FROM lesson_attachments
+		`,
+
+		insertAttachment: `
+			INSERT INTO lesson_attachments (
+				id,
+				lesson_id,
+				course_id,
+				file_name,
+				content_type,
+				size,
+				checksum,
+				storage_key,
+				created_at,
+				created_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:lesson_id,
+				:course_id,
+				:file_name,
+				:content_type,
+				:size,
+				:checksum,
+				:storage_key,
+				:created_at,
+				:created_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		updateAttachment: `
+			UPDATE lesson_attachments
+			SET
+				file_name = :file_name,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+	}
+)
+
+// AttachmentRepository is the repository for Attachments of Lessons.
+type AttachmentRepository interface {
+	CreateAttachment(attachment Attachment) (err error)
+	ResolveAttachmentByID(id uuid.UUID) (attachment Attachment, err error)
+	ResolveAttachmentsByLessonID(lessonID uuid.UUID) (attachments []Attachment, err error)
+	UpdateAttachment(attachment Attachment) (err error)
+}
+
+// AttachmentRepositoryMySQL is the MySQL-backed implementation of AttachmentRepository.
+type AttachmentRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideAttachmentRepositoryMySQL is the provider for this repository.
+func ProvideAttachmentRepositoryMySQL(db *infras.MySQLConn) *AttachmentRepositoryMySQL {
+	s := new(AttachmentRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateAttachment creates an Attachment.
+func (r *AttachmentRepositoryMySQL) CreateAttachment(attachment Attachment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, attachmentQueries.insertAttachment, attachment); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveAttachmentByID resolves an Attachment by its ID.
+func (r *AttachmentRepositoryMySQL) ResolveAttachmentByID(id uuid.UUID) (attachment Attachment, err error) {
+	err = r.DB.Read.Get(&attachment, attachmentQueries.selectAttachment+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("attachment")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAttachmentsByLessonID resolves the Attachments of a Lesson, oldest
+// first, leaving out deleted ones.
+func (r *AttachmentRepositoryMySQL) ResolveAttachmentsByLessonID(lessonID uuid.UUID) (attachments []Attachment, err error) {
+	err = r.DB.Read.Select(
+		&attachments,
+		attachmentQueries.selectAttachment+" WHERE lesson_id = ? AND deleted_at IS NULL ORDER BY created_at, id",
+		lessonID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateAttachment updates an Attachment.
+func (r *AttachmentRepositoryMySQL) UpdateAttachment(attachment Attachment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, attachmentQueries.updateAttachment, attachment); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *AttachmentRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,263 @@
+package course
+
+import (
+	"io"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras/storage"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	defaultAttachmentMaxSizeMB        = 25
+	defaultAttachmentURLExpiryMinutes = 15
+)
+
+// AttachmentService is the service interface for Attachments of Lessons.
+type AttachmentService interface {
+	AttachmentSizeLimit() (maxSize int64)
+	CreateAttachment(lessonID uuid.UUID, upload AttachmentUpload, userID uuid.UUID) (attachment Attachment, err error)
+	DeleteAttachment(id uuid.UUID, userID uuid.UUID) (attachment Attachment, err error)
+	OpenSignedFile(key string, fileName string, expires string, signature string) (file io.ReadCloser, err error)
+	ResolveAttachmentByID(id uuid.UUID, userID uuid.UUID, role string) (attachment Attachment, err error)
+	ResolveAttachmentsByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (attachments []Attachment, err error)
+}
+
+// AttachmentServiceImpl is the service implementation for Attachments of Lessons.
+type AttachmentServiceImpl struct {
+	AttachmentRepository   AttachmentRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	Storage                storage.Storage
+	Config                 *configs.Config
+}
+
+// ProvideAttachmentServiceImpl is the provider for this service.
+func ProvideAttachmentServiceImpl(
+	attachmentRepository AttachmentRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	moduleRepository ModuleRepository,
+	storage storage.Storage,
+	config *configs.Config) *AttachmentServiceImpl {
+	s := new(AttachmentServiceImpl)
+	s.AttachmentRepository = attachmentRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.Storage = storage
+	s.Config = config
+
+	return s
+}
+
+// AttachmentSizeLimit returns the size, in bytes, files attached to Lessons
+// cannot exceed.
+func (s *AttachmentServiceImpl) AttachmentSizeLimit() (maxSize int64) {
+	return attachmentSizeLimit(s.Config)
+}
+
+// CreateAttachment attaches an uploaded file to a Lesson, storing it in blob
+// storage.
+func (s *AttachmentServiceImpl) CreateAttachment(lessonID uuid.UUID, upload AttachmentUpload, userID uuid.UUID) (attachment Attachment, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, lesson.CourseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	attachment, err = Attachment{}.NewAttachmentFromUpload(lesson, upload, s.AttachmentSizeLimit(), userID)
+	if err != nil {
+		return
+	}
+
+	err = s.Storage.Put(attachment.StorageKey, upload.Body, attachment.Size, attachment.ContentType)
+	if err != nil {
+		return
+	}
+
+	err = s.AttachmentRepository.CreateAttachment(attachment)
+	if err != nil {
+		// don't leave the file behind without an Attachment pointing to it
+		if deleteErr := s.Storage.Delete(attachment.StorageKey); deleteErr != nil {
+			logger.ErrorWithStack(deleteErr)
+		}
+
+		return
+	}
+
+	err = s.signDownloadURL(&attachment)
+	return
+}
+
+// DeleteAttachment soft deletes an Attachment and removes its file from blob
+// storage. Download links handed out before stop working.
+func (s *AttachmentServiceImpl) DeleteAttachment(id uuid.UUID, userID uuid.UUID) (attachment Attachment, err error) {
+	attachment, err = s.resolveAttachment(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, attachment.CourseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	err = attachment.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.AttachmentRepository.UpdateAttachment(attachment)
+	if err != nil {
+		return
+	}
+
+	// the Attachment is gone either way; a leftover file is only wasted space
+	if deleteErr := s.Storage.Delete(attachment.StorageKey); deleteErr != nil {
+		logger.ErrorWithStack(deleteErr)
+	}
+
+	return
+}
+
+// OpenSignedFile opens a file of the local blob storage from the parameters
+// of a download link it signed. Other storages serve their signed links
+// themselves.
+func (s *AttachmentServiceImpl) OpenSignedFile(key string, fileName string, expires string, signature string) (file io.ReadCloser, err error) {
+	local, ok := s.Storage.(*storage.LocalStorage)
+	if !ok {
+		return nil, failure.NotFound("file")
+	}
+
+	err = local.Verify(key, fileName, expires, signature)
+	if err != nil {
+		return
+	}
+
+	return local.Open(key)
+}
+
+// ResolveAttachmentByID resolves an Attachment with a fresh download link.
+func (s *AttachmentServiceImpl) ResolveAttachmentByID(id uuid.UUID, userID uuid.UUID, role string) (attachment Attachment, err error) {
+	attachment, err = s.resolveAttachment(id)
+	if err != nil {
+		return
+	}
+
+	_, err = s.resolveReadableLesson(attachment.LessonID, userID, role)
+	if err != nil {
+		return
+	}
+
+	err = s.signDownloadURL(&attachment)
+	return
+}
+
+// ResolveAttachmentsByLessonID resolves the Attachments of a Lesson, each with
+// a fresh download link. Students need to be able to read the Lesson.
+func (s *AttachmentServiceImpl) ResolveAttachmentsByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (attachments []Attachment, err error) {
+	lesson, err := s.resolveReadableLesson(lessonID, userID, role)
+	if err != nil {
+		return
+	}
+
+	attachments, err = s.AttachmentRepository.ResolveAttachmentsByLessonID(lesson.ID)
+	if err != nil {
+		return
+	}
+
+	for i := range attachments {
+		err = s.signDownloadURL(&attachments[i])
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// attachmentSizeLimit returns the configured size, in bytes, files attached to
+// Lessons cannot exceed.
+func attachmentSizeLimit(config *configs.Config) (maxSize int64) {
+	maxSizeMB := config.Attachment.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultAttachmentMaxSizeMB
+	}
+
+	return maxSizeMB << 20
+}
+
+// resolveAttachment resolves an Attachment that is not deleted.
+func (s *AttachmentServiceImpl) resolveAttachment(id uuid.UUID) (attachment Attachment, err error) {
+	attachment, err = s.AttachmentRepository.ResolveAttachmentByID(id)
+	if err != nil {
+		return
+	}
+
+	if attachment.IsDeleted() {
+		return attachment, failure.NotFound("attachment")
+	}
+
+	return
+}
+
+// resolveReadableLesson resolves a Lesson on behalf of a user, who must be a
+// teacher or a student actively enrolled in a Course visible to students,
+// whose Cohort's drip schedule released the Lesson.
+func (s *AttachmentServiceImpl) resolveReadableLesson(id uuid.UUID, userID uuid.UUID, role string) (lesson Lesson, err error) {
+	lesson, err = resolveLesson(s.ModuleRepository, id)
+	if err != nil || role == shared.RoleTeacher {
+		return
+	}
+
+	err = checkReadAccess(s.EnrollmentRepository, lesson.CourseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	course, err := s.CourseRepository.ResolveCourseByID(lesson.CourseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || !course.IsVisibleToStudents() {
+		return lesson, failure.NotFound("lesson")
+	}
+
+	err = checkLessonReleased(s.EnrollmentRepository, s.CohortRepository, lesson, userID)
+	return
+}
+
+// signDownloadURL sets a download link to the file of an Attachment, which
+// expires after the configured time.
+func (s *AttachmentServiceImpl) signDownloadURL(attachment *Attachment) (err error) {
+	expiryMinutes := s.Config.Attachment.URLExpiryMinutes
+	if expiryMinutes <= 0 {
+		expiryMinutes = defaultAttachmentURLExpiryMinutes
+	}
+
+	expiry := time.Duration(expiryMinutes) * time.Minute
+	url, err := s.Storage.SignedURL(attachment.StorageKey, attachment.FileName, expiry)
+	if err != nil {
+		return
+	}
+
+	attachment.SetDownloadURL(url, time.Now().Add(expiry))
+	return
+}
@@ -0,0 +1,130 @@
+package course_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStorage keeps blobs in memory.
+type memoryStorage struct {
+	blobs map[string][]byte
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *memoryStorage) Open(key string) (io.ReadCloser, error) {
+	blob, ok := s.blobs[key]
+	if !ok {
+		return nil, errors.New("no such blob")
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (s *memoryStorage) Put(key string, body io.ReadSeeker, size int64, contentType string) error {
+	blob, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.blobs[key] = blob
+	return nil
+}
+
+func (s *memoryStorage) SignedURL(key string, fileName string, expiry time.Duration) (string, error) {
+	return "https://files.example.com/" + key, nil
+}
+
+func TestAttachmentService(t *testing.T) {
+	config := &configs.Config{}
+	c := newDraftCourse()
+	lesson := c.Modules[0].Lessons[0]
+	module := c.Modules[0]
+	pdf := "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"
+
+	t.Run("teachers who edit the content attach files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAttachmentRepo := course_mock.NewMockAttachmentRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		blobs := &memoryStorage{blobs: map[string][]byte{}}
+		s := course.ProvideAttachmentServiceImpl(mockAttachmentRepo, nil, nil, mockCourseRepo, nil, mockModuleRepo, blobs, config)
+
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(module.ID).Return(module, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(c, nil)
+		mockAttachmentRepo.EXPECT().CreateAttachment(gomock.Any()).Return(nil)
+
+		attachment, err := s.CreateAttachment(lesson.ID, newUpload("notes.pdf", pdf), c.UserID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, pdf, string(blobs.blobs[attachment.StorageKey]))
+		assert.Equal(t, "https://files.example.com/"+attachment.StorageKey, attachment.DownloadURL.String)
+		assert.True(t, attachment.DownloadURLExpiresAt.Time.After(time.Now().Add(14*time.Minute)))
+	})
+
+	t.Run("files are removed when their attachment cannot be saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAttachmentRepo := course_mock.NewMockAttachmentRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		blobs := &memoryStorage{blobs: map[string][]byte{}}
+		s := course.ProvideAttachmentServiceImpl(mockAttachmentRepo, nil, nil, mockCourseRepo, nil, mockModuleRepo, blobs, config)
+
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(module.ID).Return(module, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(c.ID).Return(c, nil)
+		mockAttachmentRepo.EXPECT().CreateAttachment(gomock.Any()).Return(errors.New("connection lost"))
+
+		_, err := s.CreateAttachment(lesson.ID, newUpload("notes.pdf", pdf), c.UserID)
+
+		assert.Error(t, err)
+		assert.Empty(t, blobs.blobs)
+	})
+
+	t.Run("students need an active enrollment to list attachments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		s := course.ProvideAttachmentServiceImpl(nil, nil, nil, nil, mockEnrollmentRepo, mockModuleRepo, &memoryStorage{}, config)
+		studentID := getRandomUUID()
+
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(module.ID).Return(module, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(c.ID, studentID).Return(newEnrollment(c.ID, studentID, course.EnrollmentStatusWithdrawn), nil)
+
+		_, err := s.ResolveAttachmentsByLessonID(lesson.ID, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("only local storage serves signed files", func(t *testing.T) {
+		s := course.ProvideAttachmentServiceImpl(nil, nil, nil, nil, nil, nil, &memoryStorage{}, config)
+
+		_, err := s.OpenSignedFile("courses/1/lessons/2/3", "notes.pdf", "0", "")
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
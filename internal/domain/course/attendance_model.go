@@ -0,0 +1,590 @@
+package course
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// AttendanceStatus indicates whether a student attended an occurrence of a
+// LiveSession.
+type AttendanceStatus string
+
+const (
+	// AttendanceStatusPresent indicates a student who attended on time.
+	AttendanceStatusPresent AttendanceStatus = "present"
+	// AttendanceStatusLate indicates a student who attended but came in late.
+	AttendanceStatusLate AttendanceStatus = "late"
+	// AttendanceStatusAbsent indicates a student who did not attend.
+	AttendanceStatusAbsent AttendanceStatus = "absent"
+	// AttendanceStatusExcused indicates a student who was excused from
+	// attending. Excused occurrences don't count toward the attendance rate.
+	AttendanceStatusExcused AttendanceStatus = "excused"
+	// AttendanceStatusUnmarked indicates an occurrence attendance was not taken
+	// for. It is never stored, and counts as an absence in reports.
+	AttendanceStatusUnmarked AttendanceStatus = "unmarked"
+)
+
+const (
+	// checkInCodeLength is the length of the codes students check in with.
+	checkInCodeLength = 6
+	// defaultCheckInMinutes is how long a check-in code stays valid unless the
+	// teacher asks otherwise.
+	defaultCheckInMinutes = 10
+	// lateCheckInAfter is how long after an occurrence starts a check-in still
+	// counts as on time.
+	lateCheckInAfter = 10 * time.Minute
+)
+
+var (
+	errAlreadyCheckedIn   = failure.Conflict("checkIn", "attendance", "attendance was already recorded")
+	errInvalidCheckInCode = failure.BadRequestFromString("invalid or expired check-in code")
+)
+
+//// Attendance Record
+
+// AttendanceRecord records whether a student attended an occurrence of a
+// LiveSession.
+type AttendanceRecord struct {
+	SessionID   uuid.UUID        `db:"session_id" validate:"required"`
+	Sequence    int              `db:"sequence" validate:"min=1"`
+	StudentID   uuid.UUID        `db:"student_id" validate:"required"`
+	CourseID    uuid.UUID        `db:"course_id" validate:"required"`
+	Status      AttendanceStatus `db:"status" validate:"required,oneof=present late absent excused"`
+	CheckedInAt null.Time        `db:"checked_in_at"`
+	Note        string           `db:"note" validate:"max=500"`
+	MarkedAt    time.Time        `db:"marked_at" validate:"required"`
+	MarkedBy    uuid.UUID        `db:"marked_by" validate:"required"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (a AttendanceRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ToResponseFormat())
+}
+
+// NewAttendanceRecord creates an AttendanceRecord a teacher marked for a
+// student.
+func (a AttendanceRecord) NewAttendanceRecord(courseID uuid.UUID, occurrence LiveSessionOccurrence, req AttendanceRecordRequestFormat, userID uuid.UUID) (newRecord AttendanceRecord, err error) {
+	newRecord = AttendanceRecord{
+		SessionID: occurrence.SessionID,
+		Sequence:  occurrence.Sequence,
+		StudentID: req.StudentID,
+		CourseID:  courseID,
+		Status:    req.Status,
+		Note:      req.Note,
+		MarkedAt:  time.Now(),
+		MarkedBy:  userID,
+	}
+
+	err = newRecord.Validate()
+	return
+}
+
+// NewCheckInRecord creates the AttendanceRecord of a student who checked in
+// to an occurrence at the given time. Students checking in more than
+// lateCheckInAfter after it started are late.
+func (a AttendanceRecord) NewCheckInRecord(courseID uuid.UUID, occurrence LiveSessionOccurrence, studentID uuid.UUID, checkedInAt time.Time) AttendanceRecord {
+	status := AttendanceStatusPresent
+	if checkedInAt.After(occurrence.StartsAt.Add(lateCheckInAfter)) {
+		status = AttendanceStatusLate
+	}
+
+	return AttendanceRecord{
+		SessionID:   occurrence.SessionID,
+		Sequence:    occurrence.Sequence,
+		StudentID:   studentID,
+		CourseID:    courseID,
+		Status:      status,
+		CheckedInAt: null.TimeFrom(checkedInAt),
+		MarkedAt:    checkedInAt,
+		MarkedBy:    studentID,
+	}
+}
+
+// ToResponseFormat converts this AttendanceRecord to its response format.
+func (a AttendanceRecord) ToResponseFormat() AttendanceRecordResponseFormat {
+	return AttendanceRecordResponseFormat{
+		SessionID:   a.SessionID,
+		Sequence:    a.Sequence,
+		StudentID:   a.StudentID,
+		Status:      a.Status,
+		CheckedInAt: a.CheckedInAt,
+		Note:        a.Note,
+		MarkedAt:    null.TimeFrom(a.MarkedAt),
+		MarkedBy:    nuuid.From(a.MarkedBy).Ptr(),
+	}
+}
+
+// Validate validates the entity.
+func (a *AttendanceRecord) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(a)
+}
+
+// AttendanceRequestFormat represents a request to mark the attendance of an
+// occurrence in bulk. Students left out of Records are marked with
+// RemainingStatus when it is set, unless they already have a record.
+type AttendanceRequestFormat struct {
+	Records         []AttendanceRecordRequestFormat `json:"records" validate:"max=500,dive"`
+	RemainingStatus AttendanceStatus                `json:"remainingStatus" validate:"omitempty,oneof=present late absent excused"`
+}
+
+// AttendanceRecordRequestFormat represents the attendance of a student marked
+// as part of a bulk request.
+type AttendanceRecordRequestFormat struct {
+	StudentID uuid.UUID `json:"studentID" validate:"required"`
+	AttendanceMarkRequestFormat
+}
+
+// AttendanceMarkRequestFormat represents the attendance a teacher marked for a
+// single student.
+type AttendanceMarkRequestFormat struct {
+	Status AttendanceStatus `json:"status" validate:"required,oneof=present late absent excused"`
+	Note   string           `json:"note" validate:"max=500"`
+}
+
+// AttendanceRecordResponseFormat represents an AttendanceRecord's standard
+// formatting for JSON serializing. Students without a record are unmarked.
+type AttendanceRecordResponseFormat struct {
+	SessionID   uuid.UUID        `json:"sessionID"`
+	Sequence    int              `json:"sequence"`
+	StudentID   uuid.UUID        `json:"studentID"`
+	Status      AttendanceStatus `json:"status"`
+	CheckedInAt null.Time        `json:"checkedInAt"`
+	Note        string           `json:"note"`
+	MarkedAt    null.Time        `json:"markedAt"`
+	MarkedBy    *uuid.UUID       `json:"markedBy"`
+}
+
+//// Check-in Code
+
+// CheckInCode is a short-lived code students check in to an occurrence of a
+// LiveSession with. Opening a new code for an occurrence replaces the
+// previous one.
+type CheckInCode struct {
+	SessionID uuid.UUID `db:"session_id" validate:"required"`
+	Sequence  int       `db:"sequence" validate:"min=1"`
+	Code      string    `db:"code" validate:"required,len=6"`
+	ExpiresAt time.Time `db:"expires_at" validate:"required"`
+	CreatedAt time.Time `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID `db:"created_by" validate:"required"`
+}
+
+// IsExpired checks whether a CheckInCode can no longer be checked in with.
+func (c *CheckInCode) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c CheckInCode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// NewCheckInCode opens a CheckInCode for an occurrence of a LiveSession.
+func (c CheckInCode) NewCheckInCode(occurrence LiveSessionOccurrence, req CheckInCodeRequestFormat, userID uuid.UUID) (newCode CheckInCode, err error) {
+	err = shared.GetValidator().Struct(req)
+	if err != nil {
+		return
+	}
+
+	code, err := randomCode(checkInCodeLength)
+	if err != nil {
+		return
+	}
+
+	validFor := req.ValidForMinutes
+	if validFor == 0 {
+		validFor = defaultCheckInMinutes
+	}
+
+	now := time.Now()
+	newCode = CheckInCode{
+		SessionID: occurrence.SessionID,
+		Sequence:  occurrence.Sequence,
+		Code:      code,
+		ExpiresAt: now.Add(time.Duration(validFor) * time.Minute),
+		CreatedAt: now,
+		CreatedBy: userID,
+	}
+
+	err = newCode.Validate()
+	return
+}
+
+// ToResponseFormat converts this CheckInCode to its response format.
+func (c CheckInCode) ToResponseFormat() CheckInCodeResponseFormat {
+	return CheckInCodeResponseFormat{
+		SessionID: c.SessionID,
+		Sequence:  c.Sequence,
+		Code:      c.Code,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+// Validate validates the entity.
+func (c *CheckInCode) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(c)
+}
+
+// NormalizeCheckInCode makes a typed-in check-in code comparable to stored
+// ones.
+func NormalizeCheckInCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CheckInCodeRequestFormat represents a request to open a CheckInCode.
+type CheckInCodeRequestFormat struct {
+	ValidForMinutes int `json:"validForMinutes" validate:"omitempty,min=1,max=60"`
+}
+
+// CheckInCodeResponseFormat represents a CheckInCode's standard formatting
+// for JSON serializing.
+type CheckInCodeResponseFormat struct {
+	SessionID uuid.UUID `json:"sessionID"`
+	Sequence  int       `json:"sequence"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CheckInRequestFormat represents a student checking in with a code.
+type CheckInRequestFormat struct {
+	Code string `json:"code" validate:"required"`
+}
+
+//// Attendance Sheet
+
+// AttendanceSheet lists the attendance of every student expected at an
+// occurrence of a LiveSession.
+type AttendanceSheet struct {
+	Occurrence LiveSessionOccurrence            `json:"occurrence"`
+	Records    []AttendanceRecordResponseFormat `json:"records"`
+}
+
+// NewAttendanceSheet builds the AttendanceSheet of an occurrence from the
+// Enrollments of the students expected at it and the records marked so far.
+func NewAttendanceSheet(occurrence LiveSessionOccurrence, attendees []Enrollment, records []AttendanceRecord) AttendanceSheet {
+	recordByStudent := make(map[uuid.UUID]AttendanceRecord, len(records))
+	for _, record := range records {
+		recordByStudent[record.StudentID] = record
+	}
+
+	sheet := AttendanceSheet{
+		Occurrence: occurrence,
+		Records:    make([]AttendanceRecordResponseFormat, 0, len(attendees)),
+	}
+	for _, attendee := range attendees {
+		record, ok := recordByStudent[attendee.StudentID]
+		if !ok {
+			sheet.Records = append(sheet.Records, AttendanceRecordResponseFormat{
+				SessionID: occurrence.SessionID,
+				Sequence:  occurrence.Sequence,
+				StudentID: attendee.StudentID,
+				Status:    AttendanceStatusUnmarked,
+			})
+			continue
+		}
+
+		sheet.Records = append(sheet.Records, record.ToResponseFormat())
+	}
+
+	return sheet
+}
+
+//// Attendance Reports
+
+// AttendanceSummary counts how often a student attended the occurrences of
+// the LiveSessions they were expected at. Present and late students attended;
+// excused occurrences are left out of the attendance percentage, which is not
+// set while nothing counts toward it.
+type AttendanceSummary struct {
+	StudentID            uuid.UUID  `json:"studentID"`
+	CohortID             *uuid.UUID `json:"cohortID"`
+	Sessions             int        `json:"sessions"`
+	Present              int        `json:"present"`
+	Late                 int        `json:"late"`
+	Absent               int        `json:"absent"`
+	Excused              int        `json:"excused"`
+	Unmarked             int        `json:"unmarked"`
+	AttendancePercentage null.Float `json:"attendancePercentage"`
+}
+
+// add counts an occurrence a student was expected at.
+func (s *AttendanceSummary) add(status AttendanceStatus) {
+	s.Sessions++
+	switch status {
+	case AttendanceStatusPresent:
+		s.Present++
+	case AttendanceStatusLate:
+		s.Late++
+	case AttendanceStatusAbsent:
+		s.Absent++
+	case AttendanceStatusExcused:
+		s.Excused++
+	default:
+		s.Unmarked++
+	}
+
+	counted := s.Sessions - s.Excused
+	if counted == 0 {
+		s.AttendancePercentage = null.Float{}
+		return
+	}
+
+	s.AttendancePercentage = null.FloatFrom(CompletionPercentage(s.Present+s.Late, counted))
+}
+
+// AttendanceEntry is a single occurrence in a student's attendance report.
+type AttendanceEntry struct {
+	SessionID   uuid.UUID        `json:"sessionID"`
+	Sequence    int              `json:"sequence"`
+	Title       string           `json:"title"`
+	StartsAt    time.Time        `json:"startsAt"`
+	Status      AttendanceStatus `json:"status"`
+	CheckedInAt null.Time        `json:"checkedInAt"`
+	Note        string           `json:"note"`
+}
+
+// StudentAttendanceReport details the attendance of a student in a Course,
+// occurrence by occurrence.
+type StudentAttendanceReport struct {
+	CourseID uuid.UUID         `json:"courseID"`
+	Summary  AttendanceSummary `json:"summary"`
+	Entries  []AttendanceEntry `json:"entries"`
+}
+
+// NewStudentAttendanceReport builds the attendance report of an enrolled
+// student. It covers the occurrences held for the whole Course or for the
+// student's Cohort that started before now, leaving out those held before the
+// student enrolled unless attendance was marked for them.
+func NewStudentAttendanceReport(enrollment Enrollment, sessions []LiveSession, records []AttendanceRecord, now time.Time) StudentAttendanceReport {
+	type occurrenceKey struct {
+		sessionID uuid.UUID
+		sequence  int
+	}
+
+	recordByOccurrence := make(map[occurrenceKey]AttendanceRecord, len(records))
+	for _, record := range records {
+		if record.StudentID == enrollment.StudentID {
+			recordByOccurrence[occurrenceKey{record.SessionID, record.Sequence}] = record
+		}
+	}
+
+	report := StudentAttendanceReport{
+		CourseID: enrollment.CourseID,
+		Summary:  AttendanceSummary{StudentID: enrollment.StudentID, CohortID: enrollment.CohortID.Ptr()},
+		Entries:  make([]AttendanceEntry, 0),
+	}
+	for _, session := range sessions {
+		if !session.IsVisibleTo(enrollment.CohortID) {
+			continue
+		}
+
+		for _, occurrence := range session.Occurrences(time.Time{}, now) {
+			entry := AttendanceEntry{
+				SessionID: occurrence.SessionID,
+				Sequence:  occurrence.Sequence,
+				Title:     occurrence.Title,
+				StartsAt:  occurrence.StartsAt,
+				Status:    AttendanceStatusUnmarked,
+			}
+
+			record, ok := recordByOccurrence[occurrenceKey{occurrence.SessionID, occurrence.Sequence}]
+			if ok {
+				entry.Status = record.Status
+				entry.CheckedInAt = record.CheckedInAt
+				entry.Note = record.Note
+			} else if occurrence.StartsAt.Before(enrollment.CreatedAt) {
+				continue
+			}
+
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].StartsAt.Before(report.Entries[j].StartsAt)
+	})
+	for _, entry := range report.Entries {
+		report.Summary.add(entry.Status)
+	}
+
+	return report
+}
+
+// WriteCSV writes a StudentAttendanceReport as CSV, one occurrence per row.
+func (r StudentAttendanceReport) WriteCSV(w io.Writer) (err error) {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"session_id", "sequence", "title", "starts_at", "status", "checked_in_at", "note"}}
+	for _, entry := range r.Entries {
+		rows = append(rows, []string{
+			entry.SessionID.String(),
+			strconv.Itoa(entry.Sequence),
+			entry.Title,
+			entry.StartsAt.UTC().Format(time.RFC3339),
+			string(entry.Status),
+			formatCSVTime(entry.CheckedInAt),
+			entry.Note,
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
+
+// AttendanceReport summarises the attendance of the students of a Course, or
+// of one of its Cohorts.
+type AttendanceReport struct {
+	CourseID    uuid.UUID           `json:"courseID"`
+	CohortID    *uuid.UUID          `json:"cohortID"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Students    []AttendanceSummary `json:"students"`
+}
+
+// NewAttendanceReport builds the AttendanceReport of the given Enrollments
+// out of the LiveSessions of their Course and the records marked for them.
+func NewAttendanceReport(courseID uuid.UUID, cohortID nuuid.NUUID, enrollments []Enrollment, sessions []LiveSession, records []AttendanceRecord, now time.Time) AttendanceReport {
+	recordsByStudent := make(map[uuid.UUID][]AttendanceRecord)
+	for _, record := range records {
+		recordsByStudent[record.StudentID] = append(recordsByStudent[record.StudentID], record)
+	}
+
+	report := AttendanceReport{
+		CourseID:    courseID,
+		CohortID:    cohortID.Ptr(),
+		GeneratedAt: now,
+		Students:    make([]AttendanceSummary, 0, len(enrollments)),
+	}
+	for _, enrollment := range enrollments {
+		studentReport := NewStudentAttendanceReport(enrollment, sessions, recordsByStudent[enrollment.StudentID], now)
+		report.Students = append(report.Students, studentReport.Summary)
+	}
+
+	return report
+}
+
+// WriteCSV writes an AttendanceReport as CSV, one student per row.
+func (r AttendanceReport) WriteCSV(w io.Writer) (err error) {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"student_id", "cohort_id", "sessions", "present", "late", "absent", "excused", "unmarked", "attendance_percentage"}}
+	for _, summary := range r.Students {
+		cohortID := ""
+		if summary.CohortID != nil {
+			cohortID = summary.CohortID.String()
+		}
+
+		percentage := ""
+		if summary.AttendancePercentage.Valid {
+			percentage = strconv.FormatFloat(summary.AttendancePercentage.Float64, 'f', 2, 64)
+		}
+
+		rows = append(rows, []string{
+			summary.StudentID.String(),
+			cohortID,
+			strconv.Itoa(summary.Sessions),
+			strconv.Itoa(summary.Present),
+			strconv.Itoa(summary.Late),
+			strconv.Itoa(summary.Absent),
+			strconv.Itoa(summary.Excused),
+			strconv.Itoa(summary.Unmarked),
+			percentage,
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
+
+// formatCSVTime formats an optional time for a CSV cell.
+func formatCSVTime(t null.Time) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+//// Completion Rules
+
+// CompletionRules are the requirements a student must meet, beyond completing
+// every Lesson, for a Course to count as completed.
+type CompletionRules struct {
+	CourseID             uuid.UUID   `db:"course_id" validate:"required"`
+	MinAttendancePercent null.Int    `db:"min_attendance_percent"`
+	UpdatedAt            null.Time   `db:"updated_at"`
+	UpdatedBy            nuuid.NUUID `db:"updated_by"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c CompletionRules) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// NewCompletionRulesFromRequestFormat creates the CompletionRules of a Course.
+func (c CompletionRules) NewCompletionRulesFromRequestFormat(courseID uuid.UUID, req CompletionRulesRequestFormat, userID uuid.UUID) (newRules CompletionRules, err error) {
+	newRules = CompletionRules{
+		CourseID:             courseID,
+		MinAttendancePercent: null.IntFromPtr(req.MinAttendancePercent),
+		UpdatedAt:            null.TimeFrom(time.Now()),
+		UpdatedBy:            nuuid.From(userID),
+	}
+
+	err = newRules.Validate()
+	return
+}
+
+// RequiresAttendance checks whether students must attend a share of the
+// LiveSessions to complete the Course.
+func (c *CompletionRules) RequiresAttendance() bool {
+	return c.MinAttendancePercent.Valid && c.MinAttendancePercent.Int64 > 0
+}
+
+// ToResponseFormat converts these CompletionRules to their response format.
+func (c CompletionRules) ToResponseFormat() CompletionRulesResponseFormat {
+	return CompletionRulesResponseFormat{
+		CourseID:             c.CourseID,
+		MinAttendancePercent: c.MinAttendancePercent,
+		UpdatedAt:            c.UpdatedAt,
+		UpdatedBy:            c.UpdatedBy.Ptr(),
+	}
+}
+
+// Validate validates the entity.
+func (c *CompletionRules) Validate() (err error) {
+	validator := shared.GetValidator()
+	err = validator.Struct(c)
+	if err != nil {
+		return
+	}
+
+	if c.MinAttendancePercent.Valid && (c.MinAttendancePercent.Int64 < 0 || c.MinAttendancePercent.Int64 > 100) {
+		return errors.New("minAttendancePercent must be between 0 and 100")
+	}
+
+	return
+}
+
+// CompletionRulesRequestFormat represents a request to set the CompletionRules
+// of a Course. Leaving MinAttendancePercent out drops the attendance
+// requirement.
+type CompletionRulesRequestFormat struct {
+	MinAttendancePercent *int64 `json:"minAttendancePercent" validate:"omitempty,min=0,max=100"`
+}
+
+// CompletionRulesResponseFormat represents CompletionRules' standard
+// formatting for JSON serializing.
+type CompletionRulesResponseFormat struct {
+	CourseID             uuid.UUID  `json:"courseID"`
+	MinAttendancePercent null.Int   `json:"minAttendancePercent"`
+	UpdatedAt            null.Time  `json:"updatedAt"`
+	UpdatedBy            *uuid.UUID `json:"updatedBy"`
+}
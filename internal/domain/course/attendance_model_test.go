@@ -0,0 +1,110 @@
+package course_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStudentAttendanceReport(t *testing.T) {
+	courseID, studentID, teacherID := getRandomUUID(), getRandomUUID(), getRandomUUID()
+
+	weekly, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(courseID, newLiveSessionRequest(
+		&course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TU"}, Count: 4},
+	), teacherID)
+	assert.NoError(t, err)
+	otherCohort, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(courseID, newLiveSessionRequest(nil), teacherID)
+	assert.NoError(t, err)
+	otherCohort.CohortID = nuuid.From(getRandomUUID())
+
+	enrollment := newEnrollment(courseID, studentID, course.EnrollmentStatusActive)
+	enrollment.CreatedAt = time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	records := []course.AttendanceRecord{
+		{SessionID: weekly.ID, Sequence: 1, StudentID: studentID, Status: course.AttendanceStatusPresent},
+		{SessionID: weekly.ID, Sequence: 2, StudentID: studentID, Status: course.AttendanceStatusExcused, Note: "sick, with a note"},
+		{SessionID: weekly.ID, Sequence: 2, StudentID: getRandomUUID(), Status: course.AttendanceStatusAbsent},
+	}
+
+	// the 3rd, 10th and 17th of March were held by the 20th; the one on the
+	// 3rd predates the enrollment but was marked anyway
+	report := course.NewStudentAttendanceReport(enrollment, []course.LiveSession{weekly, otherCohort}, records, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+
+	assert.Len(t, report.Entries, 3)
+	assert.Equal(t, course.AttendanceStatusUnmarked, report.Entries[2].Status)
+	assert.Equal(t, 3, report.Summary.Sessions)
+	assert.Equal(t, 1, report.Summary.Present)
+	assert.Equal(t, 1, report.Summary.Excused)
+	assert.Equal(t, 1, report.Summary.Unmarked)
+	assert.Equal(t, null.FloatFrom(50), report.Summary.AttendancePercentage)
+
+	t.Run("unmarked occurrences held before enrolling are left out", func(t *testing.T) {
+		report := course.NewStudentAttendanceReport(enrollment, []course.LiveSession{weekly}, nil, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, 2, report.Summary.Sessions)
+		assert.Equal(t, null.FloatFrom(0), report.Summary.AttendancePercentage)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, report.WriteCSV(&buf))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 4)
+		assert.Equal(t, "status", rows[0][4])
+		assert.Equal(t, "2026-03-10T22:00:00Z", rows[2][3])
+		assert.Equal(t, "sick, with a note", rows[2][6])
+	})
+}
+
+func TestAttendanceReportCSV(t *testing.T) {
+	studentID := getRandomUUID()
+	report := course.AttendanceReport{
+		CourseID: getRandomUUID(),
+		Students: []course.AttendanceSummary{
+			{StudentID: studentID, Sessions: 3, Present: 1, Late: 1, Absent: 1, AttendancePercentage: null.FloatFrom(66.67)},
+			{StudentID: getRandomUUID()},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteCSV(&buf))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []string{studentID.String(), "", "3", "1", "1", "1", "0", "0", "66.67"}, rows[1])
+	assert.Equal(t, "", rows[2][8])
+}
+
+func TestCheckInRecord(t *testing.T) {
+	startsAt := time.Date(2026, 3, 3, 23, 0, 0, 0, time.UTC)
+	occurrence := course.LiveSessionOccurrence{SessionID: getRandomUUID(), Sequence: 1, StartsAt: startsAt}
+
+	onTime := course.AttendanceRecord{}.NewCheckInRecord(getRandomUUID(), occurrence, getRandomUUID(), startsAt.Add(10*time.Minute))
+	late := course.AttendanceRecord{}.NewCheckInRecord(getRandomUUID(), occurrence, getRandomUUID(), startsAt.Add(11*time.Minute))
+
+	assert.Equal(t, course.AttendanceStatusPresent, onTime.Status)
+	assert.Equal(t, course.AttendanceStatusLate, late.Status)
+	assert.NoError(t, late.Validate())
+}
+
+func TestCourseProgressAttendanceRequirement(t *testing.T) {
+	progress := course.CourseProgress{TotalLessons: 2, CompletedLessons: 2}
+	rules := course.CompletionRules{MinAttendancePercent: null.IntFrom(75)}
+
+	progress.ApplyAttendance(course.AttendanceSummary{AttendancePercentage: null.FloatFrom(50)}, rules)
+	assert.False(t, progress.IsComplete())
+
+	progress.ApplyAttendance(course.AttendanceSummary{AttendancePercentage: null.FloatFrom(75)}, rules)
+	assert.True(t, progress.IsComplete())
+
+	progress.ApplyAttendance(course.AttendanceSummary{}, rules)
+	assert.True(t, progress.IsComplete(), "nothing to attend yet")
+}
@@ -0,0 +1,341 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source attendance_repository.go -destination mock/attendance_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlErrDuplicateEntry is the MySQL error number of a duplicate key.
+const mysqlErrDuplicateEntry = 1062
+
+var (
+	attendanceQueries = struct {
+		selectAttendanceRecord string
+		insertCheckIn          string
+		upsertAttendanceRecord string
+		selectCheckInCode      string
+		upsertCheckInCode      string
+		selectCompletionRules  string
+		upsertCompletionRules  string
+	}{
+		selectAttendanceRecord: `
+			SELECT
+				session_id,
+				sequence,
+				student_id,
+				course_id,
+				status,
+				checked_in_at,
+				note,
+				marked_at,
+				marked_by
+			FROM attendance_records
+		`,
+
+		insertCheckIn: `
+			INSERT INTO attendance_records (
+				session_id,
+				sequence,
+				student_id,
+				course_id,
+				status,
+				checked_in_at,
+				note,
+				marked_at,
+				marked_by
+			) VALUES (
+				:session_id,
+				:sequence,
+				:student_id,
+				:course_id,
+				:status,
+				:checked_in_at,
+				:note,
+				:marked_at,
+				:marked_by
+			)
+		`,
+
+		upsertAttendanceRecord: `
+			INSERT INTO attendance_records (
+				session_id,
+				sequence,
+				student_id,
+				course_id,
+				status,
+				checked_in_at,
+				note,
+				marked_at,
+				marked_by
+			) VALUES (
+				:session_id,
+				:sequence,
+				:student_id,
+				:course_id,
+				:status,
+				:checked_in_at,
+				:note,
+				:marked_at,
+				:marked_by
+			)
+			ON DUPLICATE KEY UPDATE
+				status = VALUES(status),
+				note = VALUES(note),
+				marked_at = VALUES(marked_at),
+				marked_by = VALUES(marked_by)
+		`,
+
+		selectCheckInCode: `
+			SELECT
+				session_id,
+				sequence,
+				code,
+				expires_at,
+				created_at,
+				created_by
+			FROM attendance_check_in_codes
+		`,
+
+		upsertCheckInCode: `
+			INSERT INTO attendance_check_in_codes (
+				session_id,
+				sequence,
+				code,
+				expires_at,
+				created_at,
+				created_by
+			) VALUES (
+				:session_id,
+				:sequence,
+				:code,
+				:expires_at,
+				:created_at,
+				:created_by
+			)
+			ON DUPLICATE KEY UPDATE
+				code = VALUES(code),
+				expires_at = VALUES(expires_at),
+				created_at = VALUES(created_at),
+				created_by = VALUES(created_by)
+		`,
+
+		selectCompletionRules: `
+			SELECT
+				course_id,
+				min_attendance_percent,
+				updated_at,
+				updated_by
+			FROM course_completion_rules
+		`,
+
+		upsertCompletionRules: `
+			INSERT INTO course_completion_rules (
+				course_id,
+				min_attendance_percent,
+				updated_at,
+				updated_by
+			) VALUES (
+				:course_id,
+				:min_attendance_percent,
+				:updated_at,
+				:updated_by
+			)
+			ON DUPLICATE KEY UPDATE
+				min_attendance_percent = VALUES(min_attendance_percent),
+				updated_at = VALUES(updated_at),
+				updated_by = VALUES(updated_by)
+		`,
+	}
+)
+
+// AttendanceRepository is the repository for attendance data.
+type AttendanceRepository interface {
+	CreateCheckIn(record AttendanceRecord) (err error)
+	ResolveAttendanceByCourseID(courseID uuid.UUID) (records []AttendanceRecord, err error)
+	ResolveAttendanceByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (records []AttendanceRecord, err error)
+	ResolveAttendanceByOccurrence(sessionID uuid.UUID, sequence int) (records []AttendanceRecord, err error)
+	ResolveCheckInCode(sessionID uuid.UUID, code string) (checkInCode CheckInCode, err error)
+	ResolveCompletionRules(courseID uuid.UUID) (rules CompletionRules, err error)
+	SaveAttendance(records ...AttendanceRecord) (err error)
+	SaveCheckInCode(checkInCode CheckInCode) (err error)
+	SaveCompletionRules(rules CompletionRules) (err error)
+}
+
+// AttendanceRepositoryMySQL is the MySQL-backed implementation of AttendanceRepository.
+type AttendanceRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideAttendanceRepositoryMySQL is the provider for this repository.
+func ProvideAttendanceRepositoryMySQL(db *infras.MySQLConn) *AttendanceRepositoryMySQL {
+	s := new(AttendanceRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCheckIn records a student checking in. It fails with a conflict when
+// the student's attendance was recorded already.
+func (r *AttendanceRepositoryMySQL) CreateCheckIn(record AttendanceRecord) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		_, err := tx.NamedExec(attendanceQueries.insertCheckIn, record)
+		if err != nil {
+			if isDuplicateEntry(err) {
+				e <- errAlreadyCheckedIn
+				return
+			}
+
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveAttendanceByCourseID resolves the AttendanceRecords of a Course.
+func (r *AttendanceRepositoryMySQL) ResolveAttendanceByCourseID(courseID uuid.UUID) (records []AttendanceRecord, err error) {
+	err = r.DB.Read.Select(&records, attendanceQueries.selectAttendanceRecord+" WHERE course_id = ?", courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAttendanceByCourseIDAndStudentID resolves the AttendanceRecords of a
+// student in a Course.
+func (r *AttendanceRepositoryMySQL) ResolveAttendanceByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (records []AttendanceRecord, err error) {
+	err = r.DB.Read.Select(
+		&records,
+		attendanceQueries.selectAttendanceRecord+" WHERE course_id = ? AND student_id = ?",
+		courseID.String(),
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAttendanceByOccurrence resolves the AttendanceRecords of an
+// occurrence of a LiveSession.
+func (r *AttendanceRepositoryMySQL) ResolveAttendanceByOccurrence(sessionID uuid.UUID, sequence int) (records []AttendanceRecord, err error) {
+	err = r.DB.Read.Select(
+		&records,
+		attendanceQueries.selectAttendanceRecord+" WHERE session_id = ? AND sequence = ?",
+		sessionID.String(),
+		sequence)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCheckInCode resolves the CheckInCode of a LiveSession's occurrence by
+// its code.
+func (r *AttendanceRepositoryMySQL) ResolveCheckInCode(sessionID uuid.UUID, code string) (checkInCode CheckInCode, err error) {
+	err = r.DB.Read.Get(
+		&checkInCode,
+		attendanceQueries.selectCheckInCode+" WHERE session_id = ? AND code = ? ORDER BY expires_at DESC LIMIT 1",
+		sessionID.String(),
+		code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("checkInCode")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCompletionRules resolves the CompletionRules of a Course.
+func (r *AttendanceRepositoryMySQL) ResolveCompletionRules(courseID uuid.UUID) (rules CompletionRules, err error) {
+	err = r.DB.Read.Get(&rules, attendanceQueries.selectCompletionRules+" WHERE course_id = ?", courseID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("completionRules")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SaveAttendance creates or replaces AttendanceRecords. The time a student
+// checked in is kept.
+func (r *AttendanceRepositoryMySQL) SaveAttendance(records ...AttendanceRecord) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		for _, record := range records {
+			if err := r.txExecNamed(tx, attendanceQueries.upsertAttendanceRecord, record); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// SaveCheckInCode creates or replaces the CheckInCode of an occurrence.
+func (r *AttendanceRepositoryMySQL) SaveCheckInCode(checkInCode CheckInCode) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, attendanceQueries.upsertCheckInCode, checkInCode); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// SaveCompletionRules creates or replaces the CompletionRules of a Course.
+func (r *AttendanceRepositoryMySQL) SaveCompletionRules(rules CompletionRules) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, attendanceQueries.upsertCompletionRules, rules); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *AttendanceRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// isDuplicateEntry tells whether an error is MySQL rejecting a duplicate key.
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
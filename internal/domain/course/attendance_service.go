@@ -0,0 +1,420 @@
+package course
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+)
+
+// AttendanceService is the service interface for LiveSession attendance and
+// the completion rules it counts toward.
+type AttendanceService interface {
+	CheckIn(sessionID uuid.UUID, requestFormat CheckInRequestFormat, studentID uuid.UUID) (record AttendanceRecord, err error)
+	MarkAttendance(sessionID uuid.UUID, sequence int, requestFormat AttendanceRequestFormat, userID uuid.UUID) (sheet AttendanceSheet, err error)
+	MarkStudentAttendance(sessionID uuid.UUID, sequence int, studentID uuid.UUID, requestFormat AttendanceMarkRequestFormat, userID uuid.UUID) (record AttendanceRecord, err error)
+	OpenCheckIn(sessionID uuid.UUID, sequence int, requestFormat CheckInCodeRequestFormat, userID uuid.UUID) (checkInCode CheckInCode, err error)
+	ResolveAttendanceSheet(sessionID uuid.UUID, sequence int, userID uuid.UUID) (sheet AttendanceSheet, err error)
+	ResolveCohortAttendance(cohortID uuid.UUID, userID uuid.UUID) (report AttendanceReport, err error)
+	ResolveCompletionRules(courseID uuid.UUID, userID uuid.UUID, role string) (rules CompletionRules, err error)
+	ResolveCourseAttendance(courseID uuid.UUID, userID uuid.UUID) (report AttendanceReport, err error)
+	ResolveStudentAttendance(courseID uuid.UUID, studentID uuid.UUID, userID uuid.UUID, role string) (report StudentAttendanceReport, err error)
+	UpdateCompletionRules(courseID uuid.UUID, requestFormat CompletionRulesRequestFormat, userID uuid.UUID) (rules CompletionRules, err error)
+}
+
+// AttendanceServiceImpl is the service implementation for LiveSession
+// attendance and the completion rules it counts toward.
+type AttendanceServiceImpl struct {
+	AttendanceRepository   AttendanceRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	Config                 *configs.Config
+}
+
+// ProvideAttendanceServiceImpl is the provider for this service.
+func ProvideAttendanceServiceImpl(
+	attendanceRepository AttendanceRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	config *configs.Config) *AttendanceServiceImpl {
+	s := new(AttendanceServiceImpl)
+	s.AttendanceRepository = attendanceRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.Config = config
+
+	return s
+}
+
+// CheckIn checks a student attending a LiveSession in to the occurrence a
+// check-in code was opened for. Students checking in well after it started
+// are marked late.
+func (s *AttendanceServiceImpl) CheckIn(sessionID uuid.UUID, requestFormat CheckInRequestFormat, studentID uuid.UUID) (record AttendanceRecord, err error) {
+	session, err := resolveLiveSession(s.LiveSessionRepository, sessionID)
+	if err != nil {
+		return
+	}
+
+	enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(session.CourseID, studentID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	if err != nil || !enrollment.IsActive() || !session.IsVisibleTo(enrollment.CohortID) {
+		return record, failure.NotFound("liveSession")
+	}
+
+	checkInCode, err := s.AttendanceRepository.ResolveCheckInCode(session.ID, NormalizeCheckInCode(requestFormat.Code))
+	if err != nil {
+		if failure.GetCode(err) == http.StatusNotFound {
+			err = errInvalidCheckInCode
+		}
+
+		return
+	}
+
+	now := time.Now()
+	if checkInCode.IsExpired(now) {
+		return record, errInvalidCheckInCode
+	}
+
+	occurrence, ok := session.Occurrence(checkInCode.Sequence)
+	if !ok {
+		return record, errInvalidCheckInCode
+	}
+
+	record = AttendanceRecord{}.NewCheckInRecord(session.CourseID, occurrence, studentID, now)
+	err = s.AttendanceRepository.CreateCheckIn(record)
+	return
+}
+
+// MarkAttendance marks the attendance of the students expected at an
+// occurrence of a LiveSession in bulk, for those allowed to take attendance in
+// its Course or the instructors of the Cohort it is held for.
+func (s *AttendanceServiceImpl) MarkAttendance(sessionID uuid.UUID, sequence int, requestFormat AttendanceRequestFormat, userID uuid.UUID) (sheet AttendanceSheet, err error) {
+	session, occurrence, err := s.resolveInstructedOccurrence(sessionID, sequence, userID)
+	if err != nil {
+		return
+	}
+
+	attendees, err := s.resolveAttendees(session)
+	if err != nil {
+		return
+	}
+
+	existing, err := s.AttendanceRepository.ResolveAttendanceByOccurrence(session.ID, occurrence.Sequence)
+	if err != nil {
+		return
+	}
+
+	marked := make(map[uuid.UUID]bool, len(existing)+len(requestFormat.Records))
+	for _, record := range existing {
+		marked[record.StudentID] = true
+	}
+
+	expected := make(map[uuid.UUID]bool, len(attendees))
+	for _, attendee := range attendees {
+		expected[attendee.StudentID] = true
+	}
+
+	records := make([]AttendanceRecord, 0, len(requestFormat.Records))
+	requested := make(map[uuid.UUID]bool, len(requestFormat.Records))
+	for _, recordRequest := range requestFormat.Records {
+		if requested[recordRequest.StudentID] {
+			return sheet, failure.BadRequestFromString("student " + recordRequest.StudentID.String() + " is listed more than once")
+		}
+
+		if !expected[recordRequest.StudentID] {
+			return sheet, failure.BadRequestFromString("student " + recordRequest.StudentID.String() + " does not attend this session")
+		}
+
+		record, err := AttendanceRecord{}.NewAttendanceRecord(session.CourseID, occurrence, recordRequest, userID)
+		if err != nil {
+			return sheet, failure.BadRequest(err)
+		}
+
+		requested[record.StudentID] = true
+		records = append(records, record)
+	}
+
+	if requestFormat.RemainingStatus != "" {
+		for _, attendee := range attendees {
+			if requested[attendee.StudentID] || marked[attendee.StudentID] {
+				continue
+			}
+
+			record, err := AttendanceRecord{}.NewAttendanceRecord(session.CourseID, occurrence, AttendanceRecordRequestFormat{
+				StudentID:                   attendee.StudentID,
+				AttendanceMarkRequestFormat: AttendanceMarkRequestFormat{Status: requestFormat.RemainingStatus},
+			}, userID)
+			if err != nil {
+				return sheet, failure.BadRequest(err)
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	if len(records) > 0 {
+		err = s.AttendanceRepository.SaveAttendance(records...)
+		if err != nil {
+			return
+		}
+	}
+
+	current, err := s.AttendanceRepository.ResolveAttendanceByOccurrence(session.ID, occurrence.Sequence)
+	if err != nil {
+		return
+	}
+
+	return NewAttendanceSheet(occurrence, attendees, current), nil
+}
+
+// MarkStudentAttendance marks the attendance of a single student expected at
+// an occurrence of a LiveSession.
+func (s *AttendanceServiceImpl) MarkStudentAttendance(sessionID uuid.UUID, sequence int, studentID uuid.UUID, requestFormat AttendanceMarkRequestFormat, userID uuid.UUID) (record AttendanceRecord, err error) {
+	sheet, err := s.MarkAttendance(sessionID, sequence, AttendanceRequestFormat{
+		Records: []AttendanceRecordRequestFormat{{StudentID: studentID, AttendanceMarkRequestFormat: requestFormat}},
+	}, userID)
+	if err != nil {
+		return
+	}
+
+	records, err := s.AttendanceRepository.ResolveAttendanceByOccurrence(sheet.Occurrence.SessionID, sheet.Occurrence.Sequence)
+	if err != nil {
+		return
+	}
+
+	for _, record := range records {
+		if record.StudentID == studentID {
+			return record, nil
+		}
+	}
+
+	return record, failure.NotFound("attendanceRecord")
+}
+
+// OpenCheckIn opens a short-lived check-in code for an occurrence of a
+// LiveSession, replacing the one opened before.
+func (s *AttendanceServiceImpl) OpenCheckIn(sessionID uuid.UUID, sequence int, requestFormat CheckInCodeRequestFormat, userID uuid.UUID) (checkInCode CheckInCode, err error) {
+	_, occurrence, err := s.resolveInstructedOccurrence(sessionID, sequence, userID)
+	if err != nil {
+		return
+	}
+
+	checkInCode, err = CheckInCode{}.NewCheckInCode(occurrence, requestFormat, userID)
+	if err != nil {
+		return checkInCode, failure.BadRequest(err)
+	}
+
+	err = s.AttendanceRepository.SaveCheckInCode(checkInCode)
+	return
+}
+
+// ResolveAttendanceSheet resolves the attendance of every student expected at
+// an occurrence of a LiveSession.
+func (s *AttendanceServiceImpl) ResolveAttendanceSheet(sessionID uuid.UUID, sequence int, userID uuid.UUID) (sheet AttendanceSheet, err error) {
+	session, occurrence, err := s.resolveInstructedOccurrence(sessionID, sequence, userID)
+	if err != nil {
+		return
+	}
+
+	attendees, err := s.resolveAttendees(session)
+	if err != nil {
+		return
+	}
+
+	records, err := s.AttendanceRepository.ResolveAttendanceByOccurrence(session.ID, occurrence.Sequence)
+	if err != nil {
+		return
+	}
+
+	return NewAttendanceSheet(occurrence, attendees, records), nil
+}
+
+// ResolveCohortAttendance summarises the attendance of the students of a
+// Cohort for those allowed to view the students of its Course or its
+// instructors.
+func (s *AttendanceServiceImpl) ResolveCohortAttendance(cohortID uuid.UUID, userID uuid.UUID) (report AttendanceReport, err error) {
+	cohort, err := resolveCohort(s.CohortRepository, cohortID)
+	if err != nil {
+		return
+	}
+
+	if !cohort.HasInstructor(userID) {
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, cohort.CourseID, userID, PermissionViewStudents)
+		if err != nil {
+			return
+		}
+	}
+
+	return s.resolveAttendanceReport(cohort.CourseID, nuuid.From(cohort.ID))
+}
+
+// ResolveCompletionRules resolves the CompletionRules of a Course. Courses
+// without rules only require their Lessons to be completed.
+func (s *AttendanceServiceImpl) ResolveCompletionRules(courseID uuid.UUID, userID uuid.UUID, role string) (rules CompletionRules, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, courseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	rules, err = s.AttendanceRepository.ResolveCompletionRules(courseID)
+	if err != nil && failure.GetCode(err) == http.StatusNotFound {
+		return CompletionRules{CourseID: courseID}, nil
+	}
+
+	return
+}
+
+// ResolveCourseAttendance summarises the attendance of the actively enrolled
+// students of a Course, for those allowed to view them.
+func (s *AttendanceServiceImpl) ResolveCourseAttendance(courseID uuid.UUID, userID uuid.UUID) (report AttendanceReport, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	return s.resolveAttendanceReport(course.ID, nuuid.NUUID{})
+}
+
+// ResolveStudentAttendance details the attendance of a student in a Course,
+// for the student themselves or those allowed to view the Course's students.
+func (s *AttendanceServiceImpl) ResolveStudentAttendance(courseID uuid.UUID, studentID uuid.UUID, userID uuid.UUID, role string) (report StudentAttendanceReport, err error) {
+	if role == shared.RoleTeacher {
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionViewStudents)
+	} else if studentID != userID {
+		err = failure.Forbidden("students can only see their own attendance")
+	} else {
+		err = checkReadAccess(s.EnrollmentRepository, courseID, userID, role)
+	}
+	if err != nil {
+		return
+	}
+
+	enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	return resolveStudentAttendance(s.AttendanceRepository, s.LiveSessionRepository, enrollment, time.Now())
+}
+
+// UpdateCompletionRules sets the CompletionRules of a Course the given user
+// may edit.
+func (s *AttendanceServiceImpl) UpdateCompletionRules(courseID uuid.UUID, requestFormat CompletionRulesRequestFormat, userID uuid.UUID) (rules CompletionRules, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	rules, err = CompletionRules{}.NewCompletionRulesFromRequestFormat(course.ID, requestFormat, userID)
+	if err != nil {
+		return rules, failure.BadRequest(err)
+	}
+
+	err = s.AttendanceRepository.SaveCompletionRules(rules)
+	return
+}
+
+// resolveAttendanceReport summarises the attendance of the actively enrolled
+// students of a Course, or of one of its Cohorts when cohortID is valid.
+func (s *AttendanceServiceImpl) resolveAttendanceReport(courseID uuid.UUID, cohortID nuuid.NUUID) (report AttendanceReport, err error) {
+	enrollments, err := s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: courseID,
+		CohortID: cohortID,
+		Status:   EnrollmentStatusActive,
+	})
+	if err != nil {
+		return
+	}
+
+	sessions, err := s.LiveSessionRepository.ResolveLiveSessionsByCourseID(courseID)
+	if err != nil {
+		return
+	}
+
+	records, err := s.AttendanceRepository.ResolveAttendanceByCourseID(courseID)
+	if err != nil {
+		return
+	}
+
+	return NewAttendanceReport(courseID, cohortID, enrollments, sessions, records, time.Now()), nil
+}
+
+// resolveAttendees resolves the Enrollments of the students expected at a
+// LiveSession: the active students of its Cohort, or of its whole Course.
+func (s *AttendanceServiceImpl) resolveAttendees(session LiveSession) (attendees []Enrollment, err error) {
+	return s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: session.CourseID,
+		CohortID: session.CohortID,
+		Status:   EnrollmentStatusActive,
+	})
+}
+
+// resolveInstructedOccurrence resolves an occurrence of a LiveSession for those
+// allowed to take attendance in its Course or, for a LiveSession held for a
+// Cohort, one of the Cohort's instructors.
+func (s *AttendanceServiceImpl) resolveInstructedOccurrence(sessionID uuid.UUID, sequence int, userID uuid.UUID) (session LiveSession, occurrence LiveSessionOccurrence, err error) {
+	session, err = resolveLiveSession(s.LiveSessionRepository, sessionID)
+	if err != nil {
+		return
+	}
+
+	instructed := false
+	if session.CohortID.Valid {
+		cohort, err := resolveCohort(s.CohortRepository, session.CohortID.UUID)
+		if err != nil {
+			return session, occurrence, err
+		}
+
+		instructed = cohort.HasInstructor(userID)
+	}
+
+	if !instructed {
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, session.CourseID, userID, PermissionTakeAttendance)
+		if err != nil {
+			return
+		}
+	}
+
+	occurrence, ok := session.Occurrence(sequence)
+	if !ok {
+		return session, occurrence, failure.NotFound("occurrence")
+	}
+
+	return
+}
+
+// resolveStudentAttendance details the attendance of an enrolled student in
+// their Course up to the given time.
+func resolveStudentAttendance(
+	attendanceRepository AttendanceRepository,
+	liveSessionRepository LiveSessionRepository,
+	enrollment Enrollment,
+	now time.Time) (report StudentAttendanceReport, err error) {
+	sessions, err := liveSessionRepository.ResolveLiveSessionsByCourseID(enrollment.CourseID)
+	if err != nil {
+		return
+	}
+
+	records, err := attendanceRepository.ResolveAttendanceByCourseIDAndStudentID(enrollment.CourseID, enrollment.StudentID)
+	if err != nil {
+		return
+	}
+
+	return NewStudentAttendanceReport(enrollment, sessions, records, now), nil
+}
@@ -0,0 +1,188 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttendanceService(t *testing.T) {
+	config := &configs.Config{}
+	config.Certificate.SigningKey = testSigningKey
+	fullCourse := newDraftCourse()
+	studentID := getRandomUUID()
+
+	// a session that started five minutes ago
+	req := newLiveSessionRequest(nil)
+	req.StartsAt = time.Now().Add(-5 * time.Minute).UTC().Truncate(time.Second)
+	session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(fullCourse.ID, req, fullCourse.UserID)
+	assert.NoError(t, err)
+
+	t.Run("bulk marking fills in the remaining students", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		present := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive)
+		checkedIn := newEnrollment(fullCourse.ID, getRandomUUID(), course.EnrollmentStatusActive)
+		missing := newEnrollment(fullCourse.ID, getRandomUUID(), course.EnrollmentStatusActive)
+		occurrence, _ := session.Occurrence(1)
+		checkIn := course.AttendanceRecord{}.NewCheckInRecord(fullCourse.ID, occurrence, checkedIn.StudentID, time.Now())
+
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+		s := course.ProvideAttendanceServiceImpl(mockAttendanceRepo, nil, nil, mockCourseRepo, mockEnrollmentRepo, mockLiveSessionRepo, config)
+		mockLiveSessionRepo.EXPECT().ResolveLiveSessionByID(session.ID).Return(session, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollments(course.EnrollmentQueryParameters{
+			CourseID: fullCourse.ID,
+			Status:   course.EnrollmentStatusActive,
+		}).Return([]course.Enrollment{present, checkedIn, missing}, nil)
+		mockAttendanceRepo.EXPECT().ResolveAttendanceByOccurrence(session.ID, 1).Return([]course.AttendanceRecord{checkIn}, nil)
+		mockAttendanceRepo.EXPECT().SaveAttendance(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(records ...course.AttendanceRecord) error {
+				assert.Equal(t, present.StudentID, records[0].StudentID)
+				assert.Equal(t, course.AttendanceStatusPresent, records[0].Status)
+				assert.Equal(t, missing.StudentID, records[1].StudentID)
+				assert.Equal(t, course.AttendanceStatusAbsent, records[1].Status)
+				return nil
+			})
+		mockAttendanceRepo.EXPECT().ResolveAttendanceByOccurrence(session.ID, 1).Return([]course.AttendanceRecord{checkIn}, nil)
+
+		sheet, err := s.MarkAttendance(session.ID, 1, course.AttendanceRequestFormat{
+			Records: []course.AttendanceRecordRequestFormat{
+				{StudentID: present.StudentID, AttendanceMarkRequestFormat: course.AttendanceMarkRequestFormat{Status: course.AttendanceStatusPresent}},
+			},
+			RemainingStatus: course.AttendanceStatusAbsent,
+		}, fullCourse.UserID)
+
+		assert.NoError(t, err)
+		assert.Len(t, sheet.Records, 3)
+	})
+
+	t.Run("marking a student who does not attend", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+		s := course.ProvideAttendanceServiceImpl(mockAttendanceRepo, nil, nil, mockCourseRepo, mockEnrollmentRepo, mockLiveSessionRepo, config)
+		mockLiveSessionRepo.EXPECT().ResolveLiveSessionByID(session.ID).Return(session, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollments(gomock.Any()).Return([]course.Enrollment{}, nil)
+		mockAttendanceRepo.EXPECT().ResolveAttendanceByOccurrence(session.ID, 1).Return(nil, nil)
+
+		_, err := s.MarkStudentAttendance(session.ID, 1, studentID, course.AttendanceMarkRequestFormat{Status: course.AttendanceStatusLate}, fullCourse.UserID)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("check in", func(t *testing.T) {
+		enrollment := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive)
+		checkInCode := course.CheckInCode{SessionID: session.ID, Sequence: 1, Code: "7KQM2X", ExpiresAt: time.Now().Add(time.Minute)}
+
+		t.Run("on time", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+			mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+			mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+			s := course.ProvideAttendanceServiceImpl(mockAttendanceRepo, nil, nil, nil, mockEnrollmentRepo, mockLiveSessionRepo, config)
+			mockLiveSessionRepo.EXPECT().ResolveLiveSessionByID(session.ID).Return(session, nil)
+			mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil)
+			mockAttendanceRepo.EXPECT().ResolveCheckInCode(session.ID, "7KQM2X").Return(checkInCode, nil)
+			mockAttendanceRepo.EXPECT().CreateCheckIn(gomock.Any()).Return(nil)
+
+			record, err := s.CheckIn(session.ID, course.CheckInRequestFormat{Code: " 7kqm2x"}, studentID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, course.AttendanceStatusPresent, record.Status)
+			assert.True(t, record.CheckedInAt.Valid)
+		})
+
+		t.Run("with an expired code", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			expired := checkInCode
+			expired.ExpiresAt = time.Now().Add(-time.Second)
+			mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+			mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+			mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+			s := course.ProvideAttendanceServiceImpl(mockAttendanceRepo, nil, nil, nil, mockEnrollmentRepo, mockLiveSessionRepo, config)
+			mockLiveSessionRepo.EXPECT().ResolveLiveSessionByID(session.ID).Return(session, nil)
+			mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil)
+			mockAttendanceRepo.EXPECT().ResolveCheckInCode(session.ID, "7KQM2X").Return(expired, nil)
+
+			_, err := s.CheckIn(session.ID, course.CheckInRequestFormat{Code: "7KQM2X"}, studentID)
+
+			assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		})
+
+		t.Run("to a session of another cohort", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cohortSession := session
+			cohortSession.CohortID = nuuid.From(getRandomUUID())
+			mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+			mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+			s := course.ProvideAttendanceServiceImpl(nil, nil, nil, nil, mockEnrollmentRepo, mockLiveSessionRepo, config)
+			mockLiveSessionRepo.EXPECT().ResolveLiveSessionByID(session.ID).Return(cohortSession, nil)
+			mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil)
+
+			_, err := s.CheckIn(session.ID, course.CheckInRequestFormat{Code: "7KQM2X"}, studentID)
+
+			assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+		})
+	})
+
+	t.Run("no certificate without the required attendance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		enrollment := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive)
+		enrollment.CreatedAt = time.Now().Add(-time.Hour)
+		var completed []course.LessonProgress
+		for _, lesson := range fullCourse.Modules[0].Lessons {
+			p := course.LessonProgress{}.NewLessonProgress(lesson, studentID)
+			p.CompletedAt = null.TimeFrom(time.Now())
+			completed = append(completed, p)
+		}
+
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		mockCertificateRepo := course_mock.NewMockCertificateRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		s := course.ProvideCertificateServiceImpl(mockAttendanceRepo, mockCertificateRepo, nil, mockCourseRepo, mockEnrollmentRepo, mockLiveSessionRepo, mockProgressRepo, config)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil).Times(2)
+		mockCertificateRepo.EXPECT().ResolveCertificateByCourseAndStudent(fullCourse.ID, studentID).
+			Return(course.Certificate{}, failure.NotFound("certificate"))
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(fullCourse.ID).Return(len(completed), nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(fullCourse.ID, studentID).Return(completed, nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(fullCourse.ID).
+			Return(course.CompletionRules{CourseID: fullCourse.ID, MinAttendancePercent: null.IntFrom(80)}, nil)
+		mockLiveSessionRepo.EXPECT().ResolveLiveSessionsByCourseID(fullCourse.ID).Return([]course.LiveSession{session}, nil)
+		mockAttendanceRepo.EXPECT().ResolveAttendanceByCourseIDAndStudentID(fullCourse.ID, studentID).Return(nil, nil)
+
+		_, err := s.ClaimCertificate(fullCourse.ID, studentID, "Ada Lovelace")
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
@@ -0,0 +1,327 @@
+package course
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+const (
+	// maxCourseBulkRows caps the number of rows of a bulk upload.
+	maxCourseBulkRows = 1000
+	// courseBulkBatchSize is the number of Courses inserted at once.
+	courseBulkBatchSize = 100
+	// maxCourseBulkLineSize caps the size of a single NDJSON line.
+	maxCourseBulkLineSize = 1 << 20
+)
+
+// CourseBulkFormat is the format of a bulk upload of Courses.
+type CourseBulkFormat string
+
+const (
+	// CourseBulkFormatCSV is a CSV file whose header names the fields of
+	// CourseRequestFormat.
+	CourseBulkFormatCSV CourseBulkFormat = "csv"
+	// CourseBulkFormatNDJSON is one CourseRequestFormat JSON object per line.
+	CourseBulkFormatNDJSON CourseBulkFormat = "ndjson"
+)
+
+// CourseBulkRowStatus indicates the outcome of a row of a bulk upload.
+type CourseBulkRowStatus string
+
+const (
+	// CourseBulkRowCreated indicates a row created as a Course.
+	CourseBulkRowCreated CourseBulkRowStatus = "created"
+	// CourseBulkRowValid indicates a valid row of a dry run, which would be
+	// created.
+	CourseBulkRowValid CourseBulkRowStatus = "valid"
+	// CourseBulkRowInvalid indicates a row that failed to parse or validate.
+	CourseBulkRowInvalid CourseBulkRowStatus = "invalid"
+	// CourseBulkRowFailed indicates a valid row whose batch could not be
+	// inserted.
+	CourseBulkRowFailed CourseBulkRowStatus = "failed"
+)
+
+// courseBulkColumns lists the CSV columns, named after the JSON fields of
+// CourseRequestFormat.
+var courseBulkColumns = []string{"title", "content", "seatLimit"}
+
+//// Course Bulk Row
+
+// CourseBulkRow is a row of a bulk upload of Courses.
+type CourseBulkRow struct {
+	// Row is the number of the row, from 1, not counting a CSV header.
+	Row     int
+	Request CourseRequestFormat
+	// Errors lists why the row could not be parsed, if it couldn't.
+	Errors []string
+}
+
+// ParseCourseBulkRows parses the rows of a bulk upload. Rows that cannot be
+// parsed are kept with their errors, so that they can be reported along with
+// the others; only a file that cannot be read at all fails.
+func ParseCourseBulkRows(r io.Reader, format CourseBulkFormat) (rows []CourseBulkRow, err error) {
+	switch format {
+	case CourseBulkFormatCSV:
+		rows, err = parseCourseBulkCSV(r)
+	case CourseBulkFormatNDJSON:
+		rows, err = parseCourseBulkNDJSON(r)
+	default:
+		return nil, failure.BadRequestFromString(fmt.Sprintf("courses can be uploaded as %s or %s, not %q", CourseBulkFormatCSV, CourseBulkFormatNDJSON, format))
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(rows) == 0 {
+		return nil, failure.BadRequestFromString("the upload has no rows")
+	}
+
+	if len(rows) > maxCourseBulkRows {
+		return nil, failure.BadRequestFromString(fmt.Sprintf("the upload has %d rows, more than the %d allowed", len(rows), maxCourseBulkRows))
+	}
+
+	return
+}
+
+// NewCourse validates a row and creates its Course.
+func (r *CourseBulkRow) NewCourse(userID uuid.UUID) (course Course, ok bool) {
+	if len(r.Errors) > 0 {
+		return
+	}
+
+	err := shared.GetValidator().Struct(r.Request)
+	if err == nil {
+		course, err = Course{}.NewCourseFromRequestFormat(r.Request, userID)
+	}
+
+	if err != nil {
+		r.Errors = validationMessages(err)
+		return
+	}
+
+	return course, true
+}
+
+// parseCourseBulkCSV parses a CSV upload. Its header must name the columns,
+// in any order; title and content are required.
+func parseCourseBulkCSV(r io.Reader) (rows []CourseBulkRow, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, failure.BadRequestFromString("the upload must start with a CSV header")
+	}
+
+	columns, err := parseCourseBulkHeader(header)
+	if err != nil {
+		return
+	}
+
+	for {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+
+		row := CourseBulkRow{Row: len(rows) + 1}
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(readErr, &parseErr):
+			row.Errors = []string{parseErr.Err.Error()}
+		case readErr != nil:
+			return nil, failure.BadRequest(readErr)
+		case len(record) != len(header):
+			row.Errors = []string{fmt.Sprintf("the row has %d fields, the header %d", len(record), len(header))}
+		default:
+			row.Request, row.Errors = parseCourseBulkRecord(record, columns)
+		}
+
+		rows = append(rows, row)
+		if len(rows) > maxCourseBulkRows {
+			break
+		}
+	}
+
+	return
+}
+
+// parseCourseBulkHeader maps the known columns of a CSV header to their
+// index.
+func parseCourseBulkHeader(header []string) (columns map[string]int, err error) {
+	columns = make(map[string]int)
+	for i, name := range header {
+		known := false
+		for _, column := range courseBulkColumns {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				if _, ok := columns[column]; ok {
+					return nil, failure.BadRequestFromString(fmt.Sprintf("the header names the %s column twice", column))
+				}
+
+				columns[column] = i
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return nil, failure.BadRequestFromString(fmt.Sprintf("the header names an unknown column %q; columns are %s", name, strings.Join(courseBulkColumns, ", ")))
+		}
+	}
+
+	for _, column := range []string{"title", "content"} {
+		if _, ok := columns[column]; !ok {
+			return nil, failure.BadRequestFromString(fmt.Sprintf("the header is missing the %s column", column))
+		}
+	}
+
+	return
+}
+
+// parseCourseBulkRecord reads a CourseRequestFormat from a CSV record. An
+// empty seatLimit stands for no limit.
+func parseCourseBulkRecord(record []string, columns map[string]int) (req CourseRequestFormat, errs []string) {
+	req.Title = record[columns["title"]]
+	req.Content = record[columns["content"]]
+
+	if i, ok := columns["seatLimit"]; ok && strings.TrimSpace(record[i]) != "" {
+		seatLimit, err := strconv.ParseInt(strings.TrimSpace(record[i]), 10, 64)
+		if err != nil {
+			return req, []string{fmt.Sprintf("seatLimit %q is not a whole number", record[i])}
+		}
+
+		req.SeatLimit = null.IntFrom(seatLimit)
+	}
+
+	return
+}
+
+// parseCourseBulkNDJSON parses an NDJSON upload, skipping blank lines.
+func parseCourseBulkNDJSON(r io.Reader) (rows []CourseBulkRow, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxCourseBulkLineSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := CourseBulkRow{Row: len(rows) + 1}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.Request); err != nil {
+			row.Errors = []string{fmt.Sprintf("the line is not a valid course: %s", err)}
+		}
+
+		rows = append(rows, row)
+		if len(rows) > maxCourseBulkRows {
+			break
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// validationMessages lists the failures of a validation, one per field.
+func validationMessages(err error) (messages []string) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []string{err.Error()}
+	}
+
+	for _, fieldErr := range fieldErrs {
+		field := fieldErr.Field()
+		field = strings.ToLower(field[:1]) + field[1:]
+		messages = append(messages, fmt.Sprintf("%s failed the %s rule", field, fieldErr.Tag()))
+	}
+
+	return
+}
+
+//// Course Bulk Report
+
+// CourseBulkReport is the outcome of every row of a bulk upload of Courses.
+type CourseBulkReport struct {
+	DryRun  bool               `json:"dryRun"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Valid   int                `json:"valid"`
+	Invalid int                `json:"invalid"`
+	Failed  int                `json:"failed"`
+	Rows    []CourseBulkResult `json:"rows"`
+}
+
+// CourseBulkResult is the outcome of a row of a bulk upload of Courses.
+type CourseBulkResult struct {
+	Row      int                 `json:"row"`
+	Status   CourseBulkRowStatus `json:"status"`
+	Title    string              `json:"title,omitempty"`
+	CourseID nuuid.NUUID         `json:"courseID" swaggertype:"string"`
+	Errors   []string            `json:"errors,omitempty"`
+}
+
+// NewCourseBulkReport starts the report of a bulk upload, with every row
+// invalid until it is validated.
+func (r CourseBulkReport) NewCourseBulkReport(rows []CourseBulkRow, dryRun bool) (report CourseBulkReport) {
+	report = CourseBulkReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]CourseBulkResult, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		report.Rows = append(report.Rows, CourseBulkResult{
+			Row:    row.Row,
+			Status: CourseBulkRowInvalid,
+			Title:  row.Request.Title,
+			Errors: row.Errors,
+		})
+	}
+
+	return
+}
+
+// Record records the outcome of the row at index i of the report.
+func (r *CourseBulkReport) Record(i int, status CourseBulkRowStatus, courseID nuuid.NUUID, errs []string) {
+	r.Rows[i].Status = status
+	r.Rows[i].CourseID = courseID
+	r.Rows[i].Errors = errs
+}
+
+// Tally counts the rows of the report by their status.
+func (r *CourseBulkReport) Tally() {
+	r.Created, r.Valid, r.Invalid, r.Failed = 0, 0, 0, 0
+	for _, row := range r.Rows {
+		switch row.Status {
+		case CourseBulkRowCreated:
+			r.Created++
+		case CourseBulkRowValid:
+			r.Valid++
+		case CourseBulkRowInvalid:
+			r.Invalid++
+		case CourseBulkRowFailed:
+			r.Failed++
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package course_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCourseBulkRows(t *testing.T) {
+	t.Run("CSV columns can come in any order", func(t *testing.T) {
+		upload := "Content,title,seatLimit\n" +
+			"\"Go, MySQL and friends\",Backend Bootcamp,30\n" +
+			"Pixels,Frontend Bootcamp,\n" +
+			"Queues,Messaging,lots\n"
+
+		rows, err := course.ParseCourseBulkRows(strings.NewReader(upload), course.CourseBulkFormatCSV)
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, "Backend Bootcamp", rows[0].Request.Title)
+		assert.Equal(t, "Go, MySQL and friends", rows[0].Request.Content)
+		assert.Equal(t, int64(30), rows[0].Request.SeatLimit.Int64)
+		assert.False(t, rows[1].Request.SeatLimit.Valid)
+		assert.Equal(t, 3, rows[2].Row)
+		assert.NotEmpty(t, rows[2].Errors)
+	})
+
+	t.Run("NDJSON skips blank lines and keeps malformed ones", func(t *testing.T) {
+		upload := `{"title":"Backend Bootcamp","content":"Go"}` + "\n\n" +
+			`{"title":"Frontend Bootcamp","colour":"blue"}` + "\n"
+
+		rows, err := course.ParseCourseBulkRows(strings.NewReader(upload), course.CourseBulkFormatNDJSON)
+
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Empty(t, rows[0].Errors)
+		assert.Equal(t, 2, rows[1].Row)
+		assert.NotEmpty(t, rows[1].Errors)
+	})
+
+	t.Run("uploads that cannot be read are rejected", func(t *testing.T) {
+		tooMany := `{"title":"Go"}` + "\n"
+		tests := []struct {
+			name   string
+			upload string
+			format course.CourseBulkFormat
+		}{
+			{name: "unknown format", upload: "title,content\nGo,Go\n", format: "xlsx"},
+			{name: "missing column", upload: "title\nGo\n", format: course.CourseBulkFormatCSV},
+			{name: "unknown column", upload: "title,content,price\nGo,Go,10\n", format: course.CourseBulkFormatCSV},
+			{name: "no rows", upload: "title,content\n", format: course.CourseBulkFormatCSV},
+			{name: "too many rows", upload: strings.Repeat(tooMany, 1001), format: course.CourseBulkFormatNDJSON},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := course.ParseCourseBulkRows(strings.NewReader(test.upload), test.format)
+
+				assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+			})
+		}
+	})
+}
+
+func TestCourseService_CreateCoursesBulk(t *testing.T) {
+	userID := getRandomUUID()
+	newRows := func(valid int) (rows []course.CourseBulkRow) {
+		for i := 0; i < valid; i++ {
+			rows = append(rows, course.CourseBulkRow{
+				Row:     i + 1,
+				Request: course.CourseRequestFormat{Title: fmt.Sprintf("Course %d", i+1), Content: "Content"},
+			})
+		}
+
+		return append(rows, course.CourseBulkRow{Row: valid + 1, Request: course.CourseRequestFormat{Content: "No title"}})
+	}
+
+	t.Run("dry runs validate without creating", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.CourseServiceImpl{CourseRepository: mockRepo}
+
+		report, err := s.CreateCoursesBulk(newRows(2), true, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.Total)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Equal(t, []string{"title failed the required rule"}, report.Rows[2].Errors)
+		assert.False(t, report.Rows[0].CourseID.Valid)
+	})
+
+	t.Run("a failed batch does not fail the others", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.CourseServiceImpl{CourseRepository: mockRepo}
+		gomock.InOrder(
+			mockRepo.EXPECT().CreateCourses(gomock.Len(100)).Return(nil),
+			mockRepo.EXPECT().CreateCourses(gomock.Len(50)).Return(errors.New("deadlock")),
+		)
+
+		report, err := s.CreateCoursesBulk(newRows(150), false, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 100, report.Created)
+		assert.Equal(t, 50, report.Failed)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Equal(t, course.CourseBulkRowCreated, report.Rows[99].Status)
+		assert.True(t, report.Rows[99].CourseID.Valid)
+		assert.Equal(t, course.CourseBulkRowFailed, report.Rows[100].Status)
+		assert.Equal(t, course.CourseBulkRowInvalid, report.Rows[150].Status)
+	})
+}
@@ -0,0 +1,273 @@
+package course
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// CertificateStatus indicates whether a Certificate can be trusted.
+type CertificateStatus string
+
+const (
+	// CertificateStatusValid indicates an authentic Certificate in force.
+	CertificateStatusValid CertificateStatus = "valid"
+	// CertificateStatusRevoked indicates an authentic Certificate that was withdrawn.
+	CertificateStatusRevoked CertificateStatus = "revoked"
+	// CertificateStatusInvalid indicates a Certificate whose signature does not match its content.
+	CertificateStatusInvalid CertificateStatus = "invalid"
+)
+
+// certificateCodeAlphabet leaves out the characters that are easily confused
+// when a code is typed in: 0, 1, I, L, O and U.
+const certificateCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+//// Certificate
+
+// Certificate is issued to a student who completed a Course. Its verification
+// code lets anyone look it up, and its signature proves that the student,
+// course and completion date were not tampered with.
+type Certificate struct {
+	ID               uuid.UUID   `db:"id" validate:"required"`
+	CourseID         uuid.UUID   `db:"course_id" validate:"required"`
+	StudentID        uuid.UUID   `db:"student_id" validate:"required"`
+	StudentName      string      `db:"student_name" validate:"max=255"`
+	Code             string      `db:"code" validate:"required,len=14"`
+	CourseTitle      string      `db:"course_title" validate:"required"`
+	CompletedAt      time.Time   `db:"completed_at" validate:"required"`
+	Signature        string      `db:"signature" validate:"required,len=64"`
+	IssuedAt         time.Time   `db:"issued_at" validate:"required"`
+	RevokedAt        null.Time   `db:"revoked_at"`
+	RevokedBy        nuuid.NUUID `db:"revoked_by"`
+	RevocationReason null.String `db:"revocation_reason"`
+}
+
+// IsRevoked checks whether a Certificate was revoked.
+func (c *Certificate) IsRevoked() bool {
+	return c.RevokedAt.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c Certificate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// NewCertificate issues a signed Certificate for a student who completed a
+// Course at the given time.
+func (c Certificate) NewCertificate(course Course, studentID uuid.UUID, studentName string, completedAt time.Time, signingKey string) (newCertificate Certificate, err error) {
+	if signingKey == "" {
+		return newCertificate, errors.New("certificate signing key is not configured")
+	}
+
+	code, err := newCertificateCode()
+	if err != nil {
+		return
+	}
+
+	certificateID, _ := uuid.NewV4()
+	newCertificate = Certificate{
+		ID:          certificateID,
+		CourseID:    course.ID,
+		StudentID:   studentID,
+		StudentName: studentName,
+		Code:        code,
+		CourseTitle: course.Title,
+		CompletedAt: completedAt.UTC().Truncate(time.Second),
+		IssuedAt:    time.Now(),
+	}
+	newCertificate.Signature = newCertificate.sign(signingKey)
+
+	err = newCertificate.Validate()
+
+	return
+}
+
+// Revoke withdraws a Certificate.
+func (c *Certificate) Revoke(reason string, userID uuid.UUID) (err error) {
+	if c.IsRevoked() {
+		return failure.Conflict("revoke", "certificate", "already revoked")
+	}
+
+	c.RevokedAt = null.TimeFrom(time.Now())
+	c.RevokedBy = nuuid.From(userID)
+	c.RevocationReason = null.NewString(reason, reason != "")
+
+	return
+}
+
+// Status tells whether a Certificate is valid, revoked or forged.
+func (c Certificate) Status(signingKey string) CertificateStatus {
+	if !c.HasValidSignature(signingKey) {
+		return CertificateStatusInvalid
+	}
+
+	if c.IsRevoked() {
+		return CertificateStatusRevoked
+	}
+
+	return CertificateStatusValid
+}
+
+// HasValidSignature checks the signature of a Certificate against its content.
+func (c Certificate) HasValidSignature(signingKey string) bool {
+	if signingKey == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(c.Signature), []byte(c.sign(signingKey)))
+}
+
+// Recipient is who this Certificate is made out to: the student's name as it
+// was when the Certificate was issued, or their identifier if it is unknown.
+func (c Certificate) Recipient() string {
+	if c.StudentName != "" {
+		return c.StudentName
+	}
+
+	return c.StudentID.String()
+}
+
+// ToResponseFormat converts this Certificate to its response format.
+func (c Certificate) ToResponseFormat() CertificateResponseFormat {
+	return CertificateResponseFormat{
+		ID:               c.ID,
+		CourseID:         c.CourseID,
+		StudentID:        c.StudentID,
+		StudentName:      c.StudentName,
+		Code:             c.Code,
+		CourseTitle:      c.CourseTitle,
+		CompletedAt:      c.CompletedAt,
+		Signature:        c.Signature,
+		IssuedAt:         c.IssuedAt,
+		RevokedAt:        c.RevokedAt,
+		RevokedBy:        c.RevokedBy.Ptr(),
+		RevocationReason: c.RevocationReason,
+	}
+}
+
+// ToVerificationFormat converts this Certificate to what the public
+// verification endpoint reveals about it.
+func (c Certificate) ToVerificationFormat(signingKey string) CertificateVerificationFormat {
+	status := c.Status(signingKey)
+	resp := CertificateVerificationFormat{
+		Code:   c.Code,
+		Status: status,
+		Valid:  status == CertificateStatusValid,
+	}
+
+	if status == CertificateStatusInvalid {
+		return resp
+	}
+
+	resp.CourseID = &c.CourseID
+	resp.StudentID = &c.StudentID
+	resp.CourseTitle = c.CourseTitle
+	resp.CompletedAt = null.TimeFrom(c.CompletedAt)
+	resp.IssuedAt = null.TimeFrom(c.IssuedAt)
+	resp.RevokedAt = c.RevokedAt
+
+	return resp
+}
+
+// Validate validates the entity.
+func (c *Certificate) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(c)
+}
+
+// sign computes the HMAC-SHA256 of the code, student, course and completion
+// date of a Certificate.
+func (c Certificate) sign(signingKey string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	fmt.Fprintf(mac, "%s|%s|%s|%s", c.Code, c.StudentID, c.CourseID, c.CompletedAt.UTC().Format(time.RFC3339))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizeCertificateCode makes a typed-in verification code comparable to
+// stored ones: uppercase, in dash-separated groups of four.
+func NormalizeCertificateCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 12 {
+		return code
+	}
+
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
+
+// newCertificateCode generates a random verification code such as
+// "7KQM-2XHD-9TRW".
+func newCertificateCode() (code string, err error) {
+	code, err = randomCode(12)
+	if err != nil {
+		return
+	}
+
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12], nil
+}
+
+// randomCode generates a random code of the given length out of
+// certificateCodeAlphabet.
+func randomCode(length int) (code string, err error) {
+	alphabetSize := big.NewInt(int64(len(certificateCodeAlphabet)))
+
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteByte(certificateCodeAlphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// CertificateRevocationRequestFormat represents a request to revoke a Certificate.
+type CertificateRevocationRequestFormat struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// CertificateResponseFormat represents a Certificate's standard formatting for JSON serializing.
+type CertificateResponseFormat struct {
+	ID               uuid.UUID   `json:"id"`
+	CourseID         uuid.UUID   `json:"courseID"`
+	StudentID        uuid.UUID   `json:"studentID"`
+	StudentName      string      `json:"studentName"`
+	Code             string      `json:"code"`
+	CourseTitle      string      `json:"courseTitle"`
+	CompletedAt      time.Time   `json:"completedAt"`
+	Signature        string      `json:"signature"`
+	IssuedAt         time.Time   `json:"issuedAt"`
+	RevokedAt        null.Time   `json:"revokedAt"`
+	RevokedBy        *uuid.UUID  `json:"revokedBy"`
+	RevocationReason null.String `json:"revocationReason" swaggertype:"string"`
+}
+
+// CertificateVerificationFormat is the public outcome of verifying a
+// Certificate. Nothing but the code and status is revealed about a forged one.
+type CertificateVerificationFormat struct {
+	Code        string            `json:"code"`
+	Valid       bool              `json:"valid"`
+	Status      CertificateStatus `json:"status"`
+	CourseID    *uuid.UUID        `json:"courseID,omitempty"`
+	StudentID   *uuid.UUID        `json:"studentID,omitempty"`
+	CourseTitle string            `json:"courseTitle,omitempty"`
+	CompletedAt null.Time         `json:"completedAt,omitempty"`
+	IssuedAt    null.Time         `json:"issuedAt,omitempty"`
+	RevokedAt   null.Time         `json:"revokedAt,omitempty"`
+}
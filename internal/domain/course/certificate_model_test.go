@@ -0,0 +1,141 @@
+package course_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningKey = "test-signing-key"
+
+func TestCertificate(t *testing.T) {
+	t.Run("new certificate is signed", func(t *testing.T) {
+		certificate := newCertificate(t)
+
+		assert.Regexp(t, `^[2-9A-HJKMNP-TV-Z]{4}-[2-9A-HJKMNP-TV-Z]{4}-[2-9A-HJKMNP-TV-Z]{4}$`, certificate.Code)
+		assert.Len(t, certificate.Signature, 64)
+		assert.True(t, certificate.HasValidSignature(testSigningKey))
+		assert.False(t, certificate.HasValidSignature("another-key"))
+		assert.Equal(t, course.CertificateStatusValid, certificate.Status(testSigningKey))
+	})
+
+	t.Run("signing key is required", func(t *testing.T) {
+		_, err := course.Certificate{}.NewCertificate(newDraftCourse(), getRandomUUID(), "Ada Lovelace", time.Now(), "")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("tampering breaks the signature", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			tamper func(c *course.Certificate)
+		}{
+			{name: "student", tamper: func(c *course.Certificate) { c.StudentID = getRandomUUID() }},
+			{name: "course", tamper: func(c *course.Certificate) { c.CourseID = getRandomUUID() }},
+			{name: "completion date", tamper: func(c *course.Certificate) { c.CompletedAt = c.CompletedAt.Add(-24 * time.Hour) }},
+			{name: "code", tamper: func(c *course.Certificate) { c.Code = "2222-2222-2222" }},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				certificate := newCertificate(t)
+				test.tamper(&certificate)
+
+				verification := certificate.ToVerificationFormat(testSigningKey)
+
+				assert.False(t, verification.Valid)
+				assert.Equal(t, course.CertificateStatusInvalid, verification.Status)
+				assert.Nil(t, verification.StudentID)
+				assert.Empty(t, verification.CourseTitle)
+			})
+		}
+	})
+
+	t.Run("signature survives a round trip through the database time zone", func(t *testing.T) {
+		certificate := newCertificate(t)
+		certificate.CompletedAt = certificate.CompletedAt.In(time.FixedZone("WIB", 7*60*60))
+
+		assert.True(t, certificate.HasValidSignature(testSigningKey))
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		certificate := newCertificate(t)
+		teacherID := getRandomUUID()
+
+		err := certificate.Revoke("plagiarised assignments", teacherID)
+
+		assert.NoError(t, err)
+		assert.True(t, certificate.IsRevoked())
+		assert.Equal(t, teacherID, certificate.RevokedBy.UUID)
+		assert.Equal(t, "plagiarised assignments", certificate.RevocationReason.String)
+
+		verification := certificate.ToVerificationFormat(testSigningKey)
+		assert.False(t, verification.Valid)
+		assert.Equal(t, course.CertificateStatusRevoked, verification.Status)
+		assert.Equal(t, certificate.StudentID, *verification.StudentID)
+		assert.True(t, verification.RevokedAt.Valid)
+
+		err = certificate.Revoke("again", teacherID)
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
+
+func TestNormalizeCertificateCode(t *testing.T) {
+	assert.Equal(t, "7KQM-2XHD-9TRW", course.NormalizeCertificateCode("7kqm-2xhd-9trw"))
+	assert.Equal(t, "7KQM-2XHD-9TRW", course.NormalizeCertificateCode("7KQM 2XHD 9TRW"))
+	assert.Equal(t, "7KQM2XHD", course.NormalizeCertificateCode("7kqm2xhd"))
+}
+
+func TestCertificateRecipient(t *testing.T) {
+	certificate := newCertificate(t)
+	assert.Equal(t, "Ada Lovelace", certificate.Recipient())
+
+	certificate.StudentName = ""
+	assert.Equal(t, certificate.StudentID.String(), certificate.Recipient())
+}
+
+func TestCertificateRenderPDF(t *testing.T) {
+	certificate := newCertificate(t)
+	certificate.CourseTitle = "Go (Advanced) \\ Concurrency"
+
+	document := certificate.RenderPDF("Siti Nurhaliza", "https://example.com/v1/certificates/verify/"+certificate.Code)
+
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+	assert.Contains(t, string(document), "(Siti Nurhaliza)")
+	assert.Contains(t, string(document), `(Go \(Advanced\) \\ Concurrency)`)
+	assert.Contains(t, string(document), certificate.Code)
+	assert.NotContains(t, string(document), "REVOKED")
+
+	// every cross-reference entry must point at the start of its object
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllStringSubmatch(string(document), -1)
+	assert.Len(t, xref, 6)
+	for i, entry := range xref {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, bytes.HasPrefix(document[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(string(document))
+	offset, _ := strconv.Atoi(startxref[1])
+	assert.True(t, bytes.HasPrefix(document[offset:], []byte("xref\n")))
+
+	_ = certificate.Revoke("", getRandomUUID())
+	assert.Contains(t, string(certificate.RenderPDF("Siti Nurhaliza", "")), "REVOKED")
+}
+
+func newCertificate(t *testing.T) course.Certificate {
+	certificate, err := course.Certificate{}.NewCertificate(newDraftCourse(), getRandomUUID(), "Ada Lovelace", time.Now(), testSigningKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate
+}
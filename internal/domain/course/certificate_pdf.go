@@ -0,0 +1,117 @@
+package course
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// certificatePageWidth and certificatePageHeight are the size of a landscape
+// A4 page in PDF points.
+const (
+	certificatePageWidth  = 842
+	certificatePageHeight = 595
+)
+
+// certificatePDFLine is a line of centred text on a Certificate.
+type certificatePDFLine struct {
+	text     string
+	font     string
+	size     float64
+	baseline float64
+}
+
+// RenderPDF renders a Certificate as a single-page PDF document. The
+// recipient is printed as the name of the student, and verifyURL is where
+// anyone holding the document can check that it is authentic.
+func (c Certificate) RenderPDF(recipient string, verifyURL string) []byte {
+	lines := []certificatePDFLine{
+		{text: "Certificate of Completion", font: "F2", size: 36, baseline: 470},
+		{text: "This certifies that", font: "F1", size: 16, baseline: 400},
+		{text: recipient, font: "F2", size: 28, baseline: 355},
+		{text: "has successfully completed the course", font: "F1", size: 16, baseline: 305},
+		{text: c.CourseTitle, font: "F2", size: 24, baseline: 262},
+		{text: "Completed on " + c.CompletedAt.UTC().Format("2 January 2006"), font: "F1", size: 14, baseline: 215},
+		{text: "Verification code: " + c.Code, font: "F1", size: 12, baseline: 120},
+		{text: "Verify at " + verifyURL, font: "F1", size: 10, baseline: 100},
+	}
+
+	if c.IsRevoked() {
+		lines = append(lines, certificatePDFLine{
+			text:     "REVOKED on " + c.RevokedAt.Time.UTC().Format("2 January 2006"),
+			font:     "F2",
+			size:     18,
+			baseline: 160,
+		})
+	}
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "4 w 30 30 %d %d re S\n", certificatePageWidth-60, certificatePageHeight-60)
+	for _, line := range lines {
+		x := (certificatePageWidth - approximateTextWidth(line.text, line.size)) / 2
+		if x < 40 {
+			x = 40
+		}
+
+		fmt.Fprintf(&content, "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n",
+			line.font, line.size, x, line.baseline, escapePDFText(line.text))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+			certificatePageWidth, certificatePageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return doc.Bytes()
+}
+
+// approximateTextWidth estimates the width of Helvetica text, which averages
+// a little over half of the font size per character.
+func approximateTextWidth(text string, size float64) float64 {
+	return float64(len([]rune(text))) * size * 0.52
+}
+
+// escapePDFText escapes a string for use in a PDF literal string. Characters
+// the standard fonts cannot show are replaced with a question mark.
+func escapePDFText(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20:
+			sb.WriteByte(' ')
+		case r < 0x7f:
+			sb.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,194 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source certificate_repository.go -destination mock/certificate_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	certificateQueries = struct {
+		selectCertificate string
+		insertCertificate string
+		revokeCertificate string
+	}{
+		selectCertificate: `
+			SELECT
+				id,
+				course_id,
+				student_id,
+				student_name,
+				code,
+				course_title,
+				completed_at,
+				signature,
+				issued_at,
+				revoked_at,
+				revoked_by,
+				revocation_reason
+			FROM certificates
+		`,
+
+		insertCertificate: `
+			INSERT INTO certificates (
+				id,
+				course_id,
+				student_id,
+				student_name,
+				code,
+				course_title,
+				completed_at,
+				signature,
+				issued_at
+			) VALUES (
+				:id,
+				:course_id,
+				:student_id,
+				:student_name,
+				:code,
+				:course_title,
+				:completed_at,
+				:signature,
+				:issued_at
+			)
+		`,
+
+		revokeCertificate: `
+			UPDATE certificates
+			SET
+				revoked_at = :revoked_at,
+				revoked_by = :revoked_by,
+				revocation_reason = :revocation_reason
+			WHERE id = :id
+		`,
+	}
+)
+
+// CertificateRepository is the repository for Certificate data.
+type CertificateRepository interface {
+	CreateCertificate(certificate Certificate) (err error)
+	ResolveCertificateByCode(code string) (certificate Certificate, err error)
+	ResolveCertificateByCourseAndStudent(courseID uuid.UUID, studentID uuid.UUID) (certificate Certificate, err error)
+	ResolveCertificateByID(id uuid.UUID) (certificate Certificate, err error)
+	ResolveCertificatesByCourseID(courseID uuid.UUID) (certificates []Certificate, err error)
+	ResolveCertificatesByStudentID(studentID uuid.UUID) (certificates []Certificate, err error)
+	RevokeCertificate(certificate Certificate) (err error)
+}
+
+// CertificateRepositoryMySQL is the MySQL-backed implementation of CertificateRepository.
+type CertificateRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideCertificateRepositoryMySQL is the provider for this repository.
+func ProvideCertificateRepositoryMySQL(db *infras.MySQLConn) *CertificateRepositoryMySQL {
+	s := new(CertificateRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCertificate creates a new Certificate.
+func (r *CertificateRepositoryMySQL) CreateCertificate(certificate Certificate) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, certificateQueries.insertCertificate, certificate); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveCertificateByCode resolves a Certificate by its verification code.
+func (r *CertificateRepositoryMySQL) ResolveCertificateByCode(code string) (certificate Certificate, err error) {
+	return r.resolveCertificate(" WHERE code = ?", code)
+}
+
+// ResolveCertificateByCourseAndStudent resolves the Certificate a student
+// received for a Course.
+func (r *CertificateRepositoryMySQL) ResolveCertificateByCourseAndStudent(courseID uuid.UUID, studentID uuid.UUID) (certificate Certificate, err error) {
+	return r.resolveCertificate(" WHERE course_id = ? AND student_id = ?", courseID.String(), studentID.String())
+}
+
+// ResolveCertificateByID resolves a Certificate by its ID.
+func (r *CertificateRepositoryMySQL) ResolveCertificateByID(id uuid.UUID) (certificate Certificate, err error) {
+	return r.resolveCertificate(" WHERE id = ?", id.String())
+}
+
+// ResolveCertificatesByCourseID resolves the Certificates issued for a Course, newest first.
+func (r *CertificateRepositoryMySQL) ResolveCertificatesByCourseID(courseID uuid.UUID) (certificates []Certificate, err error) {
+	err = r.DB.Read.Select(
+		&certificates,
+		certificateQueries.selectCertificate+" WHERE course_id = ? ORDER BY issued_at DESC",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCertificatesByStudentID resolves the Certificates issued to a student, newest first.
+func (r *CertificateRepositoryMySQL) ResolveCertificatesByStudentID(studentID uuid.UUID) (certificates []Certificate, err error) {
+	err = r.DB.Read.Select(
+		&certificates,
+		certificateQueries.selectCertificate+" WHERE student_id = ? ORDER BY issued_at DESC",
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// RevokeCertificate stores the revocation of a Certificate.
+func (r *CertificateRepositoryMySQL) RevokeCertificate(certificate Certificate) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, certificateQueries.revokeCertificate, certificate); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// resolveCertificate resolves a single Certificate matching a WHERE clause.
+func (r *CertificateRepositoryMySQL) resolveCertificate(where string, args ...interface{}) (certificate Certificate, err error) {
+	err = r.DB.Read.Get(&certificate, certificateQueries.selectCertificate+where, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("certificate")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *CertificateRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
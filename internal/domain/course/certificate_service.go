@@ -0,0 +1,226 @@
+package course
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// CertificateService is the service interface for completion Certificates.
+type CertificateService interface {
+	ClaimCertificate(courseID uuid.UUID, studentID uuid.UUID, studentName string) (certificate Certificate, err error)
+	RenderCertificatePDF(id uuid.UUID, userID uuid.UUID, role string) (document []byte, certificate Certificate, err error)
+	ResolveCertificateByID(id uuid.UUID, userID uuid.UUID, role string) (certificate Certificate, err error)
+	ResolveCertificatesByCourseID(courseID uuid.UUID, userID uuid.UUID) (certificates []Certificate, err error)
+	ResolveCertificatesByStudentID(studentID uuid.UUID) (certificates []Certificate, err error)
+	RevokeCertificate(id uuid.UUID, requestFormat CertificateRevocationRequestFormat, userID uuid.UUID) (certificate Certificate, err error)
+	VerifyCertificate(code string) (verification CertificateVerificationFormat, err error)
+}
+
+// CertificateServiceImpl is the service implementation for completion Certificates.
+type CertificateServiceImpl struct {
+	AttendanceRepository   AttendanceRepository
+	CertificateRepository  CertificateRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	ProgressRepository     ProgressRepository
+	Config                 *configs.Config
+}
+
+// ProvideCertificateServiceImpl is the provider for this service.
+func ProvideCertificateServiceImpl(
+	attendanceRepository AttendanceRepository,
+	certificateRepository CertificateRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	progressRepository ProgressRepository,
+	config *configs.Config) *CertificateServiceImpl {
+	s := new(CertificateServiceImpl)
+	s.AttendanceRepository = attendanceRepository
+	s.CertificateRepository = certificateRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.ProgressRepository = progressRepository
+	s.Config = config
+
+	return s
+}
+
+// ClaimCertificate issues the Certificate of a Course an actively enrolled
+// student completed. Certificates are normally issued as soon as the last
+// Lesson is completed; claiming one that exists returns it unchanged.
+func (s *CertificateServiceImpl) ClaimCertificate(courseID uuid.UUID, studentID uuid.UUID, studentName string) (certificate Certificate, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, courseID, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	return issueCertificate(
+		s.AttendanceRepository,
+		s.CertificateRepository,
+		s.CourseRepository,
+		s.EnrollmentRepository,
+		s.LiveSessionRepository,
+		s.ProgressRepository,
+		s.Config.Certificate.SigningKey,
+		courseID,
+		studentID,
+		studentName)
+}
+
+// RenderCertificatePDF renders a Certificate readable by the given user as a
+// PDF document made out to the recipient.
+func (s *CertificateServiceImpl) RenderCertificatePDF(id uuid.UUID, userID uuid.UUID, role string) (document []byte, certificate Certificate, err error) {
+	certificate, err = s.ResolveCertificateByID(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	document = certificate.RenderPDF(certificate.Recipient(), s.Config.App.URL+"/v1/certificates/verify/"+certificate.Code)
+	return
+}
+
+// ResolveCertificateByID resolves a Certificate for the student it was issued
+// to or those allowed to view the students of its Course.
+func (s *CertificateServiceImpl) ResolveCertificateByID(id uuid.UUID, userID uuid.UUID, role string) (certificate Certificate, err error) {
+	certificate, err = s.CertificateRepository.ResolveCertificateByID(id)
+	if err != nil {
+		return
+	}
+
+	if role != shared.RoleTeacher {
+		if certificate.StudentID != userID {
+			return certificate, failure.NotFound("certificate")
+		}
+
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, certificate.CourseID, userID, PermissionViewStudents)
+	return
+}
+
+// ResolveCertificatesByCourseID resolves the Certificates issued for a Course,
+// for those allowed to view its students.
+func (s *CertificateServiceImpl) ResolveCertificatesByCourseID(courseID uuid.UUID, userID uuid.UUID) (certificates []Certificate, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	return s.CertificateRepository.ResolveCertificatesByCourseID(course.ID)
+}
+
+// ResolveCertificatesByStudentID resolves the Certificates issued to a student.
+func (s *CertificateServiceImpl) ResolveCertificatesByStudentID(studentID uuid.UUID) (certificates []Certificate, err error) {
+	return s.CertificateRepository.ResolveCertificatesByStudentID(studentID)
+}
+
+// RevokeCertificate revokes a Certificate of a Course the given user manages
+// certificates of.
+func (s *CertificateServiceImpl) RevokeCertificate(id uuid.UUID, requestFormat CertificateRevocationRequestFormat, userID uuid.UUID) (certificate Certificate, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return certificate, failure.BadRequest(err)
+	}
+
+	certificate, err = s.CertificateRepository.ResolveCertificateByID(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, certificate.CourseID, userID, PermissionManageCertificates)
+	if err != nil {
+		return
+	}
+
+	err = certificate.Revoke(requestFormat.Reason, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.CertificateRepository.RevokeCertificate(certificate)
+	return
+}
+
+// VerifyCertificate tells anyone holding a verification code whether the
+// Certificate it belongs to is authentic and still in force.
+func (s *CertificateServiceImpl) VerifyCertificate(code string) (verification CertificateVerificationFormat, err error) {
+	certificate, err := s.CertificateRepository.ResolveCertificateByCode(NormalizeCertificateCode(code))
+	if err != nil {
+		return
+	}
+
+	return certificate.ToVerificationFormat(s.Config.Certificate.SigningKey), nil
+}
+
+// issueCertificate issues the Certificate of a completed Course to a student
+// unless one was issued already, in which case that one is returned. A
+// revoked Certificate is not replaced.
+func issueCertificate(
+	attendanceRepository AttendanceRepository,
+	certificateRepository CertificateRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	progressRepository ProgressRepository,
+	signingKey string,
+	courseID uuid.UUID,
+	studentID uuid.UUID,
+	studentName string) (certificate Certificate, err error) {
+	certificate, err = certificateRepository.ResolveCertificateByCourseAndStudent(courseID, studentID)
+	if err == nil || failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	course, err := courseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	progress, _, err := resolveCourseCompletion(
+		attendanceRepository,
+		enrollmentRepository,
+		liveSessionRepository,
+		progressRepository,
+		courseID,
+		studentID)
+	if err != nil {
+		return
+	}
+
+	if !progress.IsComplete() {
+		return certificate, failure.Conflict("issue", "certificate", "the course is not completed yet")
+	}
+
+	completedAt := progress.LastActivityAt.ValueOrZero()
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+
+	certificate, err = Certificate{}.NewCertificate(course, studentID, studentName, completedAt, signingKey)
+	if err != nil {
+		return
+	}
+
+	err = certificateRepository.CreateCertificate(certificate)
+	if err != nil {
+		// a concurrent request may have issued it in the meantime
+		existing, resolveErr := certificateRepository.ResolveCertificateByCourseAndStudent(courseID, studentID)
+		if resolveErr == nil {
+			return existing, nil
+		}
+	}
+
+	return
+}
@@ -0,0 +1,140 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateService(t *testing.T) {
+	config := &configs.Config{}
+	config.Certificate.SigningKey = testSigningKey
+	fullCourse := newDraftCourse()
+	studentID := getRandomUUID()
+	completedAt := time.Date(2026, time.March, 29, 1, 30, 0, 0, time.UTC)
+
+	lessonProgress := func(completed int) []course.LessonProgress {
+		var progress []course.LessonProgress
+		for i, lesson := range fullCourse.Modules[0].Lessons {
+			p := course.LessonProgress{}.NewLessonProgress(lesson, studentID)
+			if i < completed {
+				p.CompletedAt = null.TimeFrom(completedAt)
+				p.UpdatedAt = null.TimeFrom(completedAt)
+			}
+
+			progress = append(progress, p)
+		}
+
+		return progress
+	}
+
+	t.Run("claim issues a certificate for a completed course", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCertificateRepo := course_mock.NewMockCertificateRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		s := course.ProvideCertificateServiceImpl(mockAttendanceRepo, mockCertificateRepo, nil, mockCourseRepo, mockEnrollmentRepo, nil, mockProgressRepo, config)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).
+			Return(newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+		mockCertificateRepo.EXPECT().ResolveCertificateByCourseAndStudent(fullCourse.ID, studentID).
+			Return(course.Certificate{}, failure.NotFound("certificate"))
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(fullCourse.ID).Return(2, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(fullCourse.ID, studentID).Return(lessonProgress(2), nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(fullCourse.ID).Return(course.CompletionRules{}, failure.NotFound("completionRules"))
+		mockCertificateRepo.EXPECT().CreateCertificate(gomock.Any()).Return(nil)
+
+		got, err := s.ClaimCertificate(fullCourse.ID, studentID, "Ada Lovelace")
+
+		assert.NoError(t, err)
+		assert.Equal(t, studentID, got.StudentID)
+		assert.Equal(t, "Ada Lovelace", got.Recipient())
+		assert.Equal(t, fullCourse.Title, got.CourseTitle)
+		assert.True(t, completedAt.Equal(got.CompletedAt))
+		assert.True(t, got.HasValidSignature(testSigningKey))
+	})
+
+	t.Run("claim is rejected before the course is completed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCertificateRepo := course_mock.NewMockCertificateRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		s := course.ProvideCertificateServiceImpl(mockAttendanceRepo, mockCertificateRepo, nil, mockCourseRepo, mockEnrollmentRepo, nil, mockProgressRepo, config)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).
+			Return(newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+		mockCertificateRepo.EXPECT().ResolveCertificateByCourseAndStudent(fullCourse.ID, studentID).
+			Return(course.Certificate{}, failure.NotFound("certificate"))
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(fullCourse.ID).Return(2, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(fullCourse.ID, studentID).Return(lessonProgress(1), nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(fullCourse.ID).Return(course.CompletionRules{}, failure.NotFound("completionRules"))
+
+		_, err := s.ClaimCertificate(fullCourse.ID, studentID, "Ada Lovelace")
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+
+	t.Run("completing the last lesson issues the certificate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		lesson := fullCourse.Modules[0].Lessons[1]
+		mockCertificateRepo := course_mock.NewMockCertificateRepository(ctrl)
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		s := course.ProvideProgressServiceImpl(mockAttendanceRepo, mockCohortRepo, nil, mockCourseRepo, mockEnrollmentRepo, nil, mockModuleRepo, mockProgressRepo, mockCertificateRepo, config)
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(lesson.ModuleID).Return(fullCourse.Modules[0], nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).
+			Return(newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive), nil).Times(2)
+		mockProgressRepo.EXPECT().RecordLessonProgress(lesson, studentID, gomock.Any()).Return(lessonProgress(2)[1], nil)
+		mockCertificateRepo.EXPECT().ResolveCertificateByCourseAndStudent(fullCourse.ID, studentID).
+			Return(course.Certificate{}, failure.NotFound("certificate"))
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(fullCourse.ID).Return(2, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(fullCourse.ID, studentID).Return(lessonProgress(2), nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(fullCourse.ID).Return(course.CompletionRules{}, failure.NotFound("completionRules"))
+		mockCertificateRepo.EXPECT().CreateCertificate(gomock.Any()).Return(nil)
+
+		_, err := s.RecordLessonProgress(lesson.ID, course.LessonProgressRequestFormat{Completed: true}, studentID, "Ada Lovelace")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("verify a revoked certificate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		certificate := newCertificate(t)
+		_ = certificate.Revoke("", getRandomUUID())
+		mockCertificateRepo := course_mock.NewMockCertificateRepository(ctrl)
+		s := &course.CertificateServiceImpl{CertificateRepository: mockCertificateRepo, Config: config}
+		mockCertificateRepo.EXPECT().ResolveCertificateByCode(certificate.Code).Return(certificate, nil)
+
+		got, err := s.VerifyCertificate(" " + certificate.Code[0:4] + certificate.Code[5:9] + certificate.Code[10:])
+
+		assert.NoError(t, err)
+		assert.False(t, got.Valid)
+		assert.Equal(t, course.CertificateStatusRevoked, got.Status)
+	})
+}
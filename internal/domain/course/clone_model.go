@@ -0,0 +1,417 @@
+package course
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+//// Course Clone
+
+// CourseCloneSource is the content of a Course to be cloned: everything
+// teachers author, and nothing of its students.
+type CourseCloneSource struct {
+	// Course has its Modules and Lessons, Categories, Tags and
+	// PrerequisiteIDs attached.
+	Course Course
+	// Quizzes have their QuizQuestions attached.
+	Quizzes []Quiz
+	// Assignments have their Rubric attached.
+	Assignments  []Assignment
+	Attachments  []Attachment
+	Cohorts      []CohortSchedule
+	LiveSessions []LiveSession
+}
+
+// CourseClone is a Course copied under new IDs as a draft, with everything to
+// be created along with it.
+type CourseClone struct {
+	// Course has its Modules and Lessons attached.
+	Course        Course
+	Quizzes       []Quiz
+	Assignments   []Assignment
+	Attachments   []Attachment
+	Cohorts       []CohortSchedule
+	LiveSessions  []LiveSession
+	CategoryIDs   []uuid.UUID
+	TagIDs        []uuid.UUID
+	Prerequisites []CoursePrerequisite
+	// IDs maps the IDs of the source Course and its content to the new ones.
+	IDs map[uuid.UUID]uuid.UUID
+	// Files maps the StorageKey of every Attachment to that of the file it is
+	// copied from.
+	Files map[string]string
+}
+
+// NewCourseClone copies a Course with all of its content into a new draft
+// owned by the cloning user. Every ID is replaced, including references to
+// them in the content of the Course and its Lessons, and the dates of
+// Assignments, Cohorts and LiveSessions are moved by the requested number of
+// days. Cohorts are copied without their instructors.
+func (c CourseClone) NewCourseClone(source CourseCloneSource, req CourseCloneRequestFormat, userID uuid.UUID) (clone CourseClone, err error) {
+	now := time.Now()
+	clone = CourseClone{
+		IDs:   make(map[uuid.UUID]uuid.UUID),
+		Files: make(map[string]string),
+	}
+
+	title := source.Course.Title
+	if req.Title != "" {
+		title = req.Title
+	}
+
+	clone.Course = Course{
+		ID:        clone.remap(source.Course.ID),
+		UserID:    userID,
+		Title:     title,
+		Content:   source.Course.Content,
+		SeatLimit: source.Course.SeatLimit,
+		Status:    CourseStatusDraft,
+		CreatedAt: now,
+		CreatedBy: userID,
+	}
+
+	lessons := make(map[uuid.UUID]Lesson)
+	for _, module := range source.Course.Modules {
+		newModule := Module{
+			ID:        clone.remap(module.ID),
+			CourseID:  clone.Course.ID,
+			Title:     module.Title,
+			Position:  module.Position,
+			CreatedAt: now,
+			CreatedBy: userID,
+		}
+
+		for _, lesson := range module.Lessons {
+			newLesson := Lesson{
+				ID:        clone.remap(lesson.ID),
+				ModuleID:  newModule.ID,
+				CourseID:  clone.Course.ID,
+				Title:     lesson.Title,
+				Content:   lesson.Content,
+				Position:  lesson.Position,
+				CreatedAt: now,
+				CreatedBy: userID,
+			}
+
+			lessons[lesson.ID] = newLesson
+			newModule.Lessons = append(newModule.Lessons, newLesson)
+		}
+
+		clone.Course.Modules = append(clone.Course.Modules, newModule)
+	}
+
+	for _, quiz := range source.Quizzes {
+		if lesson, ok := lessons[quiz.LessonID]; ok {
+			clone.Quizzes = append(clone.Quizzes, clone.copyQuiz(quiz, lesson, userID))
+		}
+	}
+
+	for _, assignment := range source.Assignments {
+		if lesson, ok := lessons[assignment.LessonID]; ok {
+			clone.Assignments = append(clone.Assignments, clone.copyAssignment(assignment, lesson, req.ShiftDays, userID))
+		}
+	}
+
+	for _, attachment := range source.Attachments {
+		if lesson, ok := lessons[attachment.LessonID]; ok {
+			clone.Attachments = append(clone.Attachments, clone.copyAttachment(attachment, lesson, userID))
+		}
+	}
+
+	for _, schedule := range source.Cohorts {
+		clone.Cohorts = append(clone.Cohorts, clone.copyCohort(schedule, req.ShiftDays, userID))
+	}
+
+	for _, session := range source.LiveSessions {
+		if session.CohortID.Valid {
+			if _, ok := clone.IDs[session.CohortID.UUID]; !ok {
+				continue
+			}
+		}
+
+		clone.LiveSessions = append(clone.LiveSessions, clone.copyLiveSession(session, req.ShiftDays, userID))
+	}
+
+	for _, category := range source.Course.Categories {
+		clone.CategoryIDs = append(clone.CategoryIDs, category.ID)
+	}
+
+	for _, tag := range source.Course.Tags {
+		clone.TagIDs = append(clone.TagIDs, tag.ID)
+	}
+
+	for _, prerequisiteID := range source.Course.PrerequisiteIDs {
+		clone.Prerequisites = append(clone.Prerequisites, CoursePrerequisite{
+			CourseID:       clone.Course.ID,
+			PrerequisiteID: prerequisiteID,
+			CreatedAt:      now,
+			CreatedBy:      userID,
+		})
+	}
+
+	replaceIDReferences(&clone.Course, clone.IDs)
+	err = clone.Validate()
+
+	return
+}
+
+// Validate validates every entity to be created.
+func (c *CourseClone) Validate() (err error) {
+	err = c.Course.Validate()
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	for _, module := range c.Course.Modules {
+		err = module.Validate()
+		if err != nil {
+			return failure.BadRequest(err)
+		}
+
+		for _, lesson := range module.Lessons {
+			err = lesson.Validate()
+			if err != nil {
+				return failure.BadRequest(err)
+			}
+		}
+	}
+
+	for _, quiz := range c.Quizzes {
+		err = quiz.Validate()
+		if err != nil {
+			return failure.BadRequest(fmt.Errorf("quiz %q: %w", quiz.Title, err))
+		}
+	}
+
+	for _, assignment := range c.Assignments {
+		err = assignment.Validate()
+		if err != nil {
+			return failure.BadRequest(fmt.Errorf("assignment %q: %w", assignment.Title, err))
+		}
+	}
+
+	for _, attachment := range c.Attachments {
+		err = attachment.Validate()
+		if err != nil {
+			return failure.BadRequest(err)
+		}
+	}
+
+	for _, schedule := range c.Cohorts {
+		err = schedule.Cohort.Validate()
+		if err != nil {
+			return failure.BadRequest(fmt.Errorf("cohort %q: %w", schedule.Cohort.Name, err))
+		}
+	}
+
+	for _, session := range c.LiveSessions {
+		err = session.Validate()
+		if err != nil {
+			return failure.BadRequest(fmt.Errorf("live session %q: %w", session.Title, err))
+		}
+	}
+
+	return
+}
+
+// ToResponseFormat converts this CourseClone to its response format.
+func (c CourseClone) ToResponseFormat() CourseCloneResponseFormat {
+	return CourseCloneResponseFormat{
+		Course: c.Course.ToResponseFormat(),
+		IDs:    c.IDs,
+	}
+}
+
+// copyAssignment copies an Assignment, with its Rubric, into a Lesson of the
+// clone, moving its due date and late cutoff.
+func (c *CourseClone) copyAssignment(assignment Assignment, lesson Lesson, days int, userID uuid.UUID) Assignment {
+	newAssignment := Assignment{
+		ID:                c.remap(assignment.ID),
+		CourseID:          lesson.CourseID,
+		LessonID:          lesson.ID,
+		Title:             assignment.Title,
+		Instructions:      assignment.Instructions,
+		DueAt:             assignment.DueAt.AddDate(0, 0, days),
+		MaxScore:          assignment.MaxScore,
+		LatePenaltyPerDay: assignment.LatePenaltyPerDay,
+		MaxLatePenalty:    assignment.MaxLatePenalty,
+		CreatedAt:         lesson.CreatedAt,
+		CreatedBy:         userID,
+	}
+
+	if assignment.LateCutoffAt.Valid {
+		newAssignment.LateCutoffAt = null.TimeFrom(assignment.LateCutoffAt.Time.AddDate(0, 0, days))
+	}
+
+	for _, criterion := range assignment.Rubric {
+		newAssignment.Rubric = append(newAssignment.Rubric, RubricCriterion{
+			ID:           c.remap(criterion.ID),
+			AssignmentID: newAssignment.ID,
+			Title:        criterion.Title,
+			MaxPoints:    criterion.MaxPoints,
+			Position:     criterion.Position,
+		})
+	}
+
+	return newAssignment
+}
+
+// copyAttachment copies an Attachment into a Lesson of the clone. Its file is
+// copied under a new StorageKey.
+func (c *CourseClone) copyAttachment(attachment Attachment, lesson Lesson, userID uuid.UUID) Attachment {
+	attachmentID := c.remap(attachment.ID)
+	newAttachment := Attachment{
+		ID:          attachmentID,
+		LessonID:    lesson.ID,
+		CourseID:    lesson.CourseID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.Checksum,
+		StorageKey:  attachmentStorageKey(lesson, attachmentID),
+		CreatedAt:   lesson.CreatedAt,
+		CreatedBy:   userID,
+	}
+
+	c.Files[newAttachment.StorageKey] = attachment.StorageKey
+	return newAttachment
+}
+
+// copyCohort copies a Cohort with its drip schedule, moving its dates. Lesson
+// releases follow the Lessons they release.
+func (c *CourseClone) copyCohort(schedule CohortSchedule, days int, userID uuid.UUID) CohortSchedule {
+	cohort := schedule.Cohort
+	newCohort := Cohort{
+		ID:                 c.remap(cohort.ID),
+		CourseID:           c.Course.ID,
+		Name:               cohort.Name,
+		Timezone:           cohort.Timezone,
+		StartDate:          cohort.StartDate.AddDate(0, 0, days),
+		EndDate:            cohort.EndDate.AddDate(0, 0, days),
+		EnrollmentOpensAt:  shiftLocalDate(cohort.EnrollmentOpensAt, days, cohort.Timezone),
+		EnrollmentClosesAt: shiftLocalDate(cohort.EnrollmentClosesAt, days, cohort.Timezone),
+		CreatedAt:          c.Course.CreatedAt,
+		CreatedBy:          userID,
+	}
+
+	newSchedule := CohortSchedule{Cohort: newCohort}
+	for _, release := range schedule.Releases {
+		lessonID, ok := c.IDs[release.LessonID]
+		if !ok {
+			continue
+		}
+
+		newSchedule.Releases = append(newSchedule.Releases, CohortLessonRelease{
+			CohortID:  newCohort.ID,
+			LessonID:  lessonID,
+			DayOffset: release.DayOffset,
+		})
+	}
+
+	return newSchedule
+}
+
+// copyLiveSession copies a LiveSession, moving it and its recurrence while
+// keeping its local time.
+func (c *CourseClone) copyLiveSession(session LiveSession, days int, userID uuid.UUID) LiveSession {
+	newSession := LiveSession{
+		ID:              c.remap(session.ID),
+		CourseID:        c.Course.ID,
+		Title:           session.Title,
+		Description:     session.Description,
+		StartsAt:        shiftLocalDate(session.StartsAt, days, session.Timezone),
+		Timezone:        session.Timezone,
+		DurationMinutes: session.DurationMinutes,
+		MeetingURL:      session.MeetingURL,
+		RecurrenceRule:  session.RecurrenceRule.shift(days),
+		CreatedAt:       c.Course.CreatedAt,
+		CreatedBy:       userID,
+	}
+
+	if session.CohortID.Valid {
+		newSession.CohortID = nuuid.From(c.IDs[session.CohortID.UUID])
+	}
+
+	return newSession
+}
+
+// copyQuiz copies a Quiz, with its QuizQuestions and QuizOptions, into a
+// Lesson of the clone.
+func (c *CourseClone) copyQuiz(quiz Quiz, lesson Lesson, userID uuid.UUID) Quiz {
+	newQuiz := Quiz{
+		ID:               c.remap(quiz.ID),
+		CourseID:         lesson.CourseID,
+		LessonID:         lesson.ID,
+		Title:            quiz.Title,
+		TimeLimitSeconds: quiz.TimeLimitSeconds,
+		MaxAttempts:      quiz.MaxAttempts,
+		ShuffleAnswers:   quiz.ShuffleAnswers,
+		CreatedAt:        lesson.CreatedAt,
+		CreatedBy:        userID,
+	}
+
+	for _, question := range quiz.Questions {
+		newQuestion := QuizQuestion{
+			ID:         c.remap(question.ID),
+			QuizID:     newQuiz.ID,
+			Type:       question.Type,
+			Prompt:     question.Prompt,
+			AnswerText: question.AnswerText,
+			Points:     question.Points,
+			Position:   question.Position,
+		}
+
+		for _, option := range question.Options {
+			newQuestion.Options = append(newQuestion.Options, QuizOption{
+				ID:         c.remap(option.ID),
+				QuestionID: newQuestion.ID,
+				Text:       option.Text,
+				IsCorrect:  option.IsCorrect,
+				Position:   option.Position,
+			})
+		}
+
+		newQuiz.Questions = append(newQuiz.Questions, newQuestion)
+	}
+
+	return newQuiz
+}
+
+// remap returns a new ID for one of the source Course, and records it.
+func (c *CourseClone) remap(sourceID uuid.UUID) uuid.UUID {
+	id, _ := uuid.NewV4()
+	c.IDs[sourceID] = id
+	return id
+}
+
+// shiftLocalDate moves a time by a number of days in a time zone, keeping
+// its local time whatever the daylight saving time.
+func shiftLocalDate(t time.Time, days int, timezone string) time.Time {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return t.In(location).AddDate(0, 0, days).UTC()
+}
+
+// CourseCloneRequestFormat represents a Course clone request.
+type CourseCloneRequestFormat struct {
+	// Title names the clone. It defaults to the title of the source Course.
+	Title string `json:"title"`
+	// ShiftDays moves the dates of Assignments, Cohorts and LiveSessions by
+	// this many days, forward or back.
+	ShiftDays int `json:"shiftDays" validate:"min=-3650,max=3650"`
+}
+
+// CourseCloneResponseFormat represents a CourseClone's standard formatting
+// for JSON serializing.
+type CourseCloneResponseFormat struct {
+	Course CourseResponseFormat    `json:"course"`
+	IDs    map[uuid.UUID]uuid.UUID `json:"ids" swaggertype:"object,string"`
+}
@@ -0,0 +1,116 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCloneSource creates a Course with a Quiz, an Assignment and an Attachment
+// on its first Lesson, and a Cohort with a weekly LiveSession.
+func newCloneSource(t *testing.T) course.CourseCloneSource {
+	c := newDraftCourse()
+	lesson := c.Modules[0].Lessons[0]
+	c.Content = "Start with /lessons/" + lesson.ID.String()
+	c.Tags = []course.Tag{{ID: getRandomUUID(), Name: "go"}}
+	c.PrerequisiteIDs = append(c.PrerequisiteIDs, getRandomUUID())
+
+	quiz := newQuiz(t)
+	quiz.CourseID, quiz.LessonID = c.ID, lesson.ID
+
+	assignment := newAssignment(t, time.Date(2026, 3, 13, 23, 0, 0, 0, time.UTC), []course.RubricCriterionRequestFormat{{Title: "Structure", MaxPoints: 10}})
+	assignment.CourseID, assignment.LessonID = c.ID, lesson.ID
+
+	attachment, err := course.Attachment{}.NewAttachmentFromUpload(lesson, newUpload("notes.pdf", "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"), 1<<20, c.UserID)
+	assert.NoError(t, err)
+
+	cohort, err := course.Cohort{}.NewCohortFromRequestFormat(c.ID, newCohortRequest(), c.UserID)
+	assert.NoError(t, err)
+	cohort.InstructorIDs = append(cohort.InstructorIDs, getRandomUUID())
+
+	session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(c.ID, newLiveSessionRequest(&course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TU", "TH"}, Until: "2026-04-23"}), c.UserID)
+	assert.NoError(t, err)
+	session.CohortID = nuuid.From(cohort.ID)
+
+	return course.CourseCloneSource{
+		Course:      c,
+		Quizzes:     []course.Quiz{quiz},
+		Assignments: []course.Assignment{assignment},
+		Attachments: []course.Attachment{attachment},
+		Cohorts: []course.CohortSchedule{{
+			Cohort:   cohort,
+			Releases: []course.CohortLessonRelease{{CohortID: cohort.ID, LessonID: lesson.ID, DayOffset: 7}},
+		}},
+		LiveSessions: []course.LiveSession{session},
+	}
+}
+
+func TestCourseClone(t *testing.T) {
+	userID := getRandomUUID()
+
+	t.Run("clones are drafts of the caller under new IDs", func(t *testing.T) {
+		source := newCloneSource(t)
+		source.Course.Status = course.CourseStatusPublished
+		lesson := source.Course.Modules[0].Lessons[0]
+
+		clone, err := course.CourseClone{}.NewCourseClone(source, course.CourseCloneRequestFormat{Title: "Go basics, fall"}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go basics, fall", clone.Course.Title)
+		assert.Equal(t, userID, clone.Course.UserID)
+		assert.Equal(t, course.CourseStatusDraft, clone.Course.Status)
+		assert.False(t, clone.Course.PublishedVersion.Valid)
+		assert.NotEqual(t, source.Course.ID, clone.Course.ID)
+		assert.Equal(t, "Start with /lessons/"+clone.IDs[lesson.ID].String(), clone.Course.Content)
+		assert.Equal(t, clone.IDs[lesson.ID], clone.Quizzes[0].LessonID)
+		assert.NotEqual(t, source.Quizzes[0].Questions[0].ID, clone.Quizzes[0].Questions[0].ID)
+		assert.Equal(t, clone.Assignments[0].ID, clone.Assignments[0].Rubric[0].AssignmentID)
+		assert.Equal(t, source.Attachments[0].StorageKey, clone.Files[clone.Attachments[0].StorageKey])
+		assert.Equal(t, clone.IDs[lesson.ID], clone.Cohorts[0].Releases[0].LessonID)
+		assert.Empty(t, clone.Cohorts[0].Cohort.InstructorIDs)
+		assert.Equal(t, nuuid.From(clone.Cohorts[0].Cohort.ID), clone.LiveSessions[0].CohortID)
+		assert.Equal(t, source.Course.Tags[0].ID, clone.TagIDs[0])
+		assert.Equal(t, clone.Course.ID, clone.Prerequisites[0].CourseID)
+	})
+
+	t.Run("clones keep the title of their source by default", func(t *testing.T) {
+		clone, err := course.CourseClone{}.NewCourseClone(newCloneSource(t), course.CourseCloneRequestFormat{}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go basics", clone.Course.Title)
+	})
+
+	t.Run("schedules are shifted keeping their local time", func(t *testing.T) {
+		source := newCloneSource(t)
+
+		// 26 weeks and 1 day later, across the start of daylight saving time
+		clone, err := course.CourseClone{}.NewCourseClone(source, course.CourseCloneRequestFormat{ShiftDays: 183}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, source.Assignments[0].DueAt.AddDate(0, 0, 183), clone.Assignments[0].DueAt)
+
+		cohort := clone.Cohorts[0].Cohort
+		assert.Equal(t, "2026-09-01", cohort.StartDate.Format("2006-01-02"))
+		assert.Equal(t, "2026-10-24", cohort.EndDate.Format("2006-01-02"))
+
+		session := clone.LiveSessions[0]
+		newYork, _ := time.LoadLocation("America/New_York")
+		assert.Equal(t, time.Date(2026, 9, 2, 18, 0, 0, 0, newYork).UTC(), session.StartsAt)
+		assert.Equal(t, "WE,FR", session.ByDay)
+		assert.Equal(t, "2026-10-23", session.Until.Time.Format("2006-01-02"))
+	})
+
+	t.Run("schedules cannot be shifted by more than ten years", func(t *testing.T) {
+		source := newCloneSource(t)
+		s := course.ProvideCloneServiceImpl(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := s.CloneCourse(source.Course.ID, course.CourseCloneRequestFormat{ShiftDays: 3651}, userID)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
@@ -0,0 +1,217 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source clone_repository.go -destination mock/clone_repository_mock.go -package course_mock
+
+import (
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// CloneRepository is the repository for Courses cloned from other Courses.
+type CloneRepository interface {
+	CreateCourseClone(clone CourseClone) (err error)
+}
+
+// CloneRepositoryMySQL is the MySQL-backed implementation of CloneRepository.
+type CloneRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideCloneRepositoryMySQL is the provider for this repository.
+func ProvideCloneRepositoryMySQL(db *infras.MySQLConn) *CloneRepositoryMySQL {
+	s := new(CloneRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCourseClone creates a cloned Course with its Modules, Lessons,
+// Quizzes, Assignments, Attachments, Cohorts and LiveSessions, and links it to
+// its Categories, Tags and prerequisites. Either everything is created or, if
+// any part fails, nothing is.
+func (r *CloneRepositoryMySQL) CreateCourseClone(clone CourseClone) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txCreateCourse(tx, clone.Course); err != nil {
+			e <- err
+			return
+		}
+
+		for _, quiz := range clone.Quizzes {
+			if err := r.txCreateQuiz(tx, quiz); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, assignment := range clone.Assignments {
+			if err := r.txCreateAssignment(tx, assignment); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, attachment := range clone.Attachments {
+			if err := r.txExecNamed(tx, attachmentQueries.insertAttachment, attachment); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, schedule := range clone.Cohorts {
+			if err := r.txCreateCohort(tx, schedule); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, session := range clone.LiveSessions {
+			if err := r.txExecNamed(tx, liveSessionQueries.insertLiveSession, session); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		if err := r.txLinkCourse(tx, clone); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txCreateAssignment creates an Assignment with its Rubric transactionally
+// given the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txCreateAssignment(tx *sqlx.Tx, assignment Assignment) (err error) {
+	err = r.txExecNamed(tx, assignmentQueries.insertAssignment, assignment)
+	if err != nil {
+		return
+	}
+
+	for _, criterion := range assignment.Rubric {
+		err = r.txExecNamed(tx, assignmentQueries.insertRubricCriterion, criterion)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// txCreateCohort creates a Cohort with its drip schedule transactionally given
+// the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txCreateCohort(tx *sqlx.Tx, schedule CohortSchedule) (err error) {
+	err = r.txExecNamed(tx, cohortQueries.insertCohort, schedule.Cohort)
+	if err != nil {
+		return
+	}
+
+	for _, release := range schedule.Releases {
+		err = r.txExecNamed(tx, cohortQueries.insertLessonRelease, release)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// txCreateCourse creates a Course with its Modules and Lessons transactionally
+// given the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txCreateCourse(tx *sqlx.Tx, course Course) (err error) {
+	err = r.txExecNamed(tx, courseQueries.insertCourse, course)
+	if err != nil {
+		return
+	}
+
+	for _, module := range course.Modules {
+		err = r.txExecNamed(tx, moduleQueries.insertModule, module)
+		if err != nil {
+			return
+		}
+
+		for _, lesson := range module.Lessons {
+			err = r.txExecNamed(tx, moduleQueries.insertLesson, lesson)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// txCreateQuiz creates a Quiz with its QuizQuestions and QuizOptions
+// transactionally given the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txCreateQuiz(tx *sqlx.Tx, quiz Quiz) (err error) {
+	err = r.txExecNamed(tx, quizQueries.insertQuiz, quiz)
+	if err != nil {
+		return
+	}
+
+	for _, question := range quiz.Questions {
+		err = r.txExecNamed(tx, quizQueries.insertQuizQuestionBulk+quizQueries.insertQuizQuestionBulkPlaceholder, question)
+		if err != nil {
+			return
+		}
+
+		for _, option := range question.Options {
+			err = r.txExecNamed(tx, quizQueries.insertQuizOptionBulk+quizQueries.insertQuizOptionBulkPlaceholder, option)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// txLinkCourse links a cloned Course to its Categories, Tags and prerequisites
+// transactionally given the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txLinkCourse(tx *sqlx.Tx, clone CourseClone) (err error) {
+	courseID := clone.Course.ID.String()
+	for _, categoryID := range clone.CategoryIDs {
+		_, err = tx.Exec(taxonomyQueries.insertCourseCategory, courseID, categoryID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	for _, tagID := range clone.TagIDs {
+		_, err = tx.Exec(taxonomyQueries.insertCourseTag, courseID, tagID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	for _, prerequisite := range clone.Prerequisites {
+		err = r.txExecNamed(tx, courseQueries.insertPrerequisite, prerequisite)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *CloneRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
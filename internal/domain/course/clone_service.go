@@ -0,0 +1,252 @@
+package course
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/evermos/boilerplate-go/infras/storage"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+)
+
+// CloneService is the service interface for cloning Courses.
+type CloneService interface {
+	CloneCourse(id uuid.UUID, requestFormat CourseCloneRequestFormat, userID uuid.UUID) (clone CourseClone, err error)
+}
+
+// CloneServiceImpl is the service implementation for cloning Courses.
+type CloneServiceImpl struct {
+	CloneRepository        CloneRepository
+	AssignmentRepository   AssignmentRepository
+	AttachmentRepository   AttachmentRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	LiveSessionRepository  LiveSessionRepository
+	ModuleRepository       ModuleRepository
+	QuizRepository         QuizRepository
+	TaxonomyRepository     TaxonomyRepository
+	Storage                storage.Storage
+}
+
+// ProvideCloneServiceImpl is the provider for this service.
+func ProvideCloneServiceImpl(
+	cloneRepository CloneRepository,
+	assignmentRepository AssignmentRepository,
+	attachmentRepository AttachmentRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	liveSessionRepository LiveSessionRepository,
+	moduleRepository ModuleRepository,
+	quizRepository QuizRepository,
+	taxonomyRepository TaxonomyRepository,
+	storage storage.Storage) *CloneServiceImpl {
+	s := new(CloneServiceImpl)
+	s.CloneRepository = cloneRepository
+	s.AssignmentRepository = assignmentRepository
+	s.AttachmentRepository = attachmentRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.ModuleRepository = moduleRepository
+	s.QuizRepository = quizRepository
+	s.TaxonomyRepository = taxonomyRepository
+	s.Storage = storage
+
+	return s
+}
+
+// CloneCourse copies a Course and everything teachers authored in it into a
+// new draft owned by the cloning teacher, moving its schedule by the requested
+// number of days. Enrollments, progress, attempts, submissions and the other
+// student data are never copied. The clone is created with all of its content
+// at once, or not at all.
+func (s *CloneServiceImpl) CloneCourse(id uuid.UUID, requestFormat CourseCloneRequestFormat, userID uuid.UUID) (clone CourseClone, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return clone, failure.BadRequest(err)
+	}
+
+	source, err := s.resolveCloneSource(id, userID)
+	if err != nil {
+		return
+	}
+
+	clone, err = CourseClone{}.NewCourseClone(source, requestFormat, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.copyFiles(clone)
+	if err != nil {
+		return
+	}
+
+	err = s.CloneRepository.CreateCourseClone(clone)
+	if err != nil {
+		// don't leave files behind without Attachments pointing to them
+		s.deleteFiles(clone.Attachments)
+		return
+	}
+
+	return
+}
+
+// copyFiles copies the files of the Attachments of a CourseClone in blob
+// storage. If any fails, those already copied are removed.
+func (s *CloneServiceImpl) copyFiles(clone CourseClone) (err error) {
+	for i, attachment := range clone.Attachments {
+		err = s.copyFile(clone.Files[attachment.StorageKey], attachment)
+		if err != nil {
+			s.deleteFiles(clone.Attachments[:i])
+			return
+		}
+	}
+
+	return
+}
+
+// copyFile copies the file stored under a key to that of an Attachment.
+func (s *CloneServiceImpl) copyFile(key string, attachment Attachment) (err error) {
+	rc, err := s.Storage.Open(key)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer rc.Close()
+
+	// blob storages need to seek, which downloads can't
+	content, err := ioutil.ReadAll(io.LimitReader(rc, attachment.Size))
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return s.Storage.Put(attachment.StorageKey, bytes.NewReader(content), int64(len(content)), attachment.ContentType)
+}
+
+// deleteFiles removes the files of Attachments from blob storage.
+func (s *CloneServiceImpl) deleteFiles(attachments []Attachment) {
+	for _, attachment := range attachments {
+		if err := s.Storage.Delete(attachment.StorageKey); err != nil {
+			logger.ErrorWithStack(err)
+		}
+	}
+}
+
+// resolveCloneSource resolves a Course the user may clone, with everything
+// authored in it.
+func (s *CloneServiceImpl) resolveCloneSource(id uuid.UUID, userID uuid.UUID) (source CourseCloneSource, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionCloneCourse)
+	if err != nil {
+		return
+	}
+
+	course, err = attachCourseModules(s.ModuleRepository, course)
+	if err != nil {
+		return
+	}
+
+	categories, err := s.TaxonomyRepository.ResolveCategoriesByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	tags, err := s.TaxonomyRepository.ResolveTagsByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	course.AttachTaxonomy(categories, tags)
+
+	prerequisiteIDs, err := s.CourseRepository.ResolvePrerequisiteIDs(course.ID)
+	if err != nil {
+		return
+	}
+
+	course.AttachPrerequisites(prerequisiteIDs)
+	source.Course = course
+	err = s.resolveLessonContent(&source)
+	if err != nil {
+		return
+	}
+
+	cohorts, err := s.CohortRepository.ResolveCohortsByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	for _, cohort := range cohorts {
+		releases, err := s.CohortRepository.ResolveLessonReleases(cohort.ID)
+		if err != nil {
+			return source, err
+		}
+
+		source.Cohorts = append(source.Cohorts, CohortSchedule{Cohort: cohort, Releases: releases})
+	}
+
+	source.LiveSessions, err = s.LiveSessionRepository.ResolveLiveSessionsByCourseID(course.ID)
+	return
+}
+
+// resolveLessonContent resolves the active Quizzes, with their QuizQuestions,
+// Assignments, with their Rubric, and Attachments of the Lessons of a Course.
+func (s *CloneServiceImpl) resolveLessonContent(source *CourseCloneSource) (err error) {
+	for _, module := range source.Course.Modules {
+		for _, lesson := range module.Lessons {
+			quizzes, err := s.QuizRepository.ResolveQuizzesByLessonID(lesson.ID)
+			if err != nil {
+				return err
+			}
+
+			assignments, err := s.AssignmentRepository.ResolveAssignmentsByLessonID(lesson.ID)
+			if err != nil {
+				return err
+			}
+
+			attachments, err := s.AttachmentRepository.ResolveAttachmentsByLessonID(lesson.ID)
+			if err != nil {
+				return err
+			}
+
+			source.Quizzes = append(source.Quizzes, quizzes...)
+			source.Assignments = append(source.Assignments, assignments...)
+			source.Attachments = append(source.Attachments, attachments...)
+		}
+	}
+
+	quizIDs := make([]uuid.UUID, 0, len(source.Quizzes))
+	for _, quiz := range source.Quizzes {
+		quizIDs = append(quizIDs, quiz.ID)
+	}
+
+	questions, err := s.QuizRepository.ResolveQuestionsByQuizIDs(quizIDs)
+	if err != nil {
+		return
+	}
+
+	for i := range source.Quizzes {
+		source.Quizzes[i].AttachQuestions(questions)
+	}
+
+	assignmentIDs := make([]uuid.UUID, 0, len(source.Assignments))
+	for _, assignment := range source.Assignments {
+		assignmentIDs = append(assignmentIDs, assignment.ID)
+	}
+
+	rubric, err := s.AssignmentRepository.ResolveRubricByAssignmentIDs(assignmentIDs)
+	if err != nil {
+		return
+	}
+
+	for i := range source.Assignments {
+		source.Assignments[i].AttachRubric(rubric)
+	}
+
+	return
+}
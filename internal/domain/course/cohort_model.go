@@ -0,0 +1,376 @@
+package course
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	// embeds the time zone database so cohort time zones resolve on hosts without one
+	_ "time/tzdata"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// calendarDateFormat is the format of calendar dates, such as the start and
+// end dates of a Cohort.
+const calendarDateFormat = "2006-01-02"
+
+//// Cohort
+
+// Cohort is a scheduled run of a Course for an intake of students. Its dates
+// are calendar dates in the Cohort's time zone, while the enrollment window is
+// a pair of instants.
+type Cohort struct {
+	ID                 uuid.UUID   `db:"id" validate:"required"`
+	CourseID           uuid.UUID   `db:"course_id" validate:"required"`
+	Name               string      `db:"name" validate:"required,max=100"`
+	Timezone           string      `db:"timezone" validate:"required,timezone"`
+	StartDate          time.Time   `db:"start_date" validate:"required"`
+	EndDate            time.Time   `db:"end_date" validate:"required"`
+	EnrollmentOpensAt  time.Time   `db:"enrollment_opens_at" validate:"required"`
+	EnrollmentClosesAt time.Time   `db:"enrollment_closes_at" validate:"required"`
+	CreatedAt          time.Time   `db:"created_at" validate:"required"`
+	CreatedBy          uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt          null.Time   `db:"updated_at"`
+	UpdatedBy          nuuid.NUUID `db:"updated_by"`
+	DeletedAt          null.Time   `db:"deleted_at"`
+	DeletedBy          nuuid.NUUID `db:"deleted_by"`
+	InstructorIDs      []uuid.UUID `db:"-"`
+}
+
+// EndsAt returns the instant a Cohort ends: midnight after its end date, in
+// its time zone.
+func (c *Cohort) EndsAt() time.Time {
+	return c.dayStart(c.EndDate, 1)
+}
+
+// HasInstructor checks whether a user is one of the instructors of a Cohort.
+func (c *Cohort) HasInstructor(userID uuid.UUID) bool {
+	for _, instructorID := range c.InstructorIDs {
+		if instructorID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsDeleted checks whether a Cohort is marked as deleted.
+func (c *Cohort) IsDeleted() bool {
+	return c.DeletedAt.Valid && c.DeletedBy.Valid
+}
+
+// IsEnrollmentOpen checks whether students can join a Cohort at the given time.
+func (c *Cohort) IsEnrollmentOpen(now time.Time) bool {
+	return !now.Before(c.EnrollmentOpensAt) && now.Before(c.EnrollmentClosesAt)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c Cohort) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// NewCohortFromRequestFormat creates a new Cohort of a Course from its request format.
+func (c Cohort) NewCohortFromRequestFormat(courseID uuid.UUID, req CohortRequestFormat, userID uuid.UUID) (newCohort Cohort, err error) {
+	cohortID, _ := uuid.NewV4()
+	newCohort = Cohort{
+		ID:        cohortID,
+		CourseID:  courseID,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newCohort.apply(req)
+	return
+}
+
+// ReleaseTime returns the instant a Lesson released the given number of days
+// after the start of a Cohort becomes available: midnight of that day in the
+// Cohort's time zone, daylight saving time included.
+func (c *Cohort) ReleaseTime(dayOffset int) time.Time {
+	return c.dayStart(c.StartDate, dayOffset)
+}
+
+// SoftDelete marks a Cohort as deleted.
+func (c *Cohort) SoftDelete(userID uuid.UUID) (err error) {
+	if c.IsDeleted() {
+		return failure.Conflict("softDelete", "cohort", "already marked as deleted")
+	}
+
+	c.DeletedAt = null.TimeFrom(time.Now())
+	c.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// StartsAt returns the instant a Cohort starts: midnight of its start date, in
+// its time zone.
+func (c *Cohort) StartsAt() time.Time {
+	return c.dayStart(c.StartDate, 0)
+}
+
+// ToResponseFormat converts this Cohort to its response format.
+func (c Cohort) ToResponseFormat() CohortResponseFormat {
+	resp := CohortResponseFormat{
+		ID:                 c.ID,
+		CourseID:           c.CourseID,
+		Name:               c.Name,
+		Timezone:           c.Timezone,
+		StartDate:          c.StartDate.Format(calendarDateFormat),
+		EndDate:            c.EndDate.Format(calendarDateFormat),
+		StartsAt:           c.StartsAt(),
+		EndsAt:             c.EndsAt(),
+		EnrollmentOpensAt:  c.EnrollmentOpensAt,
+		EnrollmentClosesAt: c.EnrollmentClosesAt,
+		InstructorIDs:      c.InstructorIDs,
+		CreatedAt:          c.CreatedAt,
+		CreatedBy:          c.CreatedBy,
+		UpdatedAt:          c.UpdatedAt,
+		UpdatedBy:          c.UpdatedBy.Ptr(),
+	}
+
+	if resp.InstructorIDs == nil {
+		resp.InstructorIDs = make([]uuid.UUID, 0)
+	}
+
+	return resp
+}
+
+// Update updates a Cohort.
+func (c *Cohort) Update(req CohortRequestFormat, userID uuid.UUID) (err error) {
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	return c.apply(req)
+}
+
+// Validate validates the entity.
+func (c *Cohort) Validate() (err error) {
+	validator := shared.GetValidator()
+	err = validator.Struct(c)
+	if err != nil {
+		return
+	}
+
+	if c.EndDate.Before(c.StartDate) {
+		return errors.New("endDate cannot be before startDate")
+	}
+
+	if !c.EnrollmentClosesAt.After(c.EnrollmentOpensAt) {
+		return errors.New("enrollmentClosesAt must be after enrollmentOpensAt")
+	}
+
+	if c.EnrollmentClosesAt.After(c.EndsAt()) {
+		return errors.New("enrollment must close before the cohort ends")
+	}
+
+	return
+}
+
+// apply copies a request onto a Cohort and validates the result.
+func (c *Cohort) apply(req CohortRequestFormat) (err error) {
+	c.StartDate, err = parseCalendarDate(req.StartDate)
+	if err != nil {
+		return
+	}
+
+	c.EndDate, err = parseCalendarDate(req.EndDate)
+	if err != nil {
+		return
+	}
+
+	c.Name = req.Name
+	c.Timezone = req.Timezone
+	c.EnrollmentOpensAt = req.EnrollmentOpensAt
+	c.EnrollmentClosesAt = req.EnrollmentClosesAt
+	c.InstructorIDs = uniqueUUIDs(req.InstructorIDs)
+
+	return c.Validate()
+}
+
+// dayStart returns midnight of the day a number of days after the given date,
+// in the Cohort's time zone. Only the year, month and day of date are used,
+// so it does not matter which zone the database returned it in.
+func (c *Cohort) dayStart(date time.Time, days int) time.Time {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	year, month, day := date.Date()
+	return time.Date(year, month, day+days, 0, 0, 0, 0, location)
+}
+
+// parseCalendarDate parses a calendar date. Dates are kept at noon UTC so that
+// converting them to the zone of the database connection never moves them to
+// another day.
+func parseCalendarDate(value string) (date time.Time, err error) {
+	date, err = time.Parse(calendarDateFormat, value)
+	if err != nil {
+		return
+	}
+
+	return date.Add(12 * time.Hour), nil
+}
+
+// uniqueUUIDs drops duplicates from a list of IDs, keeping their order.
+func uniqueUUIDs(ids []uuid.UUID) (unique []uuid.UUID) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return
+}
+
+// CohortRequestFormat represents a Cohort's standard formatting for JSON deserializing.
+type CohortRequestFormat struct {
+	Name               string      `json:"name" validate:"required,max=100"`
+	Timezone           string      `json:"timezone" validate:"required,timezone" example:"Asia/Jakarta"`
+	StartDate          string      `json:"startDate" validate:"required,datetime=2006-01-02" example:"2026-02-02"`
+	EndDate            string      `json:"endDate" validate:"required,datetime=2006-01-02" example:"2026-04-24"`
+	EnrollmentOpensAt  time.Time   `json:"enrollmentOpensAt" validate:"required"`
+	EnrollmentClosesAt time.Time   `json:"enrollmentClosesAt" validate:"required"`
+	InstructorIDs      []uuid.UUID `json:"instructorIDs" validate:"max=20"`
+}
+
+// CohortResponseFormat represents a Cohort's standard formatting for JSON serializing.
+type CohortResponseFormat struct {
+	ID                 uuid.UUID   `json:"id"`
+	CourseID           uuid.UUID   `json:"courseID"`
+	Name               string      `json:"name"`
+	Timezone           string      `json:"timezone"`
+	StartDate          string      `json:"startDate"`
+	EndDate            string      `json:"endDate"`
+	StartsAt           time.Time   `json:"startsAt"`
+	EndsAt             time.Time   `json:"endsAt"`
+	EnrollmentOpensAt  time.Time   `json:"enrollmentOpensAt"`
+	EnrollmentClosesAt time.Time   `json:"enrollmentClosesAt"`
+	InstructorIDs      []uuid.UUID `json:"instructorIDs"`
+	CreatedAt          time.Time   `json:"createdAt"`
+	CreatedBy          uuid.UUID   `json:"createdBy"`
+	UpdatedAt          null.Time   `json:"updatedAt"`
+	UpdatedBy          *uuid.UUID  `json:"updatedBy"`
+}
+
+// CohortAssignmentRequestFormat represents a request to move an Enrollment to
+// another Cohort of its Course, or out of any Cohort.
+type CohortAssignmentRequestFormat struct {
+	CohortID nuuid.NUUID `json:"cohortID" swaggertype:"string"`
+}
+
+//// Drip Schedule
+
+// CohortLessonRelease releases a Lesson to the students of a Cohort a number
+// of days after the Cohort starts.
+type CohortLessonRelease struct {
+	CohortID  uuid.UUID `db:"cohort_id"`
+	LessonID  uuid.UUID `db:"lesson_id"`
+	DayOffset int       `db:"day_offset"`
+}
+
+// CohortSchedule tells when each Lesson of a Course becomes available to the
+// students of a Cohort. Lessons without a release are available from the day
+// the Cohort starts.
+type CohortSchedule struct {
+	Cohort   Cohort
+	Releases []CohortLessonRelease
+}
+
+// Apply locks the Lessons of a Course that are not released at the given time.
+func (s CohortSchedule) Apply(course Course, now time.Time) Course {
+	modules := make([]Module, 0, len(course.Modules))
+	for _, module := range course.Modules {
+		lessons := make([]Lesson, 0, len(module.Lessons))
+		for _, lesson := range module.Lessons {
+			if !s.IsReleased(lesson.ID, now) {
+				lesson.Lock(s.ReleasesAt(lesson.ID))
+			}
+
+			lessons = append(lessons, lesson)
+		}
+
+		module.Lessons = lessons
+		modules = append(modules, module)
+	}
+
+	course.Modules = modules
+	return course
+}
+
+// IsReleased checks whether a Lesson is available at the given time.
+func (s CohortSchedule) IsReleased(lessonID uuid.UUID, now time.Time) bool {
+	return !now.Before(s.ReleasesAt(lessonID))
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (s CohortSchedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToResponseFormat())
+}
+
+// ReleasesAt returns the instant a Lesson becomes available.
+func (s CohortSchedule) ReleasesAt(lessonID uuid.UUID) time.Time {
+	for _, release := range s.Releases {
+		if release.LessonID == lessonID {
+			return s.Cohort.ReleaseTime(release.DayOffset)
+		}
+	}
+
+	return s.Cohort.StartsAt()
+}
+
+// ToResponseFormat converts this CohortSchedule to its response format.
+func (s CohortSchedule) ToResponseFormat() CohortScheduleResponseFormat {
+	resp := CohortScheduleResponseFormat{
+		CohortID:  s.Cohort.ID,
+		Timezone:  s.Cohort.Timezone,
+		StartDate: s.Cohort.StartDate.Format(calendarDateFormat),
+		Releases:  make([]CohortLessonReleaseResponseFormat, 0, len(s.Releases)),
+	}
+
+	for _, release := range s.Releases {
+		resp.Releases = append(resp.Releases, CohortLessonReleaseResponseFormat{
+			LessonID:   release.LessonID,
+			DayOffset:  release.DayOffset,
+			ReleasesAt: s.Cohort.ReleaseTime(release.DayOffset),
+		})
+	}
+
+	return resp
+}
+
+// CohortScheduleRequestFormat represents a request to replace the drip
+// schedule of a Cohort.
+type CohortScheduleRequestFormat struct {
+	Releases []CohortLessonReleaseRequestFormat `json:"releases" validate:"max=500,dive"`
+}
+
+// CohortLessonReleaseRequestFormat represents the release of a Lesson in a
+// drip schedule.
+type CohortLessonReleaseRequestFormat struct {
+	LessonID  uuid.UUID `json:"lessonID" validate:"required"`
+	DayOffset int       `json:"dayOffset" validate:"min=0,max=3650"`
+}
+
+// CohortScheduleResponseFormat represents a CohortSchedule's standard formatting for JSON serializing.
+type CohortScheduleResponseFormat struct {
+	CohortID  uuid.UUID                           `json:"cohortID"`
+	Timezone  string                              `json:"timezone"`
+	StartDate string                              `json:"startDate"`
+	Releases  []CohortLessonReleaseResponseFormat `json:"releases"`
+}
+
+// CohortLessonReleaseResponseFormat represents the release of a Lesson in a
+// drip schedule.
+type CohortLessonReleaseResponseFormat struct {
+	LessonID   uuid.UUID `json:"lessonID"`
+	DayOffset  int       `json:"dayOffset"`
+	ReleasesAt time.Time `json:"releasesAt"`
+}
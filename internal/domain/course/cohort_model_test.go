@@ -0,0 +1,113 @@
+package course_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCohortRequest() course.CohortRequestFormat {
+	return course.CohortRequestFormat{
+		Name:               "Spring intake",
+		Timezone:           "America/New_York",
+		StartDate:          "2026-03-02",
+		EndDate:            "2026-04-24",
+		EnrollmentOpensAt:  time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		EnrollmentClosesAt: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCohort(t *testing.T) {
+	courseID, userID := getRandomUUID(), getRandomUUID()
+
+	t.Run("validate", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			modify  func(req *course.CohortRequestFormat)
+			wantErr bool
+		}{
+			{name: "valid", modify: func(req *course.CohortRequestFormat) {}},
+			{name: "unknown time zone", modify: func(req *course.CohortRequestFormat) { req.Timezone = "Mars/Olympus_Mons" }, wantErr: true},
+			{name: "malformed date", modify: func(req *course.CohortRequestFormat) { req.StartDate = "03/02/2026" }, wantErr: true},
+			{name: "ends before it starts", modify: func(req *course.CohortRequestFormat) { req.EndDate = "2026-03-01" }, wantErr: true},
+			{name: "enrollment closes before it opens", modify: func(req *course.CohortRequestFormat) {
+				req.EnrollmentClosesAt = req.EnrollmentOpensAt
+			}, wantErr: true},
+			{name: "enrollment closes after the cohort ends", modify: func(req *course.CohortRequestFormat) {
+				req.EnrollmentClosesAt = time.Date(2026, 4, 25, 12, 0, 0, 0, time.UTC)
+			}, wantErr: true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				req := newCohortRequest()
+				test.modify(&req)
+
+				cohort, err := course.Cohort{}.NewCohortFromRequestFormat(courseID, req, userID)
+
+				if test.wantErr {
+					assert.Error(t, err)
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, courseID, cohort.CourseID)
+			})
+		}
+	})
+
+	t.Run("release times stay at local midnight across daylight saving time", func(t *testing.T) {
+		cohort, err := course.Cohort{}.NewCohortFromRequestFormat(courseID, newCohortRequest(), userID)
+		assert.NoError(t, err)
+
+		// New York moves from EST (UTC-5) to EDT (UTC-4) on 2026-03-08
+		assert.Equal(t, time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC), cohort.StartsAt().UTC())
+		assert.Equal(t, time.Date(2026, 3, 7, 5, 0, 0, 0, time.UTC), cohort.ReleaseTime(5).UTC())
+		assert.Equal(t, time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC), cohort.ReleaseTime(7).UTC())
+		assert.Equal(t, time.Date(2026, 4, 25, 4, 0, 0, 0, time.UTC), cohort.EndsAt().UTC())
+	})
+
+	t.Run("enrollment window", func(t *testing.T) {
+		cohort, err := course.Cohort{}.NewCohortFromRequestFormat(courseID, newCohortRequest(), userID)
+		assert.NoError(t, err)
+
+		assert.False(t, cohort.IsEnrollmentOpen(cohort.EnrollmentOpensAt.Add(-time.Second)))
+		assert.True(t, cohort.IsEnrollmentOpen(cohort.EnrollmentOpensAt))
+		assert.False(t, cohort.IsEnrollmentOpen(cohort.EnrollmentClosesAt))
+	})
+}
+
+func TestCohortSchedule(t *testing.T) {
+	cohort, err := course.Cohort{}.NewCohortFromRequestFormat(getRandomUUID(), newCohortRequest(), getRandomUUID())
+	assert.NoError(t, err)
+
+	intro := course.Lesson{ID: getRandomUUID(), Title: "Intro", Content: "Welcome"}
+	joins := course.Lesson{ID: getRandomUUID(), Title: "Joins", Content: "INNER JOIN"}
+	schedule := course.CohortSchedule{
+		Cohort:   cohort,
+		Releases: []course.CohortLessonRelease{{CohortID: cohort.ID, LessonID: joins.ID, DayOffset: 7}},
+	}
+	fullCourse := course.Course{Modules: []course.Module{{Lessons: []course.Lesson{intro, joins}}}}
+
+	t.Run("unscheduled lessons are released when the cohort starts", func(t *testing.T) {
+		assert.Equal(t, cohort.StartsAt(), schedule.ReleasesAt(intro.ID))
+		assert.False(t, schedule.IsReleased(intro.ID, cohort.StartsAt().Add(-time.Second)))
+		assert.True(t, schedule.IsReleased(intro.ID, cohort.StartsAt()))
+	})
+
+	t.Run("apply locks lessons that are not released yet", func(t *testing.T) {
+		now := cohort.ReleaseTime(6)
+
+		got := schedule.Apply(fullCourse, now)
+
+		lessons := got.Modules[0].Lessons
+		assert.False(t, lessons[0].IsLocked())
+		assert.Equal(t, "Welcome", lessons[0].Content)
+		assert.True(t, lessons[1].IsLocked())
+		assert.Empty(t, lessons[1].Content)
+		assert.Equal(t, cohort.ReleaseTime(7), lessons[1].ReleasesAt.Time)
+		assert.Equal(t, "INNER JOIN", fullCourse.Modules[0].Lessons[1].Content)
+	})
+}
@@ -0,0 +1,345 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source cohort_repository.go -destination mock/cohort_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	cohortQueries = struct {
+		selectCohort                 string
+		selectInstructors            string
+		selectLessonReleases         string
+		insertCohort                 string
+		insertInstructor             string
+		insertLessonRelease          string
+		updateCohort                 string
+		deleteInstructorsByCohort    string
+		deleteLessonReleasesByCohort string
+	}{
+		selectCohort: `
+			SELECT
+				id,
+				course_id,
+				name,
+				timezone,
+				start_date,
+				end_date,
+				enrollment_opens_at,
+				enrollment_closes_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM cohorts
+		`,
+
+		selectInstructors: `
+			SELECT
+				cohort_id,
+				instructor_id
+			FROM cohort_instructors
+			WHERE cohort_id IN (?)
+		`,
+
+		selectLessonReleases: `
+			SELECT
+				r.cohort_id,
+				r.lesson_id,
+				r.day_offset
+			FROM cohort_lesson_releases r
+			JOIN lessons l ON l.id = r.lesson_id AND l.deleted_at IS NULL
+			WHERE r.cohort_id = ?
+			ORDER BY r.day_offset
+		`,
+
+		insertCohort: `
+			INSERT INTO cohorts (
+				id,
+				course_id,
+				name,
+				timezone,
+				start_date,
+				end_date,
+				enrollment_opens_at,
+				enrollment_closes_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:name,
+				:timezone,
+				:start_date,
+				:end_date,
+				:enrollment_opens_at,
+				:enrollment_closes_at,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		insertInstructor: `
+			INSERT INTO cohort_instructors (
+				cohort_id,
+				instructor_id
+			) VALUES (?, ?)
+		`,
+
+		insertLessonRelease: `
+			INSERT INTO cohort_lesson_releases (
+				cohort_id,
+				lesson_id,
+				day_offset
+			) VALUES (
+				:cohort_id,
+				:lesson_id,
+				:day_offset
+			)
+		`,
+
+		updateCohort: `
+			UPDATE cohorts
+			SET
+				name = :name,
+				timezone = :timezone,
+				start_date = :start_date,
+				end_date = :end_date,
+				enrollment_opens_at = :enrollment_opens_at,
+				enrollment_closes_at = :enrollment_closes_at,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		deleteInstructorsByCohort: `
+			DELETE FROM cohort_instructors
+			WHERE cohort_id = ?
+		`,
+
+		deleteLessonReleasesByCohort: `
+			DELETE FROM cohort_lesson_releases
+			WHERE cohort_id = ?
+		`,
+	}
+)
+
+// CohortRepository is the repository for Cohort data.
+type CohortRepository interface {
+	CreateCohort(cohort Cohort) (err error)
+	ResolveCohortByID(id uuid.UUID) (cohort Cohort, err error)
+	ResolveCohortsByCourseID(courseID uuid.UUID) (cohorts []Cohort, err error)
+	ResolveLessonReleases(cohortID uuid.UUID) (releases []CohortLessonRelease, err error)
+	SetLessonReleases(cohortID uuid.UUID, releases []CohortLessonRelease) (err error)
+	UpdateCohort(cohort Cohort) (err error)
+}
+
+// CohortRepositoryMySQL is the MySQL-backed implementation of CohortRepository.
+type CohortRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideCohortRepositoryMySQL is the provider for this repository.
+func ProvideCohortRepositoryMySQL(db *infras.MySQLConn) *CohortRepositoryMySQL {
+	s := new(CohortRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCohort creates a new Cohort with its instructors.
+func (r *CohortRepositoryMySQL) CreateCohort(cohort Cohort) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, cohortQueries.insertCohort, cohort); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txInsertInstructors(tx, cohort); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveCohortByID resolves a Cohort with its instructors.
+func (r *CohortRepositoryMySQL) ResolveCohortByID(id uuid.UUID) (cohort Cohort, err error) {
+	err = r.DB.Read.Get(&cohort, cohortQueries.selectCohort+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("cohort")
+		}
+
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	cohorts, err := r.attachInstructors([]Cohort{cohort})
+	if err != nil {
+		return
+	}
+
+	return cohorts[0], nil
+}
+
+// ResolveCohortsByCourseID resolves the Cohorts of a Course that are not
+// deleted, in the order they start.
+func (r *CohortRepositoryMySQL) ResolveCohortsByCourseID(courseID uuid.UUID) (cohorts []Cohort, err error) {
+	err = r.DB.Read.Select(
+		&cohorts,
+		cohortQueries.selectCohort+" WHERE course_id = ? AND deleted_at IS NULL ORDER BY start_date, name",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return r.attachInstructors(cohorts)
+}
+
+// ResolveLessonReleases resolves the drip schedule of a Cohort, leaving out
+// deleted Lessons.
+func (r *CohortRepositoryMySQL) ResolveLessonReleases(cohortID uuid.UUID) (releases []CohortLessonRelease, err error) {
+	err = r.DB.Read.Select(&releases, cohortQueries.selectLessonReleases, cohortID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SetLessonReleases replaces the drip schedule of a Cohort.
+func (r *CohortRepositoryMySQL) SetLessonReleases(cohortID uuid.UUID, releases []CohortLessonRelease) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		_, err := tx.Exec(cohortQueries.deleteLessonReleasesByCohort, cohortID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		for _, release := range releases {
+			if err := r.txExecNamed(tx, cohortQueries.insertLessonRelease, release); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateCohort updates a Cohort and replaces its instructors.
+func (r *CohortRepositoryMySQL) UpdateCohort(cohort Cohort) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, cohortQueries.updateCohort, cohort); err != nil {
+			e <- err
+			return
+		}
+
+		_, err := tx.Exec(cohortQueries.deleteInstructorsByCohort, cohort.ID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txInsertInstructors(tx, cohort); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// attachInstructors attaches the instructor IDs of each Cohort.
+func (r *CohortRepositoryMySQL) attachInstructors(cohorts []Cohort) ([]Cohort, error) {
+	if len(cohorts) == 0 {
+		return cohorts, nil
+	}
+
+	cohortIDs := make([]uuid.UUID, 0, len(cohorts))
+	for _, cohort := range cohorts {
+		cohortIDs = append(cohortIDs, cohort.ID)
+	}
+
+	query, args, err := sqlx.In(cohortQueries.selectInstructors, cohortIDs)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return cohorts, err
+	}
+
+	var instructors []struct {
+		CohortID     uuid.UUID `db:"cohort_id"`
+		InstructorID uuid.UUID `db:"instructor_id"`
+	}
+	err = r.DB.Read.Select(&instructors, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return cohorts, err
+	}
+
+	for i := range cohorts {
+		for _, instructor := range instructors {
+			if instructor.CohortID == cohorts[i].ID {
+				cohorts[i].InstructorIDs = append(cohorts[i].InstructorIDs, instructor.InstructorID)
+			}
+		}
+	}
+
+	return cohorts, nil
+}
+
+// txInsertInstructors stores the instructors of a Cohort.
+func (r *CohortRepositoryMySQL) txInsertInstructors(tx *sqlx.Tx, cohort Cohort) (err error) {
+	for _, instructorID := range cohort.InstructorIDs {
+		_, err = tx.Exec(cohortQueries.insertInstructor, cohort.ID.String(), instructorID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *CohortRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,314 @@
+package course
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+)
+
+// CohortService is the service interface for Cohorts and their drip schedules.
+type CohortService interface {
+	CreateCohort(courseID uuid.UUID, requestFormat CohortRequestFormat, userID uuid.UUID) (cohort Cohort, err error)
+	DeleteCohort(id uuid.UUID, userID uuid.UUID) (cohort Cohort, err error)
+	ResolveCohortByID(id uuid.UUID, role string) (cohort Cohort, err error)
+	ResolveCohortRoster(id uuid.UUID, userID uuid.UUID) (roster []Enrollment, err error)
+	ResolveCohortSchedule(id uuid.UUID, userID uuid.UUID, role string) (schedule CohortSchedule, err error)
+	ResolveCohortsByCourseID(courseID uuid.UUID, role string) (cohorts []Cohort, err error)
+	SetCohortSchedule(id uuid.UUID, requestFormat CohortScheduleRequestFormat, userID uuid.UUID) (schedule CohortSchedule, err error)
+	UpdateCohort(id uuid.UUID, requestFormat CohortRequestFormat, userID uuid.UUID) (cohort Cohort, err error)
+}
+
+// CohortServiceImpl is the service implementation for Cohorts and their drip schedules.
+type CohortServiceImpl struct {
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	Config                 *configs.Config
+}
+
+// ProvideCohortServiceImpl is the provider for this service.
+func ProvideCohortServiceImpl(
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	moduleRepository ModuleRepository,
+	config *configs.Config) *CohortServiceImpl {
+	s := new(CohortServiceImpl)
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.Config = config
+
+	return s
+}
+
+// CreateCohort schedules a new run of a Course whose schedule the given user
+// manages.
+func (s *CohortServiceImpl) CreateCohort(courseID uuid.UUID, requestFormat CohortRequestFormat, userID uuid.UUID) (cohort Cohort, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionManageSchedule)
+	if err != nil {
+		return
+	}
+
+	cohort, err = Cohort{}.NewCohortFromRequestFormat(course.ID, requestFormat, userID)
+	if err != nil {
+		return cohort, failure.BadRequest(err)
+	}
+
+	err = s.CohortRepository.CreateCohort(cohort)
+	return
+}
+
+// DeleteCohort marks a Cohort as deleted.
+// Its students stay enrolled in the Course, without a drip schedule.
+func (s *CohortServiceImpl) DeleteCohort(id uuid.UUID, userID uuid.UUID) (cohort Cohort, err error) {
+	cohort, err = s.resolveManagedCohort(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = cohort.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.CohortRepository.UpdateCohort(cohort)
+	return
+}
+
+// ResolveCohortByID resolves a Cohort. Students only see the Cohorts of
+// Courses visible to them.
+func (s *CohortServiceImpl) ResolveCohortByID(id uuid.UUID, role string) (cohort Cohort, err error) {
+	cohort, err = resolveCohort(s.CohortRepository, id)
+	if err != nil {
+		return
+	}
+
+	err = s.checkCourseVisible(cohort.CourseID, role)
+	return
+}
+
+// ResolveCohortRoster resolves the Enrollments in a Cohort for those allowed
+// to view the students of its Course or one of its instructors.
+func (s *CohortServiceImpl) ResolveCohortRoster(id uuid.UUID, userID uuid.UUID) (roster []Enrollment, err error) {
+	cohort, err := s.resolveInstructedCohort(id, userID)
+	if err != nil {
+		return
+	}
+
+	return s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: cohort.CourseID,
+		CohortID: nuuid.From(cohort.ID),
+	})
+}
+
+// ResolveCohortSchedule resolves the drip schedule of a Cohort for teachers
+// and the students enrolled in it.
+func (s *CohortServiceImpl) ResolveCohortSchedule(id uuid.UUID, userID uuid.UUID, role string) (schedule CohortSchedule, err error) {
+	cohort, err := resolveCohort(s.CohortRepository, id)
+	if err != nil {
+		return
+	}
+
+	if role != shared.RoleTeacher {
+		enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(cohort.CourseID, userID)
+		if err != nil || enrollment.CohortID.UUID != cohort.ID || !enrollment.CohortID.Valid {
+			return schedule, failure.NotFound("cohort")
+		}
+	}
+
+	releases, err := s.CohortRepository.ResolveLessonReleases(cohort.ID)
+	if err != nil {
+		return
+	}
+
+	return CohortSchedule{Cohort: cohort, Releases: releases}, nil
+}
+
+// ResolveCohortsByCourseID resolves the Cohorts of a Course in the order they
+// start. Students only see the Cohorts of Courses visible to them.
+func (s *CohortServiceImpl) ResolveCohortsByCourseID(courseID uuid.UUID, role string) (cohorts []Cohort, err error) {
+	err = s.checkCourseVisible(courseID, role)
+	if err != nil {
+		return
+	}
+
+	return s.CohortRepository.ResolveCohortsByCourseID(courseID)
+}
+
+// SetCohortSchedule replaces the drip schedule of a Cohort. Every Lesson must belong to the Course and appear once.
+func (s *CohortServiceImpl) SetCohortSchedule(id uuid.UUID, requestFormat CohortScheduleRequestFormat, userID uuid.UUID) (schedule CohortSchedule, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return schedule, failure.BadRequest(err)
+	}
+
+	cohort, err := s.resolveManagedCohort(id, userID)
+	if err != nil {
+		return
+	}
+
+	course, err := attachCourseModules(s.ModuleRepository, Course{ID: cohort.CourseID})
+	if err != nil {
+		return
+	}
+
+	courseLessons := make(map[uuid.UUID]bool)
+	for _, module := range course.Modules {
+		for _, lesson := range module.Lessons {
+			courseLessons[lesson.ID] = true
+		}
+	}
+
+	releases := make([]CohortLessonRelease, 0, len(requestFormat.Releases))
+	scheduled := make(map[uuid.UUID]bool)
+	for _, release := range requestFormat.Releases {
+		if !courseLessons[release.LessonID] {
+			return schedule, failure.NotFound("lesson")
+		}
+
+		if scheduled[release.LessonID] {
+			return schedule, failure.BadRequestFromString(fmt.Sprintf("lesson %s is scheduled more than once", release.LessonID))
+		}
+
+		scheduled[release.LessonID] = true
+		releases = append(releases, CohortLessonRelease{
+			CohortID:  cohort.ID,
+			LessonID:  release.LessonID,
+			DayOffset: release.DayOffset,
+		})
+	}
+
+	err = s.CohortRepository.SetLessonReleases(cohort.ID, releases)
+	if err != nil {
+		return
+	}
+
+	return CohortSchedule{Cohort: cohort, Releases: releases}, nil
+}
+
+// UpdateCohort updates a Cohort.
+func (s *CohortServiceImpl) UpdateCohort(id uuid.UUID, requestFormat CohortRequestFormat, userID uuid.UUID) (cohort Cohort, err error) {
+	cohort, err = s.resolveManagedCohort(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = cohort.Update(requestFormat, userID)
+	if err != nil {
+		return cohort, failure.BadRequest(err)
+	}
+
+	err = s.CohortRepository.UpdateCohort(cohort)
+	return
+}
+
+// checkCourseVisible makes sure a user may see the Cohorts of a Course.
+func (s *CohortServiceImpl) checkCourseVisible(courseID uuid.UUID, role string) (err error) {
+	course, err := s.CourseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || (role != shared.RoleTeacher && !course.IsVisibleToStudents()) {
+		return failure.NotFound("course")
+	}
+
+	return
+}
+
+// resolveInstructedCohort resolves a Cohort for those allowed to view the
+// students of its Course or one of its instructors.
+func (s *CohortServiceImpl) resolveInstructedCohort(id uuid.UUID, userID uuid.UUID) (cohort Cohort, err error) {
+	cohort, err = resolveCohort(s.CohortRepository, id)
+	if err != nil || cohort.HasInstructor(userID) {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, cohort.CourseID, userID, PermissionViewStudents)
+	return
+}
+
+// resolveManagedCohort resolves a Cohort of a Course whose schedule the given
+// user manages.
+func (s *CohortServiceImpl) resolveManagedCohort(id uuid.UUID, userID uuid.UUID) (cohort Cohort, err error) {
+	cohort, err = resolveCohort(s.CohortRepository, id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, cohort.CourseID, userID, PermissionManageSchedule)
+	return
+}
+
+// resolveCohort resolves a Cohort that is not deleted.
+func resolveCohort(cohortRepository CohortRepository, id uuid.UUID) (cohort Cohort, err error) {
+	cohort, err = cohortRepository.ResolveCohortByID(id)
+	if err != nil {
+		return
+	}
+
+	if cohort.IsDeleted() {
+		return cohort, failure.NotFound("cohort")
+	}
+
+	return
+}
+
+// resolveStudentSchedule resolves the drip schedule a student follows in a
+// Course. Students outside of any Cohort have none.
+func resolveStudentSchedule(enrollmentRepository EnrollmentRepository, cohortRepository CohortRepository, courseID uuid.UUID, studentID uuid.UUID) (schedule CohortSchedule, ok bool, err error) {
+	enrollment, err := enrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil || !enrollment.CohortID.Valid {
+		return
+	}
+
+	cohort, err := cohortRepository.ResolveCohortByID(enrollment.CohortID.UUID)
+	if err != nil || cohort.IsDeleted() {
+		return
+	}
+
+	releases, err := cohortRepository.ResolveLessonReleases(cohort.ID)
+	if err != nil {
+		return
+	}
+
+	return CohortSchedule{Cohort: cohort, Releases: releases}, true, nil
+}
+
+// checkLessonReadable makes sure a user may read a Lesson. Teachers may,
+// students need an active Enrollment in its Course and the Lesson released
+// by their Cohort's drip schedule.
+func checkLessonReadable(enrollmentRepository EnrollmentRepository, cohortRepository CohortRepository, lesson Lesson, userID uuid.UUID, role string) (err error) {
+	err = checkReadAccess(enrollmentRepository, lesson.CourseID, userID, role)
+	if err != nil || role == shared.RoleTeacher {
+		return
+	}
+
+	return checkLessonReleased(enrollmentRepository, cohortRepository, lesson, userID)
+}
+
+// checkLessonReleased makes sure a Lesson was released to a student by the
+// drip schedule of their Cohort.
+func checkLessonReleased(enrollmentRepository EnrollmentRepository, cohortRepository CohortRepository, lesson Lesson, studentID uuid.UUID) (err error) {
+	schedule, ok, err := resolveStudentSchedule(enrollmentRepository, cohortRepository, lesson.CourseID, studentID)
+	if err != nil || !ok {
+		return
+	}
+
+	if !schedule.IsReleased(lesson.ID, time.Now()) {
+		return failure.Forbidden(fmt.Sprintf("this lesson is released at %s", schedule.ReleasesAt(lesson.ID).Format(time.RFC3339)))
+	}
+
+	return
+}
@@ -0,0 +1,327 @@
+package course
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+var (
+	errCollaboratorExists = failure.Conflict("invite", "collaborator", "the user already collaborates on or is invited to this course")
+	errNoPendingInvite    = failure.Conflict("accept", "collaborator", "there is no pending invitation to accept")
+)
+
+//// Course Roles
+
+// CourseRole is the role a user has on a single Course.
+type CourseRole string
+
+const (
+	// CourseRoleOwner is the role of the user a Course belongs to. There is
+	// exactly one owner, kept in Course.UserID rather than as a Collaborator.
+	CourseRoleOwner CourseRole = "owner"
+	// CourseRoleCoInstructor can do everything the owner can, except managing
+	// collaborators, deleting the Course and handing it over.
+	CourseRoleCoInstructor CourseRole = "co_instructor"
+	// CourseRoleTeachingAssistant helps running a Course: it can look after
+	// students, grade their work and take attendance.
+	CourseRoleTeachingAssistant CourseRole = "teaching_assistant"
+)
+
+// CoursePermission is something a CourseRole allows doing on a Course.
+type CoursePermission string
+
+const (
+	// PermissionEditCourse allows changing a Course's details, taxonomy,
+	// prerequisites and completion rules.
+	PermissionEditCourse CoursePermission = "course.edit"
+	// PermissionEditContent allows authoring Modules, Lessons, Quizzes and
+	// Assignments.
+	PermissionEditContent CoursePermission = "content.edit"
+	// PermissionPublishCourse allows moving a Course through review,
+	// publishing and rolling it back.
+	PermissionPublishCourse CoursePermission = "course.publish"
+	// PermissionManageEnrollments allows approving and removing Enrollments
+	// and moving students between Cohorts.
+	PermissionManageEnrollments CoursePermission = "enrollments.manage"
+	// PermissionManageSchedule allows managing Cohorts and LiveSessions.
+	PermissionManageSchedule CoursePermission = "schedule.manage"
+	// PermissionViewStudents allows reading rosters, progress, attendance
+	// and certificates of the students.
+	PermissionViewStudents CoursePermission = "students.view"
+	// PermissionGradeSubmissions allows reading and grading Submissions.
+	PermissionGradeSubmissions CoursePermission = "submissions.grade"
+	// PermissionTakeAttendance allows marking attendance and opening
+	// check-ins.
+	PermissionTakeAttendance CoursePermission = "attendance.take"
+	// PermissionManageCertificates allows revoking Certificates.
+	PermissionManageCertificates CoursePermission = "certificates.manage"
+	// PermissionManageCollaborators allows inviting, changing and removing
+	// Collaborators.
+	PermissionManageCollaborators CoursePermission = "collaborators.manage"
+	// PermissionDeleteCourse allows deleting a Course.
+	PermissionDeleteCourse CoursePermission = "course.delete"
+	// PermissionTransferCourse allows handing a Course over to another owner.
+	PermissionTransferCourse CoursePermission = "course.transfer"
+	// PermissionReplyReviews allows replying to Reviews and dismissing their
+	// flags.
+	PermissionReplyReviews CoursePermission = "reviews.reply"
+	// PermissionModerateDiscussions allows pinning, locking, hiding and
+	// deleting discussion Threads and Posts.
+	PermissionModerateDiscussions CoursePermission = "discussions.moderate"
+	// PermissionPostAnnouncements allows posting and deleting Announcements.
+	PermissionPostAnnouncements CoursePermission = "announcements.post"
+	// PermissionExportCourse allows exporting a Course, answer keys included,
+	// as an archive.
+	PermissionExportCourse CoursePermission = "course.export"
+	// PermissionCloneCourse allows copying a Course, answer keys included,
+	// into a new draft.
+	PermissionCloneCourse CoursePermission = "course.clone"
+)
+
+// coursePermissions lists the CoursePermissions of each CourseRole.
+var coursePermissions = map[CourseRole][]CoursePermission{
+	CourseRoleOwner: {
+		PermissionEditCourse,
+		PermissionEditContent,
+		PermissionPublishCourse,
+		PermissionManageEnrollments,
+		PermissionManageSchedule,
+		PermissionViewStudents,
+		PermissionGradeSubmissions,
+		PermissionTakeAttendance,
+		PermissionManageCertificates,
+		PermissionReplyReviews,
+		PermissionModerateDiscussions,
+		PermissionPostAnnouncements,
+		PermissionExportCourse,
+		PermissionCloneCourse,
+		PermissionManageCollaborators,
+		PermissionDeleteCourse,
+		PermissionTransferCourse,
+	},
+	CourseRoleCoInstructor: {
+		PermissionEditCourse,
+		PermissionEditContent,
+		PermissionPublishCourse,
+		PermissionManageEnrollments,
+		PermissionManageSchedule,
+		PermissionViewStudents,
+		PermissionGradeSubmissions,
+		PermissionTakeAttendance,
+		PermissionManageCertificates,
+		PermissionReplyReviews,
+		PermissionModerateDiscussions,
+		PermissionPostAnnouncements,
+		PermissionExportCourse,
+		PermissionCloneCourse,
+	},
+	CourseRoleTeachingAssistant: {
+		PermissionViewStudents,
+		PermissionGradeSubmissions,
+		PermissionTakeAttendance,
+		PermissionModerateDiscussions,
+	},
+}
+
+// Can checks whether a CourseRole has a CoursePermission.
+func (r CourseRole) Can(permission CoursePermission) bool {
+	for _, granted := range coursePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Permissions returns the CoursePermissions of a CourseRole.
+func (r CourseRole) Permissions() []CoursePermission {
+	return coursePermissions[r]
+}
+
+//// Collaborators
+
+// CollaboratorStatus indicates the status of a Collaborator.
+type CollaboratorStatus string
+
+const (
+	// CollaboratorStatusInvited indicates a Collaborator who has not accepted
+	// the invitation yet and has no permissions.
+	CollaboratorStatusInvited CollaboratorStatus = "invited"
+	// CollaboratorStatusActive indicates a Collaborator who accepted the
+	// invitation.
+	CollaboratorStatusActive CollaboratorStatus = "active"
+	// CollaboratorStatusRemoved indicates a Collaborator who was removed or
+	// left. They can be invited again.
+	CollaboratorStatusRemoved CollaboratorStatus = "removed"
+)
+
+// Collaborator gives a user other than the owner a CourseRole on a Course.
+type Collaborator struct {
+	CourseID   uuid.UUID          `db:"course_id" validate:"required"`
+	UserID     uuid.UUID          `db:"user_id" validate:"required"`
+	Role       CourseRole         `db:"role" validate:"required,oneof=co_instructor teaching_assistant"`
+	Status     CollaboratorStatus `db:"status" validate:"required,oneof=invited active removed"`
+	InvitedAt  time.Time          `db:"invited_at" validate:"required"`
+	InvitedBy  uuid.UUID          `db:"invited_by" validate:"required"`
+	AcceptedAt null.Time          `db:"accepted_at"`
+	UpdatedAt  null.Time          `db:"updated_at"`
+	UpdatedBy  nuuid.NUUID        `db:"updated_by"`
+}
+
+// NewInvitation creates an invitation to collaborate on a Course. A previous
+// Collaborator record of the user is reused only if they were removed.
+func (c Collaborator) NewInvitation(courseID uuid.UUID, previous *Collaborator, req CollaboratorRequestFormat, userID uuid.UUID) (invitation Collaborator, err error) {
+	if previous != nil && previous.Status != CollaboratorStatusRemoved {
+		return *previous, errCollaboratorExists
+	}
+
+	invitation = Collaborator{
+		CourseID:  courseID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+		Status:    CollaboratorStatusInvited,
+		InvitedAt: time.Now(),
+		InvitedBy: userID,
+	}
+
+	if previous != nil {
+		invitation.UpdatedAt = null.TimeFrom(invitation.InvitedAt)
+		invitation.UpdatedBy = nuuid.From(userID)
+	}
+
+	err = invitation.Validate()
+	return
+}
+
+// Accept makes an invited Collaborator active.
+func (c *Collaborator) Accept() (err error) {
+	if c.Status != CollaboratorStatusInvited {
+		return errNoPendingInvite
+	}
+
+	now := time.Now()
+	c.Status = CollaboratorStatusActive
+	c.AcceptedAt = null.TimeFrom(now)
+	c.UpdatedAt = null.TimeFrom(now)
+	c.UpdatedBy = nuuid.From(c.UserID)
+
+	return
+}
+
+// ChangeRole gives a Collaborator another CourseRole.
+func (c *Collaborator) ChangeRole(req CollaboratorRoleRequestFormat, userID uuid.UUID) (err error) {
+	c.Role = req.Role
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	return c.Validate()
+}
+
+// Remove removes a Collaborator, or withdraws their invitation.
+func (c *Collaborator) Remove(userID uuid.UUID) (err error) {
+	if c.Status == CollaboratorStatusRemoved {
+		return failure.Conflict("remove", "collaborator", "already removed")
+	}
+
+	c.Status = CollaboratorStatusRemoved
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	return
+}
+
+// IsActive checks whether a Collaborator has accepted and was not removed
+// since.
+func (c *Collaborator) IsActive() bool {
+	return c.Status == CollaboratorStatusActive
+}
+
+// Can checks whether a Collaborator may currently do something on the Course.
+func (c *Collaborator) Can(permission CoursePermission) bool {
+	return c.IsActive() && c.Role.Can(permission)
+}
+
+// Validate validates the entity.
+func (c *Collaborator) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(c)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c Collaborator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// ToResponseFormat converts this Collaborator to its response format.
+func (c Collaborator) ToResponseFormat() CollaboratorResponseFormat {
+	permissions := []CoursePermission{}
+	if c.IsActive() {
+		permissions = c.Role.Permissions()
+	}
+
+	return CollaboratorResponseFormat{
+		CourseID:    c.CourseID,
+		UserID:      c.UserID,
+		Role:        c.Role,
+		Status:      c.Status,
+		Permissions: permissions,
+		InvitedAt:   c.InvitedAt,
+		InvitedBy:   c.InvitedBy,
+		AcceptedAt:  c.AcceptedAt,
+		UpdatedAt:   c.UpdatedAt,
+		UpdatedBy:   c.UpdatedBy.Ptr(),
+	}
+}
+
+// NewOwnerCollaborator describes the owner of a Course the way Collaborators
+// are, so that listings include them.
+func NewOwnerCollaborator(course Course) Collaborator {
+	return Collaborator{
+		CourseID:   course.ID,
+		UserID:     course.UserID,
+		Role:       CourseRoleOwner,
+		Status:     CollaboratorStatusActive,
+		InvitedAt:  course.CreatedAt,
+		InvitedBy:  course.CreatedBy,
+		AcceptedAt: null.TimeFrom(course.CreatedAt),
+		UpdatedAt:  course.UpdatedAt,
+		UpdatedBy:  course.UpdatedBy,
+	}
+}
+
+// CollaboratorRequestFormat represents an invitation's standard formatting for JSON deserializing.
+type CollaboratorRequestFormat struct {
+	UserID uuid.UUID  `json:"userID" validate:"required"`
+	Role   CourseRole `json:"role" validate:"required,oneof=co_instructor teaching_assistant" example:"teaching_assistant"`
+}
+
+// CollaboratorRoleRequestFormat represents a role change's standard formatting for JSON deserializing.
+type CollaboratorRoleRequestFormat struct {
+	Role CourseRole `json:"role" validate:"required,oneof=co_instructor teaching_assistant" example:"co_instructor"`
+}
+
+// OwnershipTransferRequestFormat represents an ownership transfer's standard formatting for JSON deserializing.
+type OwnershipTransferRequestFormat struct {
+	UserID uuid.UUID `json:"userID" validate:"required"`
+}
+
+// CollaboratorResponseFormat represents a Collaborator's standard formatting for JSON serializing.
+type CollaboratorResponseFormat struct {
+	CourseID    uuid.UUID          `json:"courseID"`
+	UserID      uuid.UUID          `json:"userID"`
+	Role        CourseRole         `json:"role"`
+	Status      CollaboratorStatus `json:"status"`
+	Permissions []CoursePermission `json:"permissions"`
+	InvitedAt   time.Time          `json:"invitedAt"`
+	InvitedBy   uuid.UUID          `json:"invitedBy"`
+	AcceptedAt  null.Time          `json:"acceptedAt"`
+	UpdatedAt   null.Time          `json:"updatedAt"`
+	UpdatedBy   *uuid.UUID         `json:"updatedBy"`
+}
@@ -0,0 +1,59 @@
+package course_test
+
+import (
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCourseRolePermissions(t *testing.T) {
+	assert.True(t, course.CourseRoleOwner.Can(course.PermissionTransferCourse))
+	assert.True(t, course.CourseRoleCoInstructor.Can(course.PermissionEditContent))
+	assert.False(t, course.CourseRoleCoInstructor.Can(course.PermissionManageCollaborators))
+	assert.False(t, course.CourseRoleCoInstructor.Can(course.PermissionDeleteCourse))
+	assert.True(t, course.CourseRoleTeachingAssistant.Can(course.PermissionGradeSubmissions))
+	assert.True(t, course.CourseRoleTeachingAssistant.Can(course.PermissionTakeAttendance))
+	assert.False(t, course.CourseRoleTeachingAssistant.Can(course.PermissionEditContent))
+	assert.False(t, course.CourseRole("guest").Can(course.PermissionViewStudents))
+}
+
+func TestCollaboratorLifecycle(t *testing.T) {
+	courseID, ownerID, userID := getRandomUUID(), getRandomUUID(), getRandomUUID()
+	req := course.CollaboratorRequestFormat{UserID: userID, Role: course.CourseRoleTeachingAssistant}
+
+	invitation, err := course.Collaborator{}.NewInvitation(courseID, nil, req, ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, course.CollaboratorStatusInvited, invitation.Status)
+	assert.False(t, invitation.Can(course.PermissionGradeSubmissions), "invitations grant nothing")
+	assert.Empty(t, invitation.ToResponseFormat().Permissions)
+
+	t.Run("cannot be invited twice", func(t *testing.T) {
+		_, err := course.Collaborator{}.NewInvitation(courseID, &invitation, req, ownerID)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, invitation.Accept())
+	assert.True(t, invitation.AcceptedAt.Valid)
+	assert.True(t, invitation.Can(course.PermissionGradeSubmissions))
+	assert.Error(t, invitation.Accept())
+
+	t.Run("the owner role cannot be given", func(t *testing.T) {
+		c := invitation
+		assert.Error(t, c.ChangeRole(course.CollaboratorRoleRequestFormat{Role: course.CourseRoleOwner}, ownerID))
+	})
+
+	assert.NoError(t, invitation.ChangeRole(course.CollaboratorRoleRequestFormat{Role: course.CourseRoleCoInstructor}, ownerID))
+	assert.True(t, invitation.Can(course.PermissionEditContent))
+
+	assert.NoError(t, invitation.Remove(ownerID))
+	assert.False(t, invitation.Can(course.PermissionViewStudents))
+
+	t.Run("removed collaborators can be invited again", func(t *testing.T) {
+		again, err := course.Collaborator{}.NewInvitation(courseID, &invitation, req, ownerID)
+		assert.NoError(t, err)
+		assert.Equal(t, course.CollaboratorStatusInvited, again.Status)
+		assert.Equal(t, course.CourseRoleTeachingAssistant, again.Role)
+		assert.False(t, again.AcceptedAt.Valid)
+	})
+}
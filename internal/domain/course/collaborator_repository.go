@@ -0,0 +1,209 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source collaborator_repository.go -destination mock/collaborator_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	collaboratorQueries = struct {
+		selectCollaborators string
+		upsertCollaborator  string
+		deleteCollaborator  string
+		updateCourseOwner   string
+	}{
+		selectCollaborators: `
+			SELECT
+				course_id,
+				user_id,
+				role,
+				status,
+				invited_at,
+				invited_by,
+				accepted_at,
+				updated_at,
+				updated_by
+			FROM course_collaborators
+		`,
+
+		upsertCollaborator: `
+			INSERT INTO course_collaborators (
+				course_id,
+				user_id,
+				role,
+				status,
+				invited_at,
+				invited_by,
+				accepted_at,
+				updated_at,
+				updated_by
+			) VALUES (
+				:course_id,
+				:user_id,
+				:role,
+				:status,
+				:invited_at,
+				:invited_by,
+				:accepted_at,
+				:updated_at,
+				:updated_by
+			)
+			ON DUPLICATE KEY UPDATE
+				role = VALUES(role),
+				status = VALUES(status),
+				invited_at = VALUES(invited_at),
+				invited_by = VALUES(invited_by),
+				accepted_at = VALUES(accepted_at),
+				updated_at = VALUES(updated_at),
+				updated_by = VALUES(updated_by)
+		`,
+
+		deleteCollaborator: `
+			DELETE FROM course_collaborators
+			WHERE course_id = ? AND user_id = ?
+		`,
+
+		updateCourseOwner: `
+			UPDATE courses
+			SET
+				user_id = :user_id,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+	}
+)
+
+type CollaboratorRepository interface {
+	ResolveCollaborationsByUserID(userID uuid.UUID) (collaborators []Collaborator, err error)
+	ResolveCollaborator(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error)
+	ResolveCollaboratorsByCourseID(courseID uuid.UUID) (collaborators []Collaborator, err error)
+	SaveCollaborator(collaborator Collaborator) (err error)
+	TransferOwnership(c Course, previousOwner Collaborator) (err error)
+}
+
+type CollaboratorRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+func ProvideCollaboratorRepositoryMySQL(db *infras.MySQLConn) *CollaboratorRepositoryMySQL {
+	s := new(CollaboratorRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// ResolveCollaborationsByUserID resolves the Collaborators a user is, on
+// Courses that are not deleted, leaving out those they were removed as.
+func (r *CollaboratorRepositoryMySQL) ResolveCollaborationsByUserID(userID uuid.UUID) (collaborators []Collaborator, err error) {
+	err = r.DB.Read.Select(
+		&collaborators,
+		collaboratorQueries.selectCollaborators+`
+			WHERE user_id = ? AND status <> ?
+			AND course_id IN (SELECT id FROM courses WHERE deleted_at IS NULL)
+			ORDER BY invited_at DESC`,
+		userID.String(),
+		CollaboratorStatusRemoved)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCollaborator resolves the Collaborator a user is on a Course,
+// whatever its status.
+func (r *CollaboratorRepositoryMySQL) ResolveCollaborator(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error) {
+	err = r.DB.Read.Get(
+		&collaborator,
+		collaboratorQueries.selectCollaborators+" WHERE course_id = ? AND user_id = ?",
+		courseID.String(),
+		userID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("collaborator")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCollaboratorsByCourseID resolves the Collaborators of a Course,
+// leaving out removed ones.
+func (r *CollaboratorRepositoryMySQL) ResolveCollaboratorsByCourseID(courseID uuid.UUID) (collaborators []Collaborator, err error) {
+	err = r.DB.Read.Select(
+		&collaborators,
+		collaboratorQueries.selectCollaborators+" WHERE course_id = ? AND status <> ? ORDER BY invited_at",
+		courseID.String(),
+		CollaboratorStatusRemoved)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SaveCollaborator creates or replaces a Collaborator.
+func (r *CollaboratorRepositoryMySQL) SaveCollaborator(collaborator Collaborator) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, collaboratorQueries.upsertCollaborator, collaborator); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// TransferOwnership hands a Course over to the owner set on it. The new
+// owner stops being a Collaborator, while the previous one stays on as one.
+func (r *CollaboratorRepositoryMySQL) TransferOwnership(c Course, previousOwner Collaborator) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, collaboratorQueries.updateCourseOwner, c); err != nil {
+			e <- err
+			return
+		}
+
+		_, err := tx.Exec(collaboratorQueries.deleteCollaborator, c.ID.String(), c.UserID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, collaboratorQueries.upsertCollaborator, previousOwner); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *CollaboratorRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,266 @@
+package course
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// CollaboratorService is the service interface for the Collaborators of Courses
+// and their ownership.
+type CollaboratorService interface {
+	AcceptInvitation(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error)
+	InviteCollaborator(courseID uuid.UUID, requestFormat CollaboratorRequestFormat, userID uuid.UUID) (collaborator Collaborator, err error)
+	RemoveCollaborator(courseID uuid.UUID, collaboratorID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error)
+	ResolveCollaborators(courseID uuid.UUID, userID uuid.UUID) (collaborators []Collaborator, err error)
+	ResolveMyCollaborations(userID uuid.UUID) (collaborators []Collaborator, err error)
+	ResolveMyMembership(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error)
+	TransferOwnership(courseID uuid.UUID, requestFormat OwnershipTransferRequestFormat, userID uuid.UUID) (course Course, err error)
+	UpdateCollaborator(courseID uuid.UUID, collaboratorID uuid.UUID, requestFormat CollaboratorRoleRequestFormat, userID uuid.UUID) (collaborator Collaborator, err error)
+}
+
+// CollaboratorServiceImpl is the service implementation for the Collaborators
+// of Courses and their ownership.
+type CollaboratorServiceImpl struct {
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	Config                 *configs.Config
+}
+
+// ProvideCollaboratorServiceImpl is the provider for this service.
+func ProvideCollaboratorServiceImpl(collaboratorRepository CollaboratorRepository, courseRepository CourseRepository, config *configs.Config) *CollaboratorServiceImpl {
+	s := new(CollaboratorServiceImpl)
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.Config = config
+
+	return s
+}
+
+// AcceptInvitation makes the given user an active Collaborator of a Course
+// they were invited to.
+func (s *CollaboratorServiceImpl) AcceptInvitation(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error) {
+	_, err = s.resolveCourse(courseID)
+	if err != nil {
+		return
+	}
+
+	collaborator, err = s.CollaboratorRepository.ResolveCollaborator(courseID, userID)
+	if err != nil {
+		return
+	}
+
+	err = collaborator.Accept()
+	if err != nil {
+		return
+	}
+
+	err = s.CollaboratorRepository.SaveCollaborator(collaborator)
+	return
+}
+
+// InviteCollaborator invites a user to collaborate on a Course. The invitation
+// grants nothing until the user accepts it.
+func (s *CollaboratorServiceImpl) InviteCollaborator(courseID uuid.UUID, requestFormat CollaboratorRequestFormat, userID uuid.UUID) (collaborator Collaborator, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return collaborator, failure.BadRequest(err)
+	}
+
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionManageCollaborators)
+	if err != nil {
+		return
+	}
+
+	if course.IsOwnedBy(requestFormat.UserID) {
+		return collaborator, failure.BadRequestFromString("the owner cannot be invited to their own course")
+	}
+
+	var previous *Collaborator
+	existing, err := s.CollaboratorRepository.ResolveCollaborator(course.ID, requestFormat.UserID)
+	if err == nil {
+		previous = &existing
+	} else if failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	collaborator, err = Collaborator{}.NewInvitation(course.ID, previous, requestFormat, userID)
+	if err != nil {
+		if err == errCollaboratorExists {
+			return
+		}
+
+		return collaborator, failure.BadRequest(err)
+	}
+
+	err = s.CollaboratorRepository.SaveCollaborator(collaborator)
+	return
+}
+
+// RemoveCollaborator removes a Collaborator from a Course, or withdraws their
+// invitation. Collaborators may remove themselves.
+func (s *CollaboratorServiceImpl) RemoveCollaborator(courseID uuid.UUID, collaboratorID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error) {
+	if collaboratorID == userID {
+		_, err = s.resolveCourse(courseID)
+	} else {
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionManageCollaborators)
+	}
+	if err != nil {
+		return
+	}
+
+	collaborator, err = s.resolveCollaborator(courseID, collaboratorID)
+	if err != nil {
+		return
+	}
+
+	err = collaborator.Remove(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.CollaboratorRepository.SaveCollaborator(collaborator)
+	return
+}
+
+// ResolveCollaborators lists the owner and Collaborators of a Course for its
+// owner and active Collaborators.
+func (s *CollaboratorServiceImpl) ResolveCollaborators(courseID uuid.UUID, userID uuid.UUID) (collaborators []Collaborator, err error) {
+	course, err := s.resolveCourse(courseID)
+	if err != nil {
+		return
+	}
+
+	collaborators, err = s.CollaboratorRepository.ResolveCollaboratorsByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	member := course.IsOwnedBy(userID)
+	for _, collaborator := range collaborators {
+		member = member || (collaborator.UserID == userID && collaborator.IsActive())
+	}
+
+	if !member {
+		return nil, failure.Forbidden("only the people teaching this course can see its collaborators")
+	}
+
+	return append([]Collaborator{NewOwnerCollaborator(course)}, collaborators...), nil
+}
+
+// ResolveMyCollaborations lists the Courses the given user collaborates on or
+// is invited to.
+func (s *CollaboratorServiceImpl) ResolveMyCollaborations(userID uuid.UUID) (collaborators []Collaborator, err error) {
+	return s.CollaboratorRepository.ResolveCollaborationsByUserID(userID)
+}
+
+// ResolveMyMembership resolves the role and permissions the given user has on
+// a Course.
+func (s *CollaboratorServiceImpl) ResolveMyMembership(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error) {
+	course, err := s.resolveCourse(courseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsOwnedBy(userID) {
+		return NewOwnerCollaborator(course), nil
+	}
+
+	return s.resolveCollaborator(courseID, userID)
+}
+
+// TransferOwnership hands a Course over to one of its active Collaborators.
+// The previous owner stays on as a co-instructor, who can then leave.
+func (s *CollaboratorServiceImpl) TransferOwnership(courseID uuid.UUID, requestFormat OwnershipTransferRequestFormat, userID uuid.UUID) (course Course, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return course, failure.BadRequest(err)
+	}
+
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionTransferCourse)
+	if err != nil {
+		return
+	}
+
+	if course.IsOwnedBy(requestFormat.UserID) {
+		return course, failure.BadRequestFromString("the user already owns this course")
+	}
+
+	newOwner, err := s.CollaboratorRepository.ResolveCollaborator(course.ID, requestFormat.UserID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	if err != nil || !newOwner.IsActive() {
+		return course, failure.Conflict("transfer", "course", "the new owner must be an active collaborator of the course")
+	}
+
+	now := time.Now()
+	previousOwner := Collaborator{
+		CourseID:   course.ID,
+		UserID:     course.UserID,
+		Role:       CourseRoleCoInstructor,
+		Status:     CollaboratorStatusActive,
+		InvitedAt:  now,
+		InvitedBy:  userID,
+		AcceptedAt: null.TimeFrom(now),
+	}
+
+	course.TransferTo(newOwner.UserID, userID)
+	err = s.CollaboratorRepository.TransferOwnership(course, previousOwner)
+	return
+}
+
+// UpdateCollaborator changes the CourseRole of a Collaborator.
+func (s *CollaboratorServiceImpl) UpdateCollaborator(courseID uuid.UUID, collaboratorID uuid.UUID, requestFormat CollaboratorRoleRequestFormat, userID uuid.UUID) (collaborator Collaborator, err error) {
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionManageCollaborators)
+	if err != nil {
+		return
+	}
+
+	collaborator, err = s.resolveCollaborator(courseID, collaboratorID)
+	if err != nil {
+		return
+	}
+
+	err = collaborator.ChangeRole(requestFormat, userID)
+	if err != nil {
+		return collaborator, failure.BadRequest(err)
+	}
+
+	err = s.CollaboratorRepository.SaveCollaborator(collaborator)
+	return
+}
+
+// resolveCollaborator resolves a Collaborator of a Course who was not removed.
+func (s *CollaboratorServiceImpl) resolveCollaborator(courseID uuid.UUID, userID uuid.UUID) (collaborator Collaborator, err error) {
+	collaborator, err = s.CollaboratorRepository.ResolveCollaborator(courseID, userID)
+	if err != nil {
+		return
+	}
+
+	if collaborator.Status == CollaboratorStatusRemoved {
+		return collaborator, failure.NotFound("collaborator")
+	}
+
+	return
+}
+
+// resolveCourse resolves a Course that is not deleted.
+func (s *CollaboratorServiceImpl) resolveCourse(id uuid.UUID) (course Course, err error) {
+	course, err = s.CourseRepository.ResolveCourseByID(id)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() {
+		return course, failure.NotFound("course")
+	}
+
+	return
+}
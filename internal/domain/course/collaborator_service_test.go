@@ -0,0 +1,171 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollaboratorPermissions(t *testing.T) {
+	fullCourse := newDraftCourse()
+	assistant := course.Collaborator{
+		CourseID:   fullCourse.ID,
+		UserID:     getRandomUUID(),
+		Role:       course.CourseRoleTeachingAssistant,
+		Status:     course.CollaboratorStatusActive,
+		InvitedAt:  time.Now(),
+		InvitedBy:  fullCourse.UserID,
+		AcceptedAt: null.TimeFrom(time.Now()),
+	}
+
+	t.Run("a teaching assistant cannot edit content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.ModuleServiceImpl{CollaboratorRepository: mockCollaboratorRepo, CourseRepository: mockCourseRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, assistant.UserID).Return(assistant, nil)
+
+		_, err := s.CreateModule(fullCourse.ID, course.ModuleRequestFormat{Title: "Generics"}, assistant.UserID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("a teaching assistant can see the students", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		params := course.EnrollmentQueryParameters{CourseID: fullCourse.ID}
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{CollaboratorRepository: mockCollaboratorRepo, CourseRepository: mockCourseRepo, EnrollmentRepository: mockEnrollmentRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, assistant.UserID).Return(assistant, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollments(params).Return([]course.Enrollment{}, nil)
+
+		_, err := s.ResolveEnrollments(params, assistant.UserID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("a pending invitation grants nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		invited := assistant
+		invited.Status = course.CollaboratorStatusInvited
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{CollaboratorRepository: mockCollaboratorRepo, CourseRepository: mockCourseRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, invited.UserID).Return(invited, nil)
+
+		_, err := s.ResolveEnrollments(course.EnrollmentQueryParameters{CourseID: fullCourse.ID}, invited.UserID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+func TestCollaboratorService(t *testing.T) {
+	config := &configs.Config{}
+	fullCourse := newDraftCourse()
+	coInstructor := course.Collaborator{
+		CourseID:   fullCourse.ID,
+		UserID:     getRandomUUID(),
+		Role:       course.CourseRoleCoInstructor,
+		Status:     course.CollaboratorStatusActive,
+		InvitedAt:  time.Now(),
+		InvitedBy:  fullCourse.UserID,
+		AcceptedAt: null.TimeFrom(time.Now()),
+	}
+
+	t.Run("a co-instructor cannot invite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := course.ProvideCollaboratorServiceImpl(mockCollaboratorRepo, mockCourseRepo, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, coInstructor.UserID).Return(coInstructor, nil)
+
+		_, err := s.InviteCollaborator(fullCourse.ID, course.CollaboratorRequestFormat{
+			UserID: getRandomUUID(),
+			Role:   course.CourseRoleTeachingAssistant,
+		}, coInstructor.UserID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("the owner invites", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userID := getRandomUUID()
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := course.ProvideCollaboratorServiceImpl(mockCollaboratorRepo, mockCourseRepo, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, userID).Return(course.Collaborator{}, failure.NotFound("collaborator"))
+		mockCollaboratorRepo.EXPECT().SaveCollaborator(gomock.Any()).Return(nil)
+
+		invitation, err := s.InviteCollaborator(fullCourse.ID, course.CollaboratorRequestFormat{
+			UserID: userID,
+			Role:   course.CourseRoleTeachingAssistant,
+		}, fullCourse.UserID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.CollaboratorStatusInvited, invitation.Status)
+	})
+
+	t.Run("transfer ownership", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := course.ProvideCollaboratorServiceImpl(mockCollaboratorRepo, mockCourseRepo, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, coInstructor.UserID).Return(coInstructor, nil)
+		mockCollaboratorRepo.EXPECT().TransferOwnership(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(transferred course.Course, previousOwner course.Collaborator) error {
+				assert.Equal(t, coInstructor.UserID, transferred.UserID)
+				assert.Equal(t, fullCourse.UserID, previousOwner.UserID)
+				assert.Equal(t, course.CourseRoleCoInstructor, previousOwner.Role)
+				assert.True(t, previousOwner.IsActive())
+				return nil
+			})
+
+		transferred, err := s.TransferOwnership(fullCourse.ID, course.OwnershipTransferRequestFormat{UserID: coInstructor.UserID}, fullCourse.UserID)
+
+		assert.NoError(t, err)
+		assert.True(t, transferred.IsOwnedBy(coInstructor.UserID))
+	})
+
+	t.Run("transfer to someone who is not a collaborator", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stranger := getRandomUUID()
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := course.ProvideCollaboratorServiceImpl(mockCollaboratorRepo, mockCourseRepo, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(fullCourse.ID, stranger).Return(course.Collaborator{}, failure.NotFound("collaborator"))
+
+		_, err := s.TransferOwnership(fullCourse.ID, course.OwnershipTransferRequestFormat{UserID: stranger}, fullCourse.UserID)
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
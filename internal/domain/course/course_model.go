@@ -2,25 +2,50 @@ package course
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
 	"github.com/evermos/boilerplate-go/shared/nuuid"
 	"github.com/gofrs/uuid"
 	"github.com/guregu/null"
 )
 
+// CourseStatus indicates the publishing status of a Course.
+type CourseStatus string
+
+const (
+	// CourseStatusDraft indicates a Course being written.
+	CourseStatusDraft CourseStatus = "draft"
+	// CourseStatusInReview indicates a Course waiting to be published.
+	CourseStatusInReview CourseStatus = "in_review"
+	// CourseStatusPublished indicates a Course whose latest draft is published.
+	CourseStatusPublished CourseStatus = "published"
+	// CourseStatusArchived indicates a Course that is closed to students.
+	CourseStatusArchived CourseStatus = "archived"
+)
+
 type Course struct {
-	ID        uuid.UUID   `db:"id" validate:"required"`
-	UserID    uuid.UUID   `db:"user_id" validate:"required"`
-	Title     string      `db:"title" validate:"required"`
-	Content   string      `db:"content" validate:"required"`
-	CreatedAt time.Time   `db:"created_at" validate:"required"`
-	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
-	UpdatedAt null.Time   `db:"updated_at"`
-	UpdatedBy nuuid.NUUID `db:"updated_by"`
-	DeletedAt null.Time   `db:"deleted_at"`
-	DeletedBy nuuid.NUUID `db:"deleted_by"`
+	ID               uuid.UUID    `db:"id" validate:"required"`
+	UserID           uuid.UUID    `db:"user_id" validate:"required"`
+	Title            string       `db:"title" validate:"required"`
+	Content          string       `db:"content" validate:"required"`
+	SeatLimit        null.Int     `db:"seat_limit"`
+	Status           CourseStatus `db:"status" validate:"required,oneof=draft in_review published archived"`
+	PublishedVersion null.Int     `db:"published_version"`
+	CourseRating
+	CreatedAt       time.Time   `db:"created_at" validate:"required"`
+	CreatedBy       uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt       null.Time   `db:"updated_at"`
+	UpdatedBy       nuuid.NUUID `db:"updated_by"`
+	DeletedAt       null.Time   `db:"deleted_at"`
+	DeletedBy       nuuid.NUUID `db:"deleted_by"`
+	Modules         []Module    `db:"-"`
+	Categories      []Category  `db:"-"`
+	Tags            []Tag       `db:"-"`
+	PrerequisiteIDs []uuid.UUID `db:"-"`
 }
 
 type CourseQueryParameters struct {
@@ -28,7 +53,57 @@ type CourseQueryParameters struct {
 	Limit int
 	Sort  string
 	Order string
-	Role  string
+	// CategoryID limits the Courses to a Category and its descendants, whose
+	// IDs are resolved into CategoryIDs.
+	CategoryID  nuuid.NUUID
+	CategoryIDs []uuid.UUID
+	Tags        []string
+}
+
+// AttachModules attaches Modules to this Course.
+func (c *Course) AttachModules(modules []Module) Course {
+	for _, module := range modules {
+		if module.CourseID == c.ID {
+			c.Modules = append(c.Modules, module)
+		}
+	}
+	return *c
+}
+
+// AttachTaxonomy attaches the Categories and Tags of this Course.
+func (c *Course) AttachTaxonomy(categories []Category, tags []Tag) Course {
+	c.Categories = categories
+	c.Tags = tags
+	return *c
+}
+
+// AttachPrerequisites attaches the IDs of the Courses this Course requires.
+func (c *Course) AttachPrerequisites(prerequisiteIDs []uuid.UUID) Course {
+	c.PrerequisiteIDs = prerequisiteIDs
+	return *c
+}
+
+// IsDeleted checks whether a Course is marked as deleted.
+func (c *Course) IsDeleted() (deleted bool) {
+	return c.DeletedAt.Valid && c.DeletedBy.Valid
+}
+
+// IsVisibleToStudents checks whether students can enroll in and read a Course,
+// which they do in its latest published version.
+func (c *Course) IsVisibleToStudents() bool {
+	return c.PublishedVersion.Valid && c.Status != CourseStatusArchived
+}
+
+// IsOwnedBy checks whether a Course belongs to the given user.
+func (c *Course) IsOwnedBy(userID uuid.UUID) bool {
+	return c.UserID == userID
+}
+
+// TransferTo hands a Course over to another owner.
+func (c *Course) TransferTo(ownerID uuid.UUID, userID uuid.UUID) {
+	c.UserID = ownerID
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
 }
 
 func (c Course) MarshalJSON() ([]byte, error) {
@@ -43,6 +118,8 @@ func (c Course) NewCourseFromRequestFormat(req CourseRequestFormat, userID uuid.
 		UserID:    userID,
 		Title:     req.Title,
 		Content:   req.Content,
+		SeatLimit: req.SeatLimit,
+		Status:    CourseStatusDraft,
 		CreatedAt: time.Now(),
 		CreatedBy: userID,
 	}
@@ -52,40 +129,209 @@ func (c Course) NewCourseFromRequestFormat(req CourseRequestFormat, userID uuid.
 	return
 }
 
+// Patch partially updates a Course, leaving fields absent from the request untouched.
+func (c *Course) Patch(req CoursePatchRequestFormat, userID uuid.UUID) (err error) {
+	if req.Title != nil {
+		c.Title = *req.Title
+	}
+
+	if req.Content != nil {
+		c.Content = *req.Content
+	}
+
+	if req.SeatLimit != nil {
+		c.SeatLimit = null.IntFrom(*req.SeatLimit)
+	}
+
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	err = c.Validate()
+
+	return
+}
+
+// SoftDelete marks a Course as deleted by setting the "deletedAt" and "deletedBy"
+// properties of a Course.
+func (c *Course) SoftDelete(userID uuid.UUID) (err error) {
+	if c.IsDeleted() {
+		return failure.Conflict("softDelete", "course", "already marked as deleted")
+	}
+
+	c.DeletedAt = null.TimeFrom(time.Now())
+	c.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// Update updates a Course.
+func (c *Course) Update(req CourseRequestFormat, userID uuid.UUID) (err error) {
+	c.Title = req.Title
+	c.Content = req.Content
+	c.SeatLimit = req.SeatLimit
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	err = c.Validate()
+
+	return
+}
+
+// Publish publishes a Course under review, making a new CourseVersion the
+// version students see.
+func (c *Course) Publish(version CourseVersion, userID uuid.UUID) (err error) {
+	err = c.UpdateStatus(CourseStatusPublished, userID)
+	if err != nil {
+		return
+	}
+
+	c.PublishedVersion = null.IntFrom(int64(version.Version))
+
+	return
+}
+
+// RollBack makes a CourseVersion restoring an earlier one the version students
+// see. The draft is left as it is.
+func (c *Course) RollBack(version CourseVersion, userID uuid.UUID) (err error) {
+	if !c.IsVisibleToStudents() {
+		return failure.Conflict("rollBack", "course", "only a course visible to students can be rolled back")
+	}
+
+	c.PublishedVersion = null.IntFrom(int64(version.Version))
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	return
+}
+
+// UpdateStatus validates a Course's status change. Allowed state changes are:
+// 1. Draft --> InReview
+// 2. InReview --> Draft, Published
+// 3. Published --> InReview, Archived
+// 4. Archived --> Draft
+func (c *Course) UpdateStatus(newStatus CourseStatus, userID uuid.UUID) (err error) {
+	stateChangeNotAllowedError := failure.Conflict(
+		"stateChange",
+		"course",
+		fmt.Sprintf("cannot change from %s to %s", c.Status, newStatus))
+
+	switch c.Status {
+	case CourseStatusDraft:
+		if newStatus != CourseStatusInReview {
+			return stateChangeNotAllowedError
+		}
+	case CourseStatusInReview:
+		if newStatus != CourseStatusDraft && newStatus != CourseStatusPublished {
+			return stateChangeNotAllowedError
+		}
+	case CourseStatusPublished:
+		if newStatus != CourseStatusInReview && newStatus != CourseStatusArchived {
+			return stateChangeNotAllowedError
+		}
+	case CourseStatusArchived:
+		if newStatus != CourseStatusDraft {
+			return stateChangeNotAllowedError
+		}
+	}
+
+	// passed all state change validations, actually update the status
+	c.Status = newStatus
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	return nil
+}
+
+// HasSeatAvailable checks whether a Course has a seat left for another student
+// while the given number of seats is taken.
+func (c *Course) HasSeatAvailable(seatsTaken int) bool {
+	return !c.HasSeatLimit() || int64(seatsTaken) < c.SeatLimit.Int64
+}
+
+// HasSeatLimit checks whether a Course caps the number of enrolled students.
+func (c *Course) HasSeatLimit() bool {
+	return c.SeatLimit.Valid
+}
+
 func (c *Course) Validate() (err error) {
+	if c.SeatLimit.Valid && c.SeatLimit.Int64 < 1 {
+		return errors.New("seat limit must be at least 1")
+	}
+
 	validator := shared.GetValidator()
 	return validator.Struct(c)
 }
 
 func (c Course) ToResponseFormat() CourseResponseFormat {
-	return CourseResponseFormat{
-		ID:        c.ID,
-		UserID:    c.UserID,
-		Title:     c.Title,
-		Content:   c.Content,
-		CreatedBy: c.CreatedBy,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: c.UpdatedAt,
-		UpdatedBy: c.UpdatedBy.Ptr(),
-		DeletedAt: c.DeletedAt,
-		DeletedBy: c.DeletedBy.Ptr(),
+	resp := CourseResponseFormat{
+		ID:               c.ID,
+		UserID:           c.UserID,
+		Title:            c.Title,
+		Content:          c.Content,
+		SeatLimit:        c.SeatLimit,
+		Status:           c.Status,
+		PublishedVersion: c.PublishedVersion,
+		CreatedBy:        c.CreatedBy,
+		CreatedAt:        c.CreatedAt,
+		UpdatedAt:        c.UpdatedAt,
+		UpdatedBy:        c.UpdatedBy.Ptr(),
+		DeletedAt:        c.DeletedAt,
+		DeletedBy:        c.DeletedBy.Ptr(),
+		PrerequisiteIDs:  c.PrerequisiteIDs,
+		Rating:           c.CourseRating.ToResponseFormat(),
+	}
+
+	for _, module := range c.Modules {
+		resp.Modules = append(resp.Modules, module.ToResponseFormat())
 	}
+
+	for _, category := range c.Categories {
+		resp.Categories = append(resp.Categories, category.ToResponseFormat())
+	}
+
+	for _, tag := range c.Tags {
+		resp.Tags = append(resp.Tags, tag.Name)
+	}
+
+	return resp
 }
 
 type CourseRequestFormat struct {
-	Title   string `json:"title" validate:"required"`
-	Content string `json:"content" validate:"required"`
+	Title     string   `json:"title" validate:"required"`
+	Content   string   `json:"content" validate:"required"`
+	SeatLimit null.Int `json:"seatLimit" swaggertype:"integer"`
+}
+
+// CoursePatchRequestFormat represents a Course's partial update request. Fields
+// left out of the request body are not changed.
+type CoursePatchRequestFormat struct {
+	Title     *string `json:"title" validate:"omitempty,min=1"`
+	Content   *string `json:"content" validate:"omitempty,min=1"`
+	SeatLimit *int64  `json:"seatLimit" validate:"omitempty,min=1"`
+}
+
+// CourseStatusRequestFormat represents a request to change a Course's status.
+type CourseStatusRequestFormat struct {
+	Status CourseStatus `json:"status" validate:"required,oneof=draft in_review published archived"`
 }
 
 type CourseResponseFormat struct {
-	ID        uuid.UUID  `json:"id"`
-	UserID    uuid.UUID  `json:"userID"`
-	Title     string     `json:"title"`
-	Content   string     `json:"content"`
-	CreatedAt time.Time  `json:"createdAt"`
-	CreatedBy uuid.UUID  `json:"createdBy"`
-	UpdatedAt null.Time  `json:"updatedAt"`
-	UpdatedBy *uuid.UUID `json:"updatedBy"`
-	DeletedAt null.Time  `json:"deletedAt,omitempty"`
-	DeletedBy *uuid.UUID `json:"deletedBy,omitempty"`
+	ID               uuid.UUID                  `json:"id"`
+	UserID           uuid.UUID                  `json:"userID"`
+	Title            string                     `json:"title"`
+	Content          string                     `json:"content"`
+	SeatLimit        null.Int                   `json:"seatLimit" swaggertype:"integer"`
+	Status           CourseStatus               `json:"status"`
+	PublishedVersion null.Int                   `json:"publishedVersion" swaggertype:"integer"`
+	Rating           CourseRatingResponseFormat `json:"rating"`
+	CreatedAt        time.Time                  `json:"createdAt"`
+	CreatedBy        uuid.UUID                  `json:"createdBy"`
+	UpdatedAt        null.Time                  `json:"updatedAt"`
+	UpdatedBy        *uuid.UUID                 `json:"updatedBy"`
+	DeletedAt        null.Time                  `json:"deletedAt,omitempty"`
+	DeletedBy        *uuid.UUID                 `json:"deletedBy,omitempty"`
+	Modules          []ModuleResponseFormat     `json:"modules,omitempty"`
+	Categories       []CategoryResponseFormat   `json:"categories,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`
+	PrerequisiteIDs  []uuid.UUID                `json:"prerequisiteIDs,omitempty"`
 }
@@ -1,5 +1,7 @@
 package course
 
+//go:generate go run github.com/golang/mock/mockgen -source course_repository.go -destination mock/course_repository_mock.go -package course_mock
+
 import (
 	"database/sql"
 	"errors"
@@ -15,8 +17,14 @@ import (
 
 var (
 	courseQueries = struct {
-		selectCourses string
-		insertCourse  string
+		selectCourses               string
+		insertCourse                string
+		insertCourseBulk            string
+		insertCourseBulkPlaceholder string
+		updateCourse                string
+		selectPrerequisites         string
+		insertPrerequisite          string
+		deletePrerequisitesByCourse string
 	}{
 		selectCourses: `
 			SELECT
@@ -24,6 +32,16 @@ var (
 				user_id,
 				title,
 				content,
+				seat_limit,
+				status,
+				published_version,
+				rating_count,
+				rating_average,
+				rating_1_count,
+				rating_2_count,
+				rating_3_count,
+				rating_4_count,
+				rating_5_count,
 				created_at,
 				created_by,
 				updated_at,
@@ -39,6 +57,9 @@ var (
 				user_id,
 				title,
 				content,
+				seat_limit,
+				status,
+				published_version,
 				created_at,
 				created_by,
 				updated_at,
@@ -50,6 +71,9 @@ var (
 				:user_id,
 				:title,
 				:content,
+				:seat_limit,
+				:status,
+				:published_version,
 				:created_at,
 				:created_by,
 				:updated_at,
@@ -58,12 +82,97 @@ var (
 				:deleted_by
 			)
 		`,
+
+		insertCourseBulk: `
+			INSERT INTO courses (
+				id,
+				user_id,
+				title,
+				content,
+				seat_limit,
+				status,
+				published_version,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES `,
+
+		insertCourseBulkPlaceholder: `
+			(:id,
+			:user_id,
+			:title,
+			:content,
+			:seat_limit,
+			:status,
+			:published_version,
+			:created_at,
+			:created_by,
+			:updated_at,
+			:updated_by,
+			:deleted_at,
+			:deleted_by)`,
+
+		updateCourse: `
+			UPDATE courses
+			SET
+				title = :title,
+				content = :content,
+				seat_limit = :seat_limit,
+				status = :status,
+				published_version = :published_version,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		selectPrerequisites: `
+			SELECT
+				cp.course_id,
+				cp.prerequisite_id,
+				cp.created_at,
+				cp.created_by
+			FROM course_prerequisites cp
+			JOIN courses c ON c.id = cp.course_id AND c.deleted_at IS NULL
+			JOIN courses p ON p.id = cp.prerequisite_id AND p.deleted_at IS NULL
+		`,
+
+		insertPrerequisite: `
+			INSERT INTO course_prerequisites (
+				course_id,
+				prerequisite_id,
+				created_at,
+				created_by
+			) VALUES (
+				:course_id,
+				:prerequisite_id,
+				:created_at,
+				:created_by
+			)
+		`,
+
+		deletePrerequisitesByCourse: `
+			DELETE FROM course_prerequisites
+			WHERE course_id = ?
+		`,
 	}
 )
 
 type CourseRepository interface {
 	CreateCourse(course Course) (err error)
+	CreateCourses(courses []Course) (err error)
+	ExistsByID(id uuid.UUID) (exists bool, err error)
+	ResolveCourseByID(id uuid.UUID) (course Course, err error)
 	ResolveCourses(params CourseQueryParameters) (courses []Course, err error)
+	ResolveCoursesByIDs(ids []uuid.UUID) (courses []Course, err error)
+	ResolvePrerequisiteGraph() (graph PrerequisiteGraph, err error)
+	ResolvePrerequisiteIDs(courseID uuid.UUID) (ids []uuid.UUID, err error)
+	SetCoursePrerequisites(courseID uuid.UUID, prerequisites []CoursePrerequisite) (err error)
+	UpdateCourse(course Course) (err error)
 }
 
 type CourseRepositoryMySQL struct {
@@ -100,14 +209,43 @@ func (r *CourseRepositoryMySQL) CreateCourse(course Course) (err error) {
 	})
 }
 
+// CreateCourses creates a batch of Courses with a single insert. Either all of
+// them are created or, if any fails, none is.
+func (r *CourseRepositoryMySQL) CreateCourses(courses []Course) (err error) {
+	if len(courses) == 0 {
+		return
+	}
+
+	query, args, err := r.composeBulkInsertCourseQuery(courses)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec(query, args...); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
 func (r *CourseRepositoryMySQL) ResolveCourses(params CourseQueryParameters) (courses []Course, err error) {
 	var args []interface{}
 
-	query := courseQueries.selectCourses
+	query := courseQueries.selectCourses + " WHERE deleted_at IS NULL"
+
+	if len(params.CategoryIDs) > 0 {
+		query += " AND id IN (SELECT course_id FROM course_categories WHERE category_id IN (?))"
+		args = append(args, params.CategoryIDs)
+	}
 
-	if params.Role != "" {
-		query += " WHERE role = ?"
-		args = append(args, params.Role)
+	if len(params.Tags) > 0 {
+		query += " AND " + searchQueries.filterByTags
+		args = append(args, params.Tags)
 	}
 
 	if params.Sort != "" {
@@ -131,6 +269,12 @@ func (r *CourseRepositoryMySQL) ResolveCourses(params CourseQueryParameters) (co
 	query += " LIMIT ? OFFSET ?"
 	args = append(args, params.Limit, offset)
 
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return nil, err
+	}
+
 	err = r.DB.Read.Select(&courses, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -146,6 +290,118 @@ func (r *CourseRepositoryMySQL) ResolveCourses(params CourseQueryParameters) (co
 	return courses, nil
 }
 
+func (r *CourseRepositoryMySQL) ResolveCourseByID(id uuid.UUID) (course Course, err error) {
+	err = r.DB.Read.Get(
+		&course,
+		courseQueries.selectCourses+" WHERE id = ?",
+		id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("course")
+		}
+
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return
+}
+
+// ResolveCoursesByIDs resolves the Courses with the given IDs that are not deleted.
+func (r *CourseRepositoryMySQL) ResolveCoursesByIDs(ids []uuid.UUID) (courses []Course, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(courseQueries.selectCourses+" WHERE id IN (?) AND deleted_at IS NULL", ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&courses, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolvePrerequisiteGraph resolves every CoursePrerequisite between Courses
+// that are not deleted.
+func (r *CourseRepositoryMySQL) ResolvePrerequisiteGraph() (graph PrerequisiteGraph, err error) {
+	err = r.DB.Read.Select(&graph, courseQueries.selectPrerequisites)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolvePrerequisiteIDs resolves the IDs of the Courses a Course requires
+// directly, leaving out deleted ones.
+func (r *CourseRepositoryMySQL) ResolvePrerequisiteIDs(courseID uuid.UUID) (ids []uuid.UUID, err error) {
+	var prerequisites []CoursePrerequisite
+	err = r.DB.Read.Select(
+		&prerequisites,
+		courseQueries.selectPrerequisites+" WHERE cp.course_id = ? ORDER BY p.title",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	for _, prerequisite := range prerequisites {
+		ids = append(ids, prerequisite.PrerequisiteID)
+	}
+
+	return
+}
+
+// SetCoursePrerequisites replaces the prerequisites of a Course.
+func (r *CourseRepositoryMySQL) SetCoursePrerequisites(courseID uuid.UUID, prerequisites []CoursePrerequisite) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		_, err := tx.Exec(courseQueries.deletePrerequisitesByCourse, courseID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		for _, prerequisite := range prerequisites {
+			if err := r.txExecNamed(tx, courseQueries.insertPrerequisite, prerequisite); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+func (r *CourseRepositoryMySQL) UpdateCourse(course Course) (err error) {
+	exists, err := r.ExistsByID(course.ID)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	if !exists {
+		err = failure.NotFound("course")
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txUpdate(tx, course); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
 func (r *CourseRepositoryMySQL) ExistsByID(id uuid.UUID) (exists bool, err error) {
 	err = r.DB.Read.Get(
 		&exists,
@@ -160,6 +416,23 @@ func (r *CourseRepositoryMySQL) ExistsByID(id uuid.UUID) (exists bool, err error
 }
 
 // Internal Functions
+
+// composeBulkInsertCourseQuery composes a bulk insert query given a slice of
+// Courses.
+func (r *CourseRepositoryMySQL) composeBulkInsertCourseQuery(courses []Course) (query string, params []interface{}, err error) {
+	values := []string{}
+	for _, course := range courses {
+		q, args, err := sqlx.Named(courseQueries.insertCourseBulkPlaceholder, course)
+		if err != nil {
+			return query, params, err
+		}
+		values = append(values, q)
+		params = append(params, args...)
+	}
+	query = fmt.Sprintf("%v %v", courseQueries.insertCourseBulk, strings.Join(values, ","))
+	return
+}
+
 func (r *CourseRepositoryMySQL) txCreate(tx *sqlx.Tx, course Course) (err error) {
 	stmt, err := tx.PrepareNamed(courseQueries.insertCourse)
 	if err != nil {
@@ -176,6 +449,39 @@ func (r *CourseRepositoryMySQL) txCreate(tx *sqlx.Tx, course Course) (err error)
 	return
 }
 
+func (r *CourseRepositoryMySQL) txUpdate(tx *sqlx.Tx, course Course) (err error) {
+	stmt, err := tx.PrepareNamed(courseQueries.updateCourse)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(course)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *CourseRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
 func (r *CourseRepositoryMySQL) isValidColumnName(columnName string) (bool, error) {
 	var columns []string
 	const query = `SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = 'courses' AND TABLE_SCHEMA = DATABASE()`
@@ -195,15 +501,17 @@ func (r *CourseRepositoryMySQL) isValidColumnName(columnName string) (bool, erro
 
 func (r *CourseRepositoryMySQL) isSortableColumn(columnName string) bool {
 	sortableColumns := map[string]bool{
-		"id":         true,
-		"user_id":    true,
-		"title":      true,
-		"created_at": true,
-		"created_by": true,
-		"updated_at": true,
-		"updated_by": true,
-		"deleted_at": true,
-		"deleted_by": true,
+		"id":             true,
+		"user_id":        true,
+		"title":          true,
+		"rating_average": true,
+		"rating_count":   true,
+		"created_at":     true,
+		"created_by":     true,
+		"updated_at":     true,
+		"updated_by":     true,
+		"deleted_at":     true,
+		"deleted_by":     true,
 	}
 
 	return sortableColumns[columnName]
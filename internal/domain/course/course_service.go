@@ -1,24 +1,53 @@
 package course
 
 import (
+	"fmt"
+	"net/http"
+	"time"
+
 	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
 	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
 	"github.com/gofrs/uuid"
 )
 
 type CourseService interface {
 	CreateCourse(requestFormat CourseRequestFormat, userID uuid.UUID) (course Course, err error)
+	CreateCoursesBulk(rows []CourseBulkRow, dryRun bool, userID uuid.UUID) (report CourseBulkReport, err error)
+	PatchCourse(id uuid.UUID, requestFormat CoursePatchRequestFormat, userID uuid.UUID) (course Course, err error)
+	ResolveCourseByID(id uuid.UUID, withModules bool) (course Course, err error)
+	ResolveReadableCourseByID(id uuid.UUID, userID uuid.UUID, role string) (course Course, err error)
 	ResolveCourses(params CourseQueryParameters) (courses []Course, err error)
+	ResolvePrerequisiteChain(id uuid.UUID, role string) (chain PrerequisiteChain, err error)
+	SearchCourses(params CourseSearchParameters, role string) (result CourseSearchResult, err error)
+	SetCoursePrerequisites(id uuid.UUID, requestFormat CoursePrerequisitesRequestFormat, userID uuid.UUID) (course Course, err error)
+	SoftDeleteCourse(id uuid.UUID, userID uuid.UUID) (course Course, err error)
+	UpdateCourse(id uuid.UUID, requestFormat CourseRequestFormat, userID uuid.UUID) (course Course, err error)
 }
 
 type CourseServiceImpl struct {
-	CourseRepository CourseRepository
-	Config           *configs.Config
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	PublishingRepository   PublishingRepository
+	SearchRepository       SearchRepository
+	TaxonomyRepository     TaxonomyRepository
+	Config                 *configs.Config
 }
 
-func ProvideCourseServiceImpl(courseRepository CourseRepository, config *configs.Config) *CourseServiceImpl {
+func ProvideCourseServiceImpl(cohortRepository CohortRepository, collaboratorRepository CollaboratorRepository, courseRepository CourseRepository, enrollmentRepository EnrollmentRepository, moduleRepository ModuleRepository, publishingRepository PublishingRepository, searchRepository SearchRepository, taxonomyRepository TaxonomyRepository, config *configs.Config) *CourseServiceImpl {
 	s := new(CourseServiceImpl)
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
 	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.PublishingRepository = publishingRepository
+	s.SearchRepository = searchRepository
+	s.TaxonomyRepository = taxonomyRepository
 	s.Config = config
 
 	return s
@@ -26,10 +55,6 @@ func ProvideCourseServiceImpl(courseRepository CourseRepository, config *configs
 
 func (s *CourseServiceImpl) CreateCourse(requestFormat CourseRequestFormat, userID uuid.UUID) (course Course, err error) {
 	course, err = course.NewCourseFromRequestFormat(requestFormat, userID)
-	if err != nil {
-		return
-	}
-
 	if err != nil {
 		return course, failure.BadRequest(err)
 	}
@@ -42,7 +67,69 @@ func (s *CourseServiceImpl) CreateCourse(requestFormat CourseRequestFormat, user
 	return
 }
 
+// CreateCoursesBulk creates a Course for every valid row of a bulk upload, in
+// batches, and reports the outcome of each row. Invalid rows are reported and
+// skipped; a batch that fails to insert is reported without failing the
+// others. A dry run only validates the rows, without creating anything.
+func (s *CourseServiceImpl) CreateCoursesBulk(rows []CourseBulkRow, dryRun bool, userID uuid.UUID) (report CourseBulkReport, err error) {
+	report = CourseBulkReport{}.NewCourseBulkReport(rows, dryRun)
+
+	courses := make([]Course, 0, len(rows))
+	indexes := make([]int, 0, len(rows))
+	for i := range rows {
+		course, ok := rows[i].NewCourse(userID)
+		if !ok {
+			report.Record(i, CourseBulkRowInvalid, nuuid.NUUID{}, rows[i].Errors)
+			continue
+		}
+
+		if dryRun {
+			report.Record(i, CourseBulkRowValid, nuuid.NUUID{}, nil)
+			continue
+		}
+
+		courses = append(courses, course)
+		indexes = append(indexes, i)
+	}
+
+	for start := 0; start < len(courses); start += courseBulkBatchSize {
+		end := start + courseBulkBatchSize
+		if end > len(courses) {
+			end = len(courses)
+		}
+
+		batchErr := s.CourseRepository.CreateCourses(courses[start:end])
+		for j := start; j < end; j++ {
+			if batchErr != nil {
+				report.Record(indexes[j], CourseBulkRowFailed, nuuid.NUUID{}, []string{"the course could not be saved"})
+				continue
+			}
+
+			report.Record(indexes[j], CourseBulkRowCreated, nuuid.From(courses[j].ID), nil)
+		}
+	}
+
+	report.Tally()
+	return
+}
+
+// ResolveCourses resolves a page of Courses, optionally only those in a
+// Category or any of its descendants, or with any of the given Tags.
 func (s *CourseServiceImpl) ResolveCourses(params CourseQueryParameters) (courses []Course, err error) {
+	if params.CategoryID.Valid {
+		categories, err := s.TaxonomyRepository.ResolveCategories()
+		if err != nil {
+			return courses, err
+		}
+
+		params.CategoryIDs = categories.Subtree(params.CategoryID.UUID)
+		if len(params.CategoryIDs) == 0 {
+			return courses, failure.NotFound("category")
+		}
+	}
+
+	params.Tags = NormalizeTagNames(params.Tags)
+
 	courses, err = s.CourseRepository.ResolveCourses(params)
 	if err != nil {
 		return courses, failure.BadRequest(err)
@@ -50,3 +137,276 @@ func (s *CourseServiceImpl) ResolveCourses(params CourseQueryParameters) (course
 
 	return
 }
+
+// PatchCourse partially updates a Course the given user may edit.
+func (s *CourseServiceImpl) PatchCourse(id uuid.UUID, requestFormat CoursePatchRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	err = course.Patch(requestFormat, userID)
+	if err != nil {
+		return course, failure.BadRequest(err)
+	}
+
+	err = s.CourseRepository.UpdateCourse(course)
+	return
+}
+
+// ResolveCourseByID resolves a Course by its ID, optionally with its Modules
+// and their Lessons.
+func (s *CourseServiceImpl) ResolveCourseByID(id uuid.UUID, withModules bool) (course Course, err error) {
+	course, err = s.CourseRepository.ResolveCourseByID(id)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() {
+		return course, failure.NotFound("course")
+	}
+
+	if withModules {
+		course, err = attachCourseModules(s.ModuleRepository, course)
+	}
+
+	return
+}
+
+// ResolveReadableCourseByID resolves a Course with its Modules and Lessons on
+// behalf of a user, who must be a teacher or an actively enrolled student.
+// Teachers see the current draft, students the published version with the
+// Lessons their Cohort has not released yet locked.
+func (s *CourseServiceImpl) ResolveReadableCourseByID(id uuid.UUID, userID uuid.UUID, role string) (course Course, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if role == shared.RoleTeacher {
+		course, err = s.ResolveCourseByID(id, true)
+	} else {
+		course, err = s.ResolveCourseByID(id, false)
+		if err == nil {
+			course, err = resolvePublishedCourse(s.PublishingRepository, course)
+		}
+
+		if err == nil {
+			course, err = s.applyStudentSchedule(course, userID)
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	course, err = attachCourseTaxonomy(s.TaxonomyRepository, course)
+	if err != nil {
+		return
+	}
+
+	prerequisiteIDs, err := s.CourseRepository.ResolvePrerequisiteIDs(course.ID)
+	if err != nil {
+		return
+	}
+
+	return course.AttachPrerequisites(prerequisiteIDs), nil
+}
+
+// ResolvePrerequisiteChain resolves every Course that has to be completed
+// before the given one, in an order they can be taken in. Students can see the
+// chain of any Course visible to them, so they can plan before enrolling.
+func (s *CourseServiceImpl) ResolvePrerequisiteChain(id uuid.UUID, role string) (chain PrerequisiteChain, err error) {
+	course, err := s.CourseRepository.ResolveCourseByID(id)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || (role != shared.RoleTeacher && !course.IsVisibleToStudents()) {
+		return chain, failure.NotFound("course")
+	}
+
+	graph, err := s.CourseRepository.ResolvePrerequisiteGraph()
+	if err != nil {
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, step := range graph.Chain(course.ID) {
+		ids = append(ids, step.CourseID)
+	}
+
+	courses, err := s.CourseRepository.ResolveCoursesByIDs(ids)
+	if err != nil {
+		return
+	}
+
+	return NewPrerequisiteChain(course.ID, graph, courses), nil
+}
+
+// SearchCourses searches Courses by their title and content. Teachers search
+// every Course, while students only find the Courses visible to them, which
+// they see as published.
+func (s *CourseServiceImpl) SearchCourses(params CourseSearchParameters, role string) (result CourseSearchResult, err error) {
+	params.VisibleOnly = role != shared.RoleTeacher
+	params.Tags = NormalizeTagNames(params.Tags)
+
+	err = params.Validate()
+	if err != nil {
+		return result, failure.BadRequest(err)
+	}
+
+	result, err = s.SearchRepository.SearchCourses(params)
+	if err != nil || !params.VisibleOnly {
+		return
+	}
+
+	for i, hit := range result.Courses {
+		published, err := resolvePublishedCourse(s.PublishingRepository, hit.Course)
+		if err != nil {
+			return result, err
+		}
+
+		published.Modules = nil
+		result.Courses[i].Course = published
+	}
+
+	return
+}
+
+// SetCoursePrerequisites replaces the Courses a Course the given user may edit
+// requires. Changes that would make a Course require itself, directly or
+// through its prerequisites, are rejected.
+func (s *CourseServiceImpl) SetCoursePrerequisites(id uuid.UUID, requestFormat CoursePrerequisitesRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	prerequisites := NewCoursePrerequisites(course.ID, requestFormat.PrerequisiteIDs, userID)
+	prerequisiteIDs := make([]uuid.UUID, 0, len(prerequisites))
+	for _, prerequisite := range prerequisites {
+		prerequisiteIDs = append(prerequisiteIDs, prerequisite.PrerequisiteID)
+	}
+
+	courses, err := s.CourseRepository.ResolveCoursesByIDs(prerequisiteIDs)
+	if err != nil {
+		return
+	}
+
+	if len(courses) != len(prerequisiteIDs) {
+		return course, failure.NotFound("prerequisite course")
+	}
+
+	graph, err := s.CourseRepository.ResolvePrerequisiteGraph()
+	if err != nil {
+		return
+	}
+
+	err = graph.CheckCycle(course.ID, prerequisiteIDs)
+	if err != nil {
+		return
+	}
+
+	err = s.CourseRepository.SetCoursePrerequisites(course.ID, prerequisites)
+	if err != nil {
+		return
+	}
+
+	return course.AttachPrerequisites(prerequisiteIDs), nil
+}
+
+// SoftDeleteCourse marks a Course as deleted by setting its `deletedAt` and `deletedBy` properties.
+func (s *CourseServiceImpl) SoftDeleteCourse(id uuid.UUID, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionDeleteCourse)
+	if err != nil {
+		return
+	}
+
+	err = course.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.CourseRepository.UpdateCourse(course)
+	return
+}
+
+// UpdateCourse updates a Course the given user may edit.
+func (s *CourseServiceImpl) UpdateCourse(id uuid.UUID, requestFormat CourseRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, id, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	err = course.Update(requestFormat, userID)
+	if err != nil {
+		return course, failure.BadRequest(err)
+	}
+
+	err = s.CourseRepository.UpdateCourse(course)
+	return
+}
+
+// attachCourseModules attaches the active Modules of a Course with their Lessons.
+func attachCourseModules(moduleRepository ModuleRepository, course Course) (Course, error) {
+	modules, err := moduleRepository.ResolveModulesByCourseIDs([]uuid.UUID{course.ID})
+	if err != nil {
+		return course, err
+	}
+
+	moduleIDs := make([]uuid.UUID, 0, len(modules))
+	for _, module := range modules {
+		moduleIDs = append(moduleIDs, module.ID)
+	}
+
+	lessons, err := moduleRepository.ResolveLessonsByModuleIDs(moduleIDs)
+	if err != nil {
+		return course, err
+	}
+
+	for i := range modules {
+		modules[i].AttachLessons(lessons)
+	}
+
+	return course.AttachModules(modules), nil
+}
+
+// resolveManagedCourse resolves a non-deleted Course and makes sure the given
+// user is its owner or an active Collaborator whose role has the permission.
+func resolveManagedCourse(collaboratorRepository CollaboratorRepository, courseRepository CourseRepository, id uuid.UUID, userID uuid.UUID, permission CoursePermission) (course Course, err error) {
+	course, err = courseRepository.ResolveCourseByID(id)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() {
+		return course, failure.NotFound("course")
+	}
+
+	if course.IsOwnedBy(userID) {
+		return
+	}
+
+	collaborator, err := collaboratorRepository.ResolveCollaborator(id, userID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return course, err
+	}
+
+	if err != nil || !collaborator.Can(permission) {
+		return course, failure.Forbidden(fmt.Sprintf("your role on this course does not allow %s", permission))
+	}
+
+	return course, nil
+}
+
+// applyStudentSchedule locks the Lessons of a Course the student's Cohort has
+// not released yet.
+func (s *CourseServiceImpl) applyStudentSchedule(course Course, studentID uuid.UUID) (Course, error) {
+	schedule, ok, err := resolveStudentSchedule(s.EnrollmentRepository, s.CohortRepository, course.ID, studentID)
+	if err != nil || !ok {
+		return course, err
+	}
+
+	return schedule.Apply(course, time.Now()), nil
+}
@@ -0,0 +1,197 @@
+package course_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRandomUUID() uuid.UUID {
+	id, _ := uuid.NewV4()
+	return id
+}
+
+func TestCourseService(t *testing.T) {
+	ownerID := getRandomUUID()
+	existing := course.Course{
+		ID:        getRandomUUID(),
+		UserID:    ownerID,
+		Title:     "Backend Bootcamp",
+		Content:   "Go, MySQL and friends",
+		Status:    course.CourseStatusDraft,
+		CreatedAt: time.Now(),
+		CreatedBy: ownerID,
+	}
+
+	t.Run("resolveCourseByID", func(t *testing.T) {
+		deleted := existing
+		deleted.DeletedAt = null.TimeFrom(time.Now())
+		deleted.DeletedBy = nuuid.From(ownerID)
+
+		tests := []struct {
+			name     string
+			returns  course.Course
+			wantCode int
+		}{
+			{name: "default", returns: existing},
+			{name: "deleted", returns: deleted, wantCode: http.StatusNotFound},
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				mockRepo := course_mock.NewMockCourseRepository(ctrl)
+				s := &course.CourseServiceImpl{CourseRepository: mockRepo}
+				mockRepo.EXPECT().ResolveCourseByID(test.returns.ID).Return(test.returns, nil)
+
+				got, err := s.ResolveCourseByID(test.returns.ID, false)
+				if test.wantCode != 0 {
+					assert.Equal(t, test.wantCode, failure.GetCode(err))
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, test.returns.Title, got.Title)
+			})
+		}
+	})
+
+	t.Run("updateCourse", func(t *testing.T) {
+		request := course.CourseRequestFormat{Title: "Backend Bootcamp II", Content: "More Go"}
+
+		tests := []struct {
+			name     string
+			userID   uuid.UUID
+			wantCode int
+		}{
+			{name: "owner", userID: ownerID},
+			{name: "not owner", userID: getRandomUUID(), wantCode: http.StatusForbidden},
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+				mockRepo := course_mock.NewMockCourseRepository(ctrl)
+				s := &course.CourseServiceImpl{CollaboratorRepository: mockCollaboratorRepo, CourseRepository: mockRepo}
+				mockRepo.EXPECT().ResolveCourseByID(existing.ID).Return(existing, nil)
+				if test.wantCode == 0 {
+					mockRepo.EXPECT().UpdateCourse(gomock.Any()).Return(nil)
+				} else {
+					mockCollaboratorRepo.EXPECT().ResolveCollaborator(existing.ID, test.userID).Return(course.Collaborator{}, failure.NotFound("collaborator"))
+				}
+
+				got, err := s.UpdateCourse(existing.ID, request, test.userID)
+				if test.wantCode != 0 {
+					assert.Equal(t, test.wantCode, failure.GetCode(err))
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, request.Title, got.Title)
+				assert.Equal(t, ownerID, *got.UpdatedBy.Ptr())
+			})
+		}
+	})
+}
+
+func TestCourseServiceSearchCourses(t *testing.T) {
+	ownerID := getRandomUUID()
+	published := course.Course{
+		ID:               getRandomUUID(),
+		UserID:           ownerID,
+		Title:            "Go basics, edited draft",
+		Content:          "Not published yet",
+		Status:           course.CourseStatusPublished,
+		PublishedVersion: null.IntFrom(1),
+		CreatedAt:        time.Now(),
+		CreatedBy:        ownerID,
+	}
+	version := course.CourseVersion{
+		CourseID: published.ID,
+		Version:  1,
+		Snapshot: &course.CourseSnapshot{Title: "Go basics", Content: "Learn Go from scratch"},
+	}
+	params := course.CourseSearchParameters{Query: "go", Limit: 10}
+
+	t.Run("students find courses as published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSearchRepo := course_mock.NewMockSearchRepository(ctrl)
+		mockPublishingRepo := course_mock.NewMockPublishingRepository(ctrl)
+		s := &course.CourseServiceImpl{SearchRepository: mockSearchRepo, PublishingRepository: mockPublishingRepo}
+		mockSearchRepo.EXPECT().SearchCourses(gomock.Any()).DoAndReturn(
+			func(params course.CourseSearchParameters) (course.CourseSearchResult, error) {
+				assert.True(t, params.VisibleOnly)
+				return course.CourseSearchResult{Courses: []course.CourseSearchHit{{Course: published, Relevance: 1.5}}, Total: 1}, nil
+			})
+		mockPublishingRepo.EXPECT().ResolveVersion(published.ID, 1).Return(version, nil)
+
+		got, err := s.SearchCourses(params, shared.RoleStudent)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Go basics", got.Courses[0].Title)
+		assert.Equal(t, 1.5, got.Courses[0].Relevance)
+		assert.Empty(t, got.Courses[0].Modules)
+	})
+
+	t.Run("teachers find every course as drafted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSearchRepo := course_mock.NewMockSearchRepository(ctrl)
+		s := &course.CourseServiceImpl{SearchRepository: mockSearchRepo}
+		mockSearchRepo.EXPECT().SearchCourses(gomock.Any()).DoAndReturn(
+			func(params course.CourseSearchParameters) (course.CourseSearchResult, error) {
+				assert.False(t, params.VisibleOnly)
+				return course.CourseSearchResult{Courses: []course.CourseSearchHit{{Course: published}}, Total: 1}, nil
+			})
+
+		got, err := s.SearchCourses(params, shared.RoleTeacher)
+
+		assert.NoError(t, err)
+		assert.Equal(t, published.Title, got.Courses[0].Title)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params course.CourseSearchParameters
+		}{
+			{name: "unknown status", params: course.CourseSearchParameters{Statuses: []course.CourseStatus{"hidden"}, Limit: 10}},
+			{name: "limit too large", params: course.CourseSearchParameters{Limit: 101}},
+			{name: "tag too long", params: course.CourseSearchParameters{Tags: []string{strings.Repeat("a", 65)}, Limit: 10}},
+			{name: "reversed date range", params: course.CourseSearchParameters{
+				CreatedFrom: null.TimeFrom(time.Now()),
+				CreatedTo:   null.TimeFrom(time.Now().AddDate(0, 0, -1)),
+				Limit:       10,
+			}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				s := &course.CourseServiceImpl{}
+
+				_, err := s.SearchCourses(test.params, shared.RoleTeacher)
+
+				assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+			})
+		}
+	})
+}
@@ -0,0 +1,618 @@
+package course
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+var (
+	errThreadLocked   = failure.Conflict("reply", "thread", "the thread is locked")
+	errAlreadyUpvoted = failure.Conflict("upvote", "discussion", "this was already upvoted by the user")
+)
+
+// UpvoteSubjectType is the kind of discussion entity an Upvote is for.
+type UpvoteSubjectType string
+
+const (
+	// UpvoteSubjectThread is an Upvote of a Thread.
+	UpvoteSubjectThread UpvoteSubjectType = "thread"
+	// UpvoteSubjectPost is an Upvote of a Post.
+	UpvoteSubjectPost UpvoteSubjectType = "post"
+)
+
+//// Threads
+
+// Thread is a discussion started on a Course, or on one of its Lessons.
+type Thread struct {
+	ID             uuid.UUID   `db:"id" validate:"required"`
+	CourseID       uuid.UUID   `db:"course_id" validate:"required"`
+	LessonID       nuuid.NUUID `db:"lesson_id"`
+	Title          string      `db:"title" validate:"required,max=255"`
+	Body           string      `db:"body" validate:"required,max=20000"`
+	ReplyCount     int         `db:"reply_count"`
+	UpvoteCount    int         `db:"upvote_count"`
+	LastActivityAt time.Time   `db:"last_activity_at" validate:"required"`
+	PinnedAt       null.Time   `db:"pinned_at"`
+	PinnedBy       nuuid.NUUID `db:"pinned_by"`
+	ResolvedAt     null.Time   `db:"resolved_at"`
+	ResolvedBy     nuuid.NUUID `db:"resolved_by"`
+	LockedAt       null.Time   `db:"locked_at"`
+	LockedBy       nuuid.NUUID `db:"locked_by"`
+	HiddenAt       null.Time   `db:"hidden_at"`
+	HiddenBy       nuuid.NUUID `db:"hidden_by"`
+	CreatedAt      time.Time   `db:"created_at" validate:"required"`
+	CreatedBy      uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt      null.Time   `db:"updated_at"`
+	UpdatedBy      nuuid.NUUID `db:"updated_by"`
+	DeletedAt      null.Time   `db:"deleted_at"`
+	DeletedBy      nuuid.NUUID `db:"deleted_by"`
+	Posts          []Post      `db:"-"`
+	// Upvoted tells whether the user reading the Thread upvoted it.
+	Upvoted bool `db:"-"`
+}
+
+// NewThreadFromRequestFormat starts a Thread on a Course, or on a Lesson of it.
+func (t Thread) NewThreadFromRequestFormat(courseID uuid.UUID, req ThreadRequestFormat, userID uuid.UUID) (newThread Thread, err error) {
+	threadID, _ := uuid.NewV4()
+	now := time.Now()
+	newThread = Thread{
+		ID:             threadID,
+		CourseID:       courseID,
+		LessonID:       req.LessonID,
+		Title:          strings.TrimSpace(req.Title),
+		Body:           req.Body,
+		LastActivityAt: now,
+		CreatedAt:      now,
+		CreatedBy:      userID,
+	}
+
+	err = newThread.Validate()
+	return
+}
+
+// AttachPosts arranges the Posts of a Thread into their reply tree, oldest
+// first on every level. Posts whose parent is missing are kept at the top.
+func (t *Thread) AttachPosts(posts []Post) Thread {
+	children := make(map[uuid.UUID][]Post)
+	known := make(map[uuid.UUID]bool)
+	for _, post := range posts {
+		known[post.ID] = true
+	}
+
+	var roots []Post
+	for _, post := range posts {
+		if post.ThreadID != t.ID {
+			continue
+		}
+
+		if post.ParentID.Valid && known[post.ParentID.UUID] {
+			children[post.ParentID.UUID] = append(children[post.ParentID.UUID], post)
+			continue
+		}
+
+		roots = append(roots, post)
+	}
+
+	var attach func(posts []Post) []Post
+	attach = func(posts []Post) []Post {
+		for i := range posts {
+			posts[i].Replies = attach(children[posts[i].ID])
+		}
+
+		return posts
+	}
+
+	t.Posts = attach(roots)
+	return *t
+}
+
+// Hide hides a Thread from everyone but the moderators of its Course.
+func (t *Thread) Hide(hidden bool, userID uuid.UUID) {
+	if !hidden {
+		t.HiddenAt = null.Time{}
+		t.HiddenBy = nuuid.NUUID{}
+		return
+	}
+
+	if !t.IsHidden() {
+		t.HiddenAt = null.TimeFrom(time.Now())
+		t.HiddenBy = nuuid.From(userID)
+	}
+}
+
+// IsDeleted checks whether a Thread is marked as deleted.
+func (t *Thread) IsDeleted() (deleted bool) {
+	return t.DeletedAt.Valid && t.DeletedBy.Valid
+}
+
+// IsHidden checks whether a Thread was hidden by a moderator.
+func (t *Thread) IsHidden() bool {
+	return t.HiddenAt.Valid
+}
+
+// IsLocked checks whether a Thread was locked against new replies.
+func (t *Thread) IsLocked() bool {
+	return t.LockedAt.Valid
+}
+
+// IsPinned checks whether a Thread is pinned to the top of its Course's list.
+func (t *Thread) IsPinned() bool {
+	return t.PinnedAt.Valid
+}
+
+// IsResolved checks whether the question of a Thread was answered.
+func (t *Thread) IsResolved() bool {
+	return t.ResolvedAt.Valid
+}
+
+// Lock locks a Thread against new replies, or unlocks it.
+func (t *Thread) Lock(locked bool, userID uuid.UUID) {
+	if !locked {
+		t.LockedAt = null.Time{}
+		t.LockedBy = nuuid.NUUID{}
+		return
+	}
+
+	if !t.IsLocked() {
+		t.LockedAt = null.TimeFrom(time.Now())
+		t.LockedBy = nuuid.From(userID)
+	}
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (t Thread) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.ToResponseFormat())
+}
+
+// Pin pins a Thread to the top of its Course's list, or unpins it.
+func (t *Thread) Pin(pinned bool, userID uuid.UUID) {
+	if !pinned {
+		t.PinnedAt = null.Time{}
+		t.PinnedBy = nuuid.NUUID{}
+		return
+	}
+
+	if !t.IsPinned() {
+		t.PinnedAt = null.TimeFrom(time.Now())
+		t.PinnedBy = nuuid.From(userID)
+	}
+}
+
+// Redact blanks out the Posts of a Thread the given reader may not see.
+func (t *Thread) Redact(moderator bool) Thread {
+	var redact func(posts []Post)
+	redact = func(posts []Post) {
+		for i := range posts {
+			posts[i].Redact(moderator)
+			redact(posts[i].Replies)
+		}
+	}
+
+	redact(t.Posts)
+	return *t
+}
+
+// Resolve marks the question of a Thread as answered, or as open again.
+func (t *Thread) Resolve(resolved bool, userID uuid.UUID) {
+	if !resolved {
+		t.ResolvedAt = null.Time{}
+		t.ResolvedBy = nuuid.NUUID{}
+		return
+	}
+
+	if !t.IsResolved() {
+		t.ResolvedAt = null.TimeFrom(time.Now())
+		t.ResolvedBy = nuuid.From(userID)
+	}
+}
+
+// SoftDelete marks a Thread as deleted.
+func (t *Thread) SoftDelete(userID uuid.UUID) (err error) {
+	if t.IsDeleted() {
+		return failure.Conflict("softDelete", "thread", "already marked as deleted")
+	}
+
+	t.DeletedAt = null.TimeFrom(time.Now())
+	t.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// Update updates the title and body of a Thread.
+func (t *Thread) Update(req ThreadUpdateRequestFormat, userID uuid.UUID) (err error) {
+	t.Title = strings.TrimSpace(req.Title)
+	t.Body = req.Body
+	t.UpdatedAt = null.TimeFrom(time.Now())
+	t.UpdatedBy = nuuid.From(userID)
+
+	return t.Validate()
+}
+
+// Validate validates the entity.
+func (t *Thread) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(t)
+}
+
+// ToResponseFormat converts this Thread to its response format.
+func (t Thread) ToResponseFormat() ThreadResponseFormat {
+	resp := ThreadResponseFormat{
+		ID:             t.ID,
+		CourseID:       t.CourseID,
+		LessonID:       t.LessonID.Ptr(),
+		Title:          t.Title,
+		Body:           t.Body,
+		ReplyCount:     t.ReplyCount,
+		UpvoteCount:    t.UpvoteCount,
+		Upvoted:        t.Upvoted,
+		LastActivityAt: t.LastActivityAt,
+		Pinned:         t.IsPinned(),
+		Resolved:       t.IsResolved(),
+		ResolvedBy:     t.ResolvedBy.Ptr(),
+		Locked:         t.IsLocked(),
+		Hidden:         t.IsHidden(),
+		CreatedAt:      t.CreatedAt,
+		CreatedBy:      t.CreatedBy,
+		UpdatedAt:      t.UpdatedAt,
+	}
+
+	for _, post := range t.Posts {
+		resp.Posts = append(resp.Posts, post.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// ThreadCursor points just past the last Thread of a page, in the order
+// Threads are listed in: most recently active first.
+type ThreadCursor struct {
+	LastActivityAt time.Time
+	ID             uuid.UUID
+}
+
+// NewThreadCursor creates the ThreadCursor following the given Thread.
+func NewThreadCursor(thread Thread) ThreadCursor {
+	return ThreadCursor{LastActivityAt: thread.LastActivityAt, ID: thread.ID}
+}
+
+// ParseThreadCursor parses a ThreadCursor encoded with Encode.
+func ParseThreadCursor(encoded string) (cursor ThreadCursor, err error) {
+	errInvalidCursor := failure.BadRequestFromString("the cursor is invalid")
+
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	parts := strings.SplitN(string(decoded), "|", 2)
+	if len(parts) != 2 {
+		return cursor, errInvalidCursor
+	}
+
+	cursor.LastActivityAt, err = time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	cursor.ID, err = uuid.FromString(parts[1])
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Encode encodes a ThreadCursor into an opaque, URL-safe string.
+func (c ThreadCursor) Encode() string {
+	raw := fmt.Sprintf("%s|%s", c.LastActivityAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ThreadQueryParameters filters the Threads of a Course.
+type ThreadQueryParameters struct {
+	CourseID uuid.UUID
+	// LessonID limits the Threads to those started on a Lesson.
+	LessonID nuuid.NUUID
+	// Pinned lists the pinned Threads instead of the others.
+	Pinned bool
+	// IncludeHidden includes the Threads hidden by moderators.
+	IncludeHidden bool
+	After         *ThreadCursor
+	Limit         int `validate:"min=1,max=100"`
+}
+
+// Validate validates the query parameters.
+func (p *ThreadQueryParameters) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(p)
+}
+
+// ThreadPage is a page of the Threads of a Course. Pinned Threads come with
+// the first page only.
+type ThreadPage struct {
+	Pinned     []Thread
+	Threads    []Thread
+	NextCursor null.String
+}
+
+// NewThreadPage creates a ThreadPage from one more Thread than the limit, the
+// extra one telling whether there is a next page.
+func NewThreadPage(pinned []Thread, threads []Thread, limit int) ThreadPage {
+	page := ThreadPage{Pinned: pinned, Threads: threads}
+	if len(threads) > limit {
+		page.Threads = threads[:limit]
+		page.NextCursor = null.StringFrom(NewThreadCursor(page.Threads[limit-1]).Encode())
+	}
+
+	return page
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (p ThreadPage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.ToResponseFormat())
+}
+
+// ToResponseFormat converts this ThreadPage to its response format.
+func (p ThreadPage) ToResponseFormat() ThreadPageResponseFormat {
+	resp := ThreadPageResponseFormat{
+		Pinned:     []ThreadResponseFormat{},
+		Threads:    []ThreadResponseFormat{},
+		NextCursor: p.NextCursor,
+	}
+
+	for _, thread := range p.Pinned {
+		resp.Pinned = append(resp.Pinned, thread.ToResponseFormat())
+	}
+
+	for _, thread := range p.Threads {
+		resp.Threads = append(resp.Threads, thread.ToResponseFormat())
+	}
+
+	return resp
+}
+
+//// Posts
+
+// Post is a reply in a Thread, either to the Thread itself or to another Post.
+type Post struct {
+	ID          uuid.UUID   `db:"id" validate:"required"`
+	ThreadID    uuid.UUID   `db:"thread_id" validate:"required"`
+	ParentID    nuuid.NUUID `db:"parent_id"`
+	Body        string      `db:"body" validate:"required,max=20000"`
+	UpvoteCount int         `db:"upvote_count"`
+	HiddenAt    null.Time   `db:"hidden_at"`
+	HiddenBy    nuuid.NUUID `db:"hidden_by"`
+	CreatedAt   time.Time   `db:"created_at" validate:"required"`
+	CreatedBy   uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt   null.Time   `db:"updated_at"`
+	UpdatedBy   nuuid.NUUID `db:"updated_by"`
+	DeletedAt   null.Time   `db:"deleted_at"`
+	DeletedBy   nuuid.NUUID `db:"deleted_by"`
+	Replies     []Post      `db:"-"`
+	// Upvoted tells whether the user reading the Post upvoted it.
+	Upvoted bool `db:"-"`
+	// Redacted is set when the body of a hidden or deleted Post is left out.
+	Redacted bool `db:"-"`
+}
+
+// NewPostFromRequestFormat creates a reply in a Thread. Replies to a Post
+// have to be in the same Thread.
+func (p Post) NewPostFromRequestFormat(thread Thread, parent *Post, req PostRequestFormat, userID uuid.UUID) (newPost Post, err error) {
+	if parent != nil && (parent.ThreadID != thread.ID || parent.IsDeleted()) {
+		return newPost, failure.BadRequestFromString("the post replied to is not in this thread")
+	}
+
+	postID, _ := uuid.NewV4()
+	newPost = Post{
+		ID:        postID,
+		ThreadID:  thread.ID,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	if parent != nil {
+		newPost.ParentID = nuuid.From(parent.ID)
+	}
+
+	err = newPost.Validate()
+	return
+}
+
+// Hide hides a Post from everyone but the moderators of its Course.
+func (p *Post) Hide(hidden bool, userID uuid.UUID) {
+	if !hidden {
+		p.HiddenAt = null.Time{}
+		p.HiddenBy = nuuid.NUUID{}
+		return
+	}
+
+	if !p.IsHidden() {
+		p.HiddenAt = null.TimeFrom(time.Now())
+		p.HiddenBy = nuuid.From(userID)
+	}
+}
+
+// IsDeleted checks whether a Post is marked as deleted.
+func (p *Post) IsDeleted() (deleted bool) {
+	return p.DeletedAt.Valid && p.DeletedBy.Valid
+}
+
+// IsHidden checks whether a Post was hidden by a moderator.
+func (p *Post) IsHidden() bool {
+	return p.HiddenAt.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (p Post) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.ToResponseFormat())
+}
+
+// Redact blanks out the body of a deleted Post, and of a hidden one unless
+// the reader moderates the Course. The Post itself stays so that its replies
+// keep their place in the tree.
+func (p *Post) Redact(moderator bool) {
+	if p.IsDeleted() || (p.IsHidden() && !moderator) {
+		p.Body = ""
+		p.Redacted = true
+	}
+}
+
+// SoftDelete marks a Post as deleted.
+func (p *Post) SoftDelete(userID uuid.UUID) (err error) {
+	if p.IsDeleted() {
+		return failure.Conflict("softDelete", "post", "already marked as deleted")
+	}
+
+	p.DeletedAt = null.TimeFrom(time.Now())
+	p.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// Update updates the body of a Post.
+func (p *Post) Update(req PostUpdateRequestFormat, userID uuid.UUID) (err error) {
+	p.Body = req.Body
+	p.UpdatedAt = null.TimeFrom(time.Now())
+	p.UpdatedBy = nuuid.From(userID)
+
+	return p.Validate()
+}
+
+// Validate validates the entity.
+func (p *Post) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(p)
+}
+
+// ToResponseFormat converts this Post to its response format.
+func (p Post) ToResponseFormat() PostResponseFormat {
+	resp := PostResponseFormat{
+		ID:          p.ID,
+		ThreadID:    p.ThreadID,
+		ParentID:    p.ParentID.Ptr(),
+		Body:        p.Body,
+		UpvoteCount: p.UpvoteCount,
+		Upvoted:     p.Upvoted,
+		Hidden:      p.IsHidden(),
+		Deleted:     p.IsDeleted(),
+		Redacted:    p.Redacted,
+		CreatedAt:   p.CreatedAt,
+		CreatedBy:   p.CreatedBy,
+		UpdatedAt:   p.UpdatedAt,
+		Replies:     []PostResponseFormat{},
+	}
+
+	for _, reply := range p.Replies {
+		resp.Replies = append(resp.Replies, reply.ToResponseFormat())
+	}
+
+	return resp
+}
+
+//// Upvotes
+
+// Upvote is a user's vote for a Thread or a Post. Each user upvotes each of
+// them once.
+type Upvote struct {
+	SubjectID   uuid.UUID         `db:"subject_id" validate:"required"`
+	SubjectType UpvoteSubjectType `db:"subject_type" validate:"required,oneof=thread post"`
+	UserID      uuid.UUID         `db:"user_id" validate:"required"`
+	CreatedAt   time.Time         `db:"created_at" validate:"required"`
+}
+
+// NewUpvote creates an Upvote. Users cannot upvote what they wrote.
+func NewUpvote(subjectType UpvoteSubjectType, subjectID uuid.UUID, authorID uuid.UUID, userID uuid.UUID) (upvote Upvote, err error) {
+	if authorID == userID {
+		return upvote, failure.BadRequestFromString("you cannot upvote your own " + string(subjectType))
+	}
+
+	return Upvote{
+		SubjectID:   subjectID,
+		SubjectType: subjectType,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+//// Request and Response Formats
+
+// ThreadRequestFormat represents a Thread's standard formatting for JSON deserializing.
+type ThreadRequestFormat struct {
+	LessonID nuuid.NUUID `json:"lessonID" swaggertype:"string"`
+	Title    string      `json:"title" validate:"required,max=255" example:"Why does the loop never end?"`
+	Body     string      `json:"body" validate:"required,max=20000"`
+}
+
+// ThreadUpdateRequestFormat represents the editable fields of a Thread.
+type ThreadUpdateRequestFormat struct {
+	Title string `json:"title" validate:"required,max=255"`
+	Body  string `json:"body" validate:"required,max=20000"`
+}
+
+// PostRequestFormat represents a Post's standard formatting for JSON deserializing.
+type PostRequestFormat struct {
+	// ParentID is the Post replied to; replies to the Thread itself leave it out.
+	ParentID nuuid.NUUID `json:"parentID" swaggertype:"string"`
+	Body     string      `json:"body" validate:"required,max=20000"`
+}
+
+// PostUpdateRequestFormat represents the editable fields of a Post.
+type PostUpdateRequestFormat struct {
+	Body string `json:"body" validate:"required,max=20000"`
+}
+
+// ThreadResponseFormat represents a Thread's standard formatting for JSON serializing.
+type ThreadResponseFormat struct {
+	ID             uuid.UUID            `json:"id"`
+	CourseID       uuid.UUID            `json:"courseID"`
+	LessonID       *uuid.UUID           `json:"lessonID"`
+	Title          string               `json:"title"`
+	Body           string               `json:"body"`
+	ReplyCount     int                  `json:"replyCount"`
+	UpvoteCount    int                  `json:"upvoteCount"`
+	Upvoted        bool                 `json:"upvoted"`
+	LastActivityAt time.Time            `json:"lastActivityAt"`
+	Pinned         bool                 `json:"pinned"`
+	Resolved       bool                 `json:"resolved"`
+	ResolvedBy     *uuid.UUID           `json:"resolvedBy"`
+	Locked         bool                 `json:"locked"`
+	Hidden         bool                 `json:"hidden"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	CreatedBy      uuid.UUID            `json:"createdBy"`
+	UpdatedAt      null.Time            `json:"updatedAt"`
+	Posts          []PostResponseFormat `json:"posts,omitempty"`
+}
+
+// ThreadPageResponseFormat represents a ThreadPage's standard formatting for JSON serializing.
+type ThreadPageResponseFormat struct {
+	Pinned     []ThreadResponseFormat `json:"pinned"`
+	Threads    []ThreadResponseFormat `json:"threads"`
+	NextCursor null.String            `json:"nextCursor" swaggertype:"string"`
+}
+
+// PostResponseFormat represents a Post's standard formatting for JSON serializing.
+type PostResponseFormat struct {
+	ID          uuid.UUID            `json:"id"`
+	ThreadID    uuid.UUID            `json:"threadID"`
+	ParentID    *uuid.UUID           `json:"parentID"`
+	Body        string               `json:"body"`
+	UpvoteCount int                  `json:"upvoteCount"`
+	Upvoted     bool                 `json:"upvoted"`
+	Hidden      bool                 `json:"hidden"`
+	Deleted     bool                 `json:"deleted"`
+	Redacted    bool                 `json:"redacted"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	CreatedBy   uuid.UUID            `json:"createdBy"`
+	UpdatedAt   null.Time            `json:"updatedAt"`
+	Replies     []PostResponseFormat `json:"replies" swaggertype:"array,object"`
+}
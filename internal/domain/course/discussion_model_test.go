@@ -0,0 +1,122 @@
+package course_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/stretchr/testify/assert"
+)
+
+func newThread(t *testing.T) course.Thread {
+	thread, err := course.Thread{}.NewThreadFromRequestFormat(getRandomUUID(), course.ThreadRequestFormat{
+		Title: "Why does the loop never end?",
+		Body:  "I followed the lesson, but the loop keeps running.",
+	}, getRandomUUID())
+	assert.NoError(t, err)
+
+	return thread
+}
+
+func TestThreadPosts(t *testing.T) {
+	thread := newThread(t)
+	authorID, moderatorID := getRandomUUID(), getRandomUUID()
+
+	first, err := course.Post{}.NewPostFromRequestFormat(thread, nil, course.PostRequestFormat{Body: "Check the condition."}, authorID)
+	assert.NoError(t, err)
+	second, err := course.Post{}.NewPostFromRequestFormat(thread, nil, course.PostRequestFormat{Body: "Same here."}, authorID)
+	assert.NoError(t, err)
+	reply, err := course.Post{}.NewPostFromRequestFormat(thread, &first, course.PostRequestFormat{Body: "That fixed it, thanks!"}, authorID)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, reply.ParentID.UUID)
+
+	t.Run("replies stay in their thread", func(t *testing.T) {
+		other := newThread(t)
+		_, err := course.Post{}.NewPostFromRequestFormat(other, &first, course.PostRequestFormat{Body: "Wrong thread."}, authorID)
+		assert.Error(t, err)
+	})
+
+	first.Hide(true, moderatorID)
+	assert.NoError(t, second.SoftDelete(authorID))
+	thread.AttachPosts([]course.Post{first, second, reply})
+
+	assert.Len(t, thread.Posts, 2)
+	assert.Len(t, thread.Posts[0].Replies, 1)
+	assert.Equal(t, reply.ID, thread.Posts[0].Replies[0].ID)
+
+	t.Run("moderators see hidden posts", func(t *testing.T) {
+		moderated := newThread(t)
+		moderated.ID = thread.ID
+		moderated.AttachPosts([]course.Post{first, second, reply})
+		moderated.Redact(true)
+
+		assert.Equal(t, "Check the condition.", moderated.Posts[0].Body)
+		assert.True(t, moderated.Posts[1].Redacted, "deleted posts are redacted for everyone")
+	})
+
+	thread.Redact(false)
+	assert.True(t, thread.Posts[0].Redacted)
+	assert.Empty(t, thread.Posts[0].Body)
+	assert.True(t, thread.Posts[1].Redacted)
+	assert.False(t, thread.Posts[0].Replies[0].Redacted)
+}
+
+func TestThreadModeration(t *testing.T) {
+	thread := newThread(t)
+	moderatorID := getRandomUUID()
+
+	thread.Pin(true, moderatorID)
+	thread.Lock(true, moderatorID)
+	thread.Resolve(true, thread.CreatedBy)
+	assert.True(t, thread.IsPinned())
+	assert.True(t, thread.IsLocked())
+	assert.True(t, thread.IsResolved())
+	assert.Equal(t, thread.CreatedBy, thread.ResolvedBy.UUID)
+
+	thread.Lock(false, moderatorID)
+	assert.False(t, thread.IsLocked())
+	assert.False(t, thread.LockedBy.Valid)
+
+	assert.NoError(t, thread.SoftDelete(moderatorID))
+	assert.Error(t, thread.SoftDelete(moderatorID))
+}
+
+func TestThreadCursor(t *testing.T) {
+	thread := newThread(t)
+	thread.LastActivityAt = time.Date(2026, time.October, 1, 9, 30, 0, 123456000, time.UTC)
+
+	cursor, err := course.ParseThreadCursor(course.NewThreadCursor(thread).Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, thread.LastActivityAt.Equal(cursor.LastActivityAt))
+	assert.Equal(t, thread.ID, cursor.ID)
+
+	_, err = course.ParseThreadCursor("not-a-cursor")
+	assert.Error(t, err)
+}
+
+func TestNewThreadPage(t *testing.T) {
+	threads := []course.Thread{newThread(t), newThread(t), newThread(t)}
+
+	page := course.NewThreadPage(nil, threads, 2)
+	assert.Len(t, page.Threads, 2)
+	assert.True(t, page.NextCursor.Valid)
+	cursor, err := course.ParseThreadCursor(page.NextCursor.String)
+	assert.NoError(t, err)
+	assert.Equal(t, threads[1].ID, cursor.ID)
+
+	last := course.NewThreadPage(nil, threads, 3)
+	assert.Len(t, last.Threads, 3)
+	assert.False(t, last.NextCursor.Valid)
+}
+
+func TestNewUpvote(t *testing.T) {
+	authorID := getRandomUUID()
+
+	_, err := course.NewUpvote(course.UpvoteSubjectPost, getRandomUUID(), authorID, authorID)
+	assert.Error(t, err)
+
+	upvote, err := course.NewUpvote(course.UpvoteSubjectPost, getRandomUUID(), authorID, getRandomUUID())
+	assert.NoError(t, err)
+	assert.Equal(t, course.UpvoteSubjectPost, upvote.SubjectType)
+}
@@ -0,0 +1,495 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source discussion_repository.go -destination mock/discussion_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	discussionQueries = struct {
+		selectThread     string
+		insertThread     string
+		updateThread     string
+		selectPost       string
+		insertPost       string
+		updatePost       string
+		recordReply      string
+		removeReply      string
+		insertUpvote     string
+		deleteUpvote     string
+		selectUpvotedIDs string
+		countUpvote      map[UpvoteSubjectType]string
+	}{
+		selectThread: `
+			SELECT
+				id,
+				course_id,
+				lesson_id,
+				title,
+				body,
+				reply_count,
+				upvote_count,
+				last_activity_at,
+				pinned_at,
+				pinned_by,
+				resolved_at,
+				resolved_by,
+				locked_at,
+				locked_by,
+				hidden_at,
+				hidden_by,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM discussion_threads
+		`,
+
+		insertThread: `
+			INSERT INTO discussion_threads (
+				id,
+				course_id,
+				lesson_id,
+				title,
+				body,
+				reply_count,
+				upvote_count,
+				last_activity_at,
+				created_at,
+				created_by
+			) VALUES (
+				:id,
+				:course_id,
+				:lesson_id,
+				:title,
+				:body,
+				:reply_count,
+				:upvote_count,
+				:last_activity_at,
+				:created_at,
+				:created_by
+			)
+		`,
+
+		updateThread: `
+			UPDATE discussion_threads
+			SET
+				title = :title,
+				body = :body,
+				pinned_at = :pinned_at,
+				pinned_by = :pinned_by,
+				resolved_at = :resolved_at,
+				resolved_by = :resolved_by,
+				locked_at = :locked_at,
+				locked_by = :locked_by,
+				hidden_at = :hidden_at,
+				hidden_by = :hidden_by,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		selectPost: `
+			SELECT
+				id,
+				thread_id,
+				parent_id,
+				body,
+				upvote_count,
+				hidden_at,
+				hidden_by,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM discussion_posts
+		`,
+
+		insertPost: `
+			INSERT INTO discussion_posts (
+				id,
+				thread_id,
+				parent_id,
+				body,
+				upvote_count,
+				created_at,
+				created_by
+			) VALUES (
+				:id,
+				:thread_id,
+				:parent_id,
+				:body,
+				:upvote_count,
+				:created_at,
+				:created_by
+			)
+		`,
+
+		updatePost: `
+			UPDATE discussion_posts
+			SET
+				body = :body,
+				hidden_at = :hidden_at,
+				hidden_by = :hidden_by,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		recordReply: `
+			UPDATE discussion_threads
+			SET
+				reply_count = reply_count + 1,
+				last_activity_at = :created_at
+			WHERE id = :thread_id
+		`,
+
+		removeReply: `
+			UPDATE discussion_threads
+			SET reply_count = GREATEST(reply_count - 1, 0)
+			WHERE id = ?
+		`,
+
+		insertUpvote: `
+			INSERT IGNORE INTO discussion_upvotes (
+				subject_id,
+				subject_type,
+				user_id,
+				created_at
+			) VALUES (
+				:subject_id,
+				:subject_type,
+				:user_id,
+				:created_at
+			)
+		`,
+
+		deleteUpvote: `
+			DELETE FROM discussion_upvotes
+			WHERE subject_id = ? AND user_id = ?
+		`,
+
+		selectUpvotedIDs: `
+			SELECT subject_id
+			FROM discussion_upvotes
+			WHERE user_id = ? AND subject_id IN (?)
+		`,
+
+		// countUpvote adds the given delta to the upvote count of a subject
+		countUpvote: map[UpvoteSubjectType]string{
+			UpvoteSubjectThread: `
+				UPDATE discussion_threads
+				SET upvote_count = GREATEST(upvote_count + ?, 0)
+				WHERE id = ?
+			`,
+			UpvoteSubjectPost: `
+				UPDATE discussion_posts
+				SET upvote_count = GREATEST(upvote_count + ?, 0)
+				WHERE id = ?
+			`,
+		},
+	}
+)
+
+// DiscussionRepository is the repository for the discussion Threads of Courses.
+type DiscussionRepository interface {
+	CreatePost(post Post) (err error)
+	CreateThread(thread Thread) (err error)
+	CreateUpvote(upvote Upvote) (err error)
+	DeletePost(post Post) (err error)
+	DeleteUpvote(subjectType UpvoteSubjectType, subjectID uuid.UUID, userID uuid.UUID) (err error)
+	ResolvePostByID(id uuid.UUID) (post Post, err error)
+	ResolvePostsByThreadID(threadID uuid.UUID) (posts []Post, err error)
+	ResolveThreadByID(id uuid.UUID) (thread Thread, err error)
+	ResolveThreads(params ThreadQueryParameters) (threads []Thread, err error)
+	ResolveUpvotedIDs(userID uuid.UUID, subjectIDs []uuid.UUID) (upvotedIDs []uuid.UUID, err error)
+	UpdatePost(post Post) (err error)
+	UpdateThread(thread Thread) (err error)
+}
+
+// DiscussionRepositoryMySQL is the MySQL-backed implementation of DiscussionRepository.
+type DiscussionRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideDiscussionRepositoryMySQL is the provider for this repository.
+func ProvideDiscussionRepositoryMySQL(db *infras.MySQLConn) *DiscussionRepositoryMySQL {
+	s := new(DiscussionRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreatePost creates a Post and records it as the latest activity of its Thread.
+func (r *DiscussionRepositoryMySQL) CreatePost(post Post) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, discussionQueries.insertPost, post); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, discussionQueries.recordReply, post); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateThread creates a Thread.
+func (r *DiscussionRepositoryMySQL) CreateThread(thread Thread) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, discussionQueries.insertThread, thread); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateUpvote records an Upvote and counts it on its subject. It fails with
+// a conflict when the user upvoted the subject already.
+func (r *DiscussionRepositoryMySQL) CreateUpvote(upvote Upvote) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		result, err := tx.NamedExec(discussionQueries.insertUpvote, upvote)
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if inserted == 0 {
+			e <- errAlreadyUpvoted
+			return
+		}
+
+		_, err = tx.Exec(discussionQueries.countUpvote[upvote.SubjectType], 1, upvote.SubjectID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// DeletePost soft-deletes a Post, no longer counting it as a reply to its Thread.
+func (r *DiscussionRepositoryMySQL) DeletePost(post Post) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, discussionQueries.updatePost, post); err != nil {
+			e <- err
+			return
+		}
+
+		_, err := tx.Exec(discussionQueries.removeReply, post.ThreadID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// DeleteUpvote withdraws a user's Upvote of a subject.
+func (r *DiscussionRepositoryMySQL) DeleteUpvote(subjectType UpvoteSubjectType, subjectID uuid.UUID, userID uuid.UUID) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		result, err := tx.Exec(discussionQueries.deleteUpvote, subjectID.String(), userID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if deleted == 0 {
+			e <- failure.NotFound("upvote")
+			return
+		}
+
+		_, err = tx.Exec(discussionQueries.countUpvote[subjectType], -1, subjectID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolvePostByID resolves a Post by its ID.
+func (r *DiscussionRepositoryMySQL) ResolvePostByID(id uuid.UUID) (post Post, err error) {
+	err = r.DB.Read.Get(&post, discussionQueries.selectPost+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("post")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolvePostsByThreadID resolves every Post of a Thread, deleted ones
+// included, oldest first.
+func (r *DiscussionRepositoryMySQL) ResolvePostsByThreadID(threadID uuid.UUID) (posts []Post, err error) {
+	err = r.DB.Read.Select(
+		&posts,
+		discussionQueries.selectPost+" WHERE thread_id = ? ORDER BY created_at, id",
+		threadID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveThreadByID resolves a Thread by its ID.
+func (r *DiscussionRepositoryMySQL) ResolveThreadByID(id uuid.UUID) (thread Thread, err error) {
+	err = r.DB.Read.Get(&thread, discussionQueries.selectThread+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("thread")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveThreads resolves the non-deleted Threads of a Course, most recently
+// active first. Unpinned Threads are resolved a page at a time, with one
+// Thread more than the limit to tell whether there is a next page; pinned
+// Threads are all resolved at once.
+func (r *DiscussionRepositoryMySQL) ResolveThreads(params ThreadQueryParameters) (threads []Thread, err error) {
+	query := discussionQueries.selectThread + " WHERE course_id = ? AND deleted_at IS NULL"
+	args := []interface{}{params.CourseID.String()}
+
+	if params.LessonID.Valid {
+		query += " AND lesson_id = ?"
+		args = append(args, params.LessonID.UUID.String())
+	}
+
+	if !params.IncludeHidden {
+		query += " AND hidden_at IS NULL"
+	}
+
+	if params.Pinned {
+		query += " AND pinned_at IS NOT NULL ORDER BY pinned_at DESC, id DESC"
+	} else {
+		query += " AND pinned_at IS NULL"
+
+		if params.After != nil {
+			query += " AND (last_activity_at < ? OR (last_activity_at = ? AND id < ?))"
+			args = append(args, params.After.LastActivityAt, params.After.LastActivityAt, params.After.ID.String())
+		}
+
+		query += " ORDER BY last_activity_at DESC, id DESC LIMIT ?"
+		args = append(args, params.Limit+1)
+	}
+
+	err = r.DB.Read.Select(&threads, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveUpvotedIDs resolves which of the given subjects a user upvoted.
+func (r *DiscussionRepositoryMySQL) ResolveUpvotedIDs(userID uuid.UUID, subjectIDs []uuid.UUID) (upvotedIDs []uuid.UUID, err error) {
+	if len(subjectIDs) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(discussionQueries.selectUpvotedIDs, userID.String(), subjectIDs)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&upvotedIDs, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdatePost updates a Post.
+func (r *DiscussionRepositoryMySQL) UpdatePost(post Post) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, discussionQueries.updatePost, post); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateThread updates a Thread.
+func (r *DiscussionRepositoryMySQL) UpdateThread(thread Thread) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, discussionQueries.updateThread, thread); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *DiscussionRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
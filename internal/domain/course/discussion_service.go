@@ -0,0 +1,513 @@
+package course
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// DiscussionService is the service interface for the discussion Threads of Courses.
+type DiscussionService interface {
+	CreatePost(threadID uuid.UUID, requestFormat PostRequestFormat, userID uuid.UUID, role string) (post Post, err error)
+	CreateThread(courseID uuid.UUID, requestFormat ThreadRequestFormat, userID uuid.UUID, role string) (thread Thread, err error)
+	HidePost(id uuid.UUID, hidden bool, userID uuid.UUID) (post Post, err error)
+	HideThread(id uuid.UUID, hidden bool, userID uuid.UUID) (thread Thread, err error)
+	LockThread(id uuid.UUID, locked bool, userID uuid.UUID) (thread Thread, err error)
+	PinThread(id uuid.UUID, pinned bool, userID uuid.UUID) (thread Thread, err error)
+	RemoveUpvote(subjectType UpvoteSubjectType, id uuid.UUID, userID uuid.UUID, role string) (err error)
+	ResolveThreadByID(id uuid.UUID, userID uuid.UUID, role string) (thread Thread, err error)
+	ResolveThreads(params ThreadQueryParameters, userID uuid.UUID, role string) (page ThreadPage, err error)
+	SetThreadResolved(id uuid.UUID, resolved bool, userID uuid.UUID, role string) (thread Thread, err error)
+	SoftDeletePost(id uuid.UUID, userID uuid.UUID, role string) (post Post, err error)
+	SoftDeleteThread(id uuid.UUID, userID uuid.UUID, role string) (thread Thread, err error)
+	UpdatePost(id uuid.UUID, requestFormat PostUpdateRequestFormat, userID uuid.UUID, role string) (post Post, err error)
+	UpdateThread(id uuid.UUID, requestFormat ThreadUpdateRequestFormat, userID uuid.UUID, role string) (thread Thread, err error)
+	Upvote(subjectType UpvoteSubjectType, id uuid.UUID, userID uuid.UUID, role string) (upvote Upvote, err error)
+}
+
+// DiscussionServiceImpl is the service implementation for the discussion Threads of Courses.
+type DiscussionServiceImpl struct {
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	DiscussionRepository   DiscussionRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	Config                 *configs.Config
+}
+
+// ProvideDiscussionServiceImpl is the provider for this service.
+func ProvideDiscussionServiceImpl(
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	discussionRepository DiscussionRepository,
+	enrollmentRepository EnrollmentRepository,
+	moduleRepository ModuleRepository,
+	config *configs.Config) *DiscussionServiceImpl {
+	s := new(DiscussionServiceImpl)
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.DiscussionRepository = discussionRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.Config = config
+
+	return s
+}
+
+// CreatePost replies to a Thread, or to one of its Posts. Only moderators can
+// reply to locked Threads.
+func (s *DiscussionServiceImpl) CreatePost(threadID uuid.UUID, requestFormat PostRequestFormat, userID uuid.UUID, role string) (post Post, err error) {
+	thread, moderator, err := s.resolveThread(threadID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.IsLocked() && !moderator {
+		return post, errThreadLocked
+	}
+
+	var parent *Post
+	if requestFormat.ParentID.Valid {
+		resolved, err := s.DiscussionRepository.ResolvePostByID(requestFormat.ParentID.UUID)
+		if err != nil {
+			return post, err
+		}
+
+		parent = &resolved
+	}
+
+	post, err = Post{}.NewPostFromRequestFormat(thread, parent, requestFormat, userID)
+	if err != nil {
+		return post, failure.BadRequest(err)
+	}
+
+	err = s.DiscussionRepository.CreatePost(post)
+	return
+}
+
+// CreateThread starts a Thread on a Course, or on one of its Lessons.
+func (s *DiscussionServiceImpl) CreateThread(courseID uuid.UUID, requestFormat ThreadRequestFormat, userID uuid.UUID, role string) (thread Thread, err error) {
+	thread, err = Thread{}.NewThreadFromRequestFormat(courseID, requestFormat, userID)
+	if err != nil {
+		return thread, failure.BadRequest(err)
+	}
+
+	_, _, err = s.resolveDiscussionAccess(courseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.LessonID.Valid {
+		lesson, err := resolveLesson(s.ModuleRepository, thread.LessonID.UUID)
+		if err != nil {
+			return thread, err
+		}
+
+		if lesson.CourseID != courseID {
+			return thread, failure.BadRequestFromString("the lesson is not part of this course")
+		}
+	}
+
+	err = s.DiscussionRepository.CreateThread(thread)
+	return
+}
+
+// HidePost hides a Post from everyone but the moderators of its Course, or
+// shows it again.
+func (s *DiscussionServiceImpl) HidePost(id uuid.UUID, hidden bool, userID uuid.UUID) (post Post, err error) {
+	post, thread, err := s.resolvePost(id, userID, shared.RoleTeacher)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, thread.CourseID, userID, PermissionModerateDiscussions)
+	if err != nil {
+		return
+	}
+
+	post.Hide(hidden, userID)
+	err = s.DiscussionRepository.UpdatePost(post)
+	return
+}
+
+// HideThread hides a Thread from everyone but the moderators of its Course, or
+// shows it again.
+func (s *DiscussionServiceImpl) HideThread(id uuid.UUID, hidden bool, userID uuid.UUID) (thread Thread, err error) {
+	thread, err = s.resolveModeratedThread(id, userID)
+	if err != nil {
+		return
+	}
+
+	thread.Hide(hidden, userID)
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// LockThread locks a Thread against new replies, or unlocks it.
+func (s *DiscussionServiceImpl) LockThread(id uuid.UUID, locked bool, userID uuid.UUID) (thread Thread, err error) {
+	thread, err = s.resolveModeratedThread(id, userID)
+	if err != nil {
+		return
+	}
+
+	thread.Lock(locked, userID)
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// PinThread pins a Thread to the top of its Course's list, or unpins it.
+func (s *DiscussionServiceImpl) PinThread(id uuid.UUID, pinned bool, userID uuid.UUID) (thread Thread, err error) {
+	thread, err = s.resolveModeratedThread(id, userID)
+	if err != nil {
+		return
+	}
+
+	thread.Pin(pinned, userID)
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// RemoveUpvote withdraws the user's Upvote of a Thread or a Post.
+func (s *DiscussionServiceImpl) RemoveUpvote(subjectType UpvoteSubjectType, id uuid.UUID, userID uuid.UUID, role string) (err error) {
+	_, err = s.resolveUpvoteSubject(subjectType, id, userID, role)
+	if err != nil {
+		return
+	}
+
+	return s.DiscussionRepository.DeleteUpvote(subjectType, id, userID)
+}
+
+// ResolveThreadByID resolves a Thread with its Posts arranged in their reply
+// tree. Hidden Posts are only shown to moderators, and deleted ones to nobody;
+// both keep their place in the tree.
+func (s *DiscussionServiceImpl) ResolveThreadByID(id uuid.UUID, userID uuid.UUID, role string) (thread Thread, err error) {
+	thread, moderator, err := s.resolveThread(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	posts, err := s.DiscussionRepository.ResolvePostsByThreadID(thread.ID)
+	if err != nil {
+		return
+	}
+
+	ids := []uuid.UUID{thread.ID}
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	upvoted, err := s.resolveUpvoted(userID, ids)
+	if err != nil {
+		return
+	}
+
+	thread.Upvoted = upvoted[thread.ID]
+	for i := range posts {
+		posts[i].Upvoted = upvoted[posts[i].ID]
+	}
+
+	thread.AttachPosts(posts)
+	return thread.Redact(moderator), nil
+}
+
+// ResolveThreads resolves a page of the Threads of a Course. Pinned Threads
+// come with the first page. Hidden Threads are only listed to moderators who
+// ask for them.
+func (s *DiscussionServiceImpl) ResolveThreads(params ThreadQueryParameters, userID uuid.UUID, role string) (page ThreadPage, err error) {
+	err = params.Validate()
+	if err != nil {
+		return page, failure.BadRequest(err)
+	}
+
+	_, moderator, err := s.resolveDiscussionAccess(params.CourseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if params.IncludeHidden && !moderator {
+		return page, failure.Forbidden(fmt.Sprintf("your role on this course does not allow %s", PermissionModerateDiscussions))
+	}
+
+	var pinned []Thread
+	if params.After == nil {
+		pinnedParams := params
+		pinnedParams.Pinned = true
+		pinned, err = s.DiscussionRepository.ResolveThreads(pinnedParams)
+		if err != nil {
+			return
+		}
+	}
+
+	params.Pinned = false
+	threads, err := s.DiscussionRepository.ResolveThreads(params)
+	if err != nil {
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, thread := range append(append([]Thread{}, pinned...), threads...) {
+		ids = append(ids, thread.ID)
+	}
+
+	upvoted, err := s.resolveUpvoted(userID, ids)
+	if err != nil {
+		return
+	}
+
+	for i := range pinned {
+		pinned[i].Upvoted = upvoted[pinned[i].ID]
+	}
+
+	for i := range threads {
+		threads[i].Upvoted = upvoted[threads[i].ID]
+	}
+
+	return NewThreadPage(pinned, threads, params.Limit), nil
+}
+
+// SetThreadResolved marks the question of a Thread as answered, or as open
+// again. The author of the Thread and its moderators can do this.
+func (s *DiscussionServiceImpl) SetThreadResolved(id uuid.UUID, resolved bool, userID uuid.UUID, role string) (thread Thread, err error) {
+	thread, moderator, err := s.resolveThread(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.CreatedBy != userID && !moderator {
+		return thread, failure.Forbidden("only the author or a moderator can resolve this thread")
+	}
+
+	thread.Resolve(resolved, userID)
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// SoftDeletePost marks a Post as deleted. Its author and the moderators of its
+// Course can do this.
+func (s *DiscussionServiceImpl) SoftDeletePost(id uuid.UUID, userID uuid.UUID, role string) (post Post, err error) {
+	post, thread, err := s.resolvePost(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if post.CreatedBy != userID {
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, thread.CourseID, userID, PermissionModerateDiscussions)
+		if err != nil {
+			return
+		}
+	}
+
+	err = post.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.DiscussionRepository.DeletePost(post)
+	return
+}
+
+// SoftDeleteThread marks a Thread as deleted. Its author and the moderators of
+// its Course can do this.
+func (s *DiscussionServiceImpl) SoftDeleteThread(id uuid.UUID, userID uuid.UUID, role string) (thread Thread, err error) {
+	thread, moderator, err := s.resolveThread(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.CreatedBy != userID && !moderator {
+		return thread, failure.Forbidden("only the author or a moderator can delete this thread")
+	}
+
+	err = thread.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// UpdatePost updates the body of a Post written by the given user.
+func (s *DiscussionServiceImpl) UpdatePost(id uuid.UUID, requestFormat PostUpdateRequestFormat, userID uuid.UUID, role string) (post Post, err error) {
+	post, _, err = s.resolvePost(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if post.CreatedBy != userID {
+		return post, failure.Forbidden("only the author can edit this post")
+	}
+
+	err = post.Update(requestFormat, userID)
+	if err != nil {
+		return post, failure.BadRequest(err)
+	}
+
+	err = s.DiscussionRepository.UpdatePost(post)
+	return
+}
+
+// UpdateThread updates the title and body of a Thread started by the given user.
+func (s *DiscussionServiceImpl) UpdateThread(id uuid.UUID, requestFormat ThreadUpdateRequestFormat, userID uuid.UUID, role string) (thread Thread, err error) {
+	thread, _, err = s.resolveThread(id, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.CreatedBy != userID {
+		return thread, failure.Forbidden("only the author can edit this thread")
+	}
+
+	err = thread.Update(requestFormat, userID)
+	if err != nil {
+		return thread, failure.BadRequest(err)
+	}
+
+	err = s.DiscussionRepository.UpdateThread(thread)
+	return
+}
+
+// Upvote upvotes a Thread or a Post on behalf of the given user.
+func (s *DiscussionServiceImpl) Upvote(subjectType UpvoteSubjectType, id uuid.UUID, userID uuid.UUID, role string) (upvote Upvote, err error) {
+	authorID, err := s.resolveUpvoteSubject(subjectType, id, userID, role)
+	if err != nil {
+		return
+	}
+
+	upvote, err = NewUpvote(subjectType, id, authorID, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.DiscussionRepository.CreateUpvote(upvote)
+	return
+}
+
+// resolveDiscussionAccess resolves a Course whose discussions the given user
+// may read and take part in, and whether they moderate them. Students need an
+// active enrollment; moderators are the owner and the Collaborators allowed to.
+func (s *DiscussionServiceImpl) resolveDiscussionAccess(courseID uuid.UUID, userID uuid.UUID, role string) (course Course, moderator bool, err error) {
+	course, err = s.CourseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || (role != shared.RoleTeacher && !course.IsVisibleToStudents()) {
+		return course, false, failure.NotFound("course")
+	}
+
+	if role != shared.RoleTeacher {
+		err = checkReadAccess(s.EnrollmentRepository, courseID, userID, role)
+		return
+	}
+
+	if course.IsOwnedBy(userID) {
+		return course, true, nil
+	}
+
+	collaborator, err := s.CollaboratorRepository.ResolveCollaborator(courseID, userID)
+	if err != nil {
+		if failure.GetCode(err) == http.StatusNotFound {
+			err = nil
+		}
+
+		return
+	}
+
+	return course, collaborator.Can(PermissionModerateDiscussions), nil
+}
+
+// resolveModeratedThread resolves a non-deleted Thread of a Course the given
+// user moderates.
+func (s *DiscussionServiceImpl) resolveModeratedThread(id uuid.UUID, userID uuid.UUID) (thread Thread, err error) {
+	thread, err = s.DiscussionRepository.ResolveThreadByID(id)
+	if err != nil {
+		return
+	}
+
+	if thread.IsDeleted() {
+		return thread, failure.NotFound("thread")
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, thread.CourseID, userID, PermissionModerateDiscussions)
+	return
+}
+
+// resolvePost resolves a non-deleted Post and its Thread, readable by the
+// given user.
+func (s *DiscussionServiceImpl) resolvePost(id uuid.UUID, userID uuid.UUID, role string) (post Post, thread Thread, err error) {
+	post, err = s.DiscussionRepository.ResolvePostByID(id)
+	if err != nil {
+		return
+	}
+
+	if post.IsDeleted() {
+		return post, thread, failure.NotFound("post")
+	}
+
+	thread, moderator, err := s.resolveThread(post.ThreadID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if post.IsHidden() && !moderator {
+		return post, thread, failure.NotFound("post")
+	}
+
+	return
+}
+
+// resolveThread resolves a non-deleted Thread readable by the given user, and
+// whether they moderate it. Hidden Threads are only readable by moderators.
+func (s *DiscussionServiceImpl) resolveThread(id uuid.UUID, userID uuid.UUID, role string) (thread Thread, moderator bool, err error) {
+	thread, err = s.DiscussionRepository.ResolveThreadByID(id)
+	if err != nil {
+		return
+	}
+
+	if thread.IsDeleted() {
+		return thread, false, failure.NotFound("thread")
+	}
+
+	_, moderator, err = s.resolveDiscussionAccess(thread.CourseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if thread.IsHidden() && !moderator {
+		return thread, false, failure.NotFound("thread")
+	}
+
+	return
+}
+
+// resolveUpvoteSubject resolves the author of a Thread or Post the given user
+// may upvote.
+func (s *DiscussionServiceImpl) resolveUpvoteSubject(subjectType UpvoteSubjectType, id uuid.UUID, userID uuid.UUID, role string) (authorID uuid.UUID, err error) {
+	if subjectType == UpvoteSubjectPost {
+		post, _, err := s.resolvePost(id, userID, role)
+		return post.CreatedBy, err
+	}
+
+	thread, _, err := s.resolveThread(id, userID, role)
+	return thread.CreatedBy, err
+}
+
+// resolveUpvoted resolves which of the given Threads and Posts a user upvoted.
+func (s *DiscussionServiceImpl) resolveUpvoted(userID uuid.UUID, ids []uuid.UUID) (upvoted map[uuid.UUID]bool, err error) {
+	upvotedIDs, err := s.DiscussionRepository.ResolveUpvotedIDs(userID, ids)
+	if err != nil {
+		return
+	}
+
+	upvoted = make(map[uuid.UUID]bool)
+	for _, id := range upvotedIDs {
+		upvoted[id] = true
+	}
+
+	return
+}
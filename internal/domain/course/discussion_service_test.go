@@ -0,0 +1,139 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscussionService(t *testing.T) {
+	config := &configs.Config{}
+	publishedCourse := newDraftCourse()
+	publishedCourse.Status = course.CourseStatusPublished
+	publishedCourse.PublishedVersion = null.IntFrom(1)
+	studentID := getRandomUUID()
+	thread := newThread(t)
+	thread.CourseID = publishedCourse.ID
+	assistant := course.Collaborator{
+		CourseID:   publishedCourse.ID,
+		UserID:     getRandomUUID(),
+		Role:       course.CourseRoleTeachingAssistant,
+		Status:     course.CollaboratorStatusActive,
+		InvitedAt:  time.Now(),
+		InvitedBy:  publishedCourse.UserID,
+		AcceptedAt: null.TimeFrom(time.Now()),
+	}
+
+	t.Run("students cannot reply to a locked thread", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		locked := thread
+		locked.Lock(true, publishedCourse.UserID)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockDiscussionRepo := course_mock.NewMockDiscussionRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideDiscussionServiceImpl(nil, mockCourseRepo, mockDiscussionRepo, mockEnrollmentRepo, nil, config)
+		mockDiscussionRepo.EXPECT().ResolveThreadByID(locked.ID).Return(locked, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(publishedCourse.ID, studentID).
+			Return(newEnrollment(publishedCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+
+		_, err := s.CreatePost(locked.ID, course.PostRequestFormat{Body: "Hello?"}, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+
+	t.Run("students cannot see hidden threads", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hidden := thread
+		hidden.Hide(true, publishedCourse.UserID)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockDiscussionRepo := course_mock.NewMockDiscussionRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideDiscussionServiceImpl(nil, mockCourseRepo, mockDiscussionRepo, mockEnrollmentRepo, nil, config)
+		mockDiscussionRepo.EXPECT().ResolveThreadByID(hidden.ID).Return(hidden, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(publishedCourse.ID, studentID).
+			Return(newEnrollment(publishedCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+
+		_, err := s.ResolveThreadByID(hidden.ID, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+
+	t.Run("a teaching assistant pins a thread", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCollaboratorRepo := course_mock.NewMockCollaboratorRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockDiscussionRepo := course_mock.NewMockDiscussionRepository(ctrl)
+		s := course.ProvideDiscussionServiceImpl(mockCollaboratorRepo, mockCourseRepo, mockDiscussionRepo, nil, nil, config)
+		mockDiscussionRepo.EXPECT().ResolveThreadByID(thread.ID).Return(thread, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockCollaboratorRepo.EXPECT().ResolveCollaborator(publishedCourse.ID, assistant.UserID).Return(assistant, nil)
+		mockDiscussionRepo.EXPECT().UpdateThread(gomock.Any()).Return(nil)
+
+		pinned, err := s.PinThread(thread.ID, true, assistant.UserID)
+
+		assert.NoError(t, err)
+		assert.True(t, pinned.IsPinned())
+	})
+
+	t.Run("the first page comes with the pinned threads", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pinned := thread
+		pinned.Pin(true, publishedCourse.UserID)
+		params := course.ThreadQueryParameters{CourseID: publishedCourse.ID, Limit: 1}
+		pinnedParams := params
+		pinnedParams.Pinned = true
+		others := []course.Thread{newThread(t), newThread(t)}
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockDiscussionRepo := course_mock.NewMockDiscussionRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideDiscussionServiceImpl(nil, mockCourseRepo, mockDiscussionRepo, mockEnrollmentRepo, nil, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(publishedCourse.ID, studentID).
+			Return(newEnrollment(publishedCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+		mockDiscussionRepo.EXPECT().ResolveThreads(pinnedParams).Return([]course.Thread{pinned}, nil)
+		mockDiscussionRepo.EXPECT().ResolveThreads(params).Return(others, nil)
+		mockDiscussionRepo.EXPECT().ResolveUpvotedIDs(studentID, gomock.Any()).Return(nil, nil)
+
+		page, err := s.ResolveThreads(params, studentID, shared.RoleStudent)
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Pinned, 1)
+		assert.Len(t, page.Threads, 1)
+		assert.True(t, page.NextCursor.Valid)
+	})
+
+	t.Run("students cannot list hidden threads", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := course.ProvideDiscussionServiceImpl(nil, mockCourseRepo, nil, mockEnrollmentRepo, nil, config)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(publishedCourse.ID, studentID).
+			Return(newEnrollment(publishedCourse.ID, studentID, course.EnrollmentStatusActive), nil)
+
+		_, err := s.ResolveThreads(course.ThreadQueryParameters{CourseID: publishedCourse.ID, IncludeHidden: true, Limit: 20}, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
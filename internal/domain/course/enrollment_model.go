@@ -0,0 +1,215 @@
+package course
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// EnrollmentStatus indicates the status of an Enrollment.
+type EnrollmentStatus string
+
+const (
+	// EnrollmentStatusPending indicates an Enrollment that holds a seat and
+	// awaits the teacher's approval.
+	EnrollmentStatusPending EnrollmentStatus = "pending"
+	// EnrollmentStatusActive indicates an approved Enrollment. Active students
+	// can read the course content.
+	EnrollmentStatusActive EnrollmentStatus = "active"
+	// EnrollmentStatusWaitlisted indicates an Enrollment waiting for a seat.
+	EnrollmentStatusWaitlisted EnrollmentStatus = "waitlisted"
+	// EnrollmentStatusWithdrawn indicates an Enrollment the student cancelled.
+	EnrollmentStatusWithdrawn EnrollmentStatus = "withdrawn"
+	// EnrollmentStatusRemoved indicates an Enrollment the teacher removed.
+	EnrollmentStatusRemoved EnrollmentStatus = "removed"
+)
+
+// HoldsSeat checks whether an Enrollment in this status takes up one of the
+// Course's seats.
+func (s EnrollmentStatus) HoldsSeat() bool {
+	return s == EnrollmentStatusPending || s == EnrollmentStatusActive
+}
+
+// Enrollment links a student to a Course.
+type Enrollment struct {
+	ID          uuid.UUID        `db:"id" validate:"required"`
+	CourseID    uuid.UUID        `db:"course_id" validate:"required"`
+	StudentID   uuid.UUID        `db:"student_id" validate:"required"`
+	CohortID    nuuid.NUUID      `db:"cohort_id"`
+	Status      EnrollmentStatus `db:"status" validate:"required,oneof=pending active waitlisted withdrawn removed"`
+	RequestedAt time.Time        `db:"requested_at" validate:"required"`
+	CreatedAt   time.Time        `db:"created_at" validate:"required"`
+	CreatedBy   uuid.UUID        `db:"created_by" validate:"required"`
+	UpdatedAt   null.Time        `db:"updated_at"`
+	UpdatedBy   nuuid.NUUID      `db:"updated_by"`
+}
+
+// EnrollmentQueryParameters filters the Enrollments of a Course.
+type EnrollmentQueryParameters struct {
+	CourseID uuid.UUID
+	CohortID nuuid.NUUID
+	Status   EnrollmentStatus
+}
+
+// AssignCohort moves an Enrollment to a Cohort of its Course, or out of any
+// Cohort when cohortID is not valid.
+func (e *Enrollment) AssignCohort(cohortID nuuid.NUUID, userID uuid.UUID) {
+	e.CohortID = cohortID
+	e.UpdatedAt = null.TimeFrom(time.Now())
+	e.UpdatedBy = nuuid.From(userID)
+}
+
+// IsActive checks whether an Enrollment grants access to the course content.
+func (e *Enrollment) IsActive() bool {
+	return e.Status == EnrollmentStatusActive
+}
+
+// Leave moves an Enrollment to a status that leaves its Course. If it held a
+// seat, the seat goes to next, the waitlisted Enrollment requested first, when
+// there is one. promoted tells whether next got the seat.
+func (e *Enrollment) Leave(newStatus EnrollmentStatus, next *Enrollment, userID uuid.UUID) (promoted bool, err error) {
+	heldSeat := e.Status.HoldsSeat()
+
+	err = e.UpdateStatus(newStatus, userID)
+	if err != nil {
+		return
+	}
+
+	if !heldSeat || next == nil {
+		return false, nil
+	}
+
+	err = next.UpdateStatus(EnrollmentStatusPending, userID)
+	if err != nil {
+		return
+	}
+
+	return true, nil
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (e Enrollment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToResponseFormat())
+}
+
+// NewEnrollment creates a new Enrollment of a student in a Course. Students
+// get a pending seat while seats are available and join the waitlist otherwise.
+func (e Enrollment) NewEnrollment(courseID uuid.UUID, studentID uuid.UUID, seatAvailable bool) (newEnrollment Enrollment, err error) {
+	enrollmentID, _ := uuid.NewV4()
+	now := time.Now()
+	newEnrollment = Enrollment{
+		ID:          enrollmentID,
+		CourseID:    courseID,
+		StudentID:   studentID,
+		Status:      EnrollmentStatusWaitlisted,
+		RequestedAt: now,
+		CreatedAt:   now,
+		CreatedBy:   studentID,
+	}
+
+	if seatAvailable {
+		newEnrollment.Status = EnrollmentStatusPending
+	}
+
+	err = newEnrollment.Validate()
+
+	return
+}
+
+// Reenroll puts a withdrawn student back in line for a seat.
+func (e *Enrollment) Reenroll(seatAvailable bool) (err error) {
+	newStatus := EnrollmentStatusWaitlisted
+	if seatAvailable {
+		newStatus = EnrollmentStatusPending
+	}
+
+	err = e.UpdateStatus(newStatus, e.StudentID)
+	if err != nil {
+		return
+	}
+
+	e.RequestedAt = time.Now()
+
+	return
+}
+
+// ToResponseFormat converts this Enrollment to its response format.
+func (e Enrollment) ToResponseFormat() EnrollmentResponseFormat {
+	return EnrollmentResponseFormat{
+		ID:          e.ID,
+		CourseID:    e.CourseID,
+		StudentID:   e.StudentID,
+		CohortID:    e.CohortID.Ptr(),
+		Status:      e.Status,
+		RequestedAt: e.RequestedAt,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+		UpdatedBy:   e.UpdatedBy.Ptr(),
+	}
+}
+
+// UpdateStatus validates an Enrollment's status change. Allowed state changes are:
+// 1. Pending --> Active, Withdrawn, Removed
+// 2. Active --> Withdrawn, Removed
+// 3. Waitlisted --> Pending, Withdrawn, Removed
+// 4. Withdrawn --> Pending, Waitlisted
+// 5. Removed --> this is a final state, no change allowed
+func (e *Enrollment) UpdateStatus(newStatus EnrollmentStatus, userID uuid.UUID) (err error) {
+	stateChangeNotAllowedError := failure.Conflict(
+		"stateChange",
+		"enrollment",
+		fmt.Sprintf("cannot change from %s to %s", e.Status, newStatus))
+
+	switch e.Status {
+	case EnrollmentStatusPending:
+		if newStatus != EnrollmentStatusActive && newStatus != EnrollmentStatusWithdrawn && newStatus != EnrollmentStatusRemoved {
+			return stateChangeNotAllowedError
+		}
+	case EnrollmentStatusActive:
+		if newStatus != EnrollmentStatusWithdrawn && newStatus != EnrollmentStatusRemoved {
+			return stateChangeNotAllowedError
+		}
+	case EnrollmentStatusWaitlisted:
+		if newStatus != EnrollmentStatusPending && newStatus != EnrollmentStatusWithdrawn && newStatus != EnrollmentStatusRemoved {
+			return stateChangeNotAllowedError
+		}
+	case EnrollmentStatusWithdrawn:
+		if newStatus != EnrollmentStatusPending && newStatus != EnrollmentStatusWaitlisted {
+			return stateChangeNotAllowedError
+		}
+	case EnrollmentStatusRemoved:
+		return stateChangeNotAllowedError
+	}
+
+	// passed all state change validations, actually update the status
+	e.Status = newStatus
+	e.UpdatedAt = null.TimeFrom(time.Now())
+	e.UpdatedBy = nuuid.From(userID)
+
+	return nil
+}
+
+// Validate validates the entity.
+func (e *Enrollment) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(e)
+}
+
+// EnrollmentResponseFormat represents an Enrollment's standard formatting for JSON serializing.
+type EnrollmentResponseFormat struct {
+	ID          uuid.UUID        `json:"id"`
+	CourseID    uuid.UUID        `json:"courseID"`
+	StudentID   uuid.UUID        `json:"studentID"`
+	CohortID    *uuid.UUID       `json:"cohortID"`
+	Status      EnrollmentStatus `json:"status"`
+	RequestedAt time.Time        `json:"requestedAt"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   null.Time        `json:"updatedAt"`
+	UpdatedBy   *uuid.UUID       `json:"updatedBy"`
+}
@@ -0,0 +1,89 @@
+package course_test
+
+import (
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrollment(t *testing.T) {
+	limited := newDraftCourse()
+	limited.SeatLimit = null.IntFrom(1)
+
+	t.Run("enroll", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			c          course.Course
+			seatsTaken int
+			want       course.EnrollmentStatus
+		}{
+			{name: "seat available", c: limited, seatsTaken: 0, want: course.EnrollmentStatusPending},
+			{name: "course full", c: limited, seatsTaken: 1, want: course.EnrollmentStatusWaitlisted},
+			{name: "no seat limit", c: newDraftCourse(), seatsTaken: 500, want: course.EnrollmentStatusPending},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				enrollment, err := course.Enrollment{}.NewEnrollment(test.c.ID, getRandomUUID(), test.c.HasSeatAvailable(test.seatsTaken))
+
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, enrollment.Status)
+			})
+		}
+	})
+
+	t.Run("re-enroll in a full course", func(t *testing.T) {
+		withdrawn := newEnrollment(limited.ID, getRandomUUID(), course.EnrollmentStatusWithdrawn)
+
+		assert.NoError(t, withdrawn.Reenroll(limited.HasSeatAvailable(1)))
+		assert.Equal(t, course.EnrollmentStatusWaitlisted, withdrawn.Status)
+	})
+
+	t.Run("leave promotes the next waitlisted student", func(t *testing.T) {
+		studentID := getRandomUUID()
+		active := newEnrollment(limited.ID, studentID, course.EnrollmentStatusActive)
+		next := newEnrollment(limited.ID, getRandomUUID(), course.EnrollmentStatusWaitlisted)
+
+		promoted, err := active.Leave(course.EnrollmentStatusWithdrawn, &next, studentID)
+
+		assert.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Equal(t, course.EnrollmentStatusWithdrawn, active.Status)
+		assert.Equal(t, course.EnrollmentStatusPending, next.Status)
+	})
+
+	t.Run("leaving the waitlist promotes nobody", func(t *testing.T) {
+		studentID := getRandomUUID()
+		waitlisted := newEnrollment(limited.ID, studentID, course.EnrollmentStatusWaitlisted)
+		next := newEnrollment(limited.ID, getRandomUUID(), course.EnrollmentStatusWaitlisted)
+
+		promoted, err := waitlisted.Leave(course.EnrollmentStatusWithdrawn, &next, studentID)
+
+		assert.NoError(t, err)
+		assert.False(t, promoted)
+		assert.Equal(t, course.EnrollmentStatusWaitlisted, next.Status)
+	})
+
+	t.Run("leave with an empty waitlist", func(t *testing.T) {
+		studentID := getRandomUUID()
+		pending := newEnrollment(limited.ID, studentID, course.EnrollmentStatusPending)
+
+		promoted, err := pending.Leave(course.EnrollmentStatusRemoved, nil, getRandomUUID())
+
+		assert.NoError(t, err)
+		assert.False(t, promoted)
+		assert.Equal(t, course.EnrollmentStatusRemoved, pending.Status)
+	})
+
+	t.Run("a removed student cannot leave again", func(t *testing.T) {
+		removed := newEnrollment(limited.ID, getRandomUUID(), course.EnrollmentStatusRemoved)
+		next := newEnrollment(limited.ID, getRandomUUID(), course.EnrollmentStatusWaitlisted)
+
+		_, err := removed.Leave(course.EnrollmentStatusWithdrawn, &next, removed.StudentID)
+
+		assert.Error(t, err)
+		assert.Equal(t, course.EnrollmentStatusWaitlisted, next.Status)
+	})
+}
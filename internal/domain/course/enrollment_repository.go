@@ -0,0 +1,355 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source enrollment_repository.go -destination mock/enrollment_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	enrollmentQueries = struct {
+		selectEnrollment string
+		lockCourse       string
+		countSeatsTaken  string
+		insertEnrollment string
+		updateEnrollment string
+	}{
+		selectEnrollment: `
+			SELECT
+				id,
+				course_id,
+				student_id,
+				cohort_id,
+				status,
+				requested_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			FROM enrollments
+		`,
+
+		lockCourse: `
+			SELECT id
+			FROM courses
+			WHERE id = ?
+			FOR UPDATE
+		`,
+
+		countSeatsTaken: `
+			SELECT COUNT(id)
+			FROM enrollments
+			WHERE course_id = ? AND status IN ('pending', 'active')
+		`,
+
+		insertEnrollment: `
+			INSERT INTO enrollments (
+				id,
+				course_id,
+				student_id,
+				cohort_id,
+				status,
+				requested_at,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			) VALUES (
+				:id,
+				:course_id,
+				:student_id,
+				:cohort_id,
+				:status,
+				:requested_at,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by
+			)
+		`,
+
+		updateEnrollment: `
+			UPDATE enrollments
+			SET
+				cohort_id = :cohort_id,
+				status = :status,
+				requested_at = :requested_at,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+	}
+)
+
+// EnrollmentRepository is the repository for Enrollment data.
+type EnrollmentRepository interface {
+	EnrollStudent(c Course, studentID uuid.UUID, cohortID nuuid.NUUID) (enrollment Enrollment, err error)
+	LeaveEnrollment(enrollment Enrollment, newStatus EnrollmentStatus, userID uuid.UUID) (left Enrollment, err error)
+	ResolveEnrollmentByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error)
+	ResolveEnrollmentByID(id uuid.UUID) (enrollment Enrollment, err error)
+	ResolveEnrollments(params EnrollmentQueryParameters) (enrollments []Enrollment, err error)
+	ResolveEnrollmentsByStudentID(studentID uuid.UUID) (enrollments []Enrollment, err error)
+	UpdateEnrollments(enrollments ...Enrollment) (err error)
+}
+
+// EnrollmentRepositoryMySQL is the MySQL-backed implementation of EnrollmentRepository.
+type EnrollmentRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideEnrollmentRepositoryMySQL is the provider for this repository.
+func ProvideEnrollmentRepositoryMySQL(db *infras.MySQLConn) *EnrollmentRepositoryMySQL {
+	s := new(EnrollmentRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// EnrollStudent enrolls a student in a Course, or enrolls a withdrawn student
+// again, placing them in the given Cohort if valid. The student takes a pending
+// seat if one is available and joins the waitlist otherwise. The Course is
+// locked while its seats are counted, so concurrent enrollments can't take
+// more seats than it has.
+func (r *EnrollmentRepositoryMySQL) EnrollStudent(c Course, studentID uuid.UUID, cohortID nuuid.NUUID) (enrollment Enrollment, err error) {
+	err = r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txLockCourse(tx, c.ID); err != nil {
+			e <- err
+			return
+		}
+
+		var taken int
+		if err := tx.Get(&taken, enrollmentQueries.countSeatsTaken, c.ID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		seatAvailable := c.HasSeatAvailable(taken)
+
+		err := tx.Get(
+			&enrollment,
+			enrollmentQueries.selectEnrollment+" WHERE course_id = ? AND student_id = ? FOR UPDATE",
+			c.ID.String(),
+			studentID.String())
+		if err != nil && err != sql.ErrNoRows {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err == sql.ErrNoRows {
+			enrollment, err = enrollment.NewEnrollment(c.ID, studentID, seatAvailable)
+			if err != nil {
+				e <- failure.BadRequest(err)
+				return
+			}
+
+			enrollment.CohortID = cohortID
+
+			e <- r.txExecNamed(tx, enrollmentQueries.insertEnrollment, enrollment)
+			return
+		}
+
+		if err := enrollment.Reenroll(seatAvailable); err != nil {
+			e <- err
+			return
+		}
+
+		if cohortID.Valid {
+			enrollment.AssignCohort(cohortID, studentID)
+		}
+
+		e <- r.txExecNamed(tx, enrollmentQueries.updateEnrollment, enrollment)
+	})
+
+	return
+}
+
+// LeaveEnrollment moves an Enrollment to a status that leaves the Course,
+// handing the seat it held to the waitlisted Enrollment requested first as
+// Enrollment.Leave decides. The Course is locked meanwhile, so concurrent
+// departures can't promote the same student twice.
+func (r *EnrollmentRepositoryMySQL) LeaveEnrollment(enrollment Enrollment, newStatus EnrollmentStatus, userID uuid.UUID) (left Enrollment, err error) {
+	err = r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txLockCourse(tx, enrollment.CourseID); err != nil {
+			e <- err
+			return
+		}
+
+		err := tx.Get(&left, enrollmentQueries.selectEnrollment+" WHERE id = ? FOR UPDATE", enrollment.ID.String())
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = failure.NotFound("enrollment")
+			}
+
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		next := new(Enrollment)
+		err = tx.Get(
+			next,
+			enrollmentQueries.selectEnrollment+" WHERE course_id = ? AND status = ? AND id <> ? ORDER BY requested_at LIMIT 1 FOR UPDATE",
+			left.CourseID.String(),
+			EnrollmentStatusWaitlisted,
+			left.ID.String())
+		if err != nil && err != sql.ErrNoRows {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err == sql.ErrNoRows {
+			next = nil
+		}
+
+		promoted, err := left.Leave(newStatus, next, userID)
+		if err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, enrollmentQueries.updateEnrollment, left); err != nil {
+			e <- err
+			return
+		}
+
+		if !promoted {
+			e <- nil
+			return
+		}
+
+		e <- r.txExecNamed(tx, enrollmentQueries.updateEnrollment, next)
+	})
+
+	return
+}
+
+// ResolveEnrollmentByCourseIDAndStudentID resolves the Enrollment of a student in a Course.
+func (r *EnrollmentRepositoryMySQL) ResolveEnrollmentByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error) {
+	err = r.DB.Read.Get(
+		&enrollment,
+		enrollmentQueries.selectEnrollment+" WHERE course_id = ? AND student_id = ?",
+		courseID.String(),
+		studentID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("enrollment")
+			return
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveEnrollmentByID resolves an Enrollment by its ID.
+func (r *EnrollmentRepositoryMySQL) ResolveEnrollmentByID(id uuid.UUID) (enrollment Enrollment, err error) {
+	err = r.DB.Read.Get(&enrollment, enrollmentQueries.selectEnrollment+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("enrollment")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveEnrollments resolves the Enrollments of a Course in the order they
+// were requested, optionally filtered by Cohort and status.
+func (r *EnrollmentRepositoryMySQL) ResolveEnrollments(params EnrollmentQueryParameters) (enrollments []Enrollment, err error) {
+	query := enrollmentQueries.selectEnrollment + " WHERE course_id = ?"
+	args := []interface{}{params.CourseID.String()}
+
+	if params.CohortID.Valid {
+		query += " AND cohort_id = ?"
+		args = append(args, params.CohortID.UUID.String())
+	}
+
+	if params.Status != "" {
+		query += " AND status = ?"
+		args = append(args, params.Status)
+	}
+
+	query += " ORDER BY requested_at"
+
+	err = r.DB.Read.Select(&enrollments, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveEnrollmentsByStudentID resolves all Enrollments of a student.
+func (r *EnrollmentRepositoryMySQL) ResolveEnrollmentsByStudentID(studentID uuid.UUID) (enrollments []Enrollment, err error) {
+	err = r.DB.Read.Select(
+		&enrollments,
+		enrollmentQueries.selectEnrollment+" WHERE student_id = ? ORDER BY requested_at DESC",
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateEnrollments updates a set of Enrollments in one transaction.
+func (r *EnrollmentRepositoryMySQL) UpdateEnrollments(enrollments ...Enrollment) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		for _, enrollment := range enrollments {
+			if err := r.txExecNamed(tx, enrollmentQueries.updateEnrollment, enrollment); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txLockCourse locks the row of a Course until the given *sqlx.Tx ends, which
+// serializes the changes to who holds its seats.
+func (r *EnrollmentRepositoryMySQL) txLockCourse(tx *sqlx.Tx, courseID uuid.UUID) (err error) {
+	var id string
+	err = tx.Get(&id, enrollmentQueries.lockCourse, courseID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("course")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *EnrollmentRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,266 @@
+package course
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+)
+
+// EnrollmentService is the service interface for Enrollment entities.
+type EnrollmentService interface {
+	ApproveEnrollment(id uuid.UUID, userID uuid.UUID) (enrollment Enrollment, err error)
+	AssignCohort(id uuid.UUID, requestFormat CohortAssignmentRequestFormat, userID uuid.UUID) (enrollment Enrollment, err error)
+	Enroll(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error)
+	EnrollInCohort(cohortID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error)
+	RemoveEnrollment(id uuid.UUID, userID uuid.UUID) (enrollment Enrollment, err error)
+	ResolveEnrollments(params EnrollmentQueryParameters, userID uuid.UUID) (enrollments []Enrollment, err error)
+	ResolveEnrollmentsByStudentID(studentID uuid.UUID) (enrollments []Enrollment, err error)
+	Unenroll(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error)
+}
+
+// EnrollmentServiceImpl is the service implementation for Enrollment entities.
+type EnrollmentServiceImpl struct {
+	AttendanceRepository   AttendanceRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	ProgressRepository     ProgressRepository
+	Config                 *configs.Config
+}
+
+// ProvideEnrollmentServiceImpl is the provider for this service.
+func ProvideEnrollmentServiceImpl(
+	attendanceRepository AttendanceRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	progressRepository ProgressRepository,
+	config *configs.Config) *EnrollmentServiceImpl {
+	s := new(EnrollmentServiceImpl)
+	s.AttendanceRepository = attendanceRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.ProgressRepository = progressRepository
+	s.Config = config
+
+	return s
+}
+
+// ApproveEnrollment activates a pending Enrollment, giving the student access
+// to the course content.
+func (s *EnrollmentServiceImpl) ApproveEnrollment(id uuid.UUID, userID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.resolveManagedEnrollment(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = enrollment.UpdateStatus(EnrollmentStatusActive, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.EnrollmentRepository.UpdateEnrollments(enrollment)
+	return
+}
+
+// AssignCohort moves an Enrollment to one of its Course's Cohorts, or out of any Cohort when no cohortID is given.
+func (s *EnrollmentServiceImpl) AssignCohort(id uuid.UUID, requestFormat CohortAssignmentRequestFormat, userID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.resolveManagedEnrollment(id, userID)
+	if err != nil {
+		return
+	}
+
+	if requestFormat.CohortID.Valid {
+		cohort, err := resolveCohort(s.CohortRepository, requestFormat.CohortID.UUID)
+		if err != nil {
+			return enrollment, err
+		}
+
+		if cohort.CourseID != enrollment.CourseID {
+			return enrollment, failure.NotFound("cohort")
+		}
+	}
+
+	enrollment.AssignCohort(requestFormat.CohortID, userID)
+
+	err = s.EnrollmentRepository.UpdateEnrollments(enrollment)
+	return
+}
+
+// Enroll enrolls a student in a published Course whose prerequisites they
+// completed. The student takes a pending seat if one is available and joins
+// the waitlist otherwise.
+func (s *EnrollmentServiceImpl) Enroll(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error) {
+	course, err := s.CourseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	return s.enroll(course, studentID, nuuid.NUUID{})
+}
+
+// EnrollInCohort enrolls a student in the Course of a Cohort and places them
+// in the Cohort, as long as its enrollment window is open.
+func (s *EnrollmentServiceImpl) EnrollInCohort(cohortID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error) {
+	cohort, err := resolveCohort(s.CohortRepository, cohortID)
+	if err != nil {
+		return
+	}
+
+	course, err := s.CourseRepository.ResolveCourseByID(cohort.CourseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || !course.IsVisibleToStudents() {
+		return enrollment, failure.NotFound("cohort")
+	}
+
+	if !cohort.IsEnrollmentOpen(time.Now()) {
+		return enrollment, failure.Conflict("enroll", "cohort", "enrollment is not open")
+	}
+
+	return s.enroll(course, studentID, nuuid.From(cohort.ID))
+}
+
+// RemoveEnrollment removes a student from a Course. A freed seat goes to the
+// next student on the waitlist.
+func (s *EnrollmentServiceImpl) RemoveEnrollment(id uuid.UUID, userID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.resolveManagedEnrollment(id, userID)
+	if err != nil {
+		return
+	}
+
+	return s.EnrollmentRepository.LeaveEnrollment(enrollment, EnrollmentStatusRemoved, userID)
+}
+
+// ResolveEnrollments resolves the Enrollments of a Course, for those allowed to
+// view its students.
+func (s *EnrollmentServiceImpl) ResolveEnrollments(params EnrollmentQueryParameters, userID uuid.UUID) (enrollments []Enrollment, err error) {
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, params.CourseID, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	return s.EnrollmentRepository.ResolveEnrollments(params)
+}
+
+// ResolveEnrollmentsByStudentID resolves all Enrollments of a student.
+func (s *EnrollmentServiceImpl) ResolveEnrollmentsByStudentID(studentID uuid.UUID) (enrollments []Enrollment, err error) {
+	return s.EnrollmentRepository.ResolveEnrollmentsByStudentID(studentID)
+}
+
+// Unenroll withdraws a student from a Course. A freed seat goes to the next
+// student on the waitlist.
+func (s *EnrollmentServiceImpl) Unenroll(courseID uuid.UUID, studentID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	return s.EnrollmentRepository.LeaveEnrollment(enrollment, EnrollmentStatusWithdrawn, studentID)
+}
+
+// checkPrerequisites makes sure a student completed every Course the given
+// one requires.
+func (s *EnrollmentServiceImpl) checkPrerequisites(course Course, studentID uuid.UUID) (err error) {
+	prerequisiteIDs, err := s.CourseRepository.ResolvePrerequisiteIDs(course.ID)
+	if err != nil || len(prerequisiteIDs) == 0 {
+		return
+	}
+
+	var unmetIDs []uuid.UUID
+	for _, prerequisiteID := range prerequisiteIDs {
+		progress, _, err := resolveCourseCompletion(
+			s.AttendanceRepository,
+			s.EnrollmentRepository,
+			s.LiveSessionRepository,
+			s.ProgressRepository,
+			prerequisiteID,
+			studentID)
+		if err != nil {
+			return err
+		}
+
+		if !progress.IsComplete() {
+			unmetIDs = append(unmetIDs, prerequisiteID)
+		}
+	}
+
+	if len(unmetIDs) == 0 {
+		return nil
+	}
+
+	unmet, err := s.CourseRepository.ResolveCoursesByIDs(unmetIDs)
+	if err != nil {
+		return
+	}
+
+	titles := make([]string, 0, len(unmet))
+	for _, prerequisite := range unmet {
+		titles = append(titles, prerequisite.Title)
+	}
+	sort.Strings(titles)
+
+	return failure.Forbidden("complete the prerequisite courses first: " + strings.Join(titles, ", "))
+}
+
+// enroll enrolls a student in a Course, placing them in the given Cohort. A
+// student enrolling again moves to the new Cohort.
+func (s *EnrollmentServiceImpl) enroll(course Course, studentID uuid.UUID, cohortID nuuid.NUUID) (enrollment Enrollment, err error) {
+	if course.IsDeleted() || !course.IsVisibleToStudents() {
+		return enrollment, failure.NotFound("course")
+	}
+
+	err = s.checkPrerequisites(course, studentID)
+	if err != nil {
+		return
+	}
+
+	return s.EnrollmentRepository.EnrollStudent(course, studentID, cohortID)
+}
+
+// resolveManagedEnrollment resolves an Enrollment in a Course whose
+// Enrollments the given user manages.
+func (s *EnrollmentServiceImpl) resolveManagedEnrollment(id uuid.UUID, userID uuid.UUID) (enrollment Enrollment, err error) {
+	enrollment, err = s.EnrollmentRepository.ResolveEnrollmentByID(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, enrollment.CourseID, userID, PermissionManageEnrollments)
+	return
+}
+
+// checkReadAccess makes sure a user may read the content of a Course. Teachers
+// may read every course, students need an active Enrollment.
+func checkReadAccess(enrollmentRepository EnrollmentRepository, courseID uuid.UUID, userID uuid.UUID, role string) (err error) {
+	if role == shared.RoleTeacher {
+		return nil
+	}
+
+	enrollment, err := enrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, userID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	if err != nil || !enrollment.IsActive() {
+		return failure.Forbidden("an active enrollment is required to read this course")
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrollmentService(t *testing.T) {
+	ownerID := getRandomUUID()
+	fullCourse := course.Course{
+		ID:               getRandomUUID(),
+		UserID:           ownerID,
+		Title:            "Data Bootcamp",
+		Content:          "SQL and pandas",
+		SeatLimit:        null.IntFrom(1),
+		Status:           course.CourseStatusPublished,
+		PublishedVersion: null.IntFrom(1),
+		CreatedAt:        time.Now(),
+		CreatedBy:        ownerID,
+	}
+
+	t.Run("enroll", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		studentID := getRandomUUID()
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{
+			CourseRepository:     mockCourseRepo,
+			EnrollmentRepository: mockEnrollmentRepo,
+		}
+		waitlisted := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusWaitlisted)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCourseRepo.EXPECT().ResolvePrerequisiteIDs(fullCourse.ID).Return(nil, nil)
+		mockEnrollmentRepo.EXPECT().EnrollStudent(fullCourse, studentID, nuuid.NUUID{}).Return(waitlisted, nil)
+
+		got, err := s.Enroll(fullCourse.ID, studentID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.EnrollmentStatusWaitlisted, got.Status)
+	})
+
+	t.Run("enroll in an unpublished course", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		draft := fullCourse
+		draft.Status = course.CourseStatusDraft
+		draft.PublishedVersion = null.Int{}
+
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{CourseRepository: mockCourseRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(draft.ID).Return(draft, nil)
+
+		_, err := s.Enroll(draft.ID, getRandomUUID())
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+
+	t.Run("enroll before completing the prerequisites", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		studentID := getRandomUUID()
+		completed := newDraftCourse()
+		started := newDraftCourse()
+		started.Title = "Python basics"
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{AttendanceRepository: mockAttendanceRepo, CourseRepository: mockCourseRepo, ProgressRepository: mockProgressRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+		mockCourseRepo.EXPECT().ResolvePrerequisiteIDs(fullCourse.ID).Return([]uuid.UUID{completed.ID, started.ID}, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(completed.ID).Return(1, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(completed.ID, studentID).Return([]course.LessonProgress{
+			{CourseID: completed.ID, StudentID: studentID, CompletedAt: null.TimeFrom(time.Now())},
+		}, nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(completed.ID).Return(course.CompletionRules{}, failure.NotFound("completionRules"))
+		mockProgressRepo.EXPECT().CountActiveLessons(started.ID).Return(2, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(started.ID, studentID).Return([]course.LessonProgress{
+			{CourseID: started.ID, StudentID: studentID, CompletedAt: null.TimeFrom(time.Now())},
+		}, nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(started.ID).Return(course.CompletionRules{CourseID: started.ID}, nil)
+		mockCourseRepo.EXPECT().ResolveCoursesByIDs([]uuid.UUID{started.ID}).Return([]course.Course{started}, nil)
+
+		_, err := s.Enroll(fullCourse.ID, studentID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+		assert.Contains(t, err.Error(), "Python basics")
+	})
+
+	t.Run("enroll in a cohort whose enrollment window closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		req := newCohortRequest()
+		req.StartDate = time.Now().AddDate(0, 0, -14).Format("2006-01-02")
+		req.EndDate = time.Now().AddDate(0, 0, 60).Format("2006-01-02")
+		req.EnrollmentOpensAt = time.Now().AddDate(0, 0, -30)
+		req.EnrollmentClosesAt = time.Now().AddDate(0, 0, -7)
+		cohort, err := course.Cohort{}.NewCohortFromRequestFormat(fullCourse.ID, req, ownerID)
+		assert.NoError(t, err)
+
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{CohortRepository: mockCohortRepo, CourseRepository: mockCourseRepo}
+		mockCohortRepo.EXPECT().ResolveCohortByID(cohort.ID).Return(cohort, nil)
+		mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+
+		_, err = s.EnrollInCohort(cohort.ID, getRandomUUID())
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+
+	t.Run("unenroll", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		studentID := getRandomUUID()
+		active := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive)
+		withdrawn := active
+		withdrawn.Status = course.EnrollmentStatusWithdrawn
+
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		s := &course.EnrollmentServiceImpl{EnrollmentRepository: mockEnrollmentRepo}
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(active, nil)
+		mockEnrollmentRepo.EXPECT().LeaveEnrollment(active, course.EnrollmentStatusWithdrawn, studentID).Return(withdrawn, nil)
+
+		got, err := s.Unenroll(fullCourse.ID, studentID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.EnrollmentStatusWithdrawn, got.Status)
+	})
+}
+
+func newEnrollment(courseID uuid.UUID, studentID uuid.UUID, status course.EnrollmentStatus) course.Enrollment {
+	return course.Enrollment{
+		ID:          getRandomUUID(),
+		CourseID:    courseID,
+		StudentID:   studentID,
+		Status:      status,
+		RequestedAt: time.Now(),
+		CreatedAt:   time.Now(),
+		CreatedBy:   studentID,
+	}
+}
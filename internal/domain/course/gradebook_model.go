@@ -0,0 +1,930 @@
+package course
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// GradeCategoryKind indicates what the grades of a GradeCategory are made of.
+type GradeCategoryKind string
+
+const (
+	// GradeCategoryKindQuizzes is graded by the best submitted attempt at
+	// each Quiz.
+	GradeCategoryKindQuizzes GradeCategoryKind = "quizzes"
+	// GradeCategoryKindAssignments is graded by the graded Submission of each
+	// Assignment, late penalty included.
+	GradeCategoryKindAssignments GradeCategoryKind = "assignments"
+	// GradeCategoryKindAttendance is graded by the attendance percentage of
+	// the LiveSessions a student was expected at.
+	GradeCategoryKindAttendance GradeCategoryKind = "attendance"
+)
+
+// defaultLetterGrades are the LetterGrades of Courses whose Gradebook doesn't
+// set its own.
+var defaultLetterGrades = []LetterGrade{
+	{Letter: "A", MinPercent: 90},
+	{Letter: "B", MinPercent: 80},
+	{Letter: "C", MinPercent: 70},
+	{Letter: "D", MinPercent: 60},
+	{Letter: "F", MinPercent: 0},
+}
+
+//// Gradebook
+
+// Gradebook sets how the final grades of a Course are computed: the weighted
+// GradeCategories its Quizzes, Assignments and attendance count toward, and
+// the LetterGrades the resulting percentages map to.
+type Gradebook struct {
+	CourseID     uuid.UUID       `db:"course_id" validate:"required"`
+	UpdatedAt    null.Time       `db:"updated_at"`
+	UpdatedBy    nuuid.NUUID     `db:"updated_by"`
+	Categories   []GradeCategory `db:"-" validate:"max=20,dive"`
+	LetterGrades []LetterGrade   `db:"-" validate:"min=1,max=20,dive"`
+}
+
+// AttachCategories attaches GradeCategories to this Gradebook.
+func (g *Gradebook) AttachCategories(categories []GradeCategory) Gradebook {
+	for _, category := range categories {
+		if category.CourseID == g.CourseID {
+			g.Categories = append(g.Categories, category)
+		}
+	}
+	return *g
+}
+
+// AttachLetterGrades attaches LetterGrades to this Gradebook, from the highest
+// to the lowest.
+func (g *Gradebook) AttachLetterGrades(letterGrades []LetterGrade) Gradebook {
+	for _, letterGrade := range letterGrades {
+		if letterGrade.CourseID == g.CourseID {
+			g.LetterGrades = append(g.LetterGrades, letterGrade)
+		}
+	}
+
+	sortLetterGrades(g.LetterGrades)
+	return *g
+}
+
+// Letter returns the letter a percentage is graded with.
+func (g *Gradebook) Letter(percent float64) null.String {
+	for _, letterGrade := range g.LetterGrades {
+		if percent >= letterGrade.MinPercent {
+			return null.StringFrom(letterGrade.Letter)
+		}
+	}
+
+	return null.String{}
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (g Gradebook) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.ToResponseFormat())
+}
+
+// NewDefaultGradebook creates the Gradebook of a Course that didn't set one:
+// it has no GradeCategories, so only overridden grades are set, and the
+// default LetterGrades.
+func (g Gradebook) NewDefaultGradebook(courseID uuid.UUID) Gradebook {
+	gradebook := Gradebook{CourseID: courseID, Categories: make([]GradeCategory, 0)}
+	for _, letterGrade := range defaultLetterGrades {
+		letterGrade.CourseID = courseID
+		gradebook.LetterGrades = append(gradebook.LetterGrades, letterGrade)
+	}
+
+	return gradebook
+}
+
+// NewGradebookFromRequestFormat creates the Gradebook of a Course. The
+// categories listing Quizzes or Assignments may only list those of the Course;
+// leaving LetterGrades out uses the default ones.
+func (g Gradebook) NewGradebookFromRequestFormat(courseID uuid.UUID, req GradebookRequestFormat, quizzes []Quiz, assignments []Assignment, userID uuid.UUID) (newGradebook Gradebook, err error) {
+	err = shared.GetValidator().Struct(req)
+	if err != nil {
+		return
+	}
+
+	newGradebook = Gradebook{}.NewDefaultGradebook(courseID)
+	newGradebook.UpdatedAt = null.TimeFrom(time.Now())
+	newGradebook.UpdatedBy = nuuid.From(userID)
+
+	for i, categoryRequest := range req.Categories {
+		categoryID, _ := uuid.NewV4()
+		newGradebook.Categories = append(newGradebook.Categories, GradeCategory{
+			ID:         categoryID,
+			CourseID:   courseID,
+			Name:       strings.TrimSpace(categoryRequest.Name),
+			Kind:       categoryRequest.Kind,
+			Weight:     categoryRequest.Weight,
+			DropLowest: categoryRequest.DropLowest,
+			ItemIDs:    UUIDList(categoryRequest.ItemIDs),
+			Position:   i + 1,
+		})
+	}
+
+	if len(req.LetterGrades) > 0 {
+		newGradebook.LetterGrades = make([]LetterGrade, 0, len(req.LetterGrades))
+		for _, letterGradeRequest := range req.LetterGrades {
+			newGradebook.LetterGrades = append(newGradebook.LetterGrades, LetterGrade{
+				CourseID:   courseID,
+				Letter:     strings.TrimSpace(letterGradeRequest.Letter),
+				MinPercent: letterGradeRequest.MinPercent,
+			})
+		}
+
+		sortLetterGrades(newGradebook.LetterGrades)
+	}
+
+	err = newGradebook.Validate()
+	if err != nil {
+		return
+	}
+
+	err = newGradebook.validateItems(quizzes, assignments)
+	return
+}
+
+// ToResponseFormat converts this Gradebook to its response format.
+func (g Gradebook) ToResponseFormat() GradebookResponseFormat {
+	resp := GradebookResponseFormat{
+		CourseID:     g.CourseID,
+		Categories:   make([]GradeCategoryResponseFormat, 0, len(g.Categories)),
+		LetterGrades: make([]LetterGrade, 0, len(g.LetterGrades)),
+		UpdatedAt:    g.UpdatedAt,
+		UpdatedBy:    g.UpdatedBy.Ptr(),
+	}
+
+	for _, category := range g.Categories {
+		resp.Categories = append(resp.Categories, category.ToResponseFormat())
+	}
+
+	resp.LetterGrades = append(resp.LetterGrades, g.LetterGrades...)
+	return resp
+}
+
+// Validate validates the entity. The weights of the GradeCategories must add
+// up to 100, and every percentage must map to a LetterGrade.
+func (g *Gradebook) Validate() (err error) {
+	validator := shared.GetValidator()
+	err = validator.Struct(g)
+	if err != nil {
+		return
+	}
+
+	weights := 0
+	names := make(map[string]bool, len(g.Categories))
+	attendance := false
+	for _, category := range g.Categories {
+		name := strings.ToLower(category.Name)
+		if names[name] {
+			return fmt.Errorf("category %q is named twice", category.Name)
+		}
+
+		if category.Kind == GradeCategoryKindAttendance {
+			if attendance {
+				return fmt.Errorf("category %q is a second attendance category", category.Name)
+			}
+
+			if len(category.ItemIDs) > 0 || category.DropLowest > 0 {
+				return fmt.Errorf("category %q grades attendance, which has no items to list or drop", category.Name)
+			}
+
+			attendance = true
+		}
+
+		names[name] = true
+		weights += category.Weight
+	}
+
+	if len(g.Categories) > 0 && weights != 100 {
+		return fmt.Errorf("the weights of the categories add up to %d, not 100", weights)
+	}
+
+	letters := make(map[string]bool, len(g.LetterGrades))
+	thresholds := make(map[float64]bool, len(g.LetterGrades))
+	for _, letterGrade := range g.LetterGrades {
+		if letters[letterGrade.Letter] {
+			return fmt.Errorf("letter %q is graded twice", letterGrade.Letter)
+		}
+
+		if thresholds[letterGrade.MinPercent] {
+			return fmt.Errorf("more than one letter starts at %v%%", letterGrade.MinPercent)
+		}
+
+		letters[letterGrade.Letter] = true
+		thresholds[letterGrade.MinPercent] = true
+	}
+
+	if !thresholds[0] {
+		return errors.New("a letter must start at 0%, so that every grade has one")
+	}
+
+	return
+}
+
+// categoryIndexes maps the ID of every Quiz and Assignment that counts toward
+// this Gradebook to the index of its GradeCategory. Items belong to the
+// category listing them or, failing that, to the category of their kind that
+// lists none.
+func (g *Gradebook) categoryIndexes(quizzes []Quiz, assignments []Assignment) map[uuid.UUID]int {
+	indexes := make(map[uuid.UUID]int)
+	catchAll := make(map[GradeCategoryKind]int)
+	for i, category := range g.Categories {
+		if len(category.ItemIDs) == 0 {
+			catchAll[category.Kind] = i
+		}
+
+		for _, itemID := range category.ItemIDs {
+			indexes[itemID] = i
+		}
+	}
+
+	assign := func(itemID uuid.UUID, kind GradeCategoryKind) {
+		if _, ok := indexes[itemID]; ok {
+			return
+		}
+
+		if i, ok := catchAll[kind]; ok {
+			indexes[itemID] = i
+		}
+	}
+
+	for _, quiz := range quizzes {
+		assign(quiz.ID, GradeCategoryKindQuizzes)
+	}
+
+	for _, assignment := range assignments {
+		assign(assignment.ID, GradeCategoryKindAssignments)
+	}
+
+	return indexes
+}
+
+// validateItems makes sure the GradeCategories list only the Quizzes and
+// Assignments of the Course, each at most once, and that at most one category
+// of each kind lists none.
+func (g *Gradebook) validateItems(quizzes []Quiz, assignments []Assignment) (err error) {
+	kinds := make(map[uuid.UUID]GradeCategoryKind, len(quizzes)+len(assignments))
+	for _, quiz := range quizzes {
+		kinds[quiz.ID] = GradeCategoryKindQuizzes
+	}
+
+	for _, assignment := range assignments {
+		kinds[assignment.ID] = GradeCategoryKindAssignments
+	}
+
+	listed := make(map[uuid.UUID]bool)
+	catchAll := make(map[GradeCategoryKind]bool)
+	for _, category := range g.Categories {
+		if category.Kind == GradeCategoryKindAttendance {
+			continue
+		}
+
+		if len(category.ItemIDs) == 0 {
+			if catchAll[category.Kind] {
+				return fmt.Errorf("category %q is a second category of all other %s", category.Name, category.Kind)
+			}
+
+			catchAll[category.Kind] = true
+			continue
+		}
+
+		for _, itemID := range category.ItemIDs {
+			if kinds[itemID] != category.Kind {
+				return fmt.Errorf("category %q lists %s, which is not one of the course's %s", category.Name, itemID, category.Kind)
+			}
+
+			if listed[itemID] {
+				return fmt.Errorf("%s is listed in more than one category", itemID)
+			}
+
+			listed[itemID] = true
+		}
+	}
+
+	return
+}
+
+// GradebookRequestFormat represents a request to set the Gradebook of a
+// Course. Categories of quizzes or assignments that list no itemIDs count all
+// of those no other category lists.
+type GradebookRequestFormat struct {
+	Categories   []GradeCategoryRequestFormat `json:"categories" validate:"max=20,dive"`
+	LetterGrades []LetterGradeRequestFormat   `json:"letterGrades" validate:"max=20,dive"`
+}
+
+// GradebookResponseFormat represents a Gradebook's standard formatting for
+// JSON serializing.
+type GradebookResponseFormat struct {
+	CourseID     uuid.UUID                     `json:"courseID"`
+	Categories   []GradeCategoryResponseFormat `json:"categories"`
+	LetterGrades []LetterGrade                 `json:"letterGrades"`
+	UpdatedAt    null.Time                     `json:"updatedAt"`
+	UpdatedBy    *uuid.UUID                    `json:"updatedBy"`
+}
+
+//// Grade Category
+
+// GradeCategory is a weighted share of the final grades of a Course. Its grade
+// is the share of the points scored on its items, after dropping the lowest
+// ones. Items a student never handed in score 0 once they are closed; every
+// category with neither a graded nor a closed item is left out of the final
+// grade.
+type GradeCategory struct {
+	ID         uuid.UUID         `db:"id" validate:"required"`
+	CourseID   uuid.UUID         `db:"course_id" validate:"required"`
+	Name       string            `db:"name" validate:"required,max=100"`
+	Kind       GradeCategoryKind `db:"kind" validate:"required,oneof=quizzes assignments attendance"`
+	Weight     int               `db:"weight" validate:"min=1,max=100"`
+	DropLowest int               `db:"drop_lowest" validate:"min=0,max=50"`
+	ItemIDs    UUIDList          `db:"item_ids"`
+	Position   int               `db:"position" validate:"min=1"`
+}
+
+// ToResponseFormat converts this GradeCategory to its response format.
+func (c GradeCategory) ToResponseFormat() GradeCategoryResponseFormat {
+	itemIDs := make([]uuid.UUID, 0, len(c.ItemIDs))
+	itemIDs = append(itemIDs, c.ItemIDs...)
+
+	return GradeCategoryResponseFormat{
+		ID:         c.ID,
+		Name:       c.Name,
+		Kind:       c.Kind,
+		Weight:     c.Weight,
+		DropLowest: c.DropLowest,
+		ItemIDs:    itemIDs,
+	}
+}
+
+// GradeCategoryRequestFormat represents a GradeCategory of a request to set a
+// Gradebook.
+type GradeCategoryRequestFormat struct {
+	Name       string            `json:"name" validate:"required,max=100"`
+	Kind       GradeCategoryKind `json:"kind" validate:"required,oneof=quizzes assignments attendance"`
+	Weight     int               `json:"weight" validate:"min=1,max=100"`
+	DropLowest int               `json:"dropLowest" validate:"min=0,max=50"`
+	ItemIDs    []uuid.UUID       `json:"itemIDs" validate:"max=200"`
+}
+
+// GradeCategoryResponseFormat represents a GradeCategory's standard formatting
+// for JSON serializing.
+type GradeCategoryResponseFormat struct {
+	ID         uuid.UUID         `json:"id"`
+	Name       string            `json:"name"`
+	Kind       GradeCategoryKind `json:"kind"`
+	Weight     int               `json:"weight"`
+	DropLowest int               `json:"dropLowest"`
+	ItemIDs    []uuid.UUID       `json:"itemIDs"`
+}
+
+//// Letter Grade
+
+// LetterGrade is the letter percentages from MinPercent up are graded with,
+// up to the next LetterGrade.
+type LetterGrade struct {
+	CourseID   uuid.UUID `db:"course_id" json:"-" validate:"required"`
+	Letter     string    `db:"letter" json:"letter" validate:"required,max=5"`
+	MinPercent float64   `db:"min_percent" json:"minPercent" validate:"min=0,max=100"`
+}
+
+// LetterGradeRequestFormat represents a LetterGrade of a request to set a
+// Gradebook.
+type LetterGradeRequestFormat struct {
+	Letter     string  `json:"letter" validate:"required,max=5"`
+	MinPercent float64 `json:"minPercent" validate:"min=0,max=100"`
+}
+
+// sortLetterGrades sorts LetterGrades from the highest to the lowest.
+func sortLetterGrades(letterGrades []LetterGrade) {
+	sort.SliceStable(letterGrades, func(i, j int) bool {
+		return letterGrades[i].MinPercent > letterGrades[j].MinPercent
+	})
+}
+
+//// Grade Override
+
+// GradeOverride records a teacher setting the final grade of a student by
+// hand, or removing a previous override when neither Percent nor Letter is
+// set. Overrides are never changed: each one records the grade it replaced,
+// so together they are the audit trail of a student's final grade.
+type GradeOverride struct {
+	ID              uuid.UUID   `db:"id" validate:"required"`
+	CourseID        uuid.UUID   `db:"course_id" validate:"required"`
+	StudentID       uuid.UUID   `db:"student_id" validate:"required"`
+	Percent         null.Float  `db:"percent"`
+	Letter          null.String `db:"letter"`
+	PreviousPercent null.Float  `db:"previous_percent"`
+	PreviousLetter  null.String `db:"previous_letter"`
+	Reason          string      `db:"reason" validate:"required,max=500"`
+	ChangedAt       time.Time   `db:"changed_at" validate:"required"`
+	ChangedBy       uuid.UUID   `db:"changed_by" validate:"required"`
+}
+
+// IsRemoval checks whether a GradeOverride removes the previous one.
+func (o *GradeOverride) IsRemoval() bool {
+	return !o.Percent.Valid && !o.Letter.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (o GradeOverride) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.ToResponseFormat())
+}
+
+// NewGradeOverride overrides the current grade of a student. A letter must be
+// one of the Gradebook's; a percentage overridden without one is graded with
+// the matching letter.
+func (o GradeOverride) NewGradeOverride(gradebook Gradebook, current StudentGrade, req GradeOverrideRequestFormat, userID uuid.UUID) (newOverride GradeOverride, err error) {
+	err = shared.GetValidator().Struct(req)
+	if err != nil {
+		return
+	}
+
+	overrideID, _ := uuid.NewV4()
+	newOverride = GradeOverride{
+		ID:              overrideID,
+		CourseID:        gradebook.CourseID,
+		StudentID:       current.StudentID,
+		Percent:         null.FloatFromPtr(req.Percent),
+		Letter:          null.NewString(strings.TrimSpace(req.Letter), strings.TrimSpace(req.Letter) != ""),
+		PreviousPercent: current.Percent,
+		PreviousLetter:  current.Letter,
+		Reason:          strings.TrimSpace(req.Reason),
+		ChangedAt:       time.Now(),
+		ChangedBy:       userID,
+	}
+
+	if newOverride.IsRemoval() && current.Override == nil {
+		return newOverride, errors.New("the grade is not overridden")
+	}
+
+	if newOverride.Letter.Valid {
+		known := false
+		for _, letterGrade := range gradebook.LetterGrades {
+			known = known || letterGrade.Letter == newOverride.Letter.String
+		}
+
+		if !known {
+			return newOverride, fmt.Errorf("letter %q is not one of the gradebook's", newOverride.Letter.String)
+		}
+	}
+
+	err = newOverride.Validate()
+	return
+}
+
+// ToResponseFormat converts this GradeOverride to its response format.
+func (o GradeOverride) ToResponseFormat() GradeOverrideResponseFormat {
+	return GradeOverrideResponseFormat{
+		ID:              o.ID,
+		StudentID:       o.StudentID,
+		Percent:         o.Percent,
+		Letter:          o.Letter,
+		PreviousPercent: o.PreviousPercent,
+		PreviousLetter:  o.PreviousLetter,
+		Reason:          o.Reason,
+		ChangedAt:       o.ChangedAt,
+		ChangedBy:       o.ChangedBy,
+	}
+}
+
+// Validate validates the entity.
+func (o *GradeOverride) Validate() (err error) {
+	validator := shared.GetValidator()
+	err = validator.Struct(o)
+	if err != nil {
+		return
+	}
+
+	if o.Percent.Valid && (o.Percent.Float64 < 0 || o.Percent.Float64 > 100) {
+		return errors.New("percent must be between 0 and 100")
+	}
+
+	return
+}
+
+// GradeOverrideRequestFormat represents a request to override the final grade
+// of a student. Leaving out both percent and letter removes the override.
+type GradeOverrideRequestFormat struct {
+	Percent *float64 `json:"percent" validate:"omitempty,min=0,max=100"`
+	Letter  string   `json:"letter" validate:"max=5"`
+	Reason  string   `json:"reason" validate:"required,max=500"`
+}
+
+// GradeOverrideResponseFormat represents a GradeOverride's standard formatting
+// for JSON serializing.
+type GradeOverrideResponseFormat struct {
+	ID              uuid.UUID   `json:"id"`
+	StudentID       uuid.UUID   `json:"studentID"`
+	Percent         null.Float  `json:"percent"`
+	Letter          null.String `json:"letter"`
+	PreviousPercent null.Float  `json:"previousPercent"`
+	PreviousLetter  null.String `json:"previousLetter"`
+	Reason          string      `json:"reason"`
+	ChangedAt       time.Time   `json:"changedAt"`
+	ChangedBy       uuid.UUID   `json:"changedBy"`
+}
+
+// currentGradeOverrides returns the GradeOverride in effect for each student
+// out of the overrides of a Course, oldest first.
+func currentGradeOverrides(overrides []GradeOverride) map[uuid.UUID]GradeOverride {
+	current := make(map[uuid.UUID]GradeOverride)
+	for _, override := range overrides {
+		if override.IsRemoval() {
+			delete(current, override.StudentID)
+			continue
+		}
+
+		current[override.StudentID] = override
+	}
+
+	return current
+}
+
+//// Grades
+
+// GradebookScores are the scores of the students of a Course that their grades
+// are computed from.
+type GradebookScores struct {
+	Quizzes           []Quiz
+	Attempts          []QuizAttempt
+	Assignments       []Assignment
+	Submissions       []Submission
+	Sessions          []LiveSession
+	AttendanceRecords []AttendanceRecord
+	Cohorts           []Cohort
+}
+
+// CategoryGrade is the grade of a student in a GradeCategory. It is not set
+// while none of the category's items is graded or missing.
+type CategoryGrade struct {
+	CategoryID uuid.UUID  `json:"categoryID"`
+	Name       string     `json:"name"`
+	Weight     int        `json:"weight"`
+	Percent    null.Float `json:"percent"`
+	Items      int        `json:"items"`
+	Graded     int        `json:"graded"`
+	Missing    int        `json:"missing"`
+	Dropped    int        `json:"dropped"`
+}
+
+// StudentGrade is the final grade of a student in a Course: the weighted
+// average of their CategoryGrades, unless a teacher overrode it. Its
+// CompletionPercent is the share of the graded Quizzes and Assignments the
+// student handed in.
+type StudentGrade struct {
+	StudentID         uuid.UUID       `json:"studentID"`
+	CohortID          *uuid.UUID      `json:"cohortID"`
+	Categories        []CategoryGrade `json:"categories"`
+	CompletionPercent null.Float      `json:"completionPercent"`
+	ComputedPercent   null.Float      `json:"computedPercent"`
+	Percent           null.Float      `json:"percent"`
+	Letter            null.String     `json:"letter"`
+	Override          *GradeOverride  `json:"override"`
+}
+
+// GradeReport lists the final grades of the students of a Course.
+type GradeReport struct {
+	CourseID    uuid.UUID                     `json:"courseID"`
+	GeneratedAt time.Time                     `json:"generatedAt"`
+	Categories  []GradeCategoryResponseFormat `json:"categories"`
+	Students    []StudentGrade                `json:"students"`
+}
+
+// NewGradeReport computes the final grades of the given Enrollments out of the
+// scores of their Course and the GradeOverrides of its students, oldest first.
+func NewGradeReport(gradebook Gradebook, enrollments []Enrollment, scores GradebookScores, overrides []GradeOverride, now time.Time) GradeReport {
+	grader := newGrader(gradebook, scores)
+	current := currentGradeOverrides(overrides)
+
+	report := GradeReport{
+		CourseID:    gradebook.CourseID,
+		GeneratedAt: now,
+		Categories:  gradebook.ToResponseFormat().Categories,
+		Students:    make([]StudentGrade, 0, len(enrollments)),
+	}
+	for _, enrollment := range enrollments {
+		var override *GradeOverride
+		if o, ok := current[enrollment.StudentID]; ok {
+			override = &o
+		}
+
+		report.Students = append(report.Students, grader.grade(enrollment, override, now))
+	}
+
+	return report
+}
+
+// NewStudentGrade computes the final grade of an enrolled student out of the
+// scores of their Course and their GradeOverrides, oldest first.
+func NewStudentGrade(gradebook Gradebook, enrollment Enrollment, scores GradebookScores, overrides []GradeOverride, now time.Time) StudentGrade {
+	report := NewGradeReport(gradebook, []Enrollment{enrollment}, scores, overrides, now)
+	return report.Students[0]
+}
+
+// WriteCSV writes a GradeReport as CSV, one student per row with a column per
+// GradeCategory.
+func (r GradeReport) WriteCSV(w io.Writer) (err error) {
+	writer := csv.NewWriter(w)
+	header := []string{"student_id", "cohort_id"}
+	for _, category := range r.Categories {
+		header = append(header, category.Name)
+	}
+
+	rows := [][]string{append(header, "computed_percent", "percent", "letter", "overridden")}
+	for _, grade := range r.Students {
+		cohortID := ""
+		if grade.CohortID != nil {
+			cohortID = grade.CohortID.String()
+		}
+
+		row := []string{grade.StudentID.String(), cohortID}
+		for _, category := range grade.Categories {
+			row = append(row, formatCSVPercent(category.Percent))
+		}
+
+		rows = append(rows, append(row,
+			formatCSVPercent(grade.ComputedPercent),
+			formatCSVPercent(grade.Percent),
+			grade.Letter.String,
+			strconv.FormatBool(grade.Override != nil),
+		))
+	}
+
+	return writer.WriteAll(rows)
+}
+
+// formatCSVPercent formats an optional percentage for a CSV cell.
+func formatCSVPercent(percent null.Float) string {
+	if !percent.Valid {
+		return ""
+	}
+
+	return strconv.FormatFloat(percent.Float64, 'f', 2, 64)
+}
+
+// gradeItemScore is the score of a student on a Quiz or an Assignment.
+type gradeItemScore struct {
+	earned   float64
+	possible float64
+}
+
+// gradeItem is a Quiz or an Assignment counting toward a GradeCategory.
+// Quizzes have no deadline, so they only close with the Cohort of a student.
+type gradeItem struct {
+	id       uuid.UUID
+	possible float64
+	closesAt null.Time
+}
+
+// grader computes the grades of the students of a Course, indexing its scores
+// once for all of them.
+type grader struct {
+	gradebook Gradebook
+	// scores holds the score of each student on each graded item, by item.
+	scores  map[uuid.UUID]map[uuid.UUID]gradeItemScore
+	indexes map[uuid.UUID]int
+	// items and handedIn tell the work each student is missing.
+	items    []gradeItem
+	handedIn map[uuid.UUID]map[uuid.UUID]bool
+	cohorts  map[uuid.UUID]Cohort
+	// sessions and records are only needed to grade attendance.
+	sessions []LiveSession
+	records  map[uuid.UUID][]AttendanceRecord
+}
+
+// newGrader indexes the scores of a Course. Only the best submitted attempt at
+// a Quiz and graded Submissions count, while any Submission hands in an
+// Assignment.
+func newGrader(gradebook Gradebook, scores GradebookScores) *grader {
+	g := &grader{
+		gradebook: gradebook,
+		scores:    make(map[uuid.UUID]map[uuid.UUID]gradeItemScore),
+		indexes:   gradebook.categoryIndexes(scores.Quizzes, scores.Assignments),
+		handedIn:  make(map[uuid.UUID]map[uuid.UUID]bool),
+		cohorts:   make(map[uuid.UUID]Cohort, len(scores.Cohorts)),
+		sessions:  scores.Sessions,
+		records:   make(map[uuid.UUID][]AttendanceRecord),
+	}
+
+	for _, quiz := range scores.Quizzes {
+		if _, ok := g.indexes[quiz.ID]; ok {
+			g.items = append(g.items, gradeItem{id: quiz.ID, possible: float64(quiz.MaxScore())})
+		}
+	}
+
+	for _, assignment := range scores.Assignments {
+		if _, ok := g.indexes[assignment.ID]; ok {
+			g.items = append(g.items, gradeItem{id: assignment.ID, possible: float64(assignment.MaxScore), closesAt: assignment.LateCutoffAt})
+		}
+	}
+
+	for _, cohort := range scores.Cohorts {
+		g.cohorts[cohort.ID] = cohort
+	}
+
+	handIn := func(studentID uuid.UUID, itemID uuid.UUID) {
+		if g.handedIn[studentID] == nil {
+			g.handedIn[studentID] = make(map[uuid.UUID]bool)
+		}
+
+		g.handedIn[studentID][itemID] = true
+	}
+
+	record := func(studentID uuid.UUID, itemID uuid.UUID, score gradeItemScore) {
+		if _, ok := g.indexes[itemID]; !ok || score.possible <= 0 {
+			return
+		}
+
+		if g.scores[studentID] == nil {
+			g.scores[studentID] = make(map[uuid.UUID]gradeItemScore)
+		}
+
+		best, ok := g.scores[studentID][itemID]
+		if !ok || score.earned/score.possible > best.earned/best.possible {
+			g.scores[studentID][itemID] = score
+		}
+	}
+
+	for _, attempt := range scores.Attempts {
+		if attempt.Status == QuizAttemptStatusSubmitted {
+			handIn(attempt.StudentID, attempt.QuizID)
+			record(attempt.StudentID, attempt.QuizID, gradeItemScore{earned: float64(attempt.Score), possible: float64(attempt.MaxScore)})
+		}
+	}
+
+	maxScores := make(map[uuid.UUID]int, len(scores.Assignments))
+	for _, assignment := range scores.Assignments {
+		maxScores[assignment.ID] = assignment.MaxScore
+	}
+
+	for _, submission := range scores.Submissions {
+		handIn(submission.StudentID, submission.AssignmentID)
+		if submission.Status == SubmissionStatusGraded && submission.Score.Valid {
+			record(submission.StudentID, submission.AssignmentID, gradeItemScore{earned: submission.Score.Float64, possible: float64(maxScores[submission.AssignmentID])})
+		}
+	}
+
+	for _, attendanceRecord := range scores.AttendanceRecords {
+		g.records[attendanceRecord.StudentID] = append(g.records[attendanceRecord.StudentID], attendanceRecord)
+	}
+
+	return g
+}
+
+// grade computes the final grade of an enrolled student.
+func (g *grader) grade(enrollment Enrollment, override *GradeOverride, now time.Time) StudentGrade {
+	grade := StudentGrade{
+		StudentID:  enrollment.StudentID,
+		CohortID:   enrollment.CohortID.Ptr(),
+		Categories: make([]CategoryGrade, 0, len(g.gradebook.Categories)),
+		Override:   override,
+	}
+
+	scores, missing := g.missingWork(enrollment, now)
+	if len(g.items) > 0 {
+		var handedIn int
+		for _, item := range g.items {
+			if g.handedIn[enrollment.StudentID][item.id] {
+				handedIn++
+			}
+		}
+
+		grade.CompletionPercent = null.FloatFrom(roundPercent(float64(handedIn) / float64(len(g.items)) * 100))
+	}
+
+	var weighted, weights float64
+	for i, category := range g.gradebook.Categories {
+		categoryGrade := CategoryGrade{CategoryID: category.ID, Name: category.Name, Weight: category.Weight}
+		if category.Kind == GradeCategoryKindAttendance {
+			summary := NewStudentAttendanceReport(enrollment, g.sessions, g.records[enrollment.StudentID], now).Summary
+			categoryGrade.Percent = summary.AttendancePercentage
+			categoryGrade.Graded = summary.Sessions - summary.Excused
+		} else {
+			categoryGrade = g.gradeItems(categoryGrade, i, category.DropLowest, scores, missing)
+		}
+
+		if categoryGrade.Percent.Valid {
+			weighted += categoryGrade.Percent.Float64 * float64(category.Weight)
+			weights += float64(category.Weight)
+		}
+
+		grade.Categories = append(grade.Categories, categoryGrade)
+	}
+
+	if weights > 0 {
+		grade.ComputedPercent = null.FloatFrom(roundPercent(weighted / weights))
+	}
+
+	grade.Percent = grade.ComputedPercent
+	if override != nil && override.Percent.Valid {
+		grade.Percent = override.Percent
+	}
+
+	if grade.Percent.Valid {
+		grade.Letter = g.gradebook.Letter(grade.Percent.Float64)
+	}
+
+	if override != nil && override.Letter.Valid {
+		grade.Letter = override.Letter
+	}
+
+	return grade
+}
+
+// gradeItems grades the items of the category at index i a student was scored
+// on or is missing, dropping the lowest ones but always keeping one.
+func (g *grader) gradeItems(categoryGrade CategoryGrade, i int, dropLowest int, scores map[uuid.UUID]gradeItemScore, missing map[uuid.UUID]bool) CategoryGrade {
+	for _, item := range g.items {
+		if g.indexes[item.id] == i {
+			categoryGrade.Items++
+		}
+	}
+
+	items := make([]gradeItemScore, 0)
+	for itemID, score := range scores {
+		if g.indexes[itemID] != i {
+			continue
+		}
+
+		if missing[itemID] {
+			categoryGrade.Missing++
+		}
+
+		items = append(items, score)
+	}
+
+	if len(items) == 0 {
+		return categoryGrade
+	}
+
+	sort.Slice(items, func(a, b int) bool {
+		ratioA, ratioB := items[a].earned/items[a].possible, items[b].earned/items[b].possible
+		if ratioA != ratioB {
+			return ratioA < ratioB
+		}
+
+		return items[a].possible > items[b].possible
+	})
+
+	categoryGrade.Dropped = dropLowest
+	if categoryGrade.Dropped > len(items)-1 {
+		categoryGrade.Dropped = len(items) - 1
+	}
+
+	var earned, possible float64
+	for _, item := range items[categoryGrade.Dropped:] {
+		earned += item.earned
+		possible += item.possible
+	}
+
+	categoryGrade.Graded = len(items) - categoryGrade.Missing
+	categoryGrade.Percent = null.FloatFrom(roundPercent(earned / possible * 100))
+	return categoryGrade
+}
+
+// missingWork adds a score of 0 to the scores of a student for each closed item
+// they never handed in: Assignments past their late cutoff, and every item
+// once the student's Cohort has ended.
+func (g *grader) missingWork(enrollment Enrollment, now time.Time) (scores map[uuid.UUID]gradeItemScore, missing map[uuid.UUID]bool) {
+	scores = make(map[uuid.UUID]gradeItemScore, len(g.items))
+	for itemID, score := range g.scores[enrollment.StudentID] {
+		scores[itemID] = score
+	}
+
+	cohortEnded := false
+	if cohort, ok := g.cohorts[enrollment.CohortID.UUID]; ok && enrollment.CohortID.Valid {
+		cohortEnded = !now.Before(cohort.EndsAt())
+	}
+
+	missing = make(map[uuid.UUID]bool)
+	for _, item := range g.items {
+		if g.handedIn[enrollment.StudentID][item.id] || item.possible <= 0 {
+			continue
+		}
+
+		if cohortEnded || (item.closesAt.Valid && now.After(item.closesAt.Time)) {
+			scores[item.id] = gradeItemScore{possible: item.possible}
+			missing[item.id] = true
+		}
+	}
+
+	return
+}
+
+// roundPercent rounds a percentage to two decimals.
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
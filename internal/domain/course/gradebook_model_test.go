@@ -0,0 +1,215 @@
+package course_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradebook(t *testing.T) {
+	courseID, teacherID := getRandomUUID(), getRandomUUID()
+	questions := []course.QuizQuestion{{Points: 10}}
+	quizzes := []course.Quiz{{ID: getRandomUUID(), Questions: questions}, {ID: getRandomUUID(), Questions: questions}, {ID: getRandomUUID(), Questions: questions}}
+	assignments := []course.Assignment{{ID: getRandomUUID(), MaxScore: 50}, {ID: getRandomUUID(), MaxScore: 100}}
+	final := assignments[1].ID
+
+	newGradebook := func(t *testing.T, req course.GradebookRequestFormat) course.Gradebook {
+		gradebook, err := course.Gradebook{}.NewGradebookFromRequestFormat(courseID, req, quizzes, assignments, teacherID)
+		assert.NoError(t, err)
+		return gradebook
+	}
+
+	gradebookRequest := course.GradebookRequestFormat{
+		Categories: []course.GradeCategoryRequestFormat{
+			{Name: "Quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 30, DropLowest: 1},
+			{Name: "Homework", Kind: course.GradeCategoryKindAssignments, Weight: 30},
+			{Name: "Final project", Kind: course.GradeCategoryKindAssignments, Weight: 40, ItemIDs: []uuid.UUID{final}},
+		},
+	}
+
+	t.Run("gradebooks are validated", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			categories []course.GradeCategoryRequestFormat
+			letters    []course.LetterGradeRequestFormat
+		}{
+			{name: "weights", categories: []course.GradeCategoryRequestFormat{
+				{Name: "Quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 60},
+			}},
+			{name: "items of another kind", categories: []course.GradeCategoryRequestFormat{
+				{Name: "Quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 100, ItemIDs: []uuid.UUID{final}},
+			}},
+			{name: "items of another course", categories: []course.GradeCategoryRequestFormat{
+				{Name: "Quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 100, ItemIDs: []uuid.UUID{getRandomUUID()}},
+			}},
+			{name: "two categories of all quizzes", categories: []course.GradeCategoryRequestFormat{
+				{Name: "Quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 50},
+				{Name: "More quizzes", Kind: course.GradeCategoryKindQuizzes, Weight: 50},
+			}},
+			{name: "dropped attendance", categories: []course.GradeCategoryRequestFormat{
+				{Name: "Attendance", Kind: course.GradeCategoryKindAttendance, Weight: 100, DropLowest: 1},
+			}},
+			{name: "no letter from 0", letters: []course.LetterGradeRequestFormat{{Letter: "P", MinPercent: 50}}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := course.Gradebook{}.NewGradebookFromRequestFormat(courseID, course.GradebookRequestFormat{
+					Categories:   test.categories,
+					LetterGrades: test.letters,
+				}, quizzes, assignments, teacherID)
+
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("letter grades default and map percentages", func(t *testing.T) {
+		gradebook := newGradebook(t, gradebookRequest)
+		assert.Equal(t, "A", gradebook.Letter(90).String)
+		assert.Equal(t, "B", gradebook.Letter(89.99).String)
+		assert.Equal(t, "F", gradebook.Letter(0).String)
+
+		custom := newGradebook(t, course.GradebookRequestFormat{LetterGrades: []course.LetterGradeRequestFormat{
+			{Letter: "Fail", MinPercent: 0},
+			{Letter: "Pass", MinPercent: 55},
+		}})
+		assert.Equal(t, "Pass", custom.LetterGrades[0].Letter)
+		assert.Equal(t, "Fail", custom.Letter(54.9).String)
+	})
+
+	studentID := getRandomUUID()
+	enrollment := course.Enrollment{CourseID: courseID, StudentID: studentID, Status: course.EnrollmentStatusActive}
+	attempt := func(quizID uuid.UUID, score int, status course.QuizAttemptStatus) course.QuizAttempt {
+		return course.QuizAttempt{QuizID: quizID, StudentID: studentID, Status: status, Score: score, MaxScore: 10}
+	}
+
+	scores := course.GradebookScores{
+		Quizzes: quizzes,
+		Attempts: []course.QuizAttempt{
+			attempt(quizzes[0].ID, 4, course.QuizAttemptStatusSubmitted),
+			attempt(quizzes[0].ID, 9, course.QuizAttemptStatusSubmitted),
+			attempt(quizzes[1].ID, 10, course.QuizAttemptStatusInProgress),
+			attempt(quizzes[1].ID, 2, course.QuizAttemptStatusSubmitted),
+			attempt(quizzes[2].ID, 7, course.QuizAttemptStatusSubmitted),
+		},
+		Assignments: assignments,
+		Submissions: []course.Submission{
+			{AssignmentID: assignments[0].ID, StudentID: studentID, Status: course.SubmissionStatusGraded, Score: null.FloatFrom(40)},
+			{AssignmentID: final, StudentID: studentID, Status: course.SubmissionStatusInReview},
+		},
+	}
+
+	t.Run("final grades weigh the graded categories", func(t *testing.T) {
+		grade := course.NewStudentGrade(newGradebook(t, gradebookRequest), enrollment, scores, nil, time.Now())
+
+		// quizzes keep the best attempts at 9/10 and 7/10, dropping 2/10
+		assert.Equal(t, null.FloatFrom(80), grade.Categories[0].Percent)
+		assert.Equal(t, 3, grade.Categories[0].Graded)
+		assert.Equal(t, 1, grade.Categories[0].Dropped)
+		assert.Equal(t, null.FloatFrom(80), grade.Categories[1].Percent)
+		assert.False(t, grade.Categories[2].Percent.Valid, "the final project is not graded yet")
+		assert.Equal(t, null.FloatFrom(80), grade.ComputedPercent)
+		assert.Equal(t, null.FloatFrom(100), grade.CompletionPercent)
+		assert.Equal(t, "B", grade.Letter.String)
+	})
+
+	t.Run("missing work counts as zero once it closes", func(t *testing.T) {
+		cohort, err := course.Cohort{}.NewCohortFromRequestFormat(courseID, newCohortRequest(), teacherID)
+		assert.NoError(t, err)
+
+		skipper := course.Enrollment{CourseID: courseID, StudentID: getRandomUUID(), Status: course.EnrollmentStatusActive}
+		withCutoff := append([]course.Assignment{}, assignments...)
+		withCutoff[0].LateCutoffAt = null.TimeFrom(cohort.EndsAt().Add(-7 * 24 * time.Hour))
+		skipped := course.GradebookScores{
+			Quizzes:     quizzes,
+			Attempts:    []course.QuizAttempt{{QuizID: quizzes[0].ID, StudentID: skipper.StudentID, Status: course.QuizAttemptStatusSubmitted, Score: 9, MaxScore: 10}},
+			Assignments: withCutoff,
+			Cohorts:     []course.Cohort{cohort},
+		}
+		gradebook := newGradebook(t, gradebookRequest)
+
+		grade := course.NewStudentGrade(gradebook, skipper, skipped, nil, cohort.EndsAt().Add(-time.Hour))
+
+		// quizzes stay open until the cohort ends, homework closed at its cutoff
+		assert.Equal(t, null.FloatFrom(90), grade.Categories[0].Percent)
+		assert.Equal(t, 0, grade.Categories[0].Missing)
+		assert.Equal(t, null.FloatFrom(0), grade.Categories[1].Percent)
+		assert.Equal(t, 1, grade.Categories[1].Missing)
+		assert.False(t, grade.Categories[2].Percent.Valid, "the final project accepts late submissions")
+		assert.Equal(t, null.FloatFrom(45), grade.ComputedPercent)
+		assert.Equal(t, null.FloatFrom(20), grade.CompletionPercent)
+
+		skipper.CohortID = nuuid.From(cohort.ID)
+		grade = course.NewStudentGrade(gradebook, skipper, skipped, nil, cohort.EndsAt())
+
+		// 9/10 on the only quiz taken, one of the two missed quizzes dropped
+		assert.Equal(t, null.FloatFrom(45), grade.Categories[0].Percent)
+		assert.Equal(t, 3, grade.Categories[0].Items)
+		assert.Equal(t, 1, grade.Categories[0].Graded)
+		assert.Equal(t, 2, grade.Categories[0].Missing)
+		assert.Equal(t, 1, grade.Categories[0].Dropped)
+		assert.Equal(t, null.FloatFrom(0), grade.Categories[2].Percent)
+		assert.Equal(t, null.FloatFrom(13.5), grade.ComputedPercent)
+		assert.Equal(t, "F", grade.Letter.String)
+	})
+
+	t.Run("ungraded gradebooks have no grade", func(t *testing.T) {
+		grade := course.NewStudentGrade(course.Gradebook{}.NewDefaultGradebook(courseID), enrollment, scores, nil, time.Now())
+
+		assert.False(t, grade.Percent.Valid)
+		assert.False(t, grade.Letter.Valid)
+	})
+
+	t.Run("overrides replace and record the computed grade", func(t *testing.T) {
+		gradebook := newGradebook(t, gradebookRequest)
+		current := course.NewStudentGrade(gradebook, enrollment, scores, nil, time.Now())
+
+		percent := 91.5
+		override, err := course.GradeOverride{}.NewGradeOverride(gradebook, current, course.GradeOverrideRequestFormat{Percent: &percent, Reason: "Regraded the midterm"}, teacherID)
+		assert.NoError(t, err)
+		assert.Equal(t, null.FloatFrom(80), override.PreviousPercent)
+		assert.Equal(t, null.StringFrom("B"), override.PreviousLetter)
+		assert.Equal(t, teacherID, override.ChangedBy)
+
+		grade := course.NewStudentGrade(gradebook, enrollment, scores, []course.GradeOverride{override}, time.Now())
+		assert.Equal(t, null.FloatFrom(80), grade.ComputedPercent)
+		assert.Equal(t, null.FloatFrom(91.5), grade.Percent)
+		assert.Equal(t, "A", grade.Letter.String)
+
+		removal, err := course.GradeOverride{}.NewGradeOverride(gradebook, grade, course.GradeOverrideRequestFormat{Reason: "Back to the computed grade"}, teacherID)
+		assert.NoError(t, err)
+		assert.True(t, removal.IsRemoval())
+
+		grade = course.NewStudentGrade(gradebook, enrollment, scores, []course.GradeOverride{override, removal}, time.Now())
+		assert.Nil(t, grade.Override)
+		assert.Equal(t, null.FloatFrom(80), grade.Percent)
+
+		_, err = course.GradeOverride{}.NewGradeOverride(gradebook, grade, course.GradeOverrideRequestFormat{Letter: "Z", Reason: "Typo"}, teacherID)
+		assert.Error(t, err, "letters must be the gradebook's")
+
+		_, err = course.GradeOverride{}.NewGradeOverride(gradebook, grade, course.GradeOverrideRequestFormat{Reason: "Nothing to remove"}, teacherID)
+		assert.Error(t, err)
+	})
+
+	t.Run("grades export with a column per category", func(t *testing.T) {
+		cohortID := getRandomUUID()
+		inCohort := enrollment
+		inCohort.CohortID = nuuid.From(cohortID)
+		report := course.NewGradeReport(newGradebook(t, gradebookRequest), []course.Enrollment{inCohort}, scores, nil, time.Now())
+
+		var buf bytes.Buffer
+		assert.NoError(t, report.WriteCSV(&buf))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Equal(t, "student_id,cohort_id,Quizzes,Homework,Final project,computed_percent,percent,letter,overridden", lines[0])
+		assert.Equal(t, studentID.String()+","+cohortID.String()+",80.00,80.00,,80.00,80.00,B,false", lines[1])
+	})
+}
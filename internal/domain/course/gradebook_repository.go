@@ -0,0 +1,296 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source gradebook_repository.go -destination mock/gradebook_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	gradebookQueries = struct {
+		selectGradebook       string
+		upsertGradebook       string
+		selectGradeCategory   string
+		insertGradeCategory   string
+		deleteGradeCategories string
+		selectLetterGrade     string
+		insertLetterGrade     string
+		deleteLetterGrades    string
+		selectGradeOverride   string
+		insertGradeOverride   string
+	}{
+		selectGradebook: `
+			SELECT
+				course_id,
+				updated_at,
+				updated_by
+			FROM gradebooks
+		`,
+
+		upsertGradebook: `
+			INSERT INTO gradebooks (
+				course_id,
+				updated_at,
+				updated_by
+			) VALUES (
+				:course_id,
+				:updated_at,
+				:updated_by
+			)
+			ON DUPLICATE KEY UPDATE
+				updated_at = VALUES(updated_at),
+				updated_by = VALUES(updated_by)
+		`,
+
+		selectGradeCategory: `
+			SELECT
+				id,
+				course_id,
+				name,
+				kind,
+				weight,
+				drop_lowest,
+				item_ids,
+				position
+			FROM gradebook_categories
+		`,
+
+		insertGradeCategory: `
+			INSERT INTO gradebook_categories (
+				id,
+				course_id,
+				name,
+				kind,
+				weight,
+				drop_lowest,
+				item_ids,
+				position
+			) VALUES (
+				:id,
+				:course_id,
+				:name,
+				:kind,
+				:weight,
+				:drop_lowest,
+				:item_ids,
+				:position
+			)
+		`,
+
+		deleteGradeCategories: `
+			DELETE FROM gradebook_categories
+			WHERE course_id = ?
+		`,
+
+		selectLetterGrade: `
+			SELECT
+				course_id,
+				letter,
+				min_percent
+			FROM gradebook_letter_grades
+		`,
+
+		insertLetterGrade: `
+			INSERT INTO gradebook_letter_grades (
+				course_id,
+				letter,
+				min_percent
+			) VALUES (
+				:course_id,
+				:letter,
+				:min_percent
+			)
+		`,
+
+		deleteLetterGrades: `
+			DELETE FROM gradebook_letter_grades
+			WHERE course_id = ?
+		`,
+
+		selectGradeOverride: `
+			SELECT
+				id,
+				course_id,
+				student_id,
+				percent,
+				letter,
+				previous_percent,
+				previous_letter,
+				reason,
+				changed_at,
+				changed_by
+			FROM grade_overrides
+		`,
+
+		insertGradeOverride: `
+			INSERT INTO grade_overrides (
+				id,
+				course_id,
+				student_id,
+				percent,
+				letter,
+				previous_percent,
+				previous_letter,
+				reason,
+				changed_at,
+				changed_by
+			) VALUES (
+				:id,
+				:course_id,
+				:student_id,
+				:percent,
+				:letter,
+				:previous_percent,
+				:previous_letter,
+				:reason,
+				:changed_at,
+				:changed_by
+			)
+		`,
+	}
+)
+
+// GradebookRepository is the repository for Gradebooks and GradeOverrides.
+type GradebookRepository interface {
+	CreateGradeOverride(override GradeOverride) (err error)
+	ResolveGradebook(courseID uuid.UUID) (gradebook Gradebook, err error)
+	ResolveGradeOverrides(courseID uuid.UUID, studentID nuuid.NUUID) (overrides []GradeOverride, err error)
+	SaveGradebook(gradebook Gradebook) (err error)
+}
+
+// GradebookRepositoryMySQL is the MySQL-backed implementation of GradebookRepository.
+type GradebookRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideGradebookRepositoryMySQL is the provider for this repository.
+func ProvideGradebookRepositoryMySQL(db *infras.MySQLConn) *GradebookRepositoryMySQL {
+	s := new(GradebookRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateGradeOverride records a GradeOverride.
+func (r *GradebookRepositoryMySQL) CreateGradeOverride(override GradeOverride) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, gradebookQueries.insertGradeOverride, override); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveGradebook resolves the Gradebook of a Course with its GradeCategories
+// and LetterGrades attached.
+func (r *GradebookRepositoryMySQL) ResolveGradebook(courseID uuid.UUID) (gradebook Gradebook, err error) {
+	err = r.DB.Read.Get(&gradebook, gradebookQueries.selectGradebook+" WHERE course_id = ?", courseID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("gradebook")
+		}
+
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	var categories []GradeCategory
+	err = r.DB.Read.Select(&categories, gradebookQueries.selectGradeCategory+" WHERE course_id = ? ORDER BY position", courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	var letterGrades []LetterGrade
+	err = r.DB.Read.Select(&letterGrades, gradebookQueries.selectLetterGrade+" WHERE course_id = ?", courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	gradebook.Categories = make([]GradeCategory, 0, len(categories))
+	gradebook.AttachCategories(categories)
+	return gradebook.AttachLetterGrades(letterGrades), nil
+}
+
+// ResolveGradeOverrides resolves the GradeOverrides of a Course, or of one of
+// its students when studentID is valid, oldest first.
+func (r *GradebookRepositoryMySQL) ResolveGradeOverrides(courseID uuid.UUID, studentID nuuid.NUUID) (overrides []GradeOverride, err error) {
+	query := gradebookQueries.selectGradeOverride + " WHERE course_id = ?"
+	args := []interface{}{courseID.String()}
+
+	if studentID.Valid {
+		query += " AND student_id = ?"
+		args = append(args, studentID.UUID.String())
+	}
+
+	err = r.DB.Read.Select(&overrides, query+" ORDER BY changed_at", args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SaveGradebook creates or replaces the Gradebook of a Course with its
+// GradeCategories and LetterGrades.
+func (r *GradebookRepositoryMySQL) SaveGradebook(gradebook Gradebook) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, gradebookQueries.upsertGradebook, gradebook); err != nil {
+			e <- err
+			return
+		}
+
+		for _, query := range []string{gradebookQueries.deleteGradeCategories, gradebookQueries.deleteLetterGrades} {
+			if _, err := tx.Exec(query, gradebook.CourseID.String()); err != nil {
+				logger.ErrorWithStack(err)
+				e <- err
+				return
+			}
+		}
+
+		for _, category := range gradebook.Categories {
+			if err := r.txExecNamed(tx, gradebookQueries.insertGradeCategory, category); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		for _, letterGrade := range gradebook.LetterGrades {
+			if err := r.txExecNamed(tx, gradebookQueries.insertLetterGrade, letterGrade); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *GradebookRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,283 @@
+package course
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+)
+
+// GradebookService is the service interface for Gradebooks and the final
+// grades they compute.
+type GradebookService interface {
+	OverrideGrade(courseID uuid.UUID, studentID uuid.UUID, requestFormat GradeOverrideRequestFormat, userID uuid.UUID) (grade StudentGrade, err error)
+	ResolveGradebook(courseID uuid.UUID, userID uuid.UUID) (gradebook Gradebook, err error)
+	ResolveGradeOverrides(courseID uuid.UUID, studentID nuuid.NUUID, userID uuid.UUID) (overrides []GradeOverride, err error)
+	ResolveGrades(courseID uuid.UUID, userID uuid.UUID) (report GradeReport, err error)
+	ResolveMyGrade(courseID uuid.UUID, studentID uuid.UUID) (grade StudentGrade, err error)
+	UpdateGradebook(courseID uuid.UUID, requestFormat GradebookRequestFormat, userID uuid.UUID) (gradebook Gradebook, err error)
+}
+
+// GradebookServiceImpl is the service implementation for Gradebooks and the
+// final grades they compute.
+type GradebookServiceImpl struct {
+	GradebookRepository    GradebookRepository
+	AssignmentRepository   AssignmentRepository
+	AttendanceRepository   AttendanceRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	QuizRepository         QuizRepository
+}
+
+// ProvideGradebookServiceImpl is the provider for this service.
+func ProvideGradebookServiceImpl(
+	gradebookRepository GradebookRepository,
+	assignmentRepository AssignmentRepository,
+	attendanceRepository AttendanceRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	quizRepository QuizRepository) *GradebookServiceImpl {
+	s := new(GradebookServiceImpl)
+	s.GradebookRepository = gradebookRepository
+	s.AssignmentRepository = assignmentRepository
+	s.AttendanceRepository = attendanceRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.QuizRepository = quizRepository
+
+	return s
+}
+
+// OverrideGrade sets the final grade of an enrolled student by hand, or
+// removes the override, for those allowed to grade in the Course. Every change
+// is recorded along with the grade it replaced.
+func (s *GradebookServiceImpl) OverrideGrade(courseID uuid.UUID, studentID uuid.UUID, requestFormat GradeOverrideRequestFormat, userID uuid.UUID) (grade StudentGrade, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(course.ID, studentID)
+	if err != nil {
+		return
+	}
+
+	gradebook, current, err := s.resolveStudentGrade(enrollment)
+	if err != nil {
+		return
+	}
+
+	override, err := GradeOverride{}.NewGradeOverride(gradebook, current, requestFormat, userID)
+	if err != nil {
+		return grade, failure.BadRequest(err)
+	}
+
+	err = s.GradebookRepository.CreateGradeOverride(override)
+	if err != nil {
+		return
+	}
+
+	_, grade, err = s.resolveStudentGrade(enrollment)
+	return
+}
+
+// ResolveGradebook resolves the Gradebook of a Course for those allowed to
+// grade in it.
+func (s *GradebookServiceImpl) ResolveGradebook(courseID uuid.UUID, userID uuid.UUID) (gradebook Gradebook, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	return s.resolveGradebook(course.ID)
+}
+
+// ResolveGradeOverrides resolves the audit trail of the final grades of a
+// Course, or of one of its students when studentID is valid, for those allowed
+// to grade in it.
+func (s *GradebookServiceImpl) ResolveGradeOverrides(courseID uuid.UUID, studentID nuuid.NUUID, userID uuid.UUID) (overrides []GradeOverride, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	return s.GradebookRepository.ResolveGradeOverrides(course.ID, studentID)
+}
+
+// ResolveGrades computes the final grades of the actively enrolled students of
+// a Course, for those allowed to grade in it.
+func (s *GradebookServiceImpl) ResolveGrades(courseID uuid.UUID, userID uuid.UUID) (report GradeReport, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionGradeSubmissions)
+	if err != nil {
+		return
+	}
+
+	gradebook, err := s.resolveGradebook(course.ID)
+	if err != nil {
+		return
+	}
+
+	enrollments, err := s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: course.ID,
+		Status:   EnrollmentStatusActive,
+	})
+	if err != nil {
+		return
+	}
+
+	scores, err := s.resolveScores(gradebook)
+	if err != nil {
+		return
+	}
+
+	overrides, err := s.GradebookRepository.ResolveGradeOverrides(course.ID, nuuid.NUUID{})
+	if err != nil {
+		return
+	}
+
+	return NewGradeReport(gradebook, enrollments, scores, overrides, time.Now()), nil
+}
+
+// ResolveMyGrade computes the final grade of an actively enrolled student.
+func (s *GradebookServiceImpl) ResolveMyGrade(courseID uuid.UUID, studentID uuid.UUID) (grade StudentGrade, err error) {
+	enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil && failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	if err != nil || !enrollment.IsActive() {
+		return grade, failure.Forbidden("an active enrollment is required to see your grade")
+	}
+
+	_, grade, err = s.resolveStudentGrade(enrollment)
+	return
+}
+
+// UpdateGradebook sets the Gradebook of a Course the given user may edit.
+func (s *GradebookServiceImpl) UpdateGradebook(courseID uuid.UUID, requestFormat GradebookRequestFormat, userID uuid.UUID) (gradebook Gradebook, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	quizzes, err := s.QuizRepository.ResolveQuizzesByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	assignments, err := s.AssignmentRepository.ResolveAssignmentsByCourseID(course.ID)
+	if err != nil {
+		return
+	}
+
+	gradebook, err = Gradebook{}.NewGradebookFromRequestFormat(course.ID, requestFormat, quizzes, assignments, userID)
+	if err != nil {
+		return gradebook, failure.BadRequest(err)
+	}
+
+	err = s.GradebookRepository.SaveGradebook(gradebook)
+	return
+}
+
+// resolveGradebook resolves the Gradebook of a Course, or the default one if
+// it didn't set one.
+func (s *GradebookServiceImpl) resolveGradebook(courseID uuid.UUID) (gradebook Gradebook, err error) {
+	gradebook, err = s.GradebookRepository.ResolveGradebook(courseID)
+	if err != nil && failure.GetCode(err) == http.StatusNotFound {
+		return Gradebook{}.NewDefaultGradebook(courseID), nil
+	}
+
+	return
+}
+
+// resolveScores resolves the scores of the students of a Course that count
+// toward its Gradebook, with the Quiz questions and Cohorts that tell the work
+// students are missing. Attendance is only resolved when it is graded.
+func (s *GradebookServiceImpl) resolveScores(gradebook Gradebook) (scores GradebookScores, err error) {
+	scores.Quizzes, err = s.QuizRepository.ResolveQuizzesByCourseID(gradebook.CourseID)
+	if err != nil {
+		return
+	}
+
+	quizIDs := make([]uuid.UUID, 0, len(scores.Quizzes))
+	for _, quiz := range scores.Quizzes {
+		quizIDs = append(quizIDs, quiz.ID)
+	}
+
+	questions, err := s.QuizRepository.ResolveQuestionsByQuizIDs(quizIDs)
+	if err != nil {
+		return
+	}
+
+	for i := range scores.Quizzes {
+		scores.Quizzes[i].AttachQuestions(questions)
+	}
+
+	scores.Attempts, err = s.QuizRepository.ResolveSubmittedAttemptsByCourseID(gradebook.CourseID)
+	if err != nil {
+		return
+	}
+
+	scores.Assignments, err = s.AssignmentRepository.ResolveAssignmentsByCourseID(gradebook.CourseID)
+	if err != nil {
+		return
+	}
+
+	scores.Submissions, err = s.AssignmentRepository.ResolveSubmissions(SubmissionQueryParameters{CourseID: gradebook.CourseID})
+	if err != nil {
+		return
+	}
+
+	scores.Cohorts, err = s.CohortRepository.ResolveCohortsByCourseID(gradebook.CourseID)
+	if err != nil {
+		return
+	}
+
+	for _, category := range gradebook.Categories {
+		if category.Kind != GradeCategoryKindAttendance {
+			continue
+		}
+
+		scores.Sessions, err = s.LiveSessionRepository.ResolveLiveSessionsByCourseID(gradebook.CourseID)
+		if err != nil {
+			return
+		}
+
+		scores.AttendanceRecords, err = s.AttendanceRepository.ResolveAttendanceByCourseID(gradebook.CourseID)
+		return
+	}
+
+	return
+}
+
+// resolveStudentGrade computes the final grade of an enrolled student, along
+// with the Gradebook of their Course.
+func (s *GradebookServiceImpl) resolveStudentGrade(enrollment Enrollment) (gradebook Gradebook, grade StudentGrade, err error) {
+	gradebook, err = s.resolveGradebook(enrollment.CourseID)
+	if err != nil {
+		return
+	}
+
+	scores, err := s.resolveScores(gradebook)
+	if err != nil {
+		return
+	}
+
+	overrides, err := s.GradebookRepository.ResolveGradeOverrides(enrollment.CourseID, nuuid.From(enrollment.StudentID))
+	if err != nil {
+		return
+	}
+
+	return gradebook, NewStudentGrade(gradebook, enrollment, scores, overrides, time.Now()), nil
+}
@@ -0,0 +1,115 @@
+package course
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// iCalendarProductID identifies this service as the producer of calendars.
+	iCalendarProductID = "-//Evermos//Courses//EN"
+
+	// iCalendarTimeFormat is the iCalendar DATE-TIME format in UTC.
+	iCalendarTimeFormat = "20060102T150405Z"
+
+	// iCalendarLineLength is the longest a content line may be, in octets,
+	// before it has to be folded.
+	iCalendarLineLength = 75
+)
+
+// iCalendarTextEscaper escapes TEXT property values.
+var iCalendarTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", "",
+)
+
+// RenderICalendar renders the occurrences of LiveSessions as an RFC 5545
+// iCalendar stream. Recurring sessions are expanded into one event per
+// occurrence, in UTC, so calendar apps show them at the right time without
+// having to resolve time zones or recurrence rules themselves. Event UIDs are
+// stable across renders, scoped with uidDomain.
+func RenderICalendar(name string, sessions []LiveSession, uidDomain string, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	w := iCalendarWriter{buf: &buf}
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", iCalendarProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", name)
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, session := range sessions {
+		modified := session.CreatedAt
+		if session.UpdatedAt.Valid {
+			modified = session.UpdatedAt.Time
+		}
+
+		for _, occurrence := range session.Occurrences(time.Time{}, time.Time{}) {
+			description := occurrence.Description
+			if description != "" {
+				description += "\n\n"
+			}
+			description += "Join: " + occurrence.MeetingURL
+
+			w.line("BEGIN", "VEVENT")
+			w.line("UID", fmt.Sprintf("%s-%d@%s", session.ID, occurrence.Sequence, uidDomain))
+			w.line("DTSTAMP", stamp.UTC().Format(iCalendarTimeFormat))
+			w.line("DTSTART", occurrence.StartsAt.UTC().Format(iCalendarTimeFormat))
+			w.line("DTEND", occurrence.EndsAt.UTC().Format(iCalendarTimeFormat))
+			w.line("LAST-MODIFIED", modified.UTC().Format(iCalendarTimeFormat))
+			w.text("SUMMARY", occurrence.Title)
+			w.text("DESCRIPTION", description)
+			w.text("LOCATION", occurrence.MeetingURL)
+			w.line("URL", occurrence.MeetingURL)
+			w.line("END", "VEVENT")
+		}
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// iCalendarWriter writes iCalendar content lines.
+type iCalendarWriter struct {
+	buf *bytes.Buffer
+}
+
+// line writes a content line, folding it so that no line is longer than
+// iCalendarLineLength octets. Folds never split a UTF-8 character.
+func (w iCalendarWriter) line(name string, value string) {
+	content := name + ":" + value
+
+	limit := iCalendarLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+
+		w.buf.WriteString(content[:cut])
+		w.buf.WriteString("\r\n ")
+		content = content[cut:]
+
+		// continuation lines start with a space, which counts toward the limit
+		limit = iCalendarLineLength - 1
+	}
+
+	w.buf.WriteString(content)
+	w.buf.WriteString("\r\n")
+}
+
+// text writes a content line holding a TEXT value.
+func (w iCalendarWriter) text(name string, value string) {
+	w.line(name, iCalendarTextEscaper.Replace(value))
+}
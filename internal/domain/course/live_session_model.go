@@ -0,0 +1,640 @@
+package course
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+const (
+	// maxLiveSessionOccurrences bounds how many times a LiveSession recurs.
+	maxLiveSessionOccurrences = 500
+
+	// maxRecurrenceDays bounds how long after its first occurrence a
+	// LiveSession recurring until a date may keep recurring.
+	maxRecurrenceDays = 366
+)
+
+// errInvalidFeedToken is returned for calendar feed tokens that were not
+// issued for the requested Course.
+var errInvalidFeedToken = failure.Unauthorized("invalid calendar feed token")
+
+//// Live Session
+
+// LiveSession is a live class of a Course, optionally held for a single
+// Cohort only. A recurring LiveSession keeps the local time of its first
+// occurrence in its time zone, whatever the daylight saving time.
+type LiveSession struct {
+	ID              uuid.UUID   `db:"id" validate:"required"`
+	CourseID        uuid.UUID   `db:"course_id" validate:"required"`
+	CohortID        nuuid.NUUID `db:"cohort_id"`
+	Title           string      `db:"title" validate:"required,max=200"`
+	Description     string      `db:"description" validate:"max=2000"`
+	StartsAt        time.Time   `db:"starts_at" validate:"required"`
+	Timezone        string      `db:"timezone" validate:"required,timezone"`
+	DurationMinutes int         `db:"duration_minutes" validate:"min=1,max=720"`
+	MeetingURL      string      `db:"meeting_url" validate:"required,url,max=2048"`
+	RecurrenceRule
+	CreatedAt time.Time   `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt null.Time   `db:"updated_at"`
+	UpdatedBy nuuid.NUUID `db:"updated_by"`
+	DeletedAt null.Time   `db:"deleted_at"`
+	DeletedBy nuuid.NUUID `db:"deleted_by"`
+}
+
+// Duration returns how long each occurrence of a LiveSession lasts.
+func (l *LiveSession) Duration() time.Duration {
+	return time.Duration(l.DurationMinutes) * time.Minute
+}
+
+// IsDeleted checks whether a LiveSession is marked as deleted.
+func (l *LiveSession) IsDeleted() bool {
+	return l.DeletedAt.Valid && l.DeletedBy.Valid
+}
+
+// IsVisibleTo checks whether the students of a Cohort, or students outside of
+// any Cohort when cohortID is not valid, attend a LiveSession.
+func (l *LiveSession) IsVisibleTo(cohortID nuuid.NUUID) bool {
+	return !l.CohortID.Valid || (cohortID.Valid && l.CohortID.UUID == cohortID.UUID)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (l LiveSession) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.ToResponseFormat())
+}
+
+// NewLiveSessionFromRequestFormat creates a new LiveSession of a Course from
+// its request format.
+func (l LiveSession) NewLiveSessionFromRequestFormat(courseID uuid.UUID, req LiveSessionRequestFormat, userID uuid.UUID) (newSession LiveSession, err error) {
+	sessionID, _ := uuid.NewV4()
+	newSession = LiveSession{
+		ID:        sessionID,
+		CourseID:  courseID,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newSession.apply(req)
+	return
+}
+
+// Occurrences expands a LiveSession into the occurrences overlapping the
+// given window, in order. A zero from or to leaves the window open on that
+// side.
+func (l *LiveSession) Occurrences(from time.Time, to time.Time) (occurrences []LiveSessionOccurrence) {
+	for i, startsAt := range l.occurrenceTimes() {
+		endsAt := startsAt.Add(l.Duration())
+		if (!from.IsZero() && !endsAt.After(from)) || (!to.IsZero() && !startsAt.Before(to)) {
+			continue
+		}
+
+		occurrences = append(occurrences, LiveSessionOccurrence{
+			SessionID:   l.ID,
+			CohortID:    l.CohortID.Ptr(),
+			Sequence:    i + 1,
+			Title:       l.Title,
+			Description: l.Description,
+			MeetingURL:  l.MeetingURL,
+			StartsAt:    startsAt,
+			EndsAt:      endsAt,
+		})
+	}
+
+	return
+}
+
+// Occurrence resolves the occurrence of a LiveSession with the given
+// sequence number, counting from 1.
+func (l *LiveSession) Occurrence(sequence int) (occurrence LiveSessionOccurrence, ok bool) {
+	occurrences := l.Occurrences(time.Time{}, time.Time{})
+	if sequence < 1 || sequence > len(occurrences) {
+		return
+	}
+
+	return occurrences[sequence-1], true
+}
+
+// SoftDelete marks a LiveSession as deleted.
+func (l *LiveSession) SoftDelete(userID uuid.UUID) (err error) {
+	if l.IsDeleted() {
+		return failure.Conflict("softDelete", "liveSession", "already marked as deleted")
+	}
+
+	l.DeletedAt = null.TimeFrom(time.Now())
+	l.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// ToResponseFormat converts this LiveSession to its response format.
+func (l LiveSession) ToResponseFormat() LiveSessionResponseFormat {
+	resp := LiveSessionResponseFormat{
+		ID:              l.ID,
+		CourseID:        l.CourseID,
+		CohortID:        l.CohortID.Ptr(),
+		Title:           l.Title,
+		Description:     l.Description,
+		StartsAt:        l.StartsAt,
+		Timezone:        l.Timezone,
+		DurationMinutes: l.DurationMinutes,
+		MeetingURL:      l.MeetingURL,
+		CreatedAt:       l.CreatedAt,
+		CreatedBy:       l.CreatedBy,
+		UpdatedAt:       l.UpdatedAt,
+		UpdatedBy:       l.UpdatedBy.Ptr(),
+	}
+
+	if l.Frequency != RecurrenceFrequencyNone {
+		recurrence := l.RecurrenceRule.ToResponseFormat()
+		resp.Recurrence = &recurrence
+	}
+
+	return resp
+}
+
+// Update updates a LiveSession.
+func (l *LiveSession) Update(req LiveSessionRequestFormat, userID uuid.UUID) (err error) {
+	l.UpdatedAt = null.TimeFrom(time.Now())
+	l.UpdatedBy = nuuid.From(userID)
+
+	return l.apply(req)
+}
+
+// Validate validates the entity.
+func (l *LiveSession) Validate() (err error) {
+	validator := shared.GetValidator()
+	err = validator.Struct(l)
+	if err != nil {
+		return
+	}
+
+	return l.RecurrenceRule.validate(l.localStart())
+}
+
+// apply copies a request onto a LiveSession and validates the result.
+func (l *LiveSession) apply(req LiveSessionRequestFormat) (err error) {
+	l.RecurrenceRule, err = newRecurrenceRule(req.Recurrence)
+	if err != nil {
+		return
+	}
+
+	l.CohortID = req.CohortID
+	l.Title = req.Title
+	l.Description = req.Description
+	l.StartsAt = req.StartsAt.UTC().Truncate(time.Second)
+	l.Timezone = req.Timezone
+	l.DurationMinutes = req.DurationMinutes
+	l.MeetingURL = req.MeetingURL
+
+	return l.Validate()
+}
+
+// localStart returns the first occurrence of a LiveSession in its time zone.
+func (l *LiveSession) localStart() time.Time {
+	location, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return l.StartsAt.In(location)
+}
+
+// occurrenceTimes expands the recurrence of a LiveSession into the start of
+// each occurrence. Occurrences are built from calendar dates and the local
+// time of the first one, so they stay at the same wall-clock time when
+// daylight saving time starts or ends.
+func (l *LiveSession) occurrenceTimes() (times []time.Time) {
+	first := l.localStart()
+	year, month, day := first.Date()
+	hour, minute, second := first.Clock()
+	at := func(days int) time.Time {
+		return time.Date(year, month, day+days, hour, minute, second, 0, first.Location())
+	}
+
+	// add returns false once the recurrence is over
+	add := func(days int) bool {
+		if l.Until.Valid && civilDate(at(days)).After(civilDate(l.Until.Time)) {
+			return false
+		}
+
+		times = append(times, at(days))
+		return len(times) < maxLiveSessionOccurrences &&
+			(!l.Count.Valid || int64(len(times)) < l.Count.Int64)
+	}
+
+	interval := l.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	weekdays := l.weekdays()
+	switch {
+	case l.Frequency == RecurrenceFrequencyDaily:
+		for days := 0; ; days += interval {
+			if !add(days) {
+				return
+			}
+		}
+	case l.Frequency == RecurrenceFrequencyWeekly && len(weekdays) > 0:
+		weekStart := -mondayOffset(first.Weekday())
+		for week := 0; ; week += interval {
+			for _, weekday := range weekdays {
+				days := weekStart + 7*week + mondayOffset(weekday)
+				if days < 0 {
+					continue
+				}
+
+				if !add(days) {
+					return
+				}
+			}
+		}
+	default:
+		times = append(times, first)
+	}
+
+	return
+}
+
+// civilDate strips a time down to its calendar date.
+func civilDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// mondayOffset returns how many days after Monday a weekday is, weeks
+// starting on Monday as they do by default in iCalendar.
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// LiveSessionRequestFormat represents a LiveSession's standard formatting for JSON deserializing.
+type LiveSessionRequestFormat struct {
+	CohortID        nuuid.NUUID                  `json:"cohortID" swaggertype:"string"`
+	Title           string                       `json:"title" validate:"required,max=200"`
+	Description     string                       `json:"description" validate:"max=2000"`
+	StartsAt        time.Time                    `json:"startsAt" validate:"required"`
+	Timezone        string                       `json:"timezone" validate:"required,timezone" example:"Asia/Jakarta"`
+	DurationMinutes int                          `json:"durationMinutes" validate:"required,min=1,max=720"`
+	MeetingURL      string                       `json:"meetingURL" validate:"required,url,max=2048"`
+	Recurrence      *RecurrenceRuleRequestFormat `json:"recurrence"`
+}
+
+// LiveSessionResponseFormat represents a LiveSession's standard formatting for JSON serializing.
+type LiveSessionResponseFormat struct {
+	ID              uuid.UUID                     `json:"id"`
+	CourseID        uuid.UUID                     `json:"courseID"`
+	CohortID        *uuid.UUID                    `json:"cohortID"`
+	Title           string                        `json:"title"`
+	Description     string                        `json:"description"`
+	StartsAt        time.Time                     `json:"startsAt"`
+	Timezone        string                        `json:"timezone"`
+	DurationMinutes int                           `json:"durationMinutes"`
+	MeetingURL      string                        `json:"meetingURL"`
+	Recurrence      *RecurrenceRuleResponseFormat `json:"recurrence"`
+	CreatedAt       time.Time                     `json:"createdAt"`
+	CreatedBy       uuid.UUID                     `json:"createdBy"`
+	UpdatedAt       null.Time                     `json:"updatedAt"`
+	UpdatedBy       *uuid.UUID                    `json:"updatedBy"`
+}
+
+// LiveSessionOccurrence is a single occurrence of a LiveSession. Sequence
+// counts occurrences from 1, the first one.
+type LiveSessionOccurrence struct {
+	SessionID   uuid.UUID  `json:"sessionID"`
+	CohortID    *uuid.UUID `json:"cohortID"`
+	Sequence    int        `json:"sequence"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	MeetingURL  string     `json:"meetingURL"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      time.Time  `json:"endsAt"`
+}
+
+// SortLiveSessionOccurrences sorts occurrences by start time.
+func SortLiveSessionOccurrences(occurrences []LiveSessionOccurrence) {
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+	})
+}
+
+//// Recurrence Rule
+
+// RecurrenceFrequency is how often a LiveSession recurs.
+type RecurrenceFrequency string
+
+const (
+	// RecurrenceFrequencyNone is the frequency of a LiveSession held once.
+	RecurrenceFrequencyNone RecurrenceFrequency = "none"
+	// RecurrenceFrequencyDaily is the frequency of a LiveSession held every Interval days.
+	RecurrenceFrequencyDaily RecurrenceFrequency = "daily"
+	// RecurrenceFrequencyWeekly is the frequency of a LiveSession held on ByDay every Interval weeks.
+	RecurrenceFrequencyWeekly RecurrenceFrequency = "weekly"
+)
+
+// recurrenceWeekdays maps iCalendar weekday codes to weekdays, in the order
+// of a week starting on Monday.
+var recurrenceWeekdays = []struct {
+	Code    string
+	Weekday time.Weekday
+}{
+	{"MO", time.Monday},
+	{"TU", time.Tuesday},
+	{"WE", time.Wednesday},
+	{"TH", time.Thursday},
+	{"FR", time.Friday},
+	{"SA", time.Saturday},
+	{"SU", time.Sunday},
+}
+
+// RecurrenceRule tells how a LiveSession recurs, a subset of the iCalendar
+// RRULE. A recurring LiveSession ends after Count occurrences or on the Until
+// date, never both. ByDay holds comma-separated iCalendar weekday codes.
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `db:"recurrence_frequency" validate:"oneof=none daily weekly"`
+	Interval  int                 `db:"recurrence_interval" validate:"min=1,max=52"`
+	ByDay     string              `db:"recurrence_by_day"`
+	Count     null.Int            `db:"recurrence_count"`
+	Until     null.Time           `db:"recurrence_until"`
+}
+
+// RRule formats a RecurrenceRule as an iCalendar RRULE value.
+func (r RecurrenceRule) RRule() string {
+	if r.Frequency == RecurrenceFrequencyNone {
+		return ""
+	}
+
+	parts := []string{"FREQ=" + strings.ToUpper(string(r.Frequency))}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+
+	if r.ByDay != "" {
+		parts = append(parts, "BYDAY="+r.ByDay)
+	}
+
+	if r.Count.Valid {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count.Int64))
+	}
+
+	if r.Until.Valid {
+		parts = append(parts, "UNTIL="+r.Until.Time.Format("20060102"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// ToResponseFormat converts this RecurrenceRule to its response format.
+func (r RecurrenceRule) ToResponseFormat() RecurrenceRuleResponseFormat {
+	resp := RecurrenceRuleResponseFormat{
+		Frequency: r.Frequency,
+		Interval:  r.Interval,
+		ByDay:     make([]string, 0),
+		Count:     r.Count,
+		RRule:     r.RRule(),
+	}
+
+	if r.ByDay != "" {
+		resp.ByDay = strings.Split(r.ByDay, ",")
+	}
+
+	if r.Until.Valid {
+		resp.Until = r.Until.Time.Format(calendarDateFormat)
+	}
+
+	return resp
+}
+
+// shift moves a RecurrenceRule along with its LiveSession by a number of
+// days, so that it recurs on the same weekdays relative to the first session.
+func (r RecurrenceRule) shift(days int) RecurrenceRule {
+	if r.Until.Valid {
+		r.Until = null.TimeFrom(r.Until.Time.AddDate(0, 0, days))
+	}
+
+	if r.ByDay == "" {
+		return r
+	}
+
+	shifted := make(map[time.Weekday]bool)
+	for _, weekday := range r.weekdays() {
+		shifted[time.Weekday(((int(weekday)+days)%7+7)%7)] = true
+	}
+
+	codes := make([]string, 0, len(shifted))
+	for _, day := range recurrenceWeekdays {
+		if shifted[day.Weekday] {
+			codes = append(codes, day.Code)
+		}
+	}
+
+	r.ByDay = strings.Join(codes, ",")
+	return r
+}
+
+// validate checks a RecurrenceRule against the first occurrence of its
+// LiveSession, in the LiveSession's time zone.
+func (r RecurrenceRule) validate(first time.Time) (err error) {
+	if r.Frequency == RecurrenceFrequencyNone {
+		return
+	}
+
+	if r.Count.Valid == r.Until.Valid {
+		return errors.New("a recurring session must end either after a count of occurrences or on a date")
+	}
+
+	if r.Until.Valid {
+		until := civilDate(r.Until.Time)
+		if until.Before(civilDate(first)) {
+			return errors.New("recurrence cannot end before the session starts")
+		}
+
+		if until.After(civilDate(first).AddDate(0, 0, maxRecurrenceDays)) {
+			return fmt.Errorf("recurrence cannot last more than %d days", maxRecurrenceDays)
+		}
+	}
+
+	if r.Frequency == RecurrenceFrequencyWeekly && !r.hasWeekday(first.Weekday()) {
+		return errors.New("byDay must include the weekday of the first session")
+	}
+
+	return
+}
+
+// hasWeekday checks whether a RecurrenceRule recurs on the given weekday.
+func (r RecurrenceRule) hasWeekday(weekday time.Weekday) bool {
+	for _, w := range r.weekdays() {
+		if w == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// weekdays returns the weekdays of ByDay, starting on Monday.
+func (r RecurrenceRule) weekdays() (weekdays []time.Weekday) {
+	codes := strings.Split(r.ByDay, ",")
+	for _, day := range recurrenceWeekdays {
+		for _, code := range codes {
+			if code == day.Code {
+				weekdays = append(weekdays, day.Weekday)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// newRecurrenceRule builds a RecurrenceRule from its request format. A
+// missing request stands for a LiveSession held once.
+func newRecurrenceRule(req *RecurrenceRuleRequestFormat) (rule RecurrenceRule, err error) {
+	rule = RecurrenceRule{Frequency: RecurrenceFrequencyNone, Interval: 1}
+	if req == nil {
+		return
+	}
+
+	err = shared.GetValidator().Struct(req)
+	if err != nil {
+		return
+	}
+
+	rule.Frequency = req.Frequency
+	if req.Interval > 0 {
+		rule.Interval = req.Interval
+	}
+
+	if req.Count > 0 {
+		rule.Count = null.IntFrom(int64(req.Count))
+	}
+
+	if req.Until != "" {
+		until, err := parseCalendarDate(req.Until)
+		if err != nil {
+			return rule, err
+		}
+
+		rule.Until = null.TimeFrom(until)
+	}
+
+	if rule.Frequency != RecurrenceFrequencyWeekly {
+		if len(req.ByDay) > 0 {
+			return rule, errors.New("byDay is only supported for weekly recurrence")
+		}
+
+		return
+	}
+
+	// store the weekdays once each, in the order of the week
+	var codes []string
+	for _, day := range recurrenceWeekdays {
+		for _, code := range req.ByDay {
+			if code == day.Code {
+				codes = append(codes, day.Code)
+				break
+			}
+		}
+	}
+	rule.ByDay = strings.Join(codes, ",")
+
+	return
+}
+
+// RecurrenceRuleRequestFormat represents a RecurrenceRule's standard
+// formatting for JSON deserializing. Interval defaults to 1. Give either a
+// count of occurrences or an until date, inclusive.
+type RecurrenceRuleRequestFormat struct {
+	Frequency RecurrenceFrequency `json:"frequency" validate:"required,oneof=daily weekly" example:"weekly"`
+	Interval  int                 `json:"interval" validate:"min=0,max=52" example:"1"`
+	ByDay     []string            `json:"byDay" validate:"max=7,dive,oneof=MO TU WE TH FR SA SU" example:"MO,WE"`
+	Count     int                 `json:"count" validate:"min=0,max=500" example:"12"`
+	Until     string              `json:"until" validate:"omitempty,datetime=2006-01-02" example:"2026-06-30"`
+}
+
+// RecurrenceRuleResponseFormat represents a RecurrenceRule's standard
+// formatting for JSON serializing. RRule is the iCalendar equivalent.
+type RecurrenceRuleResponseFormat struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+	Interval  int                 `json:"interval"`
+	ByDay     []string            `json:"byDay"`
+	Count     null.Int            `json:"count"`
+	Until     string              `json:"until,omitempty"`
+	RRule     string              `json:"rrule"`
+}
+
+//// Calendar Feed
+
+// NewCalendarFeedToken issues the token a user subscribes to the calendar of
+// a Course with. Calendar apps can't send an Authorization header, so the
+// token carries the user and role, signed with the feed key.
+func NewCalendarFeedToken(courseID uuid.UUID, userID uuid.UUID, role string, feedKey string) (token string, err error) {
+	if feedKey == "" {
+		return token, errors.New("calendar feed key is not configured")
+	}
+
+	subject := userID.String() + "|" + role
+	return base64.RawURLEncoding.EncodeToString([]byte(subject)) + "." + signCalendarFeed(courseID, subject, feedKey), nil
+}
+
+// ParseCalendarFeedToken checks a calendar feed token against a Course and
+// resolves the user and role it was issued to.
+func ParseCalendarFeedToken(courseID uuid.UUID, token string, feedKey string) (userID uuid.UUID, role string, err error) {
+	if feedKey == "" {
+		return userID, role, errInvalidFeedToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return userID, role, errInvalidFeedToken
+	}
+
+	subject, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return userID, role, errInvalidFeedToken
+	}
+
+	if !hmac.Equal([]byte(parts[1]), []byte(signCalendarFeed(courseID, string(subject), feedKey))) {
+		return userID, role, errInvalidFeedToken
+	}
+
+	fields := strings.Split(string(subject), "|")
+	if len(fields) != 2 {
+		return userID, role, errInvalidFeedToken
+	}
+
+	userID, err = uuid.FromString(fields[0])
+	if err != nil {
+		return userID, role, errInvalidFeedToken
+	}
+
+	return userID, fields[1], nil
+}
+
+// signCalendarFeed signs the subject of a calendar feed token for a Course.
+func signCalendarFeed(courseID uuid.UUID, subject string, feedKey string) string {
+	mac := hmac.New(sha256.New, []byte(feedKey))
+	fmt.Fprintf(mac, "%s|%s", courseID, subject)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CalendarFeedFormat is the subscription link to the calendar of a Course.
+type CalendarFeedFormat struct {
+	CourseID uuid.UUID `json:"courseID"`
+	URL      string    `json:"url"`
+}
@@ -0,0 +1,177 @@
+package course_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFeedKey = "test-calendar-feed-key"
+
+func newLiveSessionRequest(recurrence *course.RecurrenceRuleRequestFormat) course.LiveSessionRequestFormat {
+	return course.LiveSessionRequestFormat{
+		Title:           "Office hours",
+		Description:     "Bring your questions; we'll go through them, one by one.",
+		StartsAt:        time.Date(2026, 3, 3, 23, 0, 0, 0, time.UTC), // Tuesday 18:00 in New York
+		Timezone:        "America/New_York",
+		DurationMinutes: 90,
+		MeetingURL:      "https://meet.example.com/office-hours",
+		Recurrence:      recurrence,
+	}
+}
+
+func TestLiveSession(t *testing.T) {
+	courseID, userID := getRandomUUID(), getRandomUUID()
+
+	t.Run("validate", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			recurrence *course.RecurrenceRuleRequestFormat
+			wantErr    bool
+		}{
+			{name: "held once"},
+			{name: "weekly", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TU", "TH"}, Count: 8}},
+			{name: "daily until a date", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily", Until: "2026-03-20"}},
+			{name: "endless", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily"}, wantErr: true},
+			{name: "count and until", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily", Count: 3, Until: "2026-03-20"}, wantErr: true},
+			{name: "until before the start", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily", Until: "2026-03-02"}, wantErr: true},
+			{name: "until too far", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily", Until: "2027-06-01"}, wantErr: true},
+			{name: "weekly without the first weekday", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"WE"}, Count: 4}, wantErr: true},
+			{name: "unknown weekday", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TU", "XX"}, Count: 4}, wantErr: true},
+			{name: "daily on weekdays", recurrence: &course.RecurrenceRuleRequestFormat{Frequency: "daily", ByDay: []string{"TU"}, Count: 4}, wantErr: true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(courseID, newLiveSessionRequest(test.recurrence), userID)
+
+				if test.wantErr {
+					assert.Error(t, err)
+					return
+				}
+
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("weekly occurrences keep their local time across daylight saving time", func(t *testing.T) {
+		session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(courseID, newLiveSessionRequest(
+			&course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TH", "TU", "TU"}, Count: 4},
+		), userID)
+		assert.NoError(t, err)
+
+		occurrences := session.Occurrences(time.Time{}, time.Time{})
+
+		// New York moves from EST (UTC-5) to EDT (UTC-4) on 2026-03-08
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4", session.RRule())
+		assert.Len(t, occurrences, 4)
+		assert.Equal(t, time.Date(2026, 3, 3, 23, 0, 0, 0, time.UTC), occurrences[0].StartsAt.UTC())
+		assert.Equal(t, time.Date(2026, 3, 5, 23, 0, 0, 0, time.UTC), occurrences[1].StartsAt.UTC())
+		assert.Equal(t, time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), occurrences[2].StartsAt.UTC())
+		assert.Equal(t, time.Date(2026, 3, 12, 22, 0, 0, 0, time.UTC), occurrences[3].StartsAt.UTC())
+		assert.Equal(t, time.Date(2026, 3, 12, 23, 30, 0, 0, time.UTC), occurrences[3].EndsAt.UTC())
+		assert.Equal(t, 4, occurrences[3].Sequence)
+	})
+
+	t.Run("occurrences until a date, within a window", func(t *testing.T) {
+		session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(courseID, newLiveSessionRequest(
+			&course.RecurrenceRuleRequestFormat{Frequency: "daily", Interval: 2, Until: "2026-03-11"},
+		), userID)
+		assert.NoError(t, err)
+
+		all := session.Occurrences(time.Time{}, time.Time{})
+		windowed := session.Occurrences(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+
+		// 3rd, 5th, 7th, 9th and 11th of March; the one on the 5th runs past midnight UTC
+		assert.Len(t, all, 5)
+		assert.Equal(t, time.Date(2026, 3, 11, 22, 0, 0, 0, time.UTC), all[4].StartsAt.UTC())
+		assert.Len(t, windowed, 2)
+		assert.Equal(t, 2, windowed[0].Sequence)
+		assert.Equal(t, 3, windowed[1].Sequence)
+	})
+
+	t.Run("visible to", func(t *testing.T) {
+		cohortID := getRandomUUID()
+		everyone := course.LiveSession{}
+		cohortOnly := course.LiveSession{CohortID: nuuid.From(cohortID)}
+
+		assert.True(t, everyone.IsVisibleTo(nuuid.NUUID{}))
+		assert.True(t, cohortOnly.IsVisibleTo(nuuid.From(cohortID)))
+		assert.False(t, cohortOnly.IsVisibleTo(nuuid.From(getRandomUUID())))
+		assert.False(t, cohortOnly.IsVisibleTo(nuuid.NUUID{}))
+	})
+}
+
+func TestRenderICalendar(t *testing.T) {
+	session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(getRandomUUID(), newLiveSessionRequest(
+		&course.RecurrenceRuleRequestFormat{Frequency: "weekly", ByDay: []string{"TU"}, Count: 2},
+	), getRandomUUID())
+	assert.NoError(t, err)
+	session.Title = "Office hours, week by week; " + strings.Repeat("é", 60)
+
+	calendar := string(course.RenderICalendar("Data Bootcamp", []course.LiveSession{session}, "courses.example.com", time.Now()))
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+
+	// unfold long lines, as calendar apps do
+	calendar = strings.ReplaceAll(calendar, "\r\n ", "")
+
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(calendar, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, calendar, "UID:"+session.ID.String()+"-2@courses.example.com\r\n")
+	assert.Contains(t, calendar, "DTSTART:20260303T230000Z\r\n")
+	assert.Contains(t, calendar, "DTSTART:20260310T220000Z\r\n")
+	assert.Contains(t, calendar, "DTEND:20260310T233000Z\r\n")
+	assert.Contains(t, calendar, "SUMMARY:Office hours\\, week by week\\; "+strings.Repeat("é", 60)+"\r\n")
+	assert.Contains(t, calendar, `DESCRIPTION:Bring your questions\; we'll go through them\, one by one.\n\nJoin: https://meet.example.com/office-hours`)
+}
+
+func TestCalendarFeedToken(t *testing.T) {
+	courseID, userID := getRandomUUID(), getRandomUUID()
+	token, err := course.NewCalendarFeedToken(courseID, userID, shared.RoleStudent, testFeedKey)
+	assert.NoError(t, err)
+
+	gotUserID, gotRole, err := course.ParseCalendarFeedToken(courseID, token, testFeedKey)
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, gotUserID)
+	assert.Equal(t, shared.RoleStudent, gotRole)
+
+	t.Run("rejected", func(t *testing.T) {
+		teacherToken, _ := course.NewCalendarFeedToken(courseID, userID, shared.RoleTeacher, testFeedKey)
+		forged := strings.SplitN(teacherToken, ".", 2)[0] + "." + strings.SplitN(token, ".", 2)[1]
+
+		tests := map[string]struct {
+			courseID uuid.UUID
+			token    string
+			key      string
+		}{
+			"another course": {courseID: getRandomUUID(), token: token, key: testFeedKey},
+			"another key":    {courseID: courseID, token: token, key: "another-key"},
+			"forged role":    {courseID: courseID, token: forged, key: testFeedKey},
+			"malformed":      {courseID: courseID, token: "not-a-token", key: testFeedKey},
+			"no key":         {courseID: courseID, token: token, key: ""},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, _, err := course.ParseCalendarFeedToken(test.courseID, test.token, test.key)
+
+				assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+			})
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source live_session_repository.go -destination mock/live_session_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	liveSessionQueries = struct {
+		selectLiveSession string
+		insertLiveSession string
+		updateLiveSession string
+	}{
+		selectLiveSession: `
+			SELECT
+				id,
+				course_id,
+				cohort_id,
+				title,
+				description,
+				starts_at,
+				timezone,
+				duration_minutes,
+				meeting_url,
+				recurrence_frequency,
+				recurrence_interval,
+				recurrence_by_day,
+				recurrence_count,
+				recurrence_until,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM live_sessions
+		`,
+
+		insertLiveSession: `
+			INSERT INTO live_sessions (
+				id,
+				course_id,
+				cohort_id,
+				title,
+				description,
+				starts_at,
+				timezone,
+				duration_minutes,
+				meeting_url,
+				recurrence_frequency,
+				recurrence_interval,
+				recurrence_by_day,
+				recurrence_count,
+				recurrence_until,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:cohort_id,
+				:title,
+				:description,
+				:starts_at,
+				:timezone,
+				:duration_minutes,
+				:meeting_url,
+				:recurrence_frequency,
+				:recurrence_interval,
+				:recurrence_by_day,
+				:recurrence_count,
+				:recurrence_until,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		updateLiveSession: `
+			UPDATE live_sessions
+			SET
+				cohort_id = :cohort_id,
+				title = :title,
+				description = :description,
+				starts_at = :starts_at,
+				timezone = :timezone,
+				duration_minutes = :duration_minutes,
+				meeting_url = :meeting_url,
+				recurrence_frequency = :recurrence_frequency,
+				recurrence_interval = :recurrence_interval,
+				recurrence_by_day = :recurrence_by_day,
+				recurrence_count = :recurrence_count,
+				recurrence_until = :recurrence_until,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+	}
+)
+
+// LiveSessionRepository is the repository for LiveSession data.
+type LiveSessionRepository interface {
+	CreateLiveSession(session LiveSession) (err error)
+	ResolveLiveSessionByID(id uuid.UUID) (session LiveSession, err error)
+	ResolveLiveSessionsByCourseID(courseID uuid.UUID) (sessions []LiveSession, err error)
+	UpdateLiveSession(session LiveSession) (err error)
+}
+
+// LiveSessionRepositoryMySQL is the MySQL-backed implementation of LiveSessionRepository.
+type LiveSessionRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideLiveSessionRepositoryMySQL is the provider for this repository.
+func ProvideLiveSessionRepositoryMySQL(db *infras.MySQLConn) *LiveSessionRepositoryMySQL {
+	s := new(LiveSessionRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateLiveSession creates a new LiveSession.
+func (r *LiveSessionRepositoryMySQL) CreateLiveSession(session LiveSession) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, liveSessionQueries.insertLiveSession, session); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveLiveSessionByID resolves a LiveSession by its ID.
+func (r *LiveSessionRepositoryMySQL) ResolveLiveSessionByID(id uuid.UUID) (session LiveSession, err error) {
+	err = r.DB.Read.Get(&session, liveSessionQueries.selectLiveSession+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("liveSession")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveLiveSessionsByCourseID resolves the LiveSessions of a Course that
+// are not deleted, in the order they first take place.
+func (r *LiveSessionRepositoryMySQL) ResolveLiveSessionsByCourseID(courseID uuid.UUID) (sessions []LiveSession, err error) {
+	err = r.DB.Read.Select(
+		&sessions,
+		liveSessionQueries.selectLiveSession+" WHERE course_id = ? AND deleted_at IS NULL ORDER BY starts_at, title",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateLiveSession updates a LiveSession.
+func (r *LiveSessionRepositoryMySQL) UpdateLiveSession(session LiveSession) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, liveSessionQueries.updateLiveSession, session); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *LiveSessionRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
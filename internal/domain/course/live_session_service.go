@@ -0,0 +1,295 @@
+package course
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// LiveSessionService is the service interface for LiveSessions and the
+// calendars they make up.
+type LiveSessionService interface {
+	CreateLiveSession(courseID uuid.UUID, requestFormat LiveSessionRequestFormat, userID uuid.UUID) (session LiveSession, err error)
+	DeleteLiveSession(id uuid.UUID, userID uuid.UUID) (session LiveSession, err error)
+	RenderCalendar(courseID uuid.UUID, token string) (calendar []byte, err error)
+	ResolveCalendarFeed(courseID uuid.UUID, userID uuid.UUID, role string) (feed CalendarFeedFormat, err error)
+	ResolveLiveSessionByID(id uuid.UUID, userID uuid.UUID, role string) (session LiveSession, err error)
+	ResolveLiveSessionsByCourseID(courseID uuid.UUID, userID uuid.UUID, role string) (sessions []LiveSession, err error)
+	ResolveOccurrences(courseID uuid.UUID, from null.Time, to null.Time, userID uuid.UUID, role string) (occurrences []LiveSessionOccurrence, err error)
+	UpdateLiveSession(id uuid.UUID, requestFormat LiveSessionRequestFormat, userID uuid.UUID) (session LiveSession, err error)
+}
+
+// LiveSessionServiceImpl is the service implementation for LiveSessions and
+// the calendars they make up.
+type LiveSessionServiceImpl struct {
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	Config                 *configs.Config
+}
+
+// ProvideLiveSessionServiceImpl is the provider for this service.
+func ProvideLiveSessionServiceImpl(
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	config *configs.Config) *LiveSessionServiceImpl {
+	s := new(LiveSessionServiceImpl)
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.Config = config
+
+	return s
+}
+
+// CreateLiveSession schedules a new LiveSession of a Course whose schedule the
+// given user manages.
+func (s *LiveSessionServiceImpl) CreateLiveSession(courseID uuid.UUID, requestFormat LiveSessionRequestFormat, userID uuid.UUID) (session LiveSession, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionManageSchedule)
+	if err != nil {
+		return
+	}
+
+	session, err = LiveSession{}.NewLiveSessionFromRequestFormat(course.ID, requestFormat, userID)
+	if err != nil {
+		return session, failure.BadRequest(err)
+	}
+
+	err = s.checkCohort(session)
+	if err != nil {
+		return
+	}
+
+	err = s.LiveSessionRepository.CreateLiveSession(session)
+	return
+}
+
+// DeleteLiveSession marks a LiveSession as deleted, removing every occurrence from the calendar.
+func (s *LiveSessionServiceImpl) DeleteLiveSession(id uuid.UUID, userID uuid.UUID) (session LiveSession, err error) {
+	session, err = s.resolveManagedLiveSession(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = session.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.LiveSessionRepository.UpdateLiveSession(session)
+	return
+}
+
+// RenderCalendar renders the iCalendar feed of a Course for the user a feed
+// token was issued to. The user's access is checked again on every render,
+// so students who leave the Course stop receiving it.
+func (s *LiveSessionServiceImpl) RenderCalendar(courseID uuid.UUID, token string) (calendar []byte, err error) {
+	userID, role, err := ParseCalendarFeedToken(courseID, token, s.Config.Calendar.FeedKey)
+	if err != nil {
+		return
+	}
+
+	course, err := s.CourseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() {
+		return calendar, failure.NotFound("course")
+	}
+
+	sessions, err := s.resolveAttendedLiveSessions(course.ID, userID, role)
+	if err != nil {
+		return
+	}
+
+	return RenderICalendar(course.Title, sessions, s.uidDomain(), time.Now()), nil
+}
+
+// ResolveCalendarFeed resolves the link a user subscribes to the calendar of
+// a Course with.
+func (s *LiveSessionServiceImpl) ResolveCalendarFeed(courseID uuid.UUID, userID uuid.UUID, role string) (feed CalendarFeedFormat, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, courseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	token, err := NewCalendarFeedToken(courseID, userID, role, s.Config.Calendar.FeedKey)
+	if err != nil {
+		return feed, failure.InternalError(err)
+	}
+
+	return CalendarFeedFormat{
+		CourseID: courseID,
+		URL:      s.Config.App.URL + "/v1/courses/" + courseID.String() + "/calendar.ics?token=" + url.QueryEscape(token),
+	}, nil
+}
+
+// ResolveLiveSessionByID resolves a LiveSession for a teacher or an actively
+// enrolled student attending it.
+func (s *LiveSessionServiceImpl) ResolveLiveSessionByID(id uuid.UUID, userID uuid.UUID, role string) (session LiveSession, err error) {
+	session, err = resolveLiveSession(s.LiveSessionRepository, id)
+	if err != nil {
+		return
+	}
+
+	sessions, err := s.filterAttended([]LiveSession{session}, session.CourseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	if len(sessions) == 0 {
+		return session, failure.NotFound("liveSession")
+	}
+
+	return
+}
+
+// ResolveLiveSessionsByCourseID resolves the LiveSessions of a Course a user
+// attends. Teachers see every LiveSession, students those held for the whole
+// Course or for their Cohort.
+func (s *LiveSessionServiceImpl) ResolveLiveSessionsByCourseID(courseID uuid.UUID, userID uuid.UUID, role string) (sessions []LiveSession, err error) {
+	return s.resolveAttendedLiveSessions(courseID, userID, role)
+}
+
+// ResolveOccurrences expands the LiveSessions of a Course a user attends into
+// their occurrences within a window, in the order they take place.
+func (s *LiveSessionServiceImpl) ResolveOccurrences(courseID uuid.UUID, from null.Time, to null.Time, userID uuid.UUID, role string) (occurrences []LiveSessionOccurrence, err error) {
+	if from.Valid && to.Valid && !to.Time.After(from.Time) {
+		return occurrences, failure.BadRequestFromString("to must be after from")
+	}
+
+	sessions, err := s.resolveAttendedLiveSessions(courseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	occurrences = make([]LiveSessionOccurrence, 0)
+	for _, session := range sessions {
+		occurrences = append(occurrences, session.Occurrences(from.Time, to.Time)...)
+	}
+	SortLiveSessionOccurrences(occurrences)
+
+	return
+}
+
+// UpdateLiveSession updates a LiveSession.
+func (s *LiveSessionServiceImpl) UpdateLiveSession(id uuid.UUID, requestFormat LiveSessionRequestFormat, userID uuid.UUID) (session LiveSession, err error) {
+	session, err = s.resolveManagedLiveSession(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = session.Update(requestFormat, userID)
+	if err != nil {
+		return session, failure.BadRequest(err)
+	}
+
+	err = s.checkCohort(session)
+	if err != nil {
+		return
+	}
+
+	err = s.LiveSessionRepository.UpdateLiveSession(session)
+	return
+}
+
+// checkCohort makes sure a LiveSession held for a Cohort is held for one of
+// its Course's Cohorts.
+func (s *LiveSessionServiceImpl) checkCohort(session LiveSession) (err error) {
+	if !session.CohortID.Valid {
+		return
+	}
+
+	cohort, err := resolveCohort(s.CohortRepository, session.CohortID.UUID)
+	if err != nil {
+		return
+	}
+
+	if cohort.CourseID != session.CourseID {
+		return failure.NotFound("cohort")
+	}
+
+	return
+}
+
+// filterAttended keeps the LiveSessions of a Course a user attends.
+func (s *LiveSessionServiceImpl) filterAttended(sessions []LiveSession, courseID uuid.UUID, userID uuid.UUID, role string) (attended []LiveSession, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, courseID, userID, role)
+	if err != nil || role == shared.RoleTeacher {
+		return sessions, err
+	}
+
+	enrollment, err := s.EnrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, userID)
+	if err != nil {
+		return
+	}
+
+	attended = make([]LiveSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.IsVisibleTo(enrollment.CohortID) {
+			attended = append(attended, session)
+		}
+	}
+
+	return
+}
+
+// resolveAttendedLiveSessions resolves the LiveSessions of a Course a user
+// attends.
+func (s *LiveSessionServiceImpl) resolveAttendedLiveSessions(courseID uuid.UUID, userID uuid.UUID, role string) (sessions []LiveSession, err error) {
+	sessions, err = s.LiveSessionRepository.ResolveLiveSessionsByCourseID(courseID)
+	if err != nil {
+		return
+	}
+
+	return s.filterAttended(sessions, courseID, userID, role)
+}
+
+// resolveManagedLiveSession resolves a LiveSession of a Course whose schedule
+// the given user manages.
+func (s *LiveSessionServiceImpl) resolveManagedLiveSession(id uuid.UUID, userID uuid.UUID) (session LiveSession, err error) {
+	session, err = resolveLiveSession(s.LiveSessionRepository, id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, session.CourseID, userID, PermissionManageSchedule)
+	return
+}
+
+// uidDomain returns the domain event UIDs are scoped with in calendars.
+func (s *LiveSessionServiceImpl) uidDomain() string {
+	appURL, err := url.Parse(s.Config.App.URL)
+	if err != nil || appURL.Hostname() == "" {
+		return "localhost"
+	}
+
+	return appURL.Hostname()
+}
+
+// resolveLiveSession resolves a LiveSession that is not deleted.
+func resolveLiveSession(liveSessionRepository LiveSessionRepository, id uuid.UUID) (session LiveSession, err error) {
+	session, err = liveSessionRepository.ResolveLiveSessionByID(id)
+	if err != nil {
+		return
+	}
+
+	if session.IsDeleted() {
+		return session, failure.NotFound("liveSession")
+	}
+
+	return
+}
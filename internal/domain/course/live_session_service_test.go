@@ -0,0 +1,109 @@
+package course_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveSessionService(t *testing.T) {
+	config := &configs.Config{}
+	config.App.URL = "https://courses.example.com"
+	config.Calendar.FeedKey = testFeedKey
+
+	fullCourse := newDraftCourse()
+	studentID, cohortID, otherCohortID := getRandomUUID(), getRandomUUID(), getRandomUUID()
+	newSession := func(title string, cohortID nuuid.NUUID) course.LiveSession {
+		req := newLiveSessionRequest(nil)
+		req.Title = title
+		req.CohortID = cohortID
+		session, err := course.LiveSession{}.NewLiveSessionFromRequestFormat(fullCourse.ID, req, fullCourse.UserID)
+		assert.NoError(t, err)
+
+		return session
+	}
+	sessions := []course.LiveSession{
+		newSession("Kickoff", nuuid.NUUID{}),
+		newSession("Our cohort's lab", nuuid.From(cohortID)),
+		newSession("Another cohort's lab", nuuid.From(otherCohortID)),
+	}
+	enrollment := newEnrollment(fullCourse.ID, studentID, course.EnrollmentStatusActive)
+	enrollment.CohortID = nuuid.From(cohortID)
+
+	t.Run("students only see the sessions of their cohort", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+		s := course.ProvideLiveSessionServiceImpl(nil, nil, nil, mockEnrollmentRepo, mockLiveSessionRepo, config)
+		mockLiveSessionRepo.EXPECT().ResolveLiveSessionsByCourseID(fullCourse.ID).Return(sessions, nil)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil).Times(2)
+
+		got, err := s.ResolveLiveSessionsByCourseID(fullCourse.ID, studentID, shared.RoleStudent)
+
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "Kickoff", got[0].Title)
+		assert.Equal(t, "Our cohort's lab", got[1].Title)
+	})
+
+	t.Run("calendar feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockLiveSessionRepo := course_mock.NewMockLiveSessionRepository(ctrl)
+		s := course.ProvideLiveSessionServiceImpl(nil, nil, mockCourseRepo, mockEnrollmentRepo, mockLiveSessionRepo, config)
+		mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil)
+
+		feed, err := s.ResolveCalendarFeed(fullCourse.ID, studentID, shared.RoleStudent)
+		assert.NoError(t, err)
+		feedURL, err := url.Parse(feed.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/courses/"+fullCourse.ID.String()+"/calendar.ics", feedURL.Path)
+		token := feedURL.Query().Get("token")
+
+		t.Run("renders the sessions the student attends", func(t *testing.T) {
+			mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+			mockLiveSessionRepo.EXPECT().ResolveLiveSessionsByCourseID(fullCourse.ID).Return(sessions, nil)
+			mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(enrollment, nil).Times(2)
+
+			calendar, err := s.RenderCalendar(fullCourse.ID, token)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 2, strings.Count(string(calendar), "BEGIN:VEVENT"))
+			assert.Contains(t, string(calendar), "@courses.example.com")
+			assert.NotContains(t, string(calendar), "Another cohort")
+		})
+
+		t.Run("stops working once the student leaves", func(t *testing.T) {
+			withdrawn := enrollment
+			withdrawn.Status = course.EnrollmentStatusWithdrawn
+			mockCourseRepo.EXPECT().ResolveCourseByID(fullCourse.ID).Return(fullCourse, nil)
+			mockLiveSessionRepo.EXPECT().ResolveLiveSessionsByCourseID(fullCourse.ID).Return(sessions, nil)
+			mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(fullCourse.ID, studentID).Return(withdrawn, nil)
+
+			_, err := s.RenderCalendar(fullCourse.ID, token)
+
+			assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+		})
+
+		t.Run("rejects a token of another course", func(t *testing.T) {
+			_, err := s.RenderCalendar(getRandomUUID(), token)
+
+			assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		})
+	})
+}
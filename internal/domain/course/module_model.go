@@ -0,0 +1,310 @@
+package course
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/markdown"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+//// Module
+
+// Module is an ordered section of a Course that groups Lessons.
+type Module struct {
+	ID        uuid.UUID   `db:"id" validate:"required"`
+	CourseID  uuid.UUID   `db:"course_id" validate:"required"`
+	Title     string      `db:"title" validate:"required"`
+	Position  int         `db:"position" validate:"min=1"`
+	CreatedAt time.Time   `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt null.Time   `db:"updated_at"`
+	UpdatedBy nuuid.NUUID `db:"updated_by"`
+	DeletedAt null.Time   `db:"deleted_at"`
+	DeletedBy nuuid.NUUID `db:"deleted_by"`
+	Lessons   []Lesson    `db:"-"`
+}
+
+// AttachLessons attaches Lessons to this Module.
+func (m *Module) AttachLessons(lessons []Lesson) Module {
+	for _, lesson := range lessons {
+		if lesson.ModuleID == m.ID {
+			m.Lessons = append(m.Lessons, lesson)
+		}
+	}
+	return *m
+}
+
+// IsDeleted checks whether a Module is marked as deleted.
+func (m *Module) IsDeleted() (deleted bool) {
+	return m.DeletedAt.Valid && m.DeletedBy.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (m Module) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.ToResponseFormat())
+}
+
+// NewModuleFromRequestFormat creates a new Module placed at the given position.
+func (m Module) NewModuleFromRequestFormat(req ModuleRequestFormat, courseID uuid.UUID, position int, userID uuid.UUID) (newModule Module, err error) {
+	moduleID, _ := uuid.NewV4()
+	newModule = Module{
+		ID:        moduleID,
+		CourseID:  courseID,
+		Title:     req.Title,
+		Position:  position,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newModule.Validate()
+
+	return
+}
+
+// SoftDelete marks a Module as deleted.
+func (m *Module) SoftDelete(userID uuid.UUID) (err error) {
+	if m.IsDeleted() {
+		return failure.Conflict("softDelete", "module", "already marked as deleted")
+	}
+
+	m.DeletedAt = null.TimeFrom(time.Now())
+	m.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// ToResponseFormat converts this Module to its response format.
+func (m Module) ToResponseFormat() ModuleResponseFormat {
+	resp := ModuleResponseFormat{
+		ID:        m.ID,
+		CourseID:  m.CourseID,
+		Title:     m.Title,
+		Position:  m.Position,
+		CreatedAt: m.CreatedAt,
+		CreatedBy: m.CreatedBy,
+		UpdatedAt: m.UpdatedAt,
+		UpdatedBy: m.UpdatedBy.Ptr(),
+		Lessons:   make([]LessonResponseFormat, 0),
+	}
+
+	for _, lesson := range m.Lessons {
+		resp.Lessons = append(resp.Lessons, lesson.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// Update updates a Module.
+func (m *Module) Update(req ModuleRequestFormat, userID uuid.UUID) (err error) {
+	m.Title = req.Title
+	m.UpdatedAt = null.TimeFrom(time.Now())
+	m.UpdatedBy = nuuid.From(userID)
+
+	err = m.Validate()
+
+	return
+}
+
+// Validate validates the entity.
+func (m *Module) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(m)
+}
+
+// ModuleRequestFormat represents a Module's standard formatting for JSON deserializing.
+type ModuleRequestFormat struct {
+	Title string `json:"title" validate:"required"`
+}
+
+// ModuleResponseFormat represents a Module's standard formatting for JSON serializing.
+type ModuleResponseFormat struct {
+	ID        uuid.UUID              `json:"id"`
+	CourseID  uuid.UUID              `json:"courseID"`
+	Title     string                 `json:"title"`
+	Position  int                    `json:"position"`
+	CreatedAt time.Time              `json:"createdAt"`
+	CreatedBy uuid.UUID              `json:"createdBy"`
+	UpdatedAt null.Time              `json:"updatedAt"`
+	UpdatedBy *uuid.UUID             `json:"updatedBy"`
+	Lessons   []LessonResponseFormat `json:"lessons"`
+}
+
+//// Lesson
+
+// Lesson is a single ordered unit of content inside a Module.
+type Lesson struct {
+	ID        uuid.UUID   `db:"id" validate:"required"`
+	ModuleID  uuid.UUID   `db:"module_id" validate:"required"`
+	CourseID  uuid.UUID   `db:"course_id" validate:"required"`
+	Title     string      `db:"title" validate:"required"`
+	Content   string      `db:"content"`
+	Position  int         `db:"position" validate:"min=1"`
+	CreatedAt time.Time   `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt null.Time   `db:"updated_at"`
+	UpdatedBy nuuid.NUUID `db:"updated_by"`
+	DeletedAt null.Time   `db:"deleted_at"`
+	DeletedBy nuuid.NUUID `db:"deleted_by"`
+	// ReleasesAt is set when the Lesson is locked by a Cohort's drip schedule.
+	ReleasesAt null.Time `db:"-"`
+}
+
+// IsDeleted checks whether a Lesson is marked as deleted.
+func (l *Lesson) IsDeleted() (deleted bool) {
+	return l.DeletedAt.Valid && l.DeletedBy.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (l Lesson) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.ToResponseFormat())
+}
+
+// NewLessonFromRequestFormat creates a new Lesson placed at the given position.
+func (l Lesson) NewLessonFromRequestFormat(req LessonRequestFormat, module Module, position int, userID uuid.UUID) (newLesson Lesson, err error) {
+	lessonID, _ := uuid.NewV4()
+	newLesson = Lesson{
+		ID:        lessonID,
+		ModuleID:  module.ID,
+		CourseID:  module.CourseID,
+		Title:     req.Title,
+		Content:   req.Content,
+		Position:  position,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newLesson.Validate()
+
+	return
+}
+
+// IsLocked checks whether a Lesson is withheld until its release.
+func (l *Lesson) IsLocked() bool {
+	return l.ReleasesAt.Valid
+}
+
+// Lock withholds the content of a Lesson until it is released.
+func (l *Lesson) Lock(releasesAt time.Time) {
+	l.Content = ""
+	l.ReleasesAt = null.TimeFrom(releasesAt)
+}
+
+// SoftDelete marks a Lesson as deleted.
+func (l *Lesson) SoftDelete(userID uuid.UUID) (err error) {
+	if l.IsDeleted() {
+		return failure.Conflict("softDelete", "lesson", "already marked as deleted")
+	}
+
+	l.DeletedAt = null.TimeFrom(time.Now())
+	l.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// ToResponseFormat converts this Lesson to its response format, rendering
+// its Markdown content to HTML with a table of contents.
+func (l Lesson) ToResponseFormat() LessonResponseFormat {
+	content := markdown.Render(l.Content)
+	return LessonResponseFormat{
+		ID:              l.ID,
+		ModuleID:        l.ModuleID,
+		CourseID:        l.CourseID,
+		Title:           l.Title,
+		Content:         l.Content,
+		ContentHTML:     content.HTML,
+		TableOfContents: content.TableOfContents,
+		Position:        l.Position,
+		CreatedAt:       l.CreatedAt,
+		CreatedBy:       l.CreatedBy,
+		UpdatedAt:       l.UpdatedAt,
+		UpdatedBy:       l.UpdatedBy.Ptr(),
+		Locked:          l.IsLocked(),
+		ReleasesAt:      l.ReleasesAt.Ptr(),
+	}
+}
+
+// Update updates a Lesson.
+func (l *Lesson) Update(req LessonRequestFormat, userID uuid.UUID) (err error) {
+	l.Title = req.Title
+	l.Content = req.Content
+	l.UpdatedAt = null.TimeFrom(time.Now())
+	l.UpdatedBy = nuuid.From(userID)
+
+	err = l.Validate()
+
+	return
+}
+
+// Validate validates the entity.
+func (l *Lesson) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(l)
+}
+
+// LessonRequestFormat represents a Lesson's standard formatting for JSON deserializing.
+// Content is written in Markdown.
+type LessonRequestFormat struct {
+	Title   string `json:"title" validate:"required"`
+	Content string `json:"content"`
+}
+
+// LessonResponseFormat represents a Lesson's standard formatting for JSON serializing.
+type LessonResponseFormat struct {
+	ID       uuid.UUID `json:"id"`
+	ModuleID uuid.UUID `json:"moduleID"`
+	CourseID uuid.UUID `json:"courseID"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	// ContentHTML is Content rendered to sanitized HTML, safe to embed as is.
+	ContentHTML     string             `json:"contentHTML"`
+	TableOfContents []markdown.Heading `json:"tableOfContents"`
+	Position        int                `json:"position"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	CreatedBy       uuid.UUID          `json:"createdBy"`
+	UpdatedAt       null.Time          `json:"updatedAt"`
+	UpdatedBy       *uuid.UUID         `json:"updatedBy"`
+	Locked          bool               `json:"locked,omitempty"`
+	ReleasesAt      *time.Time         `json:"releasesAt,omitempty"`
+}
+
+//// Ordering
+
+// ReorderRequestFormat lists the IDs of all siblings in their new order.
+type ReorderRequestFormat struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1"`
+}
+
+// Positions maps each ID in the request to its new 1-based position. The IDs
+// must be exactly the set of current sibling IDs, each listed once.
+func (req ReorderRequestFormat) Positions(currentIDs []uuid.UUID) (positions map[uuid.UUID]int, err error) {
+	if len(req.IDs) != len(currentIDs) {
+		return nil, failure.BadRequestFromString(
+			fmt.Sprintf("expected %d ids, got %d", len(currentIDs), len(req.IDs)))
+	}
+
+	current := make(map[uuid.UUID]bool, len(currentIDs))
+	for _, id := range currentIDs {
+		current[id] = true
+	}
+
+	positions = make(map[uuid.UUID]int, len(req.IDs))
+	for i, id := range req.IDs {
+		if !current[id] {
+			return nil, failure.BadRequestFromString(fmt.Sprintf("unknown id %s", id))
+		}
+
+		if _, duplicate := positions[id]; duplicate {
+			return nil, failure.BadRequestFromString(fmt.Sprintf("duplicate id %s", id))
+		}
+
+		positions[id] = i + 1
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorderRequestFormat(t *testing.T) {
+	first, second, third := getRandomUUID(), getRandomUUID(), getRandomUUID()
+	current := []uuid.UUID{first, second, third}
+
+	tests := []struct {
+		name     string
+		ids      []uuid.UUID
+		want     map[uuid.UUID]int
+		wantCode int
+	}{
+		{
+			name: "default",
+			ids:  []uuid.UUID{third, first, second},
+			want: map[uuid.UUID]int{third: 1, first: 2, second: 3},
+		},
+		{name: "missing id", ids: []uuid.UUID{third, first}, wantCode: http.StatusBadRequest},
+		{name: "unknown id", ids: []uuid.UUID{third, first, getRandomUUID()}, wantCode: http.StatusBadRequest},
+		{name: "duplicate id", ids: []uuid.UUID{third, first, first}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := course.ReorderRequestFormat{IDs: test.ids}.Positions(current)
+			if test.wantCode != 0 {
+				assert.Equal(t, test.wantCode, failure.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source module_repository.go -destination mock/module_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	moduleQueries = struct {
+		selectModule             string
+		selectLesson             string
+		selectNextModulePosition string
+		selectNextLessonPosition string
+		insertModule             string
+		insertLesson             string
+		updateModule             string
+		updateLesson             string
+		updateModulePosition     string
+		updateLessonPosition     string
+	}{
+		selectModule: `
+			SELECT
+				id,
+				course_id,
+				title,
+				position,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM modules
+		`,
+
+		selectLesson: `
+			SELECT
+				id,
+				module_id,
+				course_id,
+				title,
+				content,
+				position,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM lessons
+		`,
+
+		selectNextModulePosition: `
+			SELECT COALESCE(MAX(position), 0) + 1
+			FROM modules
+			WHERE course_id = ? AND deleted_at IS NULL
+		`,
+
+		selectNextLessonPosition: `
+			SELECT COALESCE(MAX(position), 0) + 1
+			FROM lessons
+			WHERE module_id = ? AND deleted_at IS NULL
+		`,
+
+		insertModule: `
+			INSERT INTO modules (
+				id,
+				course_id,
+				title,
+				position,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:title,
+				:position,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		insertLesson: `
+			INSERT INTO lessons (
+				id,
+				module_id,
+				course_id,
+				title,
+				content,
+				position,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:module_id,
+				:course_id,
+				:title,
+				:content,
+				:position,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		updateModule: `
+			UPDATE modules
+			SET
+				title = :title,
+				position = :position,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		updateLesson: `
+			UPDATE lessons
+			SET
+				title = :title,
+				content = :content,
+				position = :position,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		updateModulePosition: `UPDATE modules SET position = ? WHERE id = ?`,
+
+		updateLessonPosition: `UPDATE lessons SET position = ? WHERE id = ?`,
+	}
+)
+
+// ModuleRepository is the repository for Module and Lesson data.
+type ModuleRepository interface {
+	CreateLesson(lesson Lesson) (err error)
+	CreateModule(module Module) (err error)
+	NextLessonPosition(moduleID uuid.UUID) (position int, err error)
+	NextModulePosition(courseID uuid.UUID) (position int, err error)
+	ReorderLessons(positions map[uuid.UUID]int) (err error)
+	ReorderModules(positions map[uuid.UUID]int) (err error)
+	ResolveLessonByID(id uuid.UUID) (lesson Lesson, err error)
+	ResolveLessonsByModuleIDs(ids []uuid.UUID) (lessons []Lesson, err error)
+	ResolveModuleByID(id uuid.UUID) (module Module, err error)
+	ResolveModulesByCourseIDs(ids []uuid.UUID) (modules []Module, err error)
+	UpdateLesson(lesson Lesson) (err error)
+	UpdateModule(module Module) (err error)
+}
+
+// ModuleRepositoryMySQL is the MySQL-backed implementation of ModuleRepository.
+type ModuleRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideModuleRepositoryMySQL is the provider for this repository.
+func ProvideModuleRepositoryMySQL(db *infras.MySQLConn) *ModuleRepositoryMySQL {
+	s := new(ModuleRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateLesson creates a new Lesson.
+func (r *ModuleRepositoryMySQL) CreateLesson(lesson Lesson) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, moduleQueries.insertLesson, lesson); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateModule creates a new Module.
+func (r *ModuleRepositoryMySQL) CreateModule(module Module) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, moduleQueries.insertModule, module); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// NextLessonPosition returns the position after the last active Lesson of a Module.
+func (r *ModuleRepositoryMySQL) NextLessonPosition(moduleID uuid.UUID) (position int, err error) {
+	err = r.DB.Read.Get(&position, moduleQueries.selectNextLessonPosition, moduleID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// NextModulePosition returns the position after the last active Module of a Course.
+func (r *ModuleRepositoryMySQL) NextModulePosition(courseID uuid.UUID) (position int, err error) {
+	err = r.DB.Read.Get(&position, moduleQueries.selectNextModulePosition, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ReorderLessons sets the positions of a set of Lessons in one transaction.
+func (r *ModuleRepositoryMySQL) ReorderLessons(positions map[uuid.UUID]int) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txUpdatePositions(tx, moduleQueries.updateLessonPosition, positions); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ReorderModules sets the positions of a set of Modules in one transaction.
+func (r *ModuleRepositoryMySQL) ReorderModules(positions map[uuid.UUID]int) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txUpdatePositions(tx, moduleQueries.updateModulePosition, positions); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveLessonByID resolves a Lesson by its ID.
+func (r *ModuleRepositoryMySQL) ResolveLessonByID(id uuid.UUID) (lesson Lesson, err error) {
+	err = r.DB.Read.Get(&lesson, moduleQueries.selectLesson+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("lesson")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveLessonsByModuleIDs resolves the active Lessons of a set of Modules,
+// ordered by their position.
+func (r *ModuleRepositoryMySQL) ResolveLessonsByModuleIDs(ids []uuid.UUID) (lessons []Lesson, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(
+		moduleQueries.selectLesson+" WHERE module_id IN (?) AND deleted_at IS NULL ORDER BY position",
+		ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&lessons, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveModuleByID resolves a Module by its ID.
+func (r *ModuleRepositoryMySQL) ResolveModuleByID(id uuid.UUID) (module Module, err error) {
+	err = r.DB.Read.Get(&module, moduleQueries.selectModule+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("module")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveModulesByCourseIDs resolves the active Modules of a set of Courses,
+// ordered by their position.
+func (r *ModuleRepositoryMySQL) ResolveModulesByCourseIDs(ids []uuid.UUID) (modules []Module, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(
+		moduleQueries.selectModule+" WHERE course_id IN (?) AND deleted_at IS NULL ORDER BY position",
+		ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&modules, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateLesson updates a Lesson.
+func (r *ModuleRepositoryMySQL) UpdateLesson(lesson Lesson) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, moduleQueries.updateLesson, lesson); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateModule updates a Module.
+func (r *ModuleRepositoryMySQL) UpdateModule(module Module) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, moduleQueries.updateModule, module); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *ModuleRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txUpdatePositions applies a position query to every entry of positions
+// transactionally given the *sqlx.Tx param.
+func (r *ModuleRepositoryMySQL) txUpdatePositions(tx *sqlx.Tx, query string, positions map[uuid.UUID]int) (err error) {
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	for id, position := range positions {
+		_, err = stmt.Exec(position, id.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,318 @@
+package course
+
+import (
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// ModuleService is the service interface for Module and Lesson entities.
+type ModuleService interface {
+	CreateLesson(moduleID uuid.UUID, requestFormat LessonRequestFormat, userID uuid.UUID) (lesson Lesson, err error)
+	CreateModule(courseID uuid.UUID, requestFormat ModuleRequestFormat, userID uuid.UUID) (module Module, err error)
+	ReorderLessons(moduleID uuid.UUID, requestFormat ReorderRequestFormat, userID uuid.UUID) (lessons []Lesson, err error)
+	ReorderModules(courseID uuid.UUID, requestFormat ReorderRequestFormat, userID uuid.UUID) (modules []Module, err error)
+	ResolveLessonByID(id uuid.UUID) (lesson Lesson, err error)
+	ResolveReadableLessonByID(id uuid.UUID, userID uuid.UUID, role string) (lesson Lesson, err error)
+	SoftDeleteLesson(id uuid.UUID, userID uuid.UUID) (lesson Lesson, err error)
+	SoftDeleteModule(id uuid.UUID, userID uuid.UUID) (module Module, err error)
+	UpdateLesson(id uuid.UUID, requestFormat LessonRequestFormat, userID uuid.UUID) (lesson Lesson, err error)
+	UpdateModule(id uuid.UUID, requestFormat ModuleRequestFormat, userID uuid.UUID) (module Module, err error)
+}
+
+// ModuleServiceImpl is the service implementation for Module and Lesson entities.
+type ModuleServiceImpl struct {
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	PublishingRepository   PublishingRepository
+	Config                 *configs.Config
+}
+
+// ProvideModuleServiceImpl is the provider for this service.
+func ProvideModuleServiceImpl(cohortRepository CohortRepository, collaboratorRepository CollaboratorRepository, courseRepository CourseRepository, enrollmentRepository EnrollmentRepository, moduleRepository ModuleRepository, publishingRepository PublishingRepository, config *configs.Config) *ModuleServiceImpl {
+	s := new(ModuleServiceImpl)
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.PublishingRepository = publishingRepository
+	s.Config = config
+
+	return s
+}
+
+// CreateLesson appends a new Lesson to the end of a Module.
+func (s *ModuleServiceImpl) CreateLesson(moduleID uuid.UUID, requestFormat LessonRequestFormat, userID uuid.UUID) (lesson Lesson, err error) {
+	module, err := s.resolveEditableModule(moduleID, userID)
+	if err != nil {
+		return
+	}
+
+	position, err := s.ModuleRepository.NextLessonPosition(module.ID)
+	if err != nil {
+		return
+	}
+
+	lesson, err = lesson.NewLessonFromRequestFormat(requestFormat, module, position, userID)
+	if err != nil {
+		return lesson, failure.BadRequest(err)
+	}
+
+	err = s.ModuleRepository.CreateLesson(lesson)
+	return
+}
+
+// CreateModule appends a new Module to the end of a Course.
+func (s *ModuleServiceImpl) CreateModule(courseID uuid.UUID, requestFormat ModuleRequestFormat, userID uuid.UUID) (module Module, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	position, err := s.ModuleRepository.NextModulePosition(course.ID)
+	if err != nil {
+		return
+	}
+
+	module, err = module.NewModuleFromRequestFormat(requestFormat, course.ID, position, userID)
+	if err != nil {
+		return module, failure.BadRequest(err)
+	}
+
+	err = s.ModuleRepository.CreateModule(module)
+	return
+}
+
+// ReorderLessons rewrites the positions of all Lessons in a Module.
+func (s *ModuleServiceImpl) ReorderLessons(moduleID uuid.UUID, requestFormat ReorderRequestFormat, userID uuid.UUID) (lessons []Lesson, err error) {
+	module, err := s.resolveEditableModule(moduleID, userID)
+	if err != nil {
+		return
+	}
+
+	lessons, err = s.ModuleRepository.ResolveLessonsByModuleIDs([]uuid.UUID{module.ID})
+	if err != nil {
+		return
+	}
+
+	currentIDs := make([]uuid.UUID, 0, len(lessons))
+	for _, lesson := range lessons {
+		currentIDs = append(currentIDs, lesson.ID)
+	}
+
+	positions, err := requestFormat.Positions(currentIDs)
+	if err != nil {
+		return
+	}
+
+	err = s.ModuleRepository.ReorderLessons(positions)
+	if err != nil {
+		return
+	}
+
+	return s.ModuleRepository.ResolveLessonsByModuleIDs([]uuid.UUID{module.ID})
+}
+
+// ReorderModules rewrites the positions of all Modules in a Course.
+func (s *ModuleServiceImpl) ReorderModules(courseID uuid.UUID, requestFormat ReorderRequestFormat, userID uuid.UUID) (modules []Module, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	modules, err = s.ModuleRepository.ResolveModulesByCourseIDs([]uuid.UUID{course.ID})
+	if err != nil {
+		return
+	}
+
+	currentIDs := make([]uuid.UUID, 0, len(modules))
+	for _, module := range modules {
+		currentIDs = append(currentIDs, module.ID)
+	}
+
+	positions, err := requestFormat.Positions(currentIDs)
+	if err != nil {
+		return
+	}
+
+	err = s.ModuleRepository.ReorderModules(positions)
+	if err != nil {
+		return
+	}
+
+	return s.ModuleRepository.ResolveModulesByCourseIDs([]uuid.UUID{course.ID})
+}
+
+// ResolveLessonByID resolves a Lesson by its ID.
+func (s *ModuleServiceImpl) ResolveLessonByID(id uuid.UUID) (lesson Lesson, err error) {
+	return resolveLesson(s.ModuleRepository, id)
+}
+
+// ResolveReadableLessonByID resolves a Lesson on behalf of a user, who must be a
+// teacher or an actively enrolled student of the Lesson's Course. Teachers see
+// the current draft, students the published version once their Cohort's drip
+// schedule releases it.
+func (s *ModuleServiceImpl) ResolveReadableLessonByID(id uuid.UUID, userID uuid.UUID, role string) (lesson Lesson, err error) {
+	if role == shared.RoleTeacher {
+		return s.ResolveLessonByID(id)
+	}
+
+	// the draft may have changed or removed the Lesson since it was published
+	draft, err := s.ModuleRepository.ResolveLessonByID(id)
+	if err != nil {
+		return
+	}
+
+	err = checkReadAccess(s.EnrollmentRepository, draft.CourseID, userID, role)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReleased(s.EnrollmentRepository, s.CohortRepository, draft, userID)
+	if err != nil {
+		return
+	}
+
+	course, err := s.CourseRepository.ResolveCourseByID(draft.CourseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || !course.IsVisibleToStudents() {
+		return lesson, failure.NotFound("lesson")
+	}
+
+	version, err := s.PublishingRepository.ResolveVersion(course.ID, int(course.PublishedVersion.Int64))
+	if err != nil {
+		return
+	}
+
+	lesson, ok := version.LessonByID(course.ID, id)
+	if !ok {
+		return lesson, failure.NotFound("lesson")
+	}
+
+	return
+}
+
+// SoftDeleteLesson marks a Lesson as deleted.
+func (s *ModuleServiceImpl) SoftDeleteLesson(id uuid.UUID, userID uuid.UUID) (lesson Lesson, err error) {
+	lesson, err = s.resolveEditableLesson(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = lesson.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.ModuleRepository.UpdateLesson(lesson)
+	return
+}
+
+// SoftDeleteModule marks a Module as deleted. Its Lessons are hidden along with it.
+func (s *ModuleServiceImpl) SoftDeleteModule(id uuid.UUID, userID uuid.UUID) (module Module, err error) {
+	module, err = s.resolveEditableModule(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = module.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.ModuleRepository.UpdateModule(module)
+	return
+}
+
+// UpdateLesson updates a Lesson.
+func (s *ModuleServiceImpl) UpdateLesson(id uuid.UUID, requestFormat LessonRequestFormat, userID uuid.UUID) (lesson Lesson, err error) {
+	lesson, err = s.resolveEditableLesson(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = lesson.Update(requestFormat, userID)
+	if err != nil {
+		return lesson, failure.BadRequest(err)
+	}
+
+	err = s.ModuleRepository.UpdateLesson(lesson)
+	return
+}
+
+// UpdateModule updates a Module.
+func (s *ModuleServiceImpl) UpdateModule(id uuid.UUID, requestFormat ModuleRequestFormat, userID uuid.UUID) (module Module, err error) {
+	module, err = s.resolveEditableModule(id, userID)
+	if err != nil {
+		return
+	}
+
+	err = module.Update(requestFormat, userID)
+	if err != nil {
+		return module, failure.BadRequest(err)
+	}
+
+	err = s.ModuleRepository.UpdateModule(module)
+	return
+}
+
+// resolveEditableLesson resolves a non-deleted Lesson whose content the given
+// user may edit.
+func (s *ModuleServiceImpl) resolveEditableLesson(id uuid.UUID, userID uuid.UUID) (lesson Lesson, err error) {
+	lesson, err = s.ResolveLessonByID(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, lesson.CourseID, userID, PermissionEditContent)
+	return
+}
+
+// resolveEditableModule resolves a non-deleted Module whose content the given
+// user may edit.
+func (s *ModuleServiceImpl) resolveEditableModule(id uuid.UUID, userID uuid.UUID) (module Module, err error) {
+	module, err = s.ModuleRepository.ResolveModuleByID(id)
+	if err != nil {
+		return
+	}
+
+	if module.IsDeleted() {
+		return module, failure.NotFound("module")
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, module.CourseID, userID, PermissionEditContent)
+	return
+}
+
+// resolveLesson resolves a Lesson that is neither deleted itself nor part of a
+// deleted Module.
+func resolveLesson(moduleRepository ModuleRepository, id uuid.UUID) (lesson Lesson, err error) {
+	lesson, err = moduleRepository.ResolveLessonByID(id)
+	if err != nil {
+		return
+	}
+
+	if lesson.IsDeleted() {
+		return lesson, failure.NotFound("lesson")
+	}
+
+	// a Lesson is hidden together with its Module
+	module, err := moduleRepository.ResolveModuleByID(lesson.ModuleID)
+	if err != nil {
+		return
+	}
+
+	if module.IsDeleted() {
+		return lesson, failure.NotFound("lesson")
+	}
+
+	return
+}
@@ -0,0 +1,219 @@
+package course
+
+import (
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+var errPrerequisiteCycle = failure.Conflict("save", "prerequisites", "a course cannot require itself, directly or through its prerequisites")
+
+//// Course Prerequisites
+
+// CoursePrerequisite records that a Course requires completing another one,
+// its prerequisite, first.
+type CoursePrerequisite struct {
+	CourseID       uuid.UUID `db:"course_id"`
+	PrerequisiteID uuid.UUID `db:"prerequisite_id"`
+	CreatedAt      time.Time `db:"created_at"`
+	CreatedBy      uuid.UUID `db:"created_by"`
+}
+
+// NewCoursePrerequisites builds the prerequisites of a Course, dropping
+// duplicates.
+func NewCoursePrerequisites(courseID uuid.UUID, prerequisiteIDs []uuid.UUID, userID uuid.UUID) (prerequisites []CoursePrerequisite) {
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range prerequisiteIDs {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		prerequisites = append(prerequisites, CoursePrerequisite{
+			CourseID:       courseID,
+			PrerequisiteID: id,
+			CreatedAt:      time.Now(),
+			CreatedBy:      userID,
+		})
+	}
+
+	return
+}
+
+// PrerequisiteGraph holds every CoursePrerequisite between Courses that are
+// not deleted. It is acyclic as long as changes are checked with CheckCycle.
+type PrerequisiteGraph []CoursePrerequisite
+
+// PrerequisiteStep is a Course on the way to another one. Depth is the length
+// of the longest path to it, so direct prerequisites are at depth 1 unless
+// another prerequisite requires them too.
+type PrerequisiteStep struct {
+	CourseID uuid.UUID
+	Depth    int
+}
+
+// Chain resolves every Course that has to be completed before the given one,
+// in an order they can be taken in: each Course comes after its own
+// prerequisites.
+func (g PrerequisiteGraph) Chain(courseID uuid.UUID) (steps []PrerequisiteStep) {
+	prerequisitesOf := g.adjacency()
+
+	var order []uuid.UUID
+	visited := map[uuid.UUID]bool{courseID: true}
+	var visit func(id uuid.UUID)
+	visit = func(id uuid.UUID) {
+		for _, prerequisiteID := range prerequisitesOf[id] {
+			if !visited[prerequisiteID] {
+				visited[prerequisiteID] = true
+				visit(prerequisiteID)
+			}
+		}
+
+		order = append(order, id)
+	}
+	visit(courseID)
+
+	// order holds every Course after its prerequisites and ends with the
+	// given one, so walking it backwards settles each depth before it is used
+	depths := make(map[uuid.UUID]int, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, prerequisiteID := range prerequisitesOf[order[i]] {
+			if depths[order[i]]+1 > depths[prerequisiteID] {
+				depths[prerequisiteID] = depths[order[i]] + 1
+			}
+		}
+	}
+
+	steps = make([]PrerequisiteStep, 0, len(order)-1)
+	for _, id := range order[:len(order)-1] {
+		steps = append(steps, PrerequisiteStep{CourseID: id, Depth: depths[id]})
+	}
+
+	return
+}
+
+// CheckCycle makes sure a Course can require the given prerequisites without
+// ending up, directly or not, among its own prerequisites.
+func (g PrerequisiteGraph) CheckCycle(courseID uuid.UUID, prerequisiteIDs []uuid.UUID) (err error) {
+	prerequisitesOf := g.adjacency()
+
+	for _, prerequisiteID := range prerequisiteIDs {
+		if prerequisiteID == courseID {
+			return errPrerequisiteCycle
+		}
+
+		visited := make(map[uuid.UUID]bool)
+		queue := []uuid.UUID{prerequisiteID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, next := range prerequisitesOf[id] {
+				if next == courseID {
+					return errPrerequisiteCycle
+				}
+
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Edges resolves the CoursePrerequisites between the given Courses.
+func (g PrerequisiteGraph) Edges(courseIDs []uuid.UUID) (edges []CoursePrerequisite) {
+	included := make(map[uuid.UUID]bool, len(courseIDs))
+	for _, id := range courseIDs {
+		included[id] = true
+	}
+
+	for _, edge := range g {
+		if included[edge.CourseID] && included[edge.PrerequisiteID] {
+			edges = append(edges, edge)
+		}
+	}
+
+	return
+}
+
+// adjacency indexes the prerequisites of each Course.
+func (g PrerequisiteGraph) adjacency() map[uuid.UUID][]uuid.UUID {
+	prerequisitesOf := make(map[uuid.UUID][]uuid.UUID)
+	for _, edge := range g {
+		prerequisitesOf[edge.CourseID] = append(prerequisitesOf[edge.CourseID], edge.PrerequisiteID)
+	}
+
+	return prerequisitesOf
+}
+
+// CoursePrerequisitesRequestFormat represents a request to replace the
+// prerequisites of a Course.
+type CoursePrerequisitesRequestFormat struct {
+	PrerequisiteIDs []uuid.UUID `json:"prerequisiteIDs" validate:"max=20"`
+}
+
+// PrerequisiteChain is the learning path leading to a Course.
+type PrerequisiteChain struct {
+	CourseID uuid.UUID                 `json:"courseID"`
+	Courses  []PrerequisiteChainCourse `json:"courses"`
+	Edges    []PrerequisiteEdgeFormat  `json:"edges"`
+}
+
+// PrerequisiteChainCourse is a Course on a learning path, listed after its
+// own prerequisites.
+type PrerequisiteChainCourse struct {
+	ID     uuid.UUID    `json:"id"`
+	Title  string       `json:"title"`
+	Status CourseStatus `json:"status"`
+	Depth  int          `json:"depth"`
+}
+
+// PrerequisiteEdgeFormat tells that a Course requires another one.
+type PrerequisiteEdgeFormat struct {
+	CourseID       uuid.UUID `json:"courseID"`
+	PrerequisiteID uuid.UUID `json:"prerequisiteID"`
+}
+
+// NewPrerequisiteChain builds the learning path leading to a Course from the
+// graph of prerequisites and the Courses on it.
+func NewPrerequisiteChain(courseID uuid.UUID, graph PrerequisiteGraph, courses []Course) PrerequisiteChain {
+	coursesByID := make(map[uuid.UUID]Course, len(courses))
+	for _, course := range courses {
+		coursesByID[course.ID] = course
+	}
+
+	chain := PrerequisiteChain{
+		CourseID: courseID,
+		Courses:  make([]PrerequisiteChainCourse, 0),
+		Edges:    make([]PrerequisiteEdgeFormat, 0),
+	}
+
+	ids := []uuid.UUID{courseID}
+	for _, step := range graph.Chain(courseID) {
+		course, ok := coursesByID[step.CourseID]
+		if !ok {
+			continue
+		}
+
+		ids = append(ids, step.CourseID)
+		chain.Courses = append(chain.Courses, PrerequisiteChainCourse{
+			ID:     course.ID,
+			Title:  course.Title,
+			Status: course.Status,
+			Depth:  step.Depth,
+		})
+	}
+
+	for _, edge := range graph.Edges(ids) {
+		chain.Edges = append(chain.Edges, PrerequisiteEdgeFormat{
+			CourseID:       edge.CourseID,
+			PrerequisiteID: edge.PrerequisiteID,
+		})
+	}
+
+	return chain
+}
@@ -0,0 +1,92 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrerequisiteGraph(t *testing.T) {
+	// basics <- syntax <- concurrency <- microservices
+	//   ^                                    |
+	//   +------------------------------------+
+	basics, syntax, concurrency, microservices, unrelated := getRandomUUID(), getRandomUUID(), getRandomUUID(), getRandomUUID(), getRandomUUID()
+	graph := course.PrerequisiteGraph{
+		{CourseID: syntax, PrerequisiteID: basics},
+		{CourseID: concurrency, PrerequisiteID: syntax},
+		{CourseID: microservices, PrerequisiteID: concurrency},
+		{CourseID: microservices, PrerequisiteID: basics},
+	}
+
+	t.Run("chain lists prerequisites before the courses requiring them", func(t *testing.T) {
+		steps := graph.Chain(microservices)
+
+		assert.Equal(t, []course.PrerequisiteStep{
+			{CourseID: basics, Depth: 3},
+			{CourseID: syntax, Depth: 2},
+			{CourseID: concurrency, Depth: 1},
+		}, steps)
+		assert.Empty(t, graph.Chain(basics))
+		assert.Empty(t, graph.Chain(unrelated))
+	})
+
+	t.Run("check cycle", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			courseID        uuid.UUID
+			prerequisiteIDs []uuid.UUID
+			wantConflict    bool
+		}{
+			{name: "new prerequisite", courseID: concurrency, prerequisiteIDs: []uuid.UUID{syntax, unrelated}},
+			{name: "shortcut to an indirect prerequisite", courseID: concurrency, prerequisiteIDs: []uuid.UUID{basics}},
+			{name: "itself", courseID: basics, prerequisiteIDs: []uuid.UUID{basics}, wantConflict: true},
+			{name: "direct cycle", courseID: basics, prerequisiteIDs: []uuid.UUID{syntax}, wantConflict: true},
+			{name: "indirect cycle", courseID: syntax, prerequisiteIDs: []uuid.UUID{unrelated, microservices}, wantConflict: true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := graph.CheckCycle(test.courseID, test.prerequisiteIDs)
+
+				if !test.wantConflict {
+					assert.NoError(t, err)
+					return
+				}
+
+				assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+			})
+		}
+	})
+
+	t.Run("new chain", func(t *testing.T) {
+		courses := []course.Course{
+			{ID: basics, Title: "Go basics", Status: course.CourseStatusPublished},
+			{ID: syntax, Title: "Go syntax", Status: course.CourseStatusPublished},
+			{ID: concurrency, Title: "Go concurrency", Status: course.CourseStatusDraft},
+		}
+
+		chain := course.NewPrerequisiteChain(microservices, graph, courses)
+
+		assert.Equal(t, microservices, chain.CourseID)
+		assert.Len(t, chain.Courses, 3)
+		assert.Equal(t, "Go basics", chain.Courses[0].Title)
+		assert.Equal(t, course.CourseStatusDraft, chain.Courses[2].Status)
+		assert.Len(t, chain.Edges, 4)
+	})
+}
+
+func TestNewCoursePrerequisites(t *testing.T) {
+	courseID, first, second, userID := getRandomUUID(), getRandomUUID(), getRandomUUID(), getRandomUUID()
+
+	prerequisites := course.NewCoursePrerequisites(courseID, []uuid.UUID{first, second, first}, userID)
+
+	assert.Len(t, prerequisites, 2)
+	assert.Equal(t, first, prerequisites[0].PrerequisiteID)
+	assert.Equal(t, second, prerequisites[1].PrerequisiteID)
+	assert.Equal(t, courseID, prerequisites[1].CourseID)
+	assert.Equal(t, userID, prerequisites[1].CreatedBy)
+}
@@ -0,0 +1,147 @@
+package course
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+//// Lesson Progress
+
+// LessonProgress records how far a student got in a Lesson.
+type LessonProgress struct {
+	ID           uuid.UUID `db:"id"`
+	CourseID     uuid.UUID `db:"course_id"`
+	LessonID     uuid.UUID `db:"lesson_id"`
+	StudentID    uuid.UUID `db:"student_id"`
+	LastPosition int       `db:"last_position"`
+	StartedAt    time.Time `db:"started_at"`
+	CompletedAt  null.Time `db:"completed_at"`
+	UpdatedAt    null.Time `db:"updated_at"`
+}
+
+// IsCompleted checks whether the student completed the Lesson.
+func (lp *LessonProgress) IsCompleted() bool {
+	return lp.CompletedAt.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (lp LessonProgress) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lp.ToResponseFormat())
+}
+
+// NewLessonProgress starts tracking a student's progress in a Lesson.
+func (lp LessonProgress) NewLessonProgress(lesson Lesson, studentID uuid.UUID) LessonProgress {
+	progressID, _ := uuid.NewV4()
+	return LessonProgress{
+		ID:        progressID,
+		CourseID:  lesson.CourseID,
+		LessonID:  lesson.ID,
+		StudentID: studentID,
+		StartedAt: time.Now(),
+	}
+}
+
+// Record applies a progress update. A completed Lesson stays completed.
+func (lp *LessonProgress) Record(req LessonProgressRequestFormat) {
+	lp.LastPosition = req.LastPosition
+	if req.Completed && !lp.IsCompleted() {
+		lp.CompletedAt = null.TimeFrom(time.Now())
+	}
+
+	lp.UpdatedAt = null.TimeFrom(time.Now())
+}
+
+// ToResponseFormat converts this LessonProgress to its response format.
+func (lp LessonProgress) ToResponseFormat() LessonProgressResponseFormat {
+	return LessonProgressResponseFormat{
+		LessonID:     lp.LessonID,
+		StudentID:    lp.StudentID,
+		LastPosition: lp.LastPosition,
+		StartedAt:    lp.StartedAt,
+		CompletedAt:  lp.CompletedAt,
+		UpdatedAt:    lp.UpdatedAt,
+	}
+}
+
+// LessonProgressRequestFormat represents a progress update sent by a student.
+type LessonProgressRequestFormat struct {
+	LastPosition int  `json:"lastPosition" validate:"min=0"`
+	Completed    bool `json:"completed"`
+}
+
+// LessonProgressResponseFormat represents a LessonProgress's standard formatting for JSON serializing.
+type LessonProgressResponseFormat struct {
+	LessonID     uuid.UUID `json:"lessonID"`
+	StudentID    uuid.UUID `json:"studentID"`
+	LastPosition int       `json:"lastPosition"`
+	StartedAt    time.Time `json:"startedAt"`
+	CompletedAt  null.Time `json:"completedAt"`
+	UpdatedAt    null.Time `json:"updatedAt"`
+}
+
+//// Course Progress
+
+// StudentCompletion counts the Lessons a student completed in a Course.
+type StudentCompletion struct {
+	StudentID        uuid.UUID `db:"student_id"`
+	CompletedLessons int       `db:"completed_lessons"`
+	LastActivityAt   null.Time `db:"last_activity_at"`
+}
+
+// CourseProgress summarises a student's progress through a Course.
+type CourseProgress struct {
+	CourseID             uuid.UUID                      `json:"courseID"`
+	StudentID            uuid.UUID                      `json:"studentID"`
+	TotalLessons         int                            `json:"totalLessons"`
+	CompletedLessons     int                            `json:"completedLessons"`
+	CompletionPercentage float64                        `json:"completionPercentage"`
+	LastActivityAt       null.Time                      `json:"lastActivityAt"`
+	AttendancePercentage null.Float                     `json:"attendancePercentage"`
+	RequiredAttendance   null.Int                       `json:"requiredAttendancePercentage"`
+	Lessons              []LessonProgressResponseFormat `json:"lessons,omitempty"`
+}
+
+// NewCourseProgress builds a CourseProgress from a student's completion count.
+func NewCourseProgress(courseID uuid.UUID, completion StudentCompletion, totalLessons int) CourseProgress {
+	return CourseProgress{
+		CourseID:             courseID,
+		StudentID:            completion.StudentID,
+		TotalLessons:         totalLessons,
+		CompletedLessons:     completion.CompletedLessons,
+		CompletionPercentage: CompletionPercentage(completion.CompletedLessons, totalLessons),
+		LastActivityAt:       completion.LastActivityAt,
+	}
+}
+
+// ApplyAttendance records a student's attendance against the attendance the
+// Course requires to be completed.
+func (cp *CourseProgress) ApplyAttendance(summary AttendanceSummary, rules CompletionRules) {
+	cp.AttendancePercentage = summary.AttendancePercentage
+	cp.RequiredAttendance = rules.MinAttendancePercent
+}
+
+// IsComplete checks whether the student completed every Lesson of the Course
+// and attended as often as it requires. Without occurrences to attend yet, the
+// attendance requirement is met.
+func (cp CourseProgress) IsComplete() bool {
+	if cp.RequiredAttendance.Valid && cp.AttendancePercentage.Valid &&
+		cp.AttendancePercentage.Float64 < float64(cp.RequiredAttendance.Int64) {
+		return false
+	}
+
+	return cp.TotalLessons > 0 && cp.CompletedLessons >= cp.TotalLessons
+}
+
+// CompletionPercentage returns completed/total as a percentage rounded to two
+// decimals. A Course without Lessons is 0% complete.
+func CompletionPercentage(completed int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(completed)/float64(total)*10000) / 100
+}
@@ -0,0 +1,46 @@
+package course_test
+
+import (
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionPercentage(t *testing.T) {
+	tests := []struct {
+		name      string
+		completed int
+		total     int
+		want      float64
+	}{
+		{name: "no lessons", completed: 0, total: 0, want: 0},
+		{name: "none completed", completed: 0, total: 4, want: 0},
+		{name: "rounded", completed: 1, total: 3, want: 33.33},
+		{name: "all completed", completed: 4, total: 4, want: 100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, course.CompletionPercentage(test.completed, test.total))
+		})
+	}
+}
+
+func TestLessonProgressRecord(t *testing.T) {
+	lesson := course.Lesson{ID: getRandomUUID(), CourseID: getRandomUUID()}
+	progress := course.LessonProgress{}.NewLessonProgress(lesson, getRandomUUID())
+
+	progress.Record(course.LessonProgressRequestFormat{LastPosition: 30})
+	assert.False(t, progress.IsCompleted())
+	assert.Equal(t, 30, progress.LastPosition)
+
+	progress.Record(course.LessonProgressRequestFormat{LastPosition: 90, Completed: true})
+	assert.True(t, progress.IsCompleted())
+	completedAt := progress.CompletedAt
+
+	progress.Record(course.LessonProgressRequestFormat{LastPosition: 10})
+	assert.True(t, progress.IsCompleted(), "a completed lesson stays completed")
+	assert.Equal(t, completedAt, progress.CompletedAt)
+	assert.Equal(t, 10, progress.LastPosition)
+}
@@ -0,0 +1,208 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source progress_repository.go -destination mock/progress_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	progressQueries = struct {
+		selectLessonProgress       string
+		selectLessonProgressLocked string
+		selectStudentCompletions   string
+		countActiveLessons         string
+		insertLessonProgress       string
+		updateLessonProgress       string
+	}{
+		selectLessonProgress: `
+			SELECT
+				lp.id,
+				lp.course_id,
+				lp.lesson_id,
+				lp.student_id,
+				lp.last_position,
+				lp.started_at,
+				lp.completed_at,
+				lp.updated_at
+			FROM lesson_progress lp
+			JOIN lessons l ON l.id = lp.lesson_id AND l.deleted_at IS NULL
+			JOIN modules m ON m.id = l.module_id AND m.deleted_at IS NULL
+		`,
+
+		selectLessonProgressLocked: `
+			SELECT
+				id,
+				course_id,
+				lesson_id,
+				student_id,
+				last_position,
+				started_at,
+				completed_at,
+				updated_at
+			FROM lesson_progress
+			WHERE lesson_id = ? AND student_id = ?
+			FOR UPDATE
+		`,
+
+		selectStudentCompletions: `
+			SELECT
+				lp.student_id,
+				COUNT(lp.completed_at) AS completed_lessons,
+				MAX(COALESCE(lp.updated_at, lp.started_at)) AS last_activity_at
+			FROM lesson_progress lp
+			JOIN lessons l ON l.id = lp.lesson_id AND l.deleted_at IS NULL
+			JOIN modules m ON m.id = l.module_id AND m.deleted_at IS NULL
+			WHERE lp.course_id = ?
+			GROUP BY lp.student_id
+		`,
+
+		countActiveLessons: `
+			SELECT COUNT(l.id)
+			FROM lessons l
+			JOIN modules m ON m.id = l.module_id AND m.deleted_at IS NULL
+			WHERE l.course_id = ? AND l.deleted_at IS NULL
+		`,
+
+		insertLessonProgress: `
+			INSERT INTO lesson_progress (
+				id,
+				course_id,
+				lesson_id,
+				student_id,
+				last_position,
+				started_at,
+				completed_at,
+				updated_at
+			) VALUES (
+				:id,
+				:course_id,
+				:lesson_id,
+				:student_id,
+				:last_position,
+				:started_at,
+				:completed_at,
+				:updated_at
+			)
+		`,
+
+		updateLessonProgress: `
+			UPDATE lesson_progress
+			SET
+				last_position = :last_position,
+				completed_at = :completed_at,
+				updated_at = :updated_at
+			WHERE id = :id
+		`,
+	}
+)
+
+// ProgressRepository is the repository for LessonProgress data.
+type ProgressRepository interface {
+	CountActiveLessons(courseID uuid.UUID) (count int, err error)
+	RecordLessonProgress(lesson Lesson, studentID uuid.UUID, req LessonProgressRequestFormat) (progress LessonProgress, err error)
+	ResolveLessonProgress(courseID uuid.UUID, studentID uuid.UUID) (progress []LessonProgress, err error)
+	ResolveStudentCompletions(courseID uuid.UUID) (completions []StudentCompletion, err error)
+}
+
+// ProgressRepositoryMySQL is the MySQL-backed implementation of ProgressRepository.
+type ProgressRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideProgressRepositoryMySQL is the provider for this repository.
+func ProvideProgressRepositoryMySQL(db *infras.MySQLConn) *ProgressRepositoryMySQL {
+	s := new(ProgressRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CountActiveLessons counts the Lessons of a Course that are not deleted.
+func (r *ProgressRepositoryMySQL) CountActiveLessons(courseID uuid.UUID) (count int, err error) {
+	err = r.DB.Read.Get(&count, progressQueries.countActiveLessons, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// RecordLessonProgress applies a progress update to a student's LessonProgress,
+// creating it on the first update. The row is locked for the duration of the
+// transaction so concurrent updates from several devices don't overwrite each other.
+func (r *ProgressRepositoryMySQL) RecordLessonProgress(lesson Lesson, studentID uuid.UUID, req LessonProgressRequestFormat) (progress LessonProgress, err error) {
+	err = r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		err := tx.Get(&progress, progressQueries.selectLessonProgressLocked, lesson.ID.String(), studentID.String())
+		if err != nil && err != sql.ErrNoRows {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		query := progressQueries.updateLessonProgress
+		if err == sql.ErrNoRows {
+			progress = progress.NewLessonProgress(lesson, studentID)
+			query = progressQueries.insertLessonProgress
+		}
+
+		progress.Record(req)
+
+		if err := r.txExecNamed(tx, query, progress); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+
+	return
+}
+
+// ResolveLessonProgress resolves a student's progress in the active Lessons of a Course.
+func (r *ProgressRepositoryMySQL) ResolveLessonProgress(courseID uuid.UUID, studentID uuid.UUID) (progress []LessonProgress, err error) {
+	err = r.DB.Read.Select(
+		&progress,
+		progressQueries.selectLessonProgress+" WHERE lp.course_id = ? AND lp.student_id = ? ORDER BY m.position, l.position",
+		courseID.String(),
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveStudentCompletions counts the completed active Lessons of a Course per student.
+func (r *ProgressRepositoryMySQL) ResolveStudentCompletions(courseID uuid.UUID) (completions []StudentCompletion, err error) {
+	err = r.DB.Read.Select(&completions, progressQueries.selectStudentCompletions, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *ProgressRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,250 @@
+package course
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+)
+
+// ProgressService is the service interface for lesson progress tracking.
+type ProgressService interface {
+	RecordLessonProgress(lessonID uuid.UUID, requestFormat LessonProgressRequestFormat, studentID uuid.UUID, studentName string) (progress LessonProgress, err error)
+	ResolveCourseProgress(courseID uuid.UUID, studentID uuid.UUID) (progress CourseProgress, err error)
+	ResolveCourseRoster(courseID uuid.UUID, userID uuid.UUID) (roster []CourseProgress, err error)
+}
+
+// ProgressServiceImpl is the service implementation for lesson progress tracking.
+type ProgressServiceImpl struct {
+	AttendanceRepository   AttendanceRepository
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	ModuleRepository       ModuleRepository
+	ProgressRepository     ProgressRepository
+	CertificateRepository  CertificateRepository
+	Config                 *configs.Config
+}
+
+// ProvideProgressServiceImpl is the provider for this service.
+func ProvideProgressServiceImpl(
+	attendanceRepository AttendanceRepository,
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	moduleRepository ModuleRepository,
+	progressRepository ProgressRepository,
+	certificateRepository CertificateRepository,
+	config *configs.Config) *ProgressServiceImpl {
+	s := new(ProgressServiceImpl)
+	s.AttendanceRepository = attendanceRepository
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.ModuleRepository = moduleRepository
+	s.ProgressRepository = progressRepository
+	s.CertificateRepository = certificateRepository
+	s.Config = config
+
+	return s
+}
+
+// RecordLessonProgress records an actively enrolled student's position in a
+// Lesson and, optionally, its completion. Lessons their Cohort has not released
+// yet can't be progressed through.
+func (s *ProgressServiceImpl) RecordLessonProgress(lessonID uuid.UUID, requestFormat LessonProgressRequestFormat, studentID uuid.UUID, studentName string) (progress LessonProgress, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	err = checkReadAccess(s.EnrollmentRepository, lesson.CourseID, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReleased(s.EnrollmentRepository, s.CohortRepository, lesson, studentID)
+	if err != nil {
+		return
+	}
+
+	progress, err = s.ProgressRepository.RecordLessonProgress(lesson, studentID, requestFormat)
+	if err != nil || !progress.IsCompleted() {
+		return
+	}
+
+	// the progress update stands even when the Certificate can't be issued;
+	// the student can still claim it later
+	_, err = issueCertificate(
+		s.AttendanceRepository,
+		s.CertificateRepository,
+		s.CourseRepository,
+		s.EnrollmentRepository,
+		s.LiveSessionRepository,
+		s.ProgressRepository,
+		s.Config.Certificate.SigningKey,
+		lesson.CourseID,
+		studentID,
+		studentName)
+	if err != nil && failure.GetCode(err) != http.StatusConflict {
+		logger.ErrorWithStack(err)
+	}
+
+	return progress, nil
+}
+
+// ResolveCourseProgress resolves a student's progress through a Course, lesson
+// by lesson, along with their attendance when the Course requires it.
+func (s *ProgressServiceImpl) ResolveCourseProgress(courseID uuid.UUID, studentID uuid.UUID) (progress CourseProgress, err error) {
+	err = checkReadAccess(s.EnrollmentRepository, courseID, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	progress, lessons, err := resolveCourseCompletion(
+		s.AttendanceRepository,
+		s.EnrollmentRepository,
+		s.LiveSessionRepository,
+		s.ProgressRepository,
+		courseID,
+		studentID)
+	if err != nil {
+		return
+	}
+
+	progress.Lessons = make([]LessonProgressResponseFormat, 0, len(lessons))
+	for _, lesson := range lessons {
+		progress.Lessons = append(progress.Lessons, lesson.ToResponseFormat())
+	}
+
+	return
+}
+
+// ResolveCourseRoster resolves the completion of every actively enrolled
+// student of a Course, for those allowed to view them.
+func (s *ProgressServiceImpl) ResolveCourseRoster(courseID uuid.UUID, userID uuid.UUID) (roster []CourseProgress, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionViewStudents)
+	if err != nil {
+		return
+	}
+
+	enrollments, err := s.EnrollmentRepository.ResolveEnrollments(EnrollmentQueryParameters{
+		CourseID: course.ID,
+		Status:   EnrollmentStatusActive,
+	})
+	if err != nil {
+		return
+	}
+
+	total, err := s.ProgressRepository.CountActiveLessons(course.ID)
+	if err != nil {
+		return
+	}
+
+	completions, err := s.ProgressRepository.ResolveStudentCompletions(course.ID)
+	if err != nil {
+		return
+	}
+
+	completionByStudent := make(map[uuid.UUID]StudentCompletion, len(completions))
+	for _, completion := range completions {
+		completionByStudent[completion.StudentID] = completion
+	}
+
+	roster = make([]CourseProgress, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		completion, ok := completionByStudent[enrollment.StudentID]
+		if !ok {
+			completion = StudentCompletion{StudentID: enrollment.StudentID}
+		}
+
+		roster = append(roster, NewCourseProgress(course.ID, completion, total))
+	}
+
+	return
+}
+
+// resolveStudentProgress resolves a student's progress through a Course
+// together with the LessonProgress it was computed from.
+func resolveStudentProgress(progressRepository ProgressRepository, courseID uuid.UUID, studentID uuid.UUID) (progress CourseProgress, lessons []LessonProgress, err error) {
+	total, err := progressRepository.CountActiveLessons(courseID)
+	if err != nil {
+		return
+	}
+
+	lessons, err = progressRepository.ResolveLessonProgress(courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	completion := StudentCompletion{StudentID: studentID}
+	for _, lesson := range lessons {
+		if lesson.IsCompleted() {
+			completion.CompletedLessons++
+		}
+
+		activity := lesson.UpdatedAt.ValueOrZero()
+		if activity.After(completion.LastActivityAt.ValueOrZero()) {
+			completion.LastActivityAt.SetValid(activity)
+		}
+	}
+
+	return NewCourseProgress(courseID, completion, total), lessons, nil
+}
+
+// resolveCourseCompletion resolves a student's progress through a Course like
+// resolveStudentProgress, adding their attendance when the CompletionRules of
+// the Course require it.
+func resolveCourseCompletion(
+	attendanceRepository AttendanceRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	progressRepository ProgressRepository,
+	courseID uuid.UUID,
+	studentID uuid.UUID) (progress CourseProgress, lessons []LessonProgress, err error) {
+	progress, lessons, err = resolveStudentProgress(progressRepository, courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	rules, err := attendanceRepository.ResolveCompletionRules(courseID)
+	if err != nil {
+		if failure.GetCode(err) == http.StatusNotFound {
+			err = nil
+		}
+
+		return
+	}
+
+	if !rules.RequiresAttendance() {
+		return
+	}
+
+	// students who never enrolled had no occurrences to attend
+	enrollment, err := enrollmentRepository.ResolveEnrollmentByCourseIDAndStudentID(courseID, studentID)
+	if err != nil {
+		if failure.GetCode(err) == http.StatusNotFound {
+			err = nil
+		}
+
+		return
+	}
+
+	attendance, err := resolveStudentAttendance(attendanceRepository, liveSessionRepository, enrollment, time.Now())
+	if err != nil {
+		return
+	}
+
+	progress.ApplyAttendance(attendance.Summary, rules)
+	return
+}
@@ -0,0 +1,380 @@
+package course
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// ChangeKind indicates how an entity differs between two CourseSnapshots.
+type ChangeKind string
+
+const (
+	// ChangeKindAdded indicates an entity that only exists in the newer snapshot.
+	ChangeKindAdded ChangeKind = "added"
+	// ChangeKindRemoved indicates an entity that only exists in the older snapshot.
+	ChangeKindRemoved ChangeKind = "removed"
+	// ChangeKindModified indicates an entity whose fields changed.
+	ChangeKindModified ChangeKind = "modified"
+)
+
+//// Course Version
+
+// CourseVersion is an immutable snapshot of a Course, its Modules and their
+// Lessons taken when the Course was published.
+type CourseVersion struct {
+	ID           uuid.UUID       `db:"id" validate:"required"`
+	CourseID     uuid.UUID       `db:"course_id" validate:"required"`
+	Version      int             `db:"version" validate:"min=1"`
+	Title        string          `db:"title" validate:"required"`
+	Snapshot     *CourseSnapshot `db:"snapshot" validate:"required"`
+	RestoredFrom null.Int        `db:"restored_from"`
+	PublishedAt  time.Time       `db:"published_at" validate:"required"`
+	PublishedBy  uuid.UUID       `db:"published_by" validate:"required"`
+}
+
+// ApplyTo returns a Course as it was published in this CourseVersion.
+// Operational settings such as the seat limit are taken from the Course itself.
+func (v CourseVersion) ApplyTo(course Course) Course {
+	course.Title = v.Snapshot.Title
+	course.Content = v.Snapshot.Content
+	course.Modules = make([]Module, 0, len(v.Snapshot.Modules))
+
+	for _, moduleSnapshot := range v.Snapshot.Modules {
+		module := moduleSnapshot.toModule(course.ID)
+		for _, lessonSnapshot := range moduleSnapshot.Lessons {
+			module.Lessons = append(module.Lessons, lessonSnapshot.toLesson(module))
+		}
+
+		course.Modules = append(course.Modules, module)
+	}
+
+	return course
+}
+
+// LessonByID finds a Lesson as it was published in this CourseVersion.
+func (v CourseVersion) LessonByID(courseID uuid.UUID, id uuid.UUID) (lesson Lesson, ok bool) {
+	for _, moduleSnapshot := range v.Snapshot.Modules {
+		for _, lessonSnapshot := range moduleSnapshot.Lessons {
+			if lessonSnapshot.ID == id {
+				return lessonSnapshot.toLesson(moduleSnapshot.toModule(courseID)), true
+			}
+		}
+	}
+
+	return lesson, false
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (v CourseVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.ToResponseFormat())
+}
+
+// NewCourseVersion takes a snapshot of a Course with its Modules and Lessons.
+func (v CourseVersion) NewCourseVersion(course Course, version int, userID uuid.UUID) (newVersion CourseVersion, err error) {
+	snapshot := CourseSnapshot{}.NewCourseSnapshot(course)
+	return v.newCourseVersion(course.ID, version, &snapshot, null.Int{}, userID)
+}
+
+// Restore creates a new CourseVersion with the same content as this one.
+func (v CourseVersion) Restore(version int, userID uuid.UUID) (newVersion CourseVersion, err error) {
+	return v.newCourseVersion(v.CourseID, version, v.Snapshot, null.IntFrom(int64(v.Version)), userID)
+}
+
+// ToResponseFormat converts this CourseVersion to its response format. The
+// snapshot is left out when it was not loaded.
+func (v CourseVersion) ToResponseFormat() CourseVersionResponseFormat {
+	return CourseVersionResponseFormat{
+		ID:           v.ID,
+		CourseID:     v.CourseID,
+		Version:      v.Version,
+		Title:        v.Title,
+		RestoredFrom: v.RestoredFrom,
+		PublishedAt:  v.PublishedAt,
+		PublishedBy:  v.PublishedBy,
+		Snapshot:     v.Snapshot,
+	}
+}
+
+// Validate validates the entity.
+func (v *CourseVersion) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(v)
+}
+
+func (v CourseVersion) newCourseVersion(courseID uuid.UUID, version int, snapshot *CourseSnapshot, restoredFrom null.Int, userID uuid.UUID) (newVersion CourseVersion, err error) {
+	versionID, _ := uuid.NewV4()
+	newVersion = CourseVersion{
+		ID:           versionID,
+		CourseID:     courseID,
+		Version:      version,
+		Title:        snapshot.Title,
+		Snapshot:     snapshot,
+		RestoredFrom: restoredFrom,
+		PublishedAt:  time.Now(),
+		PublishedBy:  userID,
+	}
+
+	err = newVersion.Validate()
+
+	return
+}
+
+// CourseVersionResponseFormat represents a CourseVersion's standard formatting for JSON serializing.
+type CourseVersionResponseFormat struct {
+	ID           uuid.UUID       `json:"id"`
+	CourseID     uuid.UUID       `json:"courseID"`
+	Version      int             `json:"version"`
+	Title        string          `json:"title"`
+	RestoredFrom null.Int        `json:"restoredFrom" swaggertype:"integer"`
+	PublishedAt  time.Time       `json:"publishedAt"`
+	PublishedBy  uuid.UUID       `json:"publishedBy"`
+	Snapshot     *CourseSnapshot `json:"snapshot,omitempty"`
+}
+
+//// Course Snapshot
+
+// CourseSnapshot is the published content of a Course, stored as JSON.
+type CourseSnapshot struct {
+	Title   string           `json:"title"`
+	Content string           `json:"content"`
+	Modules []ModuleSnapshot `json:"modules"`
+}
+
+// NewCourseSnapshot captures the content of a Course with its Modules and Lessons.
+func (s CourseSnapshot) NewCourseSnapshot(course Course) (snapshot CourseSnapshot) {
+	snapshot = CourseSnapshot{
+		Title:   course.Title,
+		Content: course.Content,
+		Modules: make([]ModuleSnapshot, 0, len(course.Modules)),
+	}
+
+	for _, module := range course.Modules {
+		snapshot.Modules = append(snapshot.Modules, newModuleSnapshot(module))
+	}
+
+	return
+}
+
+// Diff lists what changed from this CourseSnapshot to a newer one: the Course
+// first, then its Modules and Lessons in the newer order, then whatever was removed.
+func (s CourseSnapshot) Diff(newer CourseSnapshot) (changes []VersionChange) {
+	changes = make([]VersionChange, 0)
+
+	fields := make([]FieldChange, 0)
+	fields = appendFieldChange(fields, "title", s.Title, newer.Title)
+	fields = appendFieldChange(fields, "content", s.Content, newer.Content)
+	if len(fields) > 0 {
+		changes = append(changes, VersionChange{Entity: "course", Title: newer.Title, Kind: ChangeKindModified, Fields: fields})
+	}
+
+	oldModules, oldLessons := s.index()
+	newModules, newLessons := newer.index()
+
+	for _, module := range newer.Modules {
+		old, ok := oldModules[module.ID]
+		if !ok {
+			changes = append(changes, VersionChange{Entity: "module", ID: module.ID, Title: module.Title, Kind: ChangeKindAdded})
+			continue
+		}
+
+		fields := make([]FieldChange, 0)
+		fields = appendFieldChange(fields, "title", old.Title, module.Title)
+		fields = appendFieldChange(fields, "position", strconv.Itoa(old.Position), strconv.Itoa(module.Position))
+		if len(fields) > 0 {
+			changes = append(changes, VersionChange{Entity: "module", ID: module.ID, Title: module.Title, Kind: ChangeKindModified, Fields: fields})
+		}
+	}
+
+	for _, module := range newer.Modules {
+		for _, lesson := range module.Lessons {
+			old, ok := oldLessons[lesson.ID]
+			if !ok {
+				changes = append(changes, VersionChange{Entity: "lesson", ID: lesson.ID, Title: lesson.Title, Kind: ChangeKindAdded})
+				continue
+			}
+
+			fields := make([]FieldChange, 0)
+			fields = appendFieldChange(fields, "module", old.moduleID.String(), module.ID.String())
+			fields = appendFieldChange(fields, "title", old.Title, lesson.Title)
+			fields = appendFieldChange(fields, "content", old.Content, lesson.Content)
+			fields = appendFieldChange(fields, "position", strconv.Itoa(old.Position), strconv.Itoa(lesson.Position))
+			if len(fields) > 0 {
+				changes = append(changes, VersionChange{Entity: "lesson", ID: lesson.ID, Title: lesson.Title, Kind: ChangeKindModified, Fields: fields})
+			}
+		}
+	}
+
+	for _, module := range s.Modules {
+		if _, ok := newModules[module.ID]; !ok {
+			changes = append(changes, VersionChange{Entity: "module", ID: module.ID, Title: module.Title, Kind: ChangeKindRemoved})
+		}
+
+		for _, lesson := range module.Lessons {
+			if _, ok := newLessons[lesson.ID]; !ok {
+				changes = append(changes, VersionChange{Entity: "lesson", ID: lesson.ID, Title: lesson.Title, Kind: ChangeKindRemoved})
+			}
+		}
+	}
+
+	return
+}
+
+// Scan implements the Scanner interface.
+func (s *CourseSnapshot) Scan(value interface{}) error {
+	switch x := value.(type) {
+	case []byte:
+		return json.Unmarshal(x, s)
+	case string:
+		return json.Unmarshal([]byte(x), s)
+	default:
+		return fmt.Errorf("cannot scan type %T into course.CourseSnapshot: %v", value, value)
+	}
+}
+
+// Value implements the driver Valuer interface.
+func (s CourseSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// indexedLesson is a LessonSnapshot together with the Module it belongs to.
+type indexedLesson struct {
+	LessonSnapshot
+	moduleID uuid.UUID
+}
+
+// index maps the Modules and Lessons of this CourseSnapshot by their IDs.
+func (s CourseSnapshot) index() (modules map[uuid.UUID]ModuleSnapshot, lessons map[uuid.UUID]indexedLesson) {
+	modules = make(map[uuid.UUID]ModuleSnapshot)
+	lessons = make(map[uuid.UUID]indexedLesson)
+
+	for _, module := range s.Modules {
+		modules[module.ID] = module
+		for _, lesson := range module.Lessons {
+			lessons[lesson.ID] = indexedLesson{LessonSnapshot: lesson, moduleID: module.ID}
+		}
+	}
+
+	return
+}
+
+// ModuleSnapshot is a Module as it was published.
+type ModuleSnapshot struct {
+	ID        uuid.UUID        `json:"id"`
+	Title     string           `json:"title"`
+	Position  int              `json:"position"`
+	CreatedAt time.Time        `json:"createdAt"`
+	CreatedBy uuid.UUID        `json:"createdBy"`
+	UpdatedAt null.Time        `json:"updatedAt"`
+	UpdatedBy nuuid.NUUID      `json:"updatedBy" swaggertype:"string"`
+	Lessons   []LessonSnapshot `json:"lessons"`
+}
+
+func newModuleSnapshot(module Module) ModuleSnapshot {
+	snapshot := ModuleSnapshot{
+		ID:        module.ID,
+		Title:     module.Title,
+		Position:  module.Position,
+		CreatedAt: module.CreatedAt,
+		CreatedBy: module.CreatedBy,
+		UpdatedAt: module.UpdatedAt,
+		UpdatedBy: module.UpdatedBy,
+		Lessons:   make([]LessonSnapshot, 0, len(module.Lessons)),
+	}
+
+	for _, lesson := range module.Lessons {
+		snapshot.Lessons = append(snapshot.Lessons, LessonSnapshot{
+			ID:        lesson.ID,
+			Title:     lesson.Title,
+			Content:   lesson.Content,
+			Position:  lesson.Position,
+			CreatedAt: lesson.CreatedAt,
+			CreatedBy: lesson.CreatedBy,
+			UpdatedAt: lesson.UpdatedAt,
+			UpdatedBy: lesson.UpdatedBy,
+		})
+	}
+
+	return snapshot
+}
+
+func (ms ModuleSnapshot) toModule(courseID uuid.UUID) Module {
+	return Module{
+		ID:        ms.ID,
+		CourseID:  courseID,
+		Title:     ms.Title,
+		Position:  ms.Position,
+		CreatedAt: ms.CreatedAt,
+		CreatedBy: ms.CreatedBy,
+		UpdatedAt: ms.UpdatedAt,
+		UpdatedBy: ms.UpdatedBy,
+	}
+}
+
+// LessonSnapshot is a Lesson as it was published.
+type LessonSnapshot struct {
+	ID        uuid.UUID   `json:"id"`
+	Title     string      `json:"title"`
+	Content   string      `json:"content"`
+	Position  int         `json:"position"`
+	CreatedAt time.Time   `json:"createdAt"`
+	CreatedBy uuid.UUID   `json:"createdBy"`
+	UpdatedAt null.Time   `json:"updatedAt"`
+	UpdatedBy nuuid.NUUID `json:"updatedBy" swaggertype:"string"`
+}
+
+func (ls LessonSnapshot) toLesson(module Module) Lesson {
+	return Lesson{
+		ID:        ls.ID,
+		ModuleID:  module.ID,
+		CourseID:  module.CourseID,
+		Title:     ls.Title,
+		Content:   ls.Content,
+		Position:  ls.Position,
+		CreatedAt: ls.CreatedAt,
+		CreatedBy: ls.CreatedBy,
+		UpdatedAt: ls.UpdatedAt,
+		UpdatedBy: ls.UpdatedBy,
+	}
+}
+
+//// Version Diff
+
+// VersionDiff lists the changes between two CourseVersions, or between a
+// CourseVersion and the current draft.
+type VersionDiff struct {
+	CourseID uuid.UUID       `json:"courseID"`
+	From     int             `json:"from"`
+	To       null.Int        `json:"to" swaggertype:"integer"`
+	Changes  []VersionChange `json:"changes"`
+}
+
+// VersionChange is a Course, Module or Lesson that differs between two CourseSnapshots.
+type VersionChange struct {
+	Entity string        `json:"entity"`
+	ID     uuid.UUID     `json:"id"`
+	Title  string        `json:"title"`
+	Kind   ChangeKind    `json:"kind"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a field of a modified entity with its old and new value.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+func appendFieldChange(fields []FieldChange, field string, before string, after string) []FieldChange {
+	if before == after {
+		return fields
+	}
+	return append(fields, FieldChange{Field: field, Before: before, After: after})
+}
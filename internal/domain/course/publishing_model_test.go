@@ -0,0 +1,152 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCourseUpdateStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      course.CourseStatus
+		to        course.CourseStatus
+		wantError bool
+	}{
+		{name: "draft to in review", from: course.CourseStatusDraft, to: course.CourseStatusInReview},
+		{name: "draft to published", from: course.CourseStatusDraft, to: course.CourseStatusPublished, wantError: true},
+		{name: "in review to draft", from: course.CourseStatusInReview, to: course.CourseStatusDraft},
+		{name: "in review to published", from: course.CourseStatusInReview, to: course.CourseStatusPublished},
+		{name: "published to in review", from: course.CourseStatusPublished, to: course.CourseStatusInReview},
+		{name: "published to archived", from: course.CourseStatusPublished, to: course.CourseStatusArchived},
+		{name: "published to draft", from: course.CourseStatusPublished, to: course.CourseStatusDraft, wantError: true},
+		{name: "archived to draft", from: course.CourseStatusArchived, to: course.CourseStatusDraft},
+		{name: "archived to published", from: course.CourseStatusArchived, to: course.CourseStatusPublished, wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := course.Course{Status: test.from}
+
+			err := c.UpdateStatus(test.to, getRandomUUID())
+
+			if test.wantError {
+				assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+				assert.Equal(t, test.from, c.Status)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.to, c.Status)
+		})
+	}
+}
+
+func TestCourseVersion(t *testing.T) {
+	draft := newDraftCourse()
+	userID := getRandomUUID()
+
+	t.Run("publishing keeps the version unchanged by later edits", func(t *testing.T) {
+		c := newDraftCourse()
+		assert.NoError(t, c.UpdateStatus(course.CourseStatusInReview, userID))
+		version, err := course.CourseVersion{}.NewCourseVersion(c, 1, userID)
+		assert.NoError(t, err)
+		assert.NoError(t, c.Publish(version, userID))
+
+		c.Title = "Edited after publishing"
+		c.Modules[0].Lessons[0].Content = "Edited after publishing"
+		published := version.ApplyTo(c)
+
+		assert.True(t, c.IsVisibleToStudents())
+		assert.Equal(t, int64(1), c.PublishedVersion.Int64)
+		assert.Equal(t, "Go basics", published.Title)
+		assert.Equal(t, "Variables and types", published.Modules[0].Lessons[0].Content)
+	})
+
+	t.Run("restoring copies the content into a new version", func(t *testing.T) {
+		version, _ := course.CourseVersion{}.NewCourseVersion(draft, 1, userID)
+
+		restored, err := version.Restore(3, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, restored.Version)
+		assert.Equal(t, int64(1), restored.RestoredFrom.Int64)
+		assert.Equal(t, version.Snapshot, restored.Snapshot)
+		assert.NotEqual(t, version.ID, restored.ID)
+	})
+
+	t.Run("finds published lessons", func(t *testing.T) {
+		version, _ := course.CourseVersion{}.NewCourseVersion(draft, 1, userID)
+		want := draft.Modules[0].Lessons[1]
+
+		got, ok := version.LessonByID(draft.ID, want.ID)
+		_, missing := version.LessonByID(draft.ID, getRandomUUID())
+
+		assert.True(t, ok)
+		assert.Equal(t, want.Title, got.Title)
+		assert.Equal(t, want.ModuleID, got.ModuleID)
+		assert.False(t, missing)
+	})
+
+	t.Run("only courses visible to students can be rolled back", func(t *testing.T) {
+		c := newDraftCourse()
+		version, _ := course.CourseVersion{}.NewCourseVersion(c, 1, userID)
+
+		err := c.RollBack(version, userID)
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
+
+func TestCourseSnapshotDiff(t *testing.T) {
+	draft := newDraftCourse()
+	older := course.CourseSnapshot{}.NewCourseSnapshot(draft)
+
+	first, second := draft.Modules[0].Lessons[0], draft.Modules[0].Lessons[1]
+	draft.Title = "Go fundamentals"
+	draft.Modules[0].Lessons = []course.Lesson{first}
+	draft.Modules[0].Lessons[0].Content = "Variables, constants and types"
+	added := course.Module{ID: getRandomUUID(), CourseID: draft.ID, Title: "Concurrency", Position: 2, CreatedAt: time.Now()}
+	draft.Modules = append(draft.Modules, added)
+
+	changes := older.Diff(course.CourseSnapshot{}.NewCourseSnapshot(draft))
+
+	assert.Equal(t, []course.VersionChange{
+		{Entity: "course", Title: "Go fundamentals", Kind: course.ChangeKindModified, Fields: []course.FieldChange{
+			{Field: "title", Before: "Go basics", After: "Go fundamentals"},
+		}},
+		{Entity: "module", ID: added.ID, Title: "Concurrency", Kind: course.ChangeKindAdded},
+		{Entity: "lesson", ID: first.ID, Title: first.Title, Kind: course.ChangeKindModified, Fields: []course.FieldChange{
+			{Field: "content", Before: "Variables and types", After: "Variables, constants and types"},
+		}},
+		{Entity: "lesson", ID: second.ID, Title: second.Title, Kind: course.ChangeKindRemoved},
+	}, changes)
+	assert.Empty(t, older.Diff(older))
+}
+
+// newDraftCourse creates a draft Course with one Module of two Lessons.
+func newDraftCourse() course.Course {
+	ownerID := getRandomUUID()
+	c := course.Course{
+		ID:        getRandomUUID(),
+		UserID:    ownerID,
+		Title:     "Go basics",
+		Content:   "Learn Go from scratch",
+		Status:    course.CourseStatusDraft,
+		CreatedAt: time.Now(),
+		CreatedBy: ownerID,
+	}
+
+	module := course.Module{ID: getRandomUUID(), CourseID: c.ID, Title: "Syntax", Position: 1, CreatedAt: time.Now(), CreatedBy: ownerID}
+	module.Lessons = []course.Lesson{
+		{ID: getRandomUUID(), ModuleID: module.ID, CourseID: c.ID, Title: "Variables", Content: "Variables and types", Position: 1, CreatedAt: time.Now(), CreatedBy: ownerID},
+		{ID: getRandomUUID(), ModuleID: module.ID, CourseID: c.ID, Title: "Functions", Content: "Declaring functions", Position: 2, CreatedAt: time.Now(), CreatedBy: ownerID},
+	}
+	c.Modules = []course.Module{module}
+
+	return c
+}
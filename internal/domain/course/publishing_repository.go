@@ -0,0 +1,187 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source publishing_repository.go -destination mock/publishing_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	publishingQueries = struct {
+		selectVersion           string
+		selectVersionSummary    string
+		selectNextVersionNumber string
+		insertVersion           string
+		updateCoursePublication string
+	}{
+		selectVersion: `
+			SELECT
+				id,
+				course_id,
+				version,
+				title,
+				snapshot,
+				restored_from,
+				published_at,
+				published_by
+			FROM course_versions
+		`,
+
+		selectVersionSummary: `
+			SELECT
+				id,
+				course_id,
+				version,
+				title,
+				restored_from,
+				published_at,
+				published_by
+			FROM course_versions
+		`,
+
+		selectNextVersionNumber: `
+			SELECT COALESCE(MAX(version), 0) + 1
+			FROM course_versions
+			WHERE course_id = ?
+		`,
+
+		insertVersion: `
+			INSERT INTO course_versions (
+				id,
+				course_id,
+				version,
+				title,
+				snapshot,
+				restored_from,
+				published_at,
+				published_by
+			) VALUES (
+				:id,
+				:course_id,
+				:version,
+				:title,
+				:snapshot,
+				:restored_from,
+				:published_at,
+				:published_by
+			)
+		`,
+
+		updateCoursePublication: `
+			UPDATE courses
+			SET
+				status = :status,
+				published_version = :published_version,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+	}
+)
+
+// PublishingRepository is the repository for published CourseVersions. Versions
+// are never updated once created.
+type PublishingRepository interface {
+	NextVersionNumber(courseID uuid.UUID) (version int, err error)
+	PublishVersion(course Course, version CourseVersion) (err error)
+	ResolveVersion(courseID uuid.UUID, version int) (courseVersion CourseVersion, err error)
+	ResolveVersionsByCourseID(courseID uuid.UUID) (versions []CourseVersion, err error)
+}
+
+// PublishingRepositoryMySQL is the MySQL-backed implementation of PublishingRepository.
+type PublishingRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvidePublishingRepositoryMySQL is the provider for this repository.
+func ProvidePublishingRepositoryMySQL(db *infras.MySQLConn) *PublishingRepositoryMySQL {
+	s := new(PublishingRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// NextVersionNumber returns the number of the next CourseVersion of a Course.
+func (r *PublishingRepositoryMySQL) NextVersionNumber(courseID uuid.UUID) (version int, err error) {
+	err = r.DB.Read.Get(&version, publishingQueries.selectNextVersionNumber, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// PublishVersion creates a CourseVersion and stores the Course's publishing
+// status in one transaction.
+func (r *PublishingRepositoryMySQL) PublishVersion(course Course, version CourseVersion) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, publishingQueries.insertVersion, version); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, publishingQueries.updateCoursePublication, course); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveVersion resolves a CourseVersion with its snapshot.
+func (r *PublishingRepositoryMySQL) ResolveVersion(courseID uuid.UUID, version int) (courseVersion CourseVersion, err error) {
+	err = r.DB.Read.Get(
+		&courseVersion,
+		publishingQueries.selectVersion+" WHERE course_id = ? AND version = ?",
+		courseID.String(),
+		version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("course version")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveVersionsByCourseID resolves the CourseVersions of a Course without
+// their snapshots, newest first.
+func (r *PublishingRepositoryMySQL) ResolveVersionsByCourseID(courseID uuid.UUID) (versions []CourseVersion, err error) {
+	err = r.DB.Read.Select(
+		&versions,
+		publishingQueries.selectVersionSummary+" WHERE course_id = ? ORDER BY version DESC",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *PublishingRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
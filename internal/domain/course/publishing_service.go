@@ -0,0 +1,203 @@
+package course
+
+import (
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// PublishingService is the service interface for the publishing lifecycle of
+// Courses and their CourseVersions.
+type PublishingService interface {
+	DiffVersions(courseID uuid.UUID, from int, to null.Int, userID uuid.UUID) (diff VersionDiff, err error)
+	ResolveVersion(courseID uuid.UUID, version int, userID uuid.UUID) (courseVersion CourseVersion, err error)
+	ResolveVersions(courseID uuid.UUID, userID uuid.UUID) (versions []CourseVersion, err error)
+	RollBack(courseID uuid.UUID, version int, userID uuid.UUID) (courseVersion CourseVersion, err error)
+	UpdateCourseStatus(courseID uuid.UUID, requestFormat CourseStatusRequestFormat, userID uuid.UUID) (course Course, err error)
+}
+
+// PublishingServiceImpl is the service implementation for the publishing
+// lifecycle of Courses and their CourseVersions.
+type PublishingServiceImpl struct {
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	ModuleRepository       ModuleRepository
+	PublishingRepository   PublishingRepository
+	Config                 *configs.Config
+}
+
+// ProvidePublishingServiceImpl is the provider for this service.
+func ProvidePublishingServiceImpl(
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	moduleRepository ModuleRepository,
+	publishingRepository PublishingRepository,
+	config *configs.Config) *PublishingServiceImpl {
+	s := new(PublishingServiceImpl)
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.ModuleRepository = moduleRepository
+	s.PublishingRepository = publishingRepository
+	s.Config = config
+
+	return s
+}
+
+// DiffVersions lists the changes from one CourseVersion to another, or to the
+// current draft when no newer version is given.
+func (s *PublishingServiceImpl) DiffVersions(courseID uuid.UUID, from int, to null.Int, userID uuid.UUID) (diff VersionDiff, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPublishCourse)
+	if err != nil {
+		return
+	}
+
+	older, err := s.PublishingRepository.ResolveVersion(course.ID, from)
+	if err != nil {
+		return
+	}
+
+	newer, err := s.resolveSnapshot(course, to)
+	if err != nil {
+		return
+	}
+
+	diff = VersionDiff{
+		CourseID: course.ID,
+		From:     older.Version,
+		To:       to,
+		Changes:  older.Snapshot.Diff(newer),
+	}
+
+	return
+}
+
+// ResolveVersion resolves a CourseVersion with its snapshot.
+func (s *PublishingServiceImpl) ResolveVersion(courseID uuid.UUID, version int, userID uuid.UUID) (courseVersion CourseVersion, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPublishCourse)
+	if err != nil {
+		return
+	}
+
+	return s.PublishingRepository.ResolveVersion(course.ID, version)
+}
+
+// ResolveVersions lists the CourseVersions of a Course, newest first.
+func (s *PublishingServiceImpl) ResolveVersions(courseID uuid.UUID, userID uuid.UUID) (versions []CourseVersion, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPublishCourse)
+	if err != nil {
+		return
+	}
+
+	return s.PublishingRepository.ResolveVersionsByCourseID(course.ID)
+}
+
+// RollBack publishes the content of an earlier CourseVersion again, as a new
+// version. The draft is left as it is.
+func (s *PublishingServiceImpl) RollBack(courseID uuid.UUID, version int, userID uuid.UUID) (courseVersion CourseVersion, err error) {
+	course, err := resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPublishCourse)
+	if err != nil {
+		return
+	}
+
+	previous, err := s.PublishingRepository.ResolveVersion(course.ID, version)
+	if err != nil {
+		return
+	}
+
+	number, err := s.PublishingRepository.NextVersionNumber(course.ID)
+	if err != nil {
+		return
+	}
+
+	courseVersion, err = previous.Restore(number, userID)
+	if err != nil {
+		return courseVersion, failure.BadRequest(err)
+	}
+
+	err = course.RollBack(courseVersion, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.PublishingRepository.PublishVersion(course, courseVersion)
+	return
+}
+
+// UpdateCourseStatus moves a Course through its publishing lifecycle.
+// Publishing takes an immutable snapshot of the current draft, which students
+// see until the next publish.
+func (s *PublishingServiceImpl) UpdateCourseStatus(courseID uuid.UUID, requestFormat CourseStatusRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionPublishCourse)
+	if err != nil {
+		return
+	}
+
+	if requestFormat.Status != CourseStatusPublished {
+		err = course.UpdateStatus(requestFormat.Status, userID)
+		if err != nil {
+			return
+		}
+
+		err = s.CourseRepository.UpdateCourse(course)
+		return
+	}
+
+	number, err := s.PublishingRepository.NextVersionNumber(course.ID)
+	if err != nil {
+		return
+	}
+
+	draft, err := attachCourseModules(s.ModuleRepository, course)
+	if err != nil {
+		return
+	}
+
+	version, err := CourseVersion{}.NewCourseVersion(draft, number, userID)
+	if err != nil {
+		return course, failure.BadRequest(err)
+	}
+
+	err = course.Publish(version, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.PublishingRepository.PublishVersion(course, version)
+	return
+}
+
+// resolveSnapshot resolves the content of a CourseVersion, or captures the
+// current draft when no version is given.
+func (s *PublishingServiceImpl) resolveSnapshot(course Course, version null.Int) (snapshot CourseSnapshot, err error) {
+	if version.Valid {
+		courseVersion, err := s.PublishingRepository.ResolveVersion(course.ID, int(version.Int64))
+		if err != nil {
+			return snapshot, err
+		}
+
+		return *courseVersion.Snapshot, nil
+	}
+
+	draft, err := attachCourseModules(s.ModuleRepository, course)
+	if err != nil {
+		return
+	}
+
+	return snapshot.NewCourseSnapshot(draft), nil
+}
+
+// resolvePublishedCourse returns a Course as students see it: its latest
+// published CourseVersion. Courses that are not visible to students are hidden.
+func resolvePublishedCourse(publishingRepository PublishingRepository, course Course) (published Course, err error) {
+	if !course.IsVisibleToStudents() {
+		return course, failure.NotFound("course")
+	}
+
+	version, err := publishingRepository.ResolveVersion(course.ID, int(course.PublishedVersion.Int64))
+	if err != nil {
+		return
+	}
+
+	return version.ApplyTo(course), nil
+}
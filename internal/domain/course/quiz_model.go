@@ -0,0 +1,852 @@
+package course
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// QuestionType indicates how a QuizQuestion is answered and scored.
+type QuestionType string
+
+const (
+	// QuestionTypeSingleChoice is answered by selecting the one correct option.
+	QuestionTypeSingleChoice QuestionType = "single_choice"
+	// QuestionTypeMultipleChoice is answered by selecting every correct option,
+	// and nothing else.
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+	// QuestionTypeTrueFalse is answered by selecting either the true or the false option.
+	QuestionTypeTrueFalse QuestionType = "true_false"
+	// QuestionTypeShortAnswer is answered with a text that must match the
+	// expected answer exactly, ignoring surrounding whitespace.
+	QuestionTypeShortAnswer QuestionType = "short_answer"
+)
+
+// QuizAttemptStatus indicates the status of a QuizAttempt.
+type QuizAttemptStatus string
+
+const (
+	// QuizAttemptStatusInProgress indicates an attempt the student is working on.
+	QuizAttemptStatusInProgress QuizAttemptStatus = "in_progress"
+	// QuizAttemptStatusSubmitted indicates a submitted and scored attempt.
+	QuizAttemptStatusSubmitted QuizAttemptStatus = "submitted"
+	// QuizAttemptStatusExpired indicates a timed attempt that was not submitted in time.
+	QuizAttemptStatusExpired QuizAttemptStatus = "expired"
+)
+
+// quizSubmissionGracePeriod tolerates the latency of submissions sent right
+// before a timed attempt expires.
+const quizSubmissionGracePeriod = 30 * time.Second
+
+//// Quiz
+
+// Quiz is a graded checkpoint attached to a Lesson.
+type Quiz struct {
+	ID               uuid.UUID      `db:"id" validate:"required"`
+	CourseID         uuid.UUID      `db:"course_id" validate:"required"`
+	LessonID         uuid.UUID      `db:"lesson_id" validate:"required"`
+	Title            string         `db:"title" validate:"required"`
+	TimeLimitSeconds null.Int       `db:"time_limit_seconds"`
+	MaxAttempts      null.Int       `db:"max_attempts"`
+	ShuffleAnswers   bool           `db:"shuffle_answers"`
+	CreatedAt        time.Time      `db:"created_at" validate:"required"`
+	CreatedBy        uuid.UUID      `db:"created_by" validate:"required"`
+	UpdatedAt        null.Time      `db:"updated_at"`
+	UpdatedBy        nuuid.NUUID    `db:"updated_by"`
+	DeletedAt        null.Time      `db:"deleted_at"`
+	DeletedBy        nuuid.NUUID    `db:"deleted_by"`
+	Questions        []QuizQuestion `db:"-" validate:"dive"`
+}
+
+// AttachQuestions attaches QuizQuestions to this Quiz.
+func (q *Quiz) AttachQuestions(questions []QuizQuestion) Quiz {
+	for _, question := range questions {
+		if question.QuizID == q.ID {
+			q.Questions = append(q.Questions, question)
+		}
+	}
+	return *q
+}
+
+// HasAttemptLimit checks whether a Quiz caps the number of attempts per student.
+func (q *Quiz) HasAttemptLimit() bool {
+	return q.MaxAttempts.Valid
+}
+
+// HasTimeLimit checks whether attempts of a Quiz are timed.
+func (q *Quiz) HasTimeLimit() bool {
+	return q.TimeLimitSeconds.Valid
+}
+
+// IsDeleted checks whether a Quiz is marked as deleted.
+func (q *Quiz) IsDeleted() (deleted bool) {
+	return q.DeletedAt.Valid && q.DeletedBy.Valid
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (q Quiz) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.ToResponseFormat())
+}
+
+// MaxScore returns the sum of the points of every QuizQuestion.
+func (q *Quiz) MaxScore() (score int) {
+	for _, question := range q.Questions {
+		score += question.Points
+	}
+	return
+}
+
+// NewQuizFromRequestFormat creates a new Quiz attached to a Lesson.
+func (q Quiz) NewQuizFromRequestFormat(req QuizRequestFormat, lesson Lesson, userID uuid.UUID) (newQuiz Quiz, err error) {
+	quizID, _ := uuid.NewV4()
+	newQuiz = Quiz{
+		ID:               quizID,
+		CourseID:         lesson.CourseID,
+		LessonID:         lesson.ID,
+		Title:            req.Title,
+		TimeLimitSeconds: req.TimeLimitSeconds,
+		MaxAttempts:      req.MaxAttempts,
+		ShuffleAnswers:   req.ShuffleAnswers,
+		CreatedAt:        time.Now(),
+		CreatedBy:        userID,
+	}
+
+	newQuiz.Questions, err = newQuiz.questionsFromRequestFormat(req.Questions)
+	if err != nil {
+		return
+	}
+
+	err = newQuiz.Validate()
+
+	return
+}
+
+// SoftDelete marks a Quiz as deleted.
+func (q *Quiz) SoftDelete(userID uuid.UUID) (err error) {
+	if q.IsDeleted() {
+		return failure.Conflict("softDelete", "quiz", "already marked as deleted")
+	}
+
+	q.DeletedAt = null.TimeFrom(time.Now())
+	q.DeletedBy = nuuid.From(userID)
+
+	return
+}
+
+// ToResponseFormat converts this Quiz to its response format, answer key included.
+func (q Quiz) ToResponseFormat() QuizResponseFormat {
+	resp := QuizResponseFormat{
+		ID:               q.ID,
+		CourseID:         q.CourseID,
+		LessonID:         q.LessonID,
+		Title:            q.Title,
+		TimeLimitSeconds: q.TimeLimitSeconds,
+		MaxAttempts:      q.MaxAttempts,
+		ShuffleAnswers:   q.ShuffleAnswers,
+		CreatedAt:        q.CreatedAt,
+		CreatedBy:        q.CreatedBy,
+		UpdatedAt:        q.UpdatedAt,
+		UpdatedBy:        q.UpdatedBy.Ptr(),
+	}
+
+	for _, question := range q.Questions {
+		resp.Questions = append(resp.Questions, question.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// Update updates a Quiz, replacing all of its QuizQuestions.
+func (q *Quiz) Update(req QuizRequestFormat, userID uuid.UUID) (err error) {
+	questions, err := q.questionsFromRequestFormat(req.Questions)
+	if err != nil {
+		return
+	}
+
+	q.Title = req.Title
+	q.TimeLimitSeconds = req.TimeLimitSeconds
+	q.MaxAttempts = req.MaxAttempts
+	q.ShuffleAnswers = req.ShuffleAnswers
+	q.Questions = questions
+	q.UpdatedAt = null.TimeFrom(time.Now())
+	q.UpdatedBy = nuuid.From(userID)
+
+	err = q.Validate()
+
+	return
+}
+
+// Validate validates the entity.
+func (q *Quiz) Validate() (err error) {
+	if q.TimeLimitSeconds.Valid && q.TimeLimitSeconds.Int64 < 1 {
+		return errors.New("time limit must be at least 1 second")
+	}
+
+	if q.MaxAttempts.Valid && q.MaxAttempts.Int64 < 1 {
+		return errors.New("max attempts must be at least 1")
+	}
+
+	for _, question := range q.Questions {
+		err = question.validateAnswerKey()
+		if err != nil {
+			return
+		}
+	}
+
+	validator := shared.GetValidator()
+	return validator.Struct(q)
+}
+
+// questionsFromRequestFormat creates this Quiz's QuizQuestions in request order.
+func (q *Quiz) questionsFromRequestFormat(reqs []QuizQuestionRequestFormat) (questions []QuizQuestion, err error) {
+	for i, req := range reqs {
+		var question QuizQuestion
+		question, err = question.NewQuizQuestionFromRequestFormat(req, q.ID, i+1)
+		if err != nil {
+			return
+		}
+
+		questions = append(questions, question)
+	}
+
+	return
+}
+
+// QuizRequestFormat represents a Quiz's standard formatting for JSON deserializing.
+type QuizRequestFormat struct {
+	Title            string                      `json:"title" validate:"required"`
+	TimeLimitSeconds null.Int                    `json:"timeLimitSeconds" swaggertype:"integer"`
+	MaxAttempts      null.Int                    `json:"maxAttempts" swaggertype:"integer"`
+	ShuffleAnswers   bool                        `json:"shuffleAnswers"`
+	Questions        []QuizQuestionRequestFormat `json:"questions" validate:"required,min=1,dive"`
+}
+
+// QuizResponseFormat represents a Quiz's standard formatting for JSON serializing.
+type QuizResponseFormat struct {
+	ID               uuid.UUID                    `json:"id"`
+	CourseID         uuid.UUID                    `json:"courseID"`
+	LessonID         uuid.UUID                    `json:"lessonID"`
+	Title            string                       `json:"title"`
+	TimeLimitSeconds null.Int                     `json:"timeLimitSeconds" swaggertype:"integer"`
+	MaxAttempts      null.Int                     `json:"maxAttempts" swaggertype:"integer"`
+	ShuffleAnswers   bool                         `json:"shuffleAnswers"`
+	CreatedAt        time.Time                    `json:"createdAt"`
+	CreatedBy        uuid.UUID                    `json:"createdBy"`
+	UpdatedAt        null.Time                    `json:"updatedAt"`
+	UpdatedBy        *uuid.UUID                   `json:"updatedBy"`
+	Questions        []QuizQuestionResponseFormat `json:"questions,omitempty"`
+}
+
+//// Quiz Question
+
+// QuizQuestion is a single scored question of a Quiz.
+type QuizQuestion struct {
+	ID         uuid.UUID    `db:"id" validate:"required"`
+	QuizID     uuid.UUID    `db:"quiz_id" validate:"required"`
+	Type       QuestionType `db:"type" validate:"required,oneof=single_choice multiple_choice true_false short_answer"`
+	Prompt     string       `db:"prompt" validate:"required"`
+	AnswerText null.String  `db:"answer_text"`
+	Points     int          `db:"points" validate:"min=1"`
+	Position   int          `db:"position" validate:"min=1"`
+	Options    []QuizOption `db:"-" validate:"dive"`
+}
+
+// AttachOptions attaches QuizOptions to this QuizQuestion.
+func (qq *QuizQuestion) AttachOptions(options []QuizOption) QuizQuestion {
+	for _, option := range options {
+		if option.QuestionID == qq.ID {
+			qq.Options = append(qq.Options, option)
+		}
+	}
+	return *qq
+}
+
+// Grade checks whether an answer to this QuizQuestion is correct. Choice
+// questions are correct when exactly the correct options are selected.
+func (qq *QuizQuestion) Grade(answer QuizAnswerRequestFormat) (correct bool) {
+	if qq.Type == QuestionTypeShortAnswer {
+		return qq.AnswerText.Valid && strings.TrimSpace(answer.Text) == qq.AnswerText.String
+	}
+
+	selected := make(map[uuid.UUID]bool)
+	for _, optionID := range answer.OptionIDs {
+		selected[optionID] = true
+	}
+
+	correctOptions := 0
+	for _, option := range qq.Options {
+		if option.IsCorrect != selected[option.ID] {
+			return false
+		}
+
+		if option.IsCorrect {
+			correctOptions++
+		}
+	}
+
+	// rules out selected IDs that are not options of this question
+	return len(selected) == correctOptions
+}
+
+// NewQuizQuestionFromRequestFormat creates a new QuizQuestion placed at the given
+// position. True/false questions get their two options generated from the answer.
+func (qq QuizQuestion) NewQuizQuestionFromRequestFormat(req QuizQuestionRequestFormat, quizID uuid.UUID, position int) (newQuestion QuizQuestion, err error) {
+	questionID, _ := uuid.NewV4()
+	newQuestion = QuizQuestion{
+		ID:       questionID,
+		QuizID:   quizID,
+		Type:     req.Type,
+		Prompt:   req.Prompt,
+		Points:   req.Points,
+		Position: position,
+	}
+
+	if newQuestion.Points == 0 {
+		newQuestion.Points = 1
+	}
+
+	if !req.Type.HasOptions() || req.Type == QuestionTypeTrueFalse {
+		if len(req.Options) > 0 {
+			return newQuestion, fmt.Errorf("question %d takes an answer instead of options", position)
+		}
+	}
+
+	switch req.Type {
+	case QuestionTypeShortAnswer:
+		newQuestion.AnswerText = null.StringFrom(strings.TrimSpace(req.Answer))
+	case QuestionTypeTrueFalse:
+		answer := strings.ToLower(strings.TrimSpace(req.Answer))
+		if answer != "true" && answer != "false" {
+			return newQuestion, fmt.Errorf("question %d must be answered with true or false", position)
+		}
+
+		newQuestion.Options = []QuizOption{
+			newQuestion.newOption("True", answer == "true", 1),
+			newQuestion.newOption("False", answer == "false", 2),
+		}
+	default:
+		for i, option := range req.Options {
+			newQuestion.Options = append(newQuestion.Options, newQuestion.newOption(option.Text, option.Correct, i+1))
+		}
+	}
+
+	return
+}
+
+// ToAttemptFormat converts this QuizQuestion to the format shown to students
+// taking a Quiz, leaving out the answer key.
+func (qq QuizQuestion) ToAttemptFormat() QuizAttemptQuestionResponseFormat {
+	resp := QuizAttemptQuestionResponseFormat{
+		ID:      qq.ID,
+		Type:    qq.Type,
+		Prompt:  qq.Prompt,
+		Points:  qq.Points,
+		Options: make([]QuizAttemptOptionResponseFormat, 0),
+	}
+
+	for _, option := range qq.Options {
+		resp.Options = append(resp.Options, QuizAttemptOptionResponseFormat{
+			ID:   option.ID,
+			Text: option.Text,
+		})
+	}
+
+	return resp
+}
+
+// ToResponseFormat converts this QuizQuestion to its response format.
+func (qq QuizQuestion) ToResponseFormat() QuizQuestionResponseFormat {
+	resp := QuizQuestionResponseFormat{
+		ID:       qq.ID,
+		Type:     qq.Type,
+		Prompt:   qq.Prompt,
+		Answer:   qq.AnswerText,
+		Points:   qq.Points,
+		Position: qq.Position,
+		Options:  make([]QuizOptionResponseFormat, 0),
+	}
+
+	for _, option := range qq.Options {
+		resp.Options = append(resp.Options, QuizOptionResponseFormat{
+			ID:      option.ID,
+			Text:    option.Text,
+			Correct: option.IsCorrect,
+		})
+	}
+
+	return resp
+}
+
+// newOption creates a new QuizOption of this QuizQuestion.
+func (qq *QuizQuestion) newOption(text string, correct bool, position int) QuizOption {
+	optionID, _ := uuid.NewV4()
+	return QuizOption{
+		ID:         optionID,
+		QuestionID: qq.ID,
+		Text:       text,
+		IsCorrect:  correct,
+		Position:   position,
+	}
+}
+
+// validateAnswerKey checks that this QuizQuestion can be answered correctly.
+func (qq *QuizQuestion) validateAnswerKey() (err error) {
+	correctOptions := 0
+	for _, option := range qq.Options {
+		if option.IsCorrect {
+			correctOptions++
+		}
+	}
+
+	switch qq.Type {
+	case QuestionTypeSingleChoice, QuestionTypeTrueFalse:
+		if len(qq.Options) < 2 || correctOptions != 1 {
+			return fmt.Errorf("question %d needs at least two options and exactly one correct option", qq.Position)
+		}
+	case QuestionTypeMultipleChoice:
+		if len(qq.Options) < 2 || correctOptions < 1 {
+			return fmt.Errorf("question %d needs at least two options and at least one correct option", qq.Position)
+		}
+	case QuestionTypeShortAnswer:
+		if !qq.AnswerText.Valid || qq.AnswerText.String == "" {
+			return fmt.Errorf("question %d needs an answer", qq.Position)
+		}
+	}
+
+	return
+}
+
+// HasOptions checks whether questions of this type are answered by selecting options.
+func (t QuestionType) HasOptions() bool {
+	return t != QuestionTypeShortAnswer
+}
+
+// QuizQuestionRequestFormat represents a QuizQuestion's standard formatting for JSON
+// deserializing. Choice questions list their options; true/false questions are
+// answered with "true" or "false" and short-answer questions with the expected text.
+type QuizQuestionRequestFormat struct {
+	Type    QuestionType              `json:"type" validate:"required,oneof=single_choice multiple_choice true_false short_answer"`
+	Prompt  string                    `json:"prompt" validate:"required"`
+	Points  int                       `json:"points" validate:"omitempty,min=1"`
+	Options []QuizOptionRequestFormat `json:"options" validate:"dive"`
+	Answer  string                    `json:"answer"`
+}
+
+// QuizQuestionResponseFormat represents a QuizQuestion's standard formatting for JSON serializing.
+type QuizQuestionResponseFormat struct {
+	ID       uuid.UUID                  `json:"id"`
+	Type     QuestionType               `json:"type"`
+	Prompt   string                     `json:"prompt"`
+	Answer   null.String                `json:"answer" swaggertype:"string"`
+	Points   int                        `json:"points"`
+	Position int                        `json:"position"`
+	Options  []QuizOptionResponseFormat `json:"options"`
+}
+
+// QuizAttemptQuestionResponseFormat represents a QuizQuestion as shown to students taking a Quiz.
+type QuizAttemptQuestionResponseFormat struct {
+	ID      uuid.UUID                         `json:"id"`
+	Type    QuestionType                      `json:"type"`
+	Prompt  string                            `json:"prompt"`
+	Points  int                               `json:"points"`
+	Options []QuizAttemptOptionResponseFormat `json:"options"`
+}
+
+//// Quiz Option
+
+// QuizOption is a selectable answer of a choice QuizQuestion.
+type QuizOption struct {
+	ID         uuid.UUID `db:"id" validate:"required"`
+	QuestionID uuid.UUID `db:"question_id" validate:"required"`
+	Text       string    `db:"text" validate:"required"`
+	IsCorrect  bool      `db:"is_correct"`
+	Position   int       `db:"position" validate:"min=1"`
+}
+
+// QuizOptionRequestFormat represents a QuizOption's standard formatting for JSON deserializing.
+type QuizOptionRequestFormat struct {
+	Text    string `json:"text" validate:"required"`
+	Correct bool   `json:"correct"`
+}
+
+// QuizOptionResponseFormat represents a QuizOption's standard formatting for JSON serializing.
+type QuizOptionResponseFormat struct {
+	ID      uuid.UUID `json:"id"`
+	Text    string    `json:"text"`
+	Correct bool      `json:"correct"`
+}
+
+// QuizAttemptOptionResponseFormat represents a QuizOption as shown to students taking a Quiz.
+type QuizAttemptOptionResponseFormat struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+//// Quiz Attempt
+
+// QuizAttempt is a student's attempt at a Quiz.
+type QuizAttempt struct {
+	ID            uuid.UUID           `db:"id" validate:"required"`
+	QuizID        uuid.UUID           `db:"quiz_id" validate:"required"`
+	StudentID     uuid.UUID           `db:"student_id" validate:"required"`
+	AttemptNumber int                 `db:"attempt_number" validate:"min=1"`
+	ShuffleSeed   int64               `db:"shuffle_seed"`
+	Status        QuizAttemptStatus   `db:"status" validate:"required,oneof=in_progress submitted expired"`
+	StartedAt     time.Time           `db:"started_at" validate:"required"`
+	ExpiresAt     null.Time           `db:"expires_at"`
+	SubmittedAt   null.Time           `db:"submitted_at"`
+	Score         int                 `db:"score"`
+	MaxScore      int                 `db:"max_score"`
+	Questions     []QuizQuestion      `db:"-"`
+	Answers       []QuizAttemptAnswer `db:"-"`
+}
+
+// AttachAnswers attaches QuizAttemptAnswers to this QuizAttempt.
+func (qa *QuizAttempt) AttachAnswers(answers []QuizAttemptAnswer) QuizAttempt {
+	for _, answer := range answers {
+		if answer.AttemptID == qa.ID {
+			qa.Answers = append(qa.Answers, answer)
+		}
+	}
+	return *qa
+}
+
+// AttachQuiz attaches the QuizQuestions of a Quiz to this QuizAttempt. When
+// the Quiz shuffles answers, the options of choice questions are shuffled with
+// this attempt's seed, so a student sees the same order every time.
+func (qa *QuizAttempt) AttachQuiz(quiz Quiz) QuizAttempt {
+	random := rand.New(rand.NewSource(qa.ShuffleSeed))
+
+	qa.Questions = make([]QuizQuestion, 0, len(quiz.Questions))
+	for _, question := range quiz.Questions {
+		options := make([]QuizOption, len(question.Options))
+		copy(options, question.Options)
+
+		if quiz.ShuffleAnswers && question.Type != QuestionTypeTrueFalse {
+			random.Shuffle(len(options), func(i, j int) {
+				options[i], options[j] = options[j], options[i]
+			})
+		}
+
+		question.Options = options
+		qa.Questions = append(qa.Questions, question)
+	}
+
+	return *qa
+}
+
+// IsExpired checks whether the time limit of an in-progress QuizAttempt ran out.
+func (qa *QuizAttempt) IsExpired() bool {
+	return qa.Status == QuizAttemptStatusInProgress &&
+		qa.ExpiresAt.Valid &&
+		time.Now().After(qa.ExpiresAt.Time.Add(quizSubmissionGracePeriod))
+}
+
+// IsInProgress checks whether a QuizAttempt still accepts a submission.
+func (qa *QuizAttempt) IsInProgress() bool {
+	return qa.Status == QuizAttemptStatusInProgress
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (qa QuizAttempt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(qa.ToResponseFormat())
+}
+
+// NewQuizAttempt starts a student's attempt at a Quiz.
+func (qa QuizAttempt) NewQuizAttempt(quiz Quiz, studentID uuid.UUID, attemptNumber int) (newAttempt QuizAttempt, err error) {
+	attemptID, _ := uuid.NewV4()
+	now := time.Now()
+	newAttempt = QuizAttempt{
+		ID:            attemptID,
+		QuizID:        quiz.ID,
+		StudentID:     studentID,
+		AttemptNumber: attemptNumber,
+		ShuffleSeed:   now.UnixNano(),
+		Status:        QuizAttemptStatusInProgress,
+		StartedAt:     now,
+		MaxScore:      quiz.MaxScore(),
+	}
+
+	if quiz.HasTimeLimit() {
+		newAttempt.ExpiresAt = null.TimeFrom(now.Add(time.Duration(quiz.TimeLimitSeconds.Int64) * time.Second))
+	}
+
+	err = newAttempt.Validate()
+
+	return
+}
+
+// Percentage returns the score of this QuizAttempt as a percentage of the maximum score.
+func (qa *QuizAttempt) Percentage() float64 {
+	return CompletionPercentage(qa.Score, qa.MaxScore)
+}
+
+// Submit scores the answers to a Quiz and submits this QuizAttempt. Questions
+// left unanswered score no points.
+func (qa *QuizAttempt) Submit(quiz Quiz, req QuizAttemptRequestFormat) (err error) {
+	questions := make(map[uuid.UUID]bool)
+	for _, question := range quiz.Questions {
+		questions[question.ID] = true
+	}
+
+	answers := make(map[uuid.UUID]QuizAnswerRequestFormat)
+	for _, answer := range req.Answers {
+		if !questions[answer.QuestionID] {
+			return fmt.Errorf("question %s is not part of this quiz", answer.QuestionID)
+		}
+
+		if _, duplicate := answers[answer.QuestionID]; duplicate {
+			return fmt.Errorf("question %s is answered more than once", answer.QuestionID)
+		}
+
+		answers[answer.QuestionID] = answer
+	}
+
+	graded := make([]QuizAttemptAnswer, 0, len(quiz.Questions))
+	score := 0
+	for _, question := range quiz.Questions {
+		attemptAnswer := qa.newAnswer(question, answers[question.ID])
+		score += attemptAnswer.PointsAwarded
+		graded = append(graded, attemptAnswer)
+	}
+
+	err = qa.UpdateStatus(QuizAttemptStatusSubmitted)
+	if err != nil {
+		return
+	}
+
+	qa.SubmittedAt = null.TimeFrom(time.Now())
+	qa.Score = score
+	qa.MaxScore = quiz.MaxScore()
+	qa.Answers = graded
+
+	return
+}
+
+// ToResponseFormat converts this QuizAttempt to its response format.
+func (qa QuizAttempt) ToResponseFormat() QuizAttemptResponseFormat {
+	resp := QuizAttemptResponseFormat{
+		ID:            qa.ID,
+		QuizID:        qa.QuizID,
+		StudentID:     qa.StudentID,
+		AttemptNumber: qa.AttemptNumber,
+		Status:        qa.Status,
+		StartedAt:     qa.StartedAt,
+		ExpiresAt:     qa.ExpiresAt,
+		SubmittedAt:   qa.SubmittedAt,
+		Score:         qa.Score,
+		MaxScore:      qa.MaxScore,
+		Percentage:    qa.Percentage(),
+	}
+
+	for _, question := range qa.Questions {
+		resp.Questions = append(resp.Questions, question.ToAttemptFormat())
+	}
+
+	for _, answer := range qa.Answers {
+		resp.Answers = append(resp.Answers, answer.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// UpdateStatus validates a QuizAttempt's status change. Allowed state changes are:
+// 1. InProgress --> Submitted, Expired
+// 2. Submitted --> this is a final state, no change allowed
+// 3. Expired --> this is a final state, no change allowed
+func (qa *QuizAttempt) UpdateStatus(newStatus QuizAttemptStatus) (err error) {
+	stateChangeNotAllowedError := failure.Conflict(
+		"stateChange",
+		"quiz attempt",
+		fmt.Sprintf("cannot change from %s to %s", qa.Status, newStatus))
+
+	switch qa.Status {
+	case QuizAttemptStatusInProgress:
+		if newStatus != QuizAttemptStatusSubmitted && newStatus != QuizAttemptStatusExpired {
+			return stateChangeNotAllowedError
+		}
+	case QuizAttemptStatusSubmitted, QuizAttemptStatusExpired:
+		return stateChangeNotAllowedError
+	}
+
+	// passed all state change validations, actually update the status
+	qa.Status = newStatus
+
+	return nil
+}
+
+// Validate validates the entity.
+func (qa *QuizAttempt) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(qa)
+}
+
+// newAnswer scores a student's answer to a QuizQuestion of this QuizAttempt.
+func (qa *QuizAttempt) newAnswer(question QuizQuestion, answer QuizAnswerRequestFormat) QuizAttemptAnswer {
+	answerID, _ := uuid.NewV4()
+	attemptAnswer := QuizAttemptAnswer{
+		ID:         answerID,
+		AttemptID:  qa.ID,
+		QuestionID: question.ID,
+		IsCorrect:  question.Grade(answer),
+	}
+
+	if question.Type.HasOptions() {
+		attemptAnswer.SelectedOptionIDs = UUIDList(answer.OptionIDs)
+	} else {
+		attemptAnswer.AnswerText = null.NewString(strings.TrimSpace(answer.Text), answer.Text != "")
+	}
+
+	if attemptAnswer.IsCorrect {
+		attemptAnswer.PointsAwarded = question.Points
+	}
+
+	return attemptAnswer
+}
+
+// QuizAttemptRequestFormat represents the answers a student submits for a QuizAttempt.
+type QuizAttemptRequestFormat struct {
+	Answers []QuizAnswerRequestFormat `json:"answers" validate:"dive"`
+}
+
+// QuizAnswerRequestFormat represents a student's answer to a QuizQuestion. Choice
+// questions are answered with option IDs and short-answer questions with text.
+type QuizAnswerRequestFormat struct {
+	QuestionID uuid.UUID   `json:"questionID" validate:"required"`
+	OptionIDs  []uuid.UUID `json:"optionIDs"`
+	Text       string      `json:"text"`
+}
+
+// QuizAttemptResponseFormat represents a QuizAttempt's standard formatting for JSON serializing.
+type QuizAttemptResponseFormat struct {
+	ID            uuid.UUID                           `json:"id"`
+	QuizID        uuid.UUID                           `json:"quizID"`
+	StudentID     uuid.UUID                           `json:"studentID"`
+	AttemptNumber int                                 `json:"attemptNumber"`
+	Status        QuizAttemptStatus                   `json:"status"`
+	StartedAt     time.Time                           `json:"startedAt"`
+	ExpiresAt     null.Time                           `json:"expiresAt"`
+	SubmittedAt   null.Time                           `json:"submittedAt"`
+	Score         int                                 `json:"score"`
+	MaxScore      int                                 `json:"maxScore"`
+	Percentage    float64                             `json:"percentage"`
+	Questions     []QuizAttemptQuestionResponseFormat `json:"questions,omitempty"`
+	Answers       []QuizAttemptAnswerResponseFormat   `json:"answers,omitempty"`
+}
+
+//// Quiz Attempt Answer
+
+// QuizAttemptAnswer is a scored answer to a QuizQuestion within a QuizAttempt.
+type QuizAttemptAnswer struct {
+	ID                uuid.UUID   `db:"id"`
+	AttemptID         uuid.UUID   `db:"attempt_id"`
+	QuestionID        uuid.UUID   `db:"question_id"`
+	SelectedOptionIDs UUIDList    `db:"selected_option_ids"`
+	AnswerText        null.String `db:"answer_text"`
+	IsCorrect         bool        `db:"is_correct"`
+	PointsAwarded     int         `db:"points_awarded"`
+}
+
+// ToResponseFormat converts this QuizAttemptAnswer to its response format.
+func (qaa QuizAttemptAnswer) ToResponseFormat() QuizAttemptAnswerResponseFormat {
+	return QuizAttemptAnswerResponseFormat{
+		QuestionID:    qaa.QuestionID,
+		OptionIDs:     qaa.SelectedOptionIDs,
+		Text:          qaa.AnswerText,
+		Correct:       qaa.IsCorrect,
+		PointsAwarded: qaa.PointsAwarded,
+	}
+}
+
+// QuizAttemptAnswerResponseFormat represents a QuizAttemptAnswer's standard formatting for JSON serializing.
+type QuizAttemptAnswerResponseFormat struct {
+	QuestionID    uuid.UUID   `json:"questionID"`
+	OptionIDs     []uuid.UUID `json:"optionIDs"`
+	Text          null.String `json:"text" swaggertype:"string"`
+	Correct       bool        `json:"correct"`
+	PointsAwarded int         `json:"pointsAwarded"`
+}
+
+//// Quiz Statistics
+
+// QuizStatistics summarises the submitted attempts of a Quiz.
+type QuizStatistics struct {
+	QuizID            uuid.UUID                `db:"-" json:"quizID"`
+	Attempts          int                      `db:"attempts" json:"attempts"`
+	Students          int                      `db:"students" json:"students"`
+	AverageScore      float64                  `db:"average_score" json:"averageScore"`
+	AveragePercentage float64                  `db:"average_percentage" json:"averagePercentage"`
+	Questions         []QuizQuestionStatistics `db:"-" json:"questions"`
+}
+
+// QuizQuestionStatistics summarises the answers to a QuizQuestion across submitted attempts.
+type QuizQuestionStatistics struct {
+	QuestionID        uuid.UUID    `db:"question_id" json:"questionID"`
+	Position          int          `db:"position" json:"position"`
+	Type              QuestionType `db:"type" json:"type"`
+	Prompt            string       `db:"prompt" json:"prompt"`
+	Answered          int          `db:"answered" json:"answered"`
+	Correct           int          `db:"correct" json:"correct"`
+	CorrectPercentage float64      `db:"-" json:"correctPercentage"`
+}
+
+//// UUID List
+
+// UUIDList is a list of UUIDs stored as a comma-separated string.
+type UUIDList []uuid.UUID
+
+// Scan implements the Scanner interface.
+func (l *UUIDList) Scan(value interface{}) error {
+	var str string
+	switch x := value.(type) {
+	case []byte:
+		str = string(x)
+	case string:
+		str = x
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan type %T into course.UUIDList: %v", value, value)
+	}
+
+	list := make(UUIDList, 0)
+	for _, part := range strings.Split(str, ",") {
+		if part == "" {
+			continue
+		}
+
+		id, err := uuid.FromString(part)
+		if err != nil {
+			return err
+		}
+
+		list = append(list, id)
+	}
+
+	*l = list
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (l UUIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	parts := make([]string, 0, len(l))
+	for _, id := range l {
+		parts = append(parts, id.String())
+	}
+	return strings.Join(parts, ","), nil
+}
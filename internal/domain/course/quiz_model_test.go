@@ -0,0 +1,190 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizQuestionGrade(t *testing.T) {
+	quiz := newQuiz(t)
+	single, multiple, trueFalse, shortAnswer := quiz.Questions[0], quiz.Questions[1], quiz.Questions[2], quiz.Questions[3]
+
+	tests := []struct {
+		name     string
+		question course.QuizQuestion
+		answer   course.QuizAnswerRequestFormat
+		want     bool
+	}{
+		{name: "single choice correct", question: single, answer: selectOptions(single, 1), want: true},
+		{name: "single choice wrong", question: single, answer: selectOptions(single, 0)},
+		{name: "single choice with extra option", question: single, answer: selectOptions(single, 0, 1)},
+		{name: "multiple choice correct", question: multiple, answer: selectOptions(multiple, 0, 2), want: true},
+		{name: "multiple choice partial", question: multiple, answer: selectOptions(multiple, 0)},
+		{name: "multiple choice with unknown option", question: multiple, answer: course.QuizAnswerRequestFormat{
+			OptionIDs: append(selectOptions(multiple, 0, 2).OptionIDs, getRandomUUID()),
+		}},
+		{name: "true/false correct", question: trueFalse, answer: selectOptions(trueFalse, 1), want: true},
+		{name: "true/false wrong", question: trueFalse, answer: selectOptions(trueFalse, 0)},
+		{name: "short answer exact", question: shortAnswer, answer: course.QuizAnswerRequestFormat{Text: " SELECT "}, want: true},
+		{name: "short answer different case", question: shortAnswer, answer: course.QuizAnswerRequestFormat{Text: "select"}},
+		{name: "unanswered", question: shortAnswer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.question.Grade(test.answer))
+		})
+	}
+}
+
+func TestQuizAttemptSubmit(t *testing.T) {
+	quiz := newQuiz(t)
+
+	t.Run("scores the answers", func(t *testing.T) {
+		attempt, err := course.QuizAttempt{}.NewQuizAttempt(quiz, getRandomUUID(), 1)
+		assert.NoError(t, err)
+		assert.True(t, attempt.ExpiresAt.Valid)
+
+		err = attempt.Submit(quiz, course.QuizAttemptRequestFormat{Answers: []course.QuizAnswerRequestFormat{
+			withQuestion(quiz.Questions[0], selectOptions(quiz.Questions[0], 1)),
+			withQuestion(quiz.Questions[1], selectOptions(quiz.Questions[1], 0)),
+			withQuestion(quiz.Questions[3], course.QuizAnswerRequestFormat{Text: "SELECT"}),
+		}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, course.QuizAttemptStatusSubmitted, attempt.Status)
+		assert.Equal(t, 2, attempt.Score)
+		assert.Equal(t, 5, attempt.MaxScore)
+		assert.Equal(t, 40.0, attempt.Percentage())
+		assert.Len(t, attempt.Answers, len(quiz.Questions))
+	})
+
+	t.Run("rejects unknown questions", func(t *testing.T) {
+		attempt, _ := course.QuizAttempt{}.NewQuizAttempt(quiz, getRandomUUID(), 1)
+
+		err := attempt.Submit(quiz, course.QuizAttemptRequestFormat{Answers: []course.QuizAnswerRequestFormat{
+			{QuestionID: getRandomUUID()},
+		}})
+
+		assert.Error(t, err)
+		assert.Equal(t, course.QuizAttemptStatusInProgress, attempt.Status)
+	})
+
+	t.Run("cannot submit twice", func(t *testing.T) {
+		attempt, _ := course.QuizAttempt{}.NewQuizAttempt(quiz, getRandomUUID(), 1)
+		assert.NoError(t, attempt.Submit(quiz, course.QuizAttemptRequestFormat{}))
+
+		err := attempt.Submit(quiz, course.QuizAttemptRequestFormat{})
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
+
+func TestQuizAttemptAttachQuiz(t *testing.T) {
+	quiz := newQuiz(t)
+	quiz.ShuffleAnswers = true
+	attempt, _ := course.QuizAttempt{}.NewQuizAttempt(quiz, getRandomUUID(), 1)
+	options := append([]course.QuizOption(nil), quiz.Questions[0].Options...)
+
+	first := attempt.AttachQuiz(quiz)
+	second := attempt.AttachQuiz(quiz)
+
+	assert.Equal(t, first.Questions, second.Questions, "the same attempt always sees the same order")
+	assert.Equal(t, "True", first.Questions[2].Options[0].Text, "true/false options are never shuffled")
+	assert.Equal(t, options, quiz.Questions[0].Options, "the quiz itself is untouched")
+}
+
+func TestNewQuizFromRequestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		question course.QuizQuestionRequestFormat
+	}{
+		{name: "single choice with two correct options", question: course.QuizQuestionRequestFormat{
+			Type:    course.QuestionTypeSingleChoice,
+			Prompt:  "Pick one",
+			Options: []course.QuizOptionRequestFormat{{Text: "a", Correct: true}, {Text: "b", Correct: true}},
+		}},
+		{name: "multiple choice without correct option", question: course.QuizQuestionRequestFormat{
+			Type:    course.QuestionTypeMultipleChoice,
+			Prompt:  "Pick some",
+			Options: []course.QuizOptionRequestFormat{{Text: "a"}, {Text: "b"}},
+		}},
+		{name: "true/false with another answer", question: course.QuizQuestionRequestFormat{
+			Type:   course.QuestionTypeTrueFalse,
+			Prompt: "Right?",
+			Answer: "maybe",
+		}},
+		{name: "short answer without answer", question: course.QuizQuestionRequestFormat{
+			Type:   course.QuestionTypeShortAnswer,
+			Prompt: "Name it",
+		}},
+		{name: "short answer with options", question: course.QuizQuestionRequestFormat{
+			Type:    course.QuestionTypeShortAnswer,
+			Prompt:  "Name it",
+			Answer:  "it",
+			Options: []course.QuizOptionRequestFormat{{Text: "it", Correct: true}},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := course.Quiz{}.NewQuizFromRequestFormat(course.QuizRequestFormat{
+				Title:     "Checkpoint",
+				Questions: []course.QuizQuestionRequestFormat{test.question},
+			}, newLesson(), getRandomUUID())
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+// newQuiz creates a timed Quiz with one question of every type, worth 5 points in total.
+func newQuiz(t *testing.T) course.Quiz {
+	quiz, err := course.Quiz{}.NewQuizFromRequestFormat(course.QuizRequestFormat{
+		Title:            "SQL basics",
+		TimeLimitSeconds: null.IntFrom(600),
+		MaxAttempts:      null.IntFrom(2),
+		Questions: []course.QuizQuestionRequestFormat{
+			{
+				Type:    course.QuestionTypeSingleChoice,
+				Prompt:  "Which clause filters rows?",
+				Options: []course.QuizOptionRequestFormat{{Text: "ORDER BY"}, {Text: "WHERE", Correct: true}, {Text: "LIMIT"}},
+			},
+			{
+				Type:    course.QuestionTypeMultipleChoice,
+				Prompt:  "Which are aggregate functions?",
+				Points:  2,
+				Options: []course.QuizOptionRequestFormat{{Text: "COUNT", Correct: true}, {Text: "LOWER"}, {Text: "SUM", Correct: true}},
+			},
+			{Type: course.QuestionTypeTrueFalse, Prompt: "JOIN is an aggregate function.", Answer: "false"},
+			{Type: course.QuestionTypeShortAnswer, Prompt: "Which statement reads rows?", Answer: "SELECT"},
+		},
+	}, newLesson(), getRandomUUID())
+	assert.NoError(t, err)
+
+	return quiz
+}
+
+func newLesson() course.Lesson {
+	return course.Lesson{ID: getRandomUUID(), ModuleID: getRandomUUID(), CourseID: getRandomUUID(), CreatedAt: time.Now()}
+}
+
+func selectOptions(question course.QuizQuestion, indexes ...int) course.QuizAnswerRequestFormat {
+	optionIDs := make([]uuid.UUID, 0, len(indexes))
+	for _, index := range indexes {
+		optionIDs = append(optionIDs, question.Options[index].ID)
+	}
+	return course.QuizAnswerRequestFormat{QuestionID: question.ID, OptionIDs: optionIDs}
+}
+
+func withQuestion(question course.QuizQuestion, answer course.QuizAnswerRequestFormat) course.QuizAnswerRequestFormat {
+	answer.QuestionID = question.ID
+	return answer
+}
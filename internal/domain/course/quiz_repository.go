@@ -0,0 +1,694 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source quiz_repository.go -destination mock/quiz_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	quizQueries = struct {
+		selectQuiz                        string
+		selectQuizQuestion                string
+		selectQuizOption                  string
+		selectQuizAttempt                 string
+		selectQuizAttemptAnswer           string
+		selectQuizStatistics              string
+		selectQuizQuestionStatistics      string
+		countQuizAttempts                 string
+		insertQuiz                        string
+		insertQuizQuestionBulk            string
+		insertQuizQuestionBulkPlaceholder string
+		insertQuizOptionBulk              string
+		insertQuizOptionBulkPlaceholder   string
+		insertQuizAttempt                 string
+		insertQuizAnswerBulk              string
+		insertQuizAnswerBulkPlaceholder   string
+		updateQuiz                        string
+		updateQuizAttempt                 string
+	}{
+		selectQuiz: `
+			SELECT
+				id,
+				course_id,
+				lesson_id,
+				title,
+				time_limit_seconds,
+				max_attempts,
+				shuffle_answers,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			FROM quizzes
+		`,
+
+		selectQuizQuestion: `
+			SELECT
+				id,
+				quiz_id,
+				type,
+				prompt,
+				answer_text,
+				points,
+				position
+			FROM quiz_questions
+		`,
+
+		selectQuizOption: `
+			SELECT
+				id,
+				question_id,
+				text,
+				is_correct,
+				position
+			FROM quiz_options
+		`,
+
+		selectQuizAttempt: `
+			SELECT
+				id,
+				quiz_id,
+				student_id,
+				attempt_number,
+				shuffle_seed,
+				status,
+				started_at,
+				expires_at,
+				submitted_at,
+				score,
+				max_score
+			FROM quiz_attempts
+		`,
+
+		selectQuizAttemptAnswer: `
+			SELECT
+				id,
+				attempt_id,
+				question_id,
+				selected_option_ids,
+				answer_text,
+				is_correct,
+				points_awarded
+			FROM quiz_attempt_answers
+		`,
+
+		selectQuizStatistics: `
+			SELECT
+				COUNT(id) AS attempts,
+				COUNT(DISTINCT student_id) AS students,
+				COALESCE(ROUND(AVG(score), 2), 0) AS average_score,
+				COALESCE(ROUND(AVG(CASE WHEN max_score > 0 THEN score * 100 / max_score ELSE 0 END), 2), 0) AS average_percentage
+			FROM quiz_attempts
+			WHERE quiz_id = ? AND status = 'submitted'
+		`,
+
+		selectQuizQuestionStatistics: `
+			SELECT
+				q.id AS question_id,
+				q.position,
+				q.type,
+				q.prompt,
+				COUNT(a.id) AS answered,
+				COALESCE(SUM(a.is_correct), 0) AS correct
+			FROM quiz_questions q
+			LEFT JOIN quiz_attempt_answers a ON a.question_id = q.id
+			WHERE q.quiz_id = ?
+			GROUP BY q.id, q.position, q.type, q.prompt
+			ORDER BY q.position
+		`,
+
+		countQuizAttempts: `
+			SELECT COUNT(id)
+			FROM quiz_attempts
+		`,
+
+		insertQuiz: `
+			INSERT INTO quizzes (
+				id,
+				course_id,
+				lesson_id,
+				title,
+				time_limit_seconds,
+				max_attempts,
+				shuffle_answers,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by
+			) VALUES (
+				:id,
+				:course_id,
+				:lesson_id,
+				:title,
+				:time_limit_seconds,
+				:max_attempts,
+				:shuffle_answers,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by,
+				:deleted_at,
+				:deleted_by
+			)
+		`,
+
+		insertQuizQuestionBulk: `
+			INSERT INTO quiz_questions (
+				id,
+				quiz_id,
+				type,
+				prompt,
+				answer_text,
+				points,
+				position
+			) VALUES `,
+
+		insertQuizQuestionBulkPlaceholder: `
+			(:id,
+			:quiz_id,
+			:type,
+			:prompt,
+			:answer_text,
+			:points,
+			:position)`,
+
+		insertQuizOptionBulk: `
+			INSERT INTO quiz_options (
+				id,
+				question_id,
+				text,
+				is_correct,
+				position
+			) VALUES `,
+
+		insertQuizOptionBulkPlaceholder: `
+			(:id,
+			:question_id,
+			:text,
+			:is_correct,
+			:position)`,
+
+		insertQuizAttempt: `
+			INSERT INTO quiz_attempts (
+				id,
+				quiz_id,
+				student_id,
+				attempt_number,
+				shuffle_seed,
+				status,
+				started_at,
+				expires_at,
+				submitted_at,
+				score,
+				max_score
+			) VALUES (
+				:id,
+				:quiz_id,
+				:student_id,
+				:attempt_number,
+				:shuffle_seed,
+				:status,
+				:started_at,
+				:expires_at,
+				:submitted_at,
+				:score,
+				:max_score
+			)
+		`,
+
+		insertQuizAnswerBulk: `
+			INSERT INTO quiz_attempt_answers (
+				id,
+				attempt_id,
+				question_id,
+				selected_option_ids,
+				answer_text,
+				is_correct,
+				points_awarded
+			) VALUES `,
+
+		insertQuizAnswerBulkPlaceholder: `
+			(:id,
+			:attempt_id,
+			:question_id,
+			:selected_option_ids,
+			:answer_text,
+			:is_correct,
+			:points_awarded)`,
+
+		updateQuiz: `
+			UPDATE quizzes
+			SET
+				title = :title,
+				time_limit_seconds = :time_limit_seconds,
+				max_attempts = :max_attempts,
+				shuffle_answers = :shuffle_answers,
+				updated_at = :updated_at,
+				updated_by = :updated_by,
+				deleted_at = :deleted_at,
+				deleted_by = :deleted_by
+			WHERE id = :id
+		`,
+
+		updateQuizAttempt: `
+			UPDATE quiz_attempts
+			SET
+				status = :status,
+				submitted_at = :submitted_at,
+				score = :score,
+				max_score = :max_score
+			WHERE id = :id
+		`,
+	}
+)
+
+// QuizRepository is the repository for Quiz data.
+type QuizRepository interface {
+	CountAttemptsByQuizID(quizID uuid.UUID) (count int, err error)
+	CountAttemptsByQuizIDAndStudentID(quizID uuid.UUID, studentID uuid.UUID) (count int, err error)
+	CreateAttempt(attempt QuizAttempt) (err error)
+	CreateQuiz(quiz Quiz) (err error)
+	ResolveAnswersByAttemptIDs(ids []uuid.UUID) (answers []QuizAttemptAnswer, err error)
+	ResolveAttemptByID(id uuid.UUID) (attempt QuizAttempt, err error)
+	ResolveAttemptsByQuizIDAndStudentID(quizID uuid.UUID, studentID uuid.UUID) (attempts []QuizAttempt, err error)
+	ResolveQuestionsByQuizIDs(ids []uuid.UUID) (questions []QuizQuestion, err error)
+	ResolveQuestionStatistics(quizID uuid.UUID) (statistics []QuizQuestionStatistics, err error)
+	ResolveQuizByID(id uuid.UUID) (quiz Quiz, err error)
+	ResolveQuizStatistics(quizID uuid.UUID) (statistics QuizStatistics, err error)
+	ResolveQuizzesByCourseID(courseID uuid.UUID) (quizzes []Quiz, err error)
+	ResolveQuizzesByLessonID(lessonID uuid.UUID) (quizzes []Quiz, err error)
+	ResolveSubmittedAttemptsByCourseID(courseID uuid.UUID) (attempts []QuizAttempt, err error)
+	SubmitAttempt(attempt QuizAttempt) (err error)
+	UpdateAttempt(attempt QuizAttempt) (err error)
+	UpdateQuiz(quiz Quiz) (err error)
+	UpdateQuizWithQuestions(quiz Quiz) (err error)
+}
+
+// QuizRepositoryMySQL is the MySQL-backed implementation of QuizRepository.
+type QuizRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideQuizRepositoryMySQL is the provider for this repository.
+func ProvideQuizRepositoryMySQL(db *infras.MySQLConn) *QuizRepositoryMySQL {
+	s := new(QuizRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CountAttemptsByQuizID counts every attempt at a Quiz.
+func (r *QuizRepositoryMySQL) CountAttemptsByQuizID(quizID uuid.UUID) (count int, err error) {
+	err = r.DB.Read.Get(&count, quizQueries.countQuizAttempts+" WHERE quiz_id = ?", quizID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// CountAttemptsByQuizIDAndStudentID counts a student's attempts at a Quiz, expired ones included.
+func (r *QuizRepositoryMySQL) CountAttemptsByQuizIDAndStudentID(quizID uuid.UUID, studentID uuid.UUID) (count int, err error) {
+	err = r.DB.Read.Get(
+		&count,
+		quizQueries.countQuizAttempts+" WHERE quiz_id = ? AND student_id = ?",
+		quizID.String(),
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// CreateAttempt creates a new QuizAttempt. Attempt numbers are unique per
+// student and Quiz, so concurrent starts cannot exceed the attempt limit.
+func (r *QuizRepositoryMySQL) CreateAttempt(attempt QuizAttempt) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, quizQueries.insertQuizAttempt, attempt); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateQuiz creates a new Quiz together with its QuizQuestions and QuizOptions.
+func (r *QuizRepositoryMySQL) CreateQuiz(quiz Quiz) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, quizQueries.insertQuiz, quiz); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txCreateQuestions(tx, quiz.Questions); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveAnswersByAttemptIDs resolves the QuizAttemptAnswers of a set of QuizAttempts.
+func (r *QuizRepositoryMySQL) ResolveAnswersByAttemptIDs(ids []uuid.UUID) (answers []QuizAttemptAnswer, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(quizQueries.selectQuizAttemptAnswer+" WHERE attempt_id IN (?)", ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&answers, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAttemptByID resolves a QuizAttempt by its ID.
+func (r *QuizRepositoryMySQL) ResolveAttemptByID(id uuid.UUID) (attempt QuizAttempt, err error) {
+	err = r.DB.Read.Get(&attempt, quizQueries.selectQuizAttempt+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("quiz attempt")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveAttemptsByQuizIDAndStudentID resolves a student's attempts at a Quiz,
+// ordered by attempt number.
+func (r *QuizRepositoryMySQL) ResolveAttemptsByQuizIDAndStudentID(quizID uuid.UUID, studentID uuid.UUID) (attempts []QuizAttempt, err error) {
+	err = r.DB.Read.Select(
+		&attempts,
+		quizQueries.selectQuizAttempt+" WHERE quiz_id = ? AND student_id = ? ORDER BY attempt_number",
+		quizID.String(),
+		studentID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveQuestionsByQuizIDs resolves the QuizQuestions of a set of Quizzes with
+// their QuizOptions attached, ordered by their position.
+func (r *QuizRepositoryMySQL) ResolveQuestionsByQuizIDs(ids []uuid.UUID) (questions []QuizQuestion, err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(quizQueries.selectQuizQuestion+" WHERE quiz_id IN (?) ORDER BY position", ids)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&questions, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	questionIDs := make([]uuid.UUID, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.ID)
+	}
+
+	if len(questionIDs) == 0 {
+		return
+	}
+
+	query, args, err = sqlx.In(quizQueries.selectQuizOption+" WHERE question_id IN (?) ORDER BY position", questionIDs)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	var options []QuizOption
+	err = r.DB.Read.Select(&options, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	for i := range questions {
+		questions[i].AttachOptions(options)
+	}
+
+	return
+}
+
+// ResolveQuestionStatistics counts the answers and correct answers to every
+// QuizQuestion of a Quiz. Only submitted attempts have answers.
+func (r *QuizRepositoryMySQL) ResolveQuestionStatistics(quizID uuid.UUID) (statistics []QuizQuestionStatistics, err error) {
+	err = r.DB.Read.Select(&statistics, quizQueries.selectQuizQuestionStatistics, quizID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveQuizByID resolves a Quiz by its ID, without its QuizQuestions.
+func (r *QuizRepositoryMySQL) ResolveQuizByID(id uuid.UUID) (quiz Quiz, err error) {
+	err = r.DB.Read.Get(&quiz, quizQueries.selectQuiz+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("quiz")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveQuizStatistics summarises the submitted attempts at a Quiz.
+func (r *QuizRepositoryMySQL) ResolveQuizStatistics(quizID uuid.UUID) (statistics QuizStatistics, err error) {
+	err = r.DB.Read.Get(&statistics, quizQueries.selectQuizStatistics, quizID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	statistics.QuizID = quizID
+
+	return
+}
+
+// ResolveQuizzesByCourseID resolves the active Quizzes of a Course.
+func (r *QuizRepositoryMySQL) ResolveQuizzesByCourseID(courseID uuid.UUID) (quizzes []Quiz, err error) {
+	err = r.DB.Read.Select(
+		&quizzes,
+		quizQueries.selectQuiz+" WHERE course_id = ? AND deleted_at IS NULL ORDER BY created_at",
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveQuizzesByLessonID resolves the active Quizzes of a Lesson, without their QuizQuestions.
+func (r *QuizRepositoryMySQL) ResolveQuizzesByLessonID(lessonID uuid.UUID) (quizzes []Quiz, err error) {
+	err = r.DB.Read.Select(
+		&quizzes,
+		quizQueries.selectQuiz+" WHERE lesson_id = ? AND deleted_at IS NULL ORDER BY created_at",
+		lessonID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveSubmittedAttemptsByCourseID resolves the submitted QuizAttempts at
+// the active Quizzes of a Course.
+func (r *QuizRepositoryMySQL) ResolveSubmittedAttemptsByCourseID(courseID uuid.UUID) (attempts []QuizAttempt, err error) {
+	err = r.DB.Read.Select(
+		&attempts,
+		quizQueries.selectQuizAttempt+" WHERE status = ? AND quiz_id IN (SELECT id FROM quizzes WHERE course_id = ? AND deleted_at IS NULL)",
+		QuizAttemptStatusSubmitted,
+		courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SubmitAttempt stores a submitted QuizAttempt together with its scored answers.
+func (r *QuizRepositoryMySQL) SubmitAttempt(attempt QuizAttempt) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, quizQueries.updateQuizAttempt, attempt); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txCreateAnswers(tx, attempt.Answers); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateAttempt updates a QuizAttempt.
+func (r *QuizRepositoryMySQL) UpdateAttempt(attempt QuizAttempt) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, quizQueries.updateQuizAttempt, attempt); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateQuiz updates a Quiz, leaving its QuizQuestions untouched.
+func (r *QuizRepositoryMySQL) UpdateQuiz(quiz Quiz) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, quizQueries.updateQuiz, quiz); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateQuizWithQuestions updates a Quiz and replaces its QuizQuestions.
+func (r *QuizRepositoryMySQL) UpdateQuizWithQuestions(quiz Quiz) (err error) {
+	// transactionally update the Quiz
+	// strategy:
+	// 1. delete all the Quiz's questions, their options cascade
+	// 2. create a new set of the Quiz's questions and options
+	// 3. update the Quiz
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec("DELETE FROM quiz_questions WHERE quiz_id = ?", quiz.ID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txCreateQuestions(tx, quiz.Questions); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, quizQueries.updateQuiz, quiz); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// composeBulkInsertQuery composes a bulk insert query given a query prefix,
+// a named placeholder and one entry per row.
+func (r *QuizRepositoryMySQL) composeBulkInsertQuery(insert string, placeholder string, rows []interface{}) (query string, params []interface{}, err error) {
+	values := []string{}
+	for _, row := range rows {
+		q, args, err := sqlx.Named(placeholder, row)
+		if err != nil {
+			return query, params, err
+		}
+		values = append(values, q)
+		params = append(params, args...)
+	}
+	query = fmt.Sprintf("%v %v", insert, strings.Join(values, ","))
+	return
+}
+
+// txBulkInsert inserts a set of rows transactionally given the *sqlx.Tx param.
+func (r *QuizRepositoryMySQL) txBulkInsert(tx *sqlx.Tx, insert string, placeholder string, rows []interface{}) (err error) {
+	if len(rows) == 0 {
+		return
+	}
+
+	query, args, err := r.composeBulkInsertQuery(insert, placeholder, rows)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txCreateAnswers creates QuizAttemptAnswers transactionally given the *sqlx.Tx param.
+func (r *QuizRepositoryMySQL) txCreateAnswers(tx *sqlx.Tx, answers []QuizAttemptAnswer) (err error) {
+	rows := make([]interface{}, 0, len(answers))
+	for _, answer := range answers {
+		rows = append(rows, answer)
+	}
+
+	return r.txBulkInsert(tx, quizQueries.insertQuizAnswerBulk, quizQueries.insertQuizAnswerBulkPlaceholder, rows)
+}
+
+// txCreateQuestions creates QuizQuestions and their QuizOptions transactionally
+// given the *sqlx.Tx param.
+func (r *QuizRepositoryMySQL) txCreateQuestions(tx *sqlx.Tx, questions []QuizQuestion) (err error) {
+	questionRows := make([]interface{}, 0, len(questions))
+	optionRows := make([]interface{}, 0)
+	for _, question := range questions {
+		questionRows = append(questionRows, question)
+		for _, option := range question.Options {
+			optionRows = append(optionRows, option)
+		}
+	}
+
+	err = r.txBulkInsert(tx, quizQueries.insertQuizQuestionBulk, quizQueries.insertQuizQuestionBulkPlaceholder, questionRows)
+	if err != nil {
+		return
+	}
+
+	return r.txBulkInsert(tx, quizQueries.insertQuizOptionBulk, quizQueries.insertQuizOptionBulkPlaceholder, optionRows)
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *QuizRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
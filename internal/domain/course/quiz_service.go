@@ -0,0 +1,362 @@
+package course
+
+import (
+	"fmt"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// QuizService is the service interface for Quizzes and their attempts.
+type QuizService interface {
+	CreateQuiz(lessonID uuid.UUID, requestFormat QuizRequestFormat, userID uuid.UUID) (quiz Quiz, err error)
+	ResolveAttemptByID(id uuid.UUID, studentID uuid.UUID) (attempt QuizAttempt, err error)
+	ResolveAttemptsByQuizID(quizID uuid.UUID, studentID uuid.UUID) (attempts []QuizAttempt, err error)
+	ResolveQuizByID(id uuid.UUID, userID uuid.UUID) (quiz Quiz, err error)
+	ResolveQuizStatistics(id uuid.UUID, userID uuid.UUID) (statistics QuizStatistics, err error)
+	ResolveQuizzesByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (quizzes []Quiz, err error)
+	SoftDeleteQuiz(id uuid.UUID, userID uuid.UUID) (quiz Quiz, err error)
+	StartAttempt(quizID uuid.UUID, studentID uuid.UUID) (attempt QuizAttempt, err error)
+	SubmitAttempt(id uuid.UUID, requestFormat QuizAttemptRequestFormat, studentID uuid.UUID) (attempt QuizAttempt, err error)
+	UpdateQuiz(id uuid.UUID, requestFormat QuizRequestFormat, userID uuid.UUID) (quiz Quiz, err error)
+}
+
+// QuizServiceImpl is the service implementation for Quizzes and their attempts.
+type QuizServiceImpl struct {
+	CohortRepository       CohortRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	ModuleRepository       ModuleRepository
+	QuizRepository         QuizRepository
+	Config                 *configs.Config
+}
+
+// ProvideQuizServiceImpl is the provider for this service.
+func ProvideQuizServiceImpl(
+	cohortRepository CohortRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	moduleRepository ModuleRepository,
+	quizRepository QuizRepository,
+	config *configs.Config) *QuizServiceImpl {
+	s := new(QuizServiceImpl)
+	s.CohortRepository = cohortRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.ModuleRepository = moduleRepository
+	s.QuizRepository = quizRepository
+	s.Config = config
+
+	return s
+}
+
+// CreateQuiz attaches a new Quiz to a Lesson.
+func (s *QuizServiceImpl) CreateQuiz(lessonID uuid.UUID, requestFormat QuizRequestFormat, userID uuid.UUID) (quiz Quiz, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, lesson.CourseID, userID, PermissionEditContent)
+	if err != nil {
+		return
+	}
+
+	quiz, err = quiz.NewQuizFromRequestFormat(requestFormat, lesson, userID)
+	if err != nil {
+		return quiz, failure.BadRequest(err)
+	}
+
+	err = s.QuizRepository.CreateQuiz(quiz)
+	return
+}
+
+// ResolveAttemptByID resolves one of a student's QuizAttempts with its answers.
+// Attempts still in progress come with the questions to answer.
+func (s *QuizServiceImpl) ResolveAttemptByID(id uuid.UUID, studentID uuid.UUID) (attempt QuizAttempt, err error) {
+	attempt, quiz, err := s.resolveOwnedAttempt(id, studentID)
+	if err != nil {
+		return
+	}
+
+	if attempt.IsInProgress() {
+		attempt.AttachQuiz(quiz)
+		return
+	}
+
+	answers, err := s.QuizRepository.ResolveAnswersByAttemptIDs([]uuid.UUID{attempt.ID})
+	if err != nil {
+		return
+	}
+
+	attempt.AttachAnswers(answers)
+
+	return
+}
+
+// ResolveAttemptsByQuizID resolves a student's attempts at a Quiz.
+func (s *QuizServiceImpl) ResolveAttemptsByQuizID(quizID uuid.UUID, studentID uuid.UUID) (attempts []QuizAttempt, err error) {
+	quiz, _, err := s.resolveQuiz(quizID, false)
+	if err != nil {
+		return
+	}
+
+	err = checkReadAccess(s.EnrollmentRepository, quiz.CourseID, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	return s.QuizRepository.ResolveAttemptsByQuizIDAndStudentID(quiz.ID, studentID)
+}
+
+// ResolveQuizByID resolves a Quiz with its answer key for those who may edit
+// the content of its Course.
+func (s *QuizServiceImpl) ResolveQuizByID(id uuid.UUID, userID uuid.UUID) (quiz Quiz, err error) {
+	return s.resolveManagedQuiz(id, userID, PermissionEditContent, true)
+}
+
+// ResolveQuizStatistics summarises the submitted attempts at a Quiz, question by question.
+func (s *QuizServiceImpl) ResolveQuizStatistics(id uuid.UUID, userID uuid.UUID) (statistics QuizStatistics, err error) {
+	quiz, err := s.resolveManagedQuiz(id, userID, PermissionViewStudents, false)
+	if err != nil {
+		return
+	}
+
+	statistics, err = s.QuizRepository.ResolveQuizStatistics(quiz.ID)
+	if err != nil {
+		return
+	}
+
+	statistics.Questions, err = s.QuizRepository.ResolveQuestionStatistics(quiz.ID)
+	if err != nil {
+		return
+	}
+
+	for i, question := range statistics.Questions {
+		statistics.Questions[i].CorrectPercentage = CompletionPercentage(question.Correct, question.Answered)
+	}
+
+	return
+}
+
+// ResolveQuizzesByLessonID lists the Quizzes of a readable Lesson, without their questions.
+func (s *QuizServiceImpl) ResolveQuizzesByLessonID(lessonID uuid.UUID, userID uuid.UUID, role string) (quizzes []Quiz, err error) {
+	lesson, err := resolveLesson(s.ModuleRepository, lessonID)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReadable(s.EnrollmentRepository, s.CohortRepository, lesson, userID, role)
+	if err != nil {
+		return
+	}
+
+	return s.QuizRepository.ResolveQuizzesByLessonID(lesson.ID)
+}
+
+// SoftDeleteQuiz marks a Quiz as deleted. Its attempts are kept.
+func (s *QuizServiceImpl) SoftDeleteQuiz(id uuid.UUID, userID uuid.UUID) (quiz Quiz, err error) {
+	quiz, err = s.resolveManagedQuiz(id, userID, PermissionEditContent, false)
+	if err != nil {
+		return
+	}
+
+	err = quiz.SoftDelete(userID)
+	if err != nil {
+		return
+	}
+
+	err = s.QuizRepository.UpdateQuiz(quiz)
+	return
+}
+
+// StartAttempt starts a student's attempt at a Quiz, or resumes the attempt
+// still in progress. Timed attempts that ran out expire and count toward the
+// attempt limit. Quizzes of Lessons the student's Cohort has not released yet
+// can't be attempted.
+func (s *QuizServiceImpl) StartAttempt(quizID uuid.UUID, studentID uuid.UUID) (attempt QuizAttempt, err error) {
+	quiz, lesson, err := s.resolveQuiz(quizID, true)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReadable(s.EnrollmentRepository, s.CohortRepository, lesson, studentID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	attempts, err := s.QuizRepository.ResolveAttemptsByQuizIDAndStudentID(quiz.ID, studentID)
+	if err != nil {
+		return
+	}
+
+	for _, previous := range attempts {
+		if !previous.IsInProgress() {
+			continue
+		}
+
+		if !previous.IsExpired() {
+			return previous.AttachQuiz(quiz), nil
+		}
+
+		err = s.expire(previous)
+		if err != nil {
+			return
+		}
+	}
+
+	if quiz.HasAttemptLimit() && int64(len(attempts)) >= quiz.MaxAttempts.Int64 {
+		return attempt, failure.Conflict(
+			"startAttempt",
+			"quiz attempt",
+			fmt.Sprintf("all %d attempts are used up", quiz.MaxAttempts.Int64))
+	}
+
+	attempt, err = attempt.NewQuizAttempt(quiz, studentID, len(attempts)+1)
+	if err != nil {
+		return attempt, failure.BadRequest(err)
+	}
+
+	err = s.QuizRepository.CreateAttempt(attempt)
+	if err != nil {
+		return
+	}
+
+	return attempt.AttachQuiz(quiz), nil
+}
+
+// SubmitAttempt scores and submits a student's QuizAttempt. Attempts submitted
+// after their time limit expire without a score.
+func (s *QuizServiceImpl) SubmitAttempt(id uuid.UUID, requestFormat QuizAttemptRequestFormat, studentID uuid.UUID) (attempt QuizAttempt, err error) {
+	attempt, quiz, err := s.resolveOwnedAttempt(id, studentID)
+	if err != nil {
+		return
+	}
+
+	if !attempt.IsInProgress() {
+		return attempt, failure.Conflict("submit", "quiz attempt", fmt.Sprintf("attempt is already %s", attempt.Status))
+	}
+
+	if attempt.IsExpired() {
+		err = s.expire(attempt)
+		if err != nil {
+			return
+		}
+
+		return attempt, failure.Conflict("submit", "quiz attempt", "time limit exceeded")
+	}
+
+	err = attempt.Submit(quiz, requestFormat)
+	if err != nil {
+		return attempt, failure.BadRequest(err)
+	}
+
+	err = s.QuizRepository.SubmitAttempt(attempt)
+	return
+}
+
+// UpdateQuiz updates a Quiz and replaces its questions. Quizzes that were
+// already attempted cannot be changed, as that would invalidate their scores.
+func (s *QuizServiceImpl) UpdateQuiz(id uuid.UUID, requestFormat QuizRequestFormat, userID uuid.UUID) (quiz Quiz, err error) {
+	quiz, err = s.resolveManagedQuiz(id, userID, PermissionEditContent, false)
+	if err != nil {
+		return
+	}
+
+	attempts, err := s.QuizRepository.CountAttemptsByQuizID(quiz.ID)
+	if err != nil {
+		return
+	}
+
+	if attempts > 0 {
+		return quiz, failure.Conflict("update", "quiz", "quiz was already attempted")
+	}
+
+	err = quiz.Update(requestFormat, userID)
+	if err != nil {
+		return quiz, failure.BadRequest(err)
+	}
+
+	err = s.QuizRepository.UpdateQuizWithQuestions(quiz)
+	return
+}
+
+// expire marks an in-progress QuizAttempt as expired.
+func (s *QuizServiceImpl) expire(attempt QuizAttempt) (err error) {
+	err = attempt.UpdateStatus(QuizAttemptStatusExpired)
+	if err != nil {
+		return
+	}
+
+	return s.QuizRepository.UpdateAttempt(attempt)
+}
+
+// resolveOwnedAttempt resolves a student's own QuizAttempt together with its
+// Quiz, as long as the Lesson of the Quiz is released to the student.
+func (s *QuizServiceImpl) resolveOwnedAttempt(id uuid.UUID, studentID uuid.UUID) (attempt QuizAttempt, quiz Quiz, err error) {
+	attempt, err = s.QuizRepository.ResolveAttemptByID(id)
+	if err != nil {
+		return
+	}
+
+	// other students' attempts are hidden rather than forbidden
+	if attempt.StudentID != studentID {
+		return attempt, quiz, failure.NotFound("quiz attempt")
+	}
+
+	quiz, lesson, err := s.resolveQuiz(attempt.QuizID, true)
+	if err != nil {
+		return
+	}
+
+	err = checkLessonReleased(s.EnrollmentRepository, s.CohortRepository, lesson, studentID)
+	return
+}
+
+// resolveManagedQuiz resolves a Quiz whose Course the given user has the
+// permission on.
+func (s *QuizServiceImpl) resolveManagedQuiz(id uuid.UUID, userID uuid.UUID, permission CoursePermission, withQuestions bool) (quiz Quiz, err error) {
+	quiz, _, err = s.resolveQuiz(id, withQuestions)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, quiz.CourseID, userID, permission)
+	return
+}
+
+// resolveQuiz resolves a Quiz that is neither deleted itself nor attached to
+// a deleted Lesson, together with that Lesson.
+func (s *QuizServiceImpl) resolveQuiz(id uuid.UUID, withQuestions bool) (quiz Quiz, lesson Lesson, err error) {
+	quiz, err = s.QuizRepository.ResolveQuizByID(id)
+	if err != nil {
+		return
+	}
+
+	if quiz.IsDeleted() {
+		return quiz, lesson, failure.NotFound("quiz")
+	}
+
+	lesson, err = resolveLesson(s.ModuleRepository, quiz.LessonID)
+	if err != nil {
+		return
+	}
+
+	if !withQuestions {
+		return
+	}
+
+	questions, err := s.QuizRepository.ResolveQuestionsByQuizIDs([]uuid.UUID{quiz.ID})
+	if err != nil {
+		return
+	}
+
+	quiz.AttachQuestions(questions)
+
+	return
+}
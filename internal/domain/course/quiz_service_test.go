@@ -0,0 +1,107 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizService(t *testing.T) {
+	config := &configs.Config{}
+	quiz := newQuiz(t)
+	lesson := course.Lesson{ID: quiz.LessonID, ModuleID: getRandomUUID(), CourseID: quiz.CourseID}
+
+	t.Run("students cannot list quizzes of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		s := course.ProvideQuizServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, nil, config)
+		studentID := getRandomUUID()
+
+		mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+		mockModuleRepo.EXPECT().ResolveModuleByID(lesson.ModuleID).Return(course.Module{ID: lesson.ModuleID}, nil)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 2)
+
+		_, err := s.ResolveQuizzesByLessonID(lesson.ID, studentID, shared.RoleStudent)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students cannot start quizzes of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		mockQuizRepo := course_mock.NewMockQuizRepository(ctrl)
+		s := course.ProvideQuizServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, mockQuizRepo, config)
+		studentID := getRandomUUID()
+
+		expectQuiz(mockModuleRepo, mockQuizRepo, quiz, lesson)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 2)
+
+		_, err := s.StartAttempt(quiz.ID, studentID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students cannot submit quizzes of unreleased lessons", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCohortRepo := course_mock.NewMockCohortRepository(ctrl)
+		mockEnrollmentRepo := course_mock.NewMockEnrollmentRepository(ctrl)
+		mockModuleRepo := course_mock.NewMockModuleRepository(ctrl)
+		mockQuizRepo := course_mock.NewMockQuizRepository(ctrl)
+		s := course.ProvideQuizServiceImpl(mockCohortRepo, nil, nil, mockEnrollmentRepo, mockModuleRepo, mockQuizRepo, config)
+		studentID := getRandomUUID()
+		attempt, err := course.QuizAttempt{}.NewQuizAttempt(quiz, studentID, 1)
+		assert.NoError(t, err)
+
+		mockQuizRepo.EXPECT().ResolveAttemptByID(attempt.ID).Return(attempt, nil)
+		expectQuiz(mockModuleRepo, mockQuizRepo, quiz, lesson)
+		expectUnreleasedLesson(t, mockEnrollmentRepo, mockCohortRepo, lesson, studentID, 1)
+
+		_, err = s.SubmitAttempt(attempt.ID, course.QuizAttemptRequestFormat{}, studentID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+// expectQuiz expects a Quiz to be resolved with its questions and Lesson.
+func expectQuiz(mockModuleRepo *course_mock.MockModuleRepository, mockQuizRepo *course_mock.MockQuizRepository, quiz course.Quiz, lesson course.Lesson) {
+	mockQuizRepo.EXPECT().ResolveQuizByID(quiz.ID).Return(quiz, nil)
+	mockModuleRepo.EXPECT().ResolveLessonByID(lesson.ID).Return(lesson, nil)
+	mockModuleRepo.EXPECT().ResolveModuleByID(lesson.ModuleID).Return(course.Module{ID: lesson.ModuleID}, nil)
+	mockQuizRepo.EXPECT().ResolveQuestionsByQuizIDs([]uuid.UUID{quiz.ID}).Return(quiz.Questions, nil)
+}
+
+// expectUnreleasedLesson enrolls a student actively in a Cohort that releases
+// the Lesson ten years after it starts. The Enrollment is resolved the given
+// number of times.
+func expectUnreleasedLesson(t *testing.T, mockEnrollmentRepo *course_mock.MockEnrollmentRepository, mockCohortRepo *course_mock.MockCohortRepository, lesson course.Lesson, studentID uuid.UUID, times int) {
+	cohort, err := course.Cohort{}.NewCohortFromRequestFormat(lesson.CourseID, newCohortRequest(), getRandomUUID())
+	assert.NoError(t, err)
+
+	enrollment := newEnrollment(lesson.CourseID, studentID, course.EnrollmentStatusActive)
+	enrollment.CohortID = nuuid.From(cohort.ID)
+
+	mockEnrollmentRepo.EXPECT().ResolveEnrollmentByCourseIDAndStudentID(lesson.CourseID, studentID).Return(enrollment, nil).Times(times)
+	mockCohortRepo.EXPECT().ResolveCohortByID(cohort.ID).Return(cohort, nil)
+	mockCohortRepo.EXPECT().ResolveLessonReleases(cohort.ID).Return([]course.CohortLessonRelease{
+		{CohortID: cohort.ID, LessonID: lesson.ID, DayOffset: 3650},
+	}, nil)
+}
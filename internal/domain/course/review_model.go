@@ -0,0 +1,264 @@
+package course
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+// reviewFlagThreshold is the number of users who have to flag a Review before
+// it is hidden from students, pending a look by the people teaching the Course.
+const reviewFlagThreshold = 3
+
+var (
+	errAlreadyReviewed = failure.Conflict("create", "review", "the course was already reviewed; edit the review instead")
+	errAlreadyFlagged  = failure.Conflict("flag", "review", "the review was already flagged by this user")
+)
+
+//// Course Ratings
+
+// CourseRating sums up the Reviews of a Course. It is kept on the Course so
+// that Courses can be sorted by it.
+type CourseRating struct {
+	RatingCount   int        `db:"rating_count"`
+	RatingAverage null.Float `db:"rating_average"`
+	Rating1Count  int        `db:"rating_1_count"`
+	Rating2Count  int        `db:"rating_2_count"`
+	Rating3Count  int        `db:"rating_3_count"`
+	Rating4Count  int        `db:"rating_4_count"`
+	Rating5Count  int        `db:"rating_5_count"`
+}
+
+// ToResponseFormat converts this CourseRating to its response format.
+func (r CourseRating) ToResponseFormat() CourseRatingResponseFormat {
+	return CourseRatingResponseFormat{
+		Average: r.RatingAverage,
+		Count:   r.RatingCount,
+		Distribution: map[int]int{
+			1: r.Rating1Count,
+			2: r.Rating2Count,
+			3: r.Rating3Count,
+			4: r.Rating4Count,
+			5: r.Rating5Count,
+		},
+	}
+}
+
+// CourseRatingResponseFormat represents a CourseRating's standard formatting for JSON serializing.
+type CourseRatingResponseFormat struct {
+	Average      null.Float  `json:"average" swaggertype:"number"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"`
+}
+
+//// Reviews
+
+// Review is a student's rating and opinion of a Course they finished. Each
+// student reviews a Course at most once, and can edit the Review later.
+type Review struct {
+	ID        uuid.UUID   `db:"id" validate:"required"`
+	CourseID  uuid.UUID   `db:"course_id" validate:"required"`
+	StudentID uuid.UUID   `db:"student_id" validate:"required"`
+	Rating    int         `db:"rating" validate:"required,min=1,max=5"`
+	Body      string      `db:"body" validate:"max=5000"`
+	Reply     null.String `db:"reply"`
+	RepliedAt null.Time   `db:"replied_at"`
+	RepliedBy nuuid.NUUID `db:"replied_by"`
+	FlagCount int         `db:"flag_count"`
+	CreatedAt time.Time   `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt null.Time   `db:"updated_at"`
+	UpdatedBy nuuid.NUUID `db:"updated_by"`
+}
+
+// ReviewQueryParameters filters the Reviews of a Course.
+type ReviewQueryParameters struct {
+	CourseID uuid.UUID
+	Rating   int `validate:"min=0,max=5"`
+	// IncludeHidden includes the Reviews hidden after being flagged.
+	IncludeHidden bool
+	// FlaggedOnly limits the Reviews to those flagged at least once.
+	FlaggedOnly bool
+	Page        int `validate:"min=0"`
+	Limit       int `validate:"min=1,max=100"`
+}
+
+// Offset returns the number of Reviews skipped before the current page.
+func (p ReviewQueryParameters) Offset() int {
+	return p.Page * p.Limit
+}
+
+// Validate validates the query parameters.
+func (p *ReviewQueryParameters) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(p)
+}
+
+// NewReviewFromRequestFormat creates a Review of a Course by a student.
+func (r Review) NewReviewFromRequestFormat(courseID uuid.UUID, req ReviewRequestFormat, studentID uuid.UUID) (newReview Review, err error) {
+	reviewID, _ := uuid.NewV4()
+	newReview = Review{
+		ID:        reviewID,
+		CourseID:  courseID,
+		StudentID: studentID,
+		Rating:    req.Rating,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+		CreatedBy: studentID,
+	}
+
+	err = newReview.Validate()
+	return
+}
+
+// ClearFlags dismisses the flags of a Review, showing it to students again.
+func (r *Review) ClearFlags() {
+	r.FlagCount = 0
+}
+
+// IsHidden checks whether a Review was flagged by enough users to be hidden
+// from students.
+func (r *Review) IsHidden() bool {
+	return r.FlagCount >= reviewFlagThreshold
+}
+
+// SetReply sets the reply of the people teaching the Course to a Review. An
+// empty reply removes it.
+func (r *Review) SetReply(req ReviewReplyRequestFormat, userID uuid.UUID) {
+	if req.Reply == "" {
+		r.Reply = null.String{}
+		r.RepliedAt = null.Time{}
+		r.RepliedBy = nuuid.NUUID{}
+		return
+	}
+
+	r.Reply = null.StringFrom(req.Reply)
+	r.RepliedAt = null.TimeFrom(time.Now())
+	r.RepliedBy = nuuid.From(userID)
+}
+
+// Update updates a Review.
+func (r *Review) Update(req ReviewRequestFormat, userID uuid.UUID) (err error) {
+	r.Rating = req.Rating
+	r.Body = req.Body
+	r.UpdatedAt = null.TimeFrom(time.Now())
+	r.UpdatedBy = nuuid.From(userID)
+
+	return r.Validate()
+}
+
+// Validate validates the entity.
+func (r *Review) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(r)
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (r Review) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToResponseFormat())
+}
+
+// ToResponseFormat converts this Review to its response format.
+func (r Review) ToResponseFormat() ReviewResponseFormat {
+	return ReviewResponseFormat{
+		ID:        r.ID,
+		CourseID:  r.CourseID,
+		StudentID: r.StudentID,
+		Rating:    r.Rating,
+		Body:      r.Body,
+		Reply:     r.Reply,
+		RepliedAt: r.RepliedAt,
+		RepliedBy: r.RepliedBy.Ptr(),
+		FlagCount: r.FlagCount,
+		Hidden:    r.IsHidden(),
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// ReviewFlag records a user reporting a Review as abusive.
+type ReviewFlag struct {
+	ReviewID  uuid.UUID `db:"review_id" validate:"required"`
+	UserID    uuid.UUID `db:"user_id" validate:"required"`
+	Reason    string    `db:"reason" validate:"required,max=255"`
+	CreatedAt time.Time `db:"created_at" validate:"required"`
+}
+
+// NewReviewFlag creates a ReviewFlag.
+func NewReviewFlag(review Review, req ReviewFlagRequestFormat, userID uuid.UUID) (flag ReviewFlag, err error) {
+	if review.StudentID == userID {
+		return flag, failure.BadRequestFromString("you cannot flag your own review")
+	}
+
+	flag = ReviewFlag{
+		ReviewID:  review.ID,
+		UserID:    userID,
+		Reason:    req.Reason,
+		CreatedAt: time.Now(),
+	}
+
+	err = shared.GetValidator().Struct(flag)
+	if err != nil {
+		return flag, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (f ReviewFlag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.ToResponseFormat())
+}
+
+// ToResponseFormat converts this ReviewFlag to its response format.
+func (f ReviewFlag) ToResponseFormat() ReviewFlagResponseFormat {
+	return ReviewFlagResponseFormat{
+		ReviewID:  f.ReviewID,
+		Reason:    f.Reason,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+// ReviewRequestFormat represents a Review's standard formatting for JSON deserializing.
+type ReviewRequestFormat struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5" example:"5"`
+	Body   string `json:"body" validate:"max=5000" example:"Clear explanations and useful exercises."`
+}
+
+// ReviewReplyRequestFormat represents a reply to a Review. An empty reply removes it.
+type ReviewReplyRequestFormat struct {
+	Reply string `json:"reply" validate:"max=5000"`
+}
+
+// ReviewFlagRequestFormat represents a ReviewFlag's standard formatting for JSON deserializing.
+type ReviewFlagRequestFormat struct {
+	Reason string `json:"reason" validate:"required,max=255" example:"Insults the instructor"`
+}
+
+// ReviewResponseFormat represents a Review's standard formatting for JSON serializing.
+type ReviewResponseFormat struct {
+	ID        uuid.UUID   `json:"id"`
+	CourseID  uuid.UUID   `json:"courseID"`
+	StudentID uuid.UUID   `json:"studentID"`
+	Rating    int         `json:"rating"`
+	Body      string      `json:"body"`
+	Reply     null.String `json:"reply" swaggertype:"string"`
+	RepliedAt null.Time   `json:"repliedAt"`
+	RepliedBy *uuid.UUID  `json:"repliedBy"`
+	FlagCount int         `json:"flagCount"`
+	Hidden    bool        `json:"hidden"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt null.Time   `json:"updatedAt"`
+}
+
+// ReviewFlagResponseFormat represents a ReviewFlag's standard formatting for JSON serializing.
+type ReviewFlagResponseFormat struct {
+	ReviewID  uuid.UUID `json:"reviewID"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,69 @@
+package course_test
+
+import (
+	"testing"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCourseRatingResponseFormat(t *testing.T) {
+	rating := course.CourseRating{
+		RatingCount:   3,
+		RatingAverage: null.FloatFrom(4.33),
+		Rating4Count:  2,
+		Rating5Count:  1,
+	}
+
+	got := rating.ToResponseFormat()
+
+	assert.Equal(t, 3, got.Count)
+	assert.Equal(t, 4.33, got.Average.Float64)
+	assert.Equal(t, map[int]int{1: 0, 2: 0, 3: 0, 4: 2, 5: 1}, got.Distribution)
+	assert.False(t, course.CourseRating{}.ToResponseFormat().Average.Valid, "no reviews, no average")
+}
+
+func TestReviewLifecycle(t *testing.T) {
+	courseID, studentID, teacherID := getRandomUUID(), getRandomUUID(), getRandomUUID()
+
+	t.Run("the rating has to be between 1 and 5", func(t *testing.T) {
+		_, err := course.Review{}.NewReviewFromRequestFormat(courseID, course.ReviewRequestFormat{Rating: 6}, studentID)
+		assert.Error(t, err)
+	})
+
+	review, err := course.Review{}.NewReviewFromRequestFormat(courseID, course.ReviewRequestFormat{Rating: 4, Body: "Good pacing."}, studentID)
+	assert.NoError(t, err)
+	assert.False(t, review.IsHidden())
+
+	assert.NoError(t, review.Update(course.ReviewRequestFormat{Rating: 5}, studentID))
+	assert.Equal(t, 5, review.Rating)
+	assert.True(t, review.UpdatedAt.Valid)
+
+	review.SetReply(course.ReviewReplyRequestFormat{Reply: "Thank you!"}, teacherID)
+	assert.Equal(t, "Thank you!", review.Reply.String)
+	assert.Equal(t, teacherID, *review.RepliedBy.Ptr())
+
+	review.SetReply(course.ReviewReplyRequestFormat{}, teacherID)
+	assert.False(t, review.Reply.Valid)
+	assert.False(t, review.RepliedAt.Valid)
+	assert.False(t, review.RepliedBy.Valid)
+
+	t.Run("reviews are hidden once flagged enough", func(t *testing.T) {
+		flagged := review
+		flagged.FlagCount = 3
+		assert.True(t, flagged.IsHidden())
+		assert.True(t, flagged.ToResponseFormat().Hidden)
+
+		flagged.ClearFlags()
+		assert.False(t, flagged.IsHidden())
+	})
+
+	t.Run("students cannot flag their own review", func(t *testing.T) {
+		_, err := course.NewReviewFlag(review, course.ReviewFlagRequestFormat{Reason: "spam"}, studentID)
+		assert.Error(t, err)
+
+		_, err = course.NewReviewFlag(review, course.ReviewFlagRequestFormat{Reason: "spam"}, getRandomUUID())
+		assert.NoError(t, err)
+	})
+}
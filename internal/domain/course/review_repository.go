@@ -0,0 +1,341 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source review_repository.go -destination mock/review_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	reviewQueries = struct {
+		selectReview       string
+		insertReview       string
+		updateReview       string
+		refreshRating      string
+		insertFlag         string
+		incrementFlagCount string
+		deleteFlags        string
+	}{
+		selectReview: `
+			SELECT
+				id,
+				course_id,
+				student_id,
+				rating,
+				body,
+				reply,
+				replied_at,
+				replied_by,
+				flag_count,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			FROM course_reviews
+		`,
+
+		insertReview: `
+			INSERT INTO course_reviews (
+				id,
+				course_id,
+				student_id,
+				rating,
+				body,
+				reply,
+				replied_at,
+				replied_by,
+				flag_count,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			) VALUES (
+				:id,
+				:course_id,
+				:student_id,
+				:rating,
+				:body,
+				:reply,
+				:replied_at,
+				:replied_by,
+				:flag_count,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by
+			)
+		`,
+
+		updateReview: `
+			UPDATE course_reviews
+			SET
+				rating = :rating,
+				body = :body,
+				reply = :reply,
+				replied_at = :replied_at,
+				replied_by = :replied_by,
+				flag_count = :flag_count,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+
+		refreshRating: `
+			UPDATE courses c
+			JOIN (
+				SELECT
+					COUNT(id) AS rating_count,
+					AVG(rating) AS rating_average,
+					COALESCE(SUM(rating = 1), 0) AS rating_1_count,
+					COALESCE(SUM(rating = 2), 0) AS rating_2_count,
+					COALESCE(SUM(rating = 3), 0) AS rating_3_count,
+					COALESCE(SUM(rating = 4), 0) AS rating_4_count,
+					COALESCE(SUM(rating = 5), 0) AS rating_5_count
+				FROM course_reviews
+				WHERE course_id = ?
+			) r
+			SET
+				c.rating_count = r.rating_count,
+				c.rating_average = r.rating_average,
+				c.rating_1_count = r.rating_1_count,
+				c.rating_2_count = r.rating_2_count,
+				c.rating_3_count = r.rating_3_count,
+				c.rating_4_count = r.rating_4_count,
+				c.rating_5_count = r.rating_5_count
+			WHERE c.id = ?
+		`,
+
+		insertFlag: `
+			INSERT IGNORE INTO course_review_flags (
+				review_id,
+				user_id,
+				reason,
+				created_at
+			) VALUES (
+				:review_id,
+				:user_id,
+				:reason,
+				:created_at
+			)
+		`,
+
+		incrementFlagCount: `
+			UPDATE course_reviews
+			SET flag_count = flag_count + 1
+			WHERE id = ?
+		`,
+
+		deleteFlags: `
+			DELETE FROM course_review_flags
+			WHERE review_id = ?
+		`,
+	}
+)
+
+// ReviewRepository is the repository for Reviews of Courses.
+type ReviewRepository interface {
+	ClearFlags(review Review) (err error)
+	CreateFlag(flag ReviewFlag) (err error)
+	CreateReview(review Review) (err error)
+	ResolveReviewByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (review Review, err error)
+	ResolveReviewByID(id uuid.UUID) (review Review, err error)
+	ResolveReviews(params ReviewQueryParameters) (reviews []Review, err error)
+	UpdateReview(review Review) (err error)
+}
+
+// ReviewRepositoryMySQL is the MySQL-backed implementation of ReviewRepository.
+type ReviewRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideReviewRepositoryMySQL is the provider for this repository.
+func ProvideReviewRepositoryMySQL(db *infras.MySQLConn) *ReviewRepositoryMySQL {
+	s := new(ReviewRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// ClearFlags deletes the flags of a Review and resets its flag count.
+func (r *ReviewRepositoryMySQL) ClearFlags(review Review) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		_, err := tx.Exec(reviewQueries.deleteFlags, review.ID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txExecNamed(tx, reviewQueries.updateReview, review); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateFlag records a user flagging a Review. It fails with a conflict when
+// the user flagged the Review already.
+func (r *ReviewRepositoryMySQL) CreateFlag(flag ReviewFlag) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		result, err := tx.NamedExec(reviewQueries.insertFlag, flag)
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if inserted == 0 {
+			e <- errAlreadyFlagged
+			return
+		}
+
+		_, err = tx.Exec(reviewQueries.incrementFlagCount, flag.ReviewID.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateReview creates a Review and refreshes the CourseRating of its Course.
+func (r *ReviewRepositoryMySQL) CreateReview(review Review) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, reviewQueries.insertReview, review); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txRefreshRating(tx, review.CourseID); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveReviewByCourseIDAndStudentID resolves the Review a student left on a Course.
+func (r *ReviewRepositoryMySQL) ResolveReviewByCourseIDAndStudentID(courseID uuid.UUID, studentID uuid.UUID) (review Review, err error) {
+	err = r.DB.Read.Get(
+		&review,
+		reviewQueries.selectReview+" WHERE course_id = ? AND student_id = ?",
+		courseID.String(),
+		studentID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("review")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveReviewByID resolves a Review by its ID.
+func (r *ReviewRepositoryMySQL) ResolveReviewByID(id uuid.UUID) (review Review, err error) {
+	err = r.DB.Read.Get(&review, reviewQueries.selectReview+" WHERE id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("review")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveReviews resolves a page of the Reviews of a Course, newest first.
+func (r *ReviewRepositoryMySQL) ResolveReviews(params ReviewQueryParameters) (reviews []Review, err error) {
+	query := reviewQueries.selectReview + " WHERE course_id = ?"
+	args := []interface{}{params.CourseID.String()}
+
+	if params.Rating != 0 {
+		query += " AND rating = ?"
+		args = append(args, params.Rating)
+	}
+
+	if !params.IncludeHidden {
+		query += " AND flag_count < ?"
+		args = append(args, reviewFlagThreshold)
+	}
+
+	if params.FlaggedOnly {
+		query += " AND flag_count > 0"
+	}
+
+	query += " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	args = append(args, params.Limit, params.Offset())
+
+	err = r.DB.Read.Select(&reviews, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// UpdateReview updates a Review and refreshes the CourseRating of its Course.
+func (r *ReviewRepositoryMySQL) UpdateReview(review Review) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, reviewQueries.updateReview, review); err != nil {
+			e <- err
+			return
+		}
+
+		if err := r.txRefreshRating(tx, review.CourseID); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txRefreshRating recomputes the CourseRating of a Course from its Reviews.
+func (r *ReviewRepositoryMySQL) txRefreshRating(tx *sqlx.Tx, courseID uuid.UUID) (err error) {
+	_, err = tx.Exec(reviewQueries.refreshRating, courseID.String(), courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *ReviewRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
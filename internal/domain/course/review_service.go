@@ -0,0 +1,232 @@
+package course
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// ReviewService is the service interface for Reviews of Courses.
+type ReviewService interface {
+	ClearReviewFlags(id uuid.UUID, userID uuid.UUID) (review Review, err error)
+	CreateReview(courseID uuid.UUID, requestFormat ReviewRequestFormat, studentID uuid.UUID) (review Review, err error)
+	FlagReview(id uuid.UUID, requestFormat ReviewFlagRequestFormat, userID uuid.UUID, role string) (flag ReviewFlag, err error)
+	ReplyToReview(id uuid.UUID, requestFormat ReviewReplyRequestFormat, userID uuid.UUID) (review Review, err error)
+	ResolveMyReview(courseID uuid.UUID, studentID uuid.UUID) (review Review, err error)
+	ResolveReviews(params ReviewQueryParameters, userID uuid.UUID, role string) (reviews []Review, err error)
+	UpdateMyReview(courseID uuid.UUID, requestFormat ReviewRequestFormat, studentID uuid.UUID) (review Review, err error)
+}
+
+// ReviewServiceImpl is the service implementation for Reviews of Courses.
+type ReviewServiceImpl struct {
+	AttendanceRepository   AttendanceRepository
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	EnrollmentRepository   EnrollmentRepository
+	LiveSessionRepository  LiveSessionRepository
+	ProgressRepository     ProgressRepository
+	ReviewRepository       ReviewRepository
+	Config                 *configs.Config
+}
+
+// ProvideReviewServiceImpl is the provider for this service.
+func ProvideReviewServiceImpl(
+	attendanceRepository AttendanceRepository,
+	collaboratorRepository CollaboratorRepository,
+	courseRepository CourseRepository,
+	enrollmentRepository EnrollmentRepository,
+	liveSessionRepository LiveSessionRepository,
+	progressRepository ProgressRepository,
+	reviewRepository ReviewRepository,
+	config *configs.Config) *ReviewServiceImpl {
+	s := new(ReviewServiceImpl)
+	s.AttendanceRepository = attendanceRepository
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.EnrollmentRepository = enrollmentRepository
+	s.LiveSessionRepository = liveSessionRepository
+	s.ProgressRepository = progressRepository
+	s.ReviewRepository = reviewRepository
+	s.Config = config
+
+	return s
+}
+
+// ClearReviewFlags dismisses the flags of a Review, showing it to students
+// again.
+func (s *ReviewServiceImpl) ClearReviewFlags(id uuid.UUID, userID uuid.UUID) (review Review, err error) {
+	review, err = s.resolveManagedReview(id, userID)
+	if err != nil {
+		return
+	}
+
+	review.ClearFlags()
+	err = s.ReviewRepository.ClearFlags(review)
+	return
+}
+
+// CreateReview creates the Review of a Course by a student who finished it.
+func (s *ReviewServiceImpl) CreateReview(courseID uuid.UUID, requestFormat ReviewRequestFormat, studentID uuid.UUID) (review Review, err error) {
+	review, err = Review{}.NewReviewFromRequestFormat(courseID, requestFormat, studentID)
+	if err != nil {
+		return review, failure.BadRequest(err)
+	}
+
+	err = s.checkCompletion(courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	_, err = s.ReviewRepository.ResolveReviewByCourseIDAndStudentID(courseID, studentID)
+	if err == nil {
+		return review, errAlreadyReviewed
+	}
+
+	if failure.GetCode(err) != http.StatusNotFound {
+		return
+	}
+
+	err = s.ReviewRepository.CreateReview(review)
+	return
+}
+
+// FlagReview reports a Review as abusive. Reviews flagged by enough users are
+// hidden from students until the people teaching the Course look at them.
+func (s *ReviewServiceImpl) FlagReview(id uuid.UUID, requestFormat ReviewFlagRequestFormat, userID uuid.UUID, role string) (flag ReviewFlag, err error) {
+	review, err := s.ReviewRepository.ResolveReviewByID(id)
+	if err != nil {
+		return
+	}
+
+	_, err = s.resolveReviewedCourse(review.CourseID, role)
+	if err != nil {
+		return
+	}
+
+	flag, err = NewReviewFlag(review, requestFormat, userID)
+	if err != nil {
+		return
+	}
+
+	err = s.ReviewRepository.CreateFlag(flag)
+	return
+}
+
+// ReplyToReview sets the reply of the people teaching a Course to one of its
+// Reviews.
+func (s *ReviewServiceImpl) ReplyToReview(id uuid.UUID, requestFormat ReviewReplyRequestFormat, userID uuid.UUID) (review Review, err error) {
+	err = shared.GetValidator().Struct(requestFormat)
+	if err != nil {
+		return review, failure.BadRequest(err)
+	}
+
+	review, err = s.resolveManagedReview(id, userID)
+	if err != nil {
+		return
+	}
+
+	review.SetReply(requestFormat, userID)
+	err = s.ReviewRepository.UpdateReview(review)
+	return
+}
+
+// ResolveMyReview resolves the Review a student left on a Course.
+func (s *ReviewServiceImpl) ResolveMyReview(courseID uuid.UUID, studentID uuid.UUID) (review Review, err error) {
+	return s.ReviewRepository.ResolveReviewByCourseIDAndStudentID(courseID, studentID)
+}
+
+// ResolveReviews resolves the Reviews of a Course. Reviews hidden after being
+// flagged are only listed, on request, to the people allowed to reply to them.
+func (s *ReviewServiceImpl) ResolveReviews(params ReviewQueryParameters, userID uuid.UUID, role string) (reviews []Review, err error) {
+	err = params.Validate()
+	if err != nil {
+		return reviews, failure.BadRequest(err)
+	}
+
+	if params.IncludeHidden || params.FlaggedOnly {
+		if role != shared.RoleTeacher {
+			return reviews, failure.Forbidden("only the people teaching the course can see flagged reviews")
+		}
+
+		_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, params.CourseID, userID, PermissionReplyReviews)
+	} else {
+		_, err = s.resolveReviewedCourse(params.CourseID, role)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return s.ReviewRepository.ResolveReviews(params)
+}
+
+// UpdateMyReview updates the Review a student left on a Course.
+func (s *ReviewServiceImpl) UpdateMyReview(courseID uuid.UUID, requestFormat ReviewRequestFormat, studentID uuid.UUID) (review Review, err error) {
+	review, err = s.ReviewRepository.ResolveReviewByCourseIDAndStudentID(courseID, studentID)
+	if err != nil {
+		return
+	}
+
+	err = review.Update(requestFormat, studentID)
+	if err != nil {
+		return review, failure.BadRequest(err)
+	}
+
+	err = s.ReviewRepository.UpdateReview(review)
+	return
+}
+
+// checkCompletion makes sure a student finished a Course visible to students.
+func (s *ReviewServiceImpl) checkCompletion(courseID uuid.UUID, studentID uuid.UUID) (err error) {
+	_, err = s.resolveReviewedCourse(courseID, shared.RoleStudent)
+	if err != nil {
+		return
+	}
+
+	progress, _, err := resolveCourseCompletion(
+		s.AttendanceRepository,
+		s.EnrollmentRepository,
+		s.LiveSessionRepository,
+		s.ProgressRepository,
+		courseID,
+		studentID)
+	if err != nil {
+		return
+	}
+
+	if !progress.IsComplete() {
+		return failure.Forbidden("only students who finished the course can review it")
+	}
+
+	return nil
+}
+
+// resolveManagedReview resolves a Review of a Course the given user may reply
+// to Reviews of.
+func (s *ReviewServiceImpl) resolveManagedReview(id uuid.UUID, userID uuid.UUID) (review Review, err error) {
+	review, err = s.ReviewRepository.ResolveReviewByID(id)
+	if err != nil {
+		return
+	}
+
+	_, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, review.CourseID, userID, PermissionReplyReviews)
+	return
+}
+
+// resolveReviewedCourse resolves a non-deleted Course. Students only see the
+// Reviews of Courses visible to them.
+func (s *ReviewServiceImpl) resolveReviewedCourse(courseID uuid.UUID, role string) (course Course, err error) {
+	course, err = s.CourseRepository.ResolveCourseByID(courseID)
+	if err != nil {
+		return
+	}
+
+	if course.IsDeleted() || (role != shared.RoleTeacher && !course.IsVisibleToStudents()) {
+		return course, failure.NotFound("course")
+	}
+
+	return
+}
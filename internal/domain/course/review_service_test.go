@@ -0,0 +1,89 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/golang/mock/gomock"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewService(t *testing.T) {
+	config := &configs.Config{}
+	publishedCourse := newDraftCourse()
+	publishedCourse.Status = course.CourseStatusPublished
+	publishedCourse.PublishedVersion = null.IntFrom(1)
+	studentID := getRandomUUID()
+	req := course.ReviewRequestFormat{Rating: 5, Body: "Loved it."}
+
+	lessonProgress := func(completed int) []course.LessonProgress {
+		var progress []course.LessonProgress
+		for i, lesson := range publishedCourse.Modules[0].Lessons {
+			p := course.LessonProgress{}.NewLessonProgress(lesson, studentID)
+			if i < completed {
+				p.CompletedAt = null.TimeFrom(time.Now())
+			}
+
+			progress = append(progress, p)
+		}
+
+		return progress
+	}
+
+	newService := func(ctrl *gomock.Controller, completed int) (*course.ReviewServiceImpl, *course_mock.MockReviewRepository) {
+		mockAttendanceRepo := course_mock.NewMockAttendanceRepository(ctrl)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockProgressRepo := course_mock.NewMockProgressRepository(ctrl)
+		mockReviewRepo := course_mock.NewMockReviewRepository(ctrl)
+		mockCourseRepo.EXPECT().ResolveCourseByID(publishedCourse.ID).Return(publishedCourse, nil)
+		mockProgressRepo.EXPECT().CountActiveLessons(publishedCourse.ID).Return(2, nil)
+		mockProgressRepo.EXPECT().ResolveLessonProgress(publishedCourse.ID, studentID).Return(lessonProgress(completed), nil)
+		mockAttendanceRepo.EXPECT().ResolveCompletionRules(publishedCourse.ID).Return(course.CompletionRules{}, failure.NotFound("completionRules"))
+
+		return course.ProvideReviewServiceImpl(mockAttendanceRepo, nil, mockCourseRepo, nil, nil, mockProgressRepo, mockReviewRepo, config), mockReviewRepo
+	}
+
+	t.Run("students review a course they finished", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, mockReviewRepo := newService(ctrl, 2)
+		mockReviewRepo.EXPECT().ResolveReviewByCourseIDAndStudentID(publishedCourse.ID, studentID).Return(course.Review{}, failure.NotFound("review"))
+		mockReviewRepo.EXPECT().CreateReview(gomock.Any()).Return(nil)
+
+		review, err := s.CreateReview(publishedCourse.ID, req, studentID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, review.Rating)
+		assert.Equal(t, studentID, review.StudentID)
+	})
+
+	t.Run("students cannot review a course they did not finish", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, _ := newService(ctrl, 1)
+
+		_, err := s.CreateReview(publishedCourse.ID, req, studentID)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("students review a course once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, mockReviewRepo := newService(ctrl, 2)
+		mockReviewRepo.EXPECT().ResolveReviewByCourseIDAndStudentID(publishedCourse.ID, studentID).Return(course.Review{}, nil)
+
+		_, err := s.CreateReview(publishedCourse.ID, req, studentID)
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
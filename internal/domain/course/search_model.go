@@ -0,0 +1,103 @@
+package course
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/guregu/null"
+)
+
+//// CourseSearchParameters
+
+// CourseSearchParameters narrows down a full-text search of Courses. An empty
+// query matches every Course that passes the filters.
+type CourseSearchParameters struct {
+	Query       string `validate:"max=255"`
+	TeacherID   nuuid.NUUID
+	Tags        []string       `validate:"dive,required,max=64"`
+	Statuses    []CourseStatus `validate:"dive,oneof=draft in_review published archived"`
+	CreatedFrom null.Time
+	CreatedTo   null.Time
+	VisibleOnly bool
+	Page        int `validate:"min=0"`
+	Limit       int `validate:"min=1,max=100"`
+}
+
+// Offset returns the number of results skipped before the current page.
+func (p CourseSearchParameters) Offset() int {
+	return p.Page * p.Limit
+}
+
+// Validate validates the search parameters.
+func (p *CourseSearchParameters) Validate() (err error) {
+	if p.CreatedFrom.Valid && p.CreatedTo.Valid && p.CreatedTo.Time.Before(p.CreatedFrom.Time) {
+		return errors.New("createdTo must not be before createdFrom")
+	}
+
+	validator := shared.GetValidator()
+	return validator.Struct(p)
+}
+
+//// CourseSearchResult
+
+// CourseSearchResult is a page of Courses matching a search, ranked by
+// relevance, with facet counts over all matching Courses.
+type CourseSearchResult struct {
+	Courses []CourseSearchHit
+	Total   int
+	Facets  CourseSearchFacets
+}
+
+// CourseSearchHit is a Course matching a search with its relevance score.
+type CourseSearchHit struct {
+	Course
+	Relevance float64 `db:"relevance"`
+}
+
+// CourseSearchFacets counts the Courses matching a search by teacher, tag and
+// status.
+type CourseSearchFacets struct {
+	Teachers []FacetCount `json:"teachers"`
+	Tags     []FacetCount `json:"tags"`
+	Statuses []FacetCount `json:"statuses"`
+}
+
+// FacetCount is the number of matching Courses sharing a value.
+type FacetCount struct {
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+func (r CourseSearchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToResponseFormat())
+}
+
+func (r CourseSearchResult) ToResponseFormat() CourseSearchResultResponseFormat {
+	resp := CourseSearchResultResponseFormat{
+		Courses: make([]CourseSearchHitResponseFormat, 0, len(r.Courses)),
+		Total:   r.Total,
+		Facets:  r.Facets,
+	}
+
+	for _, hit := range r.Courses {
+		resp.Courses = append(resp.Courses, CourseSearchHitResponseFormat{
+			CourseResponseFormat: hit.Course.ToResponseFormat(),
+			Relevance:            hit.Relevance,
+		})
+	}
+
+	return resp
+}
+
+type CourseSearchResultResponseFormat struct {
+	Courses []CourseSearchHitResponseFormat `json:"courses"`
+	Total   int                             `json:"total"`
+	Facets  CourseSearchFacets              `json:"facets"`
+}
+
+type CourseSearchHitResponseFormat struct {
+	CourseResponseFormat
+	Relevance float64 `json:"relevance"`
+}
@@ -0,0 +1,224 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source search_repository.go -destination mock/search_repository_mock.go -package course_mock
+
+import (
+	"fmt"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	searchQueries = struct {
+		selectCourseHits      string
+		countCourses          string
+		selectTeacherFacets   string
+		selectTagFacets       string
+		selectStatusFacets    string
+		match                 string
+		filterByTags          string
+		filterVisibleStudents string
+	}{
+		selectCourseHits: `
+			SELECT
+				id,
+				user_id,
+				title,
+				content,
+				seat_limit,
+				status,
+				published_version,
+				rating_count,
+				rating_average,
+				rating_1_count,
+				rating_2_count,
+				rating_3_count,
+				rating_4_count,
+				rating_5_count,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by,
+				deleted_at,
+				deleted_by,
+				%s AS relevance
+			FROM courses
+		`,
+
+		countCourses: `
+			SELECT COUNT(id)
+			FROM courses
+		`,
+
+		selectTeacherFacets: `
+			SELECT user_id AS value, COUNT(id) AS count
+			FROM courses
+			%s
+			GROUP BY user_id
+			ORDER BY count DESC, value
+		`,
+
+		selectTagFacets: `
+			SELECT t.name AS value, COUNT(ct.course_id) AS count
+			FROM tags t
+			JOIN course_tags ct ON ct.tag_id = t.id
+			WHERE ct.course_id IN (SELECT id FROM courses %s)
+			GROUP BY t.name
+			ORDER BY count DESC, value
+		`,
+
+		selectStatusFacets: `
+			SELECT status AS value, COUNT(id) AS count
+			FROM courses
+			%s
+			GROUP BY status
+			ORDER BY count DESC, value
+		`,
+
+		match: "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)",
+
+		filterByTags: `
+			id IN (
+				SELECT ct.course_id
+				FROM course_tags ct
+				JOIN tags t ON t.id = ct.tag_id
+				WHERE t.name IN (?)
+			)
+		`,
+
+		filterVisibleStudents: "published_version IS NOT NULL AND status <> 'archived'",
+	}
+)
+
+// SearchRepository is the repository for full-text searches of Courses.
+type SearchRepository interface {
+	SearchCourses(params CourseSearchParameters) (result CourseSearchResult, err error)
+}
+
+// SearchRepositoryMySQL is the MySQL-backed implementation of SearchRepository.
+type SearchRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideSearchRepositoryMySQL is the provider for this repository.
+func ProvideSearchRepositoryMySQL(db *infras.MySQLConn) *SearchRepositoryMySQL {
+	s := new(SearchRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// SearchCourses resolves a page of Courses matching a search, most relevant
+// first, together with the total number of matches and their facet counts.
+func (r *SearchRepositoryMySQL) SearchCourses(params CourseSearchParameters) (result CourseSearchResult, err error) {
+	conditions, conditionArgs := r.searchConditions(params)
+
+	relevance, args := "0", []interface{}{}
+	if params.Query != "" {
+		relevance = searchQueries.match
+		args = append(args, params.Query)
+	}
+
+	query := fmt.Sprintf(searchQueries.selectCourseHits, relevance) + conditions +
+		" ORDER BY relevance DESC, created_at DESC LIMIT ? OFFSET ?"
+	args = append(append(args, conditionArgs...), params.Limit, params.Offset())
+
+	err = r.selectIn(&result.Courses, query, args...)
+	if err != nil {
+		return
+	}
+
+	err = r.getIn(&result.Total, searchQueries.countCourses+conditions, conditionArgs...)
+	if err != nil {
+		return
+	}
+
+	err = r.selectIn(&result.Facets.Teachers, fmt.Sprintf(searchQueries.selectTeacherFacets, conditions), conditionArgs...)
+	if err != nil {
+		return
+	}
+
+	err = r.selectIn(&result.Facets.Tags, fmt.Sprintf(searchQueries.selectTagFacets, conditions), conditionArgs...)
+	if err != nil {
+		return
+	}
+
+	err = r.selectIn(&result.Facets.Statuses, fmt.Sprintf(searchQueries.selectStatusFacets, conditions), conditionArgs...)
+	return
+}
+
+// Internal Functions
+
+// searchConditions builds the WHERE clause shared by the search, count and
+// facet queries.
+func (r *SearchRepositoryMySQL) searchConditions(params CourseSearchParameters) (conditions string, args []interface{}) {
+	conditions = " WHERE deleted_at IS NULL"
+
+	if params.Query != "" {
+		conditions += " AND " + searchQueries.match
+		args = append(args, params.Query)
+	}
+
+	if params.TeacherID.Valid {
+		conditions += " AND user_id = ?"
+		args = append(args, params.TeacherID.UUID.String())
+	}
+
+	if len(params.Tags) > 0 {
+		conditions += " AND " + searchQueries.filterByTags
+		args = append(args, params.Tags)
+	}
+
+	if len(params.Statuses) > 0 {
+		conditions += " AND status IN (?)"
+		args = append(args, params.Statuses)
+	}
+
+	if params.CreatedFrom.Valid {
+		conditions += " AND created_at >= ?"
+		args = append(args, params.CreatedFrom.Time)
+	}
+
+	if params.CreatedTo.Valid {
+		conditions += " AND created_at <= ?"
+		args = append(args, params.CreatedTo.Time)
+	}
+
+	if params.VisibleOnly {
+		conditions += " AND " + searchQueries.filterVisibleStudents
+	}
+
+	return
+}
+
+func (r *SearchRepositoryMySQL) getIn(dest interface{}, query string, args ...interface{}) (err error) {
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Get(dest, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+func (r *SearchRepositoryMySQL) selectIn(dest interface{}, query string, args ...interface{}) (err error) {
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(dest, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
@@ -0,0 +1,331 @@
+package course
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
+)
+
+//// Category
+
+// Category is a node of the category tree Courses are organised by, such as a
+// track and its specialisations.
+type Category struct {
+	ID        uuid.UUID   `db:"id" validate:"required"`
+	ParentID  nuuid.NUUID `db:"parent_id"`
+	Name      string      `db:"name" validate:"required,max=100"`
+	CreatedAt time.Time   `db:"created_at" validate:"required"`
+	CreatedBy uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt null.Time   `db:"updated_at"`
+	UpdatedBy nuuid.NUUID `db:"updated_by"`
+	Children  []Category  `db:"-"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (c Category) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToResponseFormat())
+}
+
+// NewCategoryFromRequestFormat creates a new Category.
+func (c Category) NewCategoryFromRequestFormat(req CategoryRequestFormat, userID uuid.UUID) (newCategory Category, err error) {
+	categoryID, _ := uuid.NewV4()
+	newCategory = Category{
+		ID:        categoryID,
+		ParentID:  req.ParentID,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newCategory.Validate()
+
+	return
+}
+
+// ToResponseFormat converts this Category to its response format.
+func (c Category) ToResponseFormat() CategoryResponseFormat {
+	resp := CategoryResponseFormat{
+		ID:        c.ID,
+		ParentID:  c.ParentID.Ptr(),
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+		CreatedBy: c.CreatedBy,
+		UpdatedAt: c.UpdatedAt,
+		UpdatedBy: c.UpdatedBy.Ptr(),
+	}
+
+	for _, child := range c.Children {
+		resp.Children = append(resp.Children, child.ToResponseFormat())
+	}
+
+	return resp
+}
+
+// Update renames a Category and moves it under another parent.
+func (c *Category) Update(req CategoryRequestFormat, userID uuid.UUID) (err error) {
+	c.ParentID = req.ParentID
+	c.Name = strings.TrimSpace(req.Name)
+	c.UpdatedAt = null.TimeFrom(time.Now())
+	c.UpdatedBy = nuuid.From(userID)
+
+	err = c.Validate()
+
+	return
+}
+
+// Validate validates the entity.
+func (c *Category) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(c)
+}
+
+// CategoryRequestFormat represents a Category's standard formatting for JSON deserializing.
+type CategoryRequestFormat struct {
+	ParentID nuuid.NUUID `json:"parentID" swaggertype:"string"`
+	Name     string      `json:"name" validate:"required,max=100"`
+}
+
+// CategoryResponseFormat represents a Category's standard formatting for JSON serializing.
+type CategoryResponseFormat struct {
+	ID        uuid.UUID                `json:"id"`
+	ParentID  *uuid.UUID               `json:"parentID"`
+	Name      string                   `json:"name"`
+	CreatedAt time.Time                `json:"createdAt"`
+	CreatedBy uuid.UUID                `json:"createdBy"`
+	UpdatedAt null.Time                `json:"updatedAt"`
+	UpdatedBy *uuid.UUID               `json:"updatedBy"`
+	Children  []CategoryResponseFormat `json:"children,omitempty"`
+}
+
+//// CategoryTree
+
+// CategoryTree is the flat list of every Category, which links to its parent.
+type CategoryTree []Category
+
+// CheckParent makes sure a Category may be placed under the given parent: the
+// parent must exist and must not be the Category itself or one of its
+// descendants, which would create a cycle.
+func (t CategoryTree) CheckParent(category Category) (err error) {
+	if !category.ParentID.Valid {
+		return
+	}
+
+	if _, ok := t.find(category.ParentID.UUID); !ok {
+		return failure.NotFound("parent category")
+	}
+
+	for _, id := range t.Subtree(category.ID) {
+		if id == category.ParentID.UUID {
+			return failure.Conflict("move", "category", "cannot be placed under itself or one of its descendants")
+		}
+	}
+
+	return
+}
+
+// CheckSiblingName makes sure no other Category under the same parent has the
+// same name.
+func (t CategoryTree) CheckSiblingName(category Category) (err error) {
+	for _, other := range t {
+		if other.ID != category.ID && other.ParentID == category.ParentID && strings.EqualFold(other.Name, category.Name) {
+			return failure.Conflict("save", "category", "a category with this name already exists here")
+		}
+	}
+
+	return
+}
+
+// HasChildren checks whether any Category is placed under the given one.
+func (t CategoryTree) HasChildren(id uuid.UUID) bool {
+	for _, category := range t {
+		if category.ParentID.Valid && category.ParentID.UUID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Nested returns the Category with the given ID and its descendants as Children.
+func (t CategoryTree) Nested(id uuid.UUID) (category Category, ok bool) {
+	category, ok = t.find(id)
+	if !ok {
+		return
+	}
+
+	category.Children = t.children(nuuid.From(id))
+
+	return
+}
+
+// Roots returns the top-level Categories with their descendants as Children.
+func (t CategoryTree) Roots() []Category {
+	return t.children(nuuid.NUUID{})
+}
+
+// Subtree returns the ID of the given Category and the IDs of all its
+// descendants, or nothing when there is no such Category.
+func (t CategoryTree) Subtree(id uuid.UUID) (ids []uuid.UUID) {
+	if _, ok := t.find(id); !ok {
+		return
+	}
+
+	ids = append(ids, id)
+	for i := 0; i < len(ids); i++ {
+		for _, category := range t {
+			if category.ParentID.Valid && category.ParentID.UUID == ids[i] {
+				ids = append(ids, category.ID)
+			}
+		}
+	}
+
+	return
+}
+
+func (t CategoryTree) children(parentID nuuid.NUUID) (children []Category) {
+	for _, category := range t {
+		if category.ParentID == parentID {
+			category.Children = t.children(nuuid.From(category.ID))
+			children = append(children, category)
+		}
+	}
+
+	sort.SliceStable(children, func(i, j int) bool {
+		return strings.ToLower(children[i].Name) < strings.ToLower(children[j].Name)
+	})
+
+	return
+}
+
+func (t CategoryTree) find(id uuid.UUID) (category Category, ok bool) {
+	for _, category := range t {
+		if category.ID == id {
+			return category, true
+		}
+	}
+
+	return
+}
+
+//// Tag
+
+// Tag is a free-form label for a skill taught in Courses. Tag names are
+// lowercase and unique.
+type Tag struct {
+	ID          uuid.UUID   `db:"id" validate:"required"`
+	Name        string      `db:"name" validate:"required,max=64"`
+	CourseCount int         `db:"course_count"`
+	CreatedAt   time.Time   `db:"created_at" validate:"required"`
+	CreatedBy   uuid.UUID   `db:"created_by" validate:"required"`
+	UpdatedAt   null.Time   `db:"updated_at"`
+	UpdatedBy   nuuid.NUUID `db:"updated_by"`
+}
+
+// MarshalJSON overrides the standard JSON formatting.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.ToResponseFormat())
+}
+
+// NewTag creates a new Tag with the given name.
+func (t Tag) NewTag(name string, userID uuid.UUID) (newTag Tag, err error) {
+	tagID, _ := uuid.NewV4()
+	newTag = Tag{
+		ID:        tagID,
+		Name:      NormalizeTagName(name),
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	err = newTag.Validate()
+
+	return
+}
+
+// Rename renames a Tag.
+func (t *Tag) Rename(name string, userID uuid.UUID) (err error) {
+	t.Name = NormalizeTagName(name)
+	t.UpdatedAt = null.TimeFrom(time.Now())
+	t.UpdatedBy = nuuid.From(userID)
+
+	err = t.Validate()
+
+	return
+}
+
+// ToResponseFormat converts this Tag to its response format.
+func (t Tag) ToResponseFormat() TagResponseFormat {
+	return TagResponseFormat{
+		ID:          t.ID,
+		Name:        t.Name,
+		CourseCount: t.CourseCount,
+		CreatedAt:   t.CreatedAt,
+		CreatedBy:   t.CreatedBy,
+		UpdatedAt:   t.UpdatedAt,
+		UpdatedBy:   t.UpdatedBy.Ptr(),
+	}
+}
+
+// Validate validates the entity.
+func (t *Tag) Validate() (err error) {
+	validator := shared.GetValidator()
+	return validator.Struct(t)
+}
+
+// NormalizeTagName trims a tag name and makes it lowercase, so that "Go" and
+// " go" name the same Tag.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTagNames normalizes tag names and drops blanks and duplicates,
+// keeping the order of their first occurrence.
+func NormalizeTagNames(names []string) (normalized []string) {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	return
+}
+
+// TagRequestFormat represents a Tag's standard formatting for JSON deserializing.
+type TagRequestFormat struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// TagResponseFormat represents a Tag's standard formatting for JSON serializing.
+type TagResponseFormat struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	CourseCount int        `json:"courseCount"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CreatedBy   uuid.UUID  `json:"createdBy"`
+	UpdatedAt   null.Time  `json:"updatedAt"`
+	UpdatedBy   *uuid.UUID `json:"updatedBy"`
+}
+
+//// Course taxonomy
+
+// CourseCategoriesRequestFormat replaces the Categories a Course belongs to.
+type CourseCategoriesRequestFormat struct {
+	CategoryIDs []uuid.UUID `json:"categoryIDs" validate:"max=20"`
+}
+
+// CourseTagsRequestFormat replaces the Tags of a Course. Tags that do not
+// exist yet are created.
+type CourseTagsRequestFormat struct {
+	Tags []string `json:"tags" validate:"max=30,dive,max=64"`
+}
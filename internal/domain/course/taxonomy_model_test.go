@@ -0,0 +1,99 @@
+package course_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryTree(t *testing.T) {
+	backend := newCategory("Backend", nuuid.NUUID{})
+	golang := newCategory("Go", nuuid.From(backend.ID))
+	grpc := newCategory("gRPC", nuuid.From(golang.ID))
+	data := newCategory("Data", nuuid.NUUID{})
+	tree := course.CategoryTree{grpc, data, golang, backend}
+
+	t.Run("subtree", func(t *testing.T) {
+		assert.ElementsMatch(t, []uuid.UUID{backend.ID, golang.ID, grpc.ID}, tree.Subtree(backend.ID))
+		assert.Equal(t, []uuid.UUID{data.ID}, tree.Subtree(data.ID))
+		assert.Empty(t, tree.Subtree(getRandomUUID()))
+	})
+
+	t.Run("roots are nested and sorted by name", func(t *testing.T) {
+		roots := tree.Roots()
+
+		assert.Len(t, roots, 2)
+		assert.Equal(t, "Backend", roots[0].Name)
+		assert.Equal(t, "Go", roots[0].Children[0].Name)
+		assert.Equal(t, "gRPC", roots[0].Children[0].Children[0].Name)
+		assert.Equal(t, "Data", roots[1].Name)
+		assert.Empty(t, roots[1].Children)
+	})
+
+	t.Run("check parent", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			category course.Category
+			parentID nuuid.NUUID
+			wantCode int
+		}{
+			{name: "top level", category: golang},
+			{name: "under a sibling tree", category: golang, parentID: nuuid.From(data.ID)},
+			{name: "under itself", category: golang, parentID: nuuid.From(golang.ID), wantCode: http.StatusConflict},
+			{name: "under a descendant", category: backend, parentID: nuuid.From(grpc.ID), wantCode: http.StatusConflict},
+			{name: "under a missing parent", category: golang, parentID: nuuid.From(getRandomUUID()), wantCode: http.StatusNotFound},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				category := test.category
+				category.ParentID = test.parentID
+
+				err := tree.CheckParent(category)
+
+				if test.wantCode == 0 {
+					assert.NoError(t, err)
+					return
+				}
+
+				assert.Equal(t, test.wantCode, failure.GetCode(err))
+			})
+		}
+	})
+
+	t.Run("sibling names are unique ignoring case", func(t *testing.T) {
+		duplicate := newCategory("go", nuuid.From(backend.ID))
+		elsewhere := newCategory("go", nuuid.From(data.ID))
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(tree.CheckSiblingName(duplicate)))
+		assert.NoError(t, tree.CheckSiblingName(elsewhere))
+		assert.NoError(t, tree.CheckSiblingName(golang))
+	})
+
+	t.Run("has children", func(t *testing.T) {
+		assert.True(t, tree.HasChildren(golang.ID))
+		assert.False(t, tree.HasChildren(grpc.ID))
+	})
+}
+
+func TestNormalizeTagNames(t *testing.T) {
+	got := course.NormalizeTagNames([]string{" Go ", "SQL", "go", "", "  ", "sql", "pandas"})
+
+	assert.Equal(t, []string{"go", "sql", "pandas"}, got)
+}
+
+func newCategory(name string, parentID nuuid.NUUID) course.Category {
+	return course.Category{
+		ID:        getRandomUUID(),
+		ParentID:  parentID,
+		Name:      name,
+		CreatedAt: time.Now(),
+		CreatedBy: getRandomUUID(),
+	}
+}
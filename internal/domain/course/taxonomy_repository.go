@@ -0,0 +1,451 @@
+package course
+
+//go:generate go run github.com/golang/mock/mockgen -source taxonomy_repository.go -destination mock/taxonomy_repository_mock.go -package course_mock
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	taxonomyQueries = struct {
+		selectCategory         string
+		insertCategory         string
+		updateCategory         string
+		insertCourseCategory   string
+		selectTag              string
+		insertTag              string
+		updateTag              string
+		insertCourseTag        string
+		selectCourseCategories string
+		selectCourseTags       string
+	}{
+		selectCategory: `
+			SELECT
+				id,
+				parent_id,
+				name,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			FROM categories
+		`,
+
+		insertCategory: `
+			INSERT INTO categories (
+				id,
+				parent_id,
+				name,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			) VALUES (
+				:id,
+				:parent_id,
+				:name,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by
+			)
+		`,
+
+		updateCategory: `
+			UPDATE categories
+			SET
+				parent_id = :parent_id,
+				name = :name,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+
+		insertCourseCategory: `
+			INSERT INTO course_categories (course_id, category_id)
+			VALUES (?, ?)
+		`,
+
+		selectTag: `
+			SELECT
+				t.id,
+				t.name,
+				(SELECT COUNT(ct.course_id) FROM course_tags ct WHERE ct.tag_id = t.id) AS course_count,
+				t.created_at,
+				t.created_by,
+				t.updated_at,
+				t.updated_by
+			FROM tags t
+		`,
+
+		insertTag: `
+			INSERT INTO tags (
+				id,
+				name,
+				created_at,
+				created_by,
+				updated_at,
+				updated_by
+			) VALUES (
+				:id,
+				:name,
+				:created_at,
+				:created_by,
+				:updated_at,
+				:updated_by
+			)
+		`,
+
+		updateTag: `
+			UPDATE tags
+			SET
+				name = :name,
+				updated_at = :updated_at,
+				updated_by = :updated_by
+			WHERE id = :id
+		`,
+
+		insertCourseTag: `
+			INSERT INTO course_tags (course_id, tag_id)
+			VALUES (?, ?)
+		`,
+
+		selectCourseCategories: `
+			SELECT
+				c.id,
+				c.parent_id,
+				c.name,
+				c.created_at,
+				c.created_by,
+				c.updated_at,
+				c.updated_by
+			FROM categories c
+			JOIN course_categories cc ON cc.category_id = c.id
+			WHERE cc.course_id = ?
+			ORDER BY c.name
+		`,
+
+		selectCourseTags: `
+			SELECT
+				t.id,
+				t.name,
+				(SELECT COUNT(ct2.course_id) FROM course_tags ct2 WHERE ct2.tag_id = t.id) AS course_count,
+				t.created_at,
+				t.created_by,
+				t.updated_at,
+				t.updated_by
+			FROM tags t
+			JOIN course_tags ct ON ct.tag_id = t.id
+			WHERE ct.course_id = ?
+			ORDER BY t.name
+		`,
+	}
+)
+
+// TaxonomyRepository is the repository for the Categories and Tags that
+// organise Courses.
+type TaxonomyRepository interface {
+	CreateCategory(category Category) (err error)
+	CreateTag(tag Tag) (err error)
+	DeleteCategory(id uuid.UUID) (err error)
+	DeleteTag(id uuid.UUID) (err error)
+	ResolveCategories() (categories CategoryTree, err error)
+	ResolveCategoriesByCourseID(courseID uuid.UUID) (categories []Category, err error)
+	ResolveTagByID(id uuid.UUID) (tag Tag, err error)
+	ResolveTagByName(name string) (tag Tag, err error)
+	ResolveTagsByCourseID(courseID uuid.UUID) (tags []Tag, err error)
+	ResolveTagsByNames(names []string) (tags []Tag, err error)
+	ResolveTagsByPrefix(prefix string, limit int) (tags []Tag, err error)
+	SetCourseCategories(courseID uuid.UUID, categoryIDs []uuid.UUID) (err error)
+	SetCourseTags(courseID uuid.UUID, newTags []Tag, tagIDs []uuid.UUID) (err error)
+	UpdateCategory(category Category) (err error)
+	UpdateTag(tag Tag) (err error)
+}
+
+// TaxonomyRepositoryMySQL is the MySQL-backed implementation of TaxonomyRepository.
+type TaxonomyRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+// ProvideTaxonomyRepositoryMySQL is the provider for this repository.
+func ProvideTaxonomyRepositoryMySQL(db *infras.MySQLConn) *TaxonomyRepositoryMySQL {
+	s := new(TaxonomyRepositoryMySQL)
+	s.DB = db
+
+	return s
+}
+
+// CreateCategory creates a new Category.
+func (r *TaxonomyRepositoryMySQL) CreateCategory(category Category) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, taxonomyQueries.insertCategory, category); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// CreateTag creates a new Tag.
+func (r *TaxonomyRepositoryMySQL) CreateTag(tag Tag) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, taxonomyQueries.insertTag, tag); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// DeleteCategory deletes a Category and unlinks it from its Courses.
+func (r *TaxonomyRepositoryMySQL) DeleteCategory(id uuid.UUID) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec("DELETE FROM course_categories WHERE category_id = ?", id.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if _, err := tx.Exec("DELETE FROM categories WHERE id = ?", id.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// DeleteTag deletes a Tag and unlinks it from its Courses.
+func (r *TaxonomyRepositoryMySQL) DeleteTag(id uuid.UUID) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec("DELETE FROM course_tags WHERE tag_id = ?", id.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", id.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// ResolveCategories resolves every Category.
+func (r *TaxonomyRepositoryMySQL) ResolveCategories() (categories CategoryTree, err error) {
+	err = r.DB.Read.Select(&categories, taxonomyQueries.selectCategory+" ORDER BY name")
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveCategoriesByCourseID resolves the Categories a Course belongs to.
+func (r *TaxonomyRepositoryMySQL) ResolveCategoriesByCourseID(courseID uuid.UUID) (categories []Category, err error) {
+	err = r.DB.Read.Select(&categories, taxonomyQueries.selectCourseCategories, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveTagByID resolves a Tag by its ID.
+func (r *TaxonomyRepositoryMySQL) ResolveTagByID(id uuid.UUID) (tag Tag, err error) {
+	err = r.DB.Read.Get(&tag, taxonomyQueries.selectTag+" WHERE t.id = ?", id.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("tag")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveTagByName resolves a Tag by its normalized name.
+func (r *TaxonomyRepositoryMySQL) ResolveTagByName(name string) (tag Tag, err error) {
+	err = r.DB.Read.Get(&tag, taxonomyQueries.selectTag+" WHERE t.name = ?", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = failure.NotFound("tag")
+		}
+
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveTagsByCourseID resolves the Tags of a Course.
+func (r *TaxonomyRepositoryMySQL) ResolveTagsByCourseID(courseID uuid.UUID) (tags []Tag, err error) {
+	err = r.DB.Read.Select(&tags, taxonomyQueries.selectCourseTags, courseID.String())
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveTagsByNames resolves the Tags with the given normalized names.
+func (r *TaxonomyRepositoryMySQL) ResolveTagsByNames(names []string) (tags []Tag, err error) {
+	if len(names) == 0 {
+		return
+	}
+
+	query, args, err := sqlx.In(taxonomyQueries.selectTag+" WHERE t.name IN (?)", names)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+
+	err = r.DB.Read.Select(&tags, query, args...)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// ResolveTagsByPrefix resolves the Tags whose names start with the given
+// prefix, the most used first.
+func (r *TaxonomyRepositoryMySQL) ResolveTagsByPrefix(prefix string, limit int) (tags []Tag, err error) {
+	err = r.DB.Read.Select(
+		&tags,
+		taxonomyQueries.selectTag+" WHERE t.name LIKE ? ORDER BY course_count DESC, t.name LIMIT ?",
+		escapeLike(prefix)+"%",
+		limit)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// SetCourseCategories replaces the Categories a Course belongs to.
+func (r *TaxonomyRepositoryMySQL) SetCourseCategories(courseID uuid.UUID, categoryIDs []uuid.UUID) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if _, err := tx.Exec("DELETE FROM course_categories WHERE course_id = ?", courseID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txInsertLinks(tx, taxonomyQueries.insertCourseCategory, courseID, categoryIDs); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// SetCourseTags creates the new Tags and replaces the Tags of a Course with
+// the given ones.
+func (r *TaxonomyRepositoryMySQL) SetCourseTags(courseID uuid.UUID, newTags []Tag, tagIDs []uuid.UUID) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		for _, tag := range newTags {
+			if err := r.txExecNamed(tx, taxonomyQueries.insertTag, tag); err != nil {
+				e <- err
+				return
+			}
+		}
+
+		if _, err := tx.Exec("DELETE FROM course_tags WHERE course_id = ?", courseID.String()); err != nil {
+			logger.ErrorWithStack(err)
+			e <- err
+			return
+		}
+
+		if err := r.txInsertLinks(tx, taxonomyQueries.insertCourseTag, courseID, tagIDs); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateCategory updates a Category.
+func (r *TaxonomyRepositoryMySQL) UpdateCategory(category Category) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, taxonomyQueries.updateCategory, category); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// UpdateTag updates a Tag.
+func (r *TaxonomyRepositoryMySQL) UpdateTag(tag Tag) (err error) {
+	return r.DB.WithTransaction(func(tx *sqlx.Tx, e chan error) {
+		if err := r.txExecNamed(tx, taxonomyQueries.updateTag, tag); err != nil {
+			e <- err
+			return
+		}
+
+		e <- nil
+	})
+}
+
+// internal methods
+
+// txExecNamed executes a named query transactionally given the *sqlx.Tx param.
+func (r *TaxonomyRepositoryMySQL) txExecNamed(tx *sqlx.Tx, query string, arg interface{}) (err error) {
+	stmt, err := tx.PrepareNamed(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(arg)
+	if err != nil {
+		logger.ErrorWithStack(err)
+	}
+
+	return
+}
+
+// txInsertLinks links a Course to every given ID transactionally given the
+// *sqlx.Tx param.
+func (r *TaxonomyRepositoryMySQL) txInsertLinks(tx *sqlx.Tx, query string, courseID uuid.UUID, ids []uuid.UUID) (err error) {
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		logger.ErrorWithStack(err)
+		return
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		_, err = stmt.Exec(courseID.String(), id.String())
+		if err != nil {
+			logger.ErrorWithStack(err)
+			return
+		}
+	}
+
+	return
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
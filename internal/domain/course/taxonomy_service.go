@@ -0,0 +1,317 @@
+package course
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/gofrs/uuid"
+)
+
+// TaxonomyService is the service interface for the Categories and Tags that
+// organise Courses.
+type TaxonomyService interface {
+	AutocompleteTags(prefix string, limit int) (tags []Tag, err error)
+	CreateCategory(requestFormat CategoryRequestFormat, userID uuid.UUID) (category Category, err error)
+	CreateTag(requestFormat TagRequestFormat, userID uuid.UUID) (tag Tag, err error)
+	DeleteCategory(id uuid.UUID) (category Category, err error)
+	DeleteTag(id uuid.UUID) (tag Tag, err error)
+	ResolveCategoryByID(id uuid.UUID) (category Category, err error)
+	ResolveCategoryTree() (categories []Category, err error)
+	SetCourseCategories(courseID uuid.UUID, requestFormat CourseCategoriesRequestFormat, userID uuid.UUID) (course Course, err error)
+	SetCourseTags(courseID uuid.UUID, requestFormat CourseTagsRequestFormat, userID uuid.UUID) (course Course, err error)
+	UpdateCategory(id uuid.UUID, requestFormat CategoryRequestFormat, userID uuid.UUID) (category Category, err error)
+	UpdateTag(id uuid.UUID, requestFormat TagRequestFormat, userID uuid.UUID) (tag Tag, err error)
+}
+
+// TaxonomyServiceImpl is the service implementation for the Categories and
+// Tags that organise Courses.
+type TaxonomyServiceImpl struct {
+	CollaboratorRepository CollaboratorRepository
+	CourseRepository       CourseRepository
+	TaxonomyRepository     TaxonomyRepository
+	Config                 *configs.Config
+}
+
+// ProvideTaxonomyServiceImpl is the provider for this service.
+func ProvideTaxonomyServiceImpl(collaboratorRepository CollaboratorRepository, courseRepository CourseRepository, taxonomyRepository TaxonomyRepository, config *configs.Config) *TaxonomyServiceImpl {
+	s := new(TaxonomyServiceImpl)
+	s.CollaboratorRepository = collaboratorRepository
+	s.CourseRepository = courseRepository
+	s.TaxonomyRepository = taxonomyRepository
+	s.Config = config
+
+	return s
+}
+
+// AutocompleteTags suggests the most used Tags starting with a prefix.
+func (s *TaxonomyServiceImpl) AutocompleteTags(prefix string, limit int) (tags []Tag, err error) {
+	return s.TaxonomyRepository.ResolveTagsByPrefix(NormalizeTagName(prefix), limit)
+}
+
+// CreateCategory creates a Category at the top of the tree or under a parent.
+func (s *TaxonomyServiceImpl) CreateCategory(requestFormat CategoryRequestFormat, userID uuid.UUID) (category Category, err error) {
+	category, err = Category{}.NewCategoryFromRequestFormat(requestFormat, userID)
+	if err != nil {
+		return category, failure.BadRequest(err)
+	}
+
+	err = s.checkPlacement(category)
+	if err != nil {
+		return
+	}
+
+	err = s.TaxonomyRepository.CreateCategory(category)
+	return
+}
+
+// CreateTag creates a Tag with a name not used by any other Tag.
+func (s *TaxonomyServiceImpl) CreateTag(requestFormat TagRequestFormat, userID uuid.UUID) (tag Tag, err error) {
+	tag, err = Tag{}.NewTag(requestFormat.Name, userID)
+	if err != nil {
+		return tag, failure.BadRequest(err)
+	}
+
+	err = s.checkTagName(tag)
+	if err != nil {
+		return
+	}
+
+	err = s.TaxonomyRepository.CreateTag(tag)
+	return
+}
+
+// DeleteCategory deletes a Category without children and unlinks it from its
+// Courses.
+func (s *TaxonomyServiceImpl) DeleteCategory(id uuid.UUID) (category Category, err error) {
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	category, ok := categories.Nested(id)
+	if !ok {
+		return category, failure.NotFound("category")
+	}
+
+	if categories.HasChildren(id) {
+		return category, failure.Conflict("delete", "category", "move or delete its subcategories first")
+	}
+
+	err = s.TaxonomyRepository.DeleteCategory(id)
+	return
+}
+
+// DeleteTag deletes a Tag and unlinks it from its Courses.
+func (s *TaxonomyServiceImpl) DeleteTag(id uuid.UUID) (tag Tag, err error) {
+	tag, err = s.TaxonomyRepository.ResolveTagByID(id)
+	if err != nil {
+		return
+	}
+
+	err = s.TaxonomyRepository.DeleteTag(id)
+	return
+}
+
+// ResolveCategoryByID resolves a Category with its descendants.
+func (s *TaxonomyServiceImpl) ResolveCategoryByID(id uuid.UUID) (category Category, err error) {
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	category, ok := categories.Nested(id)
+	if !ok {
+		return category, failure.NotFound("category")
+	}
+
+	return
+}
+
+// ResolveCategoryTree resolves the top-level Categories with their descendants.
+func (s *TaxonomyServiceImpl) ResolveCategoryTree() (categories []Category, err error) {
+	tree, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	return tree.Roots(), nil
+}
+
+// SetCourseCategories replaces the Categories a Course the given user may edit
+// belongs to.
+func (s *TaxonomyServiceImpl) SetCourseCategories(courseID uuid.UUID, requestFormat CourseCategoriesRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	categoryIDs := make([]uuid.UUID, 0, len(requestFormat.CategoryIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range requestFormat.CategoryIDs {
+		if _, ok := categories.find(id); !ok {
+			return course, failure.NotFound("category")
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			categoryIDs = append(categoryIDs, id)
+		}
+	}
+
+	err = s.TaxonomyRepository.SetCourseCategories(course.ID, categoryIDs)
+	if err != nil {
+		return
+	}
+
+	return attachCourseTaxonomy(s.TaxonomyRepository, course)
+}
+
+// SetCourseTags replaces the Tags of a Course the given user may edit,
+// creating the Tags that do not exist yet.
+func (s *TaxonomyServiceImpl) SetCourseTags(courseID uuid.UUID, requestFormat CourseTagsRequestFormat, userID uuid.UUID) (course Course, err error) {
+	course, err = resolveManagedCourse(s.CollaboratorRepository, s.CourseRepository, courseID, userID, PermissionEditCourse)
+	if err != nil {
+		return
+	}
+
+	names := NormalizeTagNames(requestFormat.Tags)
+	existing, err := s.TaxonomyRepository.ResolveTagsByNames(names)
+	if err != nil {
+		return
+	}
+
+	tagIDsByName := make(map[string]uuid.UUID)
+	for _, tag := range existing {
+		tagIDsByName[tag.Name] = tag.ID
+	}
+
+	var newTags []Tag
+	tagIDs := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		id, ok := tagIDsByName[name]
+		if !ok {
+			tag, err := Tag{}.NewTag(name, userID)
+			if err != nil {
+				return course, failure.BadRequest(err)
+			}
+
+			newTags = append(newTags, tag)
+			id = tag.ID
+		}
+
+		tagIDs = append(tagIDs, id)
+	}
+
+	err = s.TaxonomyRepository.SetCourseTags(course.ID, newTags, tagIDs)
+	if err != nil {
+		return
+	}
+
+	return attachCourseTaxonomy(s.TaxonomyRepository, course)
+}
+
+// UpdateCategory renames a Category and moves it under another parent.
+func (s *TaxonomyServiceImpl) UpdateCategory(id uuid.UUID, requestFormat CategoryRequestFormat, userID uuid.UUID) (category Category, err error) {
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	category, ok := categories.find(id)
+	if !ok {
+		return category, failure.NotFound("category")
+	}
+
+	err = category.Update(requestFormat, userID)
+	if err != nil {
+		return category, failure.BadRequest(err)
+	}
+
+	err = categories.CheckParent(category)
+	if err != nil {
+		return
+	}
+
+	err = categories.CheckSiblingName(category)
+	if err != nil {
+		return
+	}
+
+	err = s.TaxonomyRepository.UpdateCategory(category)
+	return
+}
+
+// UpdateTag renames a Tag.
+func (s *TaxonomyServiceImpl) UpdateTag(id uuid.UUID, requestFormat TagRequestFormat, userID uuid.UUID) (tag Tag, err error) {
+	tag, err = s.TaxonomyRepository.ResolveTagByID(id)
+	if err != nil {
+		return
+	}
+
+	err = tag.Rename(requestFormat.Name, userID)
+	if err != nil {
+		return tag, failure.BadRequest(err)
+	}
+
+	err = s.checkTagName(tag)
+	if err != nil {
+		return
+	}
+
+	err = s.TaxonomyRepository.UpdateTag(tag)
+	return
+}
+
+// checkPlacement makes sure a Category fits where it is placed in the tree.
+func (s *TaxonomyServiceImpl) checkPlacement(category Category) (err error) {
+	categories, err := s.TaxonomyRepository.ResolveCategories()
+	if err != nil {
+		return
+	}
+
+	err = categories.CheckParent(category)
+	if err != nil {
+		return
+	}
+
+	return categories.CheckSiblingName(category)
+}
+
+// checkTagName makes sure no other Tag has the name of the given one.
+func (s *TaxonomyServiceImpl) checkTagName(tag Tag) (err error) {
+	other, err := s.TaxonomyRepository.ResolveTagByName(tag.Name)
+	if failure.GetCode(err) == http.StatusNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if other.ID != tag.ID {
+		return failure.Conflict("save", "tag", "a tag with this name already exists")
+	}
+
+	return
+}
+
+// attachCourseTaxonomy attaches the Categories and Tags of a Course.
+func attachCourseTaxonomy(taxonomyRepository TaxonomyRepository, course Course) (Course, error) {
+	categories, err := taxonomyRepository.ResolveCategoriesByCourseID(course.ID)
+	if err != nil {
+		return course, err
+	}
+
+	tags, err := taxonomyRepository.ResolveTagsByCourseID(course.ID)
+	if err != nil {
+		return course, err
+	}
+
+	return course.AttachTaxonomy(categories, tags), nil
+}
@@ -0,0 +1,79 @@
+package course_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	course_mock "github.com/evermos/boilerplate-go/internal/domain/course/mock"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxonomyService(t *testing.T) {
+	ownerID := getRandomUUID()
+	owned := course.Course{
+		ID:        getRandomUUID(),
+		UserID:    ownerID,
+		Title:     "Backend Bootcamp",
+		Content:   "Go, MySQL and friends",
+		Status:    course.CourseStatusDraft,
+		CreatedAt: time.Now(),
+		CreatedBy: ownerID,
+	}
+
+	t.Run("set course tags creates missing tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		existing, _ := course.Tag{}.NewTag("go", ownerID)
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockTaxonomyRepo := course_mock.NewMockTaxonomyRepository(ctrl)
+		s := &course.TaxonomyServiceImpl{CourseRepository: mockCourseRepo, TaxonomyRepository: mockTaxonomyRepo}
+		mockCourseRepo.EXPECT().ResolveCourseByID(owned.ID).Return(owned, nil)
+		mockTaxonomyRepo.EXPECT().ResolveTagsByNames([]string{"go", "mysql"}).Return([]course.Tag{existing}, nil)
+		mockTaxonomyRepo.EXPECT().SetCourseTags(owned.ID, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(courseID uuid.UUID, newTags []course.Tag, tagIDs []uuid.UUID) error {
+				assert.Len(t, newTags, 1)
+				assert.Equal(t, "mysql", newTags[0].Name)
+				assert.Equal(t, []uuid.UUID{existing.ID, newTags[0].ID}, tagIDs)
+				return nil
+			})
+		mockTaxonomyRepo.EXPECT().ResolveCategoriesByCourseID(owned.ID).Return(nil, nil)
+		mockTaxonomyRepo.EXPECT().ResolveTagsByCourseID(owned.ID).Return([]course.Tag{existing}, nil)
+
+		got, err := s.SetCourseTags(owned.ID, course.CourseTagsRequestFormat{Tags: []string{"Go", "MySQL", "go "}}, ownerID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []course.Tag{existing}, got.Tags)
+	})
+
+	t.Run("resolve courses in a category subtree", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		backend := newCategory("Backend", nuuid.NUUID{})
+		golang := newCategory("Go", nuuid.From(backend.ID))
+		mockCourseRepo := course_mock.NewMockCourseRepository(ctrl)
+		mockTaxonomyRepo := course_mock.NewMockTaxonomyRepository(ctrl)
+		s := &course.CourseServiceImpl{CourseRepository: mockCourseRepo, TaxonomyRepository: mockTaxonomyRepo}
+		mockTaxonomyRepo.EXPECT().ResolveCategories().Return(course.CategoryTree{backend, golang}, nil)
+		mockCourseRepo.EXPECT().ResolveCourses(gomock.Any()).DoAndReturn(
+			func(params course.CourseQueryParameters) ([]course.Course, error) {
+				assert.ElementsMatch(t, []uuid.UUID{backend.ID, golang.ID}, params.CategoryIDs)
+				assert.Equal(t, []string{"go"}, params.Tags)
+				return []course.Course{owned}, nil
+			})
+
+		got, err := s.ResolveCourses(course.CourseQueryParameters{
+			Limit:      10,
+			CategoryID: nuuid.From(backend.ID),
+			Tags:       []string{"Go"},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+}
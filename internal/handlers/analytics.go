@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+)
+
+// AnalyticsHandler is the HTTP handler for the learning analytics of Courses.
+type AnalyticsHandler struct {
+	AnalyticsService course.AnalyticsService
+	AuthMiddleware   *middleware.Authentication
+}
+
+// ProvideAnalyticsHandler is the provider for this handler.
+func ProvideAnalyticsHandler(analyticsService course.AnalyticsService, authMiddleware *middleware.Authentication) AnalyticsHandler {
+	return AnalyticsHandler{
+		AnalyticsService: analyticsService,
+		AuthMiddleware:   authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *AnalyticsHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/analytics", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/active-learners", h.ResolveActiveLearners)
+			r.Get("/completion-time", h.ResolveCompletionTime)
+			r.Get("/enrollments", h.ResolveEnrollmentsOverTime)
+			r.Get("/funnel", h.ResolveCompletionFunnel)
+			r.Get("/quiz-scores", h.ResolveQuizScoreDistributions)
+		})
+	})
+}
+
+// ResolveActiveLearners resolves how many students were active in a Course day by day.
+// @Summary Resolve the active learners of a Course.
+// @Description This endpoint counts, day by day, the enrolled students who progressed through a lesson, attempted a
+// @Description quiz, submitted an assignment or posted in the discussions of a Course the teacher may view the
+// @Description students of. Analytics are aggregated periodically by UTC day, so today's are not final.
+// @Tags courses/analytics
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only count the students of this cohort."
+// @Param from query string false "The first day (YYYY-MM-DD) to count; defaults to 29 days before to."
+// @Param to query string false "The last day (YYYY-MM-DD) to count; defaults to today."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.ActiveLearnersDayResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/analytics/active-learners [get]
+func (h *AnalyticsHandler) ResolveActiveLearners(w http.ResponseWriter, r *http.Request) {
+	params, err := analyticsParamsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	days, err := h.AnalyticsService.ResolveActiveLearners(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, days)
+}
+
+// ResolveCompletionFunnel resolves how far students got through each Lesson of a Course.
+// @Summary Resolve the completion funnel of a Course.
+// @Description This endpoint lists the lessons of a Course the teacher may view the students of in course order, with
+// @Description how many students started and completed each of them over the given days. The completion rate of a
+// @Description lesson is the percentage of the students who started it that completed it; its drop-off rate is how
+// @Description many fewer completions it had than the lesson before it, as a percentage of those.
+// @Tags courses/analytics
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only count the students of this cohort."
+// @Param from query string false "The first day (YYYY-MM-DD) to count; defaults to 29 days before to."
+// @Param to query string false "The last day (YYYY-MM-DD) to count; defaults to today."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.LessonFunnelStep}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/analytics/funnel [get]
+func (h *AnalyticsHandler) ResolveCompletionFunnel(w http.ResponseWriter, r *http.Request) {
+	params, err := analyticsParamsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	funnel, err := h.AnalyticsService.ResolveCompletionFunnel(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, funnel)
+}
+
+// ResolveCompletionTime resolves the average time students took to complete a Course.
+// @Summary Resolve the average time to complete a Course.
+// @Description This endpoint averages the time from enrolling to completing a Course the teacher may view the
+// @Description students of, over the given days and day by day, by the day students completed it. A student completes
+// @Description a Course when they claim its certificate; revoked certificates are not counted.
+// @Tags courses/analytics
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only count the students of this cohort."
+// @Param from query string false "The first day (YYYY-MM-DD) to count; defaults to 29 days before to."
+// @Param to query string false "The last day (YYYY-MM-DD) to count; defaults to today."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CompletionTime}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/analytics/completion-time [get]
+func (h *AnalyticsHandler) ResolveCompletionTime(w http.ResponseWriter, r *http.Request) {
+	params, err := analyticsParamsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	completionTime, err := h.AnalyticsService.ResolveCompletionTime(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, completionTime)
+}
+
+// ResolveEnrollmentsOverTime resolves the enrollments in a Course day by day.
+// @Summary Resolve the enrollments in a Course over time.
+// @Description This endpoint counts, day by day, the students who enrolled in a Course the teacher may view the
+// @Description students of and those who withdrew or were removed from it. Enrollments that are still pending or
+// @Description waitlisted are not counted.
+// @Tags courses/analytics
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only count the students of this cohort."
+// @Param from query string false "The first day (YYYY-MM-DD) to count; defaults to 29 days before to."
+// @Param to query string false "The last day (YYYY-MM-DD) to count; defaults to today."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.EnrollmentsDayResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/analytics/enrollments [get]
+func (h *AnalyticsHandler) ResolveEnrollmentsOverTime(w http.ResponseWriter, r *http.Request) {
+	params, err := analyticsParamsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	days, err := h.AnalyticsService.ResolveEnrollmentsOverTime(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, days)
+}
+
+// ResolveQuizScoreDistributions resolves how the scores of the Quizzes of a Course are distributed.
+// @Summary Resolve the quiz score distributions of a Course.
+// @Description This endpoint distributes the scores of the attempts submitted over the given days at each active quiz
+// @Description of a Course the teacher may view the students of across ten ranges of percentages, from 0-10% to
+// @Description 90-100%. Each range includes its lower bound; the last one also includes perfect scores.
+// @Tags courses/analytics
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only count the students of this cohort."
+// @Param from query string false "The first day (YYYY-MM-DD) to count; defaults to 29 days before to."
+// @Param to query string false "The last day (YYYY-MM-DD) to count; defaults to today."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.QuizScoreDistribution}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/analytics/quiz-scores [get]
+func (h *AnalyticsHandler) ResolveQuizScoreDistributions(w http.ResponseWriter, r *http.Request) {
+	params, err := analyticsParamsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	distributions, err := h.AnalyticsService.ResolveQuizScoreDistributions(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, distributions)
+}
+
+// analyticsParamsFromRequest reads the Course, Cohort and range of days
+// analytics are filtered by from the request.
+func analyticsParamsFromRequest(r *http.Request) (params course.AnalyticsQueryParameters, err error) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		return
+	}
+
+	var cohortID nuuid.NUUID
+	if value := r.URL.Query().Get("cohortID"); value != "" {
+		id, err := uuid.FromString(value)
+		if err != nil {
+			return params, failure.BadRequest(err)
+		}
+
+		cohortID = nuuid.From(id)
+	}
+
+	from, err := timeFromQueryParam(r, "from", false)
+	if err != nil {
+		return
+	}
+
+	to, err := timeFromQueryParam(r, "to", true)
+	if err != nil {
+		return
+	}
+
+	params, err = course.NewAnalyticsQueryParameters(courseID, cohortID, from, to, time.Now())
+	if err != nil {
+		return params, failure.BadRequest(err)
+	}
+
+	return
+}
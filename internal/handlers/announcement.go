@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+)
+
+// AnnouncementHandler is the HTTP handler for Announcements.
+type AnnouncementHandler struct {
+	AnnouncementService course.AnnouncementService
+	AuthMiddleware      *middleware.Authentication
+}
+
+// ProvideAnnouncementHandler is the provider for this handler.
+func ProvideAnnouncementHandler(announcementService course.AnnouncementService, authMiddleware *middleware.Authentication) AnnouncementHandler {
+	return AnnouncementHandler{
+		AnnouncementService: announcementService,
+		AuthMiddleware:      authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *AnnouncementHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/announcements", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveAnnouncements)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateAnnouncement)
+		})
+	})
+
+	r.Route("/announcements", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveAnnouncementByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/unread-count", h.ResolveUnreadAnnouncements)
+			r.Post("/{id}/read", h.MarkAnnouncementRead)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Delete("/{id}", h.DeleteAnnouncement)
+			r.Get("/{id}/receipts", h.ResolveAnnouncementReceipts)
+		})
+	})
+}
+
+// CreateAnnouncement posts an Announcement to a Course.
+// @Summary Post an Announcement to a Course.
+// @Description This endpoint posts an Announcement to every student actively enrolled in a Course, or only to
+// @Description those of one of its Cohorts. The Announcement is published for delivery by the notification
+// @Description services. Only teachers who may post announcements on the Course can do this.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param announcement body course.AnnouncementRequestFormat true "The Announcement to be posted."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.AnnouncementResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/announcements [post]
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.AnnouncementRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	announcement, err := h.AnnouncementService.CreateAnnouncement(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, announcement)
+}
+
+// DeleteAnnouncement deletes an Announcement.
+// @Summary Delete an Announcement.
+// @Description This endpoint deletes an Announcement. Notifications already delivered are not recalled.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Announcement's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AnnouncementResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/announcements/{id} [delete]
+func (h *AnnouncementHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	announcement, err := h.AnnouncementService.DeleteAnnouncement(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, announcement)
+}
+
+// MarkAnnouncementRead marks an Announcement as read.
+// @Summary Mark an Announcement as read.
+// @Description This endpoint records the student reading an Announcement posted to them. Marking it read again
+// @Description keeps the first read time.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Announcement's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AnnouncementReceiptResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/announcements/{id}/read [post]
+func (h *AnnouncementHandler) MarkAnnouncementRead(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	receipt, err := h.AnnouncementService.MarkAnnouncementRead(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, receipt)
+}
+
+// ResolveAnnouncementByID resolves an Announcement.
+// @Summary Resolve an Announcement.
+// @Description This endpoint resolves an Announcement. Students can only resolve the Announcements posted to
+// @Description them, which tell whether they read them.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Announcement's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AnnouncementResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/announcements/{id} [get]
+func (h *AnnouncementHandler) ResolveAnnouncementByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	announcement, err := h.AnnouncementService.ResolveAnnouncementByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, announcement)
+}
+
+// ResolveAnnouncementReceipts resolves the read receipts of an Announcement.
+// @Summary Resolve the read receipts of an Announcement.
+// @Description This endpoint resolves which students read an Announcement and when, along with how many of
+// @Description its recipients have not read it yet. Only teachers who may view the students of the Course can
+// @Description do this.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Announcement's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AnnouncementReceiptsResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/announcements/{id}/receipts [get]
+func (h *AnnouncementHandler) ResolveAnnouncementReceipts(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	receipts, err := h.AnnouncementService.ResolveAnnouncementReceipts(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, receipts)
+}
+
+// ResolveAnnouncements resolves the Announcements of a Course.
+// @Summary Resolve the Announcements of a Course.
+// @Description This endpoint resolves a page of the Announcements of a Course, newest first. Students see the
+// @Description Announcements posted to the whole Course or to their Cohort, each telling whether they read it.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohortID query string false "Only list the Announcements posted to this Cohort. Teachers only."
+// @Param page query int false "The page number, starting from 0."
+// @Param limit query int false "The page size, up to 100. Defaults to 20."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.AnnouncementResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/announcements [get]
+func (h *AnnouncementHandler) ResolveAnnouncements(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	params := course.AnnouncementQueryParameters{
+		CourseID: courseID,
+		Limit:    20,
+	}
+
+	if cohortID := query.Get("cohortID"); cohortID != "" {
+		id, err := uuid.FromString(cohortID)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		params.CohortID = nuuid.From(id)
+	}
+
+	if page := query.Get("page"); page != "" {
+		params.Page, err = convertQueryParamsToInt(page)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		params.Limit, err = convertQueryParamsToInt(limit)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	announcements, err := h.AnnouncementService.ResolveAnnouncements(params, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, announcements)
+}
+
+// ResolveUnreadAnnouncements counts the Announcements the student has yet to read.
+// @Summary Count the unread Announcements.
+// @Description This endpoint counts the Announcements the student has yet to read, in total and per Course,
+// @Description over all the Courses they are actively enrolled in.
+// @Tags courses/announcements
+// @Security EVMOauthToken
+// @Produce json
+// @Success 200 {object} response.Base{data=course.UnreadAnnouncementsResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/announcements/unread-count [get]
+func (h *AnnouncementHandler) ResolveUnreadAnnouncements(w http.ResponseWriter, r *http.Request) {
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	unread, err := h.AnnouncementService.ResolveUnreadAnnouncements(claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, unread)
+}
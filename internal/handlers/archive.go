@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// archiveMemoryLimit is the part of an uploaded archive kept in memory; the
+// rest is buffered in a temporary file.
+const archiveMemoryLimit = 10 << 20
+
+// ArchiveHandler is the HTTP handler for exporting Courses to archives and
+// importing them back.
+type ArchiveHandler struct {
+	ArchiveService course.ArchiveService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideArchiveHandler is the provider for this handler.
+func ProvideArchiveHandler(archiveService course.ArchiveService, authMiddleware *middleware.Authentication) ArchiveHandler {
+	return ArchiveHandler{
+		ArchiveService: archiveService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *ArchiveHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/export", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ExportCourse)
+		})
+	})
+
+	r.Route("/courses/import", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.ImportCourse)
+		})
+	})
+}
+
+// ExportCourse exports a Course as an archive.
+// @Summary Export a Course as an archive.
+// @Description This endpoint exports a Course as a zip archive to be imported elsewhere, such as from staging
+// @Description into production. The archive holds the Course's details, categories, tags and prerequisites,
+// @Description its Modules, Lessons and Quizzes with their answer keys, and the files of its Attachments. A
+// @Description versioned manifest lists every file with its SHA-256 checksum. Deleted content and student
+// @Description data are left out. Only teachers who may export the Course can do this.
+// @Tags courses/archives
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/export [get]
+func (h *ArchiveHandler) ExportCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	archive, exported, err := h.ArchiveService.ExportCourse(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.FormatInt(archive.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="course-%s.zip"`, exported.ID))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
+
+// ImportCourse imports a Course from an archive.
+// @Summary Import a Course from an archive.
+// @Description This endpoint imports a Course from a zip archive made by the export endpoint, as a new draft
+// @Description owned by the importing teacher. The archive is sent as the "archive" part of a multipart form.
+// @Description Every file is verified against the checksums in the manifest, and every ID is replaced; the
+// @Description response maps the archived IDs to the new ones. Categories and prerequisite Courses that don't
+// @Description exist here are left out and reported as conflicts. If any part of the import fails, nothing
+// @Description is imported.
+// @Tags courses/archives
+// @Security EVMOauthToken
+// @Param archive formData file true "The archive to import."
+// @Accept multipart/form-data
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CourseImportResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/import [post]
+func (h *ArchiveHandler) ImportCourse(w http.ResponseWriter, r *http.Request) {
+	// leave room for the multipart framing
+	maxSize := h.ArchiveService.ArchiveSizeLimit()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	err := r.ParseMultipartForm(archiveMemoryLimit)
+	if err != nil {
+		response.WithError(w, failure.BadRequestFromString(fmt.Sprintf("the upload must be a multipart form of at most %d bytes", maxSize)))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("archive")
+	if err != nil {
+		response.WithError(w, failure.BadRequest(err))
+		return
+	}
+	defer file.Close()
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	courseImport, err := h.ArchiveService.ImportCourse(file, header.Size, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, courseImport.ToResponseFormat())
+}
@@ -0,0 +1,561 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// AssignmentHandler is the HTTP handler for Assignments and their Submissions.
+type AssignmentHandler struct {
+	AssignmentService course.AssignmentService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvideAssignmentHandler is the provider for this handler.
+func ProvideAssignmentHandler(assignmentService course.AssignmentService, authMiddleware *middleware.Authentication) AssignmentHandler {
+	return AssignmentHandler{
+		AssignmentService: assignmentService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *AssignmentHandler) Router(r chi.Router) {
+	r.Route("/lessons/{id}/assignments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveAssignmentsByLessonID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateAssignment)
+		})
+	})
+
+	r.Route("/courses/{id}/grading-queue", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveGradingQueue)
+		})
+	})
+
+	r.Route("/assignments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveAssignmentByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}", h.UpdateAssignment)
+			r.Delete("/{id}", h.SoftDeleteAssignment)
+			r.Get("/{id}/submissions", h.ResolveSubmissions)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/{id}/submissions", h.Submit)
+			r.Get("/{id}/submissions/me", h.ResolveMySubmission)
+		})
+	})
+
+	r.Route("/submissions", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/review", h.StartReview)
+			r.Put("/{id}/grade", h.GradeSubmission)
+			r.Put("/{id}/return", h.ReturnSubmission)
+		})
+	})
+}
+
+// CreateAssignment attaches a new Assignment to a Lesson.
+// @Summary Create a new Assignment.
+// @Description This endpoint attaches a new Assignment to a Lesson. With a rubric, the maximum score is the sum of its criteria.
+// @Description Late submissions lose latePenaltyPerDay percent per started day, up to maxLatePenalty, and are refused after lateCutoffAt.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Param assignment body course.AssignmentRequestFormat true "The Assignment to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.AssignmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/assignments [post]
+func (h *AssignmentHandler) CreateAssignment(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.AssignmentRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	assignment, err := h.AssignmentService.CreateAssignment(lessonID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, assignment)
+}
+
+// ResolveAssignmentsByLessonID lists the Assignments of a Lesson.
+// @Summary List the Assignments of a Lesson.
+// @Description This endpoint lists the Assignments of a Lesson by due date.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.AssignmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/assignments [get]
+func (h *AssignmentHandler) ResolveAssignmentsByLessonID(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	assignments, err := h.AssignmentService.ResolveAssignmentsByLessonID(lessonID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, assignments)
+}
+
+// ResolveGradingQueue lists the Submissions of a Course waiting for a teacher.
+// @Summary Resolve the grading queue of a Course.
+// @Description This endpoint lists the submitted and in-review Submissions of a Course, oldest first. Only the course owner may do this.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/grading-queue [get]
+func (h *AssignmentHandler) ResolveGradingQueue(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submissions, err := h.AssignmentService.ResolveGradingQueue(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submissions)
+}
+
+// ResolveAssignmentByID resolves an Assignment.
+// @Summary Resolve an Assignment.
+// @Description This endpoint resolves an Assignment with its rubric.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AssignmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id} [get]
+func (h *AssignmentHandler) ResolveAssignmentByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	assignment, err := h.AssignmentService.ResolveAssignmentByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, assignment)
+}
+
+// UpdateAssignment updates an Assignment.
+// @Summary Update an Assignment.
+// @Description This endpoint updates an Assignment. Without a rubric in the request the current one is kept;
+// @Description the rubric of an Assignment with submissions cannot be replaced.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Param assignment body course.AssignmentRequestFormat true "The Assignment to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AssignmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id} [put]
+func (h *AssignmentHandler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.AssignmentRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	assignment, err := h.AssignmentService.UpdateAssignment(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, assignment)
+}
+
+// SoftDeleteAssignment marks an Assignment as deleted.
+// @Summary Delete an Assignment.
+// @Description This endpoint marks an Assignment as deleted. Its submissions are kept.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AssignmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id} [delete]
+func (h *AssignmentHandler) SoftDeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	assignment, err := h.AssignmentService.SoftDeleteAssignment(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, assignment)
+}
+
+// ResolveSubmissions lists the Submissions for an Assignment.
+// @Summary List the Submissions for an Assignment.
+// @Description This endpoint lists the Submissions for an Assignment, oldest first. Only the course owner may do this.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Param status query string false "Only list submissions with these comma-separated statuses."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id}/submissions [get]
+func (h *AssignmentHandler) ResolveSubmissions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var statuses []course.SubmissionStatus
+	if status := r.URL.Query().Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			statuses = append(statuses, course.SubmissionStatus(s))
+		}
+	}
+
+	submissions, err := h.AssignmentService.ResolveSubmissionsByAssignmentID(id, statuses, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submissions)
+}
+
+// Submit hands in the current student's work for an Assignment.
+// @Summary Submit an Assignment.
+// @Description This endpoint hands in a text, links or both for an Assignment. A returned submission is handed in again as a new revision.
+// @Description Late submissions are penalised and refused after the late cutoff.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Param submission body course.SubmissionRequestFormat true "The work to be handed in."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id}/submissions [post]
+func (h *AssignmentHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.SubmissionRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submission, err := h.AssignmentService.Submit(assignmentID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submission)
+}
+
+// ResolveMySubmission resolves the current student's Submission for an Assignment.
+// @Summary Resolve my Submission.
+// @Description This endpoint resolves the current student's Submission for an Assignment with its grade and feedback.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Assignment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/assignments/{id}/submissions/me [get]
+func (h *AssignmentHandler) ResolveMySubmission(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submission, err := h.AssignmentService.ResolveMySubmission(assignmentID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submission)
+}
+
+// StartReview takes a Submission out of the grading queue for review.
+// @Summary Start reviewing a Submission.
+// @Description This endpoint moves a submitted Submission to in review.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Submission's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/submissions/{id}/review [put]
+func (h *AssignmentHandler) StartReview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submission, err := h.AssignmentService.StartReview(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submission)
+}
+
+// GradeSubmission grades a Submission under review.
+// @Summary Grade a Submission.
+// @Description This endpoint grades a Submission under review, with a single score or per rubric criterion. The late penalty is deducted from the raw score.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Submission's identifier."
+// @Param grade body course.GradeRequestFormat true "The grade."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/submissions/{id}/grade [put]
+func (h *AssignmentHandler) GradeSubmission(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.GradeRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submission, err := h.AssignmentService.GradeSubmission(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submission)
+}
+
+// ReturnSubmission returns a Submission to the student for revision.
+// @Summary Return a Submission for revision.
+// @Description This endpoint returns a Submission under review or graded to the student, who may then hand in a revision.
+// @Tags courses/assignments
+// @Security EVMOauthToken
+// @Param id path string true "The Submission's identifier."
+// @Param revision body course.ReturnRequestFormat true "The feedback for the student."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.SubmissionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/submissions/{id}/return [put]
+func (h *AssignmentHandler) ReturnSubmission(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReturnRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	submission, err := h.AssignmentService.ReturnSubmission(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, submission)
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// attachmentMemoryLimit is the part of a multipart upload kept in memory;
+// the rest is buffered in temporary files.
+const attachmentMemoryLimit = 10 << 20
+
+// AttachmentHandler is the HTTP handler for Attachments of Lessons.
+type AttachmentHandler struct {
+	AttachmentService course.AttachmentService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvideAttachmentHandler is the provider for this handler.
+func ProvideAttachmentHandler(attachmentService course.AttachmentService, authMiddleware *middleware.Authentication) AttachmentHandler {
+	return AttachmentHandler{
+		AttachmentService: attachmentService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *AttachmentHandler) Router(r chi.Router) {
+	r.Route("/lessons/{id}/attachments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveAttachmentsByLessonID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateAttachment)
+		})
+	})
+
+	r.Route("/attachments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveAttachmentByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Delete("/{id}", h.DeleteAttachment)
+		})
+	})
+
+	// download links are handed to browsers, which can't send an
+	// Authorization header, so they are authenticated by their signature
+	r.Get("/files/*", h.DownloadFile)
+}
+
+// CreateAttachment attaches a file to a Lesson.
+// @Summary Attach a file to a Lesson.
+// @Description This endpoint uploads a file, such as a PDF, an image or a code archive, and attaches it to a
+// @Description Lesson. The file is sent as the "file" part of a multipart form. Its type is detected from its
+// @Description content and must be PDF, PNG, JPEG, GIF, WebP, ZIP, gzip or plain text. An optional "checksum"
+// @Description part carries the file's hex-encoded SHA-256 digest to detect corrupted uploads.
+// @Tags lessons/attachments
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Param file formData file true "The file to attach."
+// @Param checksum formData string false "The hex-encoded SHA-256 digest of the file."
+// @Accept multipart/form-data
+// @Produce json
+// @Success 201 {object} response.Base{data=course.AttachmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/attachments [post]
+func (h *AttachmentHandler) CreateAttachment(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	// leave room for the multipart framing and the other form parts
+	maxSize := h.AttachmentService.AttachmentSizeLimit()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	err = r.ParseMultipartForm(attachmentMemoryLimit)
+	if err != nil {
+		response.WithError(w, failure.BadRequestFromString(fmt.Sprintf("the upload must be a multipart form of at most %d bytes", maxSize)))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.WithError(w, failure.BadRequest(err))
+		return
+	}
+	defer file.Close()
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	upload := course.AttachmentUpload{
+		FileName: header.Filename,
+		Size:     header.Size,
+		Body:     file,
+		Checksum: r.FormValue("checksum"),
+	}
+
+	attachment, err := h.AttachmentService.CreateAttachment(lessonID, upload, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, attachment)
+}
+
+// DeleteAttachment deletes an Attachment.
+// @Summary Delete an Attachment.
+// @Description This endpoint deletes an Attachment and its file. Download links handed out before stop working.
+// @Tags lessons/attachments
+// @Security EVMOauthToken
+// @Param id path string true "The Attachment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AttachmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attachment, err := h.AttachmentService.DeleteAttachment(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attachment)
+}
+
+// DownloadFile downloads a file through a signed link.
+// @Summary Download a file through a signed link.
+// @Description This endpoint serves the files of the local blob storage through the signed, time-limited links
+// @Description found in the downloadURL of Attachments. It needs no Authorization header.
+// @Tags lessons/attachments
+// @Param key path string true "The storage key of the file."
+// @Param expires query int true "When the link expires, as a Unix timestamp."
+// @Param filename query string true "The name the file is saved under."
+// @Param signature query string true "The signature of the link."
+// @Produce octet-stream
+// @Success 200 {file} file
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/files/{key} [get]
+func (h *AttachmentHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	fileName := query.Get("filename")
+
+	file, err := h.AttachmentService.OpenSignedFile(chi.URLParam(r, "*"), fileName, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(fileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, file); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
+
+// ResolveAttachmentByID resolves an Attachment.
+// @Summary Resolve an Attachment.
+// @Description This endpoint resolves an Attachment with a fresh, time-limited download link. Students need to be
+// @Description able to read its Lesson.
+// @Tags lessons/attachments
+// @Security EVMOauthToken
+// @Param id path string true "The Attachment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AttachmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/attachments/{id} [get]
+func (h *AttachmentHandler) ResolveAttachmentByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attachment, err := h.AttachmentService.ResolveAttachmentByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attachment)
+}
+
+// ResolveAttachmentsByLessonID resolves the Attachments of a Lesson.
+// @Summary Resolve the Attachments of a Lesson.
+// @Description This endpoint resolves the Attachments of a Lesson, oldest first, each with a fresh, time-limited
+// @Description download link. Students need to be able to read the Lesson.
+// @Tags lessons/attachments
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.AttachmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/attachments [get]
+func (h *AttachmentHandler) ResolveAttachmentsByLessonID(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attachments, err := h.AttachmentService.ResolveAttachmentsByLessonID(lessonID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attachments)
+}
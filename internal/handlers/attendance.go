@@ -0,0 +1,590 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+)
+
+// AttendanceHandler is the HTTP handler for LiveSession attendance and course
+// completion rules.
+type AttendanceHandler struct {
+	AttendanceService course.AttendanceService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvideAttendanceHandler is the provider for this handler.
+func ProvideAttendanceHandler(attendanceService course.AttendanceService, authMiddleware *middleware.Authentication) AttendanceHandler {
+	return AttendanceHandler{
+		AttendanceService: attendanceService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *AttendanceHandler) Router(r chi.Router) {
+	r.Route("/sessions/{id}/occurrences/{sequence}", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/attendance", h.ResolveAttendanceSheet)
+			r.Put("/attendance", h.MarkAttendance)
+			r.Put("/attendance/{studentID}", h.MarkStudentAttendance)
+			r.Post("/check-in-code", h.OpenCheckIn)
+		})
+	})
+
+	r.Route("/sessions/{id}/check-in", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/", h.CheckIn)
+		})
+	})
+
+	r.Route("/courses/{id}/attendance", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/me", h.ResolveMyAttendance)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveCourseAttendance)
+			r.Get("/students/{studentID}", h.ResolveStudentAttendance)
+		})
+	})
+
+	r.Route("/cohorts/{id}/attendance", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveCohortAttendance)
+		})
+	})
+
+	r.Route("/courses/{id}/completion-rules", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveCompletionRules)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.UpdateCompletionRules)
+		})
+	})
+}
+
+// CheckIn checks the current student in to a LiveSession.
+// @Summary Check in to a live session.
+// @Description This endpoint checks the current student in to the occurrence of a live session the teacher opened a
+// @Description check-in code for. Codes are short-lived; students checking in more than ten minutes after the
+// @Description occurrence started are marked late.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param checkIn body course.CheckInRequestFormat true "The check-in code."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.AttendanceRecordResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id}/check-in [post]
+func (h *AttendanceHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CheckInRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	record, err := h.AttendanceService.CheckIn(sessionID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, record)
+}
+
+// MarkAttendance marks the attendance of an occurrence of a LiveSession in bulk.
+// @Summary Mark the attendance of a live session occurrence.
+// @Description This endpoint marks students present, late, absent or excused at an occurrence of a live session, up
+// @Description to 500 at a time. When remainingStatus is set, the expected students who are neither listed nor marked
+// @Description yet are marked with it. Occurrences are numbered from 1 in the order they take place.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param sequence path int true "The occurrence's number."
+// @Param attendance body course.AttendanceRequestFormat true "The attendance to be marked."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AttendanceSheet}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id}/occurrences/{sequence}/attendance [put]
+func (h *AttendanceHandler) MarkAttendance(w http.ResponseWriter, r *http.Request) {
+	sessionID, sequence, err := occurrenceFromURLParams(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.AttendanceRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	sheet, err := h.AttendanceService.MarkAttendance(sessionID, sequence, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, sheet)
+}
+
+// MarkStudentAttendance marks the attendance of a single student.
+// @Summary Mark the attendance of a student.
+// @Description This endpoint marks a single student present, late, absent or excused at an occurrence of a live
+// @Description session, replacing what was marked before.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param sequence path int true "The occurrence's number."
+// @Param studentID path string true "The student's identifier."
+// @Param attendance body course.AttendanceMarkRequestFormat true "The attendance to be marked."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AttendanceRecordResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id}/occurrences/{sequence}/attendance/{studentID} [put]
+func (h *AttendanceHandler) MarkStudentAttendance(w http.ResponseWriter, r *http.Request) {
+	sessionID, sequence, err := occurrenceFromURLParams(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	studentID, err := uuidFromURLParam(r, "studentID")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.AttendanceMarkRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	record, err := h.AttendanceService.MarkStudentAttendance(sessionID, sequence, studentID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, record)
+}
+
+// OpenCheckIn opens a check-in code for an occurrence of a LiveSession.
+// @Summary Open the check-in of a live session occurrence.
+// @Description This endpoint opens a short-lived code students check in to an occurrence of a live session with,
+// @Description valid for ten minutes unless asked otherwise. Opening a new code replaces the previous one.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param sequence path int true "The occurrence's number."
+// @Param checkInCode body course.CheckInCodeRequestFormat true "How long the code stays valid."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CheckInCodeResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id}/occurrences/{sequence}/check-in-code [post]
+func (h *AttendanceHandler) OpenCheckIn(w http.ResponseWriter, r *http.Request) {
+	sessionID, sequence, err := occurrenceFromURLParams(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CheckInCodeRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	checkInCode, err := h.AttendanceService.OpenCheckIn(sessionID, sequence, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, checkInCode)
+}
+
+// ResolveAttendanceSheet resolves the attendance of an occurrence of a LiveSession.
+// @Summary Resolve the attendance of a live session occurrence.
+// @Description This endpoint lists every student expected at an occurrence of a live session with the attendance
+// @Description marked for them so far. Students attendance was not taken for are unmarked.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param sequence path int true "The occurrence's number."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.AttendanceSheet}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id}/occurrences/{sequence}/attendance [get]
+func (h *AttendanceHandler) ResolveAttendanceSheet(w http.ResponseWriter, r *http.Request) {
+	sessionID, sequence, err := occurrenceFromURLParams(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	sheet, err := h.AttendanceService.ResolveAttendanceSheet(sessionID, sequence, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, sheet)
+}
+
+// ResolveCohortAttendance summarises the attendance of the students of a Cohort.
+// @Summary Resolve the attendance report of a Cohort.
+// @Description This endpoint summarises the attendance of the active students of a Cohort, for the course owner and
+// @Description the cohort's instructors. Pass format=csv to download the report as CSV.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Param format query string false "Set to csv for a CSV download."
+// @Produce json,text/csv
+// @Success 200 {object} response.Base{data=course.AttendanceReport}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id}/attendance [get]
+func (h *AttendanceHandler) ResolveCohortAttendance(w http.ResponseWriter, r *http.Request) {
+	cohortID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.AttendanceService.ResolveCohortAttendance(cohortID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	writeReport(w, r, report, "attendance-"+cohortID.String()+".csv", report.WriteCSV)
+}
+
+// ResolveCompletionRules resolves the completion rules of a Course.
+// @Summary Resolve the completion rules of a Course.
+// @Description This endpoint resolves what a student needs to complete a Course besides its lessons, such as the
+// @Description share of live sessions they must attend.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CompletionRulesResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/completion-rules [get]
+func (h *AttendanceHandler) ResolveCompletionRules(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	rules, err := h.AttendanceService.ResolveCompletionRules(courseID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, rules)
+}
+
+// ResolveCourseAttendance summarises the attendance of the students of a Course.
+// @Summary Resolve the attendance report of a Course.
+// @Description This endpoint summarises the attendance of every active student of a Course owned by the teacher.
+// @Description Present and late students attended; excused occurrences don't count toward the attendance percentage,
+// @Description unmarked ones count as absences. Pass format=csv to download the report as CSV.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param format query string false "Set to csv for a CSV download."
+// @Produce json,text/csv
+// @Success 200 {object} response.Base{data=course.AttendanceReport}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/attendance [get]
+func (h *AttendanceHandler) ResolveCourseAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.AttendanceService.ResolveCourseAttendance(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	writeReport(w, r, report, "attendance-"+courseID.String()+".csv", report.WriteCSV)
+}
+
+// ResolveMyAttendance details the attendance of the current student in a Course.
+// @Summary Resolve my attendance in a Course.
+// @Description This endpoint details the attendance of the current student in a Course, occurrence by occurrence.
+// @Description Pass format=csv to download the report as CSV.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param format query string false "Set to csv for a CSV download."
+// @Produce json,text/csv
+// @Success 200 {object} response.Base{data=course.StudentAttendanceReport}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/attendance/me [get]
+func (h *AttendanceHandler) ResolveMyAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.AttendanceService.ResolveStudentAttendance(courseID, claims.UserID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	writeReport(w, r, report, "attendance.csv", report.WriteCSV)
+}
+
+// ResolveStudentAttendance details the attendance of a student in a Course.
+// @Summary Resolve the attendance of a student in a Course.
+// @Description This endpoint details the attendance of a student in a Course owned by the teacher, occurrence by
+// @Description occurrence. Pass format=csv to download the report as CSV.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param studentID path string true "The student's identifier."
+// @Param format query string false "Set to csv for a CSV download."
+// @Produce json,text/csv
+// @Success 200 {object} response.Base{data=course.StudentAttendanceReport}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/attendance/students/{studentID} [get]
+func (h *AttendanceHandler) ResolveStudentAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	studentID, err := uuidFromURLParam(r, "studentID")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.AttendanceService.ResolveStudentAttendance(courseID, studentID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	writeReport(w, r, report, "attendance-"+studentID.String()+".csv", report.WriteCSV)
+}
+
+// UpdateCompletionRules sets the completion rules of a Course.
+// @Summary Set the completion rules of a Course.
+// @Description This endpoint sets what a student needs to complete a Course owned by the teacher besides its lessons.
+// @Description With minAttendancePercent set, certificates are only issued to students who attended at least that
+// @Description share of the live sessions held so far; leaving it out drops the requirement.
+// @Tags courses/attendance
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param rules body course.CompletionRulesRequestFormat true "The completion rules."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CompletionRulesResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/completion-rules [put]
+func (h *AttendanceHandler) UpdateCompletionRules(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CompletionRulesRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	rules, err := h.AttendanceService.UpdateCompletionRules(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, rules)
+}
+
+// occurrenceFromURLParams parses the path parameters identifying an occurrence
+// of a LiveSession.
+func occurrenceFromURLParams(r *http.Request) (sessionID uuid.UUID, sequence int, err error) {
+	sessionID, err = uuidFromURLParam(r, "id")
+	if err != nil {
+		return
+	}
+
+	sequence, err = convertQueryParamsToInt(chi.URLParam(r, "sequence"))
+	if err != nil {
+		return sessionID, sequence, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// writeReport writes a report as JSON, or as a CSV download when the request
+// asks for format=csv.
+func writeReport(w http.ResponseWriter, r *http.Request, report interface{}, filename string, writeCSV func(io.Writer) error) {
+	if r.URL.Query().Get("format") != "csv" {
+		response.WithJSON(w, http.StatusOK, report)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf); err != nil {
+		response.WithError(w, failure.InternalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// CertificateHandler is the HTTP handler for completion Certificates.
+type CertificateHandler struct {
+	CertificateService course.CertificateService
+	AuthMiddleware     *middleware.Authentication
+}
+
+// ProvideCertificateHandler is the provider for this handler.
+func ProvideCertificateHandler(certificateService course.CertificateService, authMiddleware *middleware.Authentication) CertificateHandler {
+	return CertificateHandler{
+		CertificateService: certificateService,
+		AuthMiddleware:     authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *CertificateHandler) Router(r chi.Router) {
+	r.Route("/certificates", func(r chi.Router) {
+		// verification is public so that anyone shown a Certificate can check it
+		r.Get("/verify/{code}", h.VerifyCertificate)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/", h.ResolveMyCertificates)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveCertificateByID)
+			r.Get("/{id}/pdf", h.DownloadCertificatePDF)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/revoke", h.RevokeCertificate)
+		})
+	})
+
+	r.Route("/courses/{id}/certificate", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/", h.ClaimCertificate)
+		})
+	})
+
+	r.Route("/courses/{id}/certificates", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveCourseCertificates)
+		})
+	})
+}
+
+// ClaimCertificate issues the Certificate of a completed Course.
+// @Summary Claim the Certificate of a completed Course.
+// @Description This endpoint issues the Certificate of a Course the student completed. Certificates are issued
+// @Description automatically when the last Lesson is completed, so this returns the existing one if there is any.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CertificateResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/certificate [post]
+func (h *CertificateHandler) ClaimCertificate(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	certificate, err := h.CertificateService.ClaimCertificate(courseID, claims.UserID, claims.Username)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, certificate)
+}
+
+// ResolveCourseCertificates resolves the Certificates issued for a Course.
+// @Summary Resolve the Certificates of a Course.
+// @Description This endpoint resolves the Certificates issued for a Course owned by the teacher, newest first.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CertificateResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/certificates [get]
+func (h *CertificateHandler) ResolveCourseCertificates(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	certificates, err := h.CertificateService.ResolveCertificatesByCourseID(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, certificates)
+}
+
+// ResolveMyCertificates resolves the Certificates of the current student.
+// @Summary Resolve my Certificates.
+// @Description This endpoint resolves the Certificates issued to the current student, newest first.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CertificateResponseFormat}
+// @Failure 500 {object} response.Base
+// @Router /v1/certificates [get]
+func (h *CertificateHandler) ResolveMyCertificates(w http.ResponseWriter, r *http.Request) {
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	certificates, err := h.CertificateService.ResolveCertificatesByStudentID(claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, certificates)
+}
+
+// ResolveCertificateByID resolves a Certificate.
+// @Summary Resolve Certificate by ID
+// @Description This endpoint resolves a Certificate for the student it was issued to or the owner of its Course.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Param id path string true "The Certificate's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CertificateResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/certificates/{id} [get]
+func (h *CertificateHandler) ResolveCertificateByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	certificate, err := h.CertificateService.ResolveCertificateByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, certificate)
+}
+
+// DownloadCertificatePDF downloads a Certificate as a PDF document.
+// @Summary Download a Certificate as PDF.
+// @Description This endpoint renders a Certificate as a PDF document carrying its verification code and the
+// @Description address where it can be verified, made out to the name the student had when it was issued.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Param id path string true "The Certificate's identifier."
+// @Produce application/pdf
+// @Success 200 {file} file
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/certificates/{id}/pdf [get]
+func (h *CertificateHandler) DownloadCertificatePDF(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	document, certificate, err := h.CertificateService.RenderCertificatePDF(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="certificate-%s.pdf"`, certificate.Code))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(document); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
+
+// RevokeCertificate revokes a Certificate.
+// @Summary Revoke a Certificate.
+// @Description This endpoint revokes a Certificate of a Course owned by the teacher. A revoked Certificate
+// @Description still verifies as authentic but is reported as revoked, and is not issued again.
+// @Tags courses/certificates
+// @Security EVMOauthToken
+// @Param id path string true "The Certificate's identifier."
+// @Param revocation body course.CertificateRevocationRequestFormat true "Why the Certificate is revoked."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CertificateResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/certificates/{id}/revoke [put]
+func (h *CertificateHandler) RevokeCertificate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CertificateRevocationRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	certificate, err := h.CertificateService.RevokeCertificate(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, certificate)
+}
+
+// VerifyCertificate verifies a Certificate by its code.
+// @Summary Verify a Certificate.
+// @Description This public endpoint tells whether the Certificate with the given verification code is valid,
+// @Description revoked, or carries a signature that does not match its content. Codes are case-insensitive.
+// @Tags courses/certificates
+// @Param code path string true "The Certificate's verification code, e.g. 7KQM-2XHD-9TRW."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CertificateVerificationFormat}
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/certificates/verify/{code} [get]
+func (h *CertificateHandler) VerifyCertificate(w http.ResponseWriter, r *http.Request) {
+	verification, err := h.CertificateService.VerifyCertificate(chi.URLParam(r, "code"))
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, verification)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// CloneHandler is the HTTP handler for cloning Courses.
+type CloneHandler struct {
+	CloneService   course.CloneService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideCloneHandler is the provider for this handler.
+func ProvideCloneHandler(cloneService course.CloneService, authMiddleware *middleware.Authentication) CloneHandler {
+	return CloneHandler{
+		CloneService:   cloneService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *CloneHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/clone", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CloneCourse)
+		})
+	})
+}
+
+// CloneCourse copies a Course into a new draft.
+// @Summary Clone a Course into a new draft.
+// @Description This endpoint copies a Course into a new draft owned by the teacher, such as to start a new run
+// @Description from last term's Course. Its Modules, Lessons, Quizzes, Assignments, Attachments, Cohorts, Live
+// @Description Sessions, categories, tags and prerequisites are copied under new IDs; the response maps the
+// @Description source IDs to the new ones. Cohorts are copied without their instructors. The dates of
+// @Description Assignments, Cohorts and Live Sessions are moved by shiftDays, keeping their local time. Student
+// @Description data such as enrollments, progress, attempts and submissions is never copied. The request body
+// @Description is optional. Only teachers who may clone the Course can do this.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param clone body course.CourseCloneRequestFormat false "The title of the clone and how many days to move its schedule."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CourseCloneResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/clone [post]
+func (h *CloneHandler) CloneCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CourseCloneRequestFormat
+	if r.ContentLength != 0 {
+		err = decodeRequest(r, &requestFormat)
+		if err != nil {
+			response.WithError(w, err)
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	clone, err := h.CloneService.CloneCourse(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, clone.ToResponseFormat())
+}
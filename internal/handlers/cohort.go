@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// CohortHandler is the HTTP handler for Cohorts and their drip schedules.
+type CohortHandler struct {
+	CohortService  course.CohortService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideCohortHandler is the provider for this handler.
+func ProvideCohortHandler(cohortService course.CohortService, authMiddleware *middleware.Authentication) CohortHandler {
+	return CohortHandler{
+		CohortService:  cohortService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *CohortHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/cohorts", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveCourseCohorts)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateCohort)
+		})
+	})
+
+	r.Route("/cohorts", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveCohortByID)
+			r.Get("/{id}/schedule", h.ResolveCohortSchedule)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}", h.UpdateCohort)
+			r.Delete("/{id}", h.DeleteCohort)
+			r.Get("/{id}/roster", h.ResolveCohortRoster)
+			r.Put("/{id}/schedule", h.SetCohortSchedule)
+		})
+	})
+}
+
+// CreateCohort creates a new Cohort of a Course.
+// @Summary Create a Cohort of a Course.
+// @Description This endpoint schedules a new run of a Course owned by the teacher. Start and end dates are calendar
+// @Description dates in the Cohort's IANA time zone; the enrollment window must close before the Cohort ends.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param cohort body course.CohortRequestFormat true "The Cohort to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CohortResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/cohorts [post]
+func (h *CohortHandler) CreateCohort(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CohortRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	cohort, err := h.CohortService.CreateCohort(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, cohort)
+}
+
+// DeleteCohort marks a Cohort as deleted.
+// @Summary Delete a Cohort.
+// @Description This endpoint marks a Cohort of a Course owned by the teacher as deleted. Its students stay
+// @Description enrolled in the Course, without a drip schedule.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CohortResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id} [delete]
+func (h *CohortHandler) DeleteCohort(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	cohort, err := h.CohortService.DeleteCohort(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, cohort)
+}
+
+// ResolveCohortByID resolves a Cohort.
+// @Summary Resolve a Cohort.
+// @Description This endpoint resolves a Cohort. Students only see the Cohorts of published courses.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CohortResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id} [get]
+func (h *CohortHandler) ResolveCohortByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	cohort, err := h.CohortService.ResolveCohortByID(id, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, cohort)
+}
+
+// ResolveCohortRoster lists the students in a Cohort.
+// @Summary List the students in a Cohort.
+// @Description This endpoint lists the Enrollments in a Cohort in the order they enrolled. Only the course owner
+// @Description and the Cohort's instructors may do this.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id}/roster [get]
+func (h *CohortHandler) ResolveCohortRoster(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	roster, err := h.CohortService.ResolveCohortRoster(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, roster)
+}
+
+// ResolveCohortSchedule resolves the drip schedule of a Cohort.
+// @Summary Resolve the drip schedule of a Cohort.
+// @Description This endpoint resolves when each scheduled Lesson is released to a Cohort. Lessons left out of the
+// @Description schedule are released when the Cohort starts. Students only see the schedule of their own Cohort.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CohortScheduleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id}/schedule [get]
+func (h *CohortHandler) ResolveCohortSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	schedule, err := h.CohortService.ResolveCohortSchedule(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, schedule)
+}
+
+// ResolveCourseCohorts lists the Cohorts of a Course.
+// @Summary List the Cohorts of a Course.
+// @Description This endpoint lists the Cohorts of a Course in the order they start. Students only see the Cohorts
+// @Description of published courses.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CohortResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/cohorts [get]
+func (h *CohortHandler) ResolveCourseCohorts(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	cohorts, err := h.CohortService.ResolveCohortsByCourseID(courseID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, cohorts)
+}
+
+// SetCohortSchedule replaces the drip schedule of a Cohort.
+// @Summary Replace the drip schedule of a Cohort.
+// @Description This endpoint sets how many days after the Cohort starts each Lesson is released. Lessons are released
+// @Description at midnight in the Cohort's time zone. Every Lesson must belong to the Course and appear once.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Param schedule body course.CohortScheduleRequestFormat true "The new drip schedule."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CohortScheduleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id}/schedule [put]
+func (h *CohortHandler) SetCohortSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CohortScheduleRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	schedule, err := h.CohortService.SetCohortSchedule(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, schedule)
+}
+
+// UpdateCohort updates a Cohort.
+// @Summary Update a Cohort.
+// @Description This endpoint updates a Cohort of a Course owned by the teacher, replacing its instructors.
+// @Tags courses/cohorts
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Param cohort body course.CohortRequestFormat true "The updated Cohort."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CohortResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id} [put]
+func (h *CohortHandler) UpdateCohort(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CohortRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	cohort, err := h.CohortService.UpdateCohort(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, cohort)
+}
@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// CollaboratorHandler is the HTTP handler for the Collaborators of Courses and
+// their ownership.
+type CollaboratorHandler struct {
+	CollaboratorService course.CollaboratorService
+	AuthMiddleware      *middleware.Authentication
+}
+
+// ProvideCollaboratorHandler is the provider for this handler.
+func ProvideCollaboratorHandler(collaboratorService course.CollaboratorService, authMiddleware *middleware.Authentication) CollaboratorHandler {
+	return CollaboratorHandler{
+		CollaboratorService: collaboratorService,
+		AuthMiddleware:      authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *CollaboratorHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/collaborators", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveCollaborators)
+			r.Post("/", h.InviteCollaborator)
+			r.Get("/me", h.ResolveMyMembership)
+			r.Post("/me/accept", h.AcceptInvitation)
+			r.Delete("/me", h.LeaveCourse)
+			r.Put("/{userID}", h.UpdateCollaborator)
+			r.Delete("/{userID}", h.RemoveCollaborator)
+		})
+	})
+
+	r.Route("/courses/{id}/owner", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.TransferOwnership)
+		})
+	})
+
+	r.Route("/collaborations", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/me", h.ResolveMyCollaborations)
+		})
+	})
+}
+
+// AcceptInvitation accepts an invitation to collaborate on a Course.
+// @Summary Accept an invitation to collaborate on a Course.
+// @Description This endpoint makes the teacher an active Collaborator of a Course they were invited to, granting
+// @Description the permissions of their role.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators/me/accept [post]
+func (h *CollaboratorHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.AcceptInvitation(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborator)
+}
+
+// InviteCollaborator invites a teacher to collaborate on a Course.
+// @Summary Invite a Collaborator to a Course.
+// @Description This endpoint invites a teacher to a Course as a co-instructor or a teaching assistant. Only the
+// @Description owner can invite; removed Collaborators can be invited again.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param collaborator body course.CollaboratorRequestFormat true "The invitation."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators [post]
+func (h *CollaboratorHandler) InviteCollaborator(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CollaboratorRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.InviteCollaborator(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, collaborator)
+}
+
+// LeaveCourse removes the teacher from the Collaborators of a Course.
+// @Summary Leave a Course or decline an invitation.
+// @Description This endpoint removes the teacher from the Collaborators of a Course, or declines their pending
+// @Description invitation. Owners have to transfer the Course first.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators/me [delete]
+func (h *CollaboratorHandler) LeaveCourse(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.RemoveCollaborator(courseID, claims.UserID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborator)
+}
+
+// RemoveCollaborator removes a Collaborator from a Course.
+// @Summary Remove a Collaborator from a Course.
+// @Description This endpoint removes a Collaborator from a Course owned by the teacher, or withdraws their
+// @Description invitation.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param userID path string true "The Collaborator's user identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators/{userID} [delete]
+func (h *CollaboratorHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	userID, err := uuidFromURLParam(r, "userID")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.RemoveCollaborator(courseID, userID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborator)
+}
+
+// ResolveCollaborators lists the owner and Collaborators of a Course.
+// @Summary List the owner and Collaborators of a Course.
+// @Description This endpoint lists everyone teaching a Course with their roles and permissions, including pending
+// @Description invitations. Only the owner and active Collaborators can see it.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators [get]
+func (h *CollaboratorHandler) ResolveCollaborators(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborators, err := h.CollaboratorService.ResolveCollaborators(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborators)
+}
+
+// ResolveMyCollaborations lists the Courses the teacher collaborates on.
+// @Summary List my collaborations.
+// @Description This endpoint lists the Courses the teacher collaborates on or is invited to, with their role on
+// @Description each. Courses the teacher owns are not included.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/collaborations/me [get]
+func (h *CollaboratorHandler) ResolveMyCollaborations(w http.ResponseWriter, r *http.Request) {
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborators, err := h.CollaboratorService.ResolveMyCollaborations(claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborators)
+}
+
+// ResolveMyMembership resolves the teacher's role on a Course.
+// @Summary Resolve my role on a Course.
+// @Description This endpoint resolves the role and permissions the teacher has on a Course, or their pending
+// @Description invitation.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators/me [get]
+func (h *CollaboratorHandler) ResolveMyMembership(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.ResolveMyMembership(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborator)
+}
+
+// TransferOwnership hands a Course over to one of its Collaborators.
+// @Summary Transfer the ownership of a Course.
+// @Description This endpoint hands a Course owned by the teacher over to one of its active Collaborators. The
+// @Description previous owner stays on as a co-instructor.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param owner body course.OwnershipTransferRequestFormat true "The new owner."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/owner [put]
+func (h *CollaboratorHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.OwnershipTransferRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	transferred, err := h.CollaboratorService.TransferOwnership(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, transferred)
+}
+
+// UpdateCollaborator changes the role of a Collaborator.
+// @Summary Change the role of a Collaborator.
+// @Description This endpoint gives a Collaborator of a Course owned by the teacher another role.
+// @Tags courses/collaborators
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param userID path string true "The Collaborator's user identifier."
+// @Param role body course.CollaboratorRoleRequestFormat true "The new role."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CollaboratorResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/collaborators/{userID} [put]
+func (h *CollaboratorHandler) UpdateCollaborator(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	userID, err := uuidFromURLParam(r, "userID")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CollaboratorRoleRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	collaborator, err := h.CollaboratorService.UpdateCollaborator(courseID, userID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, collaborator)
+}
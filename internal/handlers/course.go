@@ -3,17 +3,26 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/evermos/boilerplate-go/internal/domain/course"
 	"github.com/evermos/boilerplate-go/shared"
 	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
 	"github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/evermos/boilerplate-go/transport/http/response"
 	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+	"github.com/guregu/null"
 )
 
+// courseBulkMaxSize caps the size of a bulk upload of Courses.
+const courseBulkMaxSize = 10 << 20
+
 type CourseHandler struct {
 	CourseService  course.CourseService
 	AuthMiddleware *middleware.Authentication
@@ -28,11 +37,36 @@ func ProvideCourseHandler(courseService course.CourseService, authMiddleware *mi
 
 func (h *CourseHandler) Router(r chi.Router) {
 	r.Route("/courses", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/search", h.SearchCourses)
+			r.Get("/{id}", h.ResolveCourseByID)
+		})
+
 		r.Group(func(r chi.Router) {
 			r.Use(h.AuthMiddleware.ValidateAuth)
 			r.Use(h.AuthMiddleware.UserRoleCheck)
 			r.Get("/", h.ResolveCourses)
 			r.Post("/", h.CreateCourse)
+			r.Post("/bulk", h.CreateCoursesBulk)
+			r.Put("/{id}", h.UpdateCourse)
+			r.Patch("/{id}", h.PatchCourse)
+			r.Delete("/{id}", h.SoftDeleteCourse)
+		})
+	})
+
+	r.Route("/courses/{id}/prerequisites", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolvePrerequisiteChain)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.SetCoursePrerequisites)
 		})
 	})
 }
@@ -65,6 +99,62 @@ func (h *CourseHandler) CreateCourse(w http.ResponseWriter, r *http.Request) {
 	response.WithJSON(w, http.StatusCreated, course)
 }
 
+// CreateCoursesBulk creates Courses from the rows of a CSV or NDJSON upload.
+// @Summary Create Courses in bulk.
+// @Description This endpoint creates a draft Course owned by the teacher for every row of the request body, such as
+// @Description when onboarding a partner. Send CSV as text/csv, with a header naming the title, content and
+// @Description optional seatLimit columns, or NDJSON as application/x-ndjson, one course request per line. Up to
+// @Description 1000 rows are validated like single course requests; valid rows are inserted in batches of 100,
+// @Description invalid ones are skipped. The report lists the outcome of every row. With dryRun, rows are only
+// @Description validated and nothing is created.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param dryRun query bool false "Only validate the rows, without creating any Course."
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseBulkReport}
+// @Failure 400 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/bulk [post]
+func (h *CourseHandler) CreateCoursesBulk(w http.ResponseWriter, r *http.Request) {
+	format, err := courseBulkFormatFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, courseBulkMaxSize)
+	rows, err := course.ParseCourseBulkRows(r.Body, format)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.CourseService.CreateCoursesBulk(rows, dryRun, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, report)
+}
+
 func (h *CourseHandler) ResolveCourses(w http.ResponseWriter, r *http.Request) {
 	pageString := r.URL.Query().Get("page")
 	page, err := convertQueryParamsToInt(pageString)
@@ -81,17 +171,25 @@ func (h *CourseHandler) ResolveCourses(w http.ResponseWriter, r *http.Request) {
 	sort := r.URL.Query().Get("sort")
 	order := r.URL.Query().Get("order")
 
-	resp, ok := r.Context().Value("responseBody").(shared.Claims)
-	if !ok {
-		response.WithError(w, failure.InternalError(err))
-	}
-
 	params := course.CourseQueryParameters{
 		Page:  page,
 		Limit: limit,
 		Sort:  sort,
 		Order: order,
-		Role:  resp.Role,
+	}
+
+	if category := r.URL.Query().Get("category"); category != "" {
+		id, err := uuid.FromString(category)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		params.CategoryID = nuuid.From(id)
+	}
+
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		params.Tags = strings.Split(tag, ",")
 	}
 
 	courses, err := h.CourseService.ResolveCourses(params)
@@ -103,6 +201,412 @@ func (h *CourseHandler) ResolveCourses(w http.ResponseWriter, r *http.Request) {
 	response.WithJSON(w, http.StatusOK, courses)
 }
 
+// SearchCourses searches Courses by their title and content.
+// @Summary Search Courses
+// @Description This endpoint searches the title and content of Courses, most relevant first, and counts the matches
+// @Description by teacher, tag and status. Without a query every Course passing the filters matches, newest first.
+// @Description Students only find published courses.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param q query string false "The words to search for."
+// @Param teacherId query string false "Only match courses of this teacher."
+// @Param tag query string false "Only match courses with any of these comma-separated tags."
+// @Param status query string false "Only match courses with these comma-separated statuses."
+// @Param createdFrom query string false "Only match courses created at or after this date (YYYY-MM-DD) or time (RFC 3339)."
+// @Param createdTo query string false "Only match courses created at or before this date (YYYY-MM-DD) or time (RFC 3339)."
+// @Param page query int false "The page number, starting from 0."
+// @Param limit query int false "The page size, up to 100. Defaults to 10."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseSearchResultResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/search [get]
+func (h *CourseHandler) SearchCourses(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := course.CourseSearchParameters{
+		Query: strings.TrimSpace(query.Get("q")),
+		Limit: 10,
+	}
+
+	if teacherID := query.Get("teacherId"); teacherID != "" {
+		id, err := uuid.FromString(teacherID)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		params.TeacherID = nuuid.From(id)
+	}
+
+	if tag := query.Get("tag"); tag != "" {
+		params.Tags = strings.Split(tag, ",")
+	}
+
+	if status := query.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			params.Statuses = append(params.Statuses, course.CourseStatus(s))
+		}
+	}
+
+	var err error
+	params.CreatedFrom, err = timeFromQueryParam(r, "createdFrom", false)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	params.CreatedTo, err = timeFromQueryParam(r, "createdTo", true)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	if page := query.Get("page"); page != "" {
+		params.Page, err = convertQueryParamsToInt(page)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		params.Limit, err = convertQueryParamsToInt(limit)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	result, err := h.CourseService.SearchCourses(params, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, result)
+}
+
+// ResolveCourseByID resolves a Course by its ID.
+// @Summary Resolve Course by ID
+// @Description This endpoint resolves a Course by its ID, together with its modules and their lessons.
+// @Description Students need an active enrollment in the course.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id} [get]
+func (h *CourseHandler) ResolveCourseByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.CourseService.ResolveReadableCourseByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// UpdateCourse updates a Course.
+// @Summary Update a Course.
+// @Description This endpoint replaces the title and content of a Course. Only the course owner may do this.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param course body course.CourseRequestFormat true "The Course to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id} [put]
+func (h *CourseHandler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CourseRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.CourseService.UpdateCourse(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// PatchCourse partially updates a Course.
+// @Summary Partially update a Course.
+// @Description This endpoint updates only the fields present in the request body. Only the course owner may do this.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param course body course.CoursePatchRequestFormat true "The fields to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id} [patch]
+func (h *CourseHandler) PatchCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CoursePatchRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.CourseService.PatchCourse(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// SoftDeleteCourse marks a Course as deleted.
+// @Summary Marks a Course as deleted.
+// @Description This endpoint marks an existing Course as deleted by setting its
+// @Description "deletedAt" and "deletedBy" properties. Only the course owner may do this.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id} [delete]
+func (h *CourseHandler) SoftDeleteCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.CourseService.SoftDeleteCourse(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// ResolvePrerequisiteChain resolves the learning path leading to a Course.
+// @Summary Resolve the prerequisite chain of a Course.
+// @Description This endpoint resolves every Course that has to be completed before the given one, directly or
+// @Description through other prerequisites, listed in an order they can be taken in. Depth is the length of the
+// @Description longest path from the Course, and the edges tell which Course requires which.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.PrerequisiteChain}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/prerequisites [get]
+func (h *CourseHandler) ResolvePrerequisiteChain(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	chain, err := h.CourseService.ResolvePrerequisiteChain(id, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, chain)
+}
+
+// SetCoursePrerequisites replaces the prerequisites of a Course.
+// @Summary Set the prerequisites of a Course.
+// @Description This endpoint replaces the Courses students must complete before enrolling in a Course. Only the
+// @Description course owner may do this. A Course cannot require itself, directly or through its prerequisites.
+// @Tags courses
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param prerequisites body course.CoursePrerequisitesRequestFormat true "The Courses required."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/prerequisites [put]
+func (h *CourseHandler) SetCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CoursePrerequisitesRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.CourseService.SetCoursePrerequisites(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// claimsFromRequest reads the claims that ValidateAuth stored in the request context.
+func claimsFromRequest(r *http.Request) (claims shared.Claims, err error) {
+	claims, ok := r.Context().Value("responseBody").(shared.Claims)
+	if !ok {
+		return claims, failure.Unauthorized("User not authorized")
+	}
+
+	return
+}
+
+// decodeRequest decodes a JSON request body into v and validates it.
+func decodeRequest(r *http.Request, v interface{}) (err error) {
+	err = json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		return failure.BadRequest(err)
+	}
+
+	return failure.BadRequest(shared.GetValidator().Struct(v))
+}
+
+// uuidFromURLParam parses a UUID path parameter.
+func uuidFromURLParam(r *http.Request, name string) (id uuid.UUID, err error) {
+	id, err = uuid.FromString(chi.URLParam(r, name))
+	if err != nil {
+		return id, failure.BadRequest(err)
+	}
+
+	return
+}
+
+// courseBulkFormatFromRequest tells the format of a bulk upload of Courses
+// from its Content-Type.
+func courseBulkFormatFromRequest(r *http.Request) (format course.CourseBulkFormat, err error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return format, failure.BadRequestFromString("the Content-Type must be text/csv or application/x-ndjson")
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return course.CourseBulkFormatCSV, nil
+	case "application/x-ndjson", "application/ndjson":
+		return course.CourseBulkFormatNDJSON, nil
+	}
+
+	return format, failure.BadRequestFromString(fmt.Sprintf("courses cannot be uploaded as %s, only as text/csv or application/x-ndjson", mediaType))
+}
+
+// timeFromQueryParam parses an optional query parameter holding either an RFC
+// 3339 time or a date. A date stands for the start of that day, or for its end
+// when endOfDay is set.
+func timeFromQueryParam(r *http.Request, name string, endOfDay bool) (t null.Time, err error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return null.TimeFrom(parsed), nil
+	}
+
+	parsed, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, failure.BadRequest(fmt.Errorf("%s must be a date or an RFC 3339 time", name))
+	}
+
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	return null.TimeFrom(parsed), nil
+}
+
 func convertQueryParamsToInt(idStr string) (int, error) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
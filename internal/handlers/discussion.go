@@ -0,0 +1,780 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+)
+
+// DiscussionHandler is the HTTP handler for the discussion Threads of Courses.
+type DiscussionHandler struct {
+	DiscussionService course.DiscussionService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvideDiscussionHandler is the provider for this handler.
+func ProvideDiscussionHandler(discussionService course.DiscussionService, authMiddleware *middleware.Authentication) DiscussionHandler {
+	return DiscussionHandler{
+		DiscussionService: discussionService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *DiscussionHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/threads", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveThreads)
+			r.Post("/", h.CreateThread)
+		})
+	})
+
+	r.Route("/threads", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveThreadByID)
+			r.Put("/{id}", h.UpdateThread)
+			r.Delete("/{id}", h.SoftDeleteThread)
+			r.Post("/{id}/posts", h.CreatePost)
+			r.Put("/{id}/resolution", h.ResolveThread)
+			r.Delete("/{id}/resolution", h.ReopenThread)
+			r.Post("/{id}/upvote", h.UpvoteThread)
+			r.Delete("/{id}/upvote", h.RemoveThreadUpvote)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/pin", h.PinThread)
+			r.Delete("/{id}/pin", h.UnpinThread)
+			r.Put("/{id}/lock", h.LockThread)
+			r.Delete("/{id}/lock", h.UnlockThread)
+			r.Put("/{id}/hidden", h.HideThread)
+			r.Delete("/{id}/hidden", h.ShowThread)
+		})
+	})
+
+	r.Route("/posts", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Put("/{id}", h.UpdatePost)
+			r.Delete("/{id}", h.SoftDeletePost)
+			r.Post("/{id}/upvote", h.UpvotePost)
+			r.Delete("/{id}/upvote", h.RemovePostUpvote)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/hidden", h.HidePost)
+			r.Delete("/{id}/hidden", h.ShowPost)
+		})
+	})
+}
+
+// CreatePost replies to a Thread.
+// @Summary Reply to a Thread.
+// @Description This endpoint replies to a Thread, or to one of its Posts when a parent is given. Only moderators
+// @Description can reply to locked Threads.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Param post body course.PostRequestFormat true "The Post to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.PostResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/posts [post]
+func (h *DiscussionHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	threadID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.PostRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	post, err := h.DiscussionService.CreatePost(threadID, requestFormat, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, post)
+}
+
+// CreateThread starts a Thread on a Course.
+// @Summary Start a Thread on a Course.
+// @Description This endpoint starts a discussion Thread on a Course, or on one of its Lessons when a lesson is
+// @Description given. Students need an active enrollment.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param thread body course.ThreadRequestFormat true "The Thread to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/threads [post]
+func (h *DiscussionHandler) CreateThread(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ThreadRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	thread, err := h.DiscussionService.CreateThread(courseID, requestFormat, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, thread)
+}
+
+// HidePost hides a Post.
+// @Summary Hide a Post.
+// @Description This endpoint hides a Post from everyone but the moderators of its Course.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.PostResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id}/hidden [put]
+func (h *DiscussionHandler) HidePost(w http.ResponseWriter, r *http.Request) {
+	h.setPostHidden(w, r, true)
+}
+
+// HideThread hides a Thread.
+// @Summary Hide a Thread.
+// @Description This endpoint hides a Thread from everyone but the moderators of its Course.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/hidden [put]
+func (h *DiscussionHandler) HideThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.HideThread(id, true, claims.UserID)
+	})
+}
+
+// LockThread locks a Thread.
+// @Summary Lock a Thread.
+// @Description This endpoint locks a Thread, so that only moderators can reply to it.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/lock [put]
+func (h *DiscussionHandler) LockThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.LockThread(id, true, claims.UserID)
+	})
+}
+
+// PinThread pins a Thread.
+// @Summary Pin a Thread.
+// @Description This endpoint pins a Thread to the top of its Course's list.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/pin [put]
+func (h *DiscussionHandler) PinThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.PinThread(id, true, claims.UserID)
+	})
+}
+
+// RemovePostUpvote withdraws an Upvote of a Post.
+// @Summary Withdraw my Upvote of a Post.
+// @Description This endpoint withdraws the current user's Upvote of a Post.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Produce json
+// @Success 200 {object} response.Base
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id}/upvote [delete]
+func (h *DiscussionHandler) RemovePostUpvote(w http.ResponseWriter, r *http.Request) {
+	h.removeUpvote(w, r, course.UpvoteSubjectPost)
+}
+
+// RemoveThreadUpvote withdraws an Upvote of a Thread.
+// @Summary Withdraw my Upvote of a Thread.
+// @Description This endpoint withdraws the current user's Upvote of a Thread.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/upvote [delete]
+func (h *DiscussionHandler) RemoveThreadUpvote(w http.ResponseWriter, r *http.Request) {
+	h.removeUpvote(w, r, course.UpvoteSubjectThread)
+}
+
+// ReopenThread marks a Thread as open again.
+// @Summary Reopen a Thread.
+// @Description This endpoint marks the question of a Thread as open again. The author of the Thread and the
+// @Description moderators of its Course can do this.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/resolution [delete]
+func (h *DiscussionHandler) ReopenThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.SetThreadResolved(id, false, claims.UserID, claims.Role)
+	})
+}
+
+// ResolveThread marks a Thread as resolved.
+// @Summary Resolve a Thread.
+// @Description This endpoint marks the question of a Thread as answered. The author of the Thread and the
+// @Description moderators of its Course can do this.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/resolution [put]
+func (h *DiscussionHandler) ResolveThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.SetThreadResolved(id, true, claims.UserID, claims.Role)
+	})
+}
+
+// ResolveThreadByID resolves a Thread with its Posts.
+// @Summary Resolve a Thread.
+// @Description This endpoint resolves a Thread with its Posts arranged in their reply tree. The bodies of deleted
+// @Description Posts, and of hidden ones for everyone but moderators, are left out.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id} [get]
+func (h *DiscussionHandler) ResolveThreadByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	thread, err := h.DiscussionService.ResolveThreadByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, thread)
+}
+
+// ResolveThreads resolves the Threads of a Course.
+// @Summary Resolve the Threads of a Course.
+// @Description This endpoint resolves a page of the Threads of a Course, most recently active first. Pinned
+// @Description Threads come with the first page. Pass the returned cursor to get the next page.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param lessonID query string false "Only list threads started on this Lesson."
+// @Param hidden query bool false "Also list hidden threads. Moderators only."
+// @Param cursor query string false "The cursor returned with the previous page."
+// @Param limit query int false "The page size, up to 100. Defaults to 20."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadPageResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/threads [get]
+func (h *DiscussionHandler) ResolveThreads(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	params := course.ThreadQueryParameters{
+		CourseID: courseID,
+		Limit:    20,
+	}
+
+	if lessonID := query.Get("lessonID"); lessonID != "" {
+		id, err := uuid.FromString(lessonID)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		params.LessonID = nuuid.From(id)
+	}
+
+	if hidden := query.Get("hidden"); hidden != "" {
+		params.IncludeHidden, err = strconv.ParseBool(hidden)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := course.ParseThreadCursor(cursor)
+		if err != nil {
+			response.WithError(w, err)
+			return
+		}
+
+		params.After = &after
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		params.Limit, err = convertQueryParamsToInt(limit)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	page, err := h.DiscussionService.ResolveThreads(params, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, page)
+}
+
+// ShowPost shows a hidden Post again.
+// @Summary Show a hidden Post.
+// @Description This endpoint shows a hidden Post to everyone again.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.PostResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id}/hidden [delete]
+func (h *DiscussionHandler) ShowPost(w http.ResponseWriter, r *http.Request) {
+	h.setPostHidden(w, r, false)
+}
+
+// ShowThread shows a hidden Thread again.
+// @Summary Show a hidden Thread.
+// @Description This endpoint shows a hidden Thread to everyone again.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/hidden [delete]
+func (h *DiscussionHandler) ShowThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.HideThread(id, false, claims.UserID)
+	})
+}
+
+// SoftDeletePost deletes a Post.
+// @Summary Delete a Post.
+// @Description This endpoint marks a Post as deleted. Its author and the moderators of its Course can do this.
+// @Description Replies to the Post are kept.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.PostResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id} [delete]
+func (h *DiscussionHandler) SoftDeletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	post, err := h.DiscussionService.SoftDeletePost(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, post)
+}
+
+// SoftDeleteThread deletes a Thread.
+// @Summary Delete a Thread.
+// @Description This endpoint marks a Thread as deleted. Its author and the moderators of its Course can do this.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id} [delete]
+func (h *DiscussionHandler) SoftDeleteThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.SoftDeleteThread(id, claims.UserID, claims.Role)
+	})
+}
+
+// UnlockThread unlocks a Thread.
+// @Summary Unlock a Thread.
+// @Description This endpoint unlocks a Thread, so that everyone can reply to it again.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/lock [delete]
+func (h *DiscussionHandler) UnlockThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.LockThread(id, false, claims.UserID)
+	})
+}
+
+// UnpinThread unpins a Thread.
+// @Summary Unpin a Thread.
+// @Description This endpoint unpins a Thread, listing it with the others again.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/pin [delete]
+func (h *DiscussionHandler) UnpinThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThread(w, r, func(id uuid.UUID, claims shared.Claims) (course.Thread, error) {
+		return h.DiscussionService.PinThread(id, false, claims.UserID)
+	})
+}
+
+// UpdatePost updates a Post.
+// @Summary Update a Post.
+// @Description This endpoint updates the body of a Post written by the current user.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Param post body course.PostUpdateRequestFormat true "The Post to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.PostResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id} [put]
+func (h *DiscussionHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.PostUpdateRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	post, err := h.DiscussionService.UpdatePost(id, requestFormat, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, post)
+}
+
+// UpdateThread updates a Thread.
+// @Summary Update a Thread.
+// @Description This endpoint updates the title and body of a Thread started by the current user.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Param thread body course.ThreadUpdateRequestFormat true "The Thread to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ThreadResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id} [put]
+func (h *DiscussionHandler) UpdateThread(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ThreadUpdateRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	thread, err := h.DiscussionService.UpdateThread(id, requestFormat, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, thread)
+}
+
+// UpvotePost upvotes a Post.
+// @Summary Upvote a Post.
+// @Description This endpoint upvotes a Post on behalf of the current user, who cannot upvote their own Posts.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Post's identifier."
+// @Produce json
+// @Success 201 {object} response.Base
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/posts/{id}/upvote [post]
+func (h *DiscussionHandler) UpvotePost(w http.ResponseWriter, r *http.Request) {
+	h.upvote(w, r, course.UpvoteSubjectPost)
+}
+
+// UpvoteThread upvotes a Thread.
+// @Summary Upvote a Thread.
+// @Description This endpoint upvotes a Thread on behalf of the current user, who cannot upvote their own Threads.
+// @Tags courses/discussions
+// @Security EVMOauthToken
+// @Param id path string true "The Thread's identifier."
+// @Produce json
+// @Success 201 {object} response.Base
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/threads/{id}/upvote [post]
+func (h *DiscussionHandler) UpvoteThread(w http.ResponseWriter, r *http.Request) {
+	h.upvote(w, r, course.UpvoteSubjectThread)
+}
+
+// changeThread changes a Thread identified by the URL with the given
+// function and responds with the result.
+func (h *DiscussionHandler) changeThread(w http.ResponseWriter, r *http.Request, change func(id uuid.UUID, claims shared.Claims) (course.Thread, error)) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	thread, err := change(id, claims)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, thread)
+}
+
+// removeUpvote withdraws the current user's Upvote of the subject identified by the URL.
+func (h *DiscussionHandler) removeUpvote(w http.ResponseWriter, r *http.Request, subjectType course.UpvoteSubjectType) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	err = h.DiscussionService.RemoveUpvote(subjectType, id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, nil)
+}
+
+// setPostHidden hides or shows the Post identified by the URL.
+func (h *DiscussionHandler) setPostHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	post, err := h.DiscussionService.HidePost(id, hidden, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, post)
+}
+
+// upvote upvotes the subject identified by the URL on behalf of the current user.
+func (h *DiscussionHandler) upvote(w http.ResponseWriter, r *http.Request, subjectType course.UpvoteSubjectType) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	_, err = h.DiscussionService.Upvote(subjectType, id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, nil)
+}
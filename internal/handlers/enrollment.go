@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// EnrollmentHandler is the HTTP handler for course Enrollments.
+type EnrollmentHandler struct {
+	EnrollmentService course.EnrollmentService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvideEnrollmentHandler is the provider for this handler.
+func ProvideEnrollmentHandler(enrollmentService course.EnrollmentService, authMiddleware *middleware.Authentication) EnrollmentHandler {
+	return EnrollmentHandler{
+		EnrollmentService: enrollmentService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *EnrollmentHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/enrollments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/", h.Enroll)
+			r.Delete("/me", h.Unenroll)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveEnrollments)
+		})
+	})
+
+	r.Route("/cohorts/{id}/enrollments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/", h.EnrollInCohort)
+		})
+	})
+
+	r.Route("/enrollments", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/me", h.ResolveMyEnrollments)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/approve", h.ApproveEnrollment)
+			r.Put("/{id}/cohort", h.AssignCohort)
+			r.Delete("/{id}", h.RemoveEnrollment)
+		})
+	})
+}
+
+// Enroll enrolls the current student in a Course.
+// @Summary Enroll in a Course.
+// @Description This endpoint enrolls the current student in a Course. The enrollment is pending
+// @Description until the teacher approves it, or waitlisted when the course has no free seat. Only published courses accept students.
+// @Description Students must have completed the course's prerequisites first.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/enrollments [post]
+func (h *EnrollmentHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.Enroll(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, enrollment)
+}
+
+// EnrollInCohort enrolls the current student in a Cohort.
+// @Summary Enroll in a Cohort.
+// @Description This endpoint enrolls the current student in the Course of a Cohort and places them in the Cohort,
+// @Description as long as its enrollment window is open. Students already enrolled in the Course move to the Cohort.
+// @Description Seats, approval and prerequisites work as when enrolling in the Course.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Cohort's identifier."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/cohorts/{id}/enrollments [post]
+func (h *EnrollmentHandler) EnrollInCohort(w http.ResponseWriter, r *http.Request) {
+	cohortID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.EnrollInCohort(cohortID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, enrollment)
+}
+
+// Unenroll withdraws the current student from a Course.
+// @Summary Withdraw from a Course.
+// @Description This endpoint withdraws the current student from a Course. A freed seat goes to the next waitlisted student.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/enrollments/me [delete]
+func (h *EnrollmentHandler) Unenroll(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.Unenroll(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollment)
+}
+
+// ResolveEnrollments lists the Enrollments of a Course.
+// @Summary List the Enrollments of a Course.
+// @Description This endpoint lists the enrollees of a Course in the order they enrolled. Only the course owner may do this.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param status query string false "Only list enrollments with this status."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/enrollments [get]
+func (h *EnrollmentHandler) ResolveEnrollments(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	params := course.EnrollmentQueryParameters{
+		CourseID: courseID,
+		Status:   course.EnrollmentStatus(r.URL.Query().Get("status")),
+	}
+
+	enrollments, err := h.EnrollmentService.ResolveEnrollments(params, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollments)
+}
+
+// ResolveMyEnrollments lists the Enrollments of the current student.
+// @Summary List my Enrollments.
+// @Description This endpoint lists every enrollment of the current student.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.EnrollmentResponseFormat}
+// @Failure 500 {object} response.Base
+// @Router /v1/enrollments/me [get]
+func (h *EnrollmentHandler) ResolveMyEnrollments(w http.ResponseWriter, r *http.Request) {
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollments, err := h.EnrollmentService.ResolveEnrollmentsByStudentID(claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollments)
+}
+
+// ApproveEnrollment approves a pending Enrollment.
+// @Summary Approve an Enrollment.
+// @Description This endpoint approves a pending enrollment, giving the student access to the course content.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Enrollment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/enrollments/{id}/approve [put]
+func (h *EnrollmentHandler) ApproveEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.ApproveEnrollment(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollment)
+}
+
+// RemoveEnrollment removes a student from a Course.
+// @Summary Remove an Enrollment.
+// @Description This endpoint removes a student from a Course. A freed seat goes to the next waitlisted student.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Enrollment's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/enrollments/{id} [delete]
+func (h *EnrollmentHandler) RemoveEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.RemoveEnrollment(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollment)
+}
+
+// AssignCohort moves an Enrollment to another Cohort.
+// @Summary Move an Enrollment to another Cohort.
+// @Description This endpoint moves an Enrollment in a Course owned by the teacher to one of the Course's Cohorts,
+// @Description or out of any Cohort when cohortID is null.
+// @Tags courses/enrollments
+// @Security EVMOauthToken
+// @Param id path string true "The Enrollment's identifier."
+// @Param assignment body course.CohortAssignmentRequestFormat true "The Cohort to move the Enrollment to."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.EnrollmentResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/enrollments/{id}/cohort [put]
+func (h *EnrollmentHandler) AssignCohort(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CohortAssignmentRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	enrollment, err := h.EnrollmentService.AssignCohort(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, enrollment)
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/nuuid"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/gofrs/uuid"
+)
+
+// GradebookHandler is the HTTP handler for Gradebooks and final grades.
+type GradebookHandler struct {
+	GradebookService course.GradebookService
+	AuthMiddleware   *middleware.Authentication
+}
+
+// ProvideGradebookHandler is the provider for this handler.
+func ProvideGradebookHandler(gradebookService course.GradebookService, authMiddleware *middleware.Authentication) GradebookHandler {
+	return GradebookHandler{
+		GradebookService: gradebookService,
+		AuthMiddleware:   authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *GradebookHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/gradebook", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveGradebook)
+			r.Put("/", h.UpdateGradebook)
+		})
+	})
+
+	r.Route("/courses/{id}/grades", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/me", h.ResolveMyGrade)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveGrades)
+			r.Get("/overrides", h.ResolveGradeOverrides)
+			r.Put("/students/{studentID}/override", h.OverrideGrade)
+		})
+	})
+}
+
+// OverrideGrade sets the final grade of a student by hand.
+// @Summary Override the final grade of a student.
+// @Description This endpoint sets the final grade of a student of a Course the teacher may grade in, replacing the
+// @Description computed one. Set percent, letter or both; a percentage without a letter is graded with the matching
+// @Description letter of the gradebook. Leave out both to remove the override. A reason is required, and every change
+// @Description is recorded with the grade it replaced, who made it and when.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param studentID path string true "The student's identifier."
+// @Param override body course.GradeOverrideRequestFormat true "The grade and the reason for overriding it."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.StudentGrade}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/grades/students/{studentID}/override [put]
+func (h *GradebookHandler) OverrideGrade(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	studentID, err := uuidFromURLParam(r, "studentID")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.GradeOverrideRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	grade, err := h.GradebookService.OverrideGrade(courseID, studentID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, grade)
+}
+
+// ResolveGradebook resolves the gradebook of a Course.
+// @Summary Resolve the gradebook of a Course.
+// @Description This endpoint resolves how the final grades of a Course the teacher may grade in are computed: its
+// @Description weighted grade categories and letter grades. Courses that didn't set a gradebook have no categories
+// @Description and the default letter grades.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.GradebookResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/gradebook [get]
+func (h *GradebookHandler) ResolveGradebook(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	gradebook, err := h.GradebookService.ResolveGradebook(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, gradebook)
+}
+
+// ResolveGradeOverrides resolves the audit trail of the final grades of a Course.
+// @Summary Resolve the grade overrides of a Course.
+// @Description This endpoint lists every change teachers made to the final grades of the students of a Course the
+// @Description teacher may grade in, oldest first: the grade set, the one it replaced, the reason, who made the change
+// @Description and when. Pass studentID to only list the changes to a student's grade.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param studentID query string false "Only list the changes to this student's grade."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.GradeOverrideResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/grades/overrides [get]
+func (h *GradebookHandler) ResolveGradeOverrides(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var studentID nuuid.NUUID
+	if value := r.URL.Query().Get("studentID"); value != "" {
+		id, err := uuid.FromString(value)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		studentID = nuuid.From(id)
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	overrides, err := h.GradebookService.ResolveGradeOverrides(courseID, studentID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, overrides)
+}
+
+// ResolveGrades computes the final grades of the students of a Course.
+// @Summary Resolve the final grades of a Course.
+// @Description This endpoint computes the final grade of every active student of a Course the teacher may grade in.
+// @Description Each grade category is graded by the share of points scored on its items after dropping the lowest
+// @Description ones; quizzes count their best submitted attempt and assignments their graded submission. Work a student
+// @Description never handed in scores 0 once it closes: assignments at their late cutoff, everything once the
+// @Description student's cohort ends. Categories without a graded or missing item yet are left out of the weighted
+// @Description average, and completionPercent tells the share of quizzes and assignments handed in. Overridden grades
+// @Description replace the computed ones. Pass format=csv to download the grades as CSV.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param format query string false "Set to csv for a CSV download."
+// @Produce json,text/csv
+// @Success 200 {object} response.Base{data=course.GradeReport}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/grades [get]
+func (h *GradebookHandler) ResolveGrades(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	report, err := h.GradebookService.ResolveGrades(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	writeReport(w, r, report, "grades-"+courseID.String()+".csv", report.WriteCSV)
+}
+
+// ResolveMyGrade computes the final grade of the current student in a Course.
+// @Summary Resolve my grade in a Course.
+// @Description This endpoint computes the final grade of the current student in a Course they are actively enrolled
+// @Description in, category by category.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.StudentGrade}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/grades/me [get]
+func (h *GradebookHandler) ResolveMyGrade(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	grade, err := h.GradebookService.ResolveMyGrade(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, grade)
+}
+
+// UpdateGradebook sets the gradebook of a Course.
+// @Summary Set the gradebook of a Course.
+// @Description This endpoint sets how the final grades of a Course the teacher may edit are computed, replacing the
+// @Description previous gradebook. Category weights must add up to 100. Quiz and assignment categories may list the
+// @Description items they count, or list none to count all those of their kind no other category lists, and may drop
+// @Description each student's lowest scores. Letter grades map percentages from minPercent up to a letter; one must
+// @Description start at 0. Leaving letter grades out uses A from 90, B from 80, C from 70, D from 60 and F.
+// @Tags courses/grades
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param gradebook body course.GradebookRequestFormat true "The grade categories and letter grades."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.GradebookResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/gradebook [put]
+func (h *GradebookHandler) UpdateGradebook(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.GradebookRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	gradebook, err := h.GradebookService.UpdateGradebook(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, gradebook)
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// LiveSessionHandler is the HTTP handler for LiveSessions and course calendars.
+type LiveSessionHandler struct {
+	LiveSessionService course.LiveSessionService
+	AuthMiddleware     *middleware.Authentication
+}
+
+// ProvideLiveSessionHandler is the provider for this handler.
+func ProvideLiveSessionHandler(liveSessionService course.LiveSessionService, authMiddleware *middleware.Authentication) LiveSessionHandler {
+	return LiveSessionHandler{
+		LiveSessionService: liveSessionService,
+		AuthMiddleware:     authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *LiveSessionHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/sessions", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveCourseLiveSessions)
+			r.Get("/occurrences", h.ResolveOccurrences)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateLiveSession)
+		})
+	})
+
+	r.Route("/courses/{id}/calendar", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveCalendarFeed)
+		})
+	})
+
+	// calendar apps can't send an Authorization header, so the feed is
+	// authenticated by the token in its link instead
+	r.Get("/courses/{id}/calendar.ics", h.DownloadCalendar)
+
+	r.Route("/sessions", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveLiveSessionByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}", h.UpdateLiveSession)
+			r.Delete("/{id}", h.DeleteLiveSession)
+		})
+	})
+}
+
+// CreateLiveSession schedules a new LiveSession of a Course.
+// @Summary Schedule a live session of a Course.
+// @Description This endpoint schedules a live class of a Course owned by the teacher, for the whole course or a single
+// @Description Cohort. Recurring sessions repeat daily or on given weekdays and keep their local time in the session's
+// @Description time zone across daylight saving time changes. They end after a count of occurrences or on a date.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param session body course.LiveSessionRequestFormat true "The live session to be scheduled."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.LiveSessionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/sessions [post]
+func (h *LiveSessionHandler) CreateLiveSession(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.LiveSessionRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	session, err := h.LiveSessionService.CreateLiveSession(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, session)
+}
+
+// DeleteLiveSession marks a LiveSession as deleted.
+// @Summary Delete a live session.
+// @Description This endpoint marks a live session of a Course owned by the teacher as deleted, removing all of its
+// @Description occurrences from the course calendar.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LiveSessionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id} [delete]
+func (h *LiveSessionHandler) DeleteLiveSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	session, err := h.LiveSessionService.DeleteLiveSession(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, session)
+}
+
+// DownloadCalendar downloads the calendar of a Course.
+// @Summary Download the calendar of a Course.
+// @Description This endpoint renders the live sessions of a Course as an RFC 5545 iCalendar feed calendar apps can
+// @Description subscribe to. Recurring sessions are expanded into one event per occurrence. The token comes from
+// @Description the subscription link and stops working when its user loses access to the course.
+// @Tags courses/sessions
+// @Param id path string true "The Course's identifier."
+// @Param token query string true "The feed token from the subscription link."
+// @Produce text/calendar
+// @Success 200 {file} file
+// @Failure 400 {object} response.Base
+// @Failure 401 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/calendar.ics [get]
+func (h *LiveSessionHandler) DownloadCalendar(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	calendar, err := h.LiveSessionService.RenderCalendar(courseID, r.URL.Query().Get("token"))
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(calendar); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
+
+// ResolveCalendarFeed resolves the subscription link to the calendar of a Course.
+// @Summary Resolve the calendar subscription link of a Course.
+// @Description This endpoint resolves the link the current user subscribes to the course calendar with. The link
+// @Description is personal: it carries a token standing for the user.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CalendarFeedFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/calendar [get]
+func (h *LiveSessionHandler) ResolveCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	feed, err := h.LiveSessionService.ResolveCalendarFeed(courseID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, feed)
+}
+
+// ResolveCourseLiveSessions lists the LiveSessions of a Course.
+// @Summary List the live sessions of a Course.
+// @Description This endpoint lists the live sessions of a Course in the order they first take place. Students need an
+// @Description active enrollment and only see sessions held for the whole course or for their Cohort.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.LiveSessionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/sessions [get]
+func (h *LiveSessionHandler) ResolveCourseLiveSessions(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	sessions, err := h.LiveSessionService.ResolveLiveSessionsByCourseID(courseID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, sessions)
+}
+
+// ResolveLiveSessionByID resolves a LiveSession.
+// @Summary Resolve a live session.
+// @Description This endpoint resolves a live session. Students need an active enrollment and only see sessions held
+// @Description for the whole course or for their Cohort.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LiveSessionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id} [get]
+func (h *LiveSessionHandler) ResolveLiveSessionByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	session, err := h.LiveSessionService.ResolveLiveSessionByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, session)
+}
+
+// ResolveOccurrences lists the occurrences of the LiveSessions of a Course.
+// @Summary List the occurrences of the live sessions of a Course.
+// @Description This endpoint expands the live sessions of a Course the user attends into their occurrences, in the
+// @Description order they take place. Only occurrences overlapping the given window are listed.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param from query string false "Only list occurrences ending after this date (YYYY-MM-DD) or time (RFC 3339)."
+// @Param to query string false "Only list occurrences starting before the end of this date (YYYY-MM-DD) or this time (RFC 3339)."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.LiveSessionOccurrence}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/sessions/occurrences [get]
+func (h *LiveSessionHandler) ResolveOccurrences(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	from, err := timeFromQueryParam(r, "from", false)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	to, err := timeFromQueryParam(r, "to", true)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	occurrences, err := h.LiveSessionService.ResolveOccurrences(courseID, from, to, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, occurrences)
+}
+
+// UpdateLiveSession updates a LiveSession.
+// @Summary Update a live session.
+// @Description This endpoint updates a live session of a Course owned by the teacher, including its recurrence.
+// @Tags courses/sessions
+// @Security EVMOauthToken
+// @Param id path string true "The live session's identifier."
+// @Param session body course.LiveSessionRequestFormat true "The updated live session."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LiveSessionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/sessions/{id} [put]
+func (h *LiveSessionHandler) UpdateLiveSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.LiveSessionRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	session, err := h.LiveSessionService.UpdateLiveSession(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, session)
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// ModuleHandler is the HTTP handler for Modules and Lessons of a Course.
+type ModuleHandler struct {
+	ModuleService  course.ModuleService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideModuleHandler is the provider for this handler.
+func ProvideModuleHandler(moduleService course.ModuleService, authMiddleware *middleware.Authentication) ModuleHandler {
+	return ModuleHandler{
+		ModuleService:  moduleService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *ModuleHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/modules", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateModule)
+			r.Put("/order", h.ReorderModules)
+		})
+	})
+
+	r.Route("/modules", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}", h.UpdateModule)
+			r.Delete("/{id}", h.SoftDeleteModule)
+			r.Post("/{id}/lessons", h.CreateLesson)
+			r.Put("/{id}/lessons/order", h.ReorderLessons)
+		})
+	})
+
+	r.Route("/lessons", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/{id}", h.ResolveLessonByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}", h.UpdateLesson)
+			r.Delete("/{id}", h.SoftDeleteLesson)
+		})
+	})
+}
+
+// CreateModule appends a new Module to a Course.
+// @Summary Create a new Module.
+// @Description This endpoint appends a new Module to the end of a Course.
+// @Tags courses/modules
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param module body course.ModuleRequestFormat true "The Module to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.ModuleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/modules [post]
+func (h *ModuleHandler) CreateModule(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ModuleRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	module, err := h.ModuleService.CreateModule(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, module)
+}
+
+// ReorderModules sets the order of the Modules of a Course.
+// @Summary Reorder Modules.
+// @Description This endpoint sets the order of all Modules of a Course. The body must list every Module exactly once.
+// @Tags courses/modules
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param order body course.ReorderRequestFormat true "The Module IDs in their new order."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.ModuleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/modules/order [put]
+func (h *ModuleHandler) ReorderModules(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReorderRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	modules, err := h.ModuleService.ReorderModules(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, modules)
+}
+
+// UpdateModule updates a Module.
+// @Summary Update a Module.
+// @Description This endpoint updates an existing Module.
+// @Tags courses/modules
+// @Security EVMOauthToken
+// @Param id path string true "The Module's identifier."
+// @Param module body course.ModuleRequestFormat true "The Module to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ModuleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/modules/{id} [put]
+func (h *ModuleHandler) UpdateModule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ModuleRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	module, err := h.ModuleService.UpdateModule(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, module)
+}
+
+// SoftDeleteModule marks a Module as deleted.
+// @Summary Marks a Module as deleted.
+// @Description This endpoint marks an existing Module, and with it its Lessons, as deleted.
+// @Tags courses/modules
+// @Security EVMOauthToken
+// @Param id path string true "The Module's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ModuleResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/modules/{id} [delete]
+func (h *ModuleHandler) SoftDeleteModule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	module, err := h.ModuleService.SoftDeleteModule(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, module)
+}
+
+// CreateLesson appends a new Lesson to a Module.
+// @Summary Create a new Lesson.
+// @Description This endpoint appends a new Lesson to the end of a Module.
+// @Tags courses/lessons
+// @Security EVMOauthToken
+// @Param id path string true "The Module's identifier."
+// @Param lesson body course.LessonRequestFormat true "The Lesson to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.LessonResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/modules/{id}/lessons [post]
+func (h *ModuleHandler) CreateLesson(w http.ResponseWriter, r *http.Request) {
+	moduleID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.LessonRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	lesson, err := h.ModuleService.CreateLesson(moduleID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, lesson)
+}
+
+// ReorderLessons sets the order of the Lessons of a Module.
+// @Summary Reorder Lessons.
+// @Description This endpoint sets the order of all Lessons of a Module. The body must list every Lesson exactly once.
+// @Tags courses/lessons
+// @Security EVMOauthToken
+// @Param id path string true "The Module's identifier."
+// @Param order body course.ReorderRequestFormat true "The Lesson IDs in their new order."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.LessonResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/modules/{id}/lessons/order [put]
+func (h *ModuleHandler) ReorderLessons(w http.ResponseWriter, r *http.Request) {
+	moduleID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReorderRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	lessons, err := h.ModuleService.ReorderLessons(moduleID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, lessons)
+}
+
+// ResolveLessonByID resolves a Lesson by its ID.
+// @Summary Resolve Lesson by ID
+// @Description This endpoint resolves a Lesson by its ID. Students need an active enrollment in the course.
+// @Tags courses/lessons
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LessonResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id} [get]
+func (h *ModuleHandler) ResolveLessonByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	lesson, err := h.ModuleService.ResolveReadableLessonByID(id, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, lesson)
+}
+
+// UpdateLesson updates a Lesson.
+// @Summary Update a Lesson.
+// @Description This endpoint updates an existing Lesson.
+// @Tags courses/lessons
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Param lesson body course.LessonRequestFormat true "The Lesson to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LessonResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id} [put]
+func (h *ModuleHandler) UpdateLesson(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.LessonRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	lesson, err := h.ModuleService.UpdateLesson(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, lesson)
+}
+
+// SoftDeleteLesson marks a Lesson as deleted.
+// @Summary Marks a Lesson as deleted.
+// @Description This endpoint marks an existing Lesson as deleted.
+// @Tags courses/lessons
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LessonResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id} [delete]
+func (h *ModuleHandler) SoftDeleteLesson(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	lesson, err := h.ModuleService.SoftDeleteLesson(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, lesson)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// ProgressHandler is the HTTP handler for lesson progress tracking.
+type ProgressHandler struct {
+	ProgressService course.ProgressService
+	AuthMiddleware  *middleware.Authentication
+}
+
+// ProvideProgressHandler is the provider for this handler.
+func ProvideProgressHandler(progressService course.ProgressService, authMiddleware *middleware.Authentication) ProgressHandler {
+	return ProgressHandler{
+		ProgressService: progressService,
+		AuthMiddleware:  authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *ProgressHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/progress", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/", h.ResolveCourseProgress)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/roster", h.ResolveCourseRoster)
+		})
+	})
+
+	r.Route("/lessons/{id}/progress", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Put("/", h.RecordLessonProgress)
+		})
+	})
+}
+
+// RecordLessonProgress records the current student's progress in a Lesson.
+// @Summary Record Lesson progress.
+// @Description This endpoint records the current student's last position in a Lesson and,
+// @Description optionally, marks it as completed. The first update marks the Lesson as started.
+// @Tags courses/progress
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Param progress body course.LessonProgressRequestFormat true "The progress update."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.LessonProgressResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/progress [put]
+func (h *ProgressHandler) RecordLessonProgress(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.LessonProgressRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	progress, err := h.ProgressService.RecordLessonProgress(lessonID, requestFormat, claims.UserID, claims.Username)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, progress)
+}
+
+// ResolveCourseProgress resolves the current student's progress in a Course.
+// @Summary Resolve my Course progress.
+// @Description This endpoint resolves the current student's completion percentage and per-lesson progress in a Course.
+// @Tags courses/progress
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseProgress}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/progress [get]
+func (h *ProgressHandler) ResolveCourseProgress(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	progress, err := h.ProgressService.ResolveCourseProgress(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, progress)
+}
+
+// ResolveCourseRoster resolves the progress of every student in a Course.
+// @Summary Resolve the Course roster.
+// @Description This endpoint resolves the completion percentage of every actively enrolled student. Only the course owner may do this.
+// @Tags courses/progress
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CourseProgress}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/progress/roster [get]
+func (h *ProgressHandler) ResolveCourseRoster(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	roster, err := h.ProgressService.ResolveCourseRoster(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, roster)
+}
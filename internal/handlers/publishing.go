@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+	"github.com/guregu/null"
+)
+
+// PublishingHandler is the HTTP handler for the publishing lifecycle of Courses.
+type PublishingHandler struct {
+	PublishingService course.PublishingService
+	AuthMiddleware    *middleware.Authentication
+}
+
+// ProvidePublishingHandler is the provider for this handler.
+func ProvidePublishingHandler(publishingService course.PublishingService, authMiddleware *middleware.Authentication) PublishingHandler {
+	return PublishingHandler{
+		PublishingService: publishingService,
+		AuthMiddleware:    authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *PublishingHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/status", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.UpdateCourseStatus)
+		})
+	})
+
+	r.Route("/courses/{id}/versions", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/", h.ResolveVersions)
+			r.Get("/{version}", h.ResolveVersion)
+			r.Get("/{version}/diff", h.DiffVersions)
+			r.Post("/{version}/rollback", h.RollBack)
+		})
+	})
+}
+
+// UpdateCourseStatus moves a Course through its publishing lifecycle.
+// @Summary Change the status of a Course.
+// @Description This endpoint moves a Course from draft to in_review, from in_review back to draft or on to published,
+// @Description from published to in_review or archived, and from archived back to draft.
+// @Description Publishing takes an immutable snapshot of the draft, which enrolled students see until the next publish.
+// @Tags courses/publishing
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param status body course.CourseStatusRequestFormat true "The new status."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/status [put]
+func (h *PublishingHandler) UpdateCourseStatus(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CourseStatusRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.PublishingService.UpdateCourseStatus(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// ResolveVersions lists the published versions of a Course.
+// @Summary List the versions of a Course.
+// @Description This endpoint lists the published versions of a Course without their content, newest first. Only the course owner may do this.
+// @Tags courses/publishing
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CourseVersionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/versions [get]
+func (h *PublishingHandler) ResolveVersions(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	versions, err := h.PublishingService.ResolveVersions(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, versions)
+}
+
+// ResolveVersion resolves a published version of a Course.
+// @Summary Resolve a version of a Course.
+// @Description This endpoint resolves a published version of a Course with its modules and lessons as they were published.
+// @Tags courses/publishing
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param version path int true "The version number."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseVersionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/versions/{version} [get]
+func (h *PublishingHandler) ResolveVersion(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	version, err := convertQueryParamsToInt(chi.URLParam(r, "version"))
+	if err != nil {
+		response.WithError(w, failure.BadRequest(err))
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	courseVersion, err := h.PublishingService.ResolveVersion(courseID, version, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, courseVersion)
+}
+
+// DiffVersions lists the changes since a published version of a Course.
+// @Summary Compare versions of a Course.
+// @Description This endpoint lists the courses, modules and lessons that were added, removed or modified
+// @Description from a version to a newer one, or to the current draft when no newer version is given.
+// @Tags courses/publishing
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param version path int true "The version number to compare from."
+// @Param to query int false "The version number to compare to. Defaults to the current draft."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.VersionDiff}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/versions/{version}/diff [get]
+func (h *PublishingHandler) DiffVersions(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	from, err := convertQueryParamsToInt(chi.URLParam(r, "version"))
+	if err != nil {
+		response.WithError(w, failure.BadRequest(err))
+		return
+	}
+
+	var to null.Int
+	if toString := r.URL.Query().Get("to"); toString != "" {
+		version, err := convertQueryParamsToInt(toString)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		to = null.IntFrom(int64(version))
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	diff, err := h.PublishingService.DiffVersions(courseID, from, to, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, diff)
+}
+
+// RollBack publishes an earlier version of a Course again.
+// @Summary Roll back to a version of a Course.
+// @Description This endpoint publishes the content of an earlier version again as a new version, which enrolled
+// @Description students then see. The draft is left as it is.
+// @Tags courses/publishing
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param version path int true "The version number to roll back to."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CourseVersionResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/versions/{version}/rollback [post]
+func (h *PublishingHandler) RollBack(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	version, err := convertQueryParamsToInt(chi.URLParam(r, "version"))
+	if err != nil {
+		response.WithError(w, failure.BadRequest(err))
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	courseVersion, err := h.PublishingService.RollBack(courseID, version, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, courseVersion)
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// QuizHandler is the HTTP handler for Quizzes and their attempts.
+type QuizHandler struct {
+	QuizService    course.QuizService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideQuizHandler is the provider for this handler.
+func ProvideQuizHandler(quizService course.QuizService, authMiddleware *middleware.Authentication) QuizHandler {
+	return QuizHandler{
+		QuizService:    quizService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *QuizHandler) Router(r chi.Router) {
+	r.Route("/lessons/{id}/quizzes", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveQuizzesByLessonID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateQuiz)
+		})
+	})
+
+	r.Route("/quizzes", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Get("/{id}", h.ResolveQuizByID)
+			r.Put("/{id}", h.UpdateQuiz)
+			r.Delete("/{id}", h.SoftDeleteQuiz)
+			r.Get("/{id}/statistics", h.ResolveQuizStatistics)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/{id}/attempts", h.StartAttempt)
+			r.Get("/{id}/attempts/me", h.ResolveMyAttempts)
+		})
+	})
+
+	r.Route("/quiz-attempts", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Get("/{id}", h.ResolveAttemptByID)
+			r.Put("/{id}/submit", h.SubmitAttempt)
+		})
+	})
+}
+
+// CreateQuiz attaches a new Quiz to a Lesson.
+// @Summary Create a new Quiz.
+// @Description This endpoint attaches a new Quiz to a Lesson. Choice questions list their options,
+// @Description true/false questions take "true" or "false" as answer and short-answer questions the expected text.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Param quiz body course.QuizRequestFormat true "The Quiz to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.QuizResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/quizzes [post]
+func (h *QuizHandler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.QuizRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	quiz, err := h.QuizService.CreateQuiz(lessonID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, quiz)
+}
+
+// ResolveQuizzesByLessonID lists the Quizzes of a Lesson.
+// @Summary List the Quizzes of a Lesson.
+// @Description This endpoint lists the Quizzes of a Lesson, without their questions.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Lesson's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.QuizResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/lessons/{id}/quizzes [get]
+func (h *QuizHandler) ResolveQuizzesByLessonID(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	quizzes, err := h.QuizService.ResolveQuizzesByLessonID(lessonID, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, quizzes)
+}
+
+// ResolveQuizByID resolves a Quiz with its answer key.
+// @Summary Resolve a Quiz.
+// @Description This endpoint resolves a Quiz with its questions and answer key. Only the course owner may do this.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id} [get]
+func (h *QuizHandler) ResolveQuizByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	quiz, err := h.QuizService.ResolveQuizByID(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, quiz)
+}
+
+// UpdateQuiz updates a Quiz.
+// @Summary Update a Quiz.
+// @Description This endpoint updates a Quiz and replaces its questions. Quizzes that were already attempted cannot be changed.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Param quiz body course.QuizRequestFormat true "The Quiz to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id} [put]
+func (h *QuizHandler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.QuizRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	quiz, err := h.QuizService.UpdateQuiz(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, quiz)
+}
+
+// SoftDeleteQuiz marks a Quiz as deleted.
+// @Summary Delete a Quiz.
+// @Description This endpoint marks a Quiz as deleted. Its attempts are kept.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id} [delete]
+func (h *QuizHandler) SoftDeleteQuiz(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	quiz, err := h.QuizService.SoftDeleteQuiz(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, quiz)
+}
+
+// ResolveQuizStatistics resolves the statistics of a Quiz.
+// @Summary Resolve Quiz statistics.
+// @Description This endpoint summarises the submitted attempts at a Quiz, including the percentage
+// @Description of attempts that answered each question correctly. Only the course owner may do this.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizStatistics}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id}/statistics [get]
+func (h *QuizHandler) ResolveQuizStatistics(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	statistics, err := h.QuizService.ResolveQuizStatistics(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, statistics)
+}
+
+// StartAttempt starts an attempt at a Quiz.
+// @Summary Start a Quiz attempt.
+// @Description This endpoint starts the current student's attempt at a Quiz, or resumes the attempt still in progress.
+// @Description The response contains the questions, with their options shuffled when the Quiz shuffles answers.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.QuizAttemptResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id}/attempts [post]
+func (h *QuizHandler) StartAttempt(w http.ResponseWriter, r *http.Request) {
+	quizID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attempt, err := h.QuizService.StartAttempt(quizID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, attempt)
+}
+
+// ResolveMyAttempts lists the current student's attempts at a Quiz.
+// @Summary List my Quiz attempts.
+// @Description This endpoint lists the current student's attempts at a Quiz with their scores.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The Quiz's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.QuizAttemptResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quizzes/{id}/attempts/me [get]
+func (h *QuizHandler) ResolveMyAttempts(w http.ResponseWriter, r *http.Request) {
+	quizID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attempts, err := h.QuizService.ResolveAttemptsByQuizID(quizID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attempts)
+}
+
+// ResolveAttemptByID resolves one of the current student's Quiz attempts.
+// @Summary Resolve a Quiz attempt.
+// @Description This endpoint resolves one of the current student's attempts. Attempts in progress come with
+// @Description their questions, submitted attempts with their scored answers.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The attempt's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizAttemptResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quiz-attempts/{id} [get]
+func (h *QuizHandler) ResolveAttemptByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attempt, err := h.QuizService.ResolveAttemptByID(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attempt)
+}
+
+// SubmitAttempt submits the answers of a Quiz attempt.
+// @Summary Submit a Quiz attempt.
+// @Description This endpoint scores and submits the current student's answers. Unanswered questions score no points.
+// @Description Timed attempts submitted after their time limit expire without a score.
+// @Tags courses/quizzes
+// @Security EVMOauthToken
+// @Param id path string true "The attempt's identifier."
+// @Param answers body course.QuizAttemptRequestFormat true "The answers to submit."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.QuizAttemptResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/quiz-attempts/{id}/submit [put]
+func (h *QuizHandler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.QuizAttemptRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	attempt, err := h.QuizService.SubmitAttempt(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, attempt)
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// ReviewHandler is the HTTP handler for Reviews of Courses.
+type ReviewHandler struct {
+	ReviewService  course.ReviewService
+	AuthMiddleware *middleware.Authentication
+}
+
+// ProvideReviewHandler is the provider for this handler.
+func ProvideReviewHandler(reviewService course.ReviewService, authMiddleware *middleware.Authentication) ReviewHandler {
+	return ReviewHandler{
+		ReviewService:  reviewService,
+		AuthMiddleware: authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *ReviewHandler) Router(r chi.Router) {
+	r.Route("/courses/{id}/reviews", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveReviews)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.StudentRoleCheck)
+			r.Post("/", h.CreateReview)
+			r.Get("/me", h.ResolveMyReview)
+			r.Put("/me", h.UpdateMyReview)
+		})
+	})
+
+	r.Route("/reviews", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Post("/{id}/flags", h.FlagReview)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/{id}/reply", h.ReplyToReview)
+			r.Delete("/{id}/flags", h.ClearReviewFlags)
+		})
+	})
+}
+
+// ClearReviewFlags dismisses the flags of a Review.
+// @Summary Dismiss the flags of a Review.
+// @Description This endpoint dismisses the flags of a Review of a Course the teacher may reply to reviews of,
+// @Description showing the Review to students again if it was hidden.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Review's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/reviews/{id}/flags [delete]
+func (h *ReviewHandler) ClearReviewFlags(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	review, err := h.ReviewService.ClearReviewFlags(id, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, review)
+}
+
+// CreateReview reviews a Course.
+// @Summary Review a Course.
+// @Description This endpoint rates and reviews a Course the student finished. Each student reviews a Course
+// @Description once, and can edit the review later.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param review body course.ReviewRequestFormat true "The Review to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReviewRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	review, err := h.ReviewService.CreateReview(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, review)
+}
+
+// FlagReview flags a Review as abusive.
+// @Summary Flag a Review.
+// @Description This endpoint reports a Review as abusive. Reviews flagged by enough users are hidden from students
+// @Description until the people teaching the Course dismiss the flags. Each user flags a Review once.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Review's identifier."
+// @Param flag body course.ReviewFlagRequestFormat true "The reason for flagging the Review."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.ReviewFlagResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/reviews/{id}/flags [post]
+func (h *ReviewHandler) FlagReview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReviewFlagRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	flag, err := h.ReviewService.FlagReview(id, requestFormat, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, flag)
+}
+
+// ReplyToReview replies to a Review.
+// @Summary Reply to a Review.
+// @Description This endpoint sets the reply to a Review of a Course the teacher may reply to reviews of. An empty
+// @Description reply removes it.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Review's identifier."
+// @Param reply body course.ReviewReplyRequestFormat true "The reply."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/reviews/{id}/reply [put]
+func (h *ReviewHandler) ReplyToReview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReviewReplyRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	review, err := h.ReviewService.ReplyToReview(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, review)
+}
+
+// ResolveMyReview resolves the current student's Review of a Course.
+// @Summary Resolve my Review of a Course.
+// @Description This endpoint resolves the Review the current student left on a Course.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/reviews/me [get]
+func (h *ReviewHandler) ResolveMyReview(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	review, err := h.ReviewService.ResolveMyReview(courseID, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, review)
+}
+
+// ResolveReviews resolves the Reviews of a Course.
+// @Summary Resolve the Reviews of a Course.
+// @Description This endpoint resolves a page of the Reviews of a Course, newest first. Reviews hidden after being
+// @Description flagged are left out, unless a teacher who may reply to reviews asks for them.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param rating query int false "Only list reviews with this rating, from 1 to 5."
+// @Param hidden query bool false "Also list hidden reviews. Teachers only."
+// @Param flagged query bool false "Only list flagged reviews, hidden or not. Teachers only."
+// @Param page query int false "The page number, starting from 0."
+// @Param limit query int false "The page size, up to 100. Defaults to 10."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/reviews [get]
+func (h *ReviewHandler) ResolveReviews(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	params := course.ReviewQueryParameters{
+		CourseID: courseID,
+		Limit:    10,
+	}
+
+	if rating := query.Get("rating"); rating != "" {
+		params.Rating, err = convertQueryParamsToInt(rating)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if hidden := query.Get("hidden"); hidden != "" {
+		params.IncludeHidden, err = strconv.ParseBool(hidden)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if flagged := query.Get("flagged"); flagged != "" {
+		params.FlaggedOnly, err = strconv.ParseBool(flagged)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+
+		// flagged Reviews include those hidden because of their flags
+		params.IncludeHidden = params.IncludeHidden || params.FlaggedOnly
+	}
+
+	if page := query.Get("page"); page != "" {
+		params.Page, err = convertQueryParamsToInt(page)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		params.Limit, err = convertQueryParamsToInt(limit)
+		if err != nil {
+			response.WithError(w, failure.BadRequest(err))
+			return
+		}
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	reviews, err := h.ReviewService.ResolveReviews(params, claims.UserID, claims.Role)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, reviews)
+}
+
+// UpdateMyReview updates the current student's Review of a Course.
+// @Summary Update my Review of a Course.
+// @Description This endpoint updates the rating and text of the Review the current student left on a Course.
+// @Tags courses/reviews
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param review body course.ReviewRequestFormat true "The Review to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.ReviewResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/reviews/me [put]
+func (h *ReviewHandler) UpdateMyReview(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.ReviewRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	review, err := h.ReviewService.UpdateMyReview(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, review)
+}
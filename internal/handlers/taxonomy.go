@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-chi/chi"
+)
+
+// TaxonomyHandler is the HTTP handler for the Categories and Tags of Courses.
+type TaxonomyHandler struct {
+	TaxonomyService course.TaxonomyService
+	AuthMiddleware  *middleware.Authentication
+}
+
+// ProvideTaxonomyHandler is the provider for this handler.
+func ProvideTaxonomyHandler(taxonomyService course.TaxonomyService, authMiddleware *middleware.Authentication) TaxonomyHandler {
+	return TaxonomyHandler{
+		TaxonomyService: taxonomyService,
+		AuthMiddleware:  authMiddleware,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *TaxonomyHandler) Router(r chi.Router) {
+	r.Route("/categories", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.ResolveCategoryTree)
+			r.Get("/{id}", h.ResolveCategoryByID)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateCategory)
+			r.Put("/{id}", h.UpdateCategory)
+			r.Delete("/{id}", h.DeleteCategory)
+		})
+	})
+
+	r.Route("/tags", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.RoleCheck(shared.RoleTeacher, shared.RoleStudent))
+			r.Get("/", h.AutocompleteTags)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Post("/", h.CreateTag)
+			r.Put("/{id}", h.UpdateTag)
+			r.Delete("/{id}", h.DeleteTag)
+		})
+	})
+
+	r.Route("/courses/{id}/categories", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.SetCourseCategories)
+		})
+	})
+
+	r.Route("/courses/{id}/tags", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(h.AuthMiddleware.ValidateAuth)
+			r.Use(h.AuthMiddleware.UserRoleCheck)
+			r.Put("/", h.SetCourseTags)
+		})
+	})
+}
+
+// ResolveCategoryTree resolves the category tree.
+// @Summary Resolve the category tree.
+// @Description This endpoint resolves the top-level Categories with their subcategories nested as children.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.CategoryResponseFormat}
+// @Failure 500 {object} response.Base
+// @Router /v1/categories [get]
+func (h *TaxonomyHandler) ResolveCategoryTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.TaxonomyService.ResolveCategoryTree()
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, categories)
+}
+
+// ResolveCategoryByID resolves a Category with its subcategories.
+// @Summary Resolve Category by ID
+// @Description This endpoint resolves a Category with its subcategories nested as children.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Category's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CategoryResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/categories/{id} [get]
+func (h *TaxonomyHandler) ResolveCategoryByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	category, err := h.TaxonomyService.ResolveCategoryByID(id)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, category)
+}
+
+// CreateCategory creates a new Category.
+// @Summary Create a new Category.
+// @Description This endpoint creates a Category at the top of the tree, or under the given parent.
+// @Description Category names are unique among their siblings.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param category body course.CategoryRequestFormat true "The Category to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.CategoryResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/categories [post]
+func (h *TaxonomyHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var requestFormat course.CategoryRequestFormat
+	err := decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	category, err := h.TaxonomyService.CreateCategory(requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, category)
+}
+
+// UpdateCategory updates a Category.
+// @Summary Update a Category.
+// @Description This endpoint renames a Category and moves it, with its subcategories, under another parent
+// @Description or to the top of the tree. A Category cannot be moved under one of its own subcategories.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Category's identifier."
+// @Param category body course.CategoryRequestFormat true "The Category to be updated."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CategoryResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/categories/{id} [put]
+func (h *TaxonomyHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CategoryRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	category, err := h.TaxonomyService.UpdateCategory(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, category)
+}
+
+// DeleteCategory deletes a Category.
+// @Summary Delete a Category.
+// @Description This endpoint deletes a Category without subcategories and removes it from its Courses.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Category's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CategoryResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/categories/{id} [delete]
+func (h *TaxonomyHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	category, err := h.TaxonomyService.DeleteCategory(id)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, category)
+}
+
+// AutocompleteTags suggests Tags starting with a prefix.
+// @Summary Autocomplete Tags.
+// @Description This endpoint suggests the Tags whose names start with the given prefix, the most used first.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param q query string false "The prefix of the tag name."
+// @Param limit query int false "The number of suggestions, up to 50. Defaults to 10."
+// @Produce json
+// @Success 200 {object} response.Base{data=[]course.TagResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/tags [get]
+func (h *TaxonomyHandler) AutocompleteTags(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if limitString := r.URL.Query().Get("limit"); limitString != "" {
+		var err error
+		limit, err = convertQueryParamsToInt(limitString)
+		if err != nil || limit < 1 || limit > 50 {
+			response.WithError(w, failure.BadRequestFromString("limit must be between 1 and 50"))
+			return
+		}
+	}
+
+	tags, err := h.TaxonomyService.AutocompleteTags(r.URL.Query().Get("q"), limit)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, tags)
+}
+
+// CreateTag creates a new Tag.
+// @Summary Create a new Tag.
+// @Description This endpoint creates a Tag. Tag names are stored in lowercase and are unique.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param tag body course.TagRequestFormat true "The Tag to be created."
+// @Produce json
+// @Success 201 {object} response.Base{data=course.TagResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/tags [post]
+func (h *TaxonomyHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var requestFormat course.TagRequestFormat
+	err := decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	tag, err := h.TaxonomyService.CreateTag(requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusCreated, tag)
+}
+
+// UpdateTag renames a Tag.
+// @Summary Rename a Tag.
+// @Description This endpoint renames a Tag on every Course carrying it.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Tag's identifier."
+// @Param tag body course.TagRequestFormat true "The new name."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.TagResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 409 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/tags/{id} [put]
+func (h *TaxonomyHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.TagRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	tag, err := h.TaxonomyService.UpdateTag(id, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, tag)
+}
+
+// DeleteTag deletes a Tag.
+// @Summary Delete a Tag.
+// @Description This endpoint deletes a Tag and removes it from its Courses.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Tag's identifier."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.TagResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/tags/{id} [delete]
+func (h *TaxonomyHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	tag, err := h.TaxonomyService.DeleteTag(id)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, tag)
+}
+
+// SetCourseCategories replaces the Categories of a Course.
+// @Summary Set the Categories of a Course.
+// @Description This endpoint replaces the Categories a Course belongs to. Only the course owner may do this.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param categories body course.CourseCategoriesRequestFormat true "The Categories of the Course."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/categories [put]
+func (h *TaxonomyHandler) SetCourseCategories(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CourseCategoriesRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.TaxonomyService.SetCourseCategories(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
+
+// SetCourseTags replaces the Tags of a Course.
+// @Summary Set the Tags of a Course.
+// @Description This endpoint replaces the Tags of a Course, creating the Tags that do not exist yet.
+// @Description Only the course owner may do this.
+// @Tags courses/taxonomy
+// @Security EVMOauthToken
+// @Param id path string true "The Course's identifier."
+// @Param tags body course.CourseTagsRequestFormat true "The Tags of the Course."
+// @Produce json
+// @Success 200 {object} response.Base{data=course.CourseResponseFormat}
+// @Failure 400 {object} response.Base
+// @Failure 403 {object} response.Base
+// @Failure 404 {object} response.Base
+// @Failure 500 {object} response.Base
+// @Router /v1/courses/{id}/tags [put]
+func (h *TaxonomyHandler) SetCourseTags(w http.ResponseWriter, r *http.Request) {
+	courseID, err := uuidFromURLParam(r, "id")
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	var requestFormat course.CourseTagsRequestFormat
+	err = decodeRequest(r, &requestFormat)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	claims, err := claimsFromRequest(r)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	course, err := h.TaxonomyService.SetCourseTags(courseID, requestFormat, claims.UserID)
+	if err != nil {
+		response.WithError(w, err)
+		return
+	}
+
+	response.WithJSON(w, http.StatusOK, course)
+}
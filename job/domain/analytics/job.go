@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/course"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultInterval is how often analytics are aggregated when no interval is
+// configured.
+const defaultInterval = time.Hour
+
+// JobImpl is the periodic job aggregating the learning analytics of Courses,
+// so reading them doesn't scan the activity they are aggregated from.
+type JobImpl struct {
+	Config  *configs.Config
+	Service course.AnalyticsService
+}
+
+// ProvideJobImpl is the provider for this job.
+func ProvideJobImpl(config *configs.Config, service course.AnalyticsService) JobImpl {
+	j := JobImpl{}
+	j.Config = config
+	j.Service = service
+
+	return j
+}
+
+// Start starts aggregating right away and then every configured interval,
+// until the context is done.
+func (j *JobImpl) Start(ctx context.Context) {
+	if j.Config.Analytics.Aggregation.Enabled {
+		go j.run(ctx)
+	}
+}
+
+func (j *JobImpl) run(ctx context.Context) {
+	interval := time.Duration(j.Config.Analytics.Aggregation.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j.aggregate()
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopped aggregating learning analytics")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *JobImpl) aggregate() {
+	startedAt := time.Now()
+	err := j.Service.AggregateAnalytics(startedAt, j.Config.Analytics.Aggregation.LookbackDays)
+	if err != nil {
+		// the repository logged the cause; the next tick tries again
+		log.Warn().Err(err).Msg("Failed aggregating learning analytics")
+		return
+	}
+
+	log.
+		Info().
+		Dur("took", time.Since(startedAt)).
+		Int("lookbackDays", j.Config.Analytics.Aggregation.LookbackDays).
+		Msg("Aggregated learning analytics")
+}
@@ -0,0 +1,17 @@
+package job
+
+import (
+	"context"
+
+	"github.com/evermos/boilerplate-go/job/domain/analytics"
+)
+
+// Jobs is the wrapper to contain all periodic jobs.
+type Jobs struct {
+	Analytics analytics.JobImpl
+}
+
+// Start starts all periodic jobs, which run until the context is done.
+func (j *Jobs) Start(ctx context.Context) {
+	j.Analytics.Start(ctx)
+}
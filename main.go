@@ -4,12 +4,23 @@ package main
 //go:generate go run github.com/google/wire/cmd/wire
 
 import (
+	"context"
+
 	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/job"
 	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/transport/http"
 )
 
 var config *configs.Config
 
+// Service is everything the server runs, sharing one set of connections: the
+// HTTP transport and the periodic jobs.
+type Service struct {
+	HTTP *http.HTTP
+	Jobs job.Jobs
+}
+
 //@securityDefinitions.apikey EVMOauthToken
 //@in header
 //@name Authorization
@@ -24,7 +35,12 @@ func main() {
 	logger.SetLogLevel(config)
 
 	// Wire everything up
-	http := InitializeService()
+	service := InitializeService()
+
+	// Start periodic jobs, stopping them when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	service.Jobs.Start(jobsCtx)
+	service.HTTP.OnShutdown(stopJobs)
 
 	// consumers := InitializeEvent()
 
@@ -32,5 +48,5 @@ func main() {
 	// consumers.Start()
 
 	// Run server
-	http.SetupAndServe()
+	service.HTTP.SetupAndServe()
 }
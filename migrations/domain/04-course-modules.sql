@@ -0,0 +1,43 @@
+DROP TABLE IF EXISTS `lessons`;
+DROP TABLE IF EXISTS `modules`;
+
+CREATE TABLE IF NOT EXISTS `modules` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `position` INT NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_modules_1` (`course_id`, `position`),
+    CONSTRAINT `fk_modules_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `lessons` (
+    `id` CHAR(36) NOT NULL,
+    `module_id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `content` TEXT,
+    `position` INT NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_lessons_1` (`module_id`, `position`),
+    INDEX `idx_lessons_2` (`course_id`),
+    CONSTRAINT `fk_lessons_module_id` FOREIGN KEY (`module_id`)
+        REFERENCES `modules` (`id`),
+    CONSTRAINT `fk_lessons_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,25 @@
+ALTER TABLE `courses`
+    ADD COLUMN `seat_limit` INT NULL AFTER `content`;
+
+DROP TABLE IF EXISTS `enrollments`;
+
+CREATE TABLE IF NOT EXISTS `enrollments` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `status` ENUM('pending', 'active', 'waitlisted', 'withdrawn', 'removed') NOT NULL,
+    `requested_at` DATETIME NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_enrollments_1` (`course_id`, `student_id`),
+    INDEX `idx_enrollments_2` (`course_id`, `status`, `requested_at`),
+    INDEX `idx_enrollments_3` (`student_id`),
+    CONSTRAINT `fk_enrollments_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_enrollments_student_id` FOREIGN KEY (`student_id`)
+        REFERENCES `users` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
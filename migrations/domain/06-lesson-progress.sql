@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS `lesson_progress`;
+
+CREATE TABLE IF NOT EXISTS `lesson_progress` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `last_position` INT NOT NULL DEFAULT 0,
+    `started_at` DATETIME NOT NULL,
+    `completed_at` DATETIME,
+    `updated_at` DATETIME,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_lesson_progress_1` (`lesson_id`, `student_id`),
+    INDEX `idx_lesson_progress_2` (`course_id`, `student_id`),
+    CONSTRAINT `fk_lesson_progress_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_lesson_progress_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
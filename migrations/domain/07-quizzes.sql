@@ -0,0 +1,95 @@
+DROP TABLE IF EXISTS `quiz_attempt_answers`;
+DROP TABLE IF EXISTS `quiz_attempts`;
+DROP TABLE IF EXISTS `quiz_options`;
+DROP TABLE IF EXISTS `quiz_questions`;
+DROP TABLE IF EXISTS `quizzes`;
+
+CREATE TABLE IF NOT EXISTS `quizzes` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36) NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `time_limit_seconds` INT,
+    `max_attempts` INT,
+    `shuffle_answers` TINYINT(1) NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_quizzes_1` (`lesson_id`),
+    INDEX `idx_quizzes_2` (`course_id`),
+    CONSTRAINT `fk_quizzes_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_quizzes_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `quiz_questions` (
+    `id` CHAR(36) NOT NULL,
+    `quiz_id` CHAR(36) NOT NULL,
+    `type` ENUM('single_choice', 'multiple_choice', 'true_false', 'short_answer') NOT NULL,
+    `prompt` TEXT NOT NULL,
+    `answer_text` VARCHAR(255),
+    `points` INT NOT NULL DEFAULT 1,
+    `position` INT NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_quiz_questions_1` (`quiz_id`, `position`),
+    CONSTRAINT `fk_quiz_questions_quiz_id` FOREIGN KEY (`quiz_id`)
+        REFERENCES `quizzes` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `quiz_options` (
+    `id` CHAR(36) NOT NULL,
+    `question_id` CHAR(36) NOT NULL,
+    `text` VARCHAR(255) NOT NULL,
+    `is_correct` TINYINT(1) NOT NULL DEFAULT 0,
+    `position` INT NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_quiz_options_1` (`question_id`, `position`),
+    CONSTRAINT `fk_quiz_options_question_id` FOREIGN KEY (`question_id`)
+        REFERENCES `quiz_questions` (`id`)
+        ON DELETE CASCADE
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `quiz_attempts` (
+    `id` CHAR(36) NOT NULL,
+    `quiz_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `attempt_number` INT NOT NULL,
+    `shuffle_seed` BIGINT NOT NULL,
+    `status` ENUM('in_progress', 'submitted', 'expired') NOT NULL,
+    `started_at` DATETIME NOT NULL,
+    `expires_at` DATETIME,
+    `submitted_at` DATETIME,
+    `score` INT NOT NULL DEFAULT 0,
+    `max_score` INT NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_quiz_attempts_1` (`quiz_id`, `student_id`, `attempt_number`),
+    CONSTRAINT `fk_quiz_attempts_quiz_id` FOREIGN KEY (`quiz_id`)
+        REFERENCES `quizzes` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `quiz_attempt_answers` (
+    `id` CHAR(36) NOT NULL,
+    `attempt_id` CHAR(36) NOT NULL,
+    `question_id` CHAR(36) NOT NULL,
+    `selected_option_ids` TEXT,
+    `answer_text` VARCHAR(255),
+    `is_correct` TINYINT(1) NOT NULL DEFAULT 0,
+    `points_awarded` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_quiz_attempt_answers_1` (`attempt_id`, `question_id`),
+    INDEX `idx_quiz_attempt_answers_2` (`question_id`),
+    CONSTRAINT `fk_quiz_attempt_answers_attempt_id` FOREIGN KEY (`attempt_id`)
+        REFERENCES `quiz_attempts` (`id`),
+    CONSTRAINT `fk_quiz_attempt_answers_question_id` FOREIGN KEY (`question_id`)
+        REFERENCES `quiz_questions` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
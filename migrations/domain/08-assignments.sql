@@ -0,0 +1,87 @@
+DROP TABLE IF EXISTS `submission_rubric_scores`;
+DROP TABLE IF EXISTS `submissions`;
+DROP TABLE IF EXISTS `assignment_rubric_criteria`;
+DROP TABLE IF EXISTS `assignments`;
+
+CREATE TABLE IF NOT EXISTS `assignments` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36) NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `instructions` TEXT,
+    `due_at` DATETIME NOT NULL,
+    `max_score` INT NOT NULL,
+    `late_penalty_per_day` INT NOT NULL DEFAULT 0,
+    `max_late_penalty` INT NOT NULL DEFAULT 100,
+    `late_cutoff_at` DATETIME,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_assignments_1` (`lesson_id`),
+    INDEX `idx_assignments_2` (`course_id`),
+    CONSTRAINT `fk_assignments_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_assignments_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `assignment_rubric_criteria` (
+    `id` CHAR(36) NOT NULL,
+    `assignment_id` CHAR(36) NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `max_points` INT NOT NULL,
+    `position` INT NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_assignment_rubric_criteria_1` (`assignment_id`, `position`),
+    CONSTRAINT `fk_assignment_rubric_criteria_assignment_id` FOREIGN KEY (`assignment_id`)
+        REFERENCES `assignments` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `submissions` (
+    `id` CHAR(36) NOT NULL,
+    `assignment_id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `status` ENUM('submitted', 'in_review', 'graded', 'returned') NOT NULL,
+    `content` TEXT,
+    `links` TEXT,
+    `revision` INT NOT NULL DEFAULT 1,
+    `submitted_at` DATETIME NOT NULL,
+    `days_late` INT NOT NULL DEFAULT 0,
+    `penalty_percent` INT NOT NULL DEFAULT 0,
+    `raw_score` INT,
+    `score` DECIMAL(10,2),
+    `feedback` TEXT,
+    `graded_at` DATETIME,
+    `graded_by` CHAR(36),
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_submissions_1` (`assignment_id`, `student_id`),
+    INDEX `idx_submissions_2` (`course_id`, `status`, `submitted_at`),
+    CONSTRAINT `fk_submissions_assignment_id` FOREIGN KEY (`assignment_id`)
+        REFERENCES `assignments` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `submission_rubric_scores` (
+    `id` CHAR(36) NOT NULL,
+    `submission_id` CHAR(36) NOT NULL,
+    `criterion_id` CHAR(36) NOT NULL,
+    `points` INT NOT NULL,
+    `comment` TEXT,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_submission_rubric_scores_1` (`submission_id`, `criterion_id`),
+    CONSTRAINT `fk_submission_rubric_scores_submission_id` FOREIGN KEY (`submission_id`)
+        REFERENCES `submissions` (`id`),
+    CONSTRAINT `fk_submission_rubric_scores_criterion_id` FOREIGN KEY (`criterion_id`)
+        REFERENCES `assignment_rubric_criteria` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
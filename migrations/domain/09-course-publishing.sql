@@ -0,0 +1,100 @@
+ALTER TABLE `courses`
+    ADD COLUMN `status` ENUM('draft', 'in_review', 'published', 'archived') NOT NULL DEFAULT 'draft' AFTER `seat_limit`,
+    ADD COLUMN `published_version` INT NULL AFTER `status`;
+
+DROP TABLE IF EXISTS `course_versions`;
+
+CREATE TABLE IF NOT EXISTS `course_versions` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `version` INT NOT NULL,
+    `title` VARCHAR(255) NOT NULL,
+    `snapshot` LONGTEXT NOT NULL,
+    `restored_from` INT,
+    `published_at` DATETIME NOT NULL,
+    `published_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_course_versions_1` (`course_id`, `version`),
+    CONSTRAINT `fk_course_versions_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+-- Courses that existed before publishing was introduced were all visible, so
+-- they become published, each with an initial version capturing its content.
+-- Only courses created from now on start as drafts.
+SET SESSION group_concat_max_len = 1073741824;
+
+UPDATE `courses`
+SET
+    `status` = 'published',
+    `published_version` = 1;
+
+INSERT INTO `course_versions` (
+    `id`,
+    `course_id`,
+    `version`,
+    `title`,
+    `snapshot`,
+    `restored_from`,
+    `published_at`,
+    `published_by`
+)
+SELECT
+    UUID(),
+    c.`id`,
+    1,
+    c.`title`,
+    JSON_OBJECT(
+        'title', c.`title`,
+        'content', COALESCE(c.`content`, ''),
+        'modules', CAST(CONCAT('[', COALESCE(ms.`modules`, ''), ']') AS JSON)
+    ),
+    NULL,
+    COALESCE(c.`updated_at`, c.`created_at`),
+    c.`user_id`
+FROM `courses` c
+LEFT JOIN (
+    SELECT
+        mj.`course_id`,
+        GROUP_CONCAT(mj.`module` ORDER BY mj.`position` SEPARATOR ',') AS `modules`
+    FROM (
+        SELECT
+            m.`course_id`,
+            m.`position`,
+            JSON_OBJECT(
+                'id', m.`id`,
+                'title', m.`title`,
+                'position', m.`position`,
+                'createdAt', DATE_FORMAT(m.`created_at`, '%Y-%m-%dT%H:%i:%sZ'),
+                'createdBy', m.`created_by`,
+                'updatedAt', DATE_FORMAT(m.`updated_at`, '%Y-%m-%dT%H:%i:%sZ'),
+                'updatedBy', m.`updated_by`,
+                'lessons', CAST(CONCAT('[', COALESCE(ls.`lessons`, ''), ']') AS JSON)
+            ) AS `module`
+        FROM `modules` m
+        LEFT JOIN (
+            SELECT
+                l.`module_id`,
+                GROUP_CONCAT(
+                    JSON_OBJECT(
+                        'id', l.`id`,
+                        'title', l.`title`,
+                        'content', COALESCE(l.`content`, ''),
+                        'position', l.`position`,
+                        'createdAt', DATE_FORMAT(l.`created_at`, '%Y-%m-%dT%H:%i:%sZ'),
+                        'createdBy', l.`created_by`,
+                        'updatedAt', DATE_FORMAT(l.`updated_at`, '%Y-%m-%dT%H:%i:%sZ'),
+                        'updatedBy', l.`updated_by`
+                    )
+                    ORDER BY l.`position`
+                    SEPARATOR ','
+                ) AS `lessons`
+            FROM `lessons` l
+            WHERE l.`deleted_at` IS NULL
+            GROUP BY l.`module_id`
+        ) ls ON ls.`module_id` = m.`id`
+        WHERE m.`deleted_at` IS NULL
+    ) mj
+    GROUP BY mj.`course_id`
+) ms ON ms.`course_id` = c.`id`;
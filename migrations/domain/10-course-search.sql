@@ -0,0 +1,29 @@
+ALTER TABLE `courses`
+    ADD FULLTEXT INDEX `idx_courses_fulltext` (`title`, `content`),
+    ADD INDEX `idx_courses_1` (`status`),
+    ADD INDEX `idx_courses_2` (`created_at`);
+
+DROP TABLE IF EXISTS `course_tags`;
+DROP TABLE IF EXISTS `tags`;
+
+CREATE TABLE IF NOT EXISTS `tags` (
+    `id` CHAR(36) NOT NULL,
+    `name` VARCHAR(64) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_tags_1` (`name`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `course_tags` (
+    `course_id` CHAR(36) NOT NULL,
+    `tag_id` CHAR(36) NOT NULL,
+    PRIMARY KEY (`course_id`, `tag_id`),
+    INDEX `idx_course_tags_1` (`tag_id`),
+    CONSTRAINT `fk_course_tags_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_course_tags_tag_id` FOREIGN KEY (`tag_id`)
+        REFERENCES `tags` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,33 @@
+ALTER TABLE `tags`
+    ADD COLUMN `updated_at` DATETIME AFTER `created_by`,
+    ADD COLUMN `updated_by` CHAR(36) AFTER `updated_at`;
+
+DROP TABLE IF EXISTS `course_categories`;
+DROP TABLE IF EXISTS `categories`;
+
+CREATE TABLE IF NOT EXISTS `categories` (
+    `id` CHAR(36) NOT NULL,
+    `parent_id` CHAR(36),
+    `name` VARCHAR(100) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_categories_1` (`parent_id`),
+    CONSTRAINT `fk_categories_parent_id` FOREIGN KEY (`parent_id`)
+        REFERENCES `categories` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `course_categories` (
+    `course_id` CHAR(36) NOT NULL,
+    `category_id` CHAR(36) NOT NULL,
+    PRIMARY KEY (`course_id`, `category_id`),
+    INDEX `idx_course_categories_1` (`category_id`),
+    CONSTRAINT `fk_course_categories_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_course_categories_category_id` FOREIGN KEY (`category_id`)
+        REFERENCES `categories` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
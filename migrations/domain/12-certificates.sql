@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS `certificates`;
+
+CREATE TABLE IF NOT EXISTS `certificates` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `student_name` VARCHAR(255) NOT NULL DEFAULT '',
+    `code` VARCHAR(14) NOT NULL,
+    `course_title` VARCHAR(255) NOT NULL,
+    `completed_at` DATETIME NOT NULL,
+    `signature` CHAR(64) NOT NULL,
+    `issued_at` DATETIME NOT NULL,
+    `revoked_at` DATETIME,
+    `revoked_by` CHAR(36),
+    `revocation_reason` VARCHAR(500),
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_certificates_1` (`code`),
+    UNIQUE `idx_certificates_2` (`course_id`, `student_id`),
+    INDEX `idx_certificates_3` (`student_id`),
+    CONSTRAINT `fk_certificates_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS `course_prerequisites`;
+
+CREATE TABLE IF NOT EXISTS `course_prerequisites` (
+    `course_id` CHAR(36) NOT NULL,
+    `prerequisite_id` CHAR(36) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`course_id`, `prerequisite_id`),
+    INDEX `idx_course_prerequisites_1` (`prerequisite_id`),
+    CONSTRAINT `fk_course_prerequisites_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_course_prerequisites_prerequisite_id` FOREIGN KEY (`prerequisite_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,53 @@
+DROP TABLE IF EXISTS `cohort_lesson_releases`;
+DROP TABLE IF EXISTS `cohort_instructors`;
+DROP TABLE IF EXISTS `cohorts`;
+
+CREATE TABLE IF NOT EXISTS `cohorts` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `timezone` VARCHAR(64) NOT NULL,
+    `start_date` DATE NOT NULL,
+    `end_date` DATE NOT NULL,
+    `enrollment_opens_at` DATETIME NOT NULL,
+    `enrollment_closes_at` DATETIME NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_cohorts_1` (`course_id`, `start_date`),
+    CONSTRAINT `fk_cohorts_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `cohort_instructors` (
+    `cohort_id` CHAR(36) NOT NULL,
+    `instructor_id` CHAR(36) NOT NULL,
+    PRIMARY KEY (`cohort_id`, `instructor_id`),
+    INDEX `idx_cohort_instructors_1` (`instructor_id`),
+    CONSTRAINT `fk_cohort_instructors_cohort_id` FOREIGN KEY (`cohort_id`)
+        REFERENCES `cohorts` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `cohort_lesson_releases` (
+    `cohort_id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36) NOT NULL,
+    `day_offset` INT NOT NULL,
+    PRIMARY KEY (`cohort_id`, `lesson_id`),
+    CONSTRAINT `fk_cohort_lesson_releases_cohort_id` FOREIGN KEY (`cohort_id`)
+        REFERENCES `cohorts` (`id`),
+    CONSTRAINT `fk_cohort_lesson_releases_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+ALTER TABLE `enrollments`
+    ADD COLUMN `cohort_id` CHAR(36) AFTER `student_id`,
+    ADD INDEX `idx_enrollments_cohort_id` (`cohort_id`),
+    ADD CONSTRAINT `fk_enrollments_cohort_id` FOREIGN KEY (`cohort_id`)
+        REFERENCES `cohorts` (`id`);
@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS `live_sessions`;
+
+CREATE TABLE IF NOT EXISTS `live_sessions` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36),
+    `title` VARCHAR(200) NOT NULL,
+    `description` TEXT NOT NULL,
+    `starts_at` DATETIME NOT NULL,
+    `timezone` VARCHAR(64) NOT NULL,
+    `duration_minutes` INT NOT NULL,
+    `meeting_url` VARCHAR(2048) NOT NULL,
+    `recurrence_frequency` VARCHAR(10) NOT NULL DEFAULT 'none',
+    `recurrence_interval` INT NOT NULL DEFAULT 1,
+    `recurrence_by_day` VARCHAR(20) NOT NULL DEFAULT '',
+    `recurrence_count` INT,
+    `recurrence_until` DATE,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_live_sessions_1` (`course_id`, `starts_at`),
+    INDEX `idx_live_sessions_2` (`cohort_id`),
+    CONSTRAINT `fk_live_sessions_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_live_sessions_cohort_id` FOREIGN KEY (`cohort_id`)
+        REFERENCES `cohorts` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,48 @@
+DROP TABLE IF EXISTS `attendance_records`;
+
+CREATE TABLE IF NOT EXISTS `attendance_records` (
+    `session_id` CHAR(36) NOT NULL,
+    `sequence` INT NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `status` VARCHAR(10) NOT NULL,
+    `checked_in_at` DATETIME,
+    `note` VARCHAR(500) NOT NULL DEFAULT '',
+    `marked_at` DATETIME NOT NULL,
+    `marked_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`session_id`, `sequence`, `student_id`),
+    INDEX `idx_attendance_records_1` (`course_id`, `student_id`),
+    CONSTRAINT `fk_attendance_records_session_id` FOREIGN KEY (`session_id`)
+        REFERENCES `live_sessions` (`id`),
+    CONSTRAINT `fk_attendance_records_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `attendance_check_in_codes`;
+
+CREATE TABLE IF NOT EXISTS `attendance_check_in_codes` (
+    `session_id` CHAR(36) NOT NULL,
+    `sequence` INT NOT NULL,
+    `code` CHAR(6) NOT NULL,
+    `expires_at` DATETIME NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`session_id`, `sequence`),
+    CONSTRAINT `fk_attendance_check_in_codes_session_id` FOREIGN KEY (`session_id`)
+        REFERENCES `live_sessions` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `course_completion_rules`;
+
+CREATE TABLE IF NOT EXISTS `course_completion_rules` (
+    `course_id` CHAR(36) NOT NULL,
+    `min_attendance_percent` INT,
+    `updated_at` DATETIME NOT NULL,
+    `updated_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`course_id`),
+    CONSTRAINT `fk_course_completion_rules_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
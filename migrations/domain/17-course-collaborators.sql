@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS `course_collaborators`;
+
+CREATE TABLE IF NOT EXISTS `course_collaborators` (
+    `course_id` CHAR(36) NOT NULL,
+    `user_id` CHAR(36) NOT NULL,
+    `role` VARCHAR(32) NOT NULL,
+    `status` VARCHAR(16) NOT NULL,
+    `invited_at` DATETIME NOT NULL,
+    `invited_by` CHAR(36) NOT NULL,
+    `accepted_at` DATETIME,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    PRIMARY KEY (`course_id`, `user_id`),
+    INDEX `idx_course_collaborators_1` (`user_id`, `status`),
+    CONSTRAINT `fk_course_collaborators_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
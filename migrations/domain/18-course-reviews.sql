@@ -0,0 +1,45 @@
+ALTER TABLE `courses`
+    ADD COLUMN `rating_count` INT NOT NULL DEFAULT 0 AFTER `published_version`,
+    ADD COLUMN `rating_average` DECIMAL(3,2) NULL AFTER `rating_count`,
+    ADD COLUMN `rating_1_count` INT NOT NULL DEFAULT 0 AFTER `rating_average`,
+    ADD COLUMN `rating_2_count` INT NOT NULL DEFAULT 0 AFTER `rating_1_count`,
+    ADD COLUMN `rating_3_count` INT NOT NULL DEFAULT 0 AFTER `rating_2_count`,
+    ADD COLUMN `rating_4_count` INT NOT NULL DEFAULT 0 AFTER `rating_3_count`,
+    ADD COLUMN `rating_5_count` INT NOT NULL DEFAULT 0 AFTER `rating_4_count`,
+    ADD INDEX `idx_courses_rating` (`rating_average`, `rating_count`);
+
+DROP TABLE IF EXISTS `course_review_flags`;
+DROP TABLE IF EXISTS `course_reviews`;
+
+CREATE TABLE IF NOT EXISTS `course_reviews` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `rating` TINYINT NOT NULL,
+    `body` TEXT NOT NULL,
+    `reply` TEXT,
+    `replied_at` DATETIME,
+    `replied_by` CHAR(36),
+    `flag_count` INT NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    UNIQUE `idx_course_reviews_1` (`course_id`, `student_id`),
+    INDEX `idx_course_reviews_2` (`course_id`, `created_at`),
+    CONSTRAINT `fk_course_reviews_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `course_review_flags` (
+    `review_id` CHAR(36) NOT NULL,
+    `user_id` CHAR(36) NOT NULL,
+    `reason` VARCHAR(255) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`review_id`, `user_id`),
+    CONSTRAINT `fk_course_review_flags_review_id` FOREIGN KEY (`review_id`)
+        REFERENCES `course_reviews` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
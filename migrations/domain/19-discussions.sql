@@ -0,0 +1,69 @@
+DROP TABLE IF EXISTS `discussion_upvotes`;
+DROP TABLE IF EXISTS `discussion_posts`;
+DROP TABLE IF EXISTS `discussion_threads`;
+
+CREATE TABLE IF NOT EXISTS `discussion_threads` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36),
+    `title` VARCHAR(255) NOT NULL,
+    `body` TEXT NOT NULL,
+    `reply_count` INT NOT NULL DEFAULT 0,
+    `upvote_count` INT NOT NULL DEFAULT 0,
+    `last_activity_at` DATETIME(6) NOT NULL,
+    `pinned_at` DATETIME,
+    `pinned_by` CHAR(36),
+    `resolved_at` DATETIME,
+    `resolved_by` CHAR(36),
+    `locked_at` DATETIME,
+    `locked_by` CHAR(36),
+    `hidden_at` DATETIME,
+    `hidden_by` CHAR(36),
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_discussion_threads_1` (`course_id`, `last_activity_at`, `id`),
+    INDEX `idx_discussion_threads_2` (`lesson_id`, `last_activity_at`, `id`),
+    CONSTRAINT `fk_discussion_threads_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_discussion_threads_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `discussion_posts` (
+    `id` CHAR(36) NOT NULL,
+    `thread_id` CHAR(36) NOT NULL,
+    `parent_id` CHAR(36),
+    `body` TEXT NOT NULL,
+    `upvote_count` INT NOT NULL DEFAULT 0,
+    `hidden_at` DATETIME,
+    `hidden_by` CHAR(36),
+    `created_at` DATETIME(6) NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `updated_at` DATETIME,
+    `updated_by` CHAR(36),
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_discussion_posts_1` (`thread_id`, `created_at`),
+    CONSTRAINT `fk_discussion_posts_thread_id` FOREIGN KEY (`thread_id`)
+        REFERENCES `discussion_threads` (`id`),
+    CONSTRAINT `fk_discussion_posts_parent_id` FOREIGN KEY (`parent_id`)
+        REFERENCES `discussion_posts` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `discussion_upvotes` (
+    `subject_id` CHAR(36) NOT NULL,
+    `subject_type` VARCHAR(16) NOT NULL,
+    `user_id` CHAR(36) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`subject_id`, `user_id`),
+    INDEX `idx_discussion_upvotes_1` (`user_id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,33 @@
+DROP TABLE IF EXISTS `announcement_reads`;
+DROP TABLE IF EXISTS `announcements`;
+
+CREATE TABLE IF NOT EXISTS `announcements` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36),
+    `title` VARCHAR(255) NOT NULL,
+    `body` TEXT NOT NULL,
+    `recipient_count` INT NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_announcements_1` (`course_id`, `created_at`),
+    CONSTRAINT `fk_announcements_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`),
+    CONSTRAINT `fk_announcements_cohort_id` FOREIGN KEY (`cohort_id`)
+        REFERENCES `cohorts` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `announcement_reads` (
+    `announcement_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `read_at` DATETIME NOT NULL,
+    PRIMARY KEY (`announcement_id`, `student_id`),
+    INDEX `idx_announcement_reads_1` (`student_id`),
+    CONSTRAINT `fk_announcement_reads_announcement_id` FOREIGN KEY (`announcement_id`)
+        REFERENCES `announcements` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS `lesson_attachments`;
+
+CREATE TABLE IF NOT EXISTS `lesson_attachments` (
+    `id` CHAR(36) NOT NULL,
+    `lesson_id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `file_name` VARCHAR(255) NOT NULL,
+    `content_type` VARCHAR(100) NOT NULL,
+    `size` BIGINT NOT NULL,
+    `checksum` CHAR(64) NOT NULL,
+    `storage_key` VARCHAR(255) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `created_by` CHAR(36) NOT NULL,
+    `deleted_at` DATETIME,
+    `deleted_by` CHAR(36),
+    PRIMARY KEY (`id`),
+    INDEX `idx_lesson_attachments_1` (`lesson_id`, `created_at`),
+    CONSTRAINT `fk_lesson_attachments_lesson_id` FOREIGN KEY (`lesson_id`)
+        REFERENCES `lessons` (`id`),
+    CONSTRAINT `fk_lesson_attachments_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,58 @@
+DROP TABLE IF EXISTS `grade_overrides`;
+DROP TABLE IF EXISTS `gradebook_letter_grades`;
+DROP TABLE IF EXISTS `gradebook_categories`;
+DROP TABLE IF EXISTS `gradebooks`;
+
+CREATE TABLE IF NOT EXISTS `gradebooks` (
+    `course_id` CHAR(36) NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    `updated_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`course_id`),
+    CONSTRAINT `fk_gradebooks_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `gradebook_categories` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `kind` VARCHAR(20) NOT NULL,
+    `weight` INT NOT NULL,
+    `drop_lowest` INT NOT NULL DEFAULT 0,
+    `item_ids` TEXT,
+    `position` INT NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_gradebook_categories_1` (`course_id`, `position`),
+    CONSTRAINT `fk_gradebook_categories_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `gradebooks` (`course_id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `gradebook_letter_grades` (
+    `course_id` CHAR(36) NOT NULL,
+    `letter` VARCHAR(5) NOT NULL,
+    `min_percent` DECIMAL(5,2) NOT NULL,
+    PRIMARY KEY (`course_id`, `letter`),
+    CONSTRAINT `fk_gradebook_letter_grades_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `gradebooks` (`course_id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `grade_overrides` (
+    `id` CHAR(36) NOT NULL,
+    `course_id` CHAR(36) NOT NULL,
+    `student_id` CHAR(36) NOT NULL,
+    `percent` DECIMAL(5,2),
+    `letter` VARCHAR(5),
+    `previous_percent` DECIMAL(5,2),
+    `previous_letter` VARCHAR(5),
+    `reason` VARCHAR(500) NOT NULL,
+    `changed_at` DATETIME(6) NOT NULL,
+    `changed_by` CHAR(36) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_grade_overrides_1` (`course_id`, `student_id`, `changed_at`),
+    CONSTRAINT `fk_grade_overrides_course_id` FOREIGN KEY (`course_id`)
+        REFERENCES `courses` (`id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
@@ -0,0 +1,60 @@
+DROP TABLE IF EXISTS `analytics_active_learners_daily`;
+DROP TABLE IF EXISTS `analytics_quiz_scores_daily`;
+DROP TABLE IF EXISTS `analytics_completion_times_daily`;
+DROP TABLE IF EXISTS `analytics_lesson_progress_daily`;
+DROP TABLE IF EXISTS `analytics_enrollments_daily`;
+
+CREATE TABLE IF NOT EXISTS `analytics_enrollments_daily` (
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36) NOT NULL DEFAULT '',
+    `day` DATE NOT NULL,
+    `enrollments` INT NOT NULL DEFAULT 0,
+    `withdrawals` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`course_id`, `day`, `cohort_id`),
+    INDEX `idx_analytics_enrollments_daily_1` (`day`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `analytics_lesson_progress_daily` (
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36) NOT NULL DEFAULT '',
+    `lesson_id` CHAR(36) NOT NULL,
+    `day` DATE NOT NULL,
+    `started` INT NOT NULL DEFAULT 0,
+    `completed` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`course_id`, `day`, `cohort_id`, `lesson_id`),
+    INDEX `idx_analytics_lesson_progress_daily_1` (`day`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `analytics_completion_times_daily` (
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36) NOT NULL DEFAULT '',
+    `day` DATE NOT NULL,
+    `completions` INT NOT NULL DEFAULT 0,
+    `total_seconds` BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`course_id`, `day`, `cohort_id`),
+    INDEX `idx_analytics_completion_times_daily_1` (`day`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `analytics_quiz_scores_daily` (
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36) NOT NULL DEFAULT '',
+    `quiz_id` CHAR(36) NOT NULL,
+    `day` DATE NOT NULL,
+    `bucket` TINYINT NOT NULL,
+    `attempts` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`course_id`, `day`, `cohort_id`, `quiz_id`, `bucket`),
+    INDEX `idx_analytics_quiz_scores_daily_1` (`day`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `analytics_active_learners_daily` (
+    `course_id` CHAR(36) NOT NULL,
+    `cohort_id` CHAR(36) NOT NULL DEFAULT '',
+    `day` DATE NOT NULL,
+    `active_learners` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`course_id`, `day`, `cohort_id`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
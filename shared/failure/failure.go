@@ -43,6 +43,15 @@ func Unauthorized(msg string) error {
 	}
 }
 
+// Forbidden returns a new Failure with code for requests from an authenticated
+// user who is not allowed to perform them.
+func Forbidden(msg string) error {
+	return &Failure{
+		Code:    http.StatusForbidden,
+		Message: msg,
+	}
+}
+
 // InternalError returns a new Failure with code for internal error and message derived from an error interface.
 func InternalError(err error) error {
 	if err != nil {
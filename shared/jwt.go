@@ -5,6 +5,13 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
+const (
+	// RoleTeacher is the role of users who author and run courses.
+	RoleTeacher = "teacher"
+	// RoleStudent is the role of users who enroll in courses.
+	RoleStudent = "student"
+)
+
 type Claims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
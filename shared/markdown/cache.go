@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// renderCacheSize is the number of renderings kept in memory.
+const renderCacheSize = 1024
+
+// renderings caches the latest renderings.
+var renderings = newCache(renderCacheSize)
+
+// cache is a least recently used cache of renderings, keyed by the digest of
+// their source. Sources are never modified in place, so entries don't need
+// to be invalidated: an edited source simply misses the cache.
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+// cacheEntry is an entry of a cache.
+type cacheEntry struct {
+	key      [sha256.Size]byte
+	document Document
+}
+
+// newCache creates a cache holding up to size renderings.
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// resolve returns the cached rendering of a source, rendering and caching it
+// on a miss.
+func (c *cache) resolve(source string, render func(string) Document) Document {
+	key := sha256.Sum256([]byte(source))
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*cacheEntry).document
+	}
+	c.mu.Unlock()
+
+	// render outside of the lock; a concurrent miss renders twice at worst
+	document := render(source)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return document
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, document: document})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	return document
+}
@@ -0,0 +1,194 @@
+package markdown
+
+import (
+	"html"
+	"strings"
+)
+
+// Highlighted tokens are wrapped in spans with these classes, for
+// stylesheets to color.
+const (
+	classComment = "hl-comment"
+	classKeyword = "hl-keyword"
+	classNumber  = "hl-number"
+	classString  = "hl-string"
+)
+
+// syntax describes the tokens of a programming language well enough to
+// highlight it.
+type syntax struct {
+	keywords       map[string]bool
+	caseFolded     bool
+	lineComments   []string
+	blockComment   [2]string
+	stringQuotes   string
+	multilineQuote byte
+}
+
+// newSyntax creates a syntax from a space-separated list of keywords.
+func newSyntax(keywords string, s syntax) *syntax {
+	s.keywords = make(map[string]bool)
+	for _, keyword := range strings.Fields(keywords) {
+		s.keywords[keyword] = true
+	}
+
+	return &s
+}
+
+var (
+	cLike = syntax{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, stringQuotes: `"'`}
+
+	syntaxGo = newSyntax(`break case chan const continue default defer else fallthrough for func go goto if
+		import interface map package range return select struct switch type var true false nil iota`,
+		syntax{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, stringQuotes: "\"'`", multilineQuote: '`'})
+	syntaxJavaScript = newSyntax(`async await break case catch class const continue debugger default delete do else
+		export extends finally for from function if import in instanceof let new of return static super switch this
+		throw try typeof var void while yield true false null undefined interface type enum implements readonly`,
+		syntax{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, stringQuotes: "\"'`", multilineQuote: '`'})
+	syntaxPython = newSyntax(`and as assert async await break class continue def del elif else except finally for
+		from global if import in is lambda nonlocal not or pass raise return try while with yield True False None`,
+		syntax{lineComments: []string{"#"}, stringQuotes: `"'`})
+	syntaxJava = newSyntax(`abstract boolean break byte case catch char class const continue default do double
+		else enum extends final finally float for if implements import instanceof int interface long new package
+		private protected public return short static super switch this throw throws try void volatile while var
+		true false null`, cLike)
+	syntaxC = newSyntax(`auto break case char class const continue default delete do double else enum extern
+		float for goto if include define inline int long namespace new private protected public return short signed
+		sizeof static struct switch template this typedef union unsigned using virtual void volatile while true
+		false nullptr NULL`, cLike)
+	syntaxRust = newSyntax(`as async await break const continue crate dyn else enum extern fn for if impl in let
+		loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while
+		true false`, cLike)
+	syntaxSQL = newSyntax(`select from where and or not insert into values update set delete create table alter
+		drop index primary key foreign references join left right inner outer on group by order having limit offset
+		as distinct union all null is in like between case when then else end default unique exists count`,
+		syntax{caseFolded: true, lineComments: []string{"--"}, blockComment: [2]string{"/*", "*/"}, stringQuotes: `'"`})
+	syntaxShell = newSyntax(`if then else elif fi for while until do done case esac function in return export
+		local echo exit`, syntax{lineComments: []string{"#"}, stringQuotes: `"'`})
+	syntaxJSON = newSyntax(`true false null`, syntax{stringQuotes: `"`})
+)
+
+// syntaxes maps the languages of code blocks, and their aliases, to their
+// syntax.
+var syntaxes = map[string]*syntax{
+	"go":         syntaxGo,
+	"golang":     syntaxGo,
+	"javascript": syntaxJavaScript,
+	"js":         syntaxJavaScript,
+	"typescript": syntaxJavaScript,
+	"ts":         syntaxJavaScript,
+	"python":     syntaxPython,
+	"py":         syntaxPython,
+	"java":       syntaxJava,
+	"c":          syntaxC,
+	"cpp":        syntaxC,
+	"c++":        syntaxC,
+	"rust":       syntaxRust,
+	"rs":         syntaxRust,
+	"sql":        syntaxSQL,
+	"bash":       syntaxShell,
+	"sh":         syntaxShell,
+	"shell":      syntaxShell,
+	"json":       syntaxJSON,
+}
+
+// highlight renders code as HTML, wrapping its comments, keywords, numbers
+// and strings in spans when the language is known. Everything is escaped.
+func highlight(language string, code string) string {
+	s, ok := syntaxes[language]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(code); {
+		class, end := s.token(code, i)
+		text := html.EscapeString(code[i:end])
+		if class == "" {
+			b.WriteString(text)
+		} else {
+			b.WriteString(`<span class="` + class + `">` + text + `</span>`)
+		}
+
+		i = end
+	}
+
+	return b.String()
+}
+
+// token finds the token starting at i, returning its class and where it
+// ends. Tokens that are not highlighted have no class.
+func (s *syntax) token(code string, i int) (class string, end int) {
+	rest := code[i:]
+	for _, prefix := range s.lineComments {
+		if strings.HasPrefix(rest, prefix) {
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				return classComment, i + end
+			}
+			return classComment, len(code)
+		}
+	}
+
+	if open := s.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+		closing := s.blockComment[1]
+		if end := strings.Index(rest[len(open):], closing); end >= 0 {
+			return classComment, i + len(open) + end + len(closing)
+		}
+		return classComment, len(code)
+	}
+
+	c := code[i]
+	switch {
+	case strings.IndexByte(s.stringQuotes, c) >= 0:
+		return classString, i + s.stringLength(rest)
+	case isDigit(c):
+		end = i + 1
+		for end < len(code) && (isWordByte(code[end]) || code[end] == '.') {
+			end++
+		}
+		return classNumber, end
+	case isWordByte(c):
+		end = i + 1
+		for end < len(code) && isWordByte(code[end]) {
+			end++
+		}
+		word := code[i:end]
+		if s.caseFolded {
+			word = strings.ToLower(word)
+		}
+		if s.keywords[word] {
+			return classKeyword, end
+		}
+		return "", end
+	}
+
+	return "", i + 1
+}
+
+// stringLength measures the string literal rest starts with, up to its
+// closing quote. Only multiline quotes span lines.
+func (s *syntax) stringLength(rest string) int {
+	quote := rest[0]
+	for i := 1; i < len(rest); i++ {
+		switch {
+		case rest[i] == '\\' && quote != s.multilineQuote:
+			i++
+		case rest[i] == quote:
+			return i + 1
+		case rest[i] == '\n' && quote != s.multilineQuote:
+			return i
+		}
+	}
+
+	return len(rest)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordByte checks whether a byte can be part of an identifier. Bytes of
+// multibyte characters are, so they are never split.
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
@@ -0,0 +1,242 @@
+// Package markdown renders Markdown into HTML that is safe to embed in a
+// page, with syntax-highlighted code blocks and a table of contents.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// extensions are the Markdown extensions rendered: GitHub-style tables,
+// fenced code blocks, autolinks and strikethrough, and heading IDs.
+const extensions = blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs
+
+// safeSchemes lists the URL schemes links and images may use. URLs without
+// a scheme are relative, and safe as well.
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Document is a Markdown source rendered to sanitized HTML.
+type Document struct {
+	HTML            string
+	TableOfContents []Heading
+}
+
+// Heading is an entry of the table of contents of a Document. ID is the id
+// attribute of the heading in the HTML, for linking to it.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+// Render renders a Markdown source. Raw HTML in the source is dropped, and
+// links and images are only kept when they use a safe scheme, so the HTML
+// can be embedded as is. Renderings are cached by source.
+func Render(source string) Document {
+	if source == "" {
+		return Document{TableOfContents: []Heading{}}
+	}
+
+	return renderings.resolve(source, render)
+}
+
+// render renders a Markdown source, bypassing the cache.
+func render(source string) Document {
+	r := &renderer{
+		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: blackfriday.SkipHTML,
+		}),
+		headingIDs: make(map[string]int),
+		toc:        []Heading{},
+	}
+
+	out := blackfriday.Run([]byte(source), blackfriday.WithExtensions(extensions), blackfriday.WithRenderer(r))
+	return Document{
+		HTML:            string(out),
+		TableOfContents: r.toc,
+	}
+}
+
+// renderer is an HTML renderer which sanitizes links and images, highlights
+// code blocks, and collects the table of contents. It leaves the other nodes
+// to blackfriday's HTML renderer, which escapes text and, told to, skips raw
+// HTML.
+type renderer struct {
+	*blackfriday.HTMLRenderer
+	headingIDs map[string]int
+	toc        []Heading
+}
+
+// RenderNode renders a single node.
+func (r *renderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	switch node.Type {
+	case blackfriday.Link:
+		r.renderLink(w, node, entering)
+		return blackfriday.GoToNext
+	case blackfriday.Image:
+		r.renderImage(w, node)
+		return blackfriday.SkipChildren
+	case blackfriday.Heading:
+		r.renderHeading(w, node, entering)
+		return blackfriday.GoToNext
+	case blackfriday.CodeBlock:
+		r.renderCodeBlock(w, node)
+		return blackfriday.GoToNext
+	}
+
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
+
+// renderLink renders a link, or only its text when the link is unsafe.
+func (r *renderer) renderLink(w io.Writer, node *blackfriday.Node, entering bool) {
+	dest, ok := sanitizeURL(node.LinkData.Destination)
+	if !ok {
+		return
+	}
+
+	if !entering {
+		io.WriteString(w, "</a>")
+		return
+	}
+
+	fmt.Fprintf(w, `<a href="%s" rel="nofollow noopener noreferrer"`, html.EscapeString(dest))
+	if len(node.LinkData.Title) > 0 {
+		fmt.Fprintf(w, ` title="%s"`, html.EscapeString(string(node.LinkData.Title)))
+	}
+	io.WriteString(w, ">")
+}
+
+// renderImage renders an image, with its text as alternative. Unsafe images
+// are left out.
+func (r *renderer) renderImage(w io.Writer, node *blackfriday.Node) {
+	src, ok := sanitizeURL(node.LinkData.Destination)
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, `<img src="%s" alt="%s"`, html.EscapeString(src), html.EscapeString(plainText(node)))
+	if len(node.LinkData.Title) > 0 {
+		fmt.Fprintf(w, ` title="%s"`, html.EscapeString(string(node.LinkData.Title)))
+	}
+	io.WriteString(w, " />")
+}
+
+// renderHeading renders a heading with a unique ID, and adds it to the table
+// of contents.
+func (r *renderer) renderHeading(w io.Writer, node *blackfriday.Node, entering bool) {
+	if !entering {
+		fmt.Fprintf(w, "</h%d>\n", node.Level)
+		return
+	}
+
+	text := plainText(node)
+	id := r.uniqueHeadingID(node.HeadingID)
+	r.toc = append(r.toc, Heading{Level: node.Level, Text: text, ID: id})
+	fmt.Fprintf(w, `<h%d id="%s">`, node.Level, html.EscapeString(id))
+}
+
+// renderCodeBlock renders a code block, highlighted when its language is
+// known.
+func (r *renderer) renderCodeBlock(w io.Writer, node *blackfriday.Node) {
+	language := codeLanguage(node.Info)
+	if language == "" {
+		io.WriteString(w, "<pre><code>")
+	} else {
+		fmt.Fprintf(w, `<pre><code class="language-%s">`, language)
+	}
+
+	io.WriteString(w, highlight(language, string(node.Literal)))
+	io.WriteString(w, "</code></pre>\n")
+}
+
+// uniqueHeadingID suffixes the ID of a heading that repeats an earlier one,
+// the way GitHub does.
+func (r *renderer) uniqueHeadingID(id string) string {
+	if id == "" {
+		id = "section"
+	}
+
+	count, seen := r.headingIDs[id]
+	r.headingIDs[id] = count + 1
+	if !seen {
+		return id
+	}
+
+	unique := fmt.Sprintf("%s-%d", id, count)
+	r.headingIDs[unique]++
+	return unique
+}
+
+// codeLanguage extracts the language of a fenced code block from its info
+// string, keeping only characters safe in a class name.
+func codeLanguage(info []byte) string {
+	fields := strings.Fields(string(info))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	language := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '+', c == '#':
+			return c
+		case c >= 'A' && c <= 'Z':
+			return c - 'A' + 'a'
+		}
+
+		return -1
+	}, fields[0])
+
+	return language
+}
+
+// plainText concatenates the text inside a node.
+func plainText(node *blackfriday.Node) string {
+	var buf bytes.Buffer
+	node.Walk(func(child *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if entering && (child.Type == blackfriday.Text || child.Type == blackfriday.Code) {
+			buf.Write(child.Literal)
+		}
+
+		return blackfriday.GoToNext
+	})
+
+	return strings.TrimSpace(buf.String())
+}
+
+// sanitizeURL decodes the character references of a link destination and
+// checks it uses a safe scheme. Browsers decode those references in
+// attributes, so checking the raw destination would let "&#106;avascript:"
+// links through.
+func sanitizeURL(dest []byte) (string, bool) {
+	decoded := strings.TrimSpace(html.UnescapeString(string(dest)))
+	if decoded == "" {
+		return "", false
+	}
+
+	for _, c := range decoded {
+		if c < ' ' || c == 0x7f {
+			return "", false
+		}
+	}
+
+	parsed, err := url.Parse(decoded)
+	if err != nil {
+		return "", false
+	}
+
+	if parsed.Scheme != "" && !safeSchemes[strings.ToLower(parsed.Scheme)] {
+		return "", false
+	}
+
+	return decoded, true
+}
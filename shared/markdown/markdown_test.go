@@ -0,0 +1,79 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/evermos/boilerplate-go/shared/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("drops raw HTML", func(t *testing.T) {
+		doc := markdown.Render("Hello <script>alert(1)</script> <img src=x onerror=alert(1)>\n\n<div onclick=\"alert(1)\">block</div>")
+
+		assert.NotContains(t, doc.HTML, "<script")
+		assert.NotContains(t, doc.HTML, "onerror")
+		assert.NotContains(t, doc.HTML, "onclick")
+		assert.Contains(t, doc.HTML, "Hello")
+	})
+
+	t.Run("strips unsafe links and images", func(t *testing.T) {
+		doc := markdown.Render("[a](javascript:alert%281%29) [b](&#106;avascript:alert%281%29) [c](JaVaScRiPt:void) ![d](data:image/svg+xml,x)")
+
+		assert.NotContains(t, strings.ToLower(doc.HTML), "javascript")
+		assert.NotContains(t, doc.HTML, "href")
+		assert.NotContains(t, doc.HTML, "<img")
+		assert.Contains(t, doc.HTML, "a b c")
+	})
+
+	t.Run("keeps safe links", func(t *testing.T) {
+		doc := markdown.Render(`[docs](https://example.com/?a=1&b=2) [next](../lessons/2)`)
+
+		assert.Contains(t, doc.HTML, `href="https://example.com/?a=1&amp;b=2"`)
+		assert.Contains(t, doc.HTML, `href="../lessons/2"`)
+	})
+
+	t.Run("builds a table of contents with unique IDs", func(t *testing.T) {
+		doc := markdown.Render("# Intro\n\n## Setup *fast*\n\n## Setup fast\n\n# Intro\n")
+
+		assert.Equal(t, []markdown.Heading{
+			{Level: 1, Text: "Intro", ID: "intro"},
+			{Level: 2, Text: "Setup fast", ID: "setup-fast"},
+			{Level: 2, Text: "Setup fast", ID: "setup-fast-1"},
+			{Level: 1, Text: "Intro", ID: "intro-1"},
+		}, doc.TableOfContents)
+		assert.Contains(t, doc.HTML, `<h2 id="setup-fast-1">`)
+	})
+
+	t.Run("highlights fenced code blocks", func(t *testing.T) {
+		doc := markdown.Render("```go\n// greet\nfunc main() { fmt.Println(\"<hi>\", 42) }\n```\n")
+
+		assert.Contains(t, doc.HTML, `<pre><code class="language-go">`)
+		assert.Contains(t, doc.HTML, `<span class="hl-comment">// greet</span>`)
+		assert.Contains(t, doc.HTML, `<span class="hl-keyword">func</span>`)
+		assert.Contains(t, doc.HTML, `<span class="hl-string">&#34;&lt;hi&gt;&#34;</span>`)
+		assert.Contains(t, doc.HTML, `<span class="hl-number">42</span>`)
+	})
+
+	t.Run("escapes code blocks of unknown languages", func(t *testing.T) {
+		doc := markdown.Render("```\"><script>\n<b>bold</b>\n```\n")
+
+		assert.NotContains(t, doc.HTML, "<script")
+		assert.NotContains(t, doc.HTML, "<b>")
+		assert.Contains(t, doc.HTML, "&lt;b&gt;bold&lt;/b&gt;")
+	})
+
+	t.Run("renders the same source the same way", func(t *testing.T) {
+		source := "# Title\n\nSome *text*."
+
+		assert.Equal(t, markdown.Render(source), markdown.Render(source))
+	})
+
+	t.Run("renders empty content", func(t *testing.T) {
+		doc := markdown.Render("")
+
+		assert.Equal(t, "", doc.HTML)
+		assert.Empty(t, doc.TableOfContents)
+	})
+}
@@ -39,11 +39,12 @@ const (
 
 // HTTP is the HTTP server.
 type HTTP struct {
-	Config *configs.Config
-	DB     *infras.MySQLConn
-	Router router.Router
-	State  ServerState
-	mux    *chi.Mux
+	Config        *configs.Config
+	DB            *infras.MySQLConn
+	Router        router.Router
+	State         ServerState
+	mux           *chi.Mux
+	shutdownHooks []func()
 }
 
 // ProvideHTTP is the provider for HTTP.
@@ -74,6 +75,12 @@ func (h *HTTP) SetupAndServe() {
 	}
 }
 
+// OnShutdown registers a function to be called when the server enters its
+// cleanup period, such as one stopping background work.
+func (h *HTTP) OnShutdown(hook func()) {
+	h.shutdownHooks = append(h.shutdownHooks, hook)
+}
+
 func (h *HTTP) setupSwaggerDocs() {
 	if h.Config.Server.Env == "development" {
 		docs.SwaggerInfo.Title = h.Config.App.Name
@@ -108,6 +115,9 @@ func (h *HTTP) respondToSigterm(done chan os.Signal) {
 
 	log.Info().Int64("seconds", shutdownConfig.CleanupPeriodSeconds).Msg("Entering cleanup period.")
 	h.State = ServerStateInCleanupPeriod
+	for _, hook := range h.shutdownHooks {
+		hook()
+	}
 	time.Sleep(time.Duration(shutdownConfig.CleanupPeriodSeconds) * time.Second)
 
 	log.Info().Msg("Cleaning up completed. Shutting down now.")
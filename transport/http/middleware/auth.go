@@ -66,21 +66,42 @@ func (a *Authentication) ValidateAuth(next http.Handler) http.Handler {
 }
 
 func (a *Authentication) UserRoleCheck(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp, ok := r.Context().Value("responseBody").(shared.Claims)
-		if !ok {
-			response.WithMessage(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
+	return a.RoleCheck(shared.RoleTeacher)(next)
+}
 
-		if resp.Role != "teacher" {
-			response.WithMessage(w, http.StatusUnauthorized, "User not authorized")
-			return
-		}
+// StudentRoleCheck only lets students through.
+func (a *Authentication) StudentRoleCheck(next http.Handler) http.Handler {
+	return a.RoleCheck(shared.RoleStudent)(next)
+}
 
-		ctx := context.WithValue(r.Context(), "responseBody", resp)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// RoleCheck returns a middleware that only lets users with one of the given
+// roles through.
+func (a *Authentication) RoleCheck(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp, ok := r.Context().Value("responseBody").(shared.Claims)
+			if !ok {
+				response.WithMessage(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+
+			allowed := false
+			for _, role := range roles {
+				if resp.Role == role {
+					allowed = true
+					break
+				}
+			}
+
+			if !allowed {
+				response.WithMessage(w, http.StatusUnauthorized, "User not authorized")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "responseBody", resp)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
 func (a *Authentication) ClientCredential(next http.Handler) http.Handler {
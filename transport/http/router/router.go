@@ -7,8 +7,28 @@ import (
 
 // DomainHandlers is a struct that contains all domain-specific handlers.
 type DomainHandlers struct {
-	FooBarBazHandler handlers.FooBarBazHandler
-	CourseHandler    handlers.CourseHandler
+	FooBarBazHandler    handlers.FooBarBazHandler
+	CourseHandler       handlers.CourseHandler
+	ModuleHandler       handlers.ModuleHandler
+	EnrollmentHandler   handlers.EnrollmentHandler
+	ProgressHandler     handlers.ProgressHandler
+	QuizHandler         handlers.QuizHandler
+	AssignmentHandler   handlers.AssignmentHandler
+	PublishingHandler   handlers.PublishingHandler
+	TaxonomyHandler     handlers.TaxonomyHandler
+	CertificateHandler  handlers.CertificateHandler
+	CohortHandler       handlers.CohortHandler
+	LiveSessionHandler  handlers.LiveSessionHandler
+	AttendanceHandler   handlers.AttendanceHandler
+	CollaboratorHandler handlers.CollaboratorHandler
+	ReviewHandler       handlers.ReviewHandler
+	DiscussionHandler   handlers.DiscussionHandler
+	AnnouncementHandler handlers.AnnouncementHandler
+	AttachmentHandler   handlers.AttachmentHandler
+	ArchiveHandler      handlers.ArchiveHandler
+	CloneHandler        handlers.CloneHandler
+	GradebookHandler    handlers.GradebookHandler
+	AnalyticsHandler    handlers.AnalyticsHandler
 }
 
 // Router is the router struct containing handlers.
@@ -28,5 +48,25 @@ func (r *Router) SetupRoutes(mux *chi.Mux) {
 	mux.Route("/v1", func(rc chi.Router) {
 		r.DomainHandlers.FooBarBazHandler.Router(rc)
 		r.DomainHandlers.CourseHandler.Router(rc)
+		r.DomainHandlers.ModuleHandler.Router(rc)
+		r.DomainHandlers.EnrollmentHandler.Router(rc)
+		r.DomainHandlers.ProgressHandler.Router(rc)
+		r.DomainHandlers.QuizHandler.Router(rc)
+		r.DomainHandlers.AssignmentHandler.Router(rc)
+		r.DomainHandlers.PublishingHandler.Router(rc)
+		r.DomainHandlers.TaxonomyHandler.Router(rc)
+		r.DomainHandlers.CertificateHandler.Router(rc)
+		r.DomainHandlers.CohortHandler.Router(rc)
+		r.DomainHandlers.LiveSessionHandler.Router(rc)
+		r.DomainHandlers.AttendanceHandler.Router(rc)
+		r.DomainHandlers.CollaboratorHandler.Router(rc)
+		r.DomainHandlers.ReviewHandler.Router(rc)
+		r.DomainHandlers.DiscussionHandler.Router(rc)
+		r.DomainHandlers.AnnouncementHandler.Router(rc)
+		r.DomainHandlers.AttachmentHandler.Router(rc)
+		r.DomainHandlers.ArchiveHandler.Router(rc)
+		r.DomainHandlers.CloneHandler.Router(rc)
+		r.DomainHandlers.GradebookHandler.Router(rc)
+		r.DomainHandlers.AnalyticsHandler.Router(rc)
 	})
 }
@@ -9,9 +9,12 @@ import (
 	// fooBarBazEvent "github.com/evermos/boilerplate-go/event/domain/foobarbaz"
 	"github.com/evermos/boilerplate-go/event/producer"
 	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/infras/storage"
 	"github.com/evermos/boilerplate-go/internal/domain/course"
 	"github.com/evermos/boilerplate-go/internal/domain/foobarbaz"
 	"github.com/evermos/boilerplate-go/internal/handlers"
+	"github.com/evermos/boilerplate-go/job"
+	analyticsJob "github.com/evermos/boilerplate-go/job/domain/analytics"
 	"github.com/evermos/boilerplate-go/transport/http"
 	"github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/evermos/boilerplate-go/transport/http/router"
@@ -26,6 +29,7 @@ var configurations = wire.NewSet(
 // Wiring for persistences.
 var persistences = wire.NewSet(
 	infras.ProvideMySQLConn,
+	storage.ProvideStorage,
 )
 
 // Wiring for domain FooBarBaz.
@@ -46,6 +50,129 @@ var domainCourse = wire.NewSet(
 	wire.Bind(new(course.CourseService), new(*course.CourseServiceImpl)),
 	course.ProvideCourseRepositoryMySQL,
 	wire.Bind(new(course.CourseRepository), new(*course.CourseRepositoryMySQL)),
+	// ModuleService interface and implementation
+	course.ProvideModuleServiceImpl,
+	wire.Bind(new(course.ModuleService), new(*course.ModuleServiceImpl)),
+	// ModuleRepository interface and implementation
+	course.ProvideModuleRepositoryMySQL,
+	wire.Bind(new(course.ModuleRepository), new(*course.ModuleRepositoryMySQL)),
+	// EnrollmentService interface and implementation
+	course.ProvideEnrollmentServiceImpl,
+	wire.Bind(new(course.EnrollmentService), new(*course.EnrollmentServiceImpl)),
+	// EnrollmentRepository interface and implementation
+	course.ProvideEnrollmentRepositoryMySQL,
+	wire.Bind(new(course.EnrollmentRepository), new(*course.EnrollmentRepositoryMySQL)),
+	// ProgressService interface and implementation
+	course.ProvideProgressServiceImpl,
+	wire.Bind(new(course.ProgressService), new(*course.ProgressServiceImpl)),
+	// ProgressRepository interface and implementation
+	course.ProvideProgressRepositoryMySQL,
+	wire.Bind(new(course.ProgressRepository), new(*course.ProgressRepositoryMySQL)),
+	// QuizService interface and implementation
+	course.ProvideQuizServiceImpl,
+	wire.Bind(new(course.QuizService), new(*course.QuizServiceImpl)),
+	// QuizRepository interface and implementation
+	course.ProvideQuizRepositoryMySQL,
+	wire.Bind(new(course.QuizRepository), new(*course.QuizRepositoryMySQL)),
+	// AssignmentService interface and implementation
+	course.ProvideAssignmentServiceImpl,
+	wire.Bind(new(course.AssignmentService), new(*course.AssignmentServiceImpl)),
+	// AssignmentRepository interface and implementation
+	course.ProvideAssignmentRepositoryMySQL,
+	wire.Bind(new(course.AssignmentRepository), new(*course.AssignmentRepositoryMySQL)),
+	// PublishingService interface and implementation
+	course.ProvidePublishingServiceImpl,
+	wire.Bind(new(course.PublishingService), new(*course.PublishingServiceImpl)),
+	// PublishingRepository interface and implementation
+	course.ProvidePublishingRepositoryMySQL,
+	wire.Bind(new(course.PublishingRepository), new(*course.PublishingRepositoryMySQL)),
+	// SearchRepository interface and implementation
+	course.ProvideSearchRepositoryMySQL,
+	wire.Bind(new(course.SearchRepository), new(*course.SearchRepositoryMySQL)),
+	// TaxonomyService interface and implementation
+	course.ProvideTaxonomyServiceImpl,
+	wire.Bind(new(course.TaxonomyService), new(*course.TaxonomyServiceImpl)),
+	// TaxonomyRepository interface and implementation
+	course.ProvideTaxonomyRepositoryMySQL,
+	wire.Bind(new(course.TaxonomyRepository), new(*course.TaxonomyRepositoryMySQL)),
+	// CertificateService interface and implementation
+	course.ProvideCertificateServiceImpl,
+	wire.Bind(new(course.CertificateService), new(*course.CertificateServiceImpl)),
+	// CertificateRepository interface and implementation
+	course.ProvideCertificateRepositoryMySQL,
+	wire.Bind(new(course.CertificateRepository), new(*course.CertificateRepositoryMySQL)),
+	// CohortService interface and implementation
+	course.ProvideCohortServiceImpl,
+	wire.Bind(new(course.CohortService), new(*course.CohortServiceImpl)),
+	// CohortRepository interface and implementation
+	course.ProvideCohortRepositoryMySQL,
+	wire.Bind(new(course.CohortRepository), new(*course.CohortRepositoryMySQL)),
+	// LiveSessionService interface and implementation
+	course.ProvideLiveSessionServiceImpl,
+	wire.Bind(new(course.LiveSessionService), new(*course.LiveSessionServiceImpl)),
+	// LiveSessionRepository interface and implementation
+	course.ProvideLiveSessionRepositoryMySQL,
+	wire.Bind(new(course.LiveSessionRepository), new(*course.LiveSessionRepositoryMySQL)),
+	// AttendanceService interface and implementation
+	course.ProvideAttendanceServiceImpl,
+	wire.Bind(new(course.AttendanceService), new(*course.AttendanceServiceImpl)),
+	// AttendanceRepository interface and implementation
+	course.ProvideAttendanceRepositoryMySQL,
+	wire.Bind(new(course.AttendanceRepository), new(*course.AttendanceRepositoryMySQL)),
+	// CollaboratorService interface and implementation
+	course.ProvideCollaboratorServiceImpl,
+	wire.Bind(new(course.CollaboratorService), new(*course.CollaboratorServiceImpl)),
+	// CollaboratorRepository interface and implementation
+	course.ProvideCollaboratorRepositoryMySQL,
+	wire.Bind(new(course.CollaboratorRepository), new(*course.CollaboratorRepositoryMySQL)),
+	// ReviewService interface and implementation
+	course.ProvideReviewServiceImpl,
+	wire.Bind(new(course.ReviewService), new(*course.ReviewServiceImpl)),
+	// ReviewRepository interface and implementation
+	course.ProvideReviewRepositoryMySQL,
+	wire.Bind(new(course.ReviewRepository), new(*course.ReviewRepositoryMySQL)),
+	// DiscussionService interface and implementation
+	course.ProvideDiscussionServiceImpl,
+	wire.Bind(new(course.DiscussionService), new(*course.DiscussionServiceImpl)),
+	// DiscussionRepository interface and implementation
+	course.ProvideDiscussionRepositoryMySQL,
+	wire.Bind(new(course.DiscussionRepository), new(*course.DiscussionRepositoryMySQL)),
+	// AnnouncementService interface and implementation
+	course.ProvideAnnouncementServiceImpl,
+	wire.Bind(new(course.AnnouncementService), new(*course.AnnouncementServiceImpl)),
+	// AnnouncementRepository interface and implementation
+	course.ProvideAnnouncementRepositoryMySQL,
+	wire.Bind(new(course.AnnouncementRepository), new(*course.AnnouncementRepositoryMySQL)),
+	// AttachmentService interface and implementation
+	course.ProvideAttachmentServiceImpl,
+	wire.Bind(new(course.AttachmentService), new(*course.AttachmentServiceImpl)),
+	// AttachmentRepository interface and implementation
+	course.ProvideAttachmentRepositoryMySQL,
+	wire.Bind(new(course.AttachmentRepository), new(*course.AttachmentRepositoryMySQL)),
+	// ArchiveService interface and implementation
+	course.ProvideArchiveServiceImpl,
+	wire.Bind(new(course.ArchiveService), new(*course.ArchiveServiceImpl)),
+	// ArchiveRepository interface and implementation
+	course.ProvideArchiveRepositoryMySQL,
+	wire.Bind(new(course.ArchiveRepository), new(*course.ArchiveRepositoryMySQL)),
+	// CloneService interface and implementation
+	course.ProvideCloneServiceImpl,
+	wire.Bind(new(course.CloneService), new(*course.CloneServiceImpl)),
+	// CloneRepository interface and implementation
+	course.ProvideCloneRepositoryMySQL,
+	wire.Bind(new(course.CloneRepository), new(*course.CloneRepositoryMySQL)),
+	// GradebookService interface and implementation
+	course.ProvideGradebookServiceImpl,
+	wire.Bind(new(course.GradebookService), new(*course.GradebookServiceImpl)),
+	// GradebookRepository interface and implementation
+	course.ProvideGradebookRepositoryMySQL,
+	wire.Bind(new(course.GradebookRepository), new(*course.GradebookRepositoryMySQL)),
+	// AnalyticsService interface and implementation
+	course.ProvideAnalyticsServiceImpl,
+	wire.Bind(new(course.AnalyticsService), new(*course.AnalyticsServiceImpl)),
+	// AnalyticsRepository interface and implementation
+	course.ProvideAnalyticsRepositoryMySQL,
+	wire.Bind(new(course.AnalyticsRepository), new(*course.AnalyticsRepositoryMySQL)),
 )
 
 // Wiring for all domains.
@@ -60,9 +187,29 @@ var authMiddleware = wire.NewSet(
 
 // Wiring for HTTP routing.
 var routing = wire.NewSet(
-	wire.Struct(new(router.DomainHandlers), "FooBarBazHandler", "CourseHandler"),
+	wire.Struct(new(router.DomainHandlers), "FooBarBazHandler", "CourseHandler", "ModuleHandler", "EnrollmentHandler", "ProgressHandler", "QuizHandler", "AssignmentHandler", "PublishingHandler", "TaxonomyHandler", "CertificateHandler", "CohortHandler", "LiveSessionHandler", "AttendanceHandler", "CollaboratorHandler", "ReviewHandler", "DiscussionHandler", "AnnouncementHandler", "AttachmentHandler", "ArchiveHandler", "CloneHandler", "GradebookHandler", "AnalyticsHandler"),
 	handlers.ProvideFooBarBazHandler,
 	handlers.ProvideCourseHandler,
+	handlers.ProvideModuleHandler,
+	handlers.ProvideEnrollmentHandler,
+	handlers.ProvideProgressHandler,
+	handlers.ProvideQuizHandler,
+	handlers.ProvideAssignmentHandler,
+	handlers.ProvidePublishingHandler,
+	handlers.ProvideTaxonomyHandler,
+	handlers.ProvideCertificateHandler,
+	handlers.ProvideCohortHandler,
+	handlers.ProvideLiveSessionHandler,
+	handlers.ProvideAttendanceHandler,
+	handlers.ProvideCollaboratorHandler,
+	handlers.ProvideReviewHandler,
+	handlers.ProvideDiscussionHandler,
+	handlers.ProvideAnnouncementHandler,
+	handlers.ProvideAttachmentHandler,
+	handlers.ProvideArchiveHandler,
+	handlers.ProvideCloneHandler,
+	handlers.ProvideGradebookHandler,
+	handlers.ProvideAnalyticsHandler,
 	router.ProvideRouter,
 )
 
@@ -72,8 +219,14 @@ var routing = wire.NewSet(
 // 	fooBarBazEvent.ProvideConsumerImpl,
 // )
 
+// Wiring for all periodic jobs.
+var jobs = wire.NewSet(
+	wire.Struct(new(job.Jobs), "Analytics"),
+	analyticsJob.ProvideJobImpl,
+)
+
 // Wiring for everything.
-func InitializeService() *http.HTTP {
+func InitializeService() Service {
 	wire.Build(
 		// configurations
 		configurations,
@@ -85,9 +238,12 @@ func InitializeService() *http.HTTP {
 		domains,
 		// routing
 		routing,
+		// periodic jobs
+		jobs,
 		// selected transport layer
-		http.ProvideHTTP)
-	return &http.HTTP{}
+		http.ProvideHTTP,
+		wire.Struct(new(Service), "HTTP", "Jobs"))
+	return Service{}
 }
 
 // Wiring the event needs.